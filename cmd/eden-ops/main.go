@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"eden-ops/internal/handler"
+	"eden-ops/internal/pkg/archive"
 	"eden-ops/internal/pkg/database"
 	"eden-ops/internal/repository"
 	"eden-ops/internal/router"
@@ -10,6 +11,7 @@ import (
 	"eden-ops/internal/task"
 	"eden-ops/pkg/auth"
 	"eden-ops/pkg/config"
+	"eden-ops/pkg/iplocator"
 	"eden-ops/pkg/logger"
 	"fmt"
 	"net"
@@ -164,16 +166,29 @@ func main() {
 	db := dbInstance.DB
 
 	// 初始化JWT
-	jwtAuth := auth.NewJWTAuth(cfg.JWT.Secret, cfg.JWT.Expire)
+	accessExpire := time.Duration(cfg.JWT.AccessExpire) * time.Minute
+	if accessExpire <= 0 {
+		accessExpire = 15 * time.Minute
+	}
+	jwtAuth := auth.NewJWTAuth(cfg.JWT.Secret, accessExpire)
 
 	// 初始化仓库
 	userRepo := repository.NewUserRepository(db)
 	roleRepo := repository.NewRoleRepository(db)
 	menuRepo := repository.NewMenuRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	userSessionRepo := repository.NewUserSessionRepository(db)
+	userRecoveryCodeRepo := repository.NewUserRecoveryCodeRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
 	cloudAccountRepo := repository.NewCloudAccountRepository(db)
 	cloudProviderRepo := repository.NewCloudProviderRepository(db)
+	cloudResourceRepo := repository.NewCloudResourceRepository(db)
+	cloudResourceHistoryRepo := repository.NewCloudResourceHistoryRepository(db)
 	databaseConfigRepo := repository.NewDatabaseConfigRepository(db)
 	serverConfigRepo := repository.NewServerConfigRepository(db)
+	serverSessionRepo := repository.NewServerSessionRepository(db)
+	serverFileAuditRepo := repository.NewServerFileAuditRepository(db)
 	k8sConfigRepo := repository.NewK8sConfigRepository(db)
 	k8sWorkloadRepo := repository.NewK8sWorkloadRepository(db)
 	k8sNamespaceRepo := repository.NewK8sNamespaceRepository(db)
@@ -181,19 +196,41 @@ func main() {
 	k8sPodHistoryRepo := repository.NewK8sPodHistoryRepository(db)
 	k8sNodeHistoryRepo := repository.NewK8sNodeHistoryRepository(db)
 	k8sWorkloadHistoryRepo := repository.NewK8sWorkloadHistoryRepository(db)
+	k8sHistoryStatisticsRepo := repository.NewK8sHistoryStatisticsRepository(db)
+	k8sHistoryRetentionPolicyRepo := repository.NewK8sHistoryRetentionPolicyRepository(db)
+	k8sChangeEventRepo := repository.NewK8sChangeEventRepository(db)
+	k8sInventoryRepo := repository.NewK8sInventoryRepository(db)
+	k8sCapacitySummaryRepo := repository.NewK8sCapacitySummaryRepository(db)
+	k8sLabelRepo := repository.NewK8sLabelRepository(db)
 
 	// 初始化服务
-	userService := service.NewUserService(userRepo, jwtAuth)
-	roleService := service.NewRoleService(roleRepo)
-	menuService := service.NewMenuService(menuRepo)
-	cloudAccountService := service.NewCloudAccountService(cloudAccountRepo)
+	tokenService := service.NewTokenService(userSessionRepo, userRepo, jwtAuth, time.Duration(cfg.JWT.Expire)*time.Hour)
+	permissionService := service.NewPermissionService(permissionRepo)
+	ldapService := service.NewLDAPService(cfg.LDAP, userRepo, roleRepo, permissionService)
+	totpService := service.NewTOTPService(cfg.Security.TOTPIssuer, userRepo, userRecoveryCodeRepo)
+	userService := service.NewUserService(userRepo, roleRepo, tokenService, ldapService, totpService, cfg.Security)
+	apiTokenService := service.NewAPITokenService(apiTokenRepo, userRepo, permissionService, cfg.Security)
+	oidcService := service.NewOIDCService(cfg.OIDC, cfg.JWT.Secret, userRepo, roleRepo, permissionService)
+	roleService := service.NewRoleService(roleRepo, menuRepo)
+	menuService := service.NewMenuService(menuRepo, permissionService)
+	cloudAccountService := service.NewCloudAccountService(cloudAccountRepo, cloudProviderRepo)
 	cloudProviderService := service.NewCloudProviderService(cloudProviderRepo)
+	cloudResourceService := service.NewCloudResourceService(cloudAccountRepo, cloudAccountService, cloudResourceRepo, cloudResourceHistoryRepo, cfg.CloudSync)
 	databaseConfigService := service.NewDatabaseConfigService(databaseConfigRepo)
 	serverConfigService := service.NewServerConfigService(serverConfigRepo)
-	k8sWorkloadService := service.NewK8sWorkloadService(k8sWorkloadRepo, k8sWorkloadHistoryRepo)
-	k8sPodService := service.NewK8sPodService(k8sPodRepo, k8sPodHistoryRepo)
+	serverTerminalService := service.NewServerTerminalService(serverConfigRepo, serverSessionRepo, userRepo, cfg.Terminal)
+	serverTerminalService.CloseStaleSessions()
+	serverFileService := service.NewServerFileService(serverConfigRepo, serverFileAuditRepo, serverTerminalService, cfg.SFTP)
+	k8sWorkloadService := service.NewK8sWorkloadService(k8sWorkloadRepo, k8sWorkloadHistoryRepo, k8sChangeEventRepo, k8sLabelRepo)
+	k8sPodService := service.NewK8sPodService(k8sPodRepo, k8sPodHistoryRepo, k8sChangeEventRepo, k8sLabelRepo)
 	k8sNodeRepo := repository.NewK8sNodeRepository(db)
-	k8sNodeService := service.NewK8sNodeService(k8sNodeRepo, k8sNodeHistoryRepo)
+	k8sNodeService := service.NewK8sNodeService(k8sNodeRepo, k8sNodeHistoryRepo, k8sChangeEventRepo, k8sLabelRepo)
+	k8sChangeEventService := service.NewK8sChangeEventService(k8sChangeEventRepo)
+	k8sInventoryService := service.NewK8sInventoryService(k8sInventoryRepo)
+	k8sSearchService := service.NewK8sSearchService(k8sPodRepo, k8sWorkloadRepo, k8sNodeRepo, k8sConfigRepo)
+	k8sTopologyService := service.NewK8sTopologyService(k8sPodRepo, k8sWorkloadRepo, k8sNodeRepo, k8sConfigRepo)
+	k8sCapacityService := service.NewK8sCapacityService(k8sPodRepo, k8sWorkloadRepo, k8sNodeRepo, k8sConfigRepo, k8sCapacitySummaryRepo)
+	ipLocateService := service.NewIPLocateService(k8sPodRepo, k8sPodHistoryRepo, k8sNodeRepo, k8sConfigRepo, serverConfigRepo, databaseConfigRepo, cloudResourceRepo, cloudAccountRepo, cloudAccountService)
 	k8sConfigService := service.NewK8sConfigService(k8sConfigRepo, k8sWorkloadService, k8sWorkloadRepo, k8sNamespaceRepo, k8sPodService, k8sNodeService, k8sPodHistoryRepo, k8sNodeHistoryRepo, k8sWorkloadHistoryRepo)
 
 	// 启动K8s同步任务
@@ -208,28 +245,65 @@ func main() {
 	}()
 	logger.Info("K8s同步任务启动成功")
 
-	// 启动K8s历史数据清理服务
-	if cfg.K8sHistory.CleanupEnabled {
-		logger.Info("启动K8s历史数据清理服务...")
+	// 启动云资源同步任务
+	cloudSyncTask := task.NewCloudSyncTask(cloudResourceService, cfg.CloudSync.Interval)
+	if err := cloudSyncTask.Start(syncCtx); err != nil {
+		logger.Error("启动云资源同步任务失败: %v", err)
+	}
 
-		// 解析清理间隔
-		cleanupInterval, err := time.ParseDuration(cfg.K8sHistory.CleanupInterval)
-		if err != nil {
-			logger.Error("解析清理间隔失败: %v，使用默认值24h", err)
-			cleanupInterval = 24 * time.Hour
-		}
+	// 启动登录会话清理任务
+	tokenCleanupTask := task.NewTokenCleanupTask(tokenService)
+	if err := tokenCleanupTask.Start(syncCtx); err != nil {
+		logger.Error("启动登录会话清理任务失败: %v", err)
+	}
 
-		// 创建清理服务配置
-		cleanupConfig := service.K8sHistoryCleanupConfig{
-			Enabled:         cfg.K8sHistory.Enabled,
-			CleanupEnabled:  cfg.K8sHistory.CleanupEnabled,
-			CleanupDays:     cfg.K8sHistory.CleanupDays,
-			CleanupInterval: cleanupInterval,
-			BatchSize:       cfg.K8sHistory.BatchSize,
+	// 启动每周容量汇总任务
+	if cfg.K8sCapacity.WeeklySummary {
+		capacitySummaryTask := task.NewK8sCapacitySummaryTask(k8sCapacityService, cfg.K8sCapacity.Schedule, cfg.K8sCapacity.IncludeUsage)
+		if err := capacitySummaryTask.Start(syncCtx); err != nil {
+			logger.Error("启动每周容量汇总任务失败: %v", err)
 		}
+	}
 
-		k8sHistoryCleanupService := service.NewK8sHistoryCleanupService(k8sPodHistoryRepo, k8sNodeHistoryRepo, k8sWorkloadHistoryRepo, cleanupConfig)
+	// 创建K8s历史数据清理服务，手动清理和历史统计也由它提供，定时清理仅在启用时启动
+	cleanupInterval, err := time.ParseDuration(cfg.K8sHistory.CleanupInterval)
+	if err != nil {
+		if cfg.K8sHistory.CleanupEnabled {
+			logger.Error("解析清理间隔失败: %v，使用默认值24h", err)
+		}
+		cleanupInterval = 24 * time.Hour
+	}
+	var batchSleep time.Duration
+	if cfg.K8sHistory.BatchSleep != "" {
+		if batchSleep, err = time.ParseDuration(cfg.K8sHistory.BatchSleep); err != nil {
+			logger.Error("解析清理批次间隔失败: %v，不设置间隔", err)
+			batchSleep = 0
+		}
+	}
+	cleanupConfig := service.K8sHistoryCleanupConfig{
+		Enabled:         cfg.K8sHistory.Enabled,
+		CleanupEnabled:  cfg.K8sHistory.CleanupEnabled,
+		CleanupDays:     cfg.K8sHistory.CleanupDays,
+		CleanupInterval: cleanupInterval,
+		BatchSize:       cfg.K8sHistory.BatchSize,
+		BatchSleep:      batchSleep,
+	}
+	// 启用归档时清理前先归档，归档存储配置错误时不能继续启动，否则数据会在未归档的情况下被删除
+	var k8sHistoryArchiveService *service.K8sHistoryArchiveService
+	if cfg.K8sHistory.Archive.Enabled {
+		archiveSink, err := archive.NewSink(cfg.K8sHistory.Archive)
+		if err != nil {
+			logger.Error("初始化K8s历史数据归档存储失败: %v", err)
+			os.Exit(1)
+		}
+		k8sHistoryArchiveService = service.NewK8sHistoryArchiveService(archiveSink, cfg.K8sHistory.Archive.Prefix, cfg.K8sHistory.BatchSize, k8sPodHistoryRepo, k8sNodeHistoryRepo, k8sWorkloadHistoryRepo)
+		logger.Info("K8s历史数据归档已启用，存储类型: %s", archiveSink.Name())
+	}
+	k8sHistoryCleanupService := service.NewK8sHistoryCleanupService(k8sPodHistoryRepo, k8sNodeHistoryRepo, k8sWorkloadHistoryRepo, k8sHistoryStatisticsRepo, k8sHistoryRetentionPolicyRepo, k8sConfigRepo, k8sChangeEventRepo, k8sHistoryArchiveService, cleanupConfig)
 
+	// 启动K8s历史数据清理服务
+	if cfg.K8sHistory.CleanupEnabled {
+		logger.Info("启动K8s历史数据清理服务...")
 		go func() {
 			k8sHistoryCleanupService.Start()
 		}()
@@ -237,40 +311,85 @@ func main() {
 	}
 
 	// 初始化处理器
-	userHandler := handler.NewUserHandler(userService, jwtAuth)
-	roleHandler := handler.NewRoleHandler(roleService)
-	menuHandler := handler.NewMenuHandler(menuService)
-	authHandler := handler.NewAuthHandler(userService, jwtAuth)
+	userHandler := handler.NewUserHandler(userService, permissionService, totpService)
+	roleHandler := handler.NewRoleHandler(roleService, permissionService)
+	menuHandler := handler.NewMenuHandler(menuService, permissionService)
+	authHandler := handler.NewAuthHandler(userService, tokenService)
+	oidcHandler := handler.NewOIDCHandler(oidcService, tokenService, cfg.OIDC.FrontendURL)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService, userService)
 	cloudAccountHandler := handler.NewCloudAccountHandler(cloudAccountService)
 	cloudProviderHandler := handler.NewCloudProviderHandler(cloudProviderService)
+	cloudResourceHandler := handler.NewCloudResourceHandler(cloudResourceService)
+	var geoLocator *iplocator.IPLocator
+	if cfg.IPLocator.BaseURL != "" {
+		geoLocator = iplocator.NewIPLocator(cfg.IPLocator.AccessKey, cfg.IPLocator.SecretKey, cfg.IPLocator.BaseURL)
+	}
+	ipLocatorHandler := handler.NewIPLocatorHandler(ipLocateService, geoLocator)
 	databaseConfigHandler := handler.NewDatabaseConfigHandler(databaseConfigService)
 	serverConfigHandler := handler.NewServerConfigHandler(serverConfigService)
+	serverTerminalHandler := handler.NewServerTerminalHandler(serverTerminalService)
+	serverFileHandler := handler.NewServerFileHandler(serverFileService, serverTerminalService)
 	k8sConfigHandler := handler.NewK8sConfigHandler(k8sConfigService)
 	k8sWorkloadHandler := handler.NewK8sWorkloadHandler(k8sWorkloadService)
 	k8sNamespaceHandler := handler.NewK8sNamespaceHandler(k8sNamespaceRepo)
 	k8sPodHandler := handler.NewK8sPodHandler(k8sPodService)
 	k8sNodeHandler := handler.NewK8sNodeHandler(k8sNodeService)
-	k8sHistoryHandler := handler.NewK8sHistoryHandler(k8sPodHistoryRepo, k8sNodeHistoryRepo, k8sWorkloadHistoryRepo)
+	k8sHistoryHandler := handler.NewK8sHistoryHandler(k8sPodHistoryRepo, k8sNodeHistoryRepo, k8sWorkloadHistoryRepo, k8sHistoryCleanupService)
+	k8sChangeEventHandler := handler.NewK8sChangeEventHandler(k8sChangeEventService)
+	k8sInventoryHandler := handler.NewK8sInventoryHandler(k8sInventoryService)
+	k8sSearchHandler := handler.NewK8sSearchHandler(k8sSearchService)
+	k8sTopologyHandler := handler.NewK8sTopologyHandler(k8sTopologyService)
+	k8sCapacityHandler := handler.NewK8sCapacityHandler(k8sCapacityService)
+
+	// 注册审计快照，修改和删除这些资源时记录字段变更
+	auditService := service.NewAuditService(auditLogRepo)
+	auditService.RegisterSnapshot("users", func(id uint) (interface{}, error) { return userService.Get(id) })
+	auditService.RegisterSnapshot("roles", func(id uint) (interface{}, error) { return roleService.Get(id) })
+	auditService.RegisterSnapshot("menus", func(id uint) (interface{}, error) { return menuService.Get(id) })
+	auditService.RegisterSnapshot("cloud-accounts", func(id uint) (interface{}, error) { return cloudAccountService.Get(id) })
+	auditService.RegisterSnapshot("cloud-providers", func(id uint) (interface{}, error) { return cloudProviderService.Get(id) })
+	auditService.RegisterSnapshot("database-configs", func(id uint) (interface{}, error) { return databaseConfigService.Get(id) })
+	auditService.RegisterSnapshot("server-configs", func(id uint) (interface{}, error) { return serverConfigService.Get(id) })
+	auditService.RegisterSnapshot("k8s-configs", func(id uint) (interface{}, error) { return k8sConfigService.Get(id) })
+	auditService.RegisterSnapshot("k8s-nodes", func(id uint) (interface{}, error) { return k8sNodeService.GetByID(int64(id)) })
+	auditLogHandler := handler.NewAuditLogHandler(auditService)
 
 	// 初始化路由
 	logger.Info("初始化路由...")
 	r := router.NewRouter(
 		cfg.Server.GinMode,
 		jwtAuth,
+		tokenService,
+		apiTokenService,
+		permissionService,
+		permissionService,
+		auditService,
 		cloudAccountHandler,
 		cloudProviderHandler,
+		cloudResourceHandler,
+		ipLocatorHandler,
 		databaseConfigHandler,
 		serverConfigHandler,
+		serverTerminalHandler,
+		serverFileHandler,
 		k8sConfigHandler,
 		k8sWorkloadHandler,
 		k8sNamespaceHandler,
 		k8sPodHandler,
 		k8sNodeHandler,
 		k8sHistoryHandler,
+		k8sChangeEventHandler,
+		k8sInventoryHandler,
+		k8sSearchHandler,
+		k8sTopologyHandler,
+		k8sCapacityHandler,
 		userHandler,
 		roleHandler,
 		menuHandler,
 		authHandler,
+		oidcHandler,
+		apiTokenHandler,
+		auditLogHandler,
 	)
 
 	// 列出所有API接口
@@ -279,6 +398,21 @@ func main() {
 		logger.Debug("注册路由: %s", endpoint)
 	}
 
+	// 检查未配置权限映射的接口，这些接口仅管理员可访问
+	var protectedEndpoints []string
+	for _, endpoint := range apiEndpoints {
+		if !router.IsPublicRoute(endpoint) {
+			protectedEndpoints = append(protectedEndpoints, endpoint)
+		}
+	}
+	if unmapped, err := permissionService.UnmappedRoutes(protectedEndpoints); err != nil {
+		logger.Warn("检查接口权限映射失败: %v", err)
+	} else {
+		for _, endpoint := range unmapped {
+			logger.Warn("接口 %s 未配置权限映射（sys_api_permission），仅管理员可访问", endpoint)
+		}
+	}
+
 	// 统计API接口
 	apiCount := countAPIEndpoints(r)
 	logger.Info("路由初始化成功，共有 %d 个 API 接口", apiCount)
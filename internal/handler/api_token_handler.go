@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/response"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APITokenHandler API令牌及服务账号处理器
+type APITokenHandler struct {
+	apiTokenService service.APITokenService
+	userService     service.UserService
+}
+
+// NewAPITokenHandler 创建API令牌处理器
+func NewAPITokenHandler(apiTokenService service.APITokenService, userService service.UserService) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenService: apiTokenService,
+		userService:     userService,
+	}
+}
+
+// ServiceAccountRequest 服务账号请求
+type ServiceAccountRequest struct {
+	Username string `json:"username" binding:"required,max=32"`
+	Nickname string `json:"nickname"`
+	Email    string `json:"email"`
+	RoleIDs  []uint `json:"roleIds"`
+}
+
+// List 获取当前用户的API令牌
+func (h *APITokenHandler) List(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	tokens, err := h.apiTokenService.List(userID)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// Create 为当前用户创建API令牌，令牌明文仅返回一次
+func (h *APITokenHandler) Create(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	var req service.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	token, err := h.apiTokenService.Create(userID, &req)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, token)
+}
+
+// Revoke 吊销当前用户的API令牌
+func (h *APITokenHandler) Revoke(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的令牌ID")
+		return
+	}
+
+	h.revoke(c, userID, uint(tokenID))
+}
+
+// CreateServiceAccount 创建服务账号
+func (h *APITokenHandler) CreateServiceAccount(c *gin.Context) {
+	var req ServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	user := &model.User{
+		Username: req.Username,
+		Nickname: req.Nickname,
+		Email:    req.Email,
+		Status:   1,
+	}
+	if err := h.apiTokenService.CreateServiceAccount(user, req.RoleIDs); err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, user)
+}
+
+// ListUserTokens 获取指定用户的API令牌
+func (h *APITokenHandler) ListUserTokens(c *gin.Context) {
+	user, ok := h.targetUser(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.apiTokenService.List(user.ID)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// CreateUserToken 为服务账号创建API令牌，普通用户的令牌须由本人创建
+func (h *APITokenHandler) CreateUserToken(c *gin.Context) {
+	user, ok := h.targetUser(c)
+	if !ok {
+		return
+	}
+	if !user.IsServiceAccount() {
+		response.BadRequest(c, "只能为服务账号创建令牌")
+		return
+	}
+
+	var req service.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	token, err := h.apiTokenService.Create(user.ID, &req)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, token)
+}
+
+// RevokeUserToken 吊销指定用户的API令牌
+func (h *APITokenHandler) RevokeUserToken(c *gin.Context) {
+	user, ok := h.targetUser(c)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("tokenId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的令牌ID")
+		return
+	}
+
+	h.revoke(c, user.ID, uint(tokenID))
+}
+
+// targetUser 获取路径参数中的用户
+func (h *APITokenHandler) targetUser(c *gin.Context) (*model.User, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的用户ID")
+		return nil, false
+	}
+
+	user, err := h.userService.Get(uint(id))
+	if err != nil {
+		response.NotFound(c, "用户不存在")
+		return nil, false
+	}
+	return user, true
+}
+
+// revoke 吊销令牌
+func (h *APITokenHandler) revoke(c *gin.Context, userID, tokenID uint) {
+	if err := h.apiTokenService.Revoke(userID, tokenID); err != nil {
+		if errors.Is(err, service.ErrAPITokenNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/repository"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditLogListOptions 审计日志按ID倒序，支持游标分页
+var auditLogListOptions = listquery.Options{
+	DefaultPageSize: 20,
+	SortFields:      map[string]string{"id": "id"},
+	DefaultSort:     "id",
+	DefaultOrder:    "desc",
+	Cursor:          true,
+}
+
+// AuditLogHandler 操作审计日志处理器
+type AuditLogHandler struct {
+	auditService service.AuditService
+}
+
+// NewAuditLogHandler 创建操作审计日志处理器
+func NewAuditLogHandler(auditService service.AuditService) *AuditLogHandler {
+	return &AuditLogHandler{auditService: auditService}
+}
+
+// List 分页查询审计日志
+func (h *AuditLogHandler) List(c *gin.Context) {
+	filter, err := auditLogFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	q, err := listquery.Parse(c, auditLogListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.auditService.List(q, filter)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.ListSuccess(c, result.PageData())
+}
+
+// Get 获取审计日志详情，包含字段变更和请求参数
+func (h *AuditLogHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的日志ID")
+		return
+	}
+
+	log, err := h.auditService.Get(uint(id))
+	if err != nil {
+		response.NotFound(c, "审计日志不存在")
+		return
+	}
+
+	response.Success(c, log)
+}
+
+// Export 导出审计日志，format 为 csv（默认）或 jsonl
+func (h *AuditLogHandler) Export(c *gin.Context) {
+	filter, err := auditLogFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		response.BadRequest(c, "不支持的导出格式")
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	var write func([]*model.AuditLog) error
+	if format == "jsonl" {
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+		encoder := json.NewEncoder(c.Writer)
+		write = func(logs []*model.AuditLog) error {
+			for _, log := range logs {
+				if err := encoder.Encode(log); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		// 写入 BOM，便于 Excel 正确识别 UTF-8 编码
+		c.Writer.WriteString("\xEF\xBB\xBF")
+		writer := csv.NewWriter(c.Writer)
+		writer.Write([]string{"ID", "时间", "用户", "来源IP", "API令牌ID", "操作", "资源类型", "资源ID", "资源名称", "请求方法", "请求路径", "结果", "信息", "变更", "请求参数", "耗时(毫秒)"})
+		write = func(logs []*model.AuditLog) error {
+			for _, log := range logs {
+				changes, _ := json.Marshal(log.Changes)
+				writer.Write([]string{
+					strconv.FormatUint(uint64(log.ID), 10),
+					log.CreatedAt.Format("2006-01-02 15:04:05"),
+					csvSafe(log.Username),
+					log.ClientIP,
+					strconv.FormatUint(uint64(log.APITokenID), 10),
+					log.Action,
+					log.ResourceType,
+					csvSafe(log.ResourceID),
+					csvSafe(log.ResourceName),
+					log.Method,
+					csvSafe(log.Path),
+					log.Result,
+					csvSafe(log.Message),
+					csvSafe(string(changes)),
+					csvSafe(log.Request),
+					strconv.FormatInt(log.Duration, 10),
+				})
+			}
+			writer.Flush()
+			return writer.Error()
+		}
+	}
+
+	// 响应已开始输出，导出中途出错时只能记录日志
+	if err := h.auditService.Export(filter, write); err != nil {
+		logger.Error("导出审计日志失败: %v", err)
+	}
+}
+
+// auditLogFilter 解析审计日志查询条件，时间格式为 2006-01-02 15:04:05 或 RFC3339
+func auditLogFilter(c *gin.Context) (*repository.AuditLogFilter, error) {
+	filter := &repository.AuditLogFilter{
+		Username:     c.Query("username"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resourceType"),
+		ResourceID:   c.Query("resourceId"),
+		Result:       c.Query("result"),
+		ClientIP:     c.Query("clientIp"),
+		Keyword:      c.Query("keyword"),
+	}
+	if userID := c.Query("userId"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 64)
+		if err != nil {
+			return nil, errors.New("无效的用户ID")
+		}
+		filter.UserID = uint(id)
+	}
+
+	var err error
+	if filter.StartTime, err = parseAuditTime(c.Query("startTime")); err != nil {
+		return nil, err
+	}
+	if filter.EndTime, err = parseAuditTime(c.Query("endTime")); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// parseAuditTime 解析查询时间，空值返回 nil
+func parseAuditTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("无效的时间: %s", value)
+	}
+	return &t, nil
+}
+
+// csvSafe 防止以公式字符开头的内容在表格软件中被当作公式执行
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
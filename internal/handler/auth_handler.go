@@ -1,26 +1,27 @@
 package handler
 
 import (
+	"eden-ops/internal/model"
 	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/service"
-	"eden-ops/pkg/auth"
 	"eden-ops/pkg/logger"
 	"eden-ops/pkg/response"
+	"errors"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AuthHandler 认证处理器
 type AuthHandler struct {
-	userService service.UserService
-	jwtAuth     *auth.JWTAuth
+	userService  service.UserService
+	tokenService service.TokenService
 }
 
 // NewAuthHandler 创建认证处理器
-func NewAuthHandler(userService service.UserService, jwtAuth *auth.JWTAuth) *AuthHandler {
+func NewAuthHandler(userService service.UserService, tokenService service.TokenService) *AuthHandler {
 	return &AuthHandler{
-		userService: userService,
-		jwtAuth:     jwtAuth,
+		userService:  userService,
+		tokenService: tokenService,
 	}
 }
 
@@ -39,25 +40,85 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	logger.Info("用户登录请求: username=%s", req.Username)
 
 	// 调用服务层登录方法
-	token, err := h.userService.Login(req.Username, req.Password)
+	user, err := h.userService.Login(req.Username, req.Password, c.ClientIP())
 	if err != nil {
 		logger.Error("用户登录失败: username=%s, error=%v", req.Username, err)
 		response.Unauthorized(c, err.Error())
 		return
 	}
 
-	// 获取用户信息
-	claims, err := h.jwtAuth.ParseToken(token)
+	// 启用两步验证的用户须先完成验证码校验
+	if user.TOTPEnabled {
+		h.challenge(c, user, service.LoginChallengeTOTP)
+		return
+	}
+	h.completeLogin(c, user)
+}
+
+// LoginTOTP 登录第二步，校验两步验证码或恢复码
+func (h *AuthHandler) LoginTOTP(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challengeToken" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, err := h.tokenService.VerifyChallenge(req.ChallengeToken, service.LoginChallengeTOTP)
 	if err != nil {
-		logger.Error("解析token失败: username=%s, error=%v", req.Username, err)
-		response.Failed(c, err)
+		response.Unauthorized(c, err.Error())
+		return
+	}
+	user, err := h.userService.VerifyTOTP(userID, req.Code, c.ClientIP())
+	if err != nil {
+		response.Unauthorized(c, err.Error())
 		return
 	}
 
-	// 获取用户信息
-	user, err := h.userService.GetUserInfo(claims.UserID)
+	h.completeLogin(c, user)
+}
+
+// LoginPassword 首次登录或密码被重置后修改密码，完成后签发令牌
+func (h *AuthHandler) LoginPassword(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challengeToken" binding:"required"`
+		NewPassword    string `json:"newPassword" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID, err := h.tokenService.VerifyChallenge(req.ChallengeToken, service.LoginChallengePasswordChange)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+	user, err := h.userService.ChangeRequiredPassword(userID, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLoginChallenge) {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	h.completeLogin(c, user)
+}
+
+// completeLogin 需要修改密码时返回修改密码挑战，否则创建登录会话并签发令牌
+func (h *AuthHandler) completeLogin(c *gin.Context, user *model.User) {
+	if user.PasswordChangeRequired && user.IsLocal() {
+		h.challenge(c, user, service.LoginChallengePasswordChange)
+		return
+	}
+
+	tokens, err := h.tokenService.Issue(user, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
-		logger.Error("获取用户信息失败: username=%s, userID=%d, error=%v", req.Username, claims.UserID, err)
+		logger.Error("签发令牌失败: username=%s, error=%v", user.Username, err)
 		response.Failed(c, err)
 		return
 	}
@@ -65,16 +126,86 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	// 清除敏感信息
 	user.Password = ""
 
-	logger.Info("用户登录成功: username=%s, userID=%d", req.Username, user.ID)
+	logger.Info("用户登录成功: username=%s, userID=%d", user.Username, user.ID)
+	response.Success(c, gin.H{
+		"token":            tokens.AccessToken,
+		"refreshToken":     tokens.RefreshToken,
+		"expiresIn":        tokens.ExpiresIn,
+		"refreshExpiresIn": tokens.RefreshExpiresIn,
+		"user":             user,
+	})
+}
+
+// challenge 返回登录挑战，前端据此进入两步验证或修改密码步骤
+func (h *AuthHandler) challenge(c *gin.Context, user *model.User, purpose string) {
+	challengeToken, err := h.tokenService.IssueChallenge(user.ID, purpose)
+	if err != nil {
+		logger.Error("生成登录挑战失败: username=%s, error=%v", user.Username, err)
+		response.Failed(c, err)
+		return
+	}
+
+	logger.Info("用户登录需要进一步验证: username=%s, challenge=%s", user.Username, purpose)
 	response.Success(c, gin.H{
-		"token": token,
-		"user":  user,
+		"challenge":      purpose,
+		"challengeToken": challengeToken,
+		"expiresIn":      int64(service.LoginChallengeTTL.Seconds()),
 	})
 }
 
-// Logout 用户登出
+// Refresh 使用刷新令牌换取新的访问令牌，刷新令牌同时轮换
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refreshToken" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	tokens, err := h.tokenService.Refresh(req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		logger.Warn("刷新令牌失败: ip=%s, error=%v", c.ClientIP(), err)
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// Logout 用户登出，吊销当前访问令牌及登录会话
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// 前端应该清除token，后端不需要做特殊处理
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	if err := h.tokenService.Logout(claims); err != nil {
+		logger.Error("用户登出失败: userID=%d, error=%v", claims.UserID, err)
+		response.Failed(c, err)
+		return
+	}
+
+	logger.Info("用户登出: username=%s, userID=%d", claims.Username, claims.UserID)
+	response.Success(c, nil)
+}
+
+// LogoutAll 登出当前用户的全部会话
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	if err := h.tokenService.RevokeUserSessions(claims.UserID, model.SessionRevokeLogoutAll); err != nil {
+		logger.Error("登出全部会话失败: userID=%d, error=%v", claims.UserID, err)
+		response.Failed(c, err)
+		return
+	}
+
+	logger.Info("用户登出全部会话: username=%s, userID=%d", claims.Username, claims.UserID)
 	response.Success(c, nil)
 }
 
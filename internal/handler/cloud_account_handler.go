@@ -2,6 +2,8 @@ package handler
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/service"
 	"eden-ops/pkg/response"
 	"strconv"
@@ -23,8 +25,11 @@ func NewCloudAccountHandler(cloudAccountService service.CloudAccountService) *Cl
 
 // List 获取云账号列表
 func (h *CloudAccountHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	name := c.DefaultQuery("name", "")
 	providerIDStr := c.DefaultQuery("providerId", "")
 	statusStr := c.DefaultQuery("status", "")
@@ -43,13 +48,13 @@ func (h *CloudAccountHandler) List(c *gin.Context) {
 		}
 	}
 
-	accounts, total, err := h.cloudAccountService.ListWithFilter(page, pageSize, name, providerID, status)
+	accounts, total, err := h.cloudAccountService.ListWithFilter(q.Page, q.PageSize, name, providerID, status, middleware.GetDataScope(c))
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.PageSuccess(c, accounts, total)
+	response.ListSuccess(c, listquery.NewResult(accounts, total, q).PageData())
 }
 
 // Get 获取云账号详情
@@ -59,6 +64,10 @@ func (h *CloudAccountHandler) Get(c *gin.Context) {
 		response.BadRequest(c, "无效的云账号ID")
 		return
 	}
+	if !middleware.GetDataScope(c).AllowsCloudAccount(int64(id)) {
+		response.NotFound(c, "云账号不存在")
+		return
+	}
 
 	account, err := h.cloudAccountService.Get(uint(id))
 	if err != nil {
@@ -92,6 +101,10 @@ func (h *CloudAccountHandler) Update(c *gin.Context) {
 		response.BadRequest(c, "无效的云账号ID")
 		return
 	}
+	if !middleware.GetDataScope(c).AllowsCloudAccount(int64(id)) {
+		response.NotFound(c, "云账号不存在")
+		return
+	}
 
 	var account model.CloudAccount
 	if err := c.ShouldBindJSON(&account); err != nil {
@@ -115,6 +128,10 @@ func (h *CloudAccountHandler) Delete(c *gin.Context) {
 		response.BadRequest(c, "无效的云账号ID")
 		return
 	}
+	if !middleware.GetDataScope(c).AllowsCloudAccount(int64(id)) {
+		response.NotFound(c, "云账号不存在")
+		return
+	}
 
 	if err := h.cloudAccountService.Delete(uint(id)); err != nil {
 		response.Failed(c, err)
@@ -132,6 +149,11 @@ func (h *CloudAccountHandler) TestConnection(c *gin.Context) {
 		return
 	}
 
+	if account.ID != 0 && !middleware.GetDataScope(c).AllowsCloudAccount(account.ID) {
+		response.NotFound(c, "云账号不存在")
+		return
+	}
+
 	if err := h.cloudAccountService.TestConnection(&account); err != nil {
 		response.Failed(c, err)
 		return
@@ -139,3 +161,24 @@ func (h *CloudAccountHandler) TestConnection(c *gin.Context) {
 
 	response.Success(c, nil)
 }
+
+// ListRegions 获取云账号可用地域
+func (h *CloudAccountHandler) ListRegions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的云账号ID")
+		return
+	}
+	if !middleware.GetDataScope(c).AllowsCloudAccount(int64(id)) {
+		response.NotFound(c, "云账号不存在")
+		return
+	}
+
+	regions, err := h.cloudAccountService.ListRegions(uint(id))
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, regions)
+}
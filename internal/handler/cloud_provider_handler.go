@@ -2,6 +2,7 @@ package handler
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
 	"eden-ops/internal/service"
 	"eden-ops/pkg/response"
 	"strconv"
@@ -23,8 +24,11 @@ func NewCloudProviderHandler(cloudProviderService service.CloudProviderService)
 
 // List 获取云厂商列表
 func (h *CloudProviderHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	name := c.DefaultQuery("name", "")
 	status := c.DefaultQuery("status", "")
 
@@ -36,13 +40,13 @@ func (h *CloudProviderHandler) List(c *gin.Context) {
 		}
 	}
 
-	providers, total, err := h.cloudProviderService.List(page, pageSize, name, statusInt)
+	providers, total, err := h.cloudProviderService.List(q.Page, q.PageSize, name, statusInt)
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.PageSuccess(c, providers, total)
+	response.ListSuccess(c, listquery.NewResult(providers, total, q).PageData())
 }
 
 // Get 获取云厂商详情
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/repository"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cloudResourceHistoryListOptions 云资源历史记录按归档时间倒序，支持游标分页
+var cloudResourceHistoryListOptions = listquery.Options{
+	SortFields:   map[string]string{"archived_at": "archived_at"},
+	DefaultSort:  "archived_at",
+	DefaultOrder: "desc",
+	Cursor:       true,
+}
+
+// CloudResourceHandler 云资源清单处理器
+type CloudResourceHandler struct {
+	cloudResourceService service.CloudResourceService
+}
+
+// NewCloudResourceHandler 创建云资源清单处理器
+func NewCloudResourceHandler(cloudResourceService service.CloudResourceService) *CloudResourceHandler {
+	return &CloudResourceHandler{
+		cloudResourceService: cloudResourceService,
+	}
+}
+
+// parseFilter 解析云资源查询条件
+func (h *CloudResourceHandler) parseFilter(c *gin.Context) *repository.CloudResourceFilter {
+	accountID, _ := strconv.ParseInt(c.DefaultQuery("accountId", "0"), 10, 64)
+	return &repository.CloudResourceFilter{
+		AccountID:    accountID,
+		Provider:     c.Query("provider"),
+		Region:       c.Query("region"),
+		ResourceType: c.Query("resourceType"),
+		Status:       c.Query("status"),
+		Keyword:      c.Query("keyword"),
+		IP:           c.Query("ip"),
+		Tag:          c.Query("tag"),
+		Scope:        middleware.GetDataScope(c),
+	}
+}
+
+// List 获取云资源列表
+func (h *CloudResourceHandler) List(c *gin.Context) {
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	resources, total, err := h.cloudResourceService.List(q.Page, q.PageSize, h.parseFilter(c))
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.ListSuccess(c, listquery.NewResult(resources, total, q).PageData())
+}
+
+// Get 获取云资源详情
+func (h *CloudResourceHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的云资源ID")
+		return
+	}
+
+	resource, err := h.cloudResourceService.Get(id)
+	if err != nil || !middleware.GetDataScope(c).AllowsCloudAccount(resource.AccountID) {
+		response.NotFound(c, "云资源不存在")
+		return
+	}
+
+	response.Success(c, resource)
+}
+
+// SearchByIP 根据IP查找云资源
+func (h *CloudResourceHandler) SearchByIP(c *gin.Context) {
+	ip := c.Query("ip")
+	if net.ParseIP(ip) == nil {
+		response.BadRequest(c, "无效的IP地址")
+		return
+	}
+
+	resources, err := h.cloudResourceService.FindByIP(ip)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	scope := middleware.GetDataScope(c)
+	result := make([]*model.CloudResourceResponse, 0, len(resources))
+	for _, resource := range resources {
+		if scope.AllowsCloudAccount(resource.AccountID) {
+			result = append(result, resource)
+		}
+	}
+
+	response.Success(c, result)
+}
+
+// GetHistory 获取云资源历史记录
+func (h *CloudResourceHandler) GetHistory(c *gin.Context) {
+	q, err := listquery.Parse(c, cloudResourceHistoryListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	var startTime, endTime *time.Time
+	if startTimeStr := c.Query("startTime"); startTimeStr != "" {
+		if t, err := time.Parse("2006-01-02 15:04:05", startTimeStr); err == nil {
+			startTime = &t
+		}
+	}
+	if endTimeStr := c.Query("endTime"); endTimeStr != "" {
+		if t, err := time.Parse("2006-01-02 15:04:05", endTimeStr); err == nil {
+			endTime = &t
+		}
+	}
+
+	result, err := h.cloudResourceService.GetHistory(q, h.parseFilter(c), startTime, endTime)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.ListSuccess(c, result.PageData())
+}
+
+// Sync 手动触发云账号资源同步，同步在后台执行
+func (h *CloudResourceHandler) Sync(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的云账号ID")
+		return
+	}
+	if !middleware.GetDataScope(c).AllowsCloudAccount(id) {
+		response.NotFound(c, "云账号不存在")
+		return
+	}
+
+	go func() {
+		if err := h.cloudResourceService.SyncAccount(id); err != nil {
+			logger.Error("同步云账号 %d 资源失败: %v", id, err)
+		}
+	}()
+
+	response.Success(c, nil)
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/service"
 	"eden-ops/pkg/response"
 	"strconv"
@@ -23,17 +25,34 @@ func NewDatabaseConfigHandler(databaseConfigService service.DatabaseConfigServic
 
 // List 获取数据库配置列表
 func (h *DatabaseConfigHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	name := c.DefaultQuery("name", "")
 
-	total, configs, err := h.databaseConfigService.List(page, pageSize, name)
+	total, configs, err := h.databaseConfigService.List(q.Page, q.PageSize, name, middleware.GetDataScope(c))
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.PageSuccess(c, configs, total)
+	response.ListSuccess(c, listquery.NewResult(configs, total, q).PageData())
+}
+
+// Export 按名称导出数据库配置，不包含密码，支持 format、columns、raw 参数
+func (h *DatabaseConfigHandler) Export(c *gin.Context) {
+	req, err := parseExportRequest(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	name, scope := c.Query("name"), middleware.GetDataScope(c)
+	exportModels(c, "databases", req, func(fn func([]model.DatabaseConfig) error) error {
+		return h.databaseConfigService.Export(name, scope, fn)
+	})
 }
 
 // Get 获取数据库配置详情
@@ -45,7 +64,7 @@ func (h *DatabaseConfigHandler) Get(c *gin.Context) {
 	}
 
 	config, err := h.databaseConfigService.Get(uint(id))
-	if err != nil {
+	if err != nil || !middleware.GetDataScope(c).AllowsDatabase(int64(config.ID)) {
 		response.NotFound(c, "数据库配置不存在")
 		return
 	}
@@ -83,6 +102,11 @@ func (h *DatabaseConfigHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !middleware.GetDataScope(c).AllowsDatabase(int64(id)) {
+		response.NotFound(c, "数据库配置不存在")
+		return
+	}
+
 	config.ID = uint(id)
 	if err := h.databaseConfigService.Update(&config); err != nil {
 		response.Failed(c, err)
@@ -100,6 +124,11 @@ func (h *DatabaseConfigHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !middleware.GetDataScope(c).AllowsDatabase(int64(id)) {
+		response.NotFound(c, "数据库配置不存在")
+		return
+	}
+
 	if err := h.databaseConfigService.Delete(uint(id)); err != nil {
 		response.Failed(c, err)
 		return
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"eden-ops/internal/pkg/export"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// historyExportMaxRange 历史数据单次导出的最大时间跨度
+const historyExportMaxRange = 31 * 24 * time.Hour
+
+// exportRequest 导出参数：format 为 csv 或 xlsx，columns 为逗号分隔的列名（为空时导出全部列），
+// raw=true 时导出数据库中的原始值，否则导出与列表接口一致的格式化值
+type exportRequest struct {
+	format  export.Format
+	columns []string
+	raw     bool
+}
+
+// parseExportRequest 解析导出参数
+func parseExportRequest(c *gin.Context) (*exportRequest, error) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := strconv.ParseBool(c.DefaultQuery("raw", "false"))
+	return &exportRequest{format: format, columns: export.ParseColumns(c.Query("columns")), raw: raw}, nil
+}
+
+// exportModels 导出 each 遍历到的模型
+func exportModels[T any](c *gin.Context, name string, req *exportRequest, each func(func([]T) error) error) {
+	exportEach[T, T](c, name, req, each, nil)
+}
+
+// exportEach 导出 each 遍历到的记录，非 raw 模式下导出 toResponse 转换后的响应结构
+func exportEach[T any, R any](c *gin.Context, name string, req *exportRequest, each func(func([]T) error) error, toResponse func(*T) *R) {
+	formatted := !req.raw && toResponse != nil
+	var sample interface{} = new(T)
+	if formatted {
+		sample = new(R)
+	}
+	exportRows(c, name, req, sample, func(write func(interface{}) error) error {
+		return each(func(items []T) error {
+			for i := range items {
+				var row interface{} = &items[i]
+				if formatted {
+					row = toResponse(&items[i])
+				}
+				if err := write(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// exportRows 以附件形式流式输出 run 写入的行，sample 为行结构体，用于确定可导出的列。
+// 第一行数据写入时才输出响应头，遍历开始前的错误（如筛选条件无效）仍可以正常返回错误信息
+func exportRows(c *gin.Context, name string, req *exportRequest, sample interface{}, run func(write func(interface{}) error) error) {
+	table, err := export.NewTable(sample, req.columns, req.raw)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	var writer export.Writer
+	rows := 0
+	start := func() error {
+		filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102150405"), req.format.Extension())
+		c.Header("Content-Type", req.format.ContentType())
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Status(http.StatusOK)
+		w, err := export.NewWriter(req.format, c.Writer)
+		if err != nil {
+			return err
+		}
+		writer = w
+		return writer.WriteRow(table.Header())
+	}
+
+	err = run(func(row interface{}) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writer.WriteRow(table.Row(row)); err != nil {
+			return err
+		}
+		rows++
+		if rows%export.BatchSize == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil && writer == nil && !c.Writer.Written() {
+		respondExportError(c, err)
+		return
+	}
+	if err != nil {
+		// 响应已开始输出，导出中途出错时只能记录日志
+		logger.Error("导出%s失败: %v", name, err)
+		return
+	}
+
+	if writer == nil {
+		if err := start(); err != nil {
+			logger.Error("导出%s失败: %v", name, err)
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		logger.Error("导出%s失败: %v", name, err)
+	}
+}
+
+// respondExportError 输出开始导出前发生的错误
+func respondExportError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidLabelSelector) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	response.Failed(c, err)
+}
+
+// historyExportRange 解析历史数据导出的时间范围，startTime 和 endTime 必填，格式与历史列表相同，跨度不超过 historyExportMaxRange
+func historyExportRange(c *gin.Context) (time.Time, time.Time, error) {
+	startTime, err := time.Parse("2006-01-02 15:04:05", c.Query("startTime"))
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("startTime is required, use YYYY-MM-DD HH:mm:ss")
+	}
+	endTime, err := time.Parse("2006-01-02 15:04:05", c.Query("endTime"))
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("endTime is required, use YYYY-MM-DD HH:mm:ss")
+	}
+	if endTime.Before(startTime) {
+		return time.Time{}, time.Time{}, errors.New("endTime must not be before startTime")
+	}
+	if endTime.Sub(startTime) > historyExportMaxRange {
+		return time.Time{}, time.Time{}, fmt.Errorf("time range must not exceed %d days", int(historyExportMaxRange/(24*time.Hour)))
+	}
+	return startTime, endTime, nil
+}
@@ -1,43 +1,70 @@
 package handler
 
 import (
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/service"
 	"eden-ops/pkg/iplocator"
+	"eden-ops/pkg/logger"
 	"eden-ops/pkg/response"
-	"fmt"
-
-	"github.com/sirupsen/logrus"
+	"net"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 // IPLocatorHandler IP定位处理器
 type IPLocatorHandler struct {
-	ipLocator *iplocator.IPLocator
-	logger    *logrus.Logger
+	ipLocateService service.IPLocateService
+	geoLocator      *iplocator.IPLocator
 }
 
-// NewIPLocatorHandler 创建IP定位处理器
-func NewIPLocatorHandler(accessKey, secretKey, baseURL string, logger *logrus.Logger) *IPLocatorHandler {
+// NewIPLocatorHandler 创建IP定位处理器，geoLocator 为空时不查询公网IP的地理位置
+func NewIPLocatorHandler(ipLocateService service.IPLocateService, geoLocator *iplocator.IPLocator) *IPLocatorHandler {
 	return &IPLocatorHandler{
-		ipLocator: iplocator.NewIPLocator(accessKey, secretKey, baseURL),
-		logger:    logger,
+		ipLocateService: ipLocateService,
+		geoLocator:      geoLocator,
 	}
 }
 
-// Locate 定位IP地址
+// locateResponse IP定位响应
+type locateResponse struct {
+	*service.IPLocateResult
+	Geo *iplocator.IPInfo `json:"geo,omitempty"`
+}
+
+// Locate 查询IP归属
 func (h *IPLocatorHandler) Locate(c *gin.Context) {
-	ip := c.Param("ip")
+	ip := c.Query("ip")
 	if ip == "" {
-		response.Failed(c, fmt.Errorf("IP地址不能为空"))
+		ip = c.Param("ip")
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		response.BadRequest(c, "无效的IP地址")
 		return
 	}
 
-	result, err := h.ipLocator.Locate(ip)
+	accountID, _ := strconv.ParseInt(c.DefaultQuery("accountId", "0"), 10, 64)
+	result, err := h.ipLocateService.Locate(c.Request.Context(), &service.IPLocateRequest{
+		IP:        parsed.String(),
+		Live:      c.Query("live") == "true",
+		AccountID: accountID,
+		Region:    c.Query("region"),
+		Scope:     middleware.GetDataScope(c),
+	})
 	if err != nil {
-		h.logger.WithError(err).WithField("ip", ip).Error("IP定位失败")
 		response.Failed(c, err)
 		return
 	}
 
-	response.Success(c, result)
+	resp := &locateResponse{IPLocateResult: result}
+	if h.geoLocator != nil && !parsed.IsPrivate() && !parsed.IsLoopback() {
+		if geo, err := h.geoLocator.Locate(parsed.String()); err != nil {
+			logger.Warn("查询IP地理位置失败: %s, %v", ip, err)
+		} else {
+			resp.Geo = geo
+		}
+	}
+
+	response.Success(c, resp)
 }
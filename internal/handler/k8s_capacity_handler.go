@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// capacitySummaryListOptions 每周汇总按周倒序
+var capacitySummaryListOptions = listquery.Options{
+	DefaultPageSize: 12,
+	SortFields:      map[string]string{"weekStart": "week_start"},
+	DefaultSort:     "weekStart",
+	DefaultOrder:    "desc",
+	Cursor:          true,
+}
+
+// K8sCapacityHandler K8s容量规划处理器
+type K8sCapacityHandler struct {
+	capacityService service.K8sCapacityService
+}
+
+// NewK8sCapacityHandler 创建K8s容量规划处理器
+func NewK8sCapacityHandler(capacityService service.K8sCapacityService) *K8sCapacityHandler {
+	return &K8sCapacityHandler{capacityService: capacityService}
+}
+
+// Report 集群或命名空间的容量报告，includeUsage=true 时从 metrics-server 获取用量并给出调整建议
+func (h *K8sCapacityHandler) Report(c *gin.Context) {
+	configID, err := strconv.ParseInt(c.Param("configId"), 10, 64)
+	if err != nil || configID <= 0 {
+		response.BadRequest(c, "无效的集群ID")
+		return
+	}
+	namespace := c.Query("namespace")
+	scope := middleware.GetDataScope(c)
+	if !scope.AllowsCluster(configID) || namespace != "" && !scope.AllowsNamespace(configID, namespace) {
+		response.NotFound(c, "集群不存在")
+		return
+	}
+	includeUsage, _ := strconv.ParseBool(c.Query("includeUsage"))
+
+	report, err := h.capacityService.Report(&service.K8sCapacityQuery{
+		ConfigID:     configID,
+		Namespace:    namespace,
+		IncludeUsage: includeUsage,
+	}, scope)
+	if errors.Is(err, service.ErrInvalidK8sCapacityQuery) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+// ListSummaries 集群的每周容量汇总，汇总包含全部命名空间，需要整个集群的数据范围
+func (h *K8sCapacityHandler) ListSummaries(c *gin.Context) {
+	configID, err := strconv.ParseInt(c.Param("configId"), 10, 64)
+	if err != nil || configID <= 0 {
+		response.BadRequest(c, "无效的集群ID")
+		return
+	}
+	if !middleware.GetDataScope(c).AllowsWholeCluster(configID) {
+		response.NotFound(c, "集群不存在")
+		return
+	}
+	q, err := listquery.Parse(c, capacitySummaryListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.capacityService.ListSummaries(configID, q)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+	response.ListSuccess(c, result.PageData())
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/repository"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// changeEventListOptions 变更事件按发现时间倒序，支持游标分页
+var changeEventListOptions = listquery.Options{
+	DefaultPageSize: 20,
+	SortFields:      map[string]string{"observedAt": "observed_at"},
+	DefaultSort:     "observedAt",
+	DefaultOrder:    "desc",
+	Cursor:          true,
+}
+
+// K8sChangeEventHandler K8s资源变更事件处理器
+type K8sChangeEventHandler struct {
+	changeService service.K8sChangeEventService
+}
+
+// NewK8sChangeEventHandler 创建K8s资源变更事件处理器
+func NewK8sChangeEventHandler(changeService service.K8sChangeEventService) *K8sChangeEventHandler {
+	return &K8sChangeEventHandler{changeService: changeService}
+}
+
+// Timeline 单个对象的变更时间线，需指定 configId、kind、name，Pod和工作负载还需指定 namespace
+func (h *K8sChangeEventHandler) Timeline(c *gin.Context) {
+	filter, err := k8sChangeEventFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if filter.StartTime, err = parseAuditTime(c.Query("startTime")); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if filter.EndTime, err = parseAuditTime(c.Query("endTime")); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	q, err := listquery.Parse(c, changeEventListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	result, err := h.changeService.Timeline(filter, q, middleware.GetDataScope(c))
+	if errors.Is(err, service.ErrInvalidK8sChangeQuery) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+	response.ListSuccess(c, result.PageData())
+}
+
+// Recent 最近的变更，window 为时间范围（如 30m、1h），默认最近一小时
+func (h *K8sChangeEventHandler) Recent(c *gin.Context) {
+	filter, err := k8sChangeEventFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	window := service.DefaultK8sChangeFeedWindow
+	if value := c.Query("window"); value != "" {
+		if window, err = time.ParseDuration(value); err != nil || window <= 0 {
+			response.BadRequest(c, "无效的时间范围")
+			return
+		}
+	}
+
+	q, err := listquery.Parse(c, changeEventListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	result, err := h.changeService.Recent(filter, window, q, middleware.GetDataScope(c))
+	if errors.Is(err, service.ErrInvalidK8sChangeQuery) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+	response.ListSuccess(c, result.PageData())
+}
+
+// k8sChangeEventFilter 解析变更事件的公共查询条件
+func k8sChangeEventFilter(c *gin.Context) (*repository.K8sChangeEventFilter, error) {
+	filter := &repository.K8sChangeEventFilter{
+		Kind:         c.Query("kind"),
+		WorkloadKind: c.Query("workloadKind"),
+		Namespace:    c.Query("namespace"),
+		Name:         c.Query("name"),
+		Action:       c.Query("action"),
+		Field:        c.Query("field"),
+	}
+	if configID := c.Query("configId"); configID != "" {
+		id, err := strconv.ParseInt(configID, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, errors.New("无效的集群ID")
+		}
+		filter.ConfigID = id
+	}
+	return filter, nil
+}
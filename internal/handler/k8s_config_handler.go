@@ -2,6 +2,8 @@ package handler
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/service"
 	"eden-ops/pkg/response"
 	"strconv"
@@ -23,8 +25,11 @@ func NewK8sConfigHandler(k8sConfigService service.K8sConfigService) *K8sConfigHa
 
 // List 获取Kubernetes配置列表
 func (h *K8sConfigHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	name := c.Query("name")
 
 	var status *int
@@ -43,19 +48,22 @@ func (h *K8sConfigHandler) List(c *gin.Context) {
 
 	clusterID := c.Query("clusterID")
 
-	configs, total, err := h.k8sConfigService.List(page, pageSize, name, status, providerId, clusterID)
+	configs, total, err := h.k8sConfigService.List(q.Page, q.PageSize, name, status, providerId, clusterID, middleware.GetDataScope(c))
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.PageSuccess(c, configs, total)
+	response.ListSuccess(c, listquery.NewResult(configs, total, q).PageData())
 }
 
 // ListWithWorkloadCount 获取Kubernetes配置列表（包含工作负载统计）
 func (h *K8sConfigHandler) ListWithWorkloadCount(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	name := c.Query("name")
 
 	var status *int
@@ -74,13 +82,36 @@ func (h *K8sConfigHandler) ListWithWorkloadCount(c *gin.Context) {
 
 	clusterID := c.Query("clusterID")
 
-	configs, total, err := h.k8sConfigService.ListWithWorkloadCount(page, pageSize, name, status, providerId, clusterID)
+	configs, total, err := h.k8sConfigService.ListWithWorkloadCount(q.Page, q.PageSize, name, status, providerId, clusterID, middleware.GetDataScope(c))
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.PageSuccess(c, configs, total)
+	response.ListSuccess(c, listquery.NewResult(configs, total, q).PageData())
+}
+
+// Export 按列表的筛选条件导出Kubernetes集群，不包含 kubeconfig，支持 format、columns、raw 参数
+func (h *K8sConfigHandler) Export(c *gin.Context) {
+	req, err := parseExportRequest(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	var status *int
+	if s, err := strconv.Atoi(c.Query("status")); err == nil {
+		status = &s
+	}
+	var providerId *int64
+	if p, err := strconv.ParseInt(c.Query("providerId"), 10, 64); err == nil {
+		providerId = &p
+	}
+
+	name, clusterID, scope := c.Query("name"), c.Query("clusterID"), middleware.GetDataScope(c)
+	exportEach(c, "k8s-clusters", req, func(fn func([]model.K8sConfig) error) error {
+		return h.k8sConfigService.Export(name, status, providerId, clusterID, scope, fn)
+	}, (*model.K8sConfig).ToResponse)
 }
 
 // Get 获取Kubernetes配置详情
@@ -92,7 +123,7 @@ func (h *K8sConfigHandler) Get(c *gin.Context) {
 	}
 
 	config, err := h.k8sConfigService.Get(uint(id))
-	if err != nil {
+	if err != nil || !middleware.GetDataScope(c).AllowsCluster(config.ID) {
 		response.NotFound(c, "Kubernetes配置不存在")
 		return
 	}
@@ -130,6 +161,11 @@ func (h *K8sConfigHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !middleware.GetDataScope(c).AllowsWholeCluster(int64(id)) {
+		response.NotFound(c, "Kubernetes配置不存在")
+		return
+	}
+
 	config.ID = int64(id)
 	if err := h.k8sConfigService.UpdateWithClusterInfo(&config); err != nil {
 		response.Failed(c, err)
@@ -147,6 +183,11 @@ func (h *K8sConfigHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !middleware.GetDataScope(c).AllowsWholeCluster(int64(id)) {
+		response.NotFound(c, "Kubernetes配置不存在")
+		return
+	}
+
 	if err := h.k8sConfigService.Delete(uint(id)); err != nil {
 		response.Failed(c, err)
 		return
@@ -178,12 +219,18 @@ func (h *K8sConfigHandler) GetNamespaces(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	scope := middleware.GetDataScope(c)
+	if !scope.AllowsCluster(id) {
+		response.NotFound(c, "Kubernetes配置不存在")
+		return
+	}
 
 	namespaces, err := h.k8sConfigService.GetNamespaces(id)
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
+	namespaces = filterNamespaces(scope, id, namespaces)
 
 	response.Success(c, namespaces)
 }
@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/repository"
+	"eden-ops/internal/service"
 	"eden-ops/pkg/logger"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -18,13 +22,15 @@ type K8sHistoryHandler struct {
 	podHistoryRepo         repository.K8sPodHistoryRepository
 	nodeHistoryRepo        repository.K8sNodeHistoryRepository
 	workloadHistoryRepo    repository.K8sWorkloadHistoryRepository
+	cleanupService         *service.K8sHistoryCleanupService
 }
 
 // NewK8sHistoryHandler 创建K8s历史数据处理器
 func NewK8sHistoryHandler(
 	podHistoryRepo repository.K8sPodHistoryRepository,
 	nodeHistoryRepo repository.K8sNodeHistoryRepository,
-	workloadHistoryRepo repository.K8sWorkloadHistoryRepository) *K8sHistoryHandler {
+	workloadHistoryRepo repository.K8sWorkloadHistoryRepository,
+	cleanupService *service.K8sHistoryCleanupService) *K8sHistoryHandler {
 	return &K8sHistoryHandler{
 		podHistoryHandler:      NewK8sPodHistoryHandler(podHistoryRepo),
 		nodeHistoryHandler:     NewK8sNodeHistoryHandler(nodeHistoryRepo),
@@ -32,6 +38,7 @@ func NewK8sHistoryHandler(
 		podHistoryRepo:         podHistoryRepo,
 		nodeHistoryRepo:        nodeHistoryRepo,
 		workloadHistoryRepo:    workloadHistoryRepo,
+		cleanupService:         cleanupService,
 	}
 }
 
@@ -50,112 +57,282 @@ func (h *K8sHistoryHandler) GetWorkloadHistory(c *gin.Context) {
 	h.workloadHistoryHandler.GetWorkloadHistory(c)
 }
 
-// CleanupHistory 手动清理历史数据
+// ExportPodHistory 导出Pod历史记录
+func (h *K8sHistoryHandler) ExportPodHistory(c *gin.Context) {
+	h.podHistoryHandler.ExportPodHistory(c)
+}
+
+// ExportNodeHistory 导出Node历史记录
+func (h *K8sHistoryHandler) ExportNodeHistory(c *gin.Context) {
+	h.nodeHistoryHandler.ExportNodeHistory(c)
+}
+
+// ExportWorkloadHistory 导出Workload历史记录
+func (h *K8sHistoryHandler) ExportWorkloadHistory(c *gin.Context) {
+	h.workloadHistoryHandler.ExportWorkloadHistory(c)
+}
+
+// CleanupHistory 手动清理历史数据，指定 configId 时只清理该集群，不受保留策略限制
 func (h *K8sHistoryHandler) CleanupHistory(c *gin.Context) {
+	if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow cleanup"})
+		return
+	}
+
 	var req struct {
 		BeforeDate string `json:"beforeDate" binding:"required"`
+		ConfigID   int64  `json:"configId"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.ConfigID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config ID"})
+		return
+	}
 
 	beforeDate, err := time.Parse("2006-01-02", req.BeforeDate)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
 		return
 	}
+	// 不允许清理最近一天内的数据，避免误删刚同步的历史
+	if beforeDate.After(time.Now().AddDate(0, 0, -1)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "beforeDate must be at least 1 day ago"})
+		return
+	}
 
-	// 清理Pod历史
-	if err := h.podHistoryRepo.CleanupPodHistory(beforeDate); err != nil {
-		logger.Error("清理Pod历史数据失败: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cleanup pod history"})
+	_, operator, _ := currentUser(c)
+	run, err := h.cleanupService.ManualCleanup(req.ConfigID, beforeDate, operator)
+	if err != nil {
+		logger.Error("手动清理历史数据失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "data": run})
 		return
 	}
 
-	// 清理Node历史
-	if err := h.nodeHistoryRepo.CleanupNodeHistory(beforeDate); err != nil {
-		logger.Error("清理Node历史数据失败: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cleanup node history"})
+	logger.Info("手动清理历史数据成功，清理 %s 之前的数据", beforeDate.Format("2006-01-02"))
+	c.JSON(http.StatusOK, gin.H{"message": "History cleanup completed successfully", "data": run})
+}
+
+// GetHistoryStatistics 获取历史数据统计：各历史表的行数、归档时间范围、表空间和最近的清理记录。
+// 不指定集群时统计全部集群并按集群分组，仅数据范围不受限的用户可用
+func (h *K8sHistoryHandler) GetHistoryStatistics(c *gin.Context) {
+	var configID int64
+	if configIDStr := c.Param("configId"); configIDStr != "" {
+		id, err := strconv.ParseInt(configIDStr, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config ID"})
+			return
+		}
+		if !middleware.GetDataScope(c).AllowsWholeCluster(id) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+			return
+		}
+		configID = id
+	} else if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow statistics of all clusters"})
 		return
 	}
 
-	// 清理Workload历史
-	if err := h.workloadHistoryRepo.CleanupWorkloadHistory(beforeDate); err != nil {
-		logger.Error("清理Workload历史数据失败: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cleanup workload history"})
+	statistics, err := h.cleanupService.GetHistoryStatistics(configID)
+	if err != nil {
+		logger.Error("获取历史数据统计失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get history statistics"})
 		return
 	}
 
-	logger.Info("手动清理历史数据成功，清理 %s 之前的数据", beforeDate.Format("2006-01-02"))
-	c.JSON(http.StatusOK, gin.H{"message": "History cleanup completed successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"data": statistics,
+	})
 }
 
-// GetHistoryStatistics 获取历史数据统计
-func (h *K8sHistoryHandler) GetHistoryStatistics(c *gin.Context) {
-	configIDStr := c.Param("configId")
-	configID, err := strconv.ParseInt(configIDStr, 10, 64)
+// retentionPolicyRequest 保留策略请求
+type retentionPolicyRequest struct {
+	ConfigID      int64  `json:"configId"`
+	Kind          string `json:"kind" binding:"required"`
+	RetentionDays *int   `json:"retentionDays" binding:"required"`
+	Remark        string `json:"remark"`
+}
+
+// ListRetentionPolicies 获取历史数据保留策略
+func (h *K8sHistoryHandler) ListRetentionPolicies(c *gin.Context) {
+	policies, err := h.cleanupService.ListRetentionPolicies()
 	if err != nil {
+		logger.Error("获取保留策略失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list retention policies"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": policies})
+}
+
+// GetRetention 获取集群各类历史数据实际生效的保留天数
+func (h *K8sHistoryHandler) GetRetention(c *gin.Context) {
+	configID, err := strconv.ParseInt(c.Param("configId"), 10, 64)
+	if err != nil || configID <= 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config ID"})
 		return
 	}
+	if !middleware.GetDataScope(c).AllowsWholeCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
 
-	// 获取各类型历史数据的统计
-	podTotal, err := h.podHistoryRepo.CountPodHistory(configID)
+	retention, err := h.cleanupService.GetRetention(configID)
 	if err != nil {
-		logger.Error("获取Pod历史统计失败: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pod history statistics"})
+		logger.Error("获取保留天数失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get retention"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": retention})
+}
+
+// CreateRetentionPolicy 创建保留策略
+func (h *K8sHistoryHandler) CreateRetentionPolicy(c *gin.Context) {
+	if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow editing retention policies"})
 		return
 	}
 
-	nodeTotal, err := h.nodeHistoryRepo.CountNodeHistory(configID)
+	var req retentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := &model.K8sHistoryRetentionPolicy{
+		ConfigID:      req.ConfigID,
+		Kind:          req.Kind,
+		RetentionDays: *req.RetentionDays,
+		Remark:        req.Remark,
+	}
+	if err := h.cleanupService.CreateRetentionPolicy(policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": policy})
+}
+
+// UpdateRetentionPolicy 更新保留策略的保留天数和备注
+func (h *K8sHistoryHandler) UpdateRetentionPolicy(c *gin.Context) {
+	if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow editing retention policies"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		logger.Error("获取Node历史统计失败: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get node history statistics"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+	var req struct {
+		RetentionDays *int   `json:"retentionDays" binding:"required"`
+		Remark        string `json:"remark"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	workloadTotal, err := h.workloadHistoryRepo.CountWorkloadHistory(configID)
+	policy, err := h.cleanupService.UpdateRetentionPolicy(uint(id), *req.RetentionDays, req.Remark)
 	if err != nil {
-		logger.Error("获取Workload历史统计失败: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workload history statistics"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"data": policy})
+}
 
-	// 获取最新的归档时间
-	var lastArchivedAt *time.Time
-	
-	// 获取Pod最新归档时间
-	podHistories, _, err := h.podHistoryRepo.GetPodHistory(configID, 1, 1, nil, nil)
-	if err == nil && len(podHistories) > 0 {
-		lastArchivedAt = &podHistories[0].ArchivedAt
+// DeleteRetentionPolicy 删除保留策略，删除后回退到上一级策略
+func (h *K8sHistoryHandler) DeleteRetentionPolicy(c *gin.Context) {
+	if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow editing retention policies"})
+		return
 	}
-	
-	// 获取Node最新归档时间
-	nodeHistories, _, err := h.nodeHistoryRepo.GetNodeHistory(configID, 1, 1, nil, nil)
-	if err == nil && len(nodeHistories) > 0 && (lastArchivedAt == nil || nodeHistories[0].ArchivedAt.After(*lastArchivedAt)) {
-		lastArchivedAt = &nodeHistories[0].ArchivedAt
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
 	}
-	
-	// 获取Workload最新归档时间
-	workloadHistories, _, err := h.workloadHistoryRepo.GetWorkloadHistory(configID, 1, 1, nil, nil)
-	if err == nil && len(workloadHistories) > 0 && (lastArchivedAt == nil || workloadHistories[0].ArchivedAt.After(*lastArchivedAt)) {
-		lastArchivedAt = &workloadHistories[0].ArchivedAt
+	if err := h.cleanupService.DeleteRetentionPolicy(uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+	c.JSON(http.StatusOK, gin.H{"message": "Retention policy deleted"})
+}
 
-	statistics := gin.H{
-		"podHistoryCount":      podTotal,
-		"nodeHistoryCount":     nodeTotal,
-		"workloadHistoryCount": workloadTotal,
-		"totalHistoryCount":    podTotal + nodeTotal + workloadTotal,
+// ListArchives 列出归档文件，可按类型、集群和日期范围（YYYY-MM-DD）过滤
+func (h *K8sHistoryHandler) ListArchives(c *gin.Context) {
+	if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow listing archives"})
+		return
+	}
+	archiver := h.cleanupService.Archiver()
+	if archiver == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "History archive is not enabled"})
+		return
 	}
 
-	if lastArchivedAt != nil {
-		statistics["lastArchivedAt"] = lastArchivedAt.Format("2006-01-02 15:04:05")
+	var configID int64
+	if configIDStr := c.Query("configId"); configIDStr != "" {
+		id, err := strconv.ParseInt(configIDStr, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config ID"})
+			return
+		}
+		configID = id
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": statistics,
-	})
+	files, err := archiver.ListArchives(c.Request.Context(), c.Query("kind"), configID, c.Query("startDate"), c.Query("endDate"))
+	if errors.Is(err, service.ErrInvalidArchiveQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logger.Error("列出历史数据归档文件失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": files})
+}
+
+// RestoreArchive 将某个集群在日期范围内归档的历史数据写回历史表，已存在的记录会被跳过
+func (h *K8sHistoryHandler) RestoreArchive(c *gin.Context) {
+	if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow restoring archives"})
+		return
+	}
+	archiver := h.cleanupService.Archiver()
+	if archiver == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "History archive is not enabled"})
+		return
+	}
+
+	var req struct {
+		ConfigID  int64  `json:"configId" binding:"required"`
+		Kind      string `json:"kind"`
+		StartDate string `json:"startDate" binding:"required"`
+		EndDate   string `json:"endDate" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := archiver.Restore(c.Request.Context(), req.Kind, req.ConfigID, req.StartDate, req.EndDate)
+	if errors.Is(err, service.ErrInvalidArchiveQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logger.Error("恢复历史数据归档失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "data": result})
+		return
+	}
+
+	_, operator, _ := currentUser(c)
+	logger.Info("%s 恢复了集群 %d 在 %s 至 %s 的历史数据归档，写回 %d 行",
+		operator, req.ConfigID, req.StartDate, req.EndDate, result.Restored)
+	c.JSON(http.StatusOK, gin.H{"message": "History archive restored", "data": result})
 }
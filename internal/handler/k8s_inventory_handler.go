@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/repository"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inventoryListOptions 清单常用于复盘时整体查看，单页上限比普通列表大
+var inventoryListOptions = listquery.Options{DefaultPageSize: 100, MaxPageSize: 500}
+
+// K8sInventoryHandler K8s时间点清单处理器
+type K8sInventoryHandler struct {
+	inventoryService service.K8sInventoryService
+}
+
+// NewK8sInventoryHandler 创建K8s时间点清单处理器
+func NewK8sInventoryHandler(inventoryService service.K8sInventoryService) *K8sInventoryHandler {
+	return &K8sInventoryHandler{inventoryService: inventoryService}
+}
+
+// PodsAt 某一时间点存在的Pod，可按命名空间、名称、节点、工作负载过滤
+func (h *K8sInventoryHandler) PodsAt(c *gin.Context) {
+	filter, err := k8sInventoryFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	q, err := listquery.Parse(c, inventoryListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	pods, total, err := h.inventoryService.PodsAt(filter, q.Page, q.PageSize, middleware.GetDataScope(c))
+	respondInventory(c, q, pods, total, err)
+}
+
+// NodesAt 某一时间点存在的节点
+func (h *K8sInventoryHandler) NodesAt(c *gin.Context) {
+	filter, err := k8sInventoryFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	q, err := listquery.Parse(c, inventoryListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	nodes, total, err := h.inventoryService.NodesAt(filter, q.Page, q.PageSize, middleware.GetDataScope(c))
+	respondInventory(c, q, nodes, total, err)
+}
+
+// WorkloadsAt 某一时间点存在的工作负载
+func (h *K8sInventoryHandler) WorkloadsAt(c *gin.Context) {
+	filter, err := k8sInventoryFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	q, err := listquery.Parse(c, inventoryListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	workloads, total, err := h.inventoryService.WorkloadsAt(filter, q.Page, q.PageSize, middleware.GetDataScope(c))
+	respondInventory(c, q, workloads, total, err)
+}
+
+// k8sInventoryFilter 解析集群ID、时间点 at 和过滤条件
+func k8sInventoryFilter(c *gin.Context) (*repository.K8sInventoryFilter, error) {
+	configID, err := strconv.ParseInt(c.Param("configId"), 10, 64)
+	if err != nil || configID <= 0 {
+		return nil, errors.New("无效的集群ID")
+	}
+	at, err := parseAuditTime(c.Query("at"))
+	if err != nil {
+		return nil, err
+	}
+	filter := &repository.K8sInventoryFilter{
+		ConfigID:     configID,
+		Namespace:    c.Query("namespace"),
+		Name:         c.Query("name"),
+		NodeName:     c.Query("nodeName"),
+		WorkloadName: c.Query("workloadName"),
+		WorkloadKind: c.Query("workloadKind"),
+	}
+	if at != nil {
+		filter.At = *at
+	}
+	return filter, nil
+}
+
+// respondInventory 输出清单查询结果
+func respondInventory[T any](c *gin.Context, q *listquery.Query, items []T, total int64, err error) {
+	if errors.Is(err, service.ErrInvalidK8sInventoryQuery) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+	response.ListSuccess(c, listquery.NewResult(items, total, q).PageData())
+}
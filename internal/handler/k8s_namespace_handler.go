@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/repository"
 	"net/http"
 	"strconv"
@@ -40,6 +42,16 @@ func (h *K8sNamespaceHandler) GetNamespacesByConfigID(c *gin.Context) {
 		return
 	}
 
+	scope := middleware.GetDataScope(c)
+	if !scope.AllowsCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "config not found",
+			"data":    nil,
+		})
+		return
+	}
+
 	namespaces, err := h.repo.GetByConfigID(configID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -53,7 +65,9 @@ func (h *K8sNamespaceHandler) GetNamespacesByConfigID(c *gin.Context) {
 	// 转换为响应格式
 	var result []string
 	for _, ns := range namespaces {
-		result = append(result, ns.Namespace)
+		if scope.AllowsNamespace(configID, ns.Namespace) {
+			result = append(result, ns.Namespace)
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -62,3 +76,17 @@ func (h *K8sNamespaceHandler) GetNamespacesByConfigID(c *gin.Context) {
 		"data":    result,
 	})
 }
+
+// filterNamespaces 过滤出数据范围内的命名空间
+func filterNamespaces(scope *model.DataScope, configID int64, namespaces []string) []string {
+	if scope.AllowsWholeCluster(configID) {
+		return namespaces
+	}
+	result := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		if scope.AllowsNamespace(configID, ns) {
+			result = append(result, ns)
+		}
+	}
+	return result
+}
@@ -1,13 +1,30 @@
 package handler
 
 import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/repository"
 	"eden-ops/internal/service"
+	"eden-ops/pkg/response"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
+// nodeListOptions 节点列表默认按创建时间倒序
+var nodeListOptions = listquery.Options{
+	SortFields: map[string]string{
+		"name":      "name",
+		"status":    "status",
+		"createdAt": "created_at",
+	},
+	DefaultSort:  "createdAt",
+	DefaultOrder: "desc",
+}
+
 // K8sNodeHandler 节点处理器
 type K8sNodeHandler struct {
 	nodeService service.K8sNodeService
@@ -22,8 +39,14 @@ func NewK8sNodeHandler(nodeService service.K8sNodeService) *K8sNodeHandler {
 
 // List 获取节点列表
 func (h *K8sNodeHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, nodeListOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
 
 	configID, _ := strconv.ParseInt(c.Query("configId"), 10, 64)
 	name := c.Query("name")
@@ -37,7 +60,14 @@ func (h *K8sNodeHandler) List(c *gin.Context) {
 		}
 	}
 
-	nodes, total, err := h.nodeService.List(page, pageSize, configID, name, internalIP, status, ready)
+	result, err := h.nodeService.List(q, configID, name, internalIP, status, ready, c.Query("labelSelector"), middleware.GetDataScope(c))
+	if errors.Is(err, service.ErrInvalidLabelSelector) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -47,16 +77,35 @@ func (h *K8sNodeHandler) List(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"code":    0,
-		"message": "success",
-		"data": gin.H{
-			"list":     nodes,
-			"total":    total,
-			"page":     page,
-			"pageSize": pageSize,
-		},
-	})
+	response.ListSuccess(c, result.PageData())
+}
+
+// Export 按列表的筛选条件导出节点，支持 format、columns、raw 参数
+func (h *K8sNodeHandler) Export(c *gin.Context) {
+	req, err := parseExportRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	configID, _ := strconv.ParseInt(c.Query("configId"), 10, 64)
+	filter := &repository.K8sNodeFilter{
+		ConfigID:   configID,
+		Name:       c.Query("name"),
+		InternalIP: c.Query("internalIP"),
+		Status:     c.Query("status"),
+	}
+	if ready, err := strconv.ParseBool(c.Query("ready")); err == nil {
+		filter.Ready = &ready
+	}
+
+	scope := middleware.GetDataScope(c)
+	exportEach(c, "k8s-nodes", req, func(fn func([]model.K8sNode) error) error {
+		return h.nodeService.Export(filter, c.Query("labelSelector"), scope, fn)
+	}, (*model.K8sNode).ToResponse)
 }
 
 // GetByID 根据ID获取节点详情
@@ -71,7 +120,7 @@ func (h *K8sNodeHandler) GetByID(c *gin.Context) {
 	}
 
 	node, err := h.nodeService.GetByID(id)
-	if err != nil {
+	if err != nil || !middleware.GetDataScope(c).AllowsCluster(node.ConfigID) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"code":    404,
 			"message": "节点不存在",
@@ -97,6 +146,15 @@ func (h *K8sNodeHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	node, err := h.nodeService.GetByID(id)
+	if err != nil || !middleware.GetDataScope(c).AllowsWholeCluster(node.ConfigID) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "节点不存在",
+		})
+		return
+	}
+
 	err = h.nodeService.Delete(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/export"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/repository"
 	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +16,19 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// nodeHistoryListOptions Node历史记录默认按归档时间倒序，支持游标分页
+var nodeHistoryListOptions = listquery.Options{
+	SortFields: map[string]string{
+		"name":        "name",
+		"status":      "status",
+		"created_at":  "created_at",
+		"archived_at": "archived_at",
+	},
+	DefaultSort:  "archived_at",
+	DefaultOrder: "desc",
+	Cursor:       true,
+}
+
 // K8sNodeHistoryHandler Node历史数据处理器
 type K8sNodeHistoryHandler struct {
 	nodeHistoryRepo repository.K8sNodeHistoryRepository
@@ -31,8 +50,16 @@ func (h *K8sNodeHistoryHandler) GetNodeHistory(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if !middleware.GetDataScope(c).AllowsCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
+
+	q, err := listquery.Parse(c, nodeHistoryListOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// 解析时间范围
 	var startTime, endTime *time.Time
@@ -47,23 +74,54 @@ func (h *K8sNodeHistoryHandler) GetNodeHistory(c *gin.Context) {
 		}
 	}
 
-	histories, total, err := h.nodeHistoryRepo.GetNodeHistory(configID, page, pageSize, startTime, endTime)
+	result, err := h.nodeHistoryRepo.GetNodeHistory(configID, q, startTime, endTime)
 	if err != nil {
 		logger.Error("获取Node历史记录失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get node history"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":     histories,
-		"total":    total,
-		"page":     page,
-		"pageSize": pageSize,
+	response.ListSuccess(c, result.PageData())
+}
+
+// ExportNodeHistory 导出归档时间在 startTime 到 endTime 之间的Node历史记录，时间范围必填，支持 format、columns、raw 参数
+func (h *K8sNodeHistoryHandler) ExportNodeHistory(c *gin.Context) {
+	configID, err := strconv.ParseInt(c.Param("configId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config ID"})
+		return
+	}
+
+	scope := middleware.GetDataScope(c)
+	if !scope.AllowsCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
+
+	startTime, endTime, err := historyExportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req, err := parseExportRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := fmt.Sprintf("k8s-node-history-%d", configID)
+	exportModels(c, name, req, func(fn func([]model.K8sNodeHistory) error) error {
+		return h.nodeHistoryRepo.EachNodeHistory(configID, startTime, endTime, export.MaxRows, export.BatchSize, fn)
 	})
 }
 
 // CleanupNodeHistory 清理Node历史数据
 func (h *K8sNodeHistoryHandler) CleanupNodeHistory(c *gin.Context) {
+	if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow cleanup"})
+		return
+	}
+
 	var req struct {
 		BeforeDate string `json:"beforeDate" binding:"required"`
 	}
@@ -79,15 +137,15 @@ func (h *K8sNodeHistoryHandler) CleanupNodeHistory(c *gin.Context) {
 		return
 	}
 
-	err = h.nodeHistoryRepo.CleanupNodeHistory(beforeDate)
+	deleted, err := h.nodeHistoryRepo.CleanupNodeHistory(c.Request.Context(), 0, beforeDate, repository.BatchDeleteOptions{})
 	if err != nil {
 		logger.Error("清理Node历史数据失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cleanup node history"})
 		return
 	}
 
-	logger.Info("手动清理Node历史数据成功，清理 %s 之前的数据", beforeDate.Format("2006-01-02"))
-	c.JSON(http.StatusOK, gin.H{"message": "Node history cleanup completed successfully"})
+	logger.Info("手动清理Node历史数据成功，清理 %s 之前的数据，共 %d 行", beforeDate.Format("2006-01-02"), deleted)
+	c.JSON(http.StatusOK, gin.H{"message": "Node history cleanup completed successfully", "deleted": deleted})
 }
 
 // GetNodeHistoryStatistics 获取Node历史数据统计
@@ -99,8 +157,15 @@ func (h *K8sNodeHistoryHandler) GetNodeHistoryStatistics(c *gin.Context) {
 		return
 	}
 
+	if !middleware.GetDataScope(c).AllowsWholeCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
+
 	// 获取Node历史数据统计
-	nodeHistories, nodeTotal, err := h.nodeHistoryRepo.GetNodeHistory(configID, 1, 1, nil, nil)
+	latest := listquery.New(nodeHistoryListOptions)
+	latest.PageSize = 1
+	result, err := h.nodeHistoryRepo.GetNodeHistory(configID, latest, nil, nil)
 	if err != nil {
 		logger.Error("获取Node历史统计失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get node history statistics"})
@@ -108,12 +173,12 @@ func (h *K8sNodeHistoryHandler) GetNodeHistoryStatistics(c *gin.Context) {
 	}
 
 	statistics := gin.H{
-		"nodeHistoryCount": nodeTotal,
+		"nodeHistoryCount": result.Total,
 	}
 
 	// 获取最新的归档时间
-	if len(nodeHistories) > 0 {
-		statistics["lastArchivedAt"] = nodeHistories[0].ArchivedAt.Format("2006-01-02 15:04:05")
+	if len(result.List) > 0 {
+		statistics["lastArchivedAt"] = result.List[0].ArchivedAt.Format("2006-01-02 15:04:05")
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -1,13 +1,32 @@
 package handler
 
 import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/repository"
 	"eden-ops/internal/service"
 	"eden-ops/pkg/response"
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
+// podListOptions Pod列表可排序的字段，未指定排序字段时按状态优先级排序
+var podListOptions = listquery.Options{
+	SortFields: map[string]string{
+		"name":          "name",
+		"namespace":     "namespace",
+		"workload_name": "workload_name",
+		"workload_kind": "workload_kind",
+		"status":        "status",
+		"restart_count": "restart_count",
+		"start_time":    "start_time",
+		"created_at":    "created_at",
+	},
+}
+
 // K8sPodHandler K8s Pod处理器
 type K8sPodHandler struct {
 	podService service.K8sPodService
@@ -20,15 +39,16 @@ func NewK8sPodHandler(podService service.K8sPodService) *K8sPodHandler {
 
 // List 获取Pod列表
 func (h *K8sPodHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, podListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	name := c.Query("name")
 	namespace := c.Query("namespace")
 	workloadName := c.Query("workloadName")
 	status := c.Query("status")
 	instanceIP := c.Query("instanceIP")
-	sortBy := c.Query("sortBy")
-	sortOrder := c.DefaultQuery("sortOrder", "asc")
 	startTimeStr := c.Query("startTime")
 	endTimeStr := c.Query("endTime")
 
@@ -48,13 +68,48 @@ func (h *K8sPodHandler) List(c *gin.Context) {
 		endTime = &endTimeStr
 	}
 
-	pods, total, err := h.podService.ListWithFilter(page, pageSize, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder, startTime, endTime, configId)
+	pods, total, err := h.podService.ListWithFilter(q.Page, q.PageSize, name, namespace, workloadName, status, instanceIP, q.SortBy, q.SortOrder, startTime, endTime, configId, c.Query("labelSelector"), middleware.GetDataScope(c))
+	if errors.Is(err, service.ErrInvalidLabelSelector) {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.PageSuccess(c, pods, total)
+	response.ListSuccess(c, listquery.NewResult(pods, total, q).PageData())
+}
+
+// Export 按列表的筛选条件导出Pod，支持 format、columns、raw 参数
+func (h *K8sPodHandler) Export(c *gin.Context) {
+	req, err := parseExportRequest(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	filter := &repository.K8sPodFilter{
+		Name:         c.Query("name"),
+		Namespace:    c.Query("namespace"),
+		WorkloadName: c.Query("workloadName"),
+		Status:       c.Query("status"),
+		InstanceIP:   c.Query("instanceIP"),
+	}
+	if id, err := strconv.ParseInt(c.Query("configId"), 10, 64); err == nil {
+		filter.ConfigID = &id
+	}
+	if startTime := c.Query("startTime"); startTime != "" {
+		filter.StartTime = &startTime
+	}
+	if endTime := c.Query("endTime"); endTime != "" {
+		filter.EndTime = &endTime
+	}
+
+	scope := middleware.GetDataScope(c)
+	exportEach(c, "k8s-pods", req, func(fn func([]model.K8sPod) error) error {
+		return h.podService.Export(filter, c.Query("labelSelector"), scope, fn)
+	}, (*model.K8sPod).ToResponse)
 }
 
 // Get 获取Pod详情
@@ -65,13 +120,9 @@ func (h *K8sPodHandler) Get(c *gin.Context) {
 		return
 	}
 
+	// 不存在和不在数据范围内统一返回 404，避免通过状态码探测ID
 	pod, err := h.podService.Get(id)
-	if err != nil {
-		response.Failed(c, err)
-		return
-	}
-
-	if pod == nil {
+	if err != nil || pod == nil || !middleware.GetDataScope(c).AllowsNamespace(pod.ConfigID, pod.Namespace) {
 		response.NotFound(c, "Pod不存在")
 		return
 	}
@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/export"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/repository"
 	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +16,21 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// podHistoryListOptions Pod历史记录默认按归档时间倒序，支持游标分页
+var podHistoryListOptions = listquery.Options{
+	SortFields: map[string]string{
+		"name":          "name",
+		"namespace":     "namespace",
+		"status":        "status",
+		"restart_count": "restart_count",
+		"created_at":    "created_at",
+		"archived_at":   "archived_at",
+	},
+	DefaultSort:  "archived_at",
+	DefaultOrder: "desc",
+	Cursor:       true,
+}
+
 // K8sPodHistoryHandler Pod历史数据处理器
 type K8sPodHistoryHandler struct {
 	podHistoryRepo repository.K8sPodHistoryRepository
@@ -31,8 +52,17 @@ func (h *K8sPodHistoryHandler) GetPodHistory(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	scope := middleware.GetDataScope(c)
+	if !scope.AllowsCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
+
+	q, err := listquery.Parse(c, podHistoryListOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// 解析时间范围
 	var startTime, endTime *time.Time
@@ -47,23 +77,54 @@ func (h *K8sPodHistoryHandler) GetPodHistory(c *gin.Context) {
 		}
 	}
 
-	histories, total, err := h.podHistoryRepo.GetPodHistory(configID, page, pageSize, startTime, endTime)
+	result, err := h.podHistoryRepo.GetPodHistory(configID, q, startTime, endTime, scope)
 	if err != nil {
 		logger.Error("获取Pod历史记录失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pod history"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":     histories,
-		"total":    total,
-		"page":     page,
-		"pageSize": pageSize,
+	response.ListSuccess(c, result.PageData())
+}
+
+// ExportPodHistory 导出归档时间在 startTime 到 endTime 之间的Pod历史记录，时间范围必填，支持 format、columns、raw 参数
+func (h *K8sPodHistoryHandler) ExportPodHistory(c *gin.Context) {
+	configID, err := strconv.ParseInt(c.Param("configId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config ID"})
+		return
+	}
+
+	scope := middleware.GetDataScope(c)
+	if !scope.AllowsCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
+
+	startTime, endTime, err := historyExportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req, err := parseExportRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := fmt.Sprintf("k8s-pod-history-%d", configID)
+	exportModels(c, name, req, func(fn func([]model.K8sPodHistory) error) error {
+		return h.podHistoryRepo.EachPodHistory(configID, startTime, endTime, scope, export.MaxRows, export.BatchSize, fn)
 	})
 }
 
 // CleanupPodHistory 清理Pod历史数据
 func (h *K8sPodHistoryHandler) CleanupPodHistory(c *gin.Context) {
+	if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow cleanup"})
+		return
+	}
+
 	var req struct {
 		BeforeDate string `json:"beforeDate" binding:"required"`
 	}
@@ -79,15 +140,15 @@ func (h *K8sPodHistoryHandler) CleanupPodHistory(c *gin.Context) {
 		return
 	}
 
-	err = h.podHistoryRepo.CleanupPodHistory(beforeDate)
+	deleted, err := h.podHistoryRepo.CleanupPodHistory(c.Request.Context(), 0, beforeDate, repository.BatchDeleteOptions{})
 	if err != nil {
 		logger.Error("清理Pod历史数据失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cleanup pod history"})
 		return
 	}
 
-	logger.Info("手动清理Pod历史数据成功，清理 %s 之前的数据", beforeDate.Format("2006-01-02"))
-	c.JSON(http.StatusOK, gin.H{"message": "Pod history cleanup completed successfully"})
+	logger.Info("手动清理Pod历史数据成功，清理 %s 之前的数据，共 %d 行", beforeDate.Format("2006-01-02"), deleted)
+	c.JSON(http.StatusOK, gin.H{"message": "Pod history cleanup completed successfully", "deleted": deleted})
 }
 
 // GetPodHistoryStatistics 获取Pod历史数据统计
@@ -99,8 +160,15 @@ func (h *K8sPodHistoryHandler) GetPodHistoryStatistics(c *gin.Context) {
 		return
 	}
 
+	if !middleware.GetDataScope(c).AllowsWholeCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
+
 	// 获取Pod历史数据统计
-	podHistories, podTotal, err := h.podHistoryRepo.GetPodHistory(configID, 1, 1, nil, nil)
+	latest := listquery.New(podHistoryListOptions)
+	latest.PageSize = 1
+	result, err := h.podHistoryRepo.GetPodHistory(configID, latest, nil, nil, nil)
 	if err != nil {
 		logger.Error("获取Pod历史统计失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pod history statistics"})
@@ -108,12 +176,12 @@ func (h *K8sPodHistoryHandler) GetPodHistoryStatistics(c *gin.Context) {
 	}
 
 	statistics := gin.H{
-		"podHistoryCount": podTotal,
+		"podHistoryCount": result.Total,
 	}
 
 	// 获取最新的归档时间
-	if len(podHistories) > 0 {
-		statistics["lastArchivedAt"] = podHistories[0].ArchivedAt.Format("2006-01-02 15:04:05")
+	if len(result.List) > 0 {
+		statistics["lastArchivedAt"] = result.List[0].ArchivedAt.Format("2006-01-02 15:04:05")
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// K8sSearchHandler K8s资源全局搜索处理器
+type K8sSearchHandler struct {
+	searchService service.K8sSearchService
+}
+
+// NewK8sSearchHandler 创建K8s资源全局搜索处理器
+func NewK8sSearchHandler(searchService service.K8sSearchService) *K8sSearchHandler {
+	return &K8sSearchHandler{searchService: searchService}
+}
+
+// Search 在全部集群中按名称、镜像、IP和标签搜索Pod、工作负载和节点，limit 为每类资源返回的条数
+func (h *K8sSearchHandler) Search(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	result, err := h.searchService.Search(c.Query("keyword"), limit, middleware.GetDataScope(c))
+	if errors.Is(err, service.ErrInvalidK8sSearch) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+	response.Success(c, result)
+}
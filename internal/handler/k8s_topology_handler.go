@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// K8sTopologyHandler K8s拓扑处理器
+type K8sTopologyHandler struct {
+	topologyService service.K8sTopologyService
+}
+
+// NewK8sTopologyHandler 创建K8s拓扑处理器
+func NewK8sTopologyHandler(topologyService service.K8sTopologyService) *K8sTopologyHandler {
+	return &K8sTopologyHandler{topologyService: topologyService}
+}
+
+// Topology 集群或命名空间的 工作负载 -> Pod -> 节点 拓扑图，includeServices=true 时实时查询 Service 和 Ingress
+func (h *K8sTopologyHandler) Topology(c *gin.Context) {
+	configID, err := strconv.ParseInt(c.Param("configId"), 10, 64)
+	if err != nil || configID <= 0 {
+		response.BadRequest(c, "无效的集群ID")
+		return
+	}
+	namespace := c.Query("namespace")
+	scope := middleware.GetDataScope(c)
+	if !scope.AllowsCluster(configID) || namespace != "" && !scope.AllowsNamespace(configID, namespace) {
+		response.NotFound(c, "集群不存在")
+		return
+	}
+	includeServices, _ := strconv.ParseBool(c.Query("includeServices"))
+
+	topology, err := h.topologyService.Topology(&service.K8sTopologyQuery{
+		ConfigID:        configID,
+		Namespace:       namespace,
+		IncludeServices: includeServices,
+	}, scope)
+	if errors.Is(err, service.ErrInvalidK8sTopology) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+	response.Success(c, topology)
+}
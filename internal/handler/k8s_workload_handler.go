@@ -1,13 +1,33 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
-	"github.com/gin-gonic/gin"
-	"eden-ops/internal/service"
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/pkg/response"
+	"eden-ops/internal/repository"
+	"eden-ops/internal/service"
+
+	"github.com/gin-gonic/gin"
 )
 
+// workloadListOptions 工作负载列表可排序的字段，未指定排序字段时按状态优先级排序
+var workloadListOptions = listquery.Options{
+	SortFields: map[string]string{
+		"name":           "name",
+		"namespace":      "namespace",
+		"kind":           "kind",
+		"status":         "status",
+		"replicas":       "replicas",
+		"ready_replicas": "ready_replicas",
+		"created_at":     "created_at",
+		"updated_at":     "updated_at",
+	},
+}
+
 // K8sWorkloadHandler Kubernetes工作负载处理器
 type K8sWorkloadHandler struct {
 	workloadService service.K8sWorkloadService
@@ -22,15 +42,16 @@ func NewK8sWorkloadHandler(workloadService service.K8sWorkloadService) *K8sWorkl
 
 // List 获取工作负载列表
 func (h *K8sWorkloadHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, workloadListOptions)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	name := c.Query("name")
 	namespace := c.Query("namespace")
 	workloadType := c.Query("workloadType")
 	status := c.Query("status")
 	replicas := c.Query("replicas")
-	sortBy := c.Query("sortBy")
-	sortOrder := c.DefaultQuery("sortOrder", "asc")
 	startTimeStr := c.Query("startTime")
 	endTimeStr := c.Query("endTime")
 
@@ -50,13 +71,48 @@ func (h *K8sWorkloadHandler) List(c *gin.Context) {
 		endTime = &endTimeStr
 	}
 
-	workloads, total, err := h.workloadService.ListWithFilter(page, pageSize, name, namespace, workloadType, status, replicas, sortBy, sortOrder, startTime, endTime, configId)
+	workloads, total, err := h.workloadService.ListWithFilter(q.Page, q.PageSize, name, namespace, workloadType, status, replicas, q.SortBy, q.SortOrder, startTime, endTime, configId, c.Query("labelSelector"), middleware.GetDataScope(c))
+	if errors.Is(err, service.ErrInvalidLabelSelector) {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.PageSuccess(c, workloads, total)
+	response.ListSuccess(c, listquery.NewResult(workloads, total, q).PageData())
+}
+
+// Export 按列表的筛选条件导出工作负载，支持 format、columns、raw 参数
+func (h *K8sWorkloadHandler) Export(c *gin.Context) {
+	req, err := parseExportRequest(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	filter := &repository.K8sWorkloadFilter{
+		Name:         c.Query("name"),
+		Namespace:    c.Query("namespace"),
+		WorkloadType: c.Query("workloadType"),
+		Status:       c.Query("status"),
+		Replicas:     c.Query("replicas"),
+	}
+	if id, err := strconv.ParseInt(c.Query("configId"), 10, 64); err == nil {
+		filter.ConfigID = &id
+	}
+	if startTime := c.Query("startTime"); startTime != "" {
+		filter.StartTime = &startTime
+	}
+	if endTime := c.Query("endTime"); endTime != "" {
+		filter.EndTime = &endTime
+	}
+
+	scope := middleware.GetDataScope(c)
+	exportEach(c, "k8s-workloads", req, func(fn func([]model.K8sWorkload) error) error {
+		return h.workloadService.Export(filter, c.Query("labelSelector"), scope, fn)
+	}, (*model.K8sWorkload).ToResponse)
 }
 
 // Get 获取工作负载详情
@@ -67,13 +123,9 @@ func (h *K8sWorkloadHandler) Get(c *gin.Context) {
 		return
 	}
 
+	// 不存在和不在数据范围内统一返回 404，避免通过状态码探测ID
 	workload, err := h.workloadService.Get(id)
-	if err != nil {
-		response.Failed(c, err)
-		return
-	}
-
-	if workload == nil {
+	if err != nil || workload == nil || !middleware.GetDataScope(c).AllowsNamespace(workload.ConfigID, workload.Namespace) {
 		response.NotFound(c, "工作负载不存在")
 		return
 	}
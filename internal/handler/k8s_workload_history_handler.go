@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/export"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
 	"eden-ops/internal/repository"
 	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +16,20 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// workloadHistoryListOptions Workload历史记录默认按归档时间倒序，支持游标分页
+var workloadHistoryListOptions = listquery.Options{
+	SortFields: map[string]string{
+		"name":        "name",
+		"namespace":   "namespace",
+		"replicas":    "replicas",
+		"created_at":  "created_at",
+		"archived_at": "archived_at",
+	},
+	DefaultSort:  "archived_at",
+	DefaultOrder: "desc",
+	Cursor:       true,
+}
+
 // K8sWorkloadHistoryHandler Workload历史数据处理器
 type K8sWorkloadHistoryHandler struct {
 	workloadHistoryRepo repository.K8sWorkloadHistoryRepository
@@ -31,8 +51,17 @@ func (h *K8sWorkloadHistoryHandler) GetWorkloadHistory(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	scope := middleware.GetDataScope(c)
+	if !scope.AllowsCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
+
+	q, err := listquery.Parse(c, workloadHistoryListOptions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// 解析时间范围
 	var startTime, endTime *time.Time
@@ -47,23 +76,54 @@ func (h *K8sWorkloadHistoryHandler) GetWorkloadHistory(c *gin.Context) {
 		}
 	}
 
-	histories, total, err := h.workloadHistoryRepo.GetWorkloadHistory(configID, page, pageSize, startTime, endTime)
+	result, err := h.workloadHistoryRepo.GetWorkloadHistory(configID, q, startTime, endTime, scope)
 	if err != nil {
 		logger.Error("获取Workload历史记录失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workload history"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":     histories,
-		"total":    total,
-		"page":     page,
-		"pageSize": pageSize,
+	response.ListSuccess(c, result.PageData())
+}
+
+// ExportWorkloadHistory 导出归档时间在 startTime 到 endTime 之间的Workload历史记录，时间范围必填，支持 format、columns、raw 参数
+func (h *K8sWorkloadHistoryHandler) ExportWorkloadHistory(c *gin.Context) {
+	configID, err := strconv.ParseInt(c.Param("configId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config ID"})
+		return
+	}
+
+	scope := middleware.GetDataScope(c)
+	if !scope.AllowsCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
+
+	startTime, endTime, err := historyExportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req, err := parseExportRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := fmt.Sprintf("k8s-workload-history-%d", configID)
+	exportModels(c, name, req, func(fn func([]model.K8sWorkloadHistory) error) error {
+		return h.workloadHistoryRepo.EachWorkloadHistory(configID, startTime, endTime, scope, export.MaxRows, export.BatchSize, fn)
 	})
 }
 
 // CleanupWorkloadHistory 清理Workload历史数据
 func (h *K8sWorkloadHistoryHandler) CleanupWorkloadHistory(c *gin.Context) {
+	if !middleware.GetDataScope(c).Unrestricted() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Data scope does not allow cleanup"})
+		return
+	}
+
 	var req struct {
 		BeforeDate string `json:"beforeDate" binding:"required"`
 	}
@@ -79,15 +139,15 @@ func (h *K8sWorkloadHistoryHandler) CleanupWorkloadHistory(c *gin.Context) {
 		return
 	}
 
-	err = h.workloadHistoryRepo.CleanupWorkloadHistory(beforeDate)
+	deleted, err := h.workloadHistoryRepo.CleanupWorkloadHistory(c.Request.Context(), 0, beforeDate, repository.BatchDeleteOptions{})
 	if err != nil {
 		logger.Error("清理Workload历史数据失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cleanup workload history"})
 		return
 	}
 
-	logger.Info("手动清理Workload历史数据成功，清理 %s 之前的数据", beforeDate.Format("2006-01-02"))
-	c.JSON(http.StatusOK, gin.H{"message": "Workload history cleanup completed successfully"})
+	logger.Info("手动清理Workload历史数据成功，清理 %s 之前的数据，共 %d 行", beforeDate.Format("2006-01-02"), deleted)
+	c.JSON(http.StatusOK, gin.H{"message": "Workload history cleanup completed successfully", "deleted": deleted})
 }
 
 // GetWorkloadHistoryStatistics 获取Workload历史数据统计
@@ -99,8 +159,15 @@ func (h *K8sWorkloadHistoryHandler) GetWorkloadHistoryStatistics(c *gin.Context)
 		return
 	}
 
+	if !middleware.GetDataScope(c).AllowsWholeCluster(configID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config not found"})
+		return
+	}
+
 	// 获取Workload历史数据统计
-	workloadHistories, workloadTotal, err := h.workloadHistoryRepo.GetWorkloadHistory(configID, 1, 1, nil, nil)
+	latest := listquery.New(workloadHistoryListOptions)
+	latest.PageSize = 1
+	result, err := h.workloadHistoryRepo.GetWorkloadHistory(configID, latest, nil, nil, nil)
 	if err != nil {
 		logger.Error("获取Workload历史统计失败: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get workload history statistics"})
@@ -108,12 +175,12 @@ func (h *K8sWorkloadHistoryHandler) GetWorkloadHistoryStatistics(c *gin.Context)
 	}
 
 	statistics := gin.H{
-		"workloadHistoryCount": workloadTotal,
+		"workloadHistoryCount": result.Total,
 	}
 
 	// 获取最新的归档时间
-	if len(workloadHistories) > 0 {
-		statistics["lastArchivedAt"] = workloadHistories[0].ArchivedAt.Format("2006-01-02 15:04:05")
+	if len(result.List) > 0 {
+		statistics["lastArchivedAt"] = result.List[0].ArchivedAt.Format("2006-01-02 15:04:05")
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -5,20 +5,26 @@ import (
 	"eden-ops/internal/service"
 	"eden-ops/pkg/response"
 	"errors"
+	"fmt"
+	"mime"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // MenuHandler 菜单处理器
 type MenuHandler struct {
-	menuService service.MenuService
+	menuService       service.MenuService
+	permissionService service.PermissionService
 }
 
 // NewMenuHandler 创建菜单处理器
-func NewMenuHandler(menuService service.MenuService) *MenuHandler {
+func NewMenuHandler(menuService service.MenuService, permissionService service.PermissionService) *MenuHandler {
 	return &MenuHandler{
-		menuService: menuService,
+		menuService:       menuService,
+		permissionService: permissionService,
 	}
 }
 
@@ -33,6 +39,8 @@ type MenuRequest struct {
 	Status     string `json:"status"`
 	Permission string `json:"permission"`
 	Type       string `json:"type" binding:"required"` // M:目录 C:菜单 F:按钮
+	Hidden     bool   `json:"hidden"`                  // 不在侧边栏显示
+	Cache      bool   `json:"cache"`                   // 缓存页面
 }
 
 // Create 创建菜单
@@ -64,10 +72,13 @@ func (h *MenuHandler) Create(c *gin.Context) {
 		Name:      req.Name,
 		Path:      req.Path,
 		Component: req.Component,
+		Perms:     req.Permission,
 		Icon:      req.Icon,
 		Sort:      req.Sort,
 		ParentID:  req.ParentID,
 		Status:    status,
+		Hidden:    req.Hidden,
+		Cache:     req.Cache,
 		Type:      menuType,
 	}
 
@@ -122,10 +133,13 @@ func (h *MenuHandler) Update(c *gin.Context) {
 	menu.Name = req.Name
 	menu.Path = req.Path
 	menu.Component = req.Component
+	menu.Perms = req.Permission
 	menu.Icon = req.Icon
 	menu.Sort = req.Sort
 	menu.ParentID = req.ParentID
 	menu.Status = status
+	menu.Hidden = req.Hidden
+	menu.Cache = req.Cache
 	menu.Type = menuType
 
 	err = h.menuService.Update(menu)
@@ -133,6 +147,7 @@ func (h *MenuHandler) Update(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	h.permissionService.InvalidateAll()
 
 	response.Success(c, menu)
 }
@@ -150,6 +165,7 @@ func (h *MenuHandler) Delete(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	h.permissionService.InvalidateAll()
 
 	response.Success(c, nil)
 }
@@ -198,3 +214,44 @@ func (h *MenuHandler) ListByRoleID(c *gin.Context) {
 
 	response.Success(c, menus)
 }
+
+// ListAPIPermissions 获取接口权限映射
+func (h *MenuHandler) ListAPIPermissions(c *gin.Context) {
+	permissions, err := h.permissionService.ListAPIPermissions()
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, permissions)
+}
+
+// GetUserMenus 获取当前用户可见的菜单路由树，用于前端动态路由
+func (h *MenuHandler) GetUserMenus(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	routes, err := h.menuService.GetUserMenuTree(userID)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, routes)
+}
+
+// Export 导出全部菜单的树形结构
+func (h *MenuHandler) Export(c *gin.Context) {
+	tree, err := h.menuService.ExportTree()
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("menus-%s.json", time.Now().Format("20060102150405"))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.JSON(http.StatusOK, tree)
+}
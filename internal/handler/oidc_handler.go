@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"eden-ops/internal/service"
+	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcFlowCookie 保存授权流程状态的 Cookie 名称
+const oidcFlowCookie = "eden_oidc_flow"
+
+// OIDCHandler OIDC单点登录处理器
+type OIDCHandler struct {
+	oidcService  service.OIDCService
+	tokenService service.TokenService
+	frontendURL  string
+}
+
+// NewOIDCHandler 创建OIDC单点登录处理器，frontendURL 为登录完成后跳转的前端页面
+func NewOIDCHandler(oidcService service.OIDCService, tokenService service.TokenService, frontendURL string) *OIDCHandler {
+	if frontendURL == "" {
+		frontendURL = "/login"
+	}
+	return &OIDCHandler{
+		oidcService:  oidcService,
+		tokenService: tokenService,
+		frontendURL:  frontendURL,
+	}
+}
+
+// Providers 获取已配置的身份提供方
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	response.Success(c, h.oidcService.Providers())
+}
+
+// Login 发起单点登录，跳转到身份提供方授权页面
+func (h *OIDCHandler) Login(c *gin.Context) {
+	provider := c.Param("provider")
+	authURL, flowToken, err := h.oidcService.BeginLogin(provider)
+	if err != nil {
+		logger.Error("发起OIDC登录失败: provider=%s, %v", provider, err)
+		h.redirectResult(c, url.Values{"error": {err.Error()}})
+		return
+	}
+
+	h.setFlowCookie(c, provider, flowToken, int(service.OIDCFlowTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback 身份提供方回调，登录成功后签发令牌并通过URL片段返回前端
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+	flowToken, _ := c.Cookie(oidcFlowCookie)
+	h.setFlowCookie(c, provider, "", -1)
+
+	if errCode := c.Query("error"); errCode != "" {
+		message := c.Query("error_description")
+		if message == "" {
+			message = errCode
+		}
+		logger.Warn("OIDC身份提供方返回错误: provider=%s, error=%s, description=%s", provider, errCode, c.Query("error_description"))
+		h.redirectResult(c, url.Values{"error": {message}})
+		return
+	}
+
+	user, err := h.oidcService.CompleteLogin(c.Request.Context(), provider, flowToken, c.Query("state"), c.Query("code"))
+	if err != nil {
+		h.redirectResult(c, url.Values{"error": {err.Error()}})
+		return
+	}
+
+	tokens, err := h.tokenService.Issue(user, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		logger.Error("签发令牌失败: username=%s, error=%v", user.Username, err)
+		h.redirectResult(c, url.Values{"error": {"登录失败，请重试"}})
+		return
+	}
+
+	logger.Info("OIDC用户登录成功: provider=%s, username=%s, userID=%d", provider, user.Username, user.ID)
+	h.redirectResult(c, url.Values{
+		"token":            {tokens.AccessToken},
+		"refreshToken":     {tokens.RefreshToken},
+		"expiresIn":        {strconv.FormatInt(tokens.ExpiresIn, 10)},
+		"refreshExpiresIn": {strconv.FormatInt(tokens.RefreshExpiresIn, 10)},
+	})
+}
+
+// setFlowCookie 设置授权流程 Cookie，仅回调地址可见；SameSite=Lax 保证身份提供方跳转回来时携带
+func (h *OIDCHandler) setFlowCookie(c *gin.Context, provider, value string, maxAge int) {
+	secure := c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, value, maxAge, "/api/v1/oidc/"+url.PathEscape(provider)+"/", "", secure, true)
+}
+
+// redirectResult 跳转回前端页面，结果放在URL片段中，不会发送到服务端或写入访问日志
+func (h *OIDCHandler) redirectResult(c *gin.Context, values url.Values) {
+	c.Redirect(http.StatusFound, h.frontendURL+"#"+values.Encode())
+}
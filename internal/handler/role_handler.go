@@ -2,22 +2,29 @@ package handler
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
 	"eden-ops/internal/service"
 	"eden-ops/pkg/response"
+	"fmt"
+	"mime"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RoleHandler 角色处理器
 type RoleHandler struct {
-	roleService service.RoleService
+	roleService       service.RoleService
+	permissionService service.PermissionService
 }
 
 // NewRoleHandler 创建角色处理器
-func NewRoleHandler(roleService service.RoleService) *RoleHandler {
+func NewRoleHandler(roleService service.RoleService, permissionService service.PermissionService) *RoleHandler {
 	return &RoleHandler{
-		roleService: roleService,
+		roleService:       roleService,
+		permissionService: permissionService,
 	}
 }
 
@@ -97,6 +104,7 @@ func (h *RoleHandler) Update(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	h.permissionService.InvalidateAll()
 
 	response.Success(c, role)
 }
@@ -114,6 +122,7 @@ func (h *RoleHandler) Delete(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	h.permissionService.InvalidateAll()
 
 	response.Success(c, nil)
 }
@@ -137,19 +146,20 @@ func (h *RoleHandler) Get(c *gin.Context) {
 
 // List 获取角色列表
 func (h *RoleHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	// 角色数量有限，下拉框会一次加载全部角色
+	q, err := listquery.Parse(c, listquery.Options{MaxPageSize: 1000})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 
-	roles, total, err := h.roleService.List(page, pageSize)
+	roles, total, err := h.roleService.List(q.Page, q.PageSize)
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.Success(c, gin.H{
-		"list":  roles,
-		"total": total,
-	})
+	response.ListSuccess(c, listquery.NewResult(roles, total, q).PageData())
 }
 
 // AssignMenus 分配菜单
@@ -181,6 +191,7 @@ func (h *RoleHandler) AssignMenus(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	h.permissionService.InvalidateAll()
 
 	response.Success(c, nil)
 }
@@ -207,6 +218,155 @@ func (h *RoleHandler) AssignUserRoles(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	h.permissionService.InvalidateAll()
 
 	response.Success(c, nil)
 }
+
+// GetDataScopes 获取角色数据范围
+func (h *RoleHandler) GetDataScopes(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的角色ID")
+		return
+	}
+
+	role, err := h.roleService.Get(uint(id))
+	if err != nil {
+		response.NotFound(c, "角色不存在")
+		return
+	}
+
+	rules, err := h.roleService.ListDataScopes(uint(id))
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	dataScope := role.DataScope
+	if dataScope == "" {
+		dataScope = model.RoleDataScopeAll
+	}
+	response.Success(c, gin.H{
+		"dataScope": dataScope,
+		"rules":     rules,
+	})
+}
+
+// AssignDataScopes 设置角色数据范围
+func (h *RoleHandler) AssignDataScopes(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的角色ID")
+		return
+	}
+
+	// 检查角色是否存在
+	_, err = h.roleService.Get(uint(id))
+	if err != nil {
+		response.NotFound(c, "角色不存在")
+		return
+	}
+
+	var req struct {
+		DataScope string                 `json:"dataScope" binding:"required"`
+		Rules     []*model.RoleDataScope `json:"rules"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.roleService.AssignDataScopes(uint(id), req.DataScope, req.Rules); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	h.permissionService.InvalidateAll()
+
+	response.Success(c, nil)
+}
+
+// Clone 复制角色，新角色拥有与源角色相同的菜单权限和数据范围
+func (h *RoleHandler) Clone(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的角色ID")
+		return
+	}
+
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if _, err := h.roleService.Get(uint(id)); err != nil {
+		response.NotFound(c, "角色不存在")
+		return
+	}
+
+	status := 1
+	if req.Status == "0" {
+		status = 0
+	}
+	role := &model.Role{
+		Name:   req.Name,
+		Code:   req.Code,
+		Status: status,
+		Remark: req.Remark,
+	}
+	if err := h.roleService.Clone(uint(id), role); err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, role)
+}
+
+// Diff 比较角色权限：查询参数 targetId 指定本环境的目标角色，
+// 或以 POST 请求体提交从其他环境导出的角色权限集合
+func (h *RoleHandler) Diff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的角色ID")
+		return
+	}
+	source, err := h.roleService.GetPermissionSet(uint(id))
+	if err != nil {
+		response.NotFound(c, "角色不存在")
+		return
+	}
+
+	var target *service.RolePermissionSet
+	if c.Request.Method == http.MethodPost {
+		target = &service.RolePermissionSet{}
+		if err := c.ShouldBindJSON(target); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	} else {
+		targetID, err := strconv.ParseUint(c.Query("targetId"), 10, 32)
+		if err != nil {
+			response.BadRequest(c, "无效的目标角色ID")
+			return
+		}
+		if target, err = h.roleService.GetPermissionSet(uint(targetID)); err != nil {
+			response.NotFound(c, "目标角色不存在")
+			return
+		}
+	}
+
+	response.Success(c, service.DiffRolePermissionSets(source, target))
+}
+
+// Export 导出全部角色的权限集合，可用于在其他环境中比较
+func (h *RoleHandler) Export(c *gin.Context) {
+	sets, err := h.roleService.ExportPermissionSets()
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("roles-%s.json", time.Now().Format("20060102150405"))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.JSON(http.StatusOK, sets)
+}
@@ -2,9 +2,13 @@ package handler
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/pkg/terminal"
 	"eden-ops/internal/service"
 	"eden-ops/pkg/logger"
 	"eden-ops/pkg/response"
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -24,17 +28,34 @@ func NewServerConfigHandler(serverConfigService service.ServerConfigService) *Se
 
 // List 获取服务器配置列表
 func (h *ServerConfigHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	name := c.DefaultQuery("name", "")
 
-	total, configs, err := h.serverConfigService.List(page, pageSize, name)
+	total, configs, err := h.serverConfigService.List(q.Page, q.PageSize, name, middleware.GetDataScope(c))
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.PageSuccess(c, configs, total)
+	response.ListSuccess(c, listquery.NewResult(configs, total, q).PageData())
+}
+
+// Export 按名称导出服务器配置，不包含密码和私钥，支持 format、columns、raw 参数
+func (h *ServerConfigHandler) Export(c *gin.Context) {
+	req, err := parseExportRequest(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	name, scope := c.Query("name"), middleware.GetDataScope(c)
+	exportModels(c, "servers", req, func(fn func([]model.ServerConfig) error) error {
+		return h.serverConfigService.Export(name, scope, fn)
+	})
 }
 
 // Get 获取服务器配置
@@ -46,7 +67,7 @@ func (h *ServerConfigHandler) Get(c *gin.Context) {
 	}
 
 	config, err := h.serverConfigService.Get(uint(id))
-	if err != nil {
+	if err != nil || !middleware.GetDataScope(c).AllowsServer(int64(config.ID)) {
 		response.NotFound(c, "服务器配置不存在")
 		return
 	}
@@ -63,6 +84,10 @@ func (h *ServerConfigHandler) Create(c *gin.Context) {
 	}
 
 	if err := h.serverConfigService.Create(&config); err != nil {
+		if errors.Is(err, terminal.ErrInvalidHostKeyFingerprint) {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		response.Failed(c, err)
 		return
 	}
@@ -84,8 +109,17 @@ func (h *ServerConfigHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !middleware.GetDataScope(c).AllowsServer(int64(id)) {
+		response.NotFound(c, "服务器配置不存在")
+		return
+	}
+
 	config.ID = uint(id)
 	if err := h.serverConfigService.Update(&config); err != nil {
+		if errors.Is(err, terminal.ErrInvalidHostKeyFingerprint) {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		logger.Error("更新服务器配置失败: %v", err)
 		response.InternalServerError(c, "更新服务器配置失败")
 		return
@@ -102,6 +136,11 @@ func (h *ServerConfigHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if !middleware.GetDataScope(c).AllowsServer(int64(id)) {
+		response.NotFound(c, "服务器配置不存在")
+		return
+	}
+
 	if err := h.serverConfigService.Delete(uint(id)); err != nil {
 		logger.Error("删除服务器配置失败: %v", err)
 		response.InternalServerError(c, "删除服务器配置失败")
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/pkg/remotefs"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServerFileHandler 服务器文件传输处理器
+type ServerFileHandler struct {
+	fileService     service.ServerFileService
+	terminalService service.ServerTerminalService
+}
+
+// NewServerFileHandler 创建服务器文件传输处理器
+func NewServerFileHandler(fileService service.ServerFileService, terminalService service.ServerTerminalService) *ServerFileHandler {
+	return &ServerFileHandler{
+		fileService:     fileService,
+		terminalService: terminalService,
+	}
+}
+
+// parseFileRequest 解析服务器ID和操作人
+func (h *ServerFileHandler) parseFileRequest(c *gin.Context) (uint, *service.FileOperator, bool) {
+	serverID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的服务器配置ID")
+		return 0, nil, false
+	}
+	if !middleware.GetDataScope(c).AllowsServer(int64(serverID)) {
+		response.NotFound(c, "服务器配置不存在")
+		return 0, nil, false
+	}
+	userID, username, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return 0, nil, false
+	}
+	return uint(serverID), &service.FileOperator{
+		UserID:   userID,
+		Username: username,
+		ClientIP: c.ClientIP(),
+	}, true
+}
+
+// fileError 输出文件操作错误
+func fileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTerminalForbidden), errors.Is(err, remotefs.ErrPathNotAllowed), errors.Is(err, remotefs.ErrNoAllowedPaths):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, remotefs.ErrInvalidPath):
+		response.BadRequest(c, err.Error())
+	default:
+		response.Failed(c, err)
+	}
+}
+
+// List 列出远程目录
+func (h *ServerFileHandler) List(c *gin.Context) {
+	serverID, op, ok := h.parseFileRequest(c)
+	if !ok {
+		return
+	}
+
+	files, err := h.fileService.List(op, serverID, c.Query("path"))
+	if err != nil {
+		fileError(c, err)
+		return
+	}
+
+	response.Success(c, files)
+}
+
+// Stat 获取远程文件信息
+func (h *ServerFileHandler) Stat(c *gin.Context) {
+	serverID, op, ok := h.parseFileRequest(c)
+	if !ok {
+		return
+	}
+
+	info, err := h.fileService.Stat(op, serverID, c.Query("path"))
+	if err != nil {
+		fileError(c, err)
+		return
+	}
+
+	response.Success(c, info)
+}
+
+// Download 流式下载远程文件
+func (h *ServerFileHandler) Download(c *gin.Context) {
+	serverID, op, ok := h.parseFileRequest(c)
+	if !ok {
+		return
+	}
+
+	started := false
+	err := h.fileService.Download(op, serverID, c.Query("path"), func(info *remotefs.FileInfo) io.Writer {
+		started = true
+		c.Header("Content-Type", "application/octet-stream")
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Name}))
+		c.Header("Content-Length", strconv.FormatInt(info.Size, 10))
+		c.Status(http.StatusOK)
+		return c.Writer
+	})
+	if err != nil {
+		if started {
+			// 已开始传输，只能中断连接
+			logger.Error("下载服务器文件失败: %v", err)
+			c.Abort()
+			return
+		}
+		fileError(c, err)
+	}
+}
+
+// Upload 上传文件（multipart/form-data，字段名 file），目标目录由 path 参数指定
+func (h *ServerFileHandler) Upload(c *gin.Context) {
+	serverID, op, ok := h.parseFileRequest(c)
+	if !ok {
+		return
+	}
+
+	maxSize := h.fileService.MaxUploadSize()
+	if c.Request.ContentLength > maxSize+(1<<20) {
+		response.BadRequest(c, fmt.Sprintf("文件大小超过限制 %d MB", maxSize>>20))
+		return
+	}
+	// 预留1MB给multipart头部
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+(1<<20))
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		response.BadRequest(c, "请使用multipart/form-data上传文件")
+		return
+	}
+
+	overwrite := c.Query("overwrite") == "true"
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			response.BadRequest(c, "读取上传内容失败")
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		info, err := h.fileService.Upload(op, serverID, c.Query("path"), part.FileName(), overwrite, part)
+		part.Close()
+		if err != nil {
+			logger.Error("上传服务器文件失败: %v", err)
+			fileError(c, err)
+			return
+		}
+		response.Success(c, info)
+		return
+	}
+
+	response.BadRequest(c, "未找到上传文件")
+}
+
+// mkdirRequest 创建目录请求
+type mkdirRequest struct {
+	Path string `json:"path" binding:"required"`
+}
+
+// Mkdir 创建远程目录
+func (h *ServerFileHandler) Mkdir(c *gin.Context) {
+	serverID, op, ok := h.parseFileRequest(c)
+	if !ok {
+		return
+	}
+
+	var req mkdirRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "无效的请求参数")
+		return
+	}
+
+	if err := h.fileService.Mkdir(op, serverID, req.Path); err != nil {
+		fileError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Delete 删除远程文件或目录
+func (h *ServerFileHandler) Delete(c *gin.Context) {
+	serverID, op, ok := h.parseFileRequest(c)
+	if !ok {
+		return
+	}
+
+	recursive := c.Query("recursive") == "true"
+	if err := h.fileService.Delete(op, serverID, c.Query("path"), recursive); err != nil {
+		fileError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ListAudits 获取文件操作审计记录，非管理员只能查看自己的记录
+func (h *ServerFileHandler) ListAudits(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	serverID, _ := strconv.ParseUint(c.DefaultQuery("serverId", "0"), 10, 32)
+	filterUserID, _ := strconv.ParseUint(c.DefaultQuery("userId", "0"), 10, 32)
+	if !h.terminalService.IsAdmin(userID) {
+		filterUserID = uint64(userID)
+	}
+
+	total, audits, err := h.fileService.ListAudits(q.Page, q.PageSize, uint(serverID), uint(filterUserID), c.Query("action"))
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.ListSuccess(c, listquery.NewResult(audits, total, q).PageData())
+}
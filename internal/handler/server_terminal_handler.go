@@ -0,0 +1,420 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/pkg/middleware"
+	"eden-ops/internal/pkg/terminal"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	terminalWriteWait  = 10 * time.Second
+	terminalPongWait   = 60 * time.Second
+	terminalPingPeriod = 50 * time.Second
+	terminalReadLimit  = 64 * 1024
+)
+
+// 终端WebSocket消息类型
+const (
+	terminalMsgInput  = "input"
+	terminalMsgResize = "resize"
+	terminalMsgPing   = "ping"
+	terminalMsgOutput = "output"
+	terminalMsgError  = "error"
+	terminalMsgClosed = "closed"
+	terminalMsgReady  = "ready"
+)
+
+// terminalMessage 终端WebSocket消息
+type terminalMessage struct {
+	Type      string `json:"type"`
+	Data      string `json:"data,omitempty"`
+	Cols      int    `json:"cols,omitempty"`
+	Rows      int    `json:"rows,omitempty"`
+	SessionID uint   `json:"session_id,omitempty"`
+}
+
+// terminalUpgrader WebSocket升级器，跨域已由Cors中间件放开，鉴权依赖JWT
+var terminalUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// ServerTerminalHandler 服务器Web终端处理器
+type ServerTerminalHandler struct {
+	terminalService service.ServerTerminalService
+}
+
+// NewServerTerminalHandler 创建服务器Web终端处理器
+func NewServerTerminalHandler(terminalService service.ServerTerminalService) *ServerTerminalHandler {
+	return &ServerTerminalHandler{
+		terminalService: terminalService,
+	}
+}
+
+// currentUser 获取当前登录用户
+func currentUser(c *gin.Context) (uint, string, bool) {
+	userID, exists := c.Get(middleware.UserIDKey)
+	if !exists {
+		return 0, "", false
+	}
+	id, ok := userID.(uint)
+	if !ok {
+		return 0, "", false
+	}
+	return id, c.GetString(middleware.UsernameKey), true
+}
+
+// Terminal 打开服务器Web终端（WebSocket）
+func (h *ServerTerminalHandler) Terminal(c *gin.Context) {
+	serverID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的服务器配置ID")
+		return
+	}
+	if !middleware.GetDataScope(c).AllowsServer(int64(serverID)) {
+		response.NotFound(c, "服务器配置不存在")
+		return
+	}
+
+	userID, username, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	if err := h.terminalService.CheckPermission(userID, uint(serverID)); err != nil {
+		if errors.Is(err, service.ErrTerminalForbidden) {
+			response.Forbidden(c, err.Error())
+			return
+		}
+		response.Failed(c, err)
+		return
+	}
+
+	cols, _ := strconv.Atoi(c.DefaultQuery("cols", "0"))
+	rows, _ := strconv.Atoi(c.DefaultQuery("rows", "0"))
+
+	conn, err := terminalUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Error("升级WebSocket连接失败: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	ws := &terminalConn{conn: conn}
+
+	session, err := h.terminalService.Open(&service.TerminalOpenRequest{
+		ServerID: uint(serverID),
+		UserID:   userID,
+		Username: username,
+		ClientIP: c.ClientIP(),
+		Cols:     cols,
+		Rows:     rows,
+	})
+	if err != nil {
+		logger.Error("打开服务器终端失败: %v", err)
+		ws.send(terminalMessage{Type: terminalMsgError, Data: err.Error()})
+		ws.close(err.Error())
+		return
+	}
+
+	reason, cause := h.serve(ws, session)
+	h.terminalService.Close(session, reason, cause)
+	ws.send(terminalMessage{Type: terminalMsgClosed, Data: reason})
+	ws.close(reason)
+}
+
+// serve 在WebSocket与SSH终端之间转发数据，返回会话结束原因
+func (h *ServerTerminalHandler) serve(ws *terminalConn, session *service.TerminalSession) (string, error) {
+	var (
+		once   sync.Once
+		reason string
+		cause  error
+		done   = make(chan struct{})
+	)
+	finish := func(r string, err error) {
+		once.Do(func() {
+			reason, cause = r, err
+			close(done)
+		})
+	}
+
+	ws.send(terminalMessage{Type: terminalMsgReady, SessionID: session.Record.ID})
+
+	// 空闲超时：仅用户输入会刷新计时
+	idleTimeout := h.terminalService.IdleTimeout()
+	idleTimer := time.AfterFunc(idleTimeout, func() {
+		ws.send(terminalMessage{Type: terminalMsgError, Data: "会话空闲超时，连接已断开"})
+		finish(model.SessionCloseReasonIdle, nil)
+	})
+	defer idleTimer.Stop()
+
+	// SSH输出 -> WebSocket
+	go func() {
+		buf := make([]byte, 32*1024)
+		var pending []byte
+		for {
+			n, err := session.Shell.Read(buf)
+			if n > 0 {
+				data := append(pending, buf[:n]...)
+				var complete []byte
+				complete, pending = terminal.SplitUTF8(data)
+				pending = append([]byte(nil), pending...)
+				if len(complete) > 0 {
+					if recErr := session.Recorder.WriteOutput(complete); recErr != nil {
+						logger.Error("写入会话录像失败: %v", recErr)
+					}
+					if sendErr := ws.send(terminalMessage{Type: terminalMsgOutput, Data: string(complete)}); sendErr != nil {
+						finish(model.SessionCloseReasonClient, nil)
+						return
+					}
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					finish(model.SessionCloseReasonExit, nil)
+				} else {
+					finish(model.SessionCloseReasonError, err)
+				}
+				return
+			}
+		}
+	}()
+
+	// WebSocket输入 -> SSH
+	go func() {
+		ws.conn.SetReadLimit(terminalReadLimit)
+		ws.conn.SetReadDeadline(time.Now().Add(terminalPongWait))
+		ws.conn.SetPongHandler(func(string) error {
+			return ws.conn.SetReadDeadline(time.Now().Add(terminalPongWait))
+		})
+		for {
+			_, data, err := ws.conn.ReadMessage()
+			if err != nil {
+				finish(model.SessionCloseReasonClient, nil)
+				return
+			}
+			ws.conn.SetReadDeadline(time.Now().Add(terminalPongWait))
+
+			var msg terminalMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				continue
+			}
+			switch msg.Type {
+			case terminalMsgInput:
+				idleTimer.Reset(idleTimeout)
+				if _, err := session.Shell.Write([]byte(msg.Data)); err != nil {
+					finish(model.SessionCloseReasonError, err)
+					return
+				}
+			case terminalMsgResize:
+				if err := session.Resize(msg.Cols, msg.Rows); err != nil {
+					logger.Warn("调整终端大小失败: %v", err)
+				}
+			case terminalMsgPing:
+				// 应用层心跳只用于保持连接，不刷新空闲计时
+			}
+		}
+	}()
+
+	// WebSocket心跳
+	ticker := time.NewTicker(terminalPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return reason, cause
+		case <-ticker.C:
+			if err := ws.ping(); err != nil {
+				finish(model.SessionCloseReasonClient, nil)
+			}
+		}
+	}
+}
+
+// terminalConn 并发安全的WebSocket写封装
+type terminalConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// send 发送JSON消息
+func (t *terminalConn) send(msg terminalMessage) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.conn.SetWriteDeadline(time.Now().Add(terminalWriteWait))
+	return t.conn.WriteJSON(msg)
+}
+
+// ping 发送心跳
+func (t *terminalConn) ping() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(terminalWriteWait))
+}
+
+// close 发送关闭帧
+func (t *terminalConn) close(reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason)
+	t.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(terminalWriteWait))
+}
+
+// ListSessions 获取终端会话列表
+func (h *ServerTerminalHandler) ListSessions(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	serverID, _ := strconv.ParseUint(c.DefaultQuery("serverId", "0"), 10, 32)
+	filterUserID, _ := strconv.ParseUint(c.DefaultQuery("userId", "0"), 10, 32)
+	status := c.DefaultQuery("status", "")
+
+	total, sessions, err := h.terminalService.ListSessions(userID, q.Page, q.PageSize, uint(serverID), uint(filterUserID), status)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.ListSuccess(c, listquery.NewResult(sessions, total, q).PageData())
+}
+
+// GetSession 获取终端会话详情
+func (h *ServerTerminalHandler) GetSession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的会话ID")
+		return
+	}
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	session, err := h.terminalService.GetSession(userID, uint(id))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// GetRecording 获取会话录像（asciinema v2 格式）用于回放
+func (h *ServerTerminalHandler) GetRecording(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的会话ID")
+		return
+	}
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	file, err := h.terminalService.GetRecordingFile(userID, uint(id))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "application/x-asciicast")
+	c.Header("Content-Disposition", "inline; filename=session-"+strconv.FormatUint(id, 10)+".cast")
+	c.File(file)
+}
+
+// requireAdmin 终端授权仅允许管理员维护
+func (h *ServerTerminalHandler) requireAdmin(c *gin.Context) bool {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return false
+	}
+	if !h.terminalService.IsAdmin(userID) {
+		response.Forbidden(c, "仅管理员可维护终端授权")
+		return false
+	}
+	return true
+}
+
+// serverPermissionRequest 服务器终端授权请求
+type serverPermissionRequest struct {
+	UserIDs []uint `json:"user_ids"`
+	RoleIDs []uint `json:"role_ids"`
+}
+
+// GetPermissions 获取服务器终端授权
+func (h *ServerTerminalHandler) GetPermissions(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的服务器配置ID")
+		return
+	}
+
+	info, err := h.terminalService.GetPermissions(uint(id))
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, info)
+}
+
+// UpdatePermissions 更新服务器终端授权
+func (h *ServerTerminalHandler) UpdatePermissions(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的服务器配置ID")
+		return
+	}
+
+	var req serverPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "无效的请求参数")
+		return
+	}
+
+	if err := h.terminalService.UpdatePermissions(uint(id), req.UserIDs, req.RoleIDs); err != nil {
+		logger.Error("更新服务器终端授权失败: %v", err)
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -2,25 +2,35 @@ package handler
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
 	"eden-ops/internal/service"
-	"eden-ops/pkg/auth"
+	"eden-ops/internal/utils"
 	"eden-ops/pkg/response"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // UserHandler 用户处理器
 type UserHandler struct {
-	userService service.UserService
-	jwtAuth     *auth.JWTAuth
+	userService       service.UserService
+	permissionService service.PermissionService
+	totpService       service.TOTPService
 }
 
 // NewUserHandler 创建用户处理器
-func NewUserHandler(userService service.UserService, jwtAuth *auth.JWTAuth) *UserHandler {
+func NewUserHandler(userService service.UserService, permissionService service.PermissionService, totpService service.TOTPService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		jwtAuth:     jwtAuth,
+		userService:       userService,
+		permissionService: permissionService,
+		totpService:       totpService,
 	}
 }
 
@@ -35,52 +45,6 @@ type UserRequest struct {
 	RoleIDs  []uint `json:"roleIds"`
 }
 
-// Login 用户登录
-func (h *UserHandler) Login(c *gin.Context) {
-	var req struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
-	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, err.Error())
-		return
-	}
-
-	token, err := h.userService.Login(req.Username, req.Password)
-	if err != nil {
-		response.Unauthorized(c, err.Error())
-		return
-	}
-
-	// 获取用户信息
-	claims, err := h.jwtAuth.ParseToken(token)
-	if err != nil {
-		response.Failed(c, err)
-		return
-	}
-
-	// 获取用户信息
-	user, err := h.userService.GetUserInfo(claims.UserID)
-	if err != nil {
-		response.Failed(c, err)
-		return
-	}
-
-	// 清除敏感信息
-	user.Password = ""
-
-	response.Success(c, gin.H{
-		"token": token,
-		"user":  user,
-	})
-}
-
-// Logout 用户登出
-func (h *UserHandler) Logout(c *gin.Context) {
-	response.Success(c, nil)
-}
-
 // Create 创建用户
 func (h *UserHandler) Create(c *gin.Context) {
 	var req UserRequest
@@ -148,6 +112,7 @@ func (h *UserHandler) Update(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	h.permissionService.InvalidateAll()
 
 	response.Success(c, user)
 }
@@ -165,6 +130,7 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	h.permissionService.InvalidateAll()
 
 	response.Success(c, nil)
 }
@@ -188,16 +154,19 @@ func (h *UserHandler) Get(c *gin.Context) {
 
 // List 获取用户列表
 func (h *UserHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	q, err := listquery.Parse(c, listquery.Options{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 
-	users, total, err := h.userService.List(page, pageSize)
+	users, total, err := h.userService.List(q.Page, q.PageSize)
 	if err != nil {
 		response.Failed(c, err)
 		return
 	}
 
-	response.PageSuccess(c, users, total)
+	response.ListSuccess(c, listquery.NewResult(users, total, q).PageData())
 }
 
 // GetRoles 获取用户角色
@@ -236,6 +205,7 @@ func (h *UserHandler) AssignRoles(c *gin.Context) {
 		response.Failed(c, err)
 		return
 	}
+	h.permissionService.InvalidateAll()
 
 	response.Success(c, nil)
 }
@@ -261,3 +231,352 @@ func (h *UserHandler) GetUserInfo(c *gin.Context) {
 
 	response.Success(c, user)
 }
+
+// GetPermissions 获取当前用户的权限标识
+func (h *UserHandler) GetPermissions(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	perms, err := h.permissionService.GetUserPermissions(userID)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, perms)
+}
+
+// ChangePassword 修改当前用户的密码，修改后需重新登录
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	var req struct {
+		OldPassword string `json:"oldPassword" binding:"required"`
+		NewPassword string `json:"newPassword" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.userService.ChangePassword(userID, req.OldPassword, req.NewPassword); err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// TOTPCodeRequest 两步验证码请求
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// GetTOTPStatus 获取当前用户的两步验证状态
+func (h *UserHandler) GetTOTPStatus(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	status, err := h.totpService.Status(userID)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// SetupTOTP 生成两步验证绑定密钥
+func (h *UserHandler) SetupTOTP(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	setup, err := h.totpService.Setup(userID)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+// EnableTOTP 校验验证码并启用两步验证，返回恢复码
+func (h *UserHandler) EnableTOTP(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	codes, err := h.totpService.Enable(userID, req.Code)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"recoveryCodes": codes})
+}
+
+// DisableTOTP 校验验证码或恢复码并关闭两步验证
+func (h *UserHandler) DisableTOTP(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.totpService.Disable(userID, req.Code); err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (h *UserHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	codes, err := h.totpService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"recoveryCodes": codes})
+}
+
+// ResetTOTP 管理员重置用户的两步验证
+func (h *UserHandler) ResetTOTP(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	if err := h.totpService.Reset(uint(id)); err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// Unlock 管理员解除用户的登录锁定
+func (h *UserHandler) Unlock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	if err := h.userService.Unlock(uint(id)); err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// userImportMaxSize 导入文件大小上限
+const userImportMaxSize = 5 << 20
+
+// Import 从 CSV 或 XLSX 文件导入用户，dryRun=true 时只校验不写入，updateExisting=true 时更新已存在的用户
+func (h *UserHandler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, userImportMaxSize+(1<<20))
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "请上传导入文件")
+		return
+	}
+	if fileHeader.Size > userImportMaxSize {
+		response.BadRequest(c, "导入文件不能超过5MB")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	rows, err := utils.ReadSheet(fileHeader.Filename, data)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	opts := service.UserImportOptions{
+		DryRun:         isTrue(c.PostForm("dryRun")),
+		UpdateExisting: isTrue(c.PostForm("updateExisting")),
+	}
+	result, err := h.userService.ImportUsers(rows, opts)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if !opts.DryRun && (result.Created > 0 || result.Updated > 0) {
+		h.permissionService.InvalidateAll()
+	}
+
+	response.Success(c, result)
+}
+
+// ImportTemplate 下载用户导入模板
+func (h *UserHandler) ImportTemplate(c *gin.Context) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "user-import-template.csv"}))
+	c.Writer.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(service.UserImportHeaders)
+	writer.Write([]string{"zhangsan", "张三", "zhangsan@example.com", "13800000000", "ops,dev", "启用", ""})
+	writer.Flush()
+}
+
+// Export 导出全部用户，format 为 csv（默认）或 json；CSV 的列与导入模板一致，不包含密码
+func (h *UserHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		response.BadRequest(c, "不支持的导出格式")
+		return
+	}
+
+	users, err := h.userService.ListAll()
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("users-%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	if format == "json" {
+		c.JSON(http.StatusOK, users)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Writer.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(c.Writer)
+	header := append(append([]string{}, service.UserImportHeaders[:len(service.UserImportHeaders)-1]...), "来源", "创建时间")
+	writer.Write(header)
+	for _, user := range users {
+		codes := make([]string, 0, len(user.Roles))
+		for _, role := range user.Roles {
+			codes = append(codes, role.Code)
+		}
+		status := "启用"
+		if user.Status != 1 {
+			status = "禁用"
+		}
+		writer.Write([]string{
+			csvSafe(user.Username),
+			csvSafe(user.Nickname),
+			csvSafe(user.Email),
+			csvSafe(user.Phone),
+			strings.Join(codes, ","),
+			status,
+			user.Source,
+			user.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	writer.Flush()
+}
+
+// BatchStatus 批量启用或禁用用户，不能禁用当前登录用户
+func (h *UserHandler) BatchStatus(c *gin.Context) {
+	var req struct {
+		IDs    []uint `json:"ids" binding:"required"`
+		Status *int   `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if userID, _, ok := currentUser(c); ok && *req.Status != 1 {
+		for _, id := range req.IDs {
+			if id == userID {
+				response.BadRequest(c, "不能禁用当前登录用户")
+				return
+			}
+		}
+	}
+
+	affected, err := h.userService.BatchSetStatus(req.IDs, *req.Status)
+	if err != nil {
+		response.Failed(c, err)
+		return
+	}
+	h.permissionService.InvalidateAll()
+
+	response.Success(c, gin.H{"affected": affected})
+}
+
+// BatchRoles 批量分配用户角色，mode 为 replace（默认）、add 或 remove
+func (h *UserHandler) BatchRoles(c *gin.Context) {
+	var req struct {
+		UserIDs []uint `json:"userIds" binding:"required"`
+		RoleIDs []uint `json:"roleIds"`
+		Mode    string `json:"mode"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = model.RoleAssignReplace
+	}
+
+	if err := h.userService.BatchAssignRoles(req.UserIDs, req.RoleIDs, req.Mode); err != nil {
+		response.Failed(c, err)
+		return
+	}
+	h.permissionService.InvalidateAll()
+
+	response.Success(c, nil)
+}
+
+// isTrue 解析表单中的布尔值
+func isTrue(value string) bool {
+	b, _ := strconv.ParseBool(value)
+	return b
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// PermsAll 超级管理员拥有的全部权限标识
+const PermsAll = "*:*:*"
+
+// APIPermission 接口权限映射模型，将HTTP方法和路由模式映射到菜单/按钮的权限标识
+// 同一路由配置多条记录时，拥有其中任意一个权限标识即可访问
+type APIPermission struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Method      string    `gorm:"size:10;not null;uniqueIndex:uk_api_permission,priority:1" json:"method"`
+	Path        string    `gorm:"size:255;not null;uniqueIndex:uk_api_permission,priority:2" json:"path"` // 路由模式，如 /api/v1/users/:id
+	Perms       string    `gorm:"size:100;not null;uniqueIndex:uk_api_permission,priority:3" json:"perms"`
+	Description string    `gorm:"size:255" json:"description"`
+}
+
+// TableName 表名
+func (APIPermission) TableName() string {
+	return "sys_api_permission"
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APITokenPrefix API令牌前缀，用于与JWT访问令牌区分
+const APITokenPrefix = "eden_"
+
+// APIToken 用户API令牌模型，供脚本和CI调用接口，数据库中只保存令牌摘要
+type APIToken struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	UserID     uint       `gorm:"not null;index" json:"userId"`
+	Name       string     `gorm:"size:64;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"` // 令牌开头若干字符，用于识别令牌
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"size:1024" json:"-"` // 权限标识，逗号分隔
+	ExpiresAt  *time.Time `gorm:"index" json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	LastUsedIP string     `gorm:"size:64" json:"lastUsedIp"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	ScopeList  []string   `gorm:"-" json:"scopes"`
+}
+
+// TableName 表名
+func (APIToken) TableName() string {
+	return "sys_api_token"
+}
+
+// SetScopes 设置权限标识
+func (t *APIToken) SetScopes(scopes []string) {
+	t.Scopes = strings.Join(scopes, ",")
+	t.ScopeList = scopes
+}
+
+// AfterFind 查询后钩子
+func (t *APIToken) AfterFind(tx *gorm.DB) error {
+	t.ScopeList = splitScopes(t.Scopes)
+	return nil
+}
+
+// Active 令牌是否可用
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// splitScopes 拆分逗号分隔的权限标识
+func splitScopes(scopes string) []string {
+	result := make([]string, 0)
+	for _, scope := range strings.Split(scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
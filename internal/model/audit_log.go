@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+)
+
+// 审计结果
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailed  = "failed"
+)
+
+// AuditChange 字段变更，敏感字段的值已脱敏
+type AuditChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditLog 操作审计日志，只允许追加，不允许修改和删除
+type AuditLog struct {
+	ID           uint          `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time     `gorm:"index" json:"created_at"`
+	UserID       uint          `gorm:"index" json:"userId"`
+	Username     string        `gorm:"size:32;index" json:"username"`
+	ClientIP     string        `gorm:"size:64" json:"clientIp"`
+	UserAgent    string        `gorm:"size:255" json:"userAgent"`
+	APITokenID   uint          `gorm:"column:api_token_id" json:"apiTokenId"` // 使用API令牌访问时的令牌ID
+	Method       string        `gorm:"size:10" json:"method"`
+	Route        string        `gorm:"size:255" json:"route"` // 路由模板，如 /api/v1/users/:id
+	Path         string        `gorm:"size:512" json:"path"`  // 实际请求路径
+	Action       string        `gorm:"size:64;index" json:"action"`
+	ResourceType string        `gorm:"size:64;index:idx_audit_resource" json:"resourceType"`
+	ResourceID   string        `gorm:"size:64;index:idx_audit_resource" json:"resourceId"`
+	ResourceName string        `gorm:"size:255" json:"resourceName"`
+	Changes      []AuditChange `gorm:"type:mediumtext;serializer:json" json:"changes"`
+	Request      string        `gorm:"type:text" json:"request"` // 脱敏后的请求参数
+	Result       string        `gorm:"size:16;index" json:"result"`
+	StatusCode   int           `json:"statusCode"` // HTTP状态码
+	Code         int           `json:"code"`       // 业务响应码
+	Message      string        `gorm:"size:512" json:"message"`
+	Duration     int64         `json:"duration"` // 耗时，毫秒
+}
+
+// TableName 表名
+func (AuditLog) TableName() string {
+	return "sys_audit_log"
+}
+
+// AuditTarget 需要审计的接口操作
+type AuditTarget struct {
+	Action       string // 操作，如 create、update、delete、terminal
+	ResourceType string // 资源类型，如 users、k8s-configs
+	IDParam      string // 资源ID所在的路径参数名，为空表示路径中不含资源ID
+	SkipRequest  bool   // 不记录请求参数，用于验证码等一次性凭据
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// CloudResource 云资源清单模型，由云账号定时同步
+type CloudResource struct {
+	ID           int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	AccountID    int64      `gorm:"type:bigint;not null;uniqueIndex:uk_cloud_resource,priority:1" json:"accountId"`
+	Provider     string     `gorm:"type:varchar(50);not null;index" json:"provider"`
+	Region       string     `gorm:"type:varchar(50);not null;uniqueIndex:uk_cloud_resource,priority:2" json:"region"`
+	Zone         string     `gorm:"type:varchar(50)" json:"zone"`
+	ResourceType string     `gorm:"type:varchar(20);not null;uniqueIndex:uk_cloud_resource,priority:3;index" json:"resourceType"`
+	InstanceID   string     `gorm:"type:varchar(100);not null;uniqueIndex:uk_cloud_resource,priority:4" json:"instanceId"`
+	Name         string     `gorm:"type:varchar(255);index" json:"name"`
+	Status       string     `gorm:"type:varchar(50)" json:"status"`
+	PrivateIPs   string     `gorm:"column:private_ips;type:varchar(1024)" json:"privateIps"` // 逗号分隔
+	PublicIPs    string     `gorm:"column:public_ips;type:varchar(1024)" json:"publicIps"`   // 逗号分隔
+	Tags         string     `gorm:"type:text" json:"tags"`                                   // JSON格式
+	Spec         string     `gorm:"type:text" json:"spec"`                                   // JSON格式
+	CreateTime   *time.Time `gorm:"column:create_time" json:"createTime"`                    // 云上创建时间
+	SyncedAt     time.Time  `gorm:"not null" json:"syncedAt"`                                // 最近同步时间
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName 指定表名
+func (CloudResource) TableName() string {
+	return "infra_cloud_resource"
+}
+
+// CloudResourceResponse 云资源响应模型
+type CloudResourceResponse struct {
+	ID           int64             `json:"id"`
+	AccountID    int64             `json:"accountId"`
+	Provider     string            `json:"provider"`
+	Region       string            `json:"region"`
+	Zone         string            `json:"zone"`
+	ResourceType string            `json:"resourceType"`
+	InstanceID   string            `json:"instanceId"`
+	Name         string            `json:"name"`
+	Status       string            `json:"status"`
+	PrivateIPs   []string          `json:"privateIps"`
+	PublicIPs    []string          `json:"publicIps"`
+	Tags         map[string]string `json:"tags"`
+	Spec         map[string]string `json:"spec"`
+	CreateTime   *time.Time        `json:"createTime"`
+	SyncedAt     time.Time         `json:"syncedAt"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+}
+
+// ToResponse 转换为响应模型
+func (r *CloudResource) ToResponse() *CloudResourceResponse {
+	resp := &CloudResourceResponse{
+		ID:           r.ID,
+		AccountID:    r.AccountID,
+		Provider:     r.Provider,
+		Region:       r.Region,
+		Zone:         r.Zone,
+		ResourceType: r.ResourceType,
+		InstanceID:   r.InstanceID,
+		Name:         r.Name,
+		Status:       r.Status,
+		PrivateIPs:   SplitIPs(r.PrivateIPs),
+		PublicIPs:    SplitIPs(r.PublicIPs),
+		CreateTime:   r.CreateTime,
+		SyncedAt:     r.SyncedAt,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+
+	// 解析JSON字段
+	if r.Tags != "" {
+		json.Unmarshal([]byte(r.Tags), &resp.Tags)
+	}
+	if r.Spec != "" {
+		json.Unmarshal([]byte(r.Spec), &resp.Spec)
+	}
+
+	return resp
+}
+
+// JoinIPs 将IP列表拼接为逗号分隔字符串
+func JoinIPs(ips []string) string {
+	return strings.Join(ips, ",")
+}
+
+// SplitIPs 解析逗号分隔的IP列表
+func SplitIPs(value string) []string {
+	result := []string{}
+	for _, ip := range strings.Split(value, ",") {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			result = append(result, ip)
+		}
+	}
+	return result
+}
+
+// ArchiveReasonAccountRemoved 云账号已删除时的归档原因
+const ArchiveReasonAccountRemoved = "account_removed"
+
+// CloudResourceHistory 云资源历史记录模型，资源在云上消失后归档至此
+type CloudResourceHistory struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	OriginalID    int64      `gorm:"not null;index" json:"original_id"`                           // 原始资源ID
+	AccountID     int64      `gorm:"not null;index" json:"account_id"`                            // 云账号ID
+	Provider      string     `gorm:"size:50;not null" json:"provider"`                            // 云厂商代码
+	Region        string     `gorm:"size:50;not null" json:"region"`                              // 地域
+	Zone          string     `gorm:"size:50" json:"zone"`                                         // 可用区
+	ResourceType  string     `gorm:"size:20;not null;index" json:"resource_type"`                 // 资源类型
+	InstanceID    string     `gorm:"size:100;not null;index" json:"instance_id"`                  // 实例ID
+	Name          string     `gorm:"size:255" json:"name"`                                        // 实例名称
+	Status        string     `gorm:"size:50" json:"status"`                                       // 最后状态
+	PrivateIPs    string     `gorm:"column:private_ips;size:1024" json:"private_ips"`             // 内网IP
+	PublicIPs     string     `gorm:"column:public_ips;size:1024" json:"public_ips"`               // 公网IP
+	Tags          string     `gorm:"type:text" json:"tags"`                                       // 标签(JSON格式)
+	Spec          string     `gorm:"type:text" json:"spec"`                                       // 规格(JSON格式)
+	CreateTime    *time.Time `gorm:"column:create_time" json:"create_time"`                       // 云上创建时间
+	SyncedAt      time.Time  `gorm:"not null" json:"synced_at"`                                   // 最后同步时间
+	CreatedAt     time.Time  `gorm:"not null" json:"created_at"`                                  // 原始创建时间
+	UpdatedAt     time.Time  `gorm:"not null" json:"updated_at"`                                  // 原始更新时间
+	ArchivedAt    time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"archived_at"` // 归档时间
+	ArchiveReason string     `gorm:"size:100;default:'sync_cleanup';index" json:"archive_reason"` // 归档原因
+}
+
+// TableName 指定表名
+func (CloudResourceHistory) TableName() string {
+	return "infra_cloud_resource_history"
+}
@@ -0,0 +1,249 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// 角色数据范围
+const (
+	RoleDataScopeAll    = "all"    // 全部数据
+	RoleDataScopeCustom = "custom" // 按规则限定
+)
+
+// 数据范围规则类型
+const (
+	DataScopeTypeCluster      = "cluster"       // K8s集群，ResourceID 为 K8sConfig ID
+	DataScopeTypeNamespace    = "namespace"     // 命名空间，ResourceID 为 K8sConfig ID（0 表示所有已授权集群），Pattern 为通配符模式
+	DataScopeTypeServer       = "server"        // 服务器，ResourceID 为 ServerConfig ID
+	DataScopeTypeDatabase     = "database"      // 数据库，ResourceID 为 DatabaseConfig ID
+	DataScopeTypeCloudAccount = "cloud_account" // 云账号，ResourceID 为 CloudAccount ID
+)
+
+// RoleDataScope 角色数据范围规则模型
+type RoleDataScope struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	RoleID     uint      `gorm:"not null;index" json:"roleId"`
+	ScopeType  string    `gorm:"size:20;not null" json:"scopeType"`
+	ResourceID int64     `gorm:"not null;default:0" json:"resourceId"`
+	Pattern    string    `gorm:"size:128" json:"pattern"`
+}
+
+// TableName 表名
+func (RoleDataScope) TableName() string {
+	return "sys_role_data_scope"
+}
+
+// DataScope 用户的有效数据范围，由所有已启用角色的规则合并而来
+// 为 nil 或 All 为 true 时不做限制
+type DataScope struct {
+	All           bool
+	Clusters      map[int64][]string // 集群ID -> 命名空间模式，模式为空表示集群内全部命名空间
+	Servers       map[int64]struct{}
+	Databases     map[int64]struct{}
+	CloudAccounts map[int64]struct{}
+}
+
+// NewDataScope 创建空数据范围（不允许访问任何资源）
+func NewDataScope() *DataScope {
+	return &DataScope{
+		Clusters:      make(map[int64][]string),
+		Servers:       make(map[int64]struct{}),
+		Databases:     make(map[int64]struct{}),
+		CloudAccounts: make(map[int64]struct{}),
+	}
+}
+
+// Merge 合并一个自定义数据范围角色的规则
+func (s *DataScope) Merge(rules []*RoleDataScope) {
+	clusters := make(map[int64]struct{})
+	patterns := make(map[int64][]string)
+	var shared []string
+	for _, rule := range rules {
+		switch rule.ScopeType {
+		case DataScopeTypeCluster:
+			clusters[rule.ResourceID] = struct{}{}
+		case DataScopeTypeNamespace:
+			if rule.Pattern == "" {
+				continue
+			}
+			if rule.ResourceID == 0 {
+				shared = append(shared, rule.Pattern)
+			} else {
+				patterns[rule.ResourceID] = append(patterns[rule.ResourceID], rule.Pattern)
+			}
+		case DataScopeTypeServer:
+			s.Servers[rule.ResourceID] = struct{}{}
+		case DataScopeTypeDatabase:
+			s.Databases[rule.ResourceID] = struct{}{}
+		case DataScopeTypeCloudAccount:
+			s.CloudAccounts[rule.ResourceID] = struct{}{}
+		}
+	}
+
+	for id := range clusters {
+		rolePatterns := append(patterns[id], shared...)
+		existing, ok := s.Clusters[id]
+		switch {
+		case ok && len(existing) == 0:
+			// 其他角色已授权全部命名空间
+		case len(rolePatterns) == 0:
+			s.Clusters[id] = nil
+		default:
+			s.Clusters[id] = append(existing, rolePatterns...)
+		}
+	}
+}
+
+// Unrestricted 是否不限制数据范围
+func (s *DataScope) Unrestricted() bool {
+	return s == nil || s.All
+}
+
+// AllowsCluster 是否可访问集群（至少一个命名空间）
+func (s *DataScope) AllowsCluster(configID int64) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	_, ok := s.Clusters[configID]
+	return ok
+}
+
+// AllowsWholeCluster 是否可访问集群内全部命名空间
+func (s *DataScope) AllowsWholeCluster(configID int64) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	patterns, ok := s.Clusters[configID]
+	return ok && len(patterns) == 0
+}
+
+// AllowsNamespace 是否可访问集群内的命名空间
+func (s *DataScope) AllowsNamespace(configID int64, namespace string) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	patterns, ok := s.Clusters[configID]
+	if !ok {
+		return false
+	}
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if MatchNamespacePattern(pattern, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsServer 是否可访问服务器
+func (s *DataScope) AllowsServer(id int64) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	_, ok := s.Servers[id]
+	return ok
+}
+
+// AllowsDatabase 是否可访问数据库
+func (s *DataScope) AllowsDatabase(id int64) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	_, ok := s.Databases[id]
+	return ok
+}
+
+// AllowsCloudAccount 是否可访问云账号
+func (s *DataScope) AllowsCloudAccount(id int64) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	_, ok := s.CloudAccounts[id]
+	return ok
+}
+
+// ClusterIDs 可访问的集群ID，不限制时返回 nil
+func (s *DataScope) ClusterIDs() []int64 {
+	if s.Unrestricted() {
+		return nil
+	}
+	ids := make([]int64, 0, len(s.Clusters))
+	for id := range s.Clusters {
+		ids = append(ids, id)
+	}
+	return sortIDs(ids)
+}
+
+// ServerIDs 可访问的服务器ID
+func (s *DataScope) ServerIDs() []int64 {
+	if s.Unrestricted() {
+		return nil
+	}
+	return setIDs(s.Servers)
+}
+
+// DatabaseIDs 可访问的数据库ID
+func (s *DataScope) DatabaseIDs() []int64 {
+	if s.Unrestricted() {
+		return nil
+	}
+	return setIDs(s.Databases)
+}
+
+// CloudAccountIDs 可访问的云账号ID
+func (s *DataScope) CloudAccountIDs() []int64 {
+	if s.Unrestricted() {
+		return nil
+	}
+	return setIDs(s.CloudAccounts)
+}
+
+func setIDs(set map[int64]struct{}) []int64 {
+	ids := make([]int64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	return sortIDs(ids)
+}
+
+func sortIDs(ids []int64) []int64 {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// ValidNamespacePattern 命名空间模式只支持 * 和 ? 两种通配符，与列表查询转换的 LIKE 模式保持一致
+func ValidNamespacePattern(pattern string) bool {
+	return pattern != "" && !strings.ContainsAny(pattern, `[]\`)
+}
+
+// MatchNamespacePattern 判断命名空间是否匹配模式：* 匹配任意个字符，? 匹配单个字符，其余字符按原样比较
+func MatchNamespacePattern(pattern, namespace string) bool {
+	p, n := []rune(pattern), []rune(namespace)
+	i, j := 0, 0
+	// star 为最近一个 * 在模式中的位置，matched 为它已匹配到的命名空间位置，后续不匹配时让它多匹配一个字符
+	star, matched := -1, 0
+	for j < len(n) {
+		switch {
+		case i < len(p) && p[i] == '*':
+			star, matched = i, j
+			i++
+		case i < len(p) && (p[i] == '?' || p[i] == n[j]):
+			i++
+			j++
+		case star >= 0:
+			matched++
+			i, j = star+1, matched
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
@@ -0,0 +1,46 @@
+package model
+
+import "testing"
+
+func TestMatchNamespacePattern(t *testing.T) {
+	tests := []struct {
+		pattern, namespace string
+		want               bool
+	}{
+		{"*", "default", true},
+		{"*", "", true},
+		{"team-*", "team-a", true},
+		{"team-*", "team-", true},
+		{"team-*", "other", false},
+		{"team-?", "team-a", true},
+		{"team-?", "team-ab", false},
+		{"*-prod", "pay-prod", true},
+		{"*-prod", "pay-prod-2", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"a*a", "aaa", true},
+		{"**", "x", true},
+		{"team-[ab]*", "team-a1", false},
+		{"team-[ab]*", "team-[ab]x", true},
+		{"default", "default", true},
+		{"default", "defaults", false},
+	}
+	for _, tt := range tests {
+		if got := MatchNamespacePattern(tt.pattern, tt.namespace); got != tt.want {
+			t.Errorf("MatchNamespacePattern(%q, %q) = %v, want %v", tt.pattern, tt.namespace, got, tt.want)
+		}
+	}
+}
+
+func TestValidNamespacePattern(t *testing.T) {
+	for _, pattern := range []string{"default", "team-*", "team-?", "*"} {
+		if !ValidNamespacePattern(pattern) {
+			t.Errorf("ValidNamespacePattern(%q) = false, want true", pattern)
+		}
+	}
+	for _, pattern := range []string{"", "team-[ab]*", `team-\*`, "team-]"} {
+		if ValidNamespacePattern(pattern) {
+			t.Errorf("ValidNamespacePattern(%q) = true, want false", pattern)
+		}
+	}
+}
@@ -14,7 +14,7 @@ type DatabaseConfig struct {
 	Host        string         `json:"host" gorm:"type:varchar(100);not null;comment:主机地址"`
 	Port        int            `json:"port" gorm:"not null;comment:端口"`
 	Username    string         `json:"username" gorm:"type:varchar(100);not null;comment:用户名"`
-	Password    string         `json:"password" gorm:"type:varchar(100);not null;comment:密码"`
+	Password    string         `json:"password" gorm:"type:varchar(100);not null;comment:密码" export:"-"`
 	Database    string         `json:"database" gorm:"type:varchar(100);not null;comment:数据库名"`
 	Description string         `json:"description" gorm:"type:varchar(200);comment:数据库描述"`
 	Status      string         `json:"status" gorm:"type:varchar(20);not null;default:enabled;comment:状态(enabled/disabled)"`
@@ -23,7 +23,7 @@ type DatabaseConfig struct {
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relations
-	Provider *CloudProvider `json:"provider,omitempty" gorm:"foreignKey:ProviderID;constraint:OnDelete:SET NULL,OnUpdate:CASCADE;-"`
+	Provider *CloudProvider `json:"provider,omitempty" gorm:"foreignKey:ProviderID;constraint:OnDelete:SET NULL,OnUpdate:CASCADE;-" export:"-"`
 }
 
 // TableName specifies the table name for DatabaseConfig
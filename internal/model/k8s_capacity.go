@@ -0,0 +1,150 @@
+package model
+
+import (
+	"time"
+)
+
+// K8s Pod QoS 等级
+const (
+	K8sQoSGuaranteed = "Guaranteed"
+	K8sQoSBurstable  = "Burstable"
+	K8sQoSBestEffort = "BestEffort"
+)
+
+// 调整建议的动作
+const (
+	K8sRightsizingSet      = "set"      // 未设置请求，按用量设置
+	K8sRightsizingIncrease = "increase" // 用量接近请求，提高请求
+	K8sRightsizingDecrease = "decrease" // 用量远低于请求，降低请求
+)
+
+// K8sResourceAmount 资源量，CPU 以核、内存以字节为单位
+type K8sResourceAmount struct {
+	CPU    float64 `json:"cpu"`
+	Memory int64   `json:"memory"`
+}
+
+// K8sQoSCount 各 QoS 等级的Pod数
+type K8sQoSCount struct {
+	Guaranteed int `json:"guaranteed"`
+	Burstable  int `json:"burstable"`
+	BestEffort int `json:"bestEffort"`
+}
+
+// K8sCapacityShare 请求占集群可分配资源的百分比，以及限制与请求的比值（超卖倍数），请求为 0 时比值为 0
+type K8sCapacityShare struct {
+	CPURequestShare    float64 `json:"cpuRequestShare"`
+	MemoryRequestShare float64 `json:"memoryRequestShare"`
+	CPULimitRatio      float64 `json:"cpuLimitRatio"`
+	MemoryLimitRatio   float64 `json:"memoryLimitRatio"`
+}
+
+// K8sRightsizingRecommendation 按用量给出的请求调整建议，CPU 以核、内存以字节为单位
+type K8sRightsizingRecommendation struct {
+	Resource    string  `json:"resource"` // cpu、memory
+	Action      string  `json:"action"`
+	Current     float64 `json:"current"` // 当前每副本的请求
+	Usage       float64 `json:"usage"`   // 每个Pod的平均用量
+	Recommended float64 `json:"recommended"`
+	Reason      string  `json:"reason"`
+}
+
+// K8sWorkloadCapacity 工作负载的容量情况。每副本的请求和限制取自工作负载定义，合计值按实际运行的Pod累加
+type K8sWorkloadCapacity struct {
+	ID              int64                           `json:"id"`
+	Name            string                          `json:"name"`
+	Namespace       string                          `json:"namespace"`
+	Kind            string                          `json:"kind"`
+	Replicas        int                             `json:"replicas"`
+	PodCount        int                             `json:"podCount"`
+	ReplicaRequests K8sResourceAmount               `json:"replicaRequests"`
+	ReplicaLimits   K8sResourceAmount               `json:"replicaLimits"`
+	Requests        K8sResourceAmount               `json:"requests"`
+	Limits          K8sResourceAmount               `json:"limits"`
+	Share           K8sCapacityShare                `json:"share"`
+	MissingRequests []string                        `json:"missingRequests,omitempty"` // 未设置请求的资源：cpu、memory
+	MissingLimits   []string                        `json:"missingLimits,omitempty"`   // 未设置限制的资源
+	Usage           *K8sResourceAmount              `json:"usage,omitempty"`           // 每个Pod的平均用量，没有用量数据时为空
+	Recommendations []*K8sRightsizingRecommendation `json:"recommendations,omitempty"`
+	BestEffortPods  int                             `json:"bestEffortPods"` // BestEffort 的Pod数
+}
+
+// K8sNamespaceCapacity 命名空间的容量汇总
+type K8sNamespaceCapacity struct {
+	Namespace               string             `json:"namespace"`
+	PodCount                int                `json:"podCount"`
+	WorkloadCount           int                `json:"workloadCount"`
+	Requests                K8sResourceAmount  `json:"requests"`
+	Limits                  K8sResourceAmount  `json:"limits"`
+	Share                   K8sCapacityShare   `json:"share"`
+	QoS                     K8sQoSCount        `json:"qos"`
+	MissingRequestWorkloads int                `json:"missingRequestWorkloads"`
+	MissingLimitWorkloads   int                `json:"missingLimitWorkloads"`
+	Usage                   *K8sResourceAmount `json:"usage,omitempty"` // 命名空间内有用量数据的Pod的用量合计
+}
+
+// K8sCapacityPod 容量报告中列出的Pod，如 BestEffort Pod
+type K8sCapacityPod struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	WorkloadKind string `json:"workloadKind,omitempty"`
+	WorkloadName string `json:"workloadName,omitempty"`
+	NodeName     string `json:"nodeName"`
+}
+
+// K8sCapacityReport 集群或命名空间的容量规划报告。
+// 资源配置来自同步的数据，同步时按全部容器（含 init 容器）计算Pod的有效请求和限制；用量为 metrics-server 的实时采样，按全部容器合计
+type K8sCapacityReport struct {
+	ConfigID                int64                   `json:"configId"`
+	Namespace               string                  `json:"namespace,omitempty"`
+	GeneratedAt             time.Time               `json:"generatedAt"`
+	NodeCount               int                     `json:"nodeCount"`
+	Allocatable             K8sResourceAmount       `json:"allocatable"` // 全部节点的可分配资源
+	PodCount                int                     `json:"podCount"`
+	WorkloadCount           int                     `json:"workloadCount"`
+	Requests                K8sResourceAmount       `json:"requests"`
+	Limits                  K8sResourceAmount       `json:"limits"`
+	Share                   K8sCapacityShare        `json:"share"`
+	QoS                     K8sQoSCount             `json:"qos"`
+	MissingRequestWorkloads int                     `json:"missingRequestWorkloads"`
+	MissingLimitWorkloads   int                     `json:"missingLimitWorkloads"`
+	RecommendationCount     int                     `json:"recommendationCount"`
+	UsageAvailable          bool                    `json:"usageAvailable"`
+	Namespaces              []*K8sNamespaceCapacity `json:"namespaces"`
+	Workloads               []*K8sWorkloadCapacity  `json:"workloads"`
+	BestEffortPods          []*K8sCapacityPod       `json:"bestEffortPods"`
+	Truncated               bool                    `json:"truncated"`          // 资源数量超过上限，只统计了部分资源
+	Warnings                []string                `json:"warnings,omitempty"` // 如获取用量失败
+}
+
+// K8sCapacitySummary 每周生成的集群容量汇总，每个集群每周一条，重复生成时覆盖
+type K8sCapacitySummary struct {
+	ID                      uint                    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConfigID                int64                   `gorm:"not null;uniqueIndex:uk_config_week,priority:1" json:"configId"`
+	ConfigName              string                  `gorm:"size:100" json:"configName"`
+	WeekStart               time.Time               `gorm:"type:date;not null;uniqueIndex:uk_config_week,priority:2;index" json:"weekStart"` // 所在周的周一
+	NodeCount               int                     `gorm:"not null;default:0" json:"nodeCount"`
+	PodCount                int                     `gorm:"not null;default:0" json:"podCount"`
+	WorkloadCount           int                     `gorm:"not null;default:0" json:"workloadCount"`
+	CPUAllocatable          float64                 `gorm:"not null;default:0" json:"cpuAllocatable"`
+	CPURequested            float64                 `gorm:"not null;default:0" json:"cpuRequested"`
+	CPURequestShare         float64                 `gorm:"not null;default:0" json:"cpuRequestShare"`
+	MemoryAllocatable       int64                   `gorm:"not null;default:0" json:"memoryAllocatable"`
+	MemoryRequested         int64                   `gorm:"not null;default:0" json:"memoryRequested"`
+	MemoryRequestShare      float64                 `gorm:"not null;default:0" json:"memoryRequestShare"`
+	MissingRequestWorkloads int                     `gorm:"not null;default:0" json:"missingRequestWorkloads"`
+	MissingLimitWorkloads   int                     `gorm:"not null;default:0" json:"missingLimitWorkloads"`
+	BestEffortPods          int                     `gorm:"not null;default:0" json:"bestEffortPods"`
+	RecommendationCount     int                     `gorm:"not null;default:0" json:"recommendationCount"`
+	UsageAvailable          bool                    `gorm:"not null;default:false" json:"usageAvailable"`
+	Namespaces              []*K8sNamespaceCapacity `gorm:"type:mediumtext;serializer:json" json:"namespaces"`
+	Workloads               []*K8sWorkloadCapacity  `gorm:"type:mediumtext;serializer:json" json:"workloads"` // 需要关注的工作负载：缺少请求或限制、有调整建议
+	Warnings                []string                `gorm:"type:text;serializer:json" json:"warnings"`
+	CreatedAt               time.Time               `json:"createdAt"`
+}
+
+// TableName 指定表名
+func (K8sCapacitySummary) TableName() string {
+	return "infra_k8s_capacity_summary"
+}
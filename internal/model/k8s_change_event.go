@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+)
+
+// K8s资源变更动作
+const (
+	K8sChangeActionCreated = "created"
+	K8sChangeActionUpdated = "updated"
+	K8sChangeActionDeleted = "deleted"
+)
+
+// K8s资源变更来源
+const (
+	K8sChangeSourceSync = "sync" // 集群同步时与上次同步的状态比较得出
+)
+
+// K8sFieldChange 单个字段的变更，字段名为数据库列名，标签类字段展开为 labels.<key>
+type K8sFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// K8sChangeEvent K8s资源变更事件，每次同步中每个发生变化的对象一条
+type K8sChangeEvent struct {
+	ID           uint             `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConfigID     int64            `gorm:"not null;index:idx_config_observed;index:idx_object,priority:1" json:"configId"`
+	Kind         string           `gorm:"size:20;not null;index:idx_object,priority:2" json:"kind"` // pod、node、workload
+	WorkloadKind string           `gorm:"size:20" json:"workloadKind,omitempty"`                    // 工作负载类型，如 Deployment
+	Namespace    string           `gorm:"size:100;index:idx_object,priority:3" json:"namespace"`    // 节点为空
+	Name         string           `gorm:"size:255;not null;index:idx_object,priority:4" json:"name"`
+	Action       string           `gorm:"size:20;not null" json:"action"`
+	Changes      []K8sFieldChange `gorm:"type:mediumtext;serializer:json" json:"changes"`
+	Fields       string           `gorm:"size:1024" json:"fields"` // 变更的字段名，逗号分隔，用于按字段筛选
+	Source       string           `gorm:"size:20;not null" json:"source"`
+	ObservedAt   time.Time        `gorm:"not null;index:idx_config_observed;index" json:"observedAt"` // 发现变更的同步时间
+}
+
+// TableName 指定表名
+func (K8sChangeEvent) TableName() string {
+	return "infra_k8s_change_event"
+}
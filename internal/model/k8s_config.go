@@ -12,7 +12,7 @@ type K8sConfig struct {
 	ID                     int64      `gorm:"primaryKey" json:"id"`
 	Name                   string     `gorm:"type:varchar(100);not null" json:"name"`
 	Description            string     `gorm:"type:text" json:"description"`
-	Kubeconfig             string     `gorm:"type:text;not null" json:"kubeconfig"`
+	Kubeconfig             string     `gorm:"type:text;not null" json:"kubeconfig" export:"-"`
 	ProviderId             *int64     `gorm:"column:provider_id" json:"providerId"`
 	ProviderName           string     `gorm:"-" json:"providerName"`
 	Status                 int        `gorm:"type:tinyint;default:1" json:"status"`
@@ -63,7 +63,7 @@ type K8sConfigResponse struct {
 	ID                     int64      `json:"id"`
 	Name                   string     `json:"name"`
 	Description            string     `json:"description"`
-	Kubeconfig             string     `json:"kubeconfig"`
+	Kubeconfig             string     `json:"kubeconfig" export:"-"`
 	ProviderId             *int64     `json:"providerId"`
 	ProviderName           string     `json:"providerName"`
 	Status                 int        `json:"status"`
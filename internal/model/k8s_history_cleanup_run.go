@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+)
+
+// 历史数据清理触发方式
+const (
+	CleanupTriggerScheduled = "scheduled" // 定时清理
+	CleanupTriggerManual    = "manual"    // 手动清理
+)
+
+// 历史数据清理状态
+const (
+	CleanupStatusRunning = "running"
+	CleanupStatusSuccess = "success"
+	CleanupStatusFailed  = "failed"
+)
+
+// K8sHistoryCleanupRun K8s历史数据清理记录，每次清理一条
+type K8sHistoryCleanupRun struct {
+	ID              uint                      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Trigger         string                    `gorm:"size:20;not null" json:"trigger"`                // 触发方式
+	Operator        string                    `gorm:"size:64" json:"operator"`                        // 手动清理的操作人
+	BeforeDate      time.Time                 `gorm:"not null" json:"beforeDate"`                     // 手动清理的截止时间，定时清理时为默认保留期的截止时间
+	Status          string                    `gorm:"size:20;not null;index" json:"status"`           // 清理状态
+	PodDeleted      int64                     `gorm:"not null;default:0" json:"podDeleted"`           // 删除的Pod历史行数
+	NodeDeleted     int64                     `gorm:"not null;default:0" json:"nodeDeleted"`          // 删除的Node历史行数
+	WorkloadDeleted int64                     `gorm:"not null;default:0" json:"workloadDeleted"`      // 删除的Workload历史行数
+	TotalDeleted    int64                     `gorm:"not null;default:0" json:"totalDeleted"`         // 删除的总行数
+	ArchivedRows    int64                     `gorm:"not null;default:0" json:"archivedRows"`         // 删除前归档的行数
+	ArchivedFiles   int                       `gorm:"not null;default:0" json:"archivedFiles"`        // 写入的归档文件数
+	Details         []K8sHistoryCleanupDetail `gorm:"type:mediumtext;serializer:json" json:"details"` // 按集群和类型的清理明细
+	Error           string                    `gorm:"type:text" json:"error"`                         // 失败原因
+	StartedAt       time.Time                 `gorm:"not null;index" json:"startedAt"`
+	FinishedAt      *time.Time                `json:"finishedAt"`
+	Duration        int64                     `gorm:"not null;default:0" json:"duration"` // 耗时，毫秒
+}
+
+// TableName 指定表名
+func (K8sHistoryCleanupRun) TableName() string {
+	return "infra_k8s_history_cleanup_run"
+}
+
+// K8sHistoryCleanupDetail 单个集群单类历史数据的清理明细，ConfigID 为 0 表示不区分集群
+type K8sHistoryCleanupDetail struct {
+	ConfigID      int64     `json:"configId"`
+	Kind          string    `json:"kind"`
+	RetentionDays int       `json:"retentionDays,omitempty"` // 手动清理时为 0
+	BeforeDate    time.Time `json:"beforeDate"`
+	Deleted       int64     `json:"deleted"`
+	Archived      int64     `json:"archived,omitempty"`      // 删除前归档的行数
+	ArchivedFiles int       `json:"archivedFiles,omitempty"` // 写入的归档文件数
+}
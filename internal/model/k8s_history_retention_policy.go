@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+// K8s历史数据类型，保留策略中的 all 表示全部类型
+const (
+	K8sHistoryKindPod      = "pod"
+	K8sHistoryKindNode     = "node"
+	K8sHistoryKindWorkload = "workload"
+	K8sHistoryKindAll      = "all"
+)
+
+// K8sHistoryKinds 全部历史数据类型
+var K8sHistoryKinds = []string{K8sHistoryKindPod, K8sHistoryKindNode, K8sHistoryKindWorkload}
+
+// K8sHistoryRetentionPolicy K8s历史数据保留策略。ConfigID 为 0 表示所有集群的默认策略，
+// 同一集群和类型只有一条策略；RetentionDays 为 0 表示永久保留
+type K8sHistoryRetentionPolicy struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConfigID      int64     `gorm:"not null;default:0;uniqueIndex:uk_config_kind" json:"configId"`
+	Kind          string    `gorm:"size:20;not null;uniqueIndex:uk_config_kind" json:"kind"`
+	RetentionDays int       `gorm:"not null" json:"retentionDays"`
+	Remark        string    `gorm:"size:255" json:"remark"`
+	ClusterName   string    `gorm:"-" json:"clusterName,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (K8sHistoryRetentionPolicy) TableName() string {
+	return "infra_k8s_history_retention_policy"
+}
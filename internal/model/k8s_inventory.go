@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+)
+
+// 时间点清单中对象的数据来源
+const (
+	K8sInventorySourceLive    = "live"    // 实时表，对象至今仍存在
+	K8sInventorySourceHistory = "history" // 历史表，对象已被删除
+)
+
+// K8sInventoryPod 某一时间点存在的Pod。字段取自对象最后一次同步的状态，RemovedAt 为同步发现其消失的时间
+type K8sInventoryPod struct {
+	OriginalID    int64      `json:"original_id"` // 实时表中的Pod ID
+	ConfigID      int64      `json:"config_id"`
+	WorkloadID    *int64     `json:"workload_id"`
+	Name          string     `json:"name"`
+	Namespace     string     `json:"namespace"`
+	WorkloadName  *string    `json:"workload_name"`
+	WorkloadKind  *string    `json:"workload_kind"`
+	Status        string     `json:"status"`
+	Phase         *string    `json:"phase"`
+	NodeName      *string    `json:"node_name"`
+	PodIP         *string    `json:"pod_ip"`
+	HostIP        *string    `json:"host_ip"`
+	InstanceIP    *string    `json:"instance_ip"`
+	CPURequest    *string    `json:"cpu_request"`
+	CPULimit      *string    `json:"cpu_limit"`
+	MemoryRequest *string    `json:"memory_request"`
+	MemoryLimit   *string    `json:"memory_limit"`
+	RestartCount  int        `json:"restart_count"`
+	StartTime     *time.Time `json:"start_time"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RemovedAt     *time.Time `json:"removed_at"`
+	Source        string     `json:"source"`
+}
+
+// K8sInventoryNode 某一时间点存在的节点
+type K8sInventoryNode struct {
+	OriginalID        int64      `json:"originalId"` // 实时表中的节点ID
+	ConfigID          int64      `json:"configId"`
+	Name              string     `json:"name"`
+	InternalIP        *string    `json:"internalIP"`
+	ExternalIP        *string    `json:"externalIP"`
+	Hostname          *string    `json:"hostname"`
+	OSImage           *string    `json:"osImage"`
+	KernelVersion     *string    `json:"kernelVersion"`
+	ContainerRuntime  *string    `json:"containerRuntime"`
+	KubeletVersion    *string    `json:"kubeletVersion"`
+	CPUCapacity       *string    `json:"cpuCapacity"`
+	MemoryCapacity    *string    `json:"memoryCapacity"`
+	PodsCapacity      *string    `json:"podsCapacity"`
+	CPUAllocatable    *string    `json:"cpuAllocatable"`
+	MemoryAllocatable *string    `json:"memoryAllocatable"`
+	PodsAllocatable   *string    `json:"podsAllocatable"`
+	Labels            *string    `json:"labels"`
+	Taints            *string    `json:"taints"`
+	Status            string     `json:"status"`
+	Ready             bool       `json:"ready"`
+	Schedulable       bool       `json:"schedulable"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	RemovedAt         *time.Time `json:"removedAt"`
+	Source            string     `json:"source"`
+}
+
+// K8sInventoryWorkload 某一时间点存在的工作负载
+type K8sInventoryWorkload struct {
+	OriginalID    int64      `json:"original_id"` // 实时表中的工作负载ID
+	ConfigID      int64      `json:"config_id"`
+	Name          string     `json:"name"`
+	Namespace     string     `json:"namespace"`
+	Kind          string     `json:"kind"`
+	Replicas      int        `json:"replicas"`
+	ReadyReplicas int        `json:"ready_replicas"`
+	Status        *string    `json:"status"`
+	Labels        *string    `json:"labels"`
+	Selector      *string    `json:"selector"`
+	Images        *string    `json:"images"`
+	CPURequest    *string    `json:"cpu_request"`
+	CPULimit      *string    `json:"cpu_limit"`
+	MemoryRequest *string    `json:"memory_request"`
+	MemoryLimit   *string    `json:"memory_limit"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RemovedAt     *time.Time `json:"removed_at"`
+	Source        string     `json:"source"`
+}
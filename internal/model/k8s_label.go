@@ -0,0 +1,16 @@
+package model
+
+// K8sLabel K8s资源标签索引，由同步时的标签 JSON 展开而来，每个对象的每个标签一条，用于标签选择器过滤和全局搜索
+type K8sLabel struct {
+	ID         int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConfigID   int64  `gorm:"not null;index:idx_config_kind_key_value,priority:1" json:"configId"`
+	Kind       string `gorm:"size:20;not null;index:idx_config_kind_key_value,priority:2;index:idx_kind_object,priority:1" json:"kind"` // pod、node、workload
+	ObjectID   int64  `gorm:"not null;index:idx_kind_object,priority:2" json:"objectId"`                                                // 实时表中的对象ID
+	LabelKey   string `gorm:"size:317;not null;index:idx_config_kind_key_value,priority:3" json:"key"`
+	LabelValue string `gorm:"size:63;not null;index:idx_config_kind_key_value,priority:4" json:"value"`
+}
+
+// TableName 指定表名
+func (K8sLabel) TableName() string {
+	return "infra_k8s_label"
+}
@@ -39,6 +39,7 @@ type K8sNodeHistory struct {
 	DeletedAt           *time.Time `json:"deleted_at"`                                         // 原始删除时间
 	ArchivedAt          time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"archived_at"` // 归档时间
 	ArchiveReason       string    `gorm:"size:100;default:'sync_cleanup';index" json:"archive_reason"` // 归档原因
+	RestoredAt          *time.Time `gorm:"index" json:"restored_at"`                                  // 从归档文件恢复的时间，未恢复为空
 }
 
 // TableName 指定表名
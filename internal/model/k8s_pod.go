@@ -26,12 +26,14 @@ type K8sPod struct {
 	CPULimit      *string    `json:"cpu_limit" gorm:"size:20;comment:CPU限制"`
 	MemoryRequest *string    `json:"memory_request" gorm:"size:20;comment:内存请求"`
 	MemoryLimit   *string    `json:"memory_limit" gorm:"size:20;comment:内存限制"`
+	QoSClass      string     `json:"qos_class" gorm:"size:20;comment:QoS等级"`
+	Labels        *string    `json:"labels" gorm:"type:text;comment:标签(JSON格式)"`
 	RestartCount  int        `json:"restart_count" gorm:"default:0;comment:重启次数"`
 	StartTime     *time.Time `json:"start_time" gorm:"comment:启动时间"`
 	CreatedAt     time.Time  `json:"created_at" gorm:"comment:创建时间"`
 	UpdatedAt     time.Time  `json:"updated_at" gorm:"comment:更新时间"`
 	DeletedAt     *time.Time `json:"deleted_at" gorm:"index;comment:删除时间"`
-	Config        *K8sConfig `json:"config,omitempty" gorm:"foreignKey:ConfigID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;-"`
+	Config        *K8sConfig `json:"config,omitempty" gorm:"foreignKey:ConfigID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE;-" export:"-"`
 }
 
 // TableName 指定表名
@@ -56,6 +58,7 @@ type K8sPodResponse struct {
 	InstanceIP          string     `json:"instance_ip"`
 	CPURequestLimits    string     `json:"cpu_request_limits"`    // CPU Request/Limits
 	MemoryRequestLimits string     `json:"memory_request_limits"` // 内存 Request/Limits
+	Labels              *string    `json:"labels"`                // 标签(JSON格式)
 	RestartCount        int        `json:"restart_count"`
 	RunningTime         string     `json:"running_time"` // 运行时间
 	StartTime           *time.Time `json:"start_time"`
@@ -81,6 +84,7 @@ func (p *K8sPod) ToResponse() *K8sPodResponse {
 		InstanceIP:          p.InstanceIP,
 		CPURequestLimits:    p.GetCPUResource(),
 		MemoryRequestLimits: p.GetMemoryResource(),
+		Labels:              p.Labels,
 		RestartCount:        p.RestartCount,
 		RunningTime:         p.GetRunningTime(),
 		StartTime:           p.StartTime,
@@ -0,0 +1,23 @@
+package model
+
+// K8sSearchHit 全局搜索命中的K8s资源
+type K8sSearchHit struct {
+	Kind         string `json:"kind"` // pod、node、workload
+	ID           int64  `json:"id"`
+	ConfigID     int64  `json:"configId"`
+	ConfigName   string `json:"configName"`
+	Namespace    string `json:"namespace,omitempty"`
+	Name         string `json:"name"`
+	WorkloadKind string `json:"workloadKind,omitempty"`
+	Status       string `json:"status"`
+	MatchField   string `json:"matchField"` // 命中的字段，如 name、pod_ip、images、labels.app
+	MatchValue   string `json:"matchValue"`
+}
+
+// K8sSearchResult 全局搜索结果，按资源类型分组
+type K8sSearchResult struct {
+	Keyword   string          `json:"keyword"`
+	Pods      []*K8sSearchHit `json:"pods"`
+	Workloads []*K8sSearchHit `json:"workloads"`
+	Nodes     []*K8sSearchHit `json:"nodes"`
+}
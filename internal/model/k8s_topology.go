@@ -0,0 +1,65 @@
+package model
+
+// K8s拓扑顶点类型
+const (
+	K8sTopologyKindWorkload = "workload"
+	K8sTopologyKindPod      = "pod"
+	K8sTopologyKindNode     = "node"
+	K8sTopologyKindService  = "service"
+	K8sTopologyKindIngress  = "ingress"
+)
+
+// K8s拓扑边类型
+const (
+	K8sTopologyEdgeOwns    = "owns"    // 工作负载 -> Pod
+	K8sTopologyEdgeRunsOn  = "runs_on" // Pod -> 节点
+	K8sTopologyEdgeSelects = "selects" // Service -> Pod
+	K8sTopologyEdgeRoutes  = "routes"  // Ingress -> Service
+)
+
+// K8s拓扑顶点健康状态
+const (
+	K8sTopologyHealthy  = "healthy"
+	K8sTopologyWarning  = "warning"
+	K8sTopologyCritical = "critical"
+	K8sTopologyUnknown  = "unknown"
+)
+
+// K8sTopologyVertex 拓扑图中的顶点
+type K8sTopologyVertex struct {
+	ID           string `json:"id"` // 如 workload:12、pod:34、node:node-1、service:default/web
+	Kind         string `json:"kind"`
+	ResourceID   int64  `json:"resourceId,omitempty"` // 工作负载、Pod、节点在库中的ID，Service、Ingress为实时查询，没有ID
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace,omitempty"`
+	WorkloadKind string `json:"workloadKind,omitempty"`
+	Status       string `json:"status"`
+	Health       string `json:"health"`
+	Reason       string `json:"reason,omitempty"` // 非 healthy 时的原因
+}
+
+// K8sTopologyEdge 拓扑图中的边
+type K8sTopologyEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+// K8sTopologyInsight 拓扑分析发现的问题
+type K8sTopologyInsight struct {
+	Type     string   `json:"type"` // single_node_replicas：多副本工作负载的全部Pod在同一节点
+	VertexID string   `json:"vertexId"`
+	Message  string   `json:"message"`
+	Related  []string `json:"related,omitempty"` // 相关顶点，如副本所在的节点
+}
+
+// K8sTopology 集群或命名空间的工作负载、Pod、节点拓扑
+type K8sTopology struct {
+	ConfigID  int64                 `json:"configId"`
+	Namespace string                `json:"namespace,omitempty"`
+	Vertices  []*K8sTopologyVertex  `json:"vertices"`
+	Edges     []*K8sTopologyEdge    `json:"edges"`
+	Insights  []*K8sTopologyInsight `json:"insights"`
+	Truncated bool                  `json:"truncated"`          // Pod数量超过上限，只返回了部分Pod
+	Warnings  []string              `json:"warnings,omitempty"` // 如实时查询Service、Ingress失败
+}
@@ -6,6 +6,13 @@ import (
 	"gorm.io/gorm"
 )
 
+// 菜单类型
+const (
+	MenuTypeDirectory = 0
+	MenuTypeMenu      = 1
+	MenuTypeButton    = 2
+)
+
 // Menu 菜单模型
 type Menu struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
@@ -16,12 +23,13 @@ type Menu struct {
 	Name      string         `gorm:"size:32;not null" json:"name"`
 	Path      string         `gorm:"size:128" json:"path"`
 	Component string         `gorm:"size:128" json:"component"`
-	Sort      int            `gorm:"default:0" json:"sort"`
+	Perms     string         `gorm:"size:100" json:"perms"` // 权限标识，如 system:user:list
+	Sort      int            `gorm:"column:sort_order;default:0" json:"sort"`
 	Icon      string         `gorm:"size:32" json:"icon"`
 	Status    int            `gorm:"default:1" json:"status"` // 1: 正常, 0: 禁用
 	Hidden    bool           `gorm:"default:false" json:"hidden"`
 	Cache     bool           `gorm:"default:false" json:"cache"`
-	Type      int            `gorm:"default:1" json:"type"` // 1: 菜单, 2: 按钮
+	Type      int            `gorm:"default:1" json:"type"` // 0: 目录, 1: 菜单, 2: 按钮
 	Roles     []*Role        `gorm:"many2many:sys_role_menu;" json:"roles,omitempty"`
 	Children  []*Menu        `gorm:"-" json:"children,omitempty"`
 }
@@ -43,3 +51,23 @@ func (m *Menu) AfterFind(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// MenuRouteMeta 前端路由元信息
+type MenuRouteMeta struct {
+	Title     string `json:"title"`
+	Icon      string `json:"icon,omitempty"`
+	Hidden    bool   `json:"hidden"`    // 不在侧边栏显示，路由仍可访问
+	KeepAlive bool   `json:"keepAlive"` // 缓存页面
+	Perms     string `json:"perms,omitempty"`
+}
+
+// MenuRoute 前端动态路由，由用户可见的目录和菜单构成
+type MenuRoute struct {
+	ID        uint          `json:"id"`
+	Name      string        `json:"name"` // 路由名称，全局唯一
+	Path      string        `json:"path"`
+	Component string        `json:"component"`
+	Redirect  string        `json:"redirect,omitempty"`
+	Meta      MenuRouteMeta `json:"meta"`
+	Children  []*MenuRoute  `json:"children,omitempty"`
+}
@@ -36,10 +36,22 @@ func AutoMigrate(db *gorm.DB) error {
 		&UserRole{},
 		&Menu{},
 		&RoleMenu{},
+		&APIPermission{},
+		&RoleDataScope{},
+		&UserSession{},
+		&RevokedToken{},
+		&UserRecoveryCode{},
+		&APIToken{},
+		&AuditLog{},
 		&CloudProvider{},
 		&CloudAccount{},
+		&CloudResource{},
+		&CloudResourceHistory{},
 		&DatabaseConfig{},
 		&ServerConfig{},
+		&ServerSession{},
+		&ServerPermission{},
+		&ServerFileAudit{},
 		&K8sConfig{},
 		&K8sWorkload{},
 		&K8sNamespace{},
@@ -48,6 +60,11 @@ func AutoMigrate(db *gorm.DB) error {
 		&K8sPodHistory{},
 		&K8sNodeHistory{},
 		&K8sWorkloadHistory{},
+		&K8sHistoryCleanupRun{},
+		&K8sHistoryRetentionPolicy{},
+		&K8sChangeEvent{},
+		&K8sLabel{},
+		&K8sCapacitySummary{},
 		&Migration{},
 	)
 }
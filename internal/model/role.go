@@ -6,6 +6,16 @@ import (
 	"gorm.io/gorm"
 )
 
+// RoleCodeAdmin 系统管理员角色编码
+const RoleCodeAdmin = "admin"
+
+// 批量分配角色方式
+const (
+	RoleAssignReplace = "replace" // 替换为指定角色
+	RoleAssignAdd     = "add"     // 追加指定角色
+	RoleAssignRemove  = "remove"  // 移除指定角色
+)
+
 // Role 角色模型
 type Role struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
@@ -16,6 +26,7 @@ type Role struct {
 	Code      string         `gorm:"size:32;uniqueIndex;not null" json:"code"`
 	Status    int            `gorm:"default:1" json:"status"` // 1: 正常, 0: 禁用
 	Remark    string         `gorm:"size:255" json:"remark"`
+	DataScope string         `gorm:"size:20;default:all" json:"data_scope"` // all: 全部数据, custom: 按规则限定
 	MenuIDs   []uint         `gorm:"-" json:"menu_ids,omitempty"`
 	Menus     []*Menu        `gorm:"many2many:sys_role_menu;" json:"menus,omitempty"`
 	Users     []*User        `gorm:"many2many:sys_user_role;" json:"users,omitempty"`
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"path"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -18,21 +20,38 @@ const (
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	Name        string         `json:"name" gorm:"type:varchar(100);not null;comment:服务器名称"`
-	Host        string         `json:"host" gorm:"type:varchar(100);not null;comment:主机地址"`
-	Port        int            `json:"port" gorm:"not null;comment:端口"`
-	Username    string         `json:"username" gorm:"type:varchar(100);not null;comment:用户名"`
-	Password    string         `json:"-" gorm:"type:varchar(100);comment:密码"` // 不返回密码
-	PrivateKey  string         `json:"-" gorm:"type:text;comment:私钥"`         // 不返回私钥
-	Description string         `json:"description" gorm:"type:varchar(200);comment:服务器描述"`
-	Status      ServerStatus   `json:"status" gorm:"type:varchar(20);not null;default:enabled;comment:状态(enabled/disabled)"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	Name               string         `json:"name" gorm:"type:varchar(100);not null;comment:服务器名称"`
+	Host               string         `json:"host" gorm:"type:varchar(100);not null;comment:主机地址"`
+	Port               int            `json:"port" gorm:"not null;comment:端口"`
+	Username           string         `json:"username" gorm:"type:varchar(100);not null;comment:用户名"`
+	Password           string         `json:"-" gorm:"type:varchar(100);comment:密码"` // 不返回密码
+	PrivateKey         string         `json:"-" gorm:"type:text;comment:私钥"`         // 不返回私钥
+	Description        string         `json:"description" gorm:"type:varchar(200);comment:服务器描述"`
+	SFTPPaths          string         `json:"sftp_paths" gorm:"column:sftp_allowed_paths;type:text;comment:SFTP允许访问的路径(每行一个)"`
+	HostKeyFingerprint string         `json:"host_key_fingerprint" gorm:"type:varchar(100);comment:主机公钥SHA256指纹，为空时首次连接登记"`
+	Status             ServerStatus   `json:"status" gorm:"type:varchar(20);not null;default:enabled;comment:状态(enabled/disabled)"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // TableName specifies the table name for ServerConfig
 func (ServerConfig) TableName() string {
 	return "infra_server_config"
 }
+
+// AllowedPaths 解析SFTP允许访问的路径列表，支持换行或逗号分隔
+func (s *ServerConfig) AllowedPaths() []string {
+	var paths []string
+	for _, p := range strings.FieldsFunc(s.SFTPPaths, func(r rune) bool {
+		return r == '\n' || r == '\r' || r == ','
+	}) {
+		p = strings.TrimSpace(p)
+		if p == "" || !strings.HasPrefix(p, "/") {
+			continue
+		}
+		paths = append(paths, path.Clean(p))
+	}
+	return paths
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// 服务器文件操作类型
+const (
+	FileActionList     = "list"
+	FileActionStat     = "stat"
+	FileActionDownload = "download"
+	FileActionUpload   = "upload"
+	FileActionMkdir    = "mkdir"
+	FileActionDelete   = "delete"
+)
+
+// ServerFileAudit 服务器文件操作审计记录
+type ServerFileAudit struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ServerID   uint      `json:"server_id" gorm:"not null;index;comment:服务器ID"`
+	ServerName string    `json:"server_name" gorm:"type:varchar(100);comment:服务器名称"`
+	UserID     uint      `json:"user_id" gorm:"not null;index;comment:用户ID"`
+	Username   string    `json:"username" gorm:"type:varchar(50);comment:用户名"`
+	ClientIP   string    `json:"client_ip" gorm:"type:varchar(64);comment:客户端IP"`
+	Action     string    `json:"action" gorm:"type:varchar(20);not null;index;comment:操作类型"`
+	Path       string    `json:"path" gorm:"type:varchar(1024);not null;comment:操作路径"`
+	Size       int64     `json:"size" gorm:"default:0;comment:传输字节数"`
+	Success    bool      `json:"success" gorm:"not null;comment:是否成功"`
+	ErrorMsg   string    `json:"error_msg" gorm:"type:varchar(500);comment:错误信息"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for ServerFileAudit
+func (ServerFileAudit) TableName() string {
+	return "infra_server_file_audit"
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+)
+
+// ServerSessionStatus 终端会话状态
+type ServerSessionStatus string
+
+const (
+	// ServerSessionStatusActive 进行中
+	ServerSessionStatusActive ServerSessionStatus = "active"
+	// ServerSessionStatusClosed 已关闭
+	ServerSessionStatusClosed ServerSessionStatus = "closed"
+	// ServerSessionStatusFailed 连接失败
+	ServerSessionStatusFailed ServerSessionStatus = "failed"
+)
+
+// 终端会话关闭原因
+const (
+	SessionCloseReasonClient   = "client_closed" // 客户端断开
+	SessionCloseReasonExit     = "shell_exit"    // Shell退出
+	SessionCloseReasonIdle     = "idle_timeout"  // 空闲超时
+	SessionCloseReasonError    = "error"         // 异常中断
+	SessionCloseReasonShutdown = "shutdown"      // 服务关闭
+)
+
+// ServerSession 服务器终端会话记录
+type ServerSession struct {
+	ID          uint                `json:"id" gorm:"primaryKey"`
+	ServerID    uint                `json:"server_id" gorm:"not null;index;comment:服务器ID"`
+	ServerName  string              `json:"server_name" gorm:"type:varchar(100);comment:服务器名称"`
+	Host        string              `json:"host" gorm:"type:varchar(100);comment:主机地址"`
+	UserID      uint                `json:"user_id" gorm:"not null;index;comment:用户ID"`
+	Username    string              `json:"username" gorm:"type:varchar(50);comment:用户名"`
+	ClientIP    string              `json:"client_ip" gorm:"type:varchar(64);comment:客户端IP"`
+	Cols        int                 `json:"cols" gorm:"comment:终端列数"`
+	Rows        int                 `json:"rows" gorm:"comment:终端行数"`
+	RecordFile  string              `json:"-" gorm:"type:varchar(255);comment:录像文件路径"`
+	RecordSize  int64               `json:"record_size" gorm:"default:0;comment:录像文件大小(字节)"`
+	Status      ServerSessionStatus `json:"status" gorm:"type:varchar(20);not null;default:active;index;comment:状态(active/closed/failed)"`
+	CloseReason string              `json:"close_reason" gorm:"type:varchar(50);comment:关闭原因"`
+	ErrorMsg    string              `json:"error_msg" gorm:"type:varchar(500);comment:错误信息"`
+	StartedAt   time.Time           `json:"started_at" gorm:"not null;index;comment:开始时间"`
+	EndedAt     *time.Time          `json:"ended_at" gorm:"comment:结束时间"`
+	Duration    int                 `json:"duration" gorm:"default:0;comment:持续时长(秒)"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// TableName specifies the table name for ServerSession
+func (ServerSession) TableName() string {
+	return "infra_server_session"
+}
+
+// ServerPermission 服务器终端访问授权，UserID 与 RoleID 二选一
+type ServerPermission struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ServerID  uint      `json:"server_id" gorm:"not null;index;comment:服务器ID"`
+	UserID    uint      `json:"user_id" gorm:"default:0;index;comment:用户ID"`
+	RoleID    uint      `json:"role_id" gorm:"default:0;index;comment:角色ID"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for ServerPermission
+func (ServerPermission) TableName() string {
+	return "infra_server_permission"
+}
@@ -6,6 +6,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// 用户来源
+const (
+	UserSourceLocal      = "local"   // 本地用户，使用本地密码登录
+	UserSourceLDAP       = "ldap"    // LDAP用户，登录时自动创建并同步角色
+	UserSourceOIDCPrefix = "oidc:"   // OIDC用户，后接身份提供方名称
+	UserSourceService    = "service" // 服务账号，不能登录，只能通过API令牌访问接口
+)
+
 // User 用户模型
 type User struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
@@ -19,8 +27,26 @@ type User struct {
 	Phone     string         `gorm:"size:32" json:"phone"`
 	Avatar    string         `gorm:"size:255" json:"avatar"`
 	Status    int            `gorm:"default:1" json:"status"` // 1: 正常, 0: 禁用
-	RoleIDs   []int64        `gorm:"-" json:"role_ids,omitempty"`
-	Roles     []*Role        `gorm:"many2many:sys_user_role;" json:"roles,omitempty"`
+	Source    string         `gorm:"size:64;default:local" json:"source"`
+	// PasswordChangeRequired 管理员创建或重置密码后，用户首次登录须修改密码
+	PasswordChangeRequired bool       `gorm:"default:false" json:"password_change_required"`
+	PasswordChangedAt      *time.Time `json:"password_changed_at"`
+	// TOTPEnabled 是否已启用两步验证；TOTPSecret 在启用前保存待确认的密钥
+	TOTPEnabled  bool    `gorm:"column:totp_enabled;default:false" json:"totp_enabled"`
+	TOTPSecret   string  `gorm:"column:totp_secret;size:64" json:"-"`
+	TOTPLastStep int64   `gorm:"column:totp_last_step;default:0" json:"-"` // 最近一次使用的验证码时间步，防止重放
+	RoleIDs      []int64 `gorm:"-" json:"role_ids,omitempty"`
+	Roles        []*Role `gorm:"many2many:sys_user_role;" json:"roles,omitempty"`
+}
+
+// IsLocal 是否为本地用户，未设置来源的历史用户视为本地用户
+func (u *User) IsLocal() bool {
+	return u.Source == "" || u.Source == UserSourceLocal
+}
+
+// IsServiceAccount 是否为服务账号
+func (u *User) IsServiceAccount() bool {
+	return u.Source == UserSourceService
 }
 
 // TableName 表名
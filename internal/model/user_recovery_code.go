@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// UserRecoveryCode 两步验证恢复码，丢失身份验证器时代替验证码登录，每个恢复码只能使用一次
+type UserRecoveryCode struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `gorm:"not null;index" json:"userId"`
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`
+	UsedAt    *time.Time `json:"usedAt"`
+}
+
+// TableName 表名
+func (UserRecoveryCode) TableName() string {
+	return "sys_user_recovery_code"
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// 会话失效原因
+const (
+	SessionRevokeLogout          = "logout"           // 用户登出
+	SessionRevokeLogoutAll       = "logout_all"       // 登出全部会话
+	SessionRevokeUserDisabled    = "user_disabled"    // 用户被禁用
+	SessionRevokeUserDeleted     = "user_deleted"     // 用户被删除
+	SessionRevokePasswordChanged = "password_changed" // 密码已修改
+	SessionRevokeTokenReuse      = "token_reuse"      // 刷新令牌被重复使用
+)
+
+// UserSession 用户登录会话模型，保存刷新令牌的摘要
+type UserSession struct {
+	ID                uint       `gorm:"primarykey" json:"id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	UserID            uint       `gorm:"not null;index" json:"userId"`
+	TokenHash         string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	PreviousTokenHash string     `gorm:"size:64;index" json:"-"`
+	ClientIP          string     `gorm:"size:64" json:"clientIp"`
+	UserAgent         string     `gorm:"size:255" json:"userAgent"`
+	ExpiresAt         time.Time  `gorm:"not null;index" json:"expiresAt"`
+	LastRefreshedAt   *time.Time `json:"lastRefreshedAt"`
+	RevokedAt         *time.Time `json:"revokedAt"`
+	RevokeReason      string     `gorm:"size:32" json:"revokeReason"`
+}
+
+// TableName 表名
+func (UserSession) TableName() string {
+	return "sys_user_session"
+}
+
+// Active 会话是否有效
+func (s *UserSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RevokedToken 已吊销的访问令牌，按 jti 拒绝
+type RevokedToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	JTI       string    `gorm:"column:jti;size:64;not null;uniqueIndex" json:"jti"`
+	UserID    uint      `gorm:"not null;index" json:"userId"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`
+	Reason    string    `gorm:"size:32" json:"reason"`
+}
+
+// TableName 表名
+func (RevokedToken) TableName() string {
+	return "sys_revoked_token"
+}
@@ -0,0 +1,81 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"eden-ops/pkg/config"
+)
+
+// 存储相关错误
+var (
+	ErrNotFound   = errors.New("归档文件不存在")
+	ErrInvalidKey = errors.New("无效的归档文件路径")
+)
+
+// Object 归档文件信息
+type Object struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// Sink 归档文件存储。Key 为以 / 分隔的相对路径
+type Sink interface {
+	// Name 存储类型
+	Name() string
+	// Put 写入文件，同名文件会被覆盖。body 会被完整读取，可能被读取多次
+	Put(ctx context.Context, key string, body io.ReadSeeker, size int64) error
+	// Get 读取文件，文件不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List 列出以 prefix 开头的文件，按路径排序
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// NewSink 根据配置创建归档存储
+func NewSink(cfg config.K8sHistoryArchiveConfig) (Sink, error) {
+	switch cfg.Sink {
+	case "", "local":
+		if cfg.LocalDir == "" {
+			return nil, errors.New("未配置归档目录 local_dir")
+		}
+		return NewLocalSink(cfg.LocalDir), nil
+	case "s3":
+		timeout := 5 * time.Minute
+		if cfg.S3.Timeout != "" {
+			d, err := time.ParseDuration(cfg.S3.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("无效的S3请求超时: %v", err)
+			}
+			timeout = d
+		}
+		return NewS3Sink(S3Options{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PathStyle: cfg.S3.PathStyle,
+			Timeout:   timeout,
+		})
+	default:
+		return nil, fmt.Errorf("不支持的归档存储类型: %s", cfg.Sink)
+	}
+}
+
+// validateKey 校验文件路径，不允许绝对路径、空段和 . .. 段
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
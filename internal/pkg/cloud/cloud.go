@@ -0,0 +1,127 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 驱动相关错误
+var (
+	ErrInvalidCredentials      = errors.New("云账号凭据无效")
+	ErrUnsupportedProvider     = errors.New("不支持的云厂商")
+	ErrUnsupportedResourceType = errors.New("不支持的资源类型")
+)
+
+// ResourceType 云资源类型
+type ResourceType string
+
+const (
+	ResourceTypeCLB     ResourceType = "CLB"
+	ResourceTypeCVM     ResourceType = "CVM"
+	ResourceTypeCFS     ResourceType = "CFS"
+	ResourceTypeMariaDB ResourceType = "MariaDB"
+	ResourceTypeRedis   ResourceType = "Redis"
+	ResourceTypeES      ResourceType = "ES"
+	ResourceTypeCKafka  ResourceType = "CKafka"
+)
+
+// Credential 云账号凭据
+type Credential struct {
+	AccessKey string
+	SecretKey string
+}
+
+// Region 云厂商地域
+type Region struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// Resource 云资源
+type Resource struct {
+	Type       ResourceType      `json:"type"`
+	InstanceID string            `json:"instance_id"`
+	Name       string            `json:"name"`
+	Region     string            `json:"region"`
+	Zone       string            `json:"zone,omitempty"`
+	Status     string            `json:"status"`
+	PrivateIPs []string          `json:"private_ips,omitempty"`
+	PublicIPs  []string          `json:"public_ips,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Spec       map[string]string `json:"spec,omitempty"`
+	CreateTime *time.Time        `json:"create_time,omitempty"`
+}
+
+// Options 驱动选项，Endpoint 用于指向私有化部署或测试用的模拟接口
+type Options struct {
+	Endpoint string        // 覆盖默认接入点，如 127.0.0.1:8080
+	Scheme   string        // 请求协议 HTTP/HTTPS，默认 HTTPS
+	Timeout  time.Duration // 单次请求超时
+}
+
+// Driver 云厂商驱动接口，按 CloudProvider.Code 注册
+type Driver interface {
+	// Code 云厂商代码
+	Code() string
+	// ResourceTypes 支持的资源类型
+	ResourceTypes() []ResourceType
+	// ValidateCredentials 校验凭据是否有效
+	ValidateCredentials(ctx context.Context, cred Credential) error
+	// ListRegions 列出可用地域
+	ListRegions(ctx context.Context, cred Credential) ([]Region, error)
+	// ListResources 列出指定地域下某类资源
+	ListResources(ctx context.Context, cred Credential, region string, resourceType ResourceType) ([]Resource, error)
+}
+
+// Factory 驱动构造函数
+type Factory func(opts Options) Driver
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register 注册云厂商驱动，重复注册会覆盖
+func Register(code string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[code] = factory
+}
+
+// NewDriver 根据云厂商代码创建驱动
+func NewDriver(code string, opts Options) (Driver, error) {
+	mu.RLock()
+	factory, ok := factories[code]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProvider, code)
+	}
+	return factory(opts), nil
+}
+
+// Codes 已注册的云厂商代码
+func Codes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	codes := make([]string, 0, len(factories))
+	for code := range factories {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Supports 判断驱动是否支持资源类型
+func Supports(d Driver, resourceType ResourceType) bool {
+	for _, t := range d.ResourceTypes() {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package cloud
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewDriverSelectsRegisteredProvider(t *testing.T) {
+	d, err := NewDriver(ProviderTencent, Options{})
+	if err != nil {
+		t.Fatalf("NewDriver(%q) error = %v", ProviderTencent, err)
+	}
+	if d.Code() != ProviderTencent {
+		t.Fatalf("driver code = %q, want %q", d.Code(), ProviderTencent)
+	}
+	if !Supports(d, ResourceTypeCVM) {
+		t.Fatal("tencent driver does not support CVM")
+	}
+	if Supports(d, ResourceType("RDS")) {
+		t.Fatal("tencent driver supports an unknown resource type")
+	}
+
+	found := false
+	for _, code := range Codes() {
+		found = found || code == ProviderTencent
+	}
+	if !found {
+		t.Fatalf("Codes() = %v, missing %q", Codes(), ProviderTencent)
+	}
+}
+
+func TestNewDriverRejectsUnknownProvider(t *testing.T) {
+	if _, err := NewDriver("unknown", Options{}); !errors.Is(err, ErrUnsupportedProvider) {
+		t.Fatalf("NewDriver(unknown) error = %v, want ErrUnsupportedProvider", err)
+	}
+}
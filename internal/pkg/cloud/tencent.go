@@ -0,0 +1,661 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	sdkerrors "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+
+	cfs "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cfs/v20190719"
+	ckafka "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/ckafka/v20190819"
+	clb "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/clb/v20180317"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	es "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/es/v20180416"
+	mariadb "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/mariadb/v20170312"
+	redis "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/redis/v20180412"
+)
+
+// ProviderTencent 腾讯云代码
+const ProviderTencent = "tencent"
+
+const (
+	// tencentDefaultRegion 校验凭据和查询地域时使用的默认地域
+	tencentDefaultRegion = "ap-guangzhou"
+	tencentPageSize      = 100
+)
+
+func init() {
+	Register(ProviderTencent, NewTencentDriver)
+}
+
+// tencentDriver 腾讯云驱动
+type tencentDriver struct {
+	opts Options
+}
+
+// NewTencentDriver 创建腾讯云驱动
+func NewTencentDriver(opts Options) Driver {
+	return &tencentDriver{opts: opts}
+}
+
+// Code 云厂商代码
+func (d *tencentDriver) Code() string {
+	return ProviderTencent
+}
+
+// ResourceTypes 支持的资源类型
+func (d *tencentDriver) ResourceTypes() []ResourceType {
+	return []ResourceType{
+		ResourceTypeCLB,
+		ResourceTypeCVM,
+		ResourceTypeCFS,
+		ResourceTypeMariaDB,
+		ResourceTypeRedis,
+		ResourceTypeES,
+		ResourceTypeCKafka,
+	}
+}
+
+// profile 构造客户端配置
+func (d *tencentDriver) profile() *profile.ClientProfile {
+	cpf := profile.NewClientProfile()
+	if d.opts.Endpoint != "" {
+		cpf.HttpProfile.Endpoint = d.opts.Endpoint
+	}
+	if d.opts.Scheme != "" {
+		cpf.HttpProfile.Scheme = strings.ToUpper(d.opts.Scheme)
+	}
+	if d.opts.Timeout > 0 {
+		cpf.HttpProfile.ReqTimeout = int(d.opts.Timeout.Seconds())
+		if cpf.HttpProfile.ReqTimeout == 0 {
+			cpf.HttpProfile.ReqTimeout = 1
+		}
+	}
+	return cpf
+}
+
+// credential 转换凭据
+func (d *tencentDriver) credential(cred Credential) *common.Credential {
+	return common.NewCredential(cred.AccessKey, cred.SecretKey)
+}
+
+// wrapError 将鉴权类错误转换为 ErrInvalidCredentials
+func (d *tencentDriver) wrapError(action string, err error) error {
+	var sdkErr *sdkerrors.TencentCloudSDKError
+	if errors.As(err, &sdkErr) {
+		if strings.HasPrefix(sdkErr.Code, "AuthFailure") || sdkErr.Code == "UnauthorizedOperation" {
+			return fmt.Errorf("%w: %s", ErrInvalidCredentials, sdkErr.Message)
+		}
+	}
+	return fmt.Errorf("%s失败: %v", action, err)
+}
+
+// ValidateCredentials 通过查询地域列表校验凭据
+func (d *tencentDriver) ValidateCredentials(ctx context.Context, cred Credential) error {
+	if cred.AccessKey == "" || cred.SecretKey == "" {
+		return fmt.Errorf("%w: AccessKey 或 SecretKey 为空", ErrInvalidCredentials)
+	}
+	_, err := d.ListRegions(ctx, cred)
+	return err
+}
+
+// ListRegions 列出CVM可用地域
+func (d *tencentDriver) ListRegions(ctx context.Context, cred Credential) ([]Region, error) {
+	client, err := cvm.NewClient(d.credential(cred), tencentDefaultRegion, d.profile())
+	if err != nil {
+		return nil, fmt.Errorf("创建CVM客户端失败: %v", err)
+	}
+
+	resp, err := client.DescribeRegionsWithContext(ctx, cvm.NewDescribeRegionsRequest())
+	if err != nil {
+		return nil, d.wrapError("查询地域", err)
+	}
+
+	regions := make([]Region, 0, len(resp.Response.RegionSet))
+	for _, r := range resp.Response.RegionSet {
+		regions = append(regions, Region{
+			ID:    str(r.Region),
+			Name:  str(r.RegionName),
+			State: str(r.RegionState),
+		})
+	}
+	return regions, nil
+}
+
+// ListResources 列出指定地域下某类资源
+func (d *tencentDriver) ListResources(ctx context.Context, cred Credential, region string, resourceType ResourceType) ([]Resource, error) {
+	switch resourceType {
+	case ResourceTypeCLB:
+		return d.listCLB(ctx, cred, region)
+	case ResourceTypeCVM:
+		return d.listCVM(ctx, cred, region)
+	case ResourceTypeCFS:
+		return d.listCFS(ctx, cred, region)
+	case ResourceTypeMariaDB:
+		return d.listMariaDB(ctx, cred, region)
+	case ResourceTypeRedis:
+		return d.listRedis(ctx, cred, region)
+	case ResourceTypeES:
+		return d.listES(ctx, cred, region)
+	case ResourceTypeCKafka:
+		return d.listCKafka(ctx, cred, region)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedResourceType, resourceType)
+	}
+}
+
+// listCLB 查询负载均衡实例
+func (d *tencentDriver) listCLB(ctx context.Context, cred Credential, region string) ([]Resource, error) {
+	client, err := clb.NewClient(d.credential(cred), region, d.profile())
+	if err != nil {
+		return nil, fmt.Errorf("创建CLB客户端失败: %v", err)
+	}
+
+	var resources []Resource
+	for offset := int64(0); ; offset += tencentPageSize {
+		req := clb.NewDescribeLoadBalancersRequest()
+		req.Offset = common.Int64Ptr(offset)
+		req.Limit = common.Int64Ptr(tencentPageSize)
+		resp, err := client.DescribeLoadBalancersWithContext(ctx, req)
+		if err != nil {
+			return nil, d.wrapError("查询CLB", err)
+		}
+
+		for _, lb := range resp.Response.LoadBalancerSet {
+			r := Resource{
+				Type:       ResourceTypeCLB,
+				InstanceID: str(lb.LoadBalancerId),
+				Name:       str(lb.LoadBalancerName),
+				Region:     region,
+				Status:     clbStatus(lb.Status),
+				Tags:       map[string]string{},
+				Spec: map[string]string{
+					"type":   str(lb.LoadBalancerType),
+					"vpc_id": str(lb.VpcId),
+					"domain": str(lb.LoadBalancerDomain),
+				},
+				CreateTime: parseTime(str(lb.CreateTime)),
+			}
+			if lb.MasterZone != nil {
+				r.Zone = str(lb.MasterZone.Zone)
+			}
+			vips := strs(lb.LoadBalancerVips)
+			if str(lb.LoadBalancerType) == "OPEN" {
+				r.PublicIPs = vips
+			} else {
+				r.PrivateIPs = vips
+			}
+			if ipv6 := str(lb.AddressIPv6); ipv6 != "" {
+				r.PublicIPs = append(r.PublicIPs, ipv6)
+			}
+			for _, tag := range lb.Tags {
+				r.Tags[str(tag.TagKey)] = str(tag.TagValue)
+			}
+			resources = append(resources, r)
+		}
+
+		if len(resp.Response.LoadBalancerSet) < tencentPageSize || uint64(offset+tencentPageSize) >= u64(resp.Response.TotalCount) {
+			break
+		}
+	}
+	return resources, nil
+}
+
+// listCVM 查询云服务器实例
+func (d *tencentDriver) listCVM(ctx context.Context, cred Credential, region string) ([]Resource, error) {
+	client, err := cvm.NewClient(d.credential(cred), region, d.profile())
+	if err != nil {
+		return nil, fmt.Errorf("创建CVM客户端失败: %v", err)
+	}
+
+	var resources []Resource
+	for offset := int64(0); ; offset += tencentPageSize {
+		req := cvm.NewDescribeInstancesRequest()
+		req.Offset = common.Int64Ptr(offset)
+		req.Limit = common.Int64Ptr(tencentPageSize)
+		resp, err := client.DescribeInstancesWithContext(ctx, req)
+		if err != nil {
+			return nil, d.wrapError("查询CVM", err)
+		}
+
+		for _, ins := range resp.Response.InstanceSet {
+			r := Resource{
+				Type:       ResourceTypeCVM,
+				InstanceID: str(ins.InstanceId),
+				Name:       str(ins.InstanceName),
+				Region:     region,
+				Status:     str(ins.InstanceState),
+				PrivateIPs: strs(ins.PrivateIpAddresses),
+				PublicIPs:  strs(ins.PublicIpAddresses),
+				Tags:       map[string]string{},
+				Spec: map[string]string{
+					"instance_type": str(ins.InstanceType),
+					"cpu":           strconv.FormatInt(i64(ins.CPU), 10),
+					"memory_gb":     strconv.FormatInt(i64(ins.Memory), 10),
+					"os":            str(ins.OsName),
+					"charge_type":   str(ins.InstanceChargeType),
+				},
+				CreateTime: parseTime(str(ins.CreatedTime)),
+			}
+			if ins.Placement != nil {
+				r.Zone = str(ins.Placement.Zone)
+			}
+			for _, tag := range ins.Tags {
+				r.Tags[str(tag.Key)] = str(tag.Value)
+			}
+			resources = append(resources, r)
+		}
+
+		if len(resp.Response.InstanceSet) < tencentPageSize || offset+tencentPageSize >= i64(resp.Response.TotalCount) {
+			break
+		}
+	}
+	return resources, nil
+}
+
+// listCFS 查询文件系统及其挂载点
+func (d *tencentDriver) listCFS(ctx context.Context, cred Credential, region string) ([]Resource, error) {
+	client, err := cfs.NewClient(d.credential(cred), region, d.profile())
+	if err != nil {
+		return nil, fmt.Errorf("创建CFS客户端失败: %v", err)
+	}
+
+	var resources []Resource
+	for offset := uint64(0); ; offset += tencentPageSize {
+		req := cfs.NewDescribeCfsFileSystemsRequest()
+		req.Offset = common.Uint64Ptr(offset)
+		req.Limit = common.Uint64Ptr(tencentPageSize)
+		resp, err := client.DescribeCfsFileSystemsWithContext(ctx, req)
+		if err != nil {
+			return nil, d.wrapError("查询CFS", err)
+		}
+
+		for _, fs := range resp.Response.FileSystems {
+			r := Resource{
+				Type:       ResourceTypeCFS,
+				InstanceID: str(fs.FileSystemId),
+				Name:       str(fs.FsName),
+				Region:     region,
+				Zone:       str(fs.Zone),
+				Status:     str(fs.LifeCycleState),
+				Tags:       map[string]string{},
+				Spec: map[string]string{
+					"protocol":     str(fs.Protocol),
+					"storage_type": str(fs.StorageType),
+					"size_bytes":   strconv.FormatUint(u64(fs.SizeByte), 10),
+				},
+				CreateTime: parseTime(str(fs.CreationTime)),
+			}
+			for _, tag := range fs.Tags {
+				r.Tags[str(tag.TagKey)] = str(tag.TagValue)
+			}
+
+			mountReq := cfs.NewDescribeMountTargetsRequest()
+			mountReq.FileSystemId = fs.FileSystemId
+			mountResp, err := client.DescribeMountTargetsWithContext(ctx, mountReq)
+			if err != nil {
+				return nil, d.wrapError("查询CFS挂载点", err)
+			}
+			for _, mt := range mountResp.Response.MountTargets {
+				if ip := str(mt.IpAddress); ip != "" {
+					r.PrivateIPs = append(r.PrivateIPs, ip)
+				}
+			}
+			resources = append(resources, r)
+		}
+
+		if len(resp.Response.FileSystems) < tencentPageSize || offset+tencentPageSize >= u64(resp.Response.TotalCount) {
+			break
+		}
+	}
+	return resources, nil
+}
+
+// listMariaDB 查询MariaDB实例
+func (d *tencentDriver) listMariaDB(ctx context.Context, cred Credential, region string) ([]Resource, error) {
+	client, err := mariadb.NewClient(d.credential(cred), region, d.profile())
+	if err != nil {
+		return nil, fmt.Errorf("创建MariaDB客户端失败: %v", err)
+	}
+
+	var resources []Resource
+	for offset := int64(0); ; offset += tencentPageSize {
+		req := mariadb.NewDescribeDBInstancesRequest()
+		req.Offset = common.Int64Ptr(offset)
+		req.Limit = common.Int64Ptr(tencentPageSize)
+		resp, err := client.DescribeDBInstancesWithContext(ctx, req)
+		if err != nil {
+			return nil, d.wrapError("查询MariaDB", err)
+		}
+
+		for _, ins := range resp.Response.Instances {
+			r := Resource{
+				Type:       ResourceTypeMariaDB,
+				InstanceID: str(ins.InstanceId),
+				Name:       str(ins.InstanceName),
+				Region:     region,
+				Zone:       str(ins.Zone),
+				Status:     str(ins.StatusDesc),
+				PrivateIPs: nonEmpty(str(ins.Vip), str(ins.Vipv6)),
+				PublicIPs:  nonEmpty(str(ins.WanVip), str(ins.WanVipv6)),
+				Tags:       map[string]string{},
+				Spec: map[string]string{
+					"cpu":        strconv.FormatInt(i64(ins.Cpu), 10),
+					"memory_gb":  strconv.FormatInt(i64(ins.Memory), 10),
+					"storage_gb": strconv.FormatInt(i64(ins.Storage), 10),
+					"engine":     str(ins.DbEngine),
+					"version":    str(ins.DbVersion),
+					"port":       strconv.FormatInt(i64(ins.Vport), 10),
+				},
+				CreateTime: parseTime(str(ins.CreateTime)),
+			}
+			for _, tag := range ins.ResourceTags {
+				r.Tags[str(tag.TagKey)] = str(tag.TagValue)
+			}
+			resources = append(resources, r)
+		}
+
+		if len(resp.Response.Instances) < tencentPageSize || uint64(offset+tencentPageSize) >= u64(resp.Response.TotalCount) {
+			break
+		}
+	}
+	return resources, nil
+}
+
+// listRedis 查询Redis实例
+func (d *tencentDriver) listRedis(ctx context.Context, cred Credential, region string) ([]Resource, error) {
+	client, err := redis.NewClient(d.credential(cred), region, d.profile())
+	if err != nil {
+		return nil, fmt.Errorf("创建Redis客户端失败: %v", err)
+	}
+
+	var resources []Resource
+	for offset := uint64(0); ; offset += tencentPageSize {
+		req := redis.NewDescribeInstancesRequest()
+		req.Offset = common.Uint64Ptr(offset)
+		req.Limit = common.Uint64Ptr(tencentPageSize)
+		resp, err := client.DescribeInstancesWithContext(ctx, req)
+		if err != nil {
+			return nil, d.wrapError("查询Redis", err)
+		}
+
+		for _, ins := range resp.Response.InstanceSet {
+			// WanIp 字段实际为实例的VPC内网地址
+			r := Resource{
+				Type:       ResourceTypeRedis,
+				InstanceID: str(ins.InstanceId),
+				Name:       str(ins.InstanceName),
+				Region:     region,
+				Status:     redisStatus(ins.Status),
+				PrivateIPs: nonEmpty(str(ins.WanIp), str(ins.IPv6)),
+				Tags:       map[string]string{},
+				Spec: map[string]string{
+					"size_mb":     strconv.FormatFloat(f64(ins.Size), 'f', -1, 64),
+					"engine":      str(ins.Engine),
+					"version":     str(ins.CurrentRedisVersion),
+					"shard_num":   strconv.FormatInt(i64(ins.RedisShardNum), 10),
+					"replica_num": strconv.FormatInt(i64(ins.RedisReplicasNum), 10),
+					"port":        strconv.FormatInt(i64(ins.Port), 10),
+				},
+				CreateTime: parseTime(str(ins.Createtime)),
+			}
+			if addr := str(ins.WanAddress); addr != "" {
+				host := addr
+				if idx := strings.LastIndex(addr, ":"); idx > 0 {
+					host = addr[:idx]
+				}
+				r.PublicIPs = append(r.PublicIPs, host)
+			}
+			for _, tag := range ins.InstanceTags {
+				r.Tags[str(tag.TagKey)] = str(tag.TagValue)
+			}
+			resources = append(resources, r)
+		}
+
+		if len(resp.Response.InstanceSet) < tencentPageSize || int64(offset+tencentPageSize) >= i64(resp.Response.TotalCount) {
+			break
+		}
+	}
+	return resources, nil
+}
+
+// listES 查询Elasticsearch实例
+func (d *tencentDriver) listES(ctx context.Context, cred Credential, region string) ([]Resource, error) {
+	client, err := es.NewClient(d.credential(cred), region, d.profile())
+	if err != nil {
+		return nil, fmt.Errorf("创建ES客户端失败: %v", err)
+	}
+
+	var resources []Resource
+	for offset := uint64(0); ; offset += tencentPageSize {
+		req := es.NewDescribeInstancesRequest()
+		req.Offset = common.Uint64Ptr(offset)
+		req.Limit = common.Uint64Ptr(tencentPageSize)
+		resp, err := client.DescribeInstancesWithContext(ctx, req)
+		if err != nil {
+			return nil, d.wrapError("查询ES", err)
+		}
+
+		for _, ins := range resp.Response.InstanceList {
+			r := Resource{
+				Type:       ResourceTypeES,
+				InstanceID: str(ins.InstanceId),
+				Name:       str(ins.InstanceName),
+				Region:     region,
+				Zone:       str(ins.Zone),
+				Status:     esStatus(ins.Status),
+				PrivateIPs: nonEmpty(str(ins.EsVip)),
+				Tags:       map[string]string{},
+				Spec: map[string]string{
+					"node_type":    str(ins.NodeType),
+					"node_num":     strconv.FormatUint(u64(ins.NodeNum), 10),
+					"disk_size_gb": strconv.FormatUint(u64(ins.DiskSize), 10),
+					"version":      str(ins.EsVersion),
+				},
+				CreateTime: parseTime(str(ins.CreateTime)),
+			}
+			for _, tag := range ins.TagList {
+				r.Tags[str(tag.TagKey)] = str(tag.TagValue)
+			}
+			resources = append(resources, r)
+		}
+
+		if len(resp.Response.InstanceList) < tencentPageSize || offset+tencentPageSize >= u64(resp.Response.TotalCount) {
+			break
+		}
+	}
+	return resources, nil
+}
+
+// listCKafka 查询CKafka实例
+func (d *tencentDriver) listCKafka(ctx context.Context, cred Credential, region string) ([]Resource, error) {
+	client, err := ckafka.NewClient(d.credential(cred), region, d.profile())
+	if err != nil {
+		return nil, fmt.Errorf("创建CKafka客户端失败: %v", err)
+	}
+
+	var resources []Resource
+	for offset := int64(0); ; offset += tencentPageSize {
+		req := ckafka.NewDescribeInstancesDetailRequest()
+		req.Offset = common.Int64Ptr(offset)
+		req.Limit = common.Int64Ptr(tencentPageSize)
+		resp, err := client.DescribeInstancesDetailWithContext(ctx, req)
+		if err != nil {
+			return nil, d.wrapError("查询CKafka", err)
+		}
+		if resp.Response.Result == nil {
+			break
+		}
+
+		for _, ins := range resp.Response.Result.InstanceList {
+			r := Resource{
+				Type:       ResourceTypeCKafka,
+				InstanceID: str(ins.InstanceId),
+				Name:       str(ins.InstanceName),
+				Region:     region,
+				Status:     ckafkaStatus(ins.Status),
+				Tags:       map[string]string{},
+				Spec: map[string]string{
+					"bandwidth_mbps": strconv.FormatInt(i64(ins.Bandwidth), 10),
+					"disk_size_gb":   strconv.FormatInt(i64(ins.DiskSize), 10),
+					"version":        str(ins.Version),
+					"instance_type":  str(ins.InstanceType),
+				},
+			}
+			if ins.CreateTime != nil && *ins.CreateTime > 0 {
+				t := time.Unix(*ins.CreateTime, 0)
+				r.CreateTime = &t
+			}
+			seen := map[string]bool{}
+			for _, vip := range append([]*ckafka.VipEntity{{Vip: ins.Vip, Vport: ins.Vport}}, ins.VipList...) {
+				if ip := str(vip.Vip); ip != "" && !seen[ip] {
+					seen[ip] = true
+					r.PrivateIPs = append(r.PrivateIPs, ip)
+				}
+			}
+			for _, tag := range ins.Tags {
+				r.Tags[str(tag.TagKey)] = str(tag.TagValue)
+			}
+			resources = append(resources, r)
+		}
+
+		if len(resp.Response.Result.InstanceList) < tencentPageSize || offset+tencentPageSize >= i64(resp.Response.Result.TotalCount) {
+			break
+		}
+	}
+	return resources, nil
+}
+
+// clbStatus CLB状态转换
+func clbStatus(status *uint64) string {
+	switch u64(status) {
+	case 0:
+		return "CREATING"
+	case 1:
+		return "NORMAL"
+	default:
+		return strconv.FormatUint(u64(status), 10)
+	}
+}
+
+// redisStatus Redis状态转换
+func redisStatus(status *int64) string {
+	switch i64(status) {
+	case 0:
+		return "TO_INIT"
+	case 1:
+		return "PROCESSING"
+	case 2:
+		return "RUNNING"
+	case -2:
+		return "ISOLATED"
+	case -3:
+		return "TO_DESTROY"
+	default:
+		return strconv.FormatInt(i64(status), 10)
+	}
+}
+
+// esStatus ES状态转换
+func esStatus(status *int64) string {
+	switch i64(status) {
+	case 0:
+		return "PROCESSING"
+	case 1:
+		return "NORMAL"
+	case -1:
+		return "STOPPED"
+	case -2:
+		return "DESTROYING"
+	case -3:
+		return "DESTROYED"
+	default:
+		return strconv.FormatInt(i64(status), 10)
+	}
+}
+
+// ckafkaStatus CKafka状态转换
+func ckafkaStatus(status *int64) string {
+	switch i64(status) {
+	case 0:
+		return "CREATING"
+	case 1:
+		return "RUNNING"
+	case 2:
+		return "DELETING"
+	case 5:
+		return "ISOLATED"
+	case 7:
+		return "UPGRADING"
+	default:
+		return strconv.FormatInt(i64(status), 10)
+	}
+}
+
+// parseTime 解析腾讯云接口返回的时间
+func parseTime(value string) *time.Time {
+	if value == "" || strings.HasPrefix(value, "0000") {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05Z", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// nonEmpty 过滤空字符串
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func str(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func strs(ps []*string) []string {
+	var result []string
+	for _, p := range ps {
+		if p != nil && *p != "" {
+			result = append(result, *p)
+		}
+	}
+	return result
+}
+
+func i64(p *int64) int64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func u64(p *uint64) uint64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func f64(p *float64) float64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
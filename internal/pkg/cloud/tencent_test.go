@@ -0,0 +1,114 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAccessKey = "AKIDtest"
+
+// newFakeTencentAPI 模拟腾讯云API，按 X-TC-Action 返回响应，凭据不是 testAccessKey 时返回鉴权失败
+func newFakeTencentAPI(t *testing.T, responses map[string]string) Driver {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.Contains(r.Header.Get("Authorization"), "Credential="+testAccessKey+"/") {
+			fmt.Fprint(w, `{"Response":{"Error":{"Code":"AuthFailure.SecretIdNotFound","Message":"SecretId 不存在"},"RequestId":"req-auth"}}`)
+			return
+		}
+		action := r.Header.Get("X-TC-Action")
+		body, ok := responses[action]
+		if !ok {
+			fmt.Fprintf(w, `{"Response":{"Error":{"Code":"InvalidAction","Message":"%s"},"RequestId":"req-action"}}`, action)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	d, err := NewDriver(ProviderTencent, Options{
+		Endpoint: strings.TrimPrefix(server.URL, "http://"),
+		Scheme:   "http",
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+const describeRegionsResponse = `{"Response":{"TotalCount":1,"RegionSet":[{"Region":"ap-guangzhou","RegionName":"华南地区(广州)","RegionState":"AVAILABLE"}],"RequestId":"req-regions"}}`
+
+func TestTencentValidateCredentials(t *testing.T) {
+	d := newFakeTencentAPI(t, map[string]string{"DescribeRegions": describeRegionsResponse})
+	ctx := context.Background()
+
+	if err := d.ValidateCredentials(ctx, Credential{AccessKey: testAccessKey, SecretKey: "secret"}); err != nil {
+		t.Fatalf("ValidateCredentials() with valid keys error = %v", err)
+	}
+	for _, cred := range []Credential{
+		{AccessKey: "AKIDwrong", SecretKey: "secret"},
+		{AccessKey: testAccessKey},
+		{SecretKey: "secret"},
+	} {
+		if err := d.ValidateCredentials(ctx, cred); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("ValidateCredentials(%q) error = %v, want ErrInvalidCredentials", cred.AccessKey, err)
+		}
+	}
+}
+
+func TestTencentListRegions(t *testing.T) {
+	d := newFakeTencentAPI(t, map[string]string{"DescribeRegions": describeRegionsResponse})
+
+	regions, err := d.ListRegions(context.Background(), Credential{AccessKey: testAccessKey, SecretKey: "secret"})
+	if err != nil {
+		t.Fatalf("ListRegions() error = %v", err)
+	}
+	want := Region{ID: "ap-guangzhou", Name: "华南地区(广州)", State: "AVAILABLE"}
+	if len(regions) != 1 || regions[0] != want {
+		t.Fatalf("ListRegions() = %+v, want [%+v]", regions, want)
+	}
+}
+
+func TestTencentListResources(t *testing.T) {
+	d := newFakeTencentAPI(t, map[string]string{
+		"DescribeInstances": `{"Response":{"TotalCount":1,"InstanceSet":[{
+			"InstanceId":"ins-1","InstanceName":"web","InstanceState":"RUNNING","InstanceType":"S5.MEDIUM4",
+			"CPU":2,"Memory":4,"OsName":"TencentOS","InstanceChargeType":"POSTPAID_BY_HOUR",
+			"PrivateIpAddresses":["10.0.0.2"],"PublicIpAddresses":["1.2.3.4"],
+			"Placement":{"Zone":"ap-guangzhou-3"},"Tags":[{"Key":"env","Value":"prod"}],
+			"CreatedTime":"2024-01-02T03:04:05Z"}],"RequestId":"req-ins"}}`,
+	})
+	cred := Credential{AccessKey: testAccessKey, SecretKey: "secret"}
+
+	resources, err := d.ListResources(context.Background(), cred, "ap-guangzhou", ResourceTypeCVM)
+	if err != nil {
+		t.Fatalf("ListResources(CVM) error = %v", err)
+	}
+	if len(resources) != 1 {
+		t.Fatalf("ListResources(CVM) returned %d resources, want 1", len(resources))
+	}
+	r := resources[0]
+	if r.InstanceID != "ins-1" || r.Name != "web" || r.Status != "RUNNING" || r.Zone != "ap-guangzhou-3" {
+		t.Errorf("resource = %+v", r)
+	}
+	if r.Spec["cpu"] != "2" || r.Spec["memory_gb"] != "4" || r.Tags["env"] != "prod" {
+		t.Errorf("resource spec = %v, tags = %v", r.Spec, r.Tags)
+	}
+	if len(r.PublicIPs) != 1 || r.PublicIPs[0] != "1.2.3.4" || r.CreateTime == nil {
+		t.Errorf("resource public IPs = %v, create time = %v", r.PublicIPs, r.CreateTime)
+	}
+
+	if _, err := d.ListResources(context.Background(), cred, "ap-guangzhou", ResourceType("RDS")); !errors.Is(err, ErrUnsupportedResourceType) {
+		t.Fatalf("ListResources(RDS) error = %v, want ErrUnsupportedResourceType", err)
+	}
+	if _, err := d.ListResources(context.Background(), Credential{AccessKey: "AKIDwrong", SecretKey: "secret"}, "ap-guangzhou", ResourceTypeCVM); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("ListResources() with wrong keys error = %v, want ErrInvalidCredentials", err)
+	}
+}
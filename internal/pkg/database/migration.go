@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -96,6 +97,38 @@ func parseScriptVersion(filename string) (version, description string, err error
 	return matches[1], matches[2], nil
 }
 
+// sortScriptFiles 按版本号排序脚本文件，版本号各段按数值比较；文件名无法解析的排在最后，执行时再报错
+func sortScriptFiles(files []string) {
+	sort.SliceStable(files, func(i, j int) bool {
+		vi, vj := scriptVersionNumbers(files[i]), scriptVersionNumbers(files[j])
+		if vi == nil || vj == nil {
+			return vi != nil
+		}
+		for k := range vi {
+			if vi[k] != vj[k] {
+				return vi[k] < vj[k]
+			}
+		}
+		return false
+	})
+}
+
+// scriptVersionNumbers 解析脚本文件的版本号各段，文件名无效时返回 nil
+func scriptVersionNumbers(file string) []int {
+	version, _, err := parseScriptVersion(filepath.Base(file))
+	if err != nil {
+		return nil
+	}
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		if numbers[i], err = strconv.Atoi(part); err != nil {
+			return nil
+		}
+	}
+	return numbers
+}
+
 // isVersionTableExists 检查版本表是否存在
 func (s *MigrationService) isVersionTableExists() (bool, error) {
 	return s.db.Migrator().HasTable(&Migration{}), nil
@@ -174,8 +207,8 @@ func (s *MigrationService) Migrate(scriptDir string) error {
 	}
 	migrationLog("找到SQL脚本文件: %d个", len(files))
 
-	// 按文件名排序
-	sort.Strings(files)
+	// 按版本号排序，版本号各段按数值比较，V1.0.10 在 V1.0.9 之后
+	sortScriptFiles(files)
 
 	// 遍历所有SQL文件
 	for _, file := range files {
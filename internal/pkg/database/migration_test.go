@@ -0,0 +1,67 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSortScriptFilesByNumericVersion(t *testing.T) {
+	files := []string{
+		"scripts/sql/V1.0.10__Add_Login_Security_Schema.sql",
+		"scripts/sql/V1.0.1__Add_Infrastructure_Schema.sql",
+		"scripts/sql/V1.0.2__Add_Server_Terminal_Schema.sql",
+		"scripts/sql/invalid.sql",
+		"scripts/sql/V1.0.0__Initial_Schema.sql",
+		"scripts/sql/V1.1.0__Next_Minor.sql",
+		"scripts/sql/V1.0.9__Widen_User_Source.sql",
+	}
+	sortScriptFiles(files)
+
+	want := []string{
+		"V1.0.0__Initial_Schema.sql",
+		"V1.0.1__Add_Infrastructure_Schema.sql",
+		"V1.0.2__Add_Server_Terminal_Schema.sql",
+		"V1.0.9__Widen_User_Source.sql",
+		"V1.0.10__Add_Login_Security_Schema.sql",
+		"V1.1.0__Next_Minor.sql",
+		"invalid.sql",
+	}
+	for i, file := range files {
+		if got := filepath.Base(file); got != want[i] {
+			t.Fatalf("files[%d] = %s, want %s (order %v)", i, got, want[i], files)
+		}
+	}
+}
+
+func TestRepositoryScriptsRunInVersionOrder(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "..", "scripts", "sql", "V*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Skip("no migration scripts found")
+	}
+	sortScriptFiles(files)
+
+	var previous []int
+	for _, file := range files {
+		version := scriptVersionNumbers(file)
+		if version == nil {
+			t.Fatalf("invalid script name %s", filepath.Base(file))
+		}
+		if previous != nil && !versionLess(previous, version) {
+			t.Fatalf("%s does not follow the previous version %v", filepath.Base(file), previous)
+		}
+		previous = version
+	}
+}
+
+// versionLess 版本号 a 是否小于 b
+func versionLess(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvWriter CSV 导出
+type csvWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+// newCSVWriter 写入 BOM，便于 Excel 正确识别 UTF-8 编码
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: csv.NewWriter(w)}, nil
+}
+
+// WriteRow 写入一行，文本单元格以公式字符开头时加单引号，防止在 Excel 中被当作公式执行；
+// 表示空值的单个 - 不会被当作公式，保持原样
+func (w *csvWriter) WriteRow(cells []Cell) error {
+	w.record = w.record[:0]
+	for _, cell := range cells {
+		value := cell.Value
+		if !cell.Numeric && value != "" && value != "-" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			value = "'" + value
+		}
+		w.record = append(w.record, value)
+	}
+	return w.writer.Write(w.record)
+}
+
+// Flush 输出缓冲的行
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Close CSV 没有文件尾，只需输出剩余的行
+func (w *csvWriter) Close() error {
+	return w.Flush()
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 导出限制
+const (
+	// MaxRows 单次导出的最大行数
+	MaxRows = 100000
+	// BatchSize 每批从数据库读取的行数
+	BatchSize = 500
+)
+
+// ErrUnsupportedFormat 不支持的导出格式
+var ErrUnsupportedFormat = errors.New("不支持的导出格式")
+
+// Format 导出文件格式
+type Format string
+
+const (
+	// FormatCSV CSV，UTF-8 编码并带 BOM
+	FormatCSV Format = "csv"
+	// FormatXLSX Excel 工作簿
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat 解析导出格式，为空时默认 CSV
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, value)
+}
+
+// ContentType 响应的 Content-Type
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Extension 文件扩展名
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Cell 单元格，Numeric 为 true 时 Value 为数字的文本形式
+type Cell struct {
+	Value   string
+	Numeric bool
+}
+
+// Writer 逐行写出导出文件，写完后必须调用 Close
+type Writer interface {
+	WriteRow(cells []Cell) error
+	// Flush 将已写入的行输出到底层 io.Writer，每批数据写完后调用，避免在内存中积压
+	Flush() error
+	Close() error
+}
+
+// NewWriter 创建指定格式的 Writer
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
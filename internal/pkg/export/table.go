@@ -0,0 +1,221 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownColumn 导出列不存在
+var ErrUnknownColumn = errors.New("未知的导出列")
+
+// displayTimeLayout 格式化输出时的时间格式
+const displayTimeLayout = "2006-01-02 15:04:05"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// column 导出的一列，index 为字段在结构体中的位置
+type column struct {
+	key   string
+	index []int
+}
+
+// Table 将结构体转换为导出行。列名取字段的 json 标签，json:"-" 或 export:"-" 的字段不导出。
+// raw 为 false 时输出便于阅读的值：时间为本地时间、布尔值为是/否、map 和切片展开为逗号分隔的文本；
+// 为 true 时时间为 RFC3339、布尔值为 true/false、map 和切片为 JSON
+type Table struct {
+	columns []column
+	raw     bool
+}
+
+// NewTable 按 sample 的结构体类型创建导出表，keys 为要导出的列及顺序，为空时导出全部列
+func NewTable(sample interface{}, keys []string, raw bool) (*Table, error) {
+	t := reflect.TypeOf(sample)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("导出类型必须为结构体: %s", t)
+	}
+
+	all := make([]column, 0, t.NumField())
+	byKey := make(map[string]column, t.NumField())
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous || field.Tag.Get("export") == "-" {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+		if _, ok := byKey[key]; ok {
+			continue
+		}
+		col := column{key: key, index: field.Index}
+		all = append(all, col)
+		byKey[key] = col
+	}
+
+	table := &Table{columns: all, raw: raw}
+	if len(keys) == 0 {
+		return table, nil
+	}
+	table.columns = make([]column, 0, len(keys))
+	for _, key := range keys {
+		col, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownColumn, key)
+		}
+		table.columns = append(table.columns, col)
+	}
+	return table, nil
+}
+
+// ParseColumns 解析逗号分隔的列名，忽略空白和重复的列
+func ParseColumns(value string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Header 表头行
+func (t *Table) Header() []Cell {
+	cells := make([]Cell, len(t.columns))
+	for i, col := range t.columns {
+		cells[i] = Cell{Value: col.key}
+	}
+	return cells
+}
+
+// Row 将一条记录转换为一行，item 的类型必须与创建表时的 sample 相同
+func (t *Table) Row(item interface{}) []Cell {
+	v := reflect.ValueOf(item)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	cells := make([]Cell, len(t.columns))
+	for i, col := range t.columns {
+		field, err := v.FieldByIndexErr(col.index)
+		if err != nil {
+			// 嵌入的结构体指针为 nil
+			continue
+		}
+		cells[i] = t.cell(field)
+	}
+	return cells
+}
+
+// cell 将字段值转换为单元格
+func (t *Table) cell(v reflect.Value) Cell {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return Cell{}
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		tm := v.Interface().(time.Time)
+		if tm.IsZero() {
+			return Cell{}
+		}
+		if t.raw {
+			return Cell{Value: tm.Format(time.RFC3339)}
+		}
+		return Cell{Value: tm.Local().Format(displayTimeLayout)}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return Cell{Value: v.String()}
+	case reflect.Bool:
+		if t.raw {
+			return Cell{Value: strconv.FormatBool(v.Bool())}
+		}
+		if v.Bool() {
+			return Cell{Value: "是"}
+		}
+		return Cell{Value: "否"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Cell{Value: strconv.FormatInt(v.Int(), 10), Numeric: true}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Cell{Value: strconv.FormatUint(v.Uint(), 10), Numeric: true}
+	case reflect.Float32, reflect.Float64:
+		return Cell{Value: strconv.FormatFloat(v.Float(), 'f', -1, 64), Numeric: true}
+	case reflect.Map:
+		if !t.raw {
+			return Cell{Value: formatMap(v)}
+		}
+	case reflect.Slice, reflect.Array:
+		if !t.raw {
+			return Cell{Value: formatSlice(v)}
+		}
+	}
+
+	if (v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil() {
+		return Cell{}
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return Cell{Value: fmt.Sprint(v.Interface())}
+	}
+	return Cell{Value: string(data)}
+}
+
+// formatMap 按键排序输出为 key=value, key=value
+func formatMap(v reflect.Value) string {
+	items := make([]string, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		items = append(items, fmt.Sprintf("%v=%s", iter.Key().Interface(), formatScalar(iter.Value())))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ", ")
+}
+
+// formatSlice 输出为逗号分隔的元素
+func formatSlice(v reflect.Value) string {
+	items := make([]string, v.Len())
+	for i := range items {
+		items[i] = formatScalar(v.Index(i))
+	}
+	return strings.Join(items, ", ")
+}
+
+// formatScalar map 和切片中的元素，非基本类型输出为 JSON
+func formatScalar(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface())
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(data)
+}
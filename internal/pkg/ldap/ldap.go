@@ -0,0 +1,241 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"eden-ops/pkg/config"
+
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// defaultTimeout 默认连接及请求超时
+const defaultTimeout = 10 * time.Second
+
+// 认证错误
+var (
+	ErrInvalidCredentials = errors.New("LDAP用户名或密码错误")
+	ErrUserNotFound       = errors.New("LDAP用户不存在")
+	ErrMultipleUsers      = errors.New("LDAP用户过滤条件匹配到多个用户")
+)
+
+// Entry LDAP用户信息
+type Entry struct {
+	DN       string
+	Username string
+	Nickname string
+	Email    string
+	Phone    string
+	Groups   []string // 所属组DN
+}
+
+// Authenticator LDAP认证器，先使用查询账号搜索用户，再以用户DN和密码绑定校验
+type Authenticator struct {
+	cfg     config.LDAPConfig
+	timeout time.Duration
+}
+
+// NewAuthenticator 创建LDAP认证器
+func NewAuthenticator(cfg config.LDAPConfig) *Authenticator {
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(uid=%s)"
+	}
+	if cfg.UsernameAttribute == "" {
+		cfg.UsernameAttribute = "uid"
+	}
+	if cfg.GroupFilter == "" {
+		cfg.GroupFilter = "(member=%s)"
+	}
+	return &Authenticator{cfg: cfg, timeout: timeout}
+}
+
+// Authenticate 校验用户名和密码，返回用户信息及所属组
+func (a *Authenticator) Authenticate(username, password string) (*Entry, error) {
+	// 空密码会被服务端视为匿名绑定而成功，必须拒绝
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := a.bindService(conn); err != nil {
+		return nil, err
+	}
+
+	entry, err := a.searchUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("LDAP用户绑定失败: %v", err)
+	}
+
+	if a.cfg.GroupBaseDN != "" {
+		// 用户绑定后可能无权查询组，重新使用查询账号
+		if err := a.bindService(conn); err != nil {
+			return nil, err
+		}
+		groups, err := a.searchGroups(conn, entry.DN)
+		if err != nil {
+			return nil, err
+		}
+		entry.Groups = mergeGroups(entry.Groups, groups)
+	}
+
+	return entry, nil
+}
+
+// dial 建立连接，ldap:// 地址按配置升级为 StartTLS
+func (a *Authenticator) dial() (*goldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: a.cfg.InsecureSkipVerify}
+	if u, err := url.Parse(a.cfg.URL); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	conn, err := goldap.DialURL(a.cfg.URL,
+		goldap.DialWithDialer(&net.Dialer{Timeout: a.timeout}),
+		goldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("连接LDAP服务失败: %v", err)
+	}
+	conn.SetTimeout(a.timeout)
+
+	if a.cfg.StartTLS && strings.HasPrefix(strings.ToLower(a.cfg.URL), "ldap://") {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP StartTLS 失败: %v", err)
+		}
+	}
+	return conn, nil
+}
+
+// bindService 使用查询账号绑定，未配置时保持匿名
+func (a *Authenticator) bindService(conn *goldap.Conn) error {
+	if a.cfg.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(a.cfg.BindDN, a.cfg.BindPassword); err != nil {
+		return fmt.Errorf("LDAP查询账号绑定失败: %v", err)
+	}
+	return nil
+}
+
+// searchUser 按用户过滤条件搜索唯一用户
+func (a *Authenticator) searchUser(conn *goldap.Conn, username string) (*Entry, error) {
+	attributes := []string{"dn", a.cfg.UsernameAttribute}
+	for _, attr := range []string{a.cfg.NicknameAttribute, a.cfg.EmailAttribute, a.cfg.PhoneAttribute, a.cfg.GroupAttribute} {
+		if attr != "" {
+			attributes = append(attributes, attr)
+		}
+	}
+
+	result, err := conn.Search(goldap.NewSearchRequest(
+		a.cfg.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, int(a.timeout.Seconds()), false,
+		fmt.Sprintf(a.cfg.UserFilter, goldap.EscapeFilter(username)),
+		attributes, nil,
+	))
+	if err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+			return nil, ErrUserNotFound
+		}
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+			return nil, ErrMultipleUsers
+		}
+		return nil, fmt.Errorf("搜索LDAP用户失败: %v", err)
+	}
+	switch len(result.Entries) {
+	case 0:
+		return nil, ErrUserNotFound
+	case 1:
+	default:
+		return nil, ErrMultipleUsers
+	}
+
+	e := result.Entries[0]
+	entry := &Entry{
+		DN:       e.DN,
+		Username: e.GetAttributeValue(a.cfg.UsernameAttribute),
+		Nickname: attributeValue(e, a.cfg.NicknameAttribute),
+		Email:    attributeValue(e, a.cfg.EmailAttribute),
+		Phone:    attributeValue(e, a.cfg.PhoneAttribute),
+	}
+	if entry.Username == "" {
+		entry.Username = username
+	}
+	if a.cfg.GroupAttribute != "" {
+		entry.Groups = e.GetAttributeValues(a.cfg.GroupAttribute)
+	}
+	return entry, nil
+}
+
+// searchGroups 搜索用户所属的组
+func (a *Authenticator) searchGroups(conn *goldap.Conn, userDN string) ([]string, error) {
+	result, err := conn.Search(goldap.NewSearchRequest(
+		a.cfg.GroupBaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, int(a.timeout.Seconds()), false,
+		fmt.Sprintf(a.cfg.GroupFilter, goldap.EscapeFilter(userDN)),
+		[]string{"dn"}, nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("搜索LDAP用户组失败: %v", err)
+	}
+
+	groups := make([]string, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		groups = append(groups, e.DN)
+	}
+	return groups, nil
+}
+
+// GroupName 获取组DN的CN，如 CN=SRE,OU=Groups,DC=example,DC=com 返回 SRE；无法解析时原样返回
+func GroupName(groupDN string) string {
+	dn, err := goldap.ParseDN(groupDN)
+	if err != nil || len(dn.RDNs) == 0 {
+		return groupDN
+	}
+	for _, attr := range dn.RDNs[0].Attributes {
+		if strings.EqualFold(attr.Type, "cn") {
+			return attr.Value
+		}
+	}
+	return groupDN
+}
+
+// attributeValue 获取属性值，属性名为空时返回空字符串
+func attributeValue(e *goldap.Entry, attr string) string {
+	if attr == "" {
+		return ""
+	}
+	return e.GetAttributeValue(attr)
+}
+
+// mergeGroups 合并组列表并按不区分大小写去重
+func mergeGroups(groups, more []string) []string {
+	seen := make(map[string]struct{}, len(groups)+len(more))
+	merged := make([]string, 0, len(groups)+len(more))
+	for _, group := range append(groups, more...) {
+		key := strings.ToLower(group)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		merged = append(merged, group)
+	}
+	return merged
+}
@@ -0,0 +1,144 @@
+package ldap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"eden-ops/internal/pkg/ldap/ldaptest"
+	"eden-ops/pkg/config"
+)
+
+const (
+	testServiceDN = "cn=reader,dc=example,dc=com"
+	testAliceDN   = "uid=alice,ou=people,dc=example,dc=com"
+)
+
+// newTestDirectory 启动包含查询账号、用户和组的LDAP服务
+func newTestDirectory(t *testing.T) *ldaptest.Server {
+	return ldaptest.NewServer(t,
+		ldaptest.Entry{DN: testServiceDN, Password: "reader-secret"},
+		ldaptest.Entry{
+			DN:       testAliceDN,
+			Password: "alice-secret",
+			Attributes: map[string][]string{
+				"uid":         {"alice"},
+				"cn":          {"Alice"},
+				"mail":        {"alice@example.com"},
+				"memberOf":    {"cn=SRE,ou=groups,dc=example,dc=com"},
+				"departments": {"ops"},
+			},
+		},
+		ldaptest.Entry{
+			DN:         "uid=bob,ou=people,dc=example,dc=com",
+			Password:   "bob-secret",
+			Attributes: map[string][]string{"uid": {"bob"}, "departments": {"ops"}},
+		},
+		ldaptest.Entry{
+			DN:         "cn=sre,ou=groups,dc=example,dc=com",
+			Attributes: map[string][]string{"member": {testAliceDN}},
+		},
+		ldaptest.Entry{
+			DN:         "cn=dev,ou=groups,dc=example,dc=com",
+			Attributes: map[string][]string{"member": {testAliceDN, "uid=bob,ou=people,dc=example,dc=com"}},
+		},
+	)
+}
+
+func testConfig(url string) config.LDAPConfig {
+	return config.LDAPConfig{
+		Enabled:           true,
+		URL:               url,
+		Timeout:           "5s",
+		BindDN:            testServiceDN,
+		BindPassword:      "reader-secret",
+		BaseDN:            "ou=people,dc=example,dc=com",
+		NicknameAttribute: "cn",
+		EmailAttribute:    "mail",
+		GroupAttribute:    "memberOf",
+		GroupBaseDN:       "ou=groups,dc=example,dc=com",
+	}
+}
+
+func TestAuthenticateBindsAndSearchesGroups(t *testing.T) {
+	server := newTestDirectory(t)
+
+	entry, err := NewAuthenticator(testConfig(server.URL)).Authenticate("alice", "alice-secret")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if entry.DN != testAliceDN || entry.Username != "alice" || entry.Nickname != "Alice" || entry.Email != "alice@example.com" {
+		t.Fatalf("entry = %+v", entry)
+	}
+	// memberOf 与组搜索结果合并，大小写不同的同一组只保留一个
+	wantGroups := []string{"cn=SRE,ou=groups,dc=example,dc=com", "cn=dev,ou=groups,dc=example,dc=com"}
+	if !reflect.DeepEqual(entry.Groups, wantGroups) {
+		t.Fatalf("groups = %v, want %v", entry.Groups, wantGroups)
+	}
+	// 查询账号搜索用户，用户绑定校验密码，再用查询账号搜索组
+	wantBinds := []string{testServiceDN, testAliceDN, testServiceDN}
+	if binds := server.Binds(); !reflect.DeepEqual(binds, wantBinds) {
+		t.Fatalf("binds = %v, want %v", binds, wantBinds)
+	}
+}
+
+func TestAuthenticateFailures(t *testing.T) {
+	server := newTestDirectory(t)
+	a := NewAuthenticator(testConfig(server.URL))
+
+	tests := []struct {
+		username, password string
+		want               error
+	}{
+		{"alice", "wrong", ErrInvalidCredentials},
+		{"alice", "", ErrInvalidCredentials},
+		{"", "alice-secret", ErrInvalidCredentials},
+		{"carol", "secret", ErrUserNotFound},
+		// 过滤条件中的特殊字符必须转义，不能匹配任意用户
+		{"*", "alice-secret", ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := a.Authenticate(tt.username, tt.password); !errors.Is(err, tt.want) {
+			t.Errorf("Authenticate(%q, %q) error = %v, want %v", tt.username, tt.password, err, tt.want)
+		}
+	}
+	for _, dn := range server.Binds() {
+		if dn == testAliceDN {
+			t.Fatal("user bind succeeded with a wrong or empty password")
+		}
+	}
+}
+
+func TestAuthenticateRejectsAmbiguousFilter(t *testing.T) {
+	server := newTestDirectory(t)
+	cfg := testConfig(server.URL)
+	cfg.UserFilter = "(departments=%s)"
+
+	if _, err := NewAuthenticator(cfg).Authenticate("ops", "alice-secret"); !errors.Is(err, ErrMultipleUsers) {
+		t.Fatalf("Authenticate() error = %v, want ErrMultipleUsers", err)
+	}
+}
+
+func TestAuthenticateServiceBindFailure(t *testing.T) {
+	server := newTestDirectory(t)
+	cfg := testConfig(server.URL)
+	cfg.BindPassword = "wrong"
+
+	_, err := NewAuthenticator(cfg).Authenticate("alice", "alice-secret")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate() error = %v, want a service bind error distinct from invalid user credentials", err)
+	}
+}
+
+func TestGroupName(t *testing.T) {
+	for groupDN, want := range map[string]string{
+		"CN=SRE,OU=Groups,DC=example,DC=com": "SRE",
+		"cn=dev,ou=groups,dc=example,dc=com": "dev",
+		"ou=groups,dc=example,dc=com":        "ou=groups,dc=example,dc=com",
+		"not a dn":                           "not a dn",
+	} {
+		if got := GroupName(groupDN); got != want {
+			t.Errorf("GroupName(%q) = %q, want %q", groupDN, got, want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Package ldaptest 提供测试用的进程内LDAP服务，只实现简单绑定和子树搜索
+package ldaptest
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// Entry 目录条目，Password 为该DN绑定使用的密码，为空时不能绑定
+type Entry struct {
+	DN         string
+	Password   string
+	Attributes map[string][]string
+}
+
+// Server 进程内LDAP服务
+type Server struct {
+	URL string
+
+	listener net.Listener
+	entries  []Entry
+
+	mu    sync.Mutex
+	binds []string
+}
+
+// NewServer 启动LDAP服务，测试结束时自动关闭
+func NewServer(t testing.TB, entries ...Entry) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{URL: "ldap://" + listener.Addr().String(), listener: listener, entries: entries}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+// Binds 已成功绑定的DN，匿名绑定记为空字符串
+func (s *Server) Binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle 依次处理一个连接上的请求，直到客户端解绑或断开
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value
+		op := packet.Children[1]
+		switch op.Tag {
+		case goldap.ApplicationBindRequest:
+			err = s.bind(conn, messageID, op)
+		case goldap.ApplicationSearchRequest:
+			err = s.search(conn, messageID, op)
+		default:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// bind 处理简单绑定，DN 和密码均为空时为匿名绑定
+func (s *Server) bind(w io.Writer, messageID interface{}, op *ber.Packet) error {
+	dn, _ := op.Children[1].Value.(string)
+	password := op.Children[2].Data.String()
+
+	code := uint16(goldap.LDAPResultInvalidCredentials)
+	if dn == "" && password == "" {
+		code = goldap.LDAPResultSuccess
+	} else if entry := s.find(dn); entry != nil && entry.Password != "" && entry.Password == password {
+		code = goldap.LDAPResultSuccess
+	}
+	if code == goldap.LDAPResultSuccess {
+		s.mu.Lock()
+		s.binds = append(s.binds, dn)
+		s.mu.Unlock()
+	}
+	return write(w, messageID, result(goldap.ApplicationBindResponse, code))
+}
+
+// search 在 baseDN 子树中按过滤条件搜索，超过数量限制时返回 SizeLimitExceeded
+func (s *Server) search(w io.Writer, messageID interface{}, op *ber.Packet) error {
+	baseDN, _ := op.Children[0].Value.(string)
+	sizeLimit, _ := op.Children[3].Value.(int64)
+	filter := op.Children[6]
+	var attributes []string
+	for _, attr := range op.Children[7].Children {
+		if name, ok := attr.Value.(string); ok {
+			attributes = append(attributes, name)
+		}
+	}
+
+	sent := int64(0)
+	for i := range s.entries {
+		entry := &s.entries[i]
+		if !inSubtree(entry.DN, baseDN) || !matches(entry, filter) {
+			continue
+		}
+		if sizeLimit > 0 && sent == sizeLimit {
+			return write(w, messageID, result(goldap.ApplicationSearchResultDone, goldap.LDAPResultSizeLimitExceeded))
+		}
+		if err := write(w, messageID, searchEntry(entry, attributes)); err != nil {
+			return err
+		}
+		sent++
+	}
+	return write(w, messageID, result(goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess))
+}
+
+// find 按DN查找条目，不区分大小写
+func (s *Server) find(dn string) *Entry {
+	for i := range s.entries {
+		if strings.EqualFold(s.entries[i].DN, dn) {
+			return &s.entries[i]
+		}
+	}
+	return nil
+}
+
+// values 条目的属性值，属性名不区分大小写
+func (e *Entry) values(attr string) []string {
+	for name, values := range e.Attributes {
+		if strings.EqualFold(name, attr) {
+			return values
+		}
+	}
+	return nil
+}
+
+// inSubtree 条目是否位于 baseDN 子树中
+func inSubtree(dn, baseDN string) bool {
+	dn, baseDN = strings.ToLower(dn), strings.ToLower(baseDN)
+	return baseDN == "" || dn == baseDN || strings.HasSuffix(dn, ","+baseDN)
+}
+
+// matches 计算过滤条件，支持与、或、非、相等和存在判断
+func matches(entry *Entry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case goldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matches(entry, child) {
+				return false
+			}
+		}
+		return true
+	case goldap.FilterOr:
+		for _, child := range filter.Children {
+			if matches(entry, child) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterNot:
+		return len(filter.Children) == 1 && !matches(entry, filter.Children[0])
+	case goldap.FilterEqualityMatch:
+		attr, _ := filter.Children[0].Value.(string)
+		value, _ := filter.Children[1].Value.(string)
+		for _, v := range entry.values(attr) {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterPresent:
+		attr := filter.Data.String()
+		return strings.EqualFold(attr, "objectClass") || len(entry.values(attr)) > 0
+	default:
+		return false
+	}
+}
+
+// searchEntry 构造搜索结果条目，attributes 为空时返回全部属性
+func searchEntry(entry *Entry, attributes []string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "Object Name"))
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.Attributes {
+		if len(attributes) > 0 && !contains(attributes, name) {
+			continue
+		}
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attr.AppendChild(set)
+		list.AppendChild(attr)
+	}
+	op.AppendChild(list)
+	return op
+}
+
+// result 构造只包含结果码的响应
+func result(tag ber.Tag, code uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, goldap.ApplicationMap[uint8(tag)])
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, goldap.LDAPResultCodeMap[code], "Diagnostic Message"))
+	return op
+}
+
+// write 发送一条LDAP消息
+func write(w io.Writer, messageID interface{}, op *ber.Packet) error {
+	id, ok := messageID.(int64)
+	if !ok {
+		return errors.New("invalid message id")
+	}
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+	packet.AppendChild(op)
+	_, err := w.Write(packet.Bytes())
+	return err
+}
+
+// contains 名称列表中是否包含 name，不区分大小写
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package listquery
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// cursor 游标记录上一页最后一条记录的排序值和主键，以及生成游标时的排序方式和页码。
+// 对客户端不透明，编码为 base64 的 JSON
+type cursor struct {
+	Sort  string          `json:"s,omitempty"`
+	Order string          `json:"o"`
+	Page  int             `json:"p"`
+	Value json.RawMessage `json:"v,omitempty"`
+	Null  bool            `json:"n,omitempty"` // 排序值为 NULL
+	ID    json.RawMessage `json:"id"`
+}
+
+// encode 编码游标
+func (c *cursor) encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor 解码游标
+func decodeCursor(value string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || len(c.ID) == 0 || c.Page < 1 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
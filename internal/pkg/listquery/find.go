@@ -0,0 +1,189 @@
+package listquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// schemaCache 解析游标列时使用的模型结构缓存
+var schemaCache sync.Map
+
+// Find 按 q 的排序字段查询一页数据，query 为带筛选条件的查询。
+// 排序时以主键作为最后一个排序列；有游标时按 (排序列, 主键) 定位，翻页代价不随页数增加，
+// 同步过程中插入或删除数据也不会导致跳过或重复记录
+func Find[T any](query *gorm.DB, q *Query) (*Result[T], error) {
+	return find[T](query, q, "")
+}
+
+// FindWithOrder 使用仓库自定义的排序表达式（如按状态优先级排序）查询一页数据，只支持页码分页
+func FindWithOrder[T any](query *gorm.DB, q *Query, order string) (*Result[T], error) {
+	if q.cursor != nil {
+		return nil, ErrCursorUnsupported
+	}
+	return find[T](query, q, order)
+}
+
+// find 查询总数和一页数据，多查询一条用于判断是否还有下一页
+func find[T any](query *gorm.DB, q *Query, order string) (*Result[T], error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var keys *keyFields
+	if q.keyset && order == "" {
+		var err error
+		if keys, err = lookupKeyFields[T](query, q); err != nil {
+			return nil, err
+		}
+	}
+
+	page := query.Session(&gorm.Session{})
+	if order != "" {
+		page = page.Order(order).Offset(q.Offset())
+	} else {
+		if q.cursor != nil {
+			condition, args, err := keys.after(q)
+			if err != nil {
+				return nil, err
+			}
+			page = page.Where(condition, args...)
+		} else {
+			page = page.Offset(q.Offset())
+		}
+		if q.sortColumn != "" && q.sortColumn != q.idColumn {
+			page = page.Order(q.sortColumn + " " + q.SortOrder)
+		}
+		page = page.Order(q.idColumn + " " + q.SortOrder)
+	}
+
+	var list []T
+	if err := page.Limit(q.PageSize + 1).Find(&list).Error; err != nil {
+		return nil, err
+	}
+
+	result := &Result[T]{List: list, Total: total, Page: q.Page, PageSize: q.PageSize}
+	if len(list) > q.PageSize {
+		result.List = list[:q.PageSize]
+		result.HasMore = true
+	}
+	if result.HasMore && keys != nil {
+		next, err := keys.next(q, &result.List[len(result.List)-1])
+		if err != nil {
+			return nil, err
+		}
+		// 无法生成游标时不再提示有下一页，避免客户端拿到空游标
+		result.NextCursor = next
+		result.HasMore = next != ""
+	}
+	return result, nil
+}
+
+// keyFields 游标定位使用的排序列和主键对应的模型字段
+type keyFields struct {
+	sort *schema.Field // 按主键排序时为空
+	id   *schema.Field
+}
+
+// lookupKeyFields 查找排序列和主键在模型 T 中对应的字段
+func lookupKeyFields[T any](query *gorm.DB, q *Query) (*keyFields, error) {
+	s, err := schema.Parse(new(T), &schemaCache, query.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	keys := &keyFields{id: s.LookUpField(columnName(q.idColumn))}
+	if keys.id == nil {
+		return nil, fmt.Errorf("%s 中没有主键列 %s", s.Name, q.idColumn)
+	}
+	if q.sortColumn != "" && q.sortColumn != q.idColumn {
+		if keys.sort = s.LookUpField(columnName(q.sortColumn)); keys.sort == nil {
+			return nil, fmt.Errorf("%s 中没有排序列 %s", s.Name, q.sortColumn)
+		}
+	}
+	return keys, nil
+}
+
+// after 游标之后的记录满足的条件。MySQL 升序时 NULL 排在最前，降序时排在最后，
+// 排序值为 NULL 的记录按主键定位，并与非 NULL 的记录衔接
+func (k *keyFields) after(q *Query) (string, []interface{}, error) {
+	op := ">"
+	if q.SortOrder == "desc" {
+		op = "<"
+	}
+	id, err := decodeValue(k.id, q.cursor.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	if k.sort == nil {
+		return fmt.Sprintf("%s %s ?", q.idColumn, op), []interface{}{id}, nil
+	}
+	if q.cursor.Null {
+		if q.SortOrder == "desc" {
+			return fmt.Sprintf("(%s IS NULL AND %s < ?)", q.sortColumn, q.idColumn), []interface{}{id}, nil
+		}
+		return fmt.Sprintf("(%s IS NOT NULL OR %s > ?)", q.sortColumn, q.idColumn), []interface{}{id}, nil
+	}
+	value, err := decodeValue(k.sort, q.cursor.Value)
+	if err != nil {
+		return "", nil, err
+	}
+	condition := fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", q.sortColumn, op, q.sortColumn, q.idColumn, op)
+	if q.SortOrder == "desc" {
+		condition = fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?) OR %s IS NULL)", q.sortColumn, op, q.sortColumn, q.idColumn, op, q.sortColumn)
+	}
+	return condition, []interface{}{value, value, id}, nil
+}
+
+// next 以本页最后一条记录生成下一页的游标，排序值为空时在游标中标记为 NULL
+func (k *keyFields) next(q *Query, last interface{}) (string, error) {
+	row := reflect.ValueOf(last)
+	for row.Kind() == reflect.Pointer {
+		if row.IsNil() {
+			return "", nil
+		}
+		row = row.Elem()
+	}
+
+	cur := &cursor{Sort: q.SortBy, Order: q.SortOrder, Page: q.Page}
+	id, _ := k.id.ValueOf(context.Background(), row)
+	var err error
+	if cur.ID, err = json.Marshal(id); err != nil {
+		return "", err
+	}
+	if k.sort != nil {
+		value, _ := k.sort.ValueOf(context.Background(), row)
+		if value == nil || reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil() {
+			cur.Null = true
+		} else if cur.Value, err = json.Marshal(value); err != nil {
+			return "", err
+		}
+	}
+	return cur.encode()
+}
+
+// decodeValue 按字段类型解析游标中的值
+func decodeValue(field *schema.Field, raw json.RawMessage) (interface{}, error) {
+	value := reflect.New(field.FieldType)
+	if len(raw) == 0 || json.Unmarshal(raw, value.Interface()) != nil {
+		return nil, ErrInvalidCursor
+	}
+	if value.Elem().Kind() == reflect.Pointer && value.Elem().IsNil() {
+		return nil, ErrInvalidCursor
+	}
+	return value.Elem().Interface(), nil
+}
+
+// columnName 去掉列名的表名前缀
+func columnName(column string) string {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		return column[i+1:]
+	}
+	return column
+}
@@ -0,0 +1,122 @@
+package listquery
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type testRow struct {
+	ID   uint
+	Name *string
+}
+
+var testOptions = Options{
+	SortFields: map[string]string{"name": "name"},
+	Cursor:     true,
+}
+
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func parseQuery(t *testing.T, values url.Values) *Query {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?"+values.Encode(), nil)
+	q, err := Parse(c, testOptions)
+	if err != nil {
+		t.Fatalf("Parse(%v) error = %v", values, err)
+	}
+	return q
+}
+
+// nextQuery 以 last 作为上一页的最后一条记录，生成并解析下一页的查询
+func nextQuery(t *testing.T, db *gorm.DB, order string, last *testRow) (*Query, *keyFields) {
+	t.Helper()
+	q := parseQuery(t, url.Values{"sortBy": {"name"}, "sortOrder": {order}})
+	keys, err := lookupKeyFields[testRow](db, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := keys.next(q, last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == "" {
+		t.Fatalf("next(%+v) returned an empty cursor", last)
+	}
+	return parseQuery(t, url.Values{"sortBy": {"name"}, "sortOrder": {order}, "cursor": {next}}), keys
+}
+
+func TestCursorAfterNullSortValue(t *testing.T) {
+	db := dryRunDB(t)
+	name := "b"
+	tests := []struct {
+		order string
+		last  *testRow
+		want  string
+		args  int
+	}{
+		// 升序时 NULL 在最前：NULL 中主键更大的，以及全部非 NULL
+		{"asc", &testRow{ID: 7}, "(name IS NOT NULL OR id > ?)", 1},
+		// 降序时 NULL 在最后：只剩主键更小的 NULL
+		{"desc", &testRow{ID: 7}, "(name IS NULL AND id < ?)", 1},
+		{"asc", &testRow{ID: 7, Name: &name}, "(name > ? OR (name = ? AND id > ?))", 3},
+		// 降序时非 NULL 之后还有全部 NULL
+		{"desc", &testRow{ID: 7, Name: &name}, "(name < ? OR (name = ? AND id < ?) OR name IS NULL)", 3},
+	}
+	for _, tt := range tests {
+		q, keys := nextQuery(t, db, tt.order, tt.last)
+		if (tt.last.Name == nil) != q.cursor.Null {
+			t.Fatalf("%s cursor Null = %v for %+v", tt.order, q.cursor.Null, tt.last)
+		}
+		condition, args, err := keys.after(q)
+		if err != nil {
+			t.Fatalf("after() error = %v", err)
+		}
+		if condition != tt.want || len(args) != tt.args {
+			t.Errorf("%s after %+v = %q with %d args, want %q with %d args", tt.order, tt.last, condition, len(args), tt.want, tt.args)
+		}
+		if args[len(args)-1] != uint(7) {
+			t.Errorf("%s after %+v id arg = %v, want 7", tt.order, tt.last, args[len(args)-1])
+		}
+	}
+}
+
+func TestFindWithNullCursorBuildsKeysetQuery(t *testing.T) {
+	db := dryRunDB(t)
+	var statements []string
+	err := db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, _ := nextQuery(t, db, "desc", &testRow{ID: 7})
+
+	if _, err := Find[testRow](db.Model(&testRow{}), q); err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(statements) != 2 {
+		t.Fatalf("Find() ran %d queries, want count and page", len(statements))
+	}
+	page := statements[1]
+	for _, want := range []string{"(name IS NULL AND id < ?)", "ORDER BY name desc,id desc"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page query %q does not contain %q", page, want)
+		}
+	}
+}
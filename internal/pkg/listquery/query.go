@@ -0,0 +1,155 @@
+package listquery
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultPageSize 未指定时的每页条数
+	DefaultPageSize = 10
+	// MaxPageSize 未指定时每页条数的上限
+	MaxPageSize = 100
+)
+
+var (
+	// ErrInvalidSort 排序字段或排序方向无效
+	ErrInvalidSort = errors.New("无效的排序参数")
+	// ErrInvalidCursor 游标无效或与当前排序不一致
+	ErrInvalidCursor = errors.New("无效的游标")
+	// ErrCursorUnsupported 列表不支持游标分页
+	ErrCursorUnsupported = errors.New("该列表不支持游标分页")
+)
+
+// Options 列表的分页与排序约定
+type Options struct {
+	// DefaultPageSize 默认每页条数，为 0 时使用 DefaultPageSize
+	DefaultPageSize int
+	// MaxPageSize 每页条数上限，超出时按上限返回，为 0 时使用 MaxPageSize
+	MaxPageSize int
+	// SortFields 允许排序的字段及对应的列，不在其中的字段返回 ErrInvalidSort
+	SortFields map[string]string
+	// DefaultSort 未指定排序字段时使用的字段，为空时按主键排序，或由仓库使用自定义的默认排序
+	DefaultSort string
+	// DefaultOrder 默认排序方向，为空时为 asc
+	DefaultOrder string
+	// IDColumn 主键列，作为最后一个排序列保证顺序稳定，并用于游标定位，为空时为 id
+	IDColumn string
+	// Cursor 是否支持游标分页
+	Cursor bool
+}
+
+// Query 解析后的列表查询参数
+type Query struct {
+	Page      int
+	PageSize  int
+	SortBy    string // 排序字段，为空表示使用默认排序
+	SortOrder string // asc 或 desc
+
+	sortColumn string
+	idColumn   string
+	keyset     bool
+	cursor     *cursor
+}
+
+// New 返回第一页、默认排序的查询
+func New(opts Options) *Query {
+	q := &Query{
+		Page:      1,
+		PageSize:  opts.DefaultPageSize,
+		SortBy:    opts.DefaultSort,
+		SortOrder: "asc",
+		idColumn:  opts.IDColumn,
+		keyset:    opts.Cursor,
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = DefaultPageSize
+	}
+	if q.SortBy != "" {
+		q.sortColumn = opts.SortFields[q.SortBy]
+	}
+	if strings.EqualFold(opts.DefaultOrder, "desc") {
+		q.SortOrder = "desc"
+	}
+	if q.idColumn == "" {
+		q.idColumn = "id"
+	}
+	return q
+}
+
+// Parse 解析列表查询参数：
+//   - page、pageSize 为页码和每页条数，兼容旧的 size 参数
+//   - sortBy、sortOrder 为排序字段和方向，兼容 sort、order 参数，方向也接受 ascending、descending
+//   - cursor 为上一页返回的 nextCursor，指定后忽略 page，按排序列和主键定位下一页
+func Parse(c *gin.Context, opts Options) (*Query, error) {
+	maxPageSize := opts.MaxPageSize
+	if maxPageSize <= 0 {
+		maxPageSize = MaxPageSize
+	}
+
+	q := New(opts)
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		q.Page = page
+	}
+	if pageSize, err := strconv.Atoi(firstQuery(c, "pageSize", "size")); err == nil && pageSize > 0 {
+		q.PageSize = min(pageSize, maxPageSize)
+	}
+
+	if sortBy := firstQuery(c, "sortBy", "sort"); sortBy != "" {
+		column, ok := opts.SortFields[sortBy]
+		if !ok {
+			return nil, fmt.Errorf("%w: 不支持按 %s 排序", ErrInvalidSort, sortBy)
+		}
+		q.SortBy, q.sortColumn = sortBy, column
+	}
+
+	switch strings.ToLower(firstQuery(c, "sortOrder", "order")) {
+	case "":
+	case "asc", "ascending":
+		q.SortOrder = "asc"
+	case "desc", "descending":
+		q.SortOrder = "desc"
+	default:
+		return nil, fmt.Errorf("%w: 排序方向只能为 asc 或 desc", ErrInvalidSort)
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		if !opts.Cursor {
+			return nil, ErrCursorUnsupported
+		}
+		cur, err := decodeCursor(value)
+		if err != nil {
+			return nil, err
+		}
+		if cur.Sort != q.SortBy || cur.Order != q.SortOrder {
+			return nil, fmt.Errorf("%w: 游标与当前排序不一致", ErrInvalidCursor)
+		}
+		q.cursor = cur
+		q.Page = cur.Page + 1
+	}
+	return q, nil
+}
+
+// Offset 页码分页时跳过的条数
+func (q *Query) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// HasCursor 是否按游标定位
+func (q *Query) HasCursor() bool {
+	return q.cursor != nil
+}
+
+// firstQuery 返回第一个非空的查询参数
+func firstQuery(c *gin.Context, keys ...string) string {
+	for _, key := range keys {
+		if value := c.Query(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package listquery
+
+import (
+	"eden-ops/pkg/response"
+)
+
+// Result 一页查询结果
+type Result[T any] struct {
+	List       []T
+	Total      int64
+	Page       int
+	PageSize   int
+	NextCursor string // 下一页的游标，列表不支持游标分页或没有下一页时为空
+	HasMore    bool
+}
+
+// NewResult 包装已按页码分页查询的数据
+func NewResult[T any](list []T, total int64, q *Query) *Result[T] {
+	return &Result[T]{
+		List:     list,
+		Total:    total,
+		Page:     q.Page,
+		PageSize: q.PageSize,
+		HasMore:  int64(q.Offset()+len(list)) < total,
+	}
+}
+
+// Convert 转换结果中的每条记录，分页信息保持不变
+func Convert[T any, R any](r *Result[T], fn func(T) R) *Result[R] {
+	list := make([]R, len(r.List))
+	for i, item := range r.List {
+		list[i] = fn(item)
+	}
+	return &Result[R]{
+		List:       list,
+		Total:      r.Total,
+		Page:       r.Page,
+		PageSize:   r.PageSize,
+		NextCursor: r.NextCursor,
+		HasMore:    r.HasMore,
+	}
+}
+
+// PageData 转换为统一的分页响应数据，空列表输出为 [] 而不是 null
+func (r *Result[T]) PageData() response.PageData {
+	list := r.List
+	if list == nil {
+		list = []T{}
+	}
+	return response.PageData{
+		List:       list,
+		Total:      r.Total,
+		Page:       r.Page,
+		PageSize:   r.PageSize,
+		NextCursor: r.NextCursor,
+		HasMore:    r.HasMore,
+	}
+}
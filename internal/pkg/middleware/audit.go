@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"eden-ops/internal/model"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 审计捕获的请求和响应最大长度，超出部分不解析
+const (
+	auditMaxRequestBody  = 64 << 10
+	auditMaxResponseBody = 256 << 10
+)
+
+// AuditRecorder 操作审计记录器
+type AuditRecorder interface {
+	Target(method, route string) (*model.AuditTarget, bool)
+	Snapshot(resourceType, resourceID string) interface{}
+	Record(entry *model.AuditLog, request []byte, before interface{}, response json.RawMessage)
+}
+
+// auditResponseWriter 缓存JSON响应内容，用于判断操作结果
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture 只缓存JSON响应，文件下载等响应不缓存
+func (w *auditResponseWriter) capture(data []byte) {
+	if w.overflow || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		return
+	}
+	if w.body.Len()+len(data) > auditMaxResponseBody {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
+
+// Audit 操作审计中间件，需在 JWT 中间件之后、Permission 中间件之前使用，以便记录被拒绝的操作
+func Audit(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		target, ok := recorder.Target(c.Request.Method, c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		entry := &model.AuditLog{
+			CreatedAt:    time.Now(),
+			Username:     c.GetString(UsernameKey),
+			ClientIP:     c.ClientIP(),
+			UserAgent:    truncate(c.Request.UserAgent(), 255),
+			Method:       c.Request.Method,
+			Route:        c.FullPath(),
+			Path:         truncate(c.Request.URL.Path, 512),
+			Action:       target.Action,
+			ResourceType: target.ResourceType,
+		}
+		if userID, ok := c.Get(UserIDKey); ok {
+			entry.UserID, _ = userID.(uint)
+		}
+		if token, ok := GetAPIToken(c); ok {
+			entry.APITokenID = token.TokenID
+		}
+		if target.IDParam != "" {
+			entry.ResourceID = c.Param(target.IDParam)
+		}
+
+		var request []byte
+		if !target.SkipRequest {
+			request = auditRequest(c)
+		}
+
+		// WebSocket连接持续到会话结束，建立连接时即记录
+		if isWebSocketUpgrade(c) {
+			entry.Result = model.AuditResultSuccess
+			entry.Message = "建立连接"
+			recorder.Record(entry, request, nil, nil)
+			c.Next()
+			return
+		}
+
+		var before interface{}
+		if entry.ResourceID != "" {
+			before = recorder.Snapshot(entry.ResourceType, entry.ResourceID)
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		entry.Duration = time.Since(entry.CreatedAt).Milliseconds()
+		entry.StatusCode = writer.Status()
+		entry.Result = model.AuditResultSuccess
+
+		var resp struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		}
+		if writer.body.Len() > 0 && json.Unmarshal(writer.body.Bytes(), &resp) == nil {
+			entry.Code = resp.Code
+			if resp.Code != 0 {
+				entry.Result = model.AuditResultFailed
+				entry.Message = resp.Message
+			}
+		}
+		if entry.StatusCode >= http.StatusBadRequest {
+			entry.Result = model.AuditResultFailed
+		}
+		if entry.Message == "" && len(c.Errors) > 0 {
+			entry.Message = c.Errors.Last().Error()
+		}
+
+		recorder.Record(entry, request, before, resp.Data)
+	}
+}
+
+// auditRequest 读取请求参数并恢复请求体；只记录JSON请求体和查询参数，上传的文件内容不记录
+func auditRequest(c *gin.Context) []byte {
+	if strings.HasPrefix(c.ContentType(), "application/json") && c.Request.Body != nil && c.Request.ContentLength <= auditMaxRequestBody {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, auditMaxRequestBody+1))
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		if err == nil && len(body) > 0 && len(body) <= auditMaxRequestBody {
+			return body
+		}
+		return nil
+	}
+
+	query := c.Request.URL.Query()
+	if len(query) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(query))
+	for key := range query {
+		values[key] = query.Get(key)
+	}
+	data, _ := json.Marshal(values)
+	return data
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
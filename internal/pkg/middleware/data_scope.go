@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DataScopeKey 数据范围上下文键
+const DataScopeKey = "data_scope"
+
+// DataScopeResolver 用户数据范围解析器
+type DataScopeResolver interface {
+	GetDataScope(userID uint) (*model.DataScope, error)
+}
+
+// DataScope 数据范围中间件，将当前用户的数据范围写入上下文，需在 JWT 中间件之后使用
+func DataScope(resolver DataScopeResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get(UserIDKey)
+		id, valid := userID.(uint)
+		if !ok || !valid {
+			response.Unauthorized(c, "未登录或非法访问")
+			c.Abort()
+			return
+		}
+
+		scope, err := resolver.GetDataScope(id)
+		if err != nil {
+			logger.Error("获取用户数据范围失败: user=%d, %v", id, err)
+			response.Forbidden(c, "权限校验失败")
+			c.Abort()
+			return
+		}
+
+		c.Set(DataScopeKey, scope)
+		c.Next()
+	}
+}
+
+// GetDataScope 获取上下文中的数据范围，未设置时返回空数据范围
+func GetDataScope(c *gin.Context) *model.DataScope {
+	if value, ok := c.Get(DataScopeKey); ok {
+		if scope, ok := value.(*model.DataScope); ok {
+			return scope
+		}
+	}
+	return model.NewDataScope()
+}
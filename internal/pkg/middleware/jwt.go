@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"eden-ops/internal/model"
 	"eden-ops/pkg/auth"
+	"eden-ops/pkg/logger"
 	"eden-ops/pkg/response"
 	"strings"
 
@@ -13,12 +15,32 @@ const (
 	UserIDKey = "user_id"
 	// UsernameKey 用户名上下文键
 	UsernameKey = "username"
+	// ClaimsKey 令牌声明上下文键
+	ClaimsKey = "claims"
+	// APITokenKey API令牌身份上下文键，仅使用API令牌访问时设置
+	APITokenKey = "api_token"
 )
 
-// JWT 中间件
-func JWT(jwtAuth *auth.JWTAuth) gin.HandlerFunc {
+// TokenRevocationChecker 访问令牌吊销检查器
+type TokenRevocationChecker interface {
+	IsTokenRevoked(claims *auth.CustomClaims) (bool, error)
+}
+
+// APITokenAuthenticator API令牌认证器
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(token, clientIP string) (*auth.APITokenClaims, error)
+}
+
+// JWT 中间件，校验令牌签名、有效期及是否已被吊销；以 eden_ 开头的令牌按API令牌校验
+func JWT(jwtAuth *auth.JWTAuth, checker TokenRevocationChecker, apiTokens APITokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		// 浏览器无法为WebSocket设置请求头，允许通过token查询参数传递
+		if authHeader == "" && isWebSocketUpgrade(c) {
+			if token := c.Query("token"); token != "" {
+				authHeader = "Bearer " + token
+			}
+		}
 		if authHeader == "" {
 			response.Unauthorized(c, "未登录或非法访问")
 			c.Abort()
@@ -33,6 +55,20 @@ func JWT(jwtAuth *auth.JWTAuth) gin.HandlerFunc {
 			return
 		}
 
+		if strings.HasPrefix(parts[1], model.APITokenPrefix) {
+			tokenClaims, err := apiTokens.AuthenticateAPIToken(parts[1], c.ClientIP())
+			if err != nil {
+				response.Unauthorized(c, err.Error())
+				c.Abort()
+				return
+			}
+			c.Set(UserIDKey, tokenClaims.UserID)
+			c.Set(UsernameKey, tokenClaims.Username)
+			c.Set(APITokenKey, tokenClaims)
+			c.Next()
+			return
+		}
+
 		claims, err := jwtAuth.ParseToken(parts[1])
 		if err != nil {
 			response.Unauthorized(c, err.Error())
@@ -40,10 +76,61 @@ func JWT(jwtAuth *auth.JWTAuth) gin.HandlerFunc {
 			return
 		}
 
+		revoked, err := checker.IsTokenRevoked(claims)
+		if err != nil {
+			logger.Error("检查令牌状态失败: user=%d, %v", claims.UserID, err)
+			response.Unauthorized(c, auth.ErrTokenInvalid.Error())
+			c.Abort()
+			return
+		}
+		if revoked {
+			response.Unauthorized(c, auth.ErrTokenRevoked.Error())
+			c.Abort()
+			return
+		}
+
 		// 设置用户信息
 		c.Set(UserIDKey, claims.UserID)
 		c.Set(UsernameKey, claims.Username)
+		c.Set(ClaimsKey, claims)
 
 		c.Next()
 	}
 }
+
+// isWebSocketUpgrade 判断是否为WebSocket升级请求
+func isWebSocketUpgrade(c *gin.Context) bool {
+	return strings.EqualFold(c.GetHeader("Upgrade"), "websocket")
+}
+
+// GetClaims 获取上下文中的令牌声明
+func GetClaims(c *gin.Context) (*auth.CustomClaims, bool) {
+	value, ok := c.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*auth.CustomClaims)
+	return claims, ok
+}
+
+// GetAPIToken 获取上下文中的API令牌身份，非API令牌访问时返回 false
+func GetAPIToken(c *gin.Context) (*auth.APITokenClaims, bool) {
+	value, ok := c.Get(APITokenKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*auth.APITokenClaims)
+	return claims, ok
+}
+
+// InteractiveOnly 仅允许登录会话访问，拒绝API令牌，用于修改密码、两步验证、令牌管理等账号安全操作
+func InteractiveOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetAPIToken(c); ok {
+			response.Forbidden(c, "API令牌不能用于该操作")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -25,8 +25,13 @@ func Logger() gin.HandlerFunc {
 		// 请求方式
 		reqMethod := c.Request.Method
 
-		// 请求路由
+		// 请求路由，WebSocket请求会在查询参数中携带token，记录前脱敏
 		reqURI := c.Request.RequestURI
+		if c.Query("token") != "" {
+			query := c.Request.URL.Query()
+			query.Set("token", "***")
+			reqURI = c.Request.URL.Path + "?" + query.Encode()
+		}
 
 		// 状态码
 		statusCode := c.Writer.Status()
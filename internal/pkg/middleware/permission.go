@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"eden-ops/pkg/logger"
+	"eden-ops/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionChecker 接口权限校验器
+type PermissionChecker interface {
+	Authorize(userID uint, method, path string) (bool, error)
+	AuthorizeScopes(userID uint, scopes []string, method, path string) (bool, error)
+}
+
+// Permission 接口权限中间件，需在 JWT 中间件之后使用；API令牌同时受令牌权限限制
+func Permission(checker PermissionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get(UserIDKey)
+		id, valid := userID.(uint)
+		if !ok || !valid {
+			response.Unauthorized(c, "未登录或非法访问")
+			c.Abort()
+			return
+		}
+
+		var allowed bool
+		var err error
+		if token, ok := GetAPIToken(c); ok {
+			allowed, err = checker.AuthorizeScopes(id, token.Scopes, c.Request.Method, c.FullPath())
+		} else {
+			allowed, err = checker.Authorize(id, c.Request.Method, c.FullPath())
+		}
+		if err != nil {
+			logger.Error("校验接口权限失败: user=%d, %s %s, %v", id, c.Request.Method, c.FullPath(), err)
+			response.Forbidden(c, "权限校验失败")
+			c.Abort()
+			return
+		}
+		if !allowed {
+			response.Forbidden(c, "没有访问该接口的权限")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
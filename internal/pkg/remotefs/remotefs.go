@@ -0,0 +1,408 @@
+package remotefs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/terminal"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// 路径校验错误
+var (
+	ErrNoAllowedPaths = errors.New("服务器未配置SFTP允许访问的路径")
+	ErrPathNotAllowed = errors.New("路径不在允许访问的范围内")
+	ErrInvalidPath    = errors.New("无效的路径，必须为绝对路径")
+)
+
+// FileInfo 远程文件信息
+type FileInfo struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode"`
+	IsDir   bool      `json:"is_dir"`
+	IsLink  bool      `json:"is_link"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// newFileInfo 转换文件信息
+func newFileInfo(dir string, fi os.FileInfo) FileInfo {
+	return FileInfo{
+		Name:    fi.Name(),
+		Path:    path.Join(dir, fi.Name()),
+		Size:    fi.Size(),
+		Mode:    fi.Mode().String(),
+		IsDir:   fi.IsDir(),
+		IsLink:  fi.Mode()&os.ModeSymlink != 0,
+		ModTime: fi.ModTime(),
+	}
+}
+
+// Client 带路径白名单校验的SFTP客户端
+type Client struct {
+	sshClient    *ssh.Client
+	sftpClient   *sftp.Client
+	allowedPaths []string
+}
+
+// Open 连接服务器并创建SFTP客户端，主机公钥的校验与终端相同
+func Open(server *model.ServerConfig, timeout time.Duration, trust terminal.HostKeyTrustFunc) (*Client, error) {
+	allowed := server.AllowedPaths()
+	if len(allowed) == 0 {
+		return nil, ErrNoAllowedPaths
+	}
+
+	sshClient, err := terminal.Dial(server, timeout, trust)
+	if err != nil {
+		return nil, err
+	}
+	sftpClient, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, fmt.Errorf("创建SFTP会话失败: %v", err)
+	}
+
+	return &Client{
+		sshClient:    sshClient,
+		sftpClient:   sftpClient,
+		allowedPaths: allowed,
+	}, nil
+}
+
+// Close 关闭SFTP会话及SSH连接
+func (c *Client) Close() error {
+	c.sftpClient.Close()
+	return c.sshClient.Close()
+}
+
+// IsAllowed 判断规范化后的路径是否位于白名单内
+func IsAllowed(p string, allowedPaths []string) bool {
+	for _, allowed := range allowedPaths {
+		if allowed == "/" || p == allowed || strings.HasPrefix(p, allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanPath 规范化路径，拒绝相对路径
+func CleanPath(p string) (string, error) {
+	if p == "" || !strings.HasPrefix(p, "/") {
+		return "", ErrInvalidPath
+	}
+	return path.Clean(p), nil
+}
+
+// resolve 校验请求路径及其真实路径（解析符号链接后）均在白名单内
+func (c *Client) resolve(p string) (string, error) {
+	cleaned, err := CleanPath(p)
+	if err != nil {
+		return "", err
+	}
+	if !IsAllowed(cleaned, c.allowedPaths) {
+		return "", ErrPathNotAllowed
+	}
+	real, err := c.sftpClient.RealPath(cleaned)
+	if err != nil {
+		return "", err
+	}
+	real = path.Clean(real)
+	if !IsAllowed(real, c.allowedPaths) {
+		return "", ErrPathNotAllowed
+	}
+	return real, nil
+}
+
+// resolveNew 校验待创建路径：父目录需存在且在白名单内
+func (c *Client) resolveNew(p string) (string, error) {
+	cleaned, err := CleanPath(p)
+	if err != nil {
+		return "", err
+	}
+	if !IsAllowed(cleaned, c.allowedPaths) {
+		return "", ErrPathNotAllowed
+	}
+	parent, err := c.resolve(path.Dir(cleaned))
+	if err != nil {
+		return "", err
+	}
+	target := path.Join(parent, path.Base(cleaned))
+	if !IsAllowed(target, c.allowedPaths) {
+		return "", ErrPathNotAllowed
+	}
+	// 目标已存在且为符号链接时，禁止通过链接越界写入
+	if fi, err := c.sftpClient.Lstat(target); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		if _, err := c.resolve(target); err != nil {
+			return "", err
+		}
+	}
+	return target, nil
+}
+
+// List 列出目录内容，目录在前并按名称排序
+func (c *Client) List(p string) ([]FileInfo, error) {
+	dir, err := c.resolve(p)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := c.sftpClient.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		files = append(files, newFileInfo(dir, entry))
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].IsDir != files[j].IsDir {
+			return files[i].IsDir
+		}
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// Stat 获取文件信息
+func (c *Client) Stat(p string) (*FileInfo, error) {
+	real, err := c.resolve(p)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := c.sftpClient.Stat(real)
+	if err != nil {
+		return nil, err
+	}
+	info := newFileInfo(path.Dir(real), fi)
+	return &info, nil
+}
+
+// OpenFile 打开远程文件用于读取
+func (c *Client) OpenFile(p string) (*sftp.File, *FileInfo, error) {
+	real, err := c.resolve(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	fi, err := c.sftpClient.Stat(real)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fi.IsDir() {
+		return nil, nil, fmt.Errorf("%s 是目录，无法下载", real)
+	}
+	file, err := c.sftpClient.Open(real)
+	if err != nil {
+		return nil, nil, err
+	}
+	info := newFileInfo(path.Dir(real), fi)
+	return file, &info, nil
+}
+
+// Upload 上传中的文件。内容先写入目标目录下的临时文件，Commit 时再重命名为目标文件，
+// 上传失败时 Abort 只删除临时文件，覆盖上传中途失败不会损坏已有的文件
+type Upload struct {
+	*sftp.File
+	client    *Client
+	tempPath  string
+	target    string
+	overwrite bool
+}
+
+// CreateUpload 在目标文件所在目录创建临时文件用于写入，目标为符号链接时写入链接指向的文件
+func (c *Client) CreateUpload(p string, overwrite bool) (*Upload, error) {
+	target, err := c.resolveNew(p)
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := c.sftpClient.Lstat(target); err == nil {
+		if !overwrite {
+			return nil, fmt.Errorf("文件 %s 已存在", target)
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if target, err = c.resolve(target); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if fi, err := c.sftpClient.Stat(target); err == nil && fi.IsDir() {
+		return nil, fmt.Errorf("%s 是目录，无法覆盖", target)
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	tempPath := path.Join(path.Dir(target), "."+path.Base(target)+".upload-"+hex.EncodeToString(suffix))
+	file, err := c.sftpClient.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return nil, err
+	}
+	return &Upload{File: file, client: c, tempPath: tempPath, target: target, overwrite: overwrite}, nil
+}
+
+// Target 上传的目标文件路径
+func (u *Upload) Target() string {
+	return u.target
+}
+
+// posixRenameExtension 支持原子替换目标文件的 SFTP 扩展，OpenSSH 支持，部分其他实现不支持
+const posixRenameExtension = "posix-rename@openssh.com"
+
+// Commit 关闭临时文件并重命名为目标文件，失败时删除临时文件。覆盖时保留原文件的权限，
+// 服务器支持 posix-rename 扩展时原子替换，否则先将原文件改名备份，再重命名临时文件，失败时恢复原文件；
+// 不覆盖时使用普通重命名，目标在上传期间被创建时重命名失败
+func (u *Upload) Commit() error {
+	if err := u.File.Close(); err != nil {
+		u.removeTemp()
+		return err
+	}
+	sftpClient := u.client.sftpClient
+	if !u.overwrite {
+		if err := sftpClient.Rename(u.tempPath, u.target); err != nil {
+			u.removeTemp()
+			if _, statErr := sftpClient.Stat(u.target); statErr == nil {
+				return fmt.Errorf("文件 %s 已存在", u.target)
+			}
+			return err
+		}
+		return nil
+	}
+
+	fi, statErr := sftpClient.Stat(u.target)
+	if statErr == nil {
+		_ = sftpClient.Chmod(u.tempPath, fi.Mode().Perm())
+	}
+	if _, ok := sftpClient.HasExtension(posixRenameExtension); ok {
+		if err := sftpClient.PosixRename(u.tempPath, u.target); err != nil {
+			u.removeTemp()
+			return fmt.Errorf("替换文件 %s 失败: %v", u.target, err)
+		}
+		return nil
+	}
+	if statErr != nil {
+		// 目标在上传期间被删除，直接重命名
+		if err := sftpClient.Rename(u.tempPath, u.target); err != nil {
+			u.removeTemp()
+			return fmt.Errorf("替换文件 %s 失败: %v", u.target, err)
+		}
+		return nil
+	}
+
+	backupPath := u.tempPath + ".bak"
+	if err := sftpClient.Rename(u.target, backupPath); err != nil {
+		u.removeTemp()
+		return fmt.Errorf("替换文件 %s 失败: %v", u.target, err)
+	}
+	if err := sftpClient.Rename(u.tempPath, u.target); err != nil {
+		u.removeTemp()
+		if restoreErr := sftpClient.Rename(backupPath, u.target); restoreErr != nil {
+			return fmt.Errorf("替换文件 %s 失败: %v，原文件已保留为 %s", u.target, err, backupPath)
+		}
+		return fmt.Errorf("替换文件 %s 失败: %v", u.target, err)
+	}
+	_ = sftpClient.Remove(backupPath)
+	return nil
+}
+
+// Abort 关闭并删除临时文件，目标文件保持不变
+func (u *Upload) Abort() error {
+	_ = u.File.Close()
+	if err := u.client.sftpClient.Remove(u.tempPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// removeTemp 提交失败时删除临时文件
+func (u *Upload) removeTemp() {
+	_ = u.client.sftpClient.Remove(u.tempPath)
+}
+
+// Remove 删除远程文件或目录（recursive 为 true 时递归删除）
+func (c *Client) Remove(p string, recursive bool) (string, error) {
+	cleaned, err := CleanPath(p)
+	if err != nil {
+		return "", err
+	}
+	// 禁止删除白名单根目录本身
+	for _, allowed := range c.allowedPaths {
+		if cleaned == allowed {
+			return "", fmt.Errorf("不允许删除根目录 %s", allowed)
+		}
+	}
+
+	// 符号链接只删除链接本身，校验其所在目录即可
+	if fi, err := c.sftpClient.Lstat(cleaned); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		target, err := c.resolveNew(cleaned)
+		if err != nil {
+			return "", err
+		}
+		return target, c.sftpClient.Remove(target)
+	}
+
+	target, err := c.resolve(cleaned)
+	if err != nil {
+		return "", err
+	}
+	for _, allowed := range c.allowedPaths {
+		if target == allowed {
+			return "", fmt.Errorf("不允许删除根目录 %s", allowed)
+		}
+	}
+	fi, err := c.sftpClient.Stat(target)
+	if err != nil {
+		return "", err
+	}
+	if fi.IsDir() {
+		if recursive {
+			return target, c.sftpClient.RemoveAll(target)
+		}
+		return target, c.sftpClient.RemoveDirectory(target)
+	}
+	return target, c.sftpClient.Remove(target)
+}
+
+// Mkdir 创建目录（含父目录）
+func (c *Client) Mkdir(p string) (string, error) {
+	cleaned, err := CleanPath(p)
+	if err != nil {
+		return "", err
+	}
+	if !IsAllowed(cleaned, c.allowedPaths) {
+		return "", ErrPathNotAllowed
+	}
+
+	// 找到最近的已存在祖先目录并校验其真实路径
+	existing := cleaned
+	for {
+		if _, err := c.sftpClient.Lstat(existing); err == nil {
+			break
+		}
+		parent := path.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	realBase, err := c.resolve(existing)
+	if err != nil {
+		return "", err
+	}
+	rest := strings.TrimPrefix(cleaned, existing)
+	target := path.Join(realBase, rest)
+	if !IsAllowed(target, c.allowedPaths) {
+		return "", ErrPathNotAllowed
+	}
+	return target, c.sftpClient.MkdirAll(target)
+}
@@ -0,0 +1,179 @@
+package remotefs
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+)
+
+// newTestClient 连接内存中的SFTP服务器，extensions 为服务器声明支持的扩展
+func newTestClient(t *testing.T, extensions ...string) *Client {
+	t.Helper()
+	if err := sftp.SetSFTPExtensions(extensions...); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = sftp.SetSFTPExtensions("hardlink@openssh.com", "posix-rename@openssh.com", "statvfs@openssh.com")
+	})
+
+	clientRead, serverWrite := io.Pipe()
+	serverRead, clientWrite := io.Pipe()
+	server := sftp.NewRequestServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverRead, serverWrite}, sftp.InMemHandler())
+	go server.Serve()
+
+	sftpClient, err := sftp.NewClientPipe(clientRead, clientWrite)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	// 先关闭服务端，客户端的接收协程读到 EOF 后才能关闭客户端
+	t.Cleanup(func() {
+		server.Close()
+		sftpClient.Close()
+	})
+	if err := sftpClient.MkdirAll("/data"); err != nil {
+		t.Fatal(err)
+	}
+	return &Client{sftpClient: sftpClient, allowedPaths: []string{"/data"}}
+}
+
+func writeRemote(t *testing.T, c *Client, p, content string) {
+	t.Helper()
+	f, err := c.sftpClient.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+}
+
+func readRemote(t *testing.T, c *Client, p string) string {
+	t.Helper()
+	f, err := c.sftpClient.Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// assertNoLeftovers 上传结束后目录中不应留下临时文件或备份文件
+func assertNoLeftovers(t *testing.T, c *Client, dir string) {
+	t.Helper()
+	entries, err := c.sftpClient.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".upload-") {
+			t.Fatalf("leftover upload file %s", entry.Name())
+		}
+	}
+}
+
+func upload(t *testing.T, c *Client, p, content string, overwrite bool) error {
+	t.Helper()
+	u, err := c.CreateUpload(p, overwrite)
+	if err != nil {
+		return err
+	}
+	if _, err := u.Write([]byte(content)); err != nil {
+		u.Abort()
+		return err
+	}
+	if err := u.Commit(); err != nil {
+		u.Abort()
+		return err
+	}
+	return nil
+}
+
+func TestUploadOverwrite(t *testing.T) {
+	for name, extensions := range map[string][]string{
+		"posix-rename": {"posix-rename@openssh.com"},
+		"fallback":     {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, extensions...)
+			_, hasPosixRename := c.sftpClient.HasExtension(posixRenameExtension)
+			if hasPosixRename != (len(extensions) > 0) {
+				t.Fatalf("server posix-rename support = %v", hasPosixRename)
+			}
+
+			writeRemote(t, c, "/data/app.conf", "old")
+			if err := upload(t, c, "/data/app.conf", "new", true); err != nil {
+				t.Fatalf("overwrite upload error = %v", err)
+			}
+			if got := readRemote(t, c, "/data/app.conf"); got != "new" {
+				t.Fatalf("content after overwrite = %q, want %q", got, "new")
+			}
+			assertNoLeftovers(t, c, "/data")
+		})
+	}
+}
+
+func TestUploadWithoutOverwriteKeepsExistingFile(t *testing.T) {
+	c := newTestClient(t)
+	writeRemote(t, c, "/data/app.conf", "old")
+
+	if err := upload(t, c, "/data/app.conf", "new", false); err == nil {
+		t.Fatal("upload without overwrite onto an existing file succeeded")
+	}
+	if got := readRemote(t, c, "/data/app.conf"); got != "old" {
+		t.Fatalf("content = %q, want the original", got)
+	}
+
+	if err := upload(t, c, "/data/new.conf", "created", false); err != nil {
+		t.Fatalf("upload of a new file error = %v", err)
+	}
+	if got := readRemote(t, c, "/data/new.conf"); got != "created" {
+		t.Fatalf("content = %q, want %q", got, "created")
+	}
+	assertNoLeftovers(t, c, "/data")
+}
+
+func TestUploadAbortKeepsOriginal(t *testing.T) {
+	c := newTestClient(t)
+	writeRemote(t, c, "/data/app.conf", "old")
+
+	u, err := c.CreateUpload("/data/app.conf", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Write([]byte("partial")); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Abort(); err != nil {
+		t.Fatalf("Abort() error = %v", err)
+	}
+	// 提交失败后调用方仍会 Abort，重复清理不应报错
+	if err := u.Abort(); err != nil {
+		t.Fatalf("second Abort() error = %v", err)
+	}
+	if got := readRemote(t, c, "/data/app.conf"); got != "old" {
+		t.Fatalf("content after abort = %q, want the original", got)
+	}
+	assertNoLeftovers(t, c, "/data")
+}
+
+func TestCreateUploadRejectsPathOutsideAllowed(t *testing.T) {
+	c := newTestClient(t)
+	if _, err := c.CreateUpload("/etc/passwd", true); err == nil {
+		t.Fatal("upload outside the allowed paths succeeded")
+	}
+	if _, err := c.sftpClient.Stat("/etc/passwd"); !os.IsNotExist(err) {
+		t.Fatalf("stat outside allowed path error = %v, want not exist", err)
+	}
+}
@@ -3,6 +3,8 @@ package response
 import (
 	"net/http"
 
+	"eden-ops/pkg/response"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,12 +15,6 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// PageData 分页数据结构
-type PageData struct {
-	List  interface{} `json:"list"`
-	Total int64       `json:"total"`
-}
-
 // Error 自定义错误
 type Error struct {
 	Message string `json:"message"`
@@ -59,16 +55,9 @@ func FailedWithCode(c *gin.Context, code int, err error) {
 	})
 }
 
-// PageSuccess 分页成功响应
-func PageSuccess(c *gin.Context, list interface{}, total int64) {
-	c.JSON(http.StatusOK, Response{
-		Code:    200,
-		Message: "success",
-		Data: PageData{
-			List:  list,
-			Total: total,
-		},
-	})
+// ListSuccess 列表成功响应，所有列表接口统一使用 eden-ops/pkg/response 的响应结构和分页数据
+func ListSuccess(c *gin.Context, data response.PageData) {
+	response.ListSuccess(c, data)
 }
 
 // BadRequest 请求参数错误响应
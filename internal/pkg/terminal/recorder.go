@@ -0,0 +1,119 @@
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// asciinema v2 事件类型
+const (
+	EventOutput = "o"
+	EventResize = "r"
+)
+
+// castHeader asciinema v2 文件头
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder asciinema v2 格式的会话录像器，只记录输出，避免录下用户键入的口令
+type Recorder struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	start  time.Time
+	closed bool
+}
+
+// NewRecorder 创建录像文件并写入文件头
+func NewRecorder(path string, cols, rows int, title string) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("创建录像目录失败: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("创建录像文件失败: %v", err)
+	}
+
+	r := &Recorder{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		start:  time.Now(),
+	}
+
+	header := castHeader{
+		Version:   2,
+		Width:     cols,
+		Height:    rows,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": DefaultTerm},
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := r.writer.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("写入录像文件头失败: %v", err)
+	}
+
+	return r, nil
+}
+
+// WriteOutput 记录终端输出
+func (r *Recorder) WriteOutput(data []byte) error {
+	return r.writeEvent(EventOutput, string(data))
+}
+
+// WriteResize 记录终端窗口大小变化
+func (r *Recorder) WriteResize(cols, rows int) error {
+	return r.writeEvent(EventResize, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// writeEvent 写入一条 [time, type, data] 事件
+func (r *Recorder) writeEvent(eventType, data string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+
+	elapsed := time.Since(r.start).Seconds()
+	line, err := json.Marshal([]interface{}{elapsed, eventType, data})
+	if err != nil {
+		return err
+	}
+	_, err = r.writer.Write(append(line, '\n'))
+	return err
+}
+
+// Close 刷新缓冲并关闭录像文件
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	if err := r.writer.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
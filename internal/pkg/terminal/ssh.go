@@ -0,0 +1,224 @@
+package terminal
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"eden-ops/internal/model"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultTerm 默认终端类型
+const DefaultTerm = "xterm-256color"
+
+// hostKeyFingerprintPrefix SHA256 主机公钥指纹的前缀，与 ssh-keygen -lf 的输出一致
+const hostKeyFingerprintPrefix = "SHA256:"
+
+// ErrHostKeyMismatch 服务器的主机公钥与登记的指纹不一致，可能存在中间人攻击
+var ErrHostKeyMismatch = errors.New("主机公钥指纹不一致")
+
+// ErrInvalidHostKeyFingerprint 主机公钥指纹格式无效
+var ErrInvalidHostKeyFingerprint = errors.New("无效的主机公钥指纹，应为 SHA256:<base64>")
+
+// HostKeyTrustFunc 服务器未登记主机公钥指纹时调用，用于首次连接时登记指纹，返回错误则拒绝连接
+type HostKeyTrustFunc func(fingerprint string) error
+
+// NormalizeHostKeyFingerprint 规范化手工填写的 SHA256 主机公钥指纹，允许省略前缀和 base64 填充
+func NormalizeHostKeyFingerprint(fingerprint string) (string, error) {
+	encoded := strings.TrimRight(strings.TrimPrefix(strings.TrimSpace(fingerprint), hostKeyFingerprintPrefix), "=")
+	sum, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sum) != 32 {
+		return "", ErrInvalidHostKeyFingerprint
+	}
+	return hostKeyFingerprintPrefix + encoded, nil
+}
+
+// hostKeyCallback 按登记的指纹校验主机公钥；未登记时交由 trust 决定是否信任并登记，trust 为空则拒绝连接
+func hostKeyCallback(server *model.ServerConfig, trust HostKeyTrustFunc) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fingerprint := ssh.FingerprintSHA256(key)
+		if server.HostKeyFingerprint == "" {
+			if trust == nil {
+				return fmt.Errorf("服务器 %s 未登记主机公钥指纹", server.Name)
+			}
+			return trust(fingerprint)
+		}
+		if fingerprint != server.HostKeyFingerprint {
+			return fmt.Errorf("%w: 服务器 %s 登记的指纹为 %s，实际为 %s", ErrHostKeyMismatch, server.Name, server.HostKeyFingerprint, fingerprint)
+		}
+		return nil
+	}
+}
+
+// Dial 使用服务器配置中保存的凭据建立SSH连接，并按登记的指纹校验主机公钥
+func Dial(server *model.ServerConfig, timeout time.Duration, trust HostKeyTrustFunc) (*ssh.Client, error) {
+	if server == nil {
+		return nil, fmt.Errorf("服务器配置为空")
+	}
+
+	var authMethods []ssh.AuthMethod
+	if server.PrivateKey != "" {
+		var signer ssh.Signer
+		var err error
+		if server.Password != "" {
+			// 私钥带口令时使用密码作为口令解析，失败则按无口令私钥处理
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(server.PrivateKey), []byte(server.Password))
+			if err != nil {
+				signer, err = ssh.ParsePrivateKey([]byte(server.PrivateKey))
+			}
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(server.PrivateKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析私钥失败: %v", err)
+		}
+		authMethods = append(authMethods, ssh.PublicKeys(signer))
+	}
+	if server.Password != "" {
+		authMethods = append(authMethods, ssh.Password(server.Password))
+	}
+	if len(authMethods) == 0 {
+		return nil, fmt.Errorf("服务器 %s 未配置密码或私钥", server.Name)
+	}
+
+	port := server.Port
+	if port == 0 {
+		port = 22
+	}
+
+	clientConfig := &ssh.ClientConfig{
+		User:            server.Username,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback(server, trust),
+		Timeout:         timeout,
+	}
+
+	addr := net.JoinHostPort(server.Host, strconv.Itoa(port))
+	client, err := ssh.Dial("tcp", addr, clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("连接服务器 %s 失败: %w", addr, err)
+	}
+	return client, nil
+}
+
+// Shell 交互式终端会话
+type Shell struct {
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	stdout  io.Reader
+
+	closeOnce sync.Once
+}
+
+// NewShell 在SSH连接上申请PTY并启动登录Shell
+func NewShell(client *ssh.Client, cols, rows int) (*Shell, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("创建SSH会话失败: %v", err)
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err := session.RequestPty(DefaultTerm, rows, cols, modes); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("申请PTY失败: %v", err)
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, fmt.Errorf("获取标准输入失败: %v", err)
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, fmt.Errorf("获取标准输出失败: %v", err)
+	}
+	// PTY模式下标准错误会合并到标准输出
+	session.Stderr = session.Stdout
+
+	if err := session.Shell(); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("启动Shell失败: %v", err)
+	}
+
+	return &Shell{
+		client:  client,
+		session: session,
+		stdin:   stdin,
+		stdout:  stdout,
+	}, nil
+}
+
+// Read 读取终端输出
+func (s *Shell) Read(p []byte) (int, error) {
+	return s.stdout.Read(p)
+}
+
+// Write 写入终端输入
+func (s *Shell) Write(p []byte) (int, error) {
+	return s.stdin.Write(p)
+}
+
+// Resize 调整终端窗口大小
+func (s *Shell) Resize(cols, rows int) error {
+	return s.session.WindowChange(rows, cols)
+}
+
+// Wait 等待Shell退出
+func (s *Shell) Wait() error {
+	return s.session.Wait()
+}
+
+// Close 关闭会话及底层连接
+func (s *Shell) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.session.Close()
+		err = s.client.Close()
+	})
+	return err
+}
+
+// SplitUTF8 拆分出完整的UTF-8前缀，末尾被截断的多字节字符留给下一次读取
+func SplitUTF8(data []byte) ([]byte, []byte) {
+	n := len(data)
+	// UTF-8字符最长4字节，只需检查末尾3个字节
+	for i := n - 1; i >= 0 && i >= n-3; i-- {
+		b := data[i]
+		if b&0xC0 == 0x80 {
+			continue // 续字节
+		}
+		if b&0x80 == 0 {
+			return data, nil // ASCII
+		}
+		var size int
+		switch {
+		case b&0xE0 == 0xC0:
+			size = 2
+		case b&0xF0 == 0xE0:
+			size = 3
+		case b&0xF8 == 0xF0:
+			size = 4
+		default:
+			return data, nil
+		}
+		if n-i < size {
+			return data[:i], data[i:]
+		}
+		return data, nil
+	}
+	return data, nil
+}
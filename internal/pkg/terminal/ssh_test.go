@@ -0,0 +1,140 @@
+package terminal
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"eden-ops/internal/model"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startTestSSHServer 启动只接受密码认证的SSH服务器，返回监听地址和主机公钥
+func startTestSSHServer(t *testing.T) (host string, port int, hostKey ssh.PublicKey) {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "ops" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("denied")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					conn.Close()
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					ch.Reject(ssh.Prohibited, "no channels")
+				}
+			}()
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, signer.PublicKey()
+}
+
+func testServer(host string, port int) *model.ServerConfig {
+	return &model.ServerConfig{Name: "test", Host: host, Port: port, Username: "ops", Password: "secret"}
+}
+
+func TestDialTrustsHostKeyOnFirstUse(t *testing.T) {
+	host, port, hostKey := startTestSSHServer(t)
+	server := testServer(host, port)
+
+	var trusted string
+	client, err := Dial(server, 5*time.Second, func(fingerprint string) error {
+		trusted = fingerprint
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	client.Close()
+	if want := ssh.FingerprintSHA256(hostKey); trusted != want {
+		t.Fatalf("trusted fingerprint = %q, want %q", trusted, want)
+	}
+}
+
+func TestDialRejectsUnknownHostKeyWithoutTrust(t *testing.T) {
+	host, port, _ := startTestSSHServer(t)
+	if _, err := Dial(testServer(host, port), 5*time.Second, nil); err == nil {
+		t.Fatal("Dial() without a registered fingerprint or trust func succeeded")
+	}
+}
+
+func TestDialVerifiesRegisteredFingerprint(t *testing.T) {
+	host, port, hostKey := startTestSSHServer(t)
+
+	server := testServer(host, port)
+	server.HostKeyFingerprint = ssh.FingerprintSHA256(hostKey)
+	client, err := Dial(server, 5*time.Second, func(string) error {
+		t.Fatal("trust func called for a registered fingerprint")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Dial() with the matching fingerprint error = %v", err)
+	}
+	client.Close()
+
+	server.HostKeyFingerprint = "SHA256:" + strings.Repeat("A", 43)
+	_, err = Dial(server, 5*time.Second, nil)
+	if !errors.Is(err, ErrHostKeyMismatch) {
+		t.Fatalf("Dial() with a different fingerprint error = %v, want ErrHostKeyMismatch", err)
+	}
+}
+
+func TestNormalizeHostKeyFingerprint(t *testing.T) {
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := ssh.FingerprintSHA256(hostKey)
+	bare := strings.TrimPrefix(fingerprint, "SHA256:")
+
+	for _, input := range []string{fingerprint, bare, " " + bare + "= "} {
+		got, err := NormalizeHostKeyFingerprint(input)
+		if err != nil || got != fingerprint {
+			t.Fatalf("NormalizeHostKeyFingerprint(%q) = %q, %v; want %q", input, got, err, fingerprint)
+		}
+	}
+	for _, input := range []string{"", "SHA256:abc", "MD5:" + bare, "not base64!"} {
+		if _, err := NormalizeHostKeyFingerprint(input); !errors.Is(err, ErrInvalidHostKeyFingerprint) {
+			t.Fatalf("NormalizeHostKeyFingerprint(%q) error = %v, want ErrInvalidHostKeyFingerprint", input, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APITokenRepository API令牌仓储接口
+type APITokenRepository interface {
+	Create(token *model.APIToken) error
+	Get(id uint) (*model.APIToken, error)
+	GetByHash(hash string) (*model.APIToken, error)
+	ListByUser(userID uint) ([]*model.APIToken, error)
+	CountActiveByUser(userID uint) (int64, error)
+	Revoke(id uint) error
+	RevokeByUser(userID uint) (int64, error)
+	TouchLastUsed(id uint, ip string, at time.Time) error
+}
+
+// APITokenRepositoryImpl API令牌仓储实现
+type APITokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository 创建API令牌仓储实例
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &APITokenRepositoryImpl{db: db}
+}
+
+// Create 创建令牌
+func (r *APITokenRepositoryImpl) Create(token *model.APIToken) error {
+	return r.db.Create(token).Error
+}
+
+// Get 获取令牌
+func (r *APITokenRepositoryImpl) Get(id uint) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := r.db.First(&token, id).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetByHash 根据令牌摘要获取令牌
+func (r *APITokenRepositoryImpl) GetByHash(hash string) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListByUser 获取用户的全部令牌，按创建时间倒序
+func (r *APITokenRepositoryImpl) ListByUser(userID uint) ([]*model.APIToken, error) {
+	var tokens []*model.APIToken
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// CountActiveByUser 统计用户未吊销且未过期的令牌数量
+func (r *APITokenRepositoryImpl) CountActiveByUser(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Count(&count).Error
+	return count, err
+}
+
+// Revoke 吊销令牌
+func (r *APITokenRepositoryImpl) Revoke(id uint) error {
+	return r.db.Model(&model.APIToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeByUser 吊销用户的全部令牌
+func (r *APITokenRepositoryImpl) RevokeByUser(userID uint) (int64, error) {
+	result := r.db.Model(&model.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// TouchLastUsed 记录令牌最近使用时间和来源IP
+func (r *APITokenRepositoryImpl) TouchLastUsed(id uint, ip string, at time.Time) error {
+	return r.db.Model(&model.APIToken{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"last_used_at": at,
+			"last_used_ip": ip,
+		}).Error
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计日志查询条件
+type AuditLogFilter struct {
+	UserID       uint
+	Username     string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Result       string
+	ClientIP     string
+	Keyword      string // 匹配资源名称或请求路径
+	StartTime    *time.Time
+	EndTime      *time.Time
+}
+
+// AuditLogRepository 审计日志仓储接口，审计日志只允许追加
+type AuditLogRepository interface {
+	Create(log *model.AuditLog) error
+	Get(id uint) (*model.AuditLog, error)
+	List(q *listquery.Query, filter *AuditLogFilter) (*listquery.Result[*model.AuditLog], error)
+	Each(filter *AuditLogFilter, limit, batchSize int, fn func([]*model.AuditLog) error) error
+}
+
+// AuditLogRepositoryImpl 审计日志仓储实现
+type AuditLogRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建审计日志仓储实例
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &AuditLogRepositoryImpl{db: db}
+}
+
+// Create 写入审计日志
+func (r *AuditLogRepositoryImpl) Create(log *model.AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+// Get 获取审计日志
+func (r *AuditLogRepositoryImpl) Get(id uint) (*model.AuditLog, error) {
+	var log model.AuditLog
+	if err := r.db.First(&log, id).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// List 分页查询审计日志，列表不返回变更明细和请求参数
+func (r *AuditLogRepositoryImpl) List(q *listquery.Query, filter *AuditLogFilter) (*listquery.Result[*model.AuditLog], error) {
+	query := applyAuditLogFilter(r.db.Model(&model.AuditLog{}), filter)
+	return listquery.Find[*model.AuditLog](query.Omit("changes", "request"), q)
+}
+
+// Each 按ID倒序分批遍历符合条件的审计日志，最多遍历 limit 条，用于导出
+func (r *AuditLogRepositoryImpl) Each(filter *AuditLogFilter, limit, batchSize int, fn func([]*model.AuditLog) error) error {
+	var lastID uint
+	for read := 0; read < limit; {
+		size := batchSize
+		if limit-read < size {
+			size = limit - read
+		}
+
+		query := applyAuditLogFilter(r.db.Model(&model.AuditLog{}), filter)
+		if lastID > 0 {
+			query = query.Where("id < ?", lastID)
+		}
+		var logs []*model.AuditLog
+		if err := query.Order("id DESC").Limit(size).Find(&logs).Error; err != nil {
+			return err
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		if err := fn(logs); err != nil {
+			return err
+		}
+		read += len(logs)
+		lastID = logs[len(logs)-1].ID
+		if len(logs) < size {
+			return nil
+		}
+	}
+	return nil
+}
+
+// applyAuditLogFilter 应用审计日志查询条件
+func applyAuditLogFilter(query *gorm.DB, filter *AuditLogFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.Result != "" {
+		query = query.Where("result = ?", filter.Result)
+	}
+	if filter.ClientIP != "" {
+		query = query.Where("client_ip = ?", filter.ClientIP)
+	}
+	if filter.Keyword != "" {
+		like := "%" + filter.Keyword + "%"
+		query = query.Where("resource_name LIKE ? OR path LIKE ?", like, like)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("created_at < ?", *filter.EndTime)
+	}
+	return query
+}
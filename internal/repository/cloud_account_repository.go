@@ -41,7 +41,7 @@ func (r *CloudAccountRepository) List(page, pageSize int, name string) (int64, [
 }
 
 // ListWithFilter 获取云账号列表（支持过滤）
-func (r *CloudAccountRepository) ListWithFilter(page, pageSize int, name string, providerID *int64, status *int) (int64, []model.CloudAccount, error) {
+func (r *CloudAccountRepository) ListWithFilter(page, pageSize int, name string, providerID *int64, status *int, scope *model.DataScope) (int64, []model.CloudAccount, error) {
 	var total int64
 	var accounts []model.CloudAccount
 
@@ -56,6 +56,7 @@ func (r *CloudAccountRepository) ListWithFilter(page, pageSize int, name string,
 	if status != nil {
 		query = query.Where("infra_cloud_account.status = ?", *status)
 	}
+	query = applyIDScope(query, scope, "infra_cloud_account.id", scope.CloudAccountIDs())
 
 	// 计算总数
 	err := query.Count(&total).Error
@@ -88,6 +89,23 @@ func (r *CloudAccountRepository) ListWithFilter(page, pageSize int, name string,
 	return total, accounts, nil
 }
 
+// ListByStatus 获取指定状态的全部云账号
+func (r *CloudAccountRepository) ListByStatus(status int) ([]model.CloudAccount, error) {
+	var accounts []model.CloudAccount
+	err := r.db.Where("status = ?", status).Order("id").Find(&accounts).Error
+	return accounts, err
+}
+
+// ExistingIDs 返回 ids 中仍然存在的云账号ID
+func (r *CloudAccountRepository) ExistingIDs(ids []int64) ([]int64, error) {
+	existing := make([]int64, 0, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	err := r.db.Model(&model.CloudAccount{}).Where("id IN ?", ids).Pluck("id", &existing).Error
+	return existing, err
+}
+
 // Create 创建云账号
 func (r *CloudAccountRepository) Create(account *model.CloudAccount) error {
 	return r.db.Create(account).Error
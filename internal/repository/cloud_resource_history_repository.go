@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CloudResourceHistoryRepository 云资源历史数据仓库接口
+type CloudResourceHistoryRepository interface {
+	ArchiveNotInList(accountID int64, region, resourceType string, instanceIDs []string, reason string) error
+	ArchiveByAccountID(accountID int64, reason string) error
+	GetHistory(q *listquery.Query, filter *CloudResourceFilter, startTime, endTime *time.Time) (*listquery.Result[model.CloudResourceHistory], error)
+	CleanupHistory(beforeDate time.Time) error
+}
+
+// cloudResourceHistoryRepository 云资源历史数据仓库实现
+type cloudResourceHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCloudResourceHistoryRepository 创建云资源历史数据仓库
+func NewCloudResourceHistoryRepository(db *gorm.DB) CloudResourceHistoryRepository {
+	return &cloudResourceHistoryRepository{db: db}
+}
+
+// cloudResourceArchiveSQL 归档语句，WHERE 条件由调用方追加
+const cloudResourceArchiveSQL = `INSERT INTO infra_cloud_resource_history
+		(original_id, account_id, provider, region, zone, resource_type, instance_id, name, status,
+		 private_ips, public_ips, tags, spec, create_time, synced_at, created_at, updated_at, archive_reason)
+		SELECT id, account_id, provider, region, zone, resource_type, instance_id, name, status,
+			   private_ips, public_ips, tags, spec, create_time, synced_at, created_at, updated_at, ?
+		FROM infra_cloud_resource `
+
+// ArchiveNotInList 归档指定账号、地域、类型下不在当前列表中的资源
+func (r *cloudResourceHistoryRepository) ArchiveNotInList(accountID int64, region, resourceType string, instanceIDs []string, reason string) error {
+	if len(instanceIDs) == 0 {
+		return r.db.Exec(cloudResourceArchiveSQL+"WHERE account_id = ? AND region = ? AND resource_type = ?",
+			reason, accountID, region, resourceType).Error
+	}
+	return r.db.Exec(cloudResourceArchiveSQL+"WHERE account_id = ? AND region = ? AND resource_type = ? AND instance_id NOT IN ?",
+		reason, accountID, region, resourceType, instanceIDs).Error
+}
+
+// ArchiveByAccountID 归档云账号下的所有资源
+func (r *cloudResourceHistoryRepository) ArchiveByAccountID(accountID int64, reason string) error {
+	return r.db.Exec(cloudResourceArchiveSQL+"WHERE account_id = ?", reason, accountID).Error
+}
+
+// GetHistory 获取云资源历史记录
+func (r *cloudResourceHistoryRepository) GetHistory(q *listquery.Query, filter *CloudResourceFilter, startTime, endTime *time.Time) (*listquery.Result[model.CloudResourceHistory], error) {
+	query := applyCloudResourceFilter(r.db.Model(&model.CloudResourceHistory{}), filter)
+
+	if startTime != nil {
+		query = query.Where("archived_at >= ?", *startTime)
+	}
+	if endTime != nil {
+		query = query.Where("archived_at <= ?", *endTime)
+	}
+
+	return listquery.Find[model.CloudResourceHistory](query, q)
+}
+
+// CleanupHistory 清理云资源历史记录
+func (r *cloudResourceHistoryRepository) CleanupHistory(beforeDate time.Time) error {
+	return r.db.Where("archived_at < ?", beforeDate).Delete(&model.CloudResourceHistory{}).Error
+}
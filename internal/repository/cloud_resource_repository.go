@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CloudResourceFilter 云资源查询条件
+type CloudResourceFilter struct {
+	AccountID    int64
+	Provider     string
+	Region       string
+	ResourceType string
+	Status       string
+	Keyword      string // 匹配实例ID或名称
+	IP           string // 精确匹配内网或公网IP
+	Tag          string // 标签，格式 key 或 key=value
+	Scope        *model.DataScope
+}
+
+// CloudResourceRepository 云资源仓库接口
+type CloudResourceRepository interface {
+	List(page, pageSize int, filter *CloudResourceFilter) (int64, []model.CloudResource, error)
+	Get(id int64) (*model.CloudResource, error)
+	FindByIP(ip string) ([]model.CloudResource, error)
+	BatchCreateOrUpdate(resources []model.CloudResource) error
+	DeleteNotInList(accountID int64, region, resourceType string, instanceIDs []string) error
+	DeleteByAccountID(accountID int64) error
+	ListAccountIDs() ([]int64, error)
+}
+
+// cloudResourceRepository 云资源仓库实现
+type cloudResourceRepository struct {
+	db *gorm.DB
+}
+
+// NewCloudResourceRepository 创建云资源仓库
+func NewCloudResourceRepository(db *gorm.DB) CloudResourceRepository {
+	return &cloudResourceRepository{db: db}
+}
+
+// applyCloudResourceFilter 应用云资源查询条件
+func applyCloudResourceFilter(query *gorm.DB, filter *CloudResourceFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if filter.AccountID > 0 {
+		query = query.Where("account_id = ?", filter.AccountID)
+	}
+	query = applyIDScope(query, filter.Scope, "account_id", filter.Scope.CloudAccountIDs())
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	if filter.Region != "" {
+		query = query.Where("region = ?", filter.Region)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Keyword != "" {
+		like := "%" + filter.Keyword + "%"
+		query = query.Where("instance_id LIKE ? OR name LIKE ?", like, like)
+	}
+	if filter.IP != "" {
+		query = query.Where("FIND_IN_SET(?, private_ips) > 0 OR FIND_IN_SET(?, public_ips) > 0", filter.IP, filter.IP)
+	}
+	if filter.Tag != "" {
+		// 标签以JSON存储，按 "key":"value" 片段匹配
+		key, value, hasValue := strings.Cut(filter.Tag, "=")
+		if hasValue {
+			query = query.Where("tags LIKE ?", fmt.Sprintf("%%%q:%q%%", key, value))
+		} else {
+			query = query.Where("tags LIKE ?", fmt.Sprintf("%%%q:%%", key))
+		}
+	}
+	return query
+}
+
+// List 分页查询云资源
+func (r *cloudResourceRepository) List(page, pageSize int, filter *CloudResourceFilter) (int64, []model.CloudResource, error) {
+	var resources []model.CloudResource
+	var total int64
+
+	query := applyCloudResourceFilter(r.db.Model(&model.CloudResource{}), filter)
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("account_id, region, resource_type, instance_id").Find(&resources).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return total, resources, nil
+}
+
+// Get 获取云资源详情
+func (r *cloudResourceRepository) Get(id int64) (*model.CloudResource, error) {
+	var resource model.CloudResource
+	if err := r.db.First(&resource, id).Error; err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// FindByIP 根据IP查找云资源
+func (r *cloudResourceRepository) FindByIP(ip string) ([]model.CloudResource, error) {
+	var resources []model.CloudResource
+	err := r.db.Where("FIND_IN_SET(?, private_ips) > 0 OR FIND_IN_SET(?, public_ips) > 0", ip, ip).
+		Order("synced_at DESC").Find(&resources).Error
+	return resources, err
+}
+
+// BatchCreateOrUpdate 批量创建或更新云资源
+func (r *cloudResourceRepository) BatchCreateOrUpdate(resources []model.CloudResource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	// 分批处理，减少锁持有时间
+	batchSize := 50
+	for i := 0; i < len(resources); i += batchSize {
+		end := i + batchSize
+		if end > len(resources) {
+			end = len(resources)
+		}
+
+		batch := resources[i:end]
+
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			for _, resource := range batch {
+				var existing model.CloudResource
+				err := tx.Where("account_id = ? AND region = ? AND resource_type = ? AND instance_id = ?",
+					resource.AccountID, resource.Region, resource.ResourceType, resource.InstanceID).First(&existing).Error
+
+				if err == gorm.ErrRecordNotFound {
+					if err := tx.Create(&resource).Error; err != nil {
+						return err
+					}
+				} else if err == nil {
+					resource.ID = existing.ID
+					resource.CreatedAt = existing.CreatedAt
+					if err := tx.Save(&resource).Error; err != nil {
+						return err
+					}
+				} else {
+					return err
+				}
+			}
+			return nil
+		})
+
+		if err != nil {
+			return fmt.Errorf("batch %d-%d failed: %v", i, end-1, err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteNotInList 删除指定账号、地域、类型下不在当前列表中的资源
+func (r *cloudResourceRepository) DeleteNotInList(accountID int64, region, resourceType string, instanceIDs []string) error {
+	query := r.db.Where("account_id = ? AND region = ? AND resource_type = ?", accountID, region, resourceType)
+	if len(instanceIDs) > 0 {
+		query = query.Where("instance_id NOT IN ?", instanceIDs)
+	}
+	return query.Delete(&model.CloudResource{}).Error
+}
+
+// DeleteByAccountID 删除云账号下的所有资源
+func (r *cloudResourceRepository) DeleteByAccountID(accountID int64) error {
+	return r.db.Where("account_id = ?", accountID).Delete(&model.CloudResource{}).Error
+}
+
+// ListAccountIDs 获取存在资源的云账号ID
+func (r *cloudResourceRepository) ListAccountIDs() ([]int64, error) {
+	var accountIDs []int64
+	err := r.db.Model(&model.CloudResource{}).Distinct().Pluck("account_id", &accountIDs).Error
+	return accountIDs, err
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// applyClusterScope 按数据范围限定K8s集群及命名空间；namespaceColumn 为空时仅按集群限定
+func applyClusterScope(query *gorm.DB, scope *model.DataScope, configColumn, namespaceColumn string) *gorm.DB {
+	if scope.Unrestricted() {
+		return query
+	}
+	if len(scope.Clusters) == 0 {
+		return query.Where("1 = 0")
+	}
+
+	conditions := make([]string, 0, len(scope.Clusters))
+	args := make([]interface{}, 0)
+	var whole []int64
+	for _, id := range scope.ClusterIDs() {
+		patterns := scope.Clusters[id]
+		if len(patterns) == 0 || namespaceColumn == "" {
+			whole = append(whole, id)
+			continue
+		}
+		likes := make([]string, 0, len(patterns))
+		args = append(args, id)
+		for _, pattern := range patterns {
+			likes = append(likes, namespaceColumn+" LIKE ?")
+			args = append(args, globToLike(pattern))
+		}
+		conditions = append(conditions, "("+configColumn+" = ? AND ("+strings.Join(likes, " OR ")+"))")
+	}
+	if len(whole) > 0 {
+		conditions = append(conditions, configColumn+" IN ?")
+		args = append(args, whole)
+	}
+
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// applyIDScope 按数据范围限定资源ID；scope 不限制时 ids 参数被忽略
+func applyIDScope(query *gorm.DB, scope *model.DataScope, column string, ids []int64) *gorm.DB {
+	if scope.Unrestricted() {
+		return query
+	}
+	if len(ids) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where(column+" IN ?", ids)
+}
+
+// globToLike 将命名空间通配符模式（* 和 ?）转换为 LIKE 模式
+func globToLike(pattern string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%", "?", "_")
+	return replacer.Replace(pattern)
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"eden-ops/internal/model"
+)
+
+// likeToRegexp 按 MySQL LIKE 的语义（\ 为转义字符）将 LIKE 模式转换为正则表达式
+func likeToRegexp(like string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(like); i++ {
+		switch c := like[i]; c {
+		case '\\':
+			i++
+			b.WriteString(regexp.QuoteMeta(string(like[i])))
+		case '%':
+			b.WriteString("(?s:.*)")
+		case '_':
+			b.WriteString("(?s:.)")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// 列表查询使用的 LIKE 模式与单个对象的数据范围校验必须得到相同的结果
+func TestGlobToLikeMatchesDataScope(t *testing.T) {
+	patterns := []string{"*", "team-*", "team-?", "*-prod", "a*b?c", "ns_1", "ns%", "team-[ab]*"}
+	namespaces := []string{"", "default", "team-a", "team-ab", "team-", "pay-prod", "axbyc", "ab1c", "ns_1", "nsx1", "ns%", "nsabc", "team-a1", "team-[ab]x"}
+	for _, pattern := range patterns {
+		re := likeToRegexp(globToLike(pattern))
+		for _, namespace := range namespaces {
+			sqlMatch := re.MatchString(namespace)
+			scopeMatch := model.MatchNamespacePattern(pattern, namespace)
+			if sqlMatch != scopeMatch {
+				t.Errorf("pattern %q namespace %q: LIKE %q = %v, MatchNamespacePattern = %v",
+					pattern, namespace, globToLike(pattern), sqlMatch, scopeMatch)
+			}
+		}
+	}
+}
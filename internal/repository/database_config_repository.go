@@ -19,14 +19,11 @@ func NewDatabaseConfigRepository(db *gorm.DB) *DatabaseConfigRepository {
 }
 
 // List 获取数据库配置列表
-func (r *DatabaseConfigRepository) List(page, pageSize int, name string) (int64, []model.DatabaseConfig, error) {
+func (r *DatabaseConfigRepository) List(page, pageSize int, name string, scope *model.DataScope) (int64, []model.DatabaseConfig, error) {
 	var total int64
 	var configs []model.DatabaseConfig
 
-	query := r.db.Model(&model.DatabaseConfig{})
-	if name != "" {
-		query = query.Where("name LIKE ?", "%"+name+"%")
-	}
+	query := r.filter(name, scope)
 
 	err := query.Count(&total).Error
 	if err != nil {
@@ -42,6 +39,22 @@ func (r *DatabaseConfigRepository) List(page, pageSize int, name string) (int64,
 	return total, configs, nil
 }
 
+// Each 按ID升序分批遍历符合条件的数据库配置，最多遍历 limit 条，用于导出
+func (r *DatabaseConfigRepository) Each(name string, scope *model.DataScope, limit, batchSize int, fn func([]model.DatabaseConfig) error) error {
+	return eachInBatches(func() *gorm.DB {
+		return r.filter(name, scope)
+	}, "id", limit, batchSize, func(config *model.DatabaseConfig) int64 { return int64(config.ID) }, fn)
+}
+
+// filter 按名称和数据范围过滤数据库配置
+func (r *DatabaseConfigRepository) filter(name string, scope *model.DataScope) *gorm.DB {
+	query := r.db.Model(&model.DatabaseConfig{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+	return applyIDScope(query, scope, "id", scope.DatabaseIDs())
+}
+
 // Create 创建数据库配置
 func (r *DatabaseConfigRepository) Create(config *model.DatabaseConfig) error {
 	return r.db.Create(config).Error
@@ -66,3 +79,10 @@ func (r *DatabaseConfigRepository) Update(config *model.DatabaseConfig) error {
 func (r *DatabaseConfigRepository) Delete(id uint) error {
 	return r.db.Delete(&model.DatabaseConfig{}, id).Error
 }
+
+// FindByHost 根据主机地址查找配置
+func (r *DatabaseConfigRepository) FindByHost(host string) ([]model.DatabaseConfig, error) {
+	var configs []model.DatabaseConfig
+	err := r.db.Where("host = ?", host).Find(&configs).Error
+	return configs, err
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// eachInBatches 按主键升序分批遍历查询结果，最多遍历 limit 条，用于导出。
+// query 每次调用返回一个新的带过滤条件的查询，idColumn 为主键列，id 读取记录的主键；
+// 按主键定位下一批而不是使用 OFFSET，导出大量数据时每批的查询代价不会随位置增加
+func eachInBatches[T any](query func() *gorm.DB, idColumn string, limit, batchSize int, id func(*T) int64, fn func([]T) error) error {
+	var lastID int64
+	for read := 0; read < limit; {
+		size := batchSize
+		if limit-read < size {
+			size = limit - read
+		}
+
+		q := query()
+		if read > 0 {
+			q = q.Where(idColumn+" > ?", lastID)
+		}
+		var rows []T
+		if err := q.Order(idColumn).Limit(size).Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		read += len(rows)
+		lastID = id(&rows[len(rows)-1])
+		if len(rows) < size {
+			return nil
+		}
+	}
+	return nil
+}
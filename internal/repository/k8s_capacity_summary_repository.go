@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// K8sCapacitySummaryRepository 每周容量汇总仓库接口
+type K8sCapacitySummaryRepository interface {
+	Save(summary *model.K8sCapacitySummary) error
+	List(configID int64, q *listquery.Query) (*listquery.Result[*model.K8sCapacitySummary], error)
+}
+
+// k8sCapacitySummaryRepository 每周容量汇总仓库实现
+type k8sCapacitySummaryRepository struct {
+	db *gorm.DB
+}
+
+// NewK8sCapacitySummaryRepository 创建每周容量汇总仓库
+func NewK8sCapacitySummaryRepository(db *gorm.DB) K8sCapacitySummaryRepository {
+	return &k8sCapacitySummaryRepository{db: db}
+}
+
+// Save 保存汇总，同一集群同一周已有汇总时覆盖
+func (r *k8sCapacitySummaryRepository) Save(summary *model.K8sCapacitySummary) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "config_id"}, {Name: "week_start"}},
+		UpdateAll: true,
+	}).Create(summary).Error
+}
+
+// List 查询集群的每周汇总
+func (r *k8sCapacitySummaryRepository) List(configID int64, q *listquery.Query) (*listquery.Result[*model.K8sCapacitySummary], error) {
+	query := r.db.Model(&model.K8sCapacitySummary{}).Where("config_id = ?", configID)
+	return listquery.Find[*model.K8sCapacitySummary](query, q)
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// K8sChangeEventFilter K8s资源变更事件查询条件，零值表示不限制
+type K8sChangeEventFilter struct {
+	ConfigID     int64
+	Kind         string
+	WorkloadKind string
+	Namespace    string
+	Name         string
+	Action       string
+	Field        string // 变更的字段名
+	StartTime    *time.Time
+	EndTime      *time.Time
+}
+
+// K8sChangeEventRepository K8s资源变更事件仓库接口
+type K8sChangeEventRepository interface {
+	BatchCreate(events []*model.K8sChangeEvent) error
+	List(filter *K8sChangeEventFilter, q *listquery.Query, scope *model.DataScope) (*listquery.Result[*model.K8sChangeEvent], error)
+	CleanupBefore(ctx context.Context, beforeDate time.Time, opts BatchDeleteOptions) (int64, error)
+}
+
+// k8sChangeEventRepository K8s资源变更事件仓库实现
+type k8sChangeEventRepository struct {
+	db *gorm.DB
+}
+
+// NewK8sChangeEventRepository 创建K8s资源变更事件仓库
+func NewK8sChangeEventRepository(db *gorm.DB) K8sChangeEventRepository {
+	return &k8sChangeEventRepository{db: db}
+}
+
+// changeEventBatchSize 批量写入变更事件时每批的条数
+const changeEventBatchSize = 500
+
+// BatchCreate 批量写入变更事件
+func (r *k8sChangeEventRepository) BatchCreate(events []*model.K8sChangeEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(events, changeEventBatchSize).Error
+}
+
+// List 查询变更事件。节点不属于命名空间，限定了命名空间的数据范围看不到节点的变更
+func (r *k8sChangeEventRepository) List(filter *K8sChangeEventFilter, q *listquery.Query, scope *model.DataScope) (*listquery.Result[*model.K8sChangeEvent], error) {
+	query := applyClusterScope(r.db.Model(&model.K8sChangeEvent{}), scope, "config_id", "namespace")
+	if filter.ConfigID > 0 {
+		query = query.Where("config_id = ?", filter.ConfigID)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.WorkloadKind != "" {
+		query = query.Where("workload_kind = ?", filter.WorkloadKind)
+	}
+	if filter.Namespace != "" {
+		query = query.Where("namespace = ?", filter.Namespace)
+	}
+	if filter.Name != "" {
+		query = query.Where("name = ?", filter.Name)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Field != "" {
+		query = query.Where("FIND_IN_SET(?, fields) > 0", filter.Field)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("observed_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("observed_at <= ?", *filter.EndTime)
+	}
+
+	return listquery.Find[*model.K8sChangeEvent](query, q)
+}
+
+// CleanupBefore 分批删除 beforeDate 之前发现的变更事件
+func (r *k8sChangeEventRepository) CleanupBefore(ctx context.Context, beforeDate time.Time, opts BatchDeleteOptions) (int64, error) {
+	return execDeleteInBatches(ctx, r.db,
+		"DELETE FROM infra_k8s_change_event WHERE observed_at < ? ORDER BY observed_at LIMIT ?",
+		[]interface{}{beforeDate}, opts)
+}
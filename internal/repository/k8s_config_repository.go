@@ -18,7 +18,8 @@ type K8sConfigRepository interface {
 	Update(config *model.K8sConfig) error
 	Delete(id int64) error
 	Get(id int64) (*model.K8sConfig, error)
-	List(page, pageSize int, name string, status *int, providerId *int64, clusterID string) (int64, []model.K8sConfig, error)
+	List(page, pageSize int, name string, status *int, providerId *int64, clusterID string, scope *model.DataScope) (int64, []model.K8sConfig, error)
+	Each(name string, status *int, providerId *int64, clusterID string, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sConfig) error) error
 	UpdateDestroyedStats(configID int64, workloadCount, podCount, nodeCount int) error
 	GetDB() *gorm.DB
 }
@@ -69,23 +70,11 @@ func (r *k8sConfigRepository) Get(id int64) (*model.K8sConfig, error) {
 }
 
 // List 获取Kubernetes配置列表
-func (r *k8sConfigRepository) List(page, pageSize int, name string, status *int, providerId *int64, clusterID string) (int64, []model.K8sConfig, error) {
+func (r *k8sConfigRepository) List(page, pageSize int, name string, status *int, providerId *int64, clusterID string, scope *model.DataScope) (int64, []model.K8sConfig, error) {
 	var configs []model.K8sConfig
 	var total int64
 
-	query := r.db.Model(&model.K8sConfig{})
-	if name != "" {
-		query = query.Where("infra_k8s_config.name LIKE ?", "%"+name+"%")
-	}
-	if status != nil {
-		query = query.Where("infra_k8s_config.status = ?", *status)
-	}
-	if providerId != nil {
-		query = query.Where("infra_k8s_config.provider_id = ?", *providerId)
-	}
-	if clusterID != "" {
-		query = query.Where("infra_k8s_config.cluster_id = ?", clusterID)
-	}
+	query := r.filter(name, status, providerId, clusterID, scope)
 
 	if err := query.Count(&total).Error; err != nil {
 		return 0, nil, err
@@ -115,6 +104,54 @@ func (r *k8sConfigRepository) List(page, pageSize int, name string, status *int,
 	return total, configs, nil
 }
 
+// Each 按ID升序分批遍历符合条件的Kubernetes配置并填充云厂商名称，最多遍历 limit 条，用于导出
+func (r *k8sConfigRepository) Each(name string, status *int, providerId *int64, clusterID string, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sConfig) error) error {
+	return eachInBatches(func() *gorm.DB {
+		return r.filter(name, status, providerId, clusterID, scope)
+	}, "infra_k8s_config.id", limit, batchSize, func(config *model.K8sConfig) int64 { return config.ID }, func(configs []model.K8sConfig) error {
+		providerIDs := make([]int64, 0, len(configs))
+		for _, config := range configs {
+			if config.ProviderId != nil {
+				providerIDs = append(providerIDs, *config.ProviderId)
+			}
+		}
+		if len(providerIDs) > 0 {
+			var providers []model.CloudProvider
+			if err := r.db.Select("id, name").Where("id IN ?", providerIDs).Find(&providers).Error; err != nil {
+				return err
+			}
+			names := make(map[int64]string, len(providers))
+			for _, provider := range providers {
+				names[int64(provider.ID)] = provider.Name
+			}
+			for i := range configs {
+				if configs[i].ProviderId != nil {
+					configs[i].ProviderName = names[*configs[i].ProviderId]
+				}
+			}
+		}
+		return fn(configs)
+	})
+}
+
+// filter 应用Kubernetes配置的筛选条件和数据范围
+func (r *k8sConfigRepository) filter(name string, status *int, providerId *int64, clusterID string, scope *model.DataScope) *gorm.DB {
+	query := r.db.Model(&model.K8sConfig{})
+	if name != "" {
+		query = query.Where("infra_k8s_config.name LIKE ?", "%"+name+"%")
+	}
+	if status != nil {
+		query = query.Where("infra_k8s_config.status = ?", *status)
+	}
+	if providerId != nil {
+		query = query.Where("infra_k8s_config.provider_id = ?", *providerId)
+	}
+	if clusterID != "" {
+		query = query.Where("infra_k8s_config.cluster_id = ?", clusterID)
+	}
+	return applyIDScope(query, scope, "infra_k8s_config.id", scope.ClusterIDs())
+}
+
 // GetDB 获取数据库连接
 func (r *k8sConfigRepository) GetDB() *gorm.DB {
 	return r.db
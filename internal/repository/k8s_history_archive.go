@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// historyRowKey 历史记录在归档和恢复时使用的定位信息
+type historyRowKey struct {
+	ID         uint
+	OriginalID uint
+	ArchivedAt time.Time
+}
+
+// eachHistoryForArchive 按 (archived_at, id) 顺序分批读取某个集群 beforeDate 之前归档、且不是从归档文件恢复的历史数据。
+// 使用键集分页，读取期间删除或插入数据不会导致跳过或重复
+func eachHistoryForArchive[T any](ctx context.Context, db *gorm.DB, configID int64, beforeDate time.Time, batchSize int,
+	key func(*T) historyRowKey, fn func([]T) error) error {
+	if batchSize <= 0 {
+		batchSize = defaultDeleteBatchSize
+	}
+
+	var last *historyRowKey
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		query := db.WithContext(ctx).
+			Where("config_id = ? AND archived_at < ? AND restored_at IS NULL", configID, beforeDate)
+		if last != nil {
+			query = query.Where("(archived_at > ? OR (archived_at = ? AND id > ?))", last.ArchivedAt, last.ArchivedAt, last.ID)
+		}
+		var rows []T
+		if err := query.Order("archived_at, id").Limit(batchSize).Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		if len(rows) < batchSize {
+			return nil
+		}
+		k := key(&rows[len(rows)-1])
+		last = &k
+	}
+}
+
+// restoreHistory 将归档文件中的历史数据写回历史表，已存在相同原始ID和归档时间的记录会被跳过，
+// 恢复的记录重新分配ID并记录恢复时间。返回实际写入的行数
+func restoreHistory[T any](ctx context.Context, db *gorm.DB, table string, configID int64, rows []T,
+	key func(*T) historyRowKey, markRestored func(*T, time.Time)) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	minAt, maxAt := key(&rows[0]).ArchivedAt, key(&rows[0]).ArchivedAt
+	for i := range rows {
+		at := key(&rows[i]).ArchivedAt
+		if at.Before(minAt) {
+			minAt = at
+		}
+		if at.After(maxAt) {
+			maxAt = at
+		}
+	}
+
+	var existing []historyRowKey
+	err := db.WithContext(ctx).Table(table).
+		Select("original_id, archived_at").
+		Where("config_id = ? AND archived_at BETWEEN ? AND ?", configID, minAt, maxAt).
+		Scan(&existing).Error
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]struct{}, len(existing))
+	for _, k := range existing {
+		seen[restoreKey(k)] = struct{}{}
+	}
+
+	now := time.Now()
+	pending := make([]T, 0, len(rows))
+	for i := range rows {
+		k := restoreKey(key(&rows[i]))
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		markRestored(&rows[i], now)
+		pending = append(pending, rows[i])
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	result := db.WithContext(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).CreateInBatches(pending, defaultDeleteBatchSize)
+	return result.RowsAffected, result.Error
+}
+
+// restoreKey 判断记录是否已存在时使用的键，归档时间精确到秒
+func restoreKey(k historyRowKey) string {
+	return fmt.Sprintf("%d/%d", k.OriginalID, k.ArchivedAt.Unix())
+}
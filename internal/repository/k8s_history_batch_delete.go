@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultDeleteBatchSize 未配置批量大小时每批删除的行数
+const defaultDeleteBatchSize = 1000
+
+// BatchDeleteOptions 分批删除参数
+type BatchDeleteOptions struct {
+	BatchSize int           // 每批删除的行数
+	Sleep     time.Duration // 批次之间的间隔，降低对数据库的压力
+	// OnlyArchived 只删除已归档的数据：按 (archived_at, id) 不超过 ArchivedUpTo 的数据，以及从归档文件恢复的数据。
+	// 归档和删除是两次查询，期间同步写入的数据不在归档范围内，不能删除
+	OnlyArchived bool
+	ArchivedUpTo *HistoryArchiveBound // 为空表示没有归档任何数据
+}
+
+// HistoryArchiveBound 已归档数据中按 (archived_at, id) 排序的最后一条的位置
+type HistoryArchiveBound struct {
+	ArchivedAt time.Time
+	ID         uint
+}
+
+// deleteHistoryInBatches 分批删除历史表中 beforeDate 之前归档的数据，configID 为 0 时不区分集群。
+// 从归档文件恢复的数据按恢复时间计算保留期。每批是一个独立的短语句，避免长时间锁表；
+// ctx 取消时停止并返回已删除的行数
+func deleteHistoryInBatches(ctx context.Context, db *gorm.DB, table string, configID int64, beforeDate time.Time, opts BatchDeleteOptions) (int64, error) {
+	where := "archived_at < ? AND (restored_at IS NULL OR restored_at < ?)"
+	args := []interface{}{beforeDate, beforeDate}
+	if configID > 0 {
+		where = "config_id = ? AND " + where
+		args = append([]interface{}{configID}, args...)
+	}
+	if opts.OnlyArchived {
+		if bound := opts.ArchivedUpTo; bound != nil {
+			where += " AND (restored_at IS NOT NULL OR archived_at < ? OR (archived_at = ? AND id <= ?))"
+			args = append(args, bound.ArchivedAt, bound.ArchivedAt, bound.ID)
+		} else {
+			where += " AND restored_at IS NOT NULL"
+		}
+	}
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s ORDER BY archived_at LIMIT ?", table, where)
+	return execDeleteInBatches(ctx, db, sql, args, opts)
+}
+
+// execDeleteInBatches 重复执行带 LIMIT 的删除语句直到删除的行数不足一批，sql 的最后一个参数为批量大小
+func execDeleteInBatches(ctx context.Context, db *gorm.DB, sql string, args []interface{}, opts BatchDeleteOptions) (int64, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultDeleteBatchSize
+	}
+	args = append(args, batchSize)
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		result := db.WithContext(ctx).Exec(sql, args...)
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < int64(batchSize) {
+			return total, nil
+		}
+
+		if opts.Sleep > 0 {
+			timer := time.NewTimer(opts.Sleep)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return total, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
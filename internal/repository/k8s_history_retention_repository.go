@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// K8sHistoryRetentionPolicyRepository K8s历史数据保留策略仓库接口
+type K8sHistoryRetentionPolicyRepository interface {
+	Create(policy *model.K8sHistoryRetentionPolicy) error
+	Update(policy *model.K8sHistoryRetentionPolicy) error
+	Delete(id uint) error
+	Get(id uint) (*model.K8sHistoryRetentionPolicy, error)
+	FindByConfigAndKind(configID int64, kind string) (*model.K8sHistoryRetentionPolicy, error)
+	List() ([]*model.K8sHistoryRetentionPolicy, error)
+}
+
+// k8sHistoryRetentionPolicyRepository K8s历史数据保留策略仓库实现
+type k8sHistoryRetentionPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewK8sHistoryRetentionPolicyRepository 创建K8s历史数据保留策略仓库
+func NewK8sHistoryRetentionPolicyRepository(db *gorm.DB) K8sHistoryRetentionPolicyRepository {
+	return &k8sHistoryRetentionPolicyRepository{db: db}
+}
+
+// Create 创建保留策略
+func (r *k8sHistoryRetentionPolicyRepository) Create(policy *model.K8sHistoryRetentionPolicy) error {
+	return r.db.Create(policy).Error
+}
+
+// Update 更新保留策略的保留天数和备注
+func (r *k8sHistoryRetentionPolicyRepository) Update(policy *model.K8sHistoryRetentionPolicy) error {
+	return r.db.Model(policy).Select("retention_days", "remark").Updates(policy).Error
+}
+
+// Delete 删除保留策略
+func (r *k8sHistoryRetentionPolicyRepository) Delete(id uint) error {
+	return r.db.Delete(&model.K8sHistoryRetentionPolicy{}, id).Error
+}
+
+// Get 获取保留策略
+func (r *k8sHistoryRetentionPolicyRepository) Get(id uint) (*model.K8sHistoryRetentionPolicy, error) {
+	var policy model.K8sHistoryRetentionPolicy
+	if err := r.db.First(&policy, id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FindByConfigAndKind 根据集群和类型查找保留策略
+func (r *k8sHistoryRetentionPolicyRepository) FindByConfigAndKind(configID int64, kind string) (*model.K8sHistoryRetentionPolicy, error) {
+	var policy model.K8sHistoryRetentionPolicy
+	if err := r.db.Where("config_id = ? AND kind = ?", configID, kind).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// List 获取全部保留策略，附带集群名称
+func (r *k8sHistoryRetentionPolicyRepository) List() ([]*model.K8sHistoryRetentionPolicy, error) {
+	var policies []*model.K8sHistoryRetentionPolicy
+	if err := r.db.Order("config_id, kind").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	var configIDs []int64
+	for _, policy := range policies {
+		if policy.ConfigID > 0 {
+			configIDs = append(configIDs, policy.ConfigID)
+		}
+	}
+	if len(configIDs) == 0 {
+		return policies, nil
+	}
+	var configs []model.K8sConfig
+	if err := r.db.Select("id", "name").Where("id IN ?", configIDs).Find(&configs).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(configs))
+	for _, config := range configs {
+		names[config.ID] = config.Name
+	}
+	for _, policy := range policies {
+		policy.ClusterName = names[policy.ConfigID]
+	}
+	return policies, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// K8sHistoryTableSummary 历史表的行数和归档时间范围
+type K8sHistoryTableSummary struct {
+	Count            int64      `json:"count"`
+	OldestArchivedAt *time.Time `json:"oldestArchivedAt"`
+	NewestArchivedAt *time.Time `json:"newestArchivedAt"`
+}
+
+// K8sHistoryClusterSummary 单个集群在历史表中的行数和归档时间范围
+type K8sHistoryClusterSummary struct {
+	ConfigID         int64      `json:"configId"`
+	ClusterName      string     `json:"clusterName"`
+	Count            int64      `json:"count"`
+	OldestArchivedAt *time.Time `json:"oldestArchivedAt"`
+	NewestArchivedAt *time.Time `json:"newestArchivedAt"`
+}
+
+// K8sHistoryTableSize information_schema 中的表空间信息，行数为估算值
+type K8sHistoryTableSize struct {
+	EstimatedRows int64 `json:"estimatedRows"`
+	DataSize      int64 `json:"dataSize"`  // 数据大小，字节
+	IndexSize     int64 `json:"indexSize"` // 索引大小，字节
+}
+
+// K8sHistoryStatisticsRepository K8s历史表统计和清理记录仓库接口
+type K8sHistoryStatisticsRepository interface {
+	SummarizeTable(table string, configID int64) (*K8sHistoryTableSummary, error)
+	SummarizeByCluster(table string) ([]*K8sHistoryClusterSummary, error)
+	GetTableSize(table string) (*K8sHistoryTableSize, error)
+	ListConfigIDs(table string) ([]int64, error)
+
+	CreateCleanupRun(run *model.K8sHistoryCleanupRun) error
+	UpdateCleanupRun(run *model.K8sHistoryCleanupRun) error
+	ListCleanupRuns(limit int) ([]*model.K8sHistoryCleanupRun, error)
+}
+
+// k8sHistoryStatisticsRepository K8s历史表统计和清理记录仓库实现
+type k8sHistoryStatisticsRepository struct {
+	db *gorm.DB
+}
+
+// NewK8sHistoryStatisticsRepository 创建K8s历史表统计和清理记录仓库
+func NewK8sHistoryStatisticsRepository(db *gorm.DB) K8sHistoryStatisticsRepository {
+	return &k8sHistoryStatisticsRepository{db: db}
+}
+
+// SummarizeTable 统计历史表的行数和归档时间范围，configID 为 0 时统计全部集群
+func (r *k8sHistoryStatisticsRepository) SummarizeTable(table string, configID int64) (*K8sHistoryTableSummary, error) {
+	var row struct {
+		Count            int64
+		OldestArchivedAt *time.Time
+		NewestArchivedAt *time.Time
+	}
+	query := r.db.Table(table).
+		Select("COUNT(*) AS count, MIN(archived_at) AS oldest_archived_at, MAX(archived_at) AS newest_archived_at")
+	if configID > 0 {
+		query = query.Where("config_id = ?", configID)
+	}
+	if err := query.Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	return &K8sHistoryTableSummary{
+		Count:            row.Count,
+		OldestArchivedAt: row.OldestArchivedAt,
+		NewestArchivedAt: row.NewestArchivedAt,
+	}, nil
+}
+
+// SummarizeByCluster 按集群统计历史表的行数和归档时间范围，集群已删除时名称为空
+func (r *k8sHistoryStatisticsRepository) SummarizeByCluster(table string) ([]*K8sHistoryClusterSummary, error) {
+	var summaries []*K8sHistoryClusterSummary
+	err := r.db.Table(table + " AS h").
+		Select("h.config_id, COALESCE(c.name, '') AS cluster_name, COUNT(*) AS count, " +
+			"MIN(h.archived_at) AS oldest_archived_at, MAX(h.archived_at) AS newest_archived_at").
+		Joins("LEFT JOIN " + model.K8sConfig{}.TableName() + " AS c ON c.id = h.config_id").
+		Group("h.config_id, c.name").
+		Order("count DESC").
+		Scan(&summaries).Error
+	return summaries, err
+}
+
+// GetTableSize 从 information_schema 获取表空间信息
+func (r *k8sHistoryStatisticsRepository) GetTableSize(table string) (*K8sHistoryTableSize, error) {
+	var size K8sHistoryTableSize
+	err := r.db.Raw(`SELECT COALESCE(table_rows, 0) AS estimated_rows,
+			COALESCE(data_length, 0) AS data_size,
+			COALESCE(index_length, 0) AS index_size
+		FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = ?`, table).Scan(&size).Error
+	return &size, err
+}
+
+// ListConfigIDs 获取历史表中出现的全部集群ID，包括已删除的集群
+func (r *k8sHistoryStatisticsRepository) ListConfigIDs(table string) ([]int64, error) {
+	var ids []int64
+	err := r.db.Table(table).Distinct("config_id").Order("config_id").Pluck("config_id", &ids).Error
+	return ids, err
+}
+
+// CreateCleanupRun 创建清理记录
+func (r *k8sHistoryStatisticsRepository) CreateCleanupRun(run *model.K8sHistoryCleanupRun) error {
+	return r.db.Create(run).Error
+}
+
+// UpdateCleanupRun 更新清理记录
+func (r *k8sHistoryStatisticsRepository) UpdateCleanupRun(run *model.K8sHistoryCleanupRun) error {
+	return r.db.Save(run).Error
+}
+
+// ListCleanupRuns 获取最近的清理记录
+func (r *k8sHistoryStatisticsRepository) ListCleanupRuns(limit int) ([]*model.K8sHistoryCleanupRun, error) {
+	var runs []*model.K8sHistoryCleanupRun
+	err := r.db.Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// K8sInventoryFilter 时间点清单查询条件，ConfigID 和 At 必填，其余零值表示不限制
+type K8sInventoryFilter struct {
+	ConfigID     int64
+	At           time.Time
+	Namespace    string
+	Name         string // 名称模糊匹配
+	NodeName     string // 仅Pod
+	WorkloadName string // 仅Pod
+	WorkloadKind string // Pod按所属工作负载类型过滤，工作负载按自身类型过滤
+}
+
+// K8sInventoryRepository K8s时间点清单仓库接口
+type K8sInventoryRepository interface {
+	PodsAt(filter *K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryPod, int64, error)
+	NodesAt(filter *K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryNode, int64, error)
+	WorkloadsAt(filter *K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryWorkload, int64, error)
+}
+
+// k8sInventoryRepository K8s时间点清单仓库实现
+type k8sInventoryRepository struct {
+	db *gorm.DB
+}
+
+// NewK8sInventoryRepository 创建K8s时间点清单仓库
+func NewK8sInventoryRepository(db *gorm.DB) K8sInventoryRepository {
+	return &k8sInventoryRepository{db: db}
+}
+
+// inventorySource 一类对象的实时表和历史表
+type inventorySource struct {
+	liveTable       string
+	historyTable    string
+	columns         string   // 两张表共有的列
+	identity        []string // 除 config_id 和 created_at 外标识同一对象的列
+	namespaceColumn string   // 数据范围按命名空间限定时使用的列，为空表示仅按集群限定
+	order           string
+}
+
+var (
+	podInventorySource = inventorySource{
+		liveTable:    "infra_k8s_pod",
+		historyTable: "infra_k8s_pod_history",
+		columns: "config_id, workload_id, name, namespace, workload_name, workload_kind, status, phase, node_name, " +
+			"pod_ip, host_ip, instance_ip, cpu_request, cpu_limit, memory_request, memory_limit, restart_count, start_time, created_at",
+		identity:        []string{"name", "namespace"},
+		namespaceColumn: "namespace",
+		order:           "namespace, name, created_at",
+	}
+	nodeInventorySource = inventorySource{
+		liveTable:    "infra_k8s_node",
+		historyTable: "infra_k8s_node_history",
+		columns: "config_id, name, internal_ip, external_ip, hostname, os_image, kernel_version, container_runtime, kubelet_version, " +
+			"cpu_capacity, memory_capacity, pods_capacity, cpu_allocatable, memory_allocatable, pods_allocatable, " +
+			"labels, taints, status, ready, schedulable, created_at",
+		identity: []string{"name"},
+		order:    "name, created_at",
+	}
+	workloadInventorySource = inventorySource{
+		liveTable:    "infra_k8s_workload",
+		historyTable: "infra_k8s_workload_history",
+		columns: "config_id, name, namespace, kind, replicas, ready_replicas, status, labels, selector, images, " +
+			"cpu_request, cpu_limit, memory_request, memory_limit, created_at",
+		identity:        []string{"name", "namespace", "kind"},
+		namespaceColumn: "namespace",
+		order:           "namespace, kind, name, created_at",
+	}
+)
+
+// PodsAt 查询某一时间点存在的Pod
+func (r *k8sInventoryRepository) PodsAt(filter *K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryPod, int64, error) {
+	var pods []*model.K8sInventoryPod
+	total, err := r.listAt(podInventorySource, filter, page, pageSize, scope, func(query *gorm.DB) *gorm.DB {
+		if filter.Namespace != "" {
+			query = query.Where("namespace = ?", filter.Namespace)
+		}
+		if filter.Name != "" {
+			query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+		}
+		if filter.NodeName != "" {
+			query = query.Where("node_name = ?", filter.NodeName)
+		}
+		if filter.WorkloadName != "" {
+			query = query.Where("workload_name = ?", filter.WorkloadName)
+		}
+		if filter.WorkloadKind != "" {
+			query = query.Where("workload_kind = ?", filter.WorkloadKind)
+		}
+		return query
+	}, &pods)
+	return pods, total, err
+}
+
+// NodesAt 查询某一时间点存在的节点
+func (r *k8sInventoryRepository) NodesAt(filter *K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryNode, int64, error) {
+	var nodes []*model.K8sInventoryNode
+	total, err := r.listAt(nodeInventorySource, filter, page, pageSize, scope, func(query *gorm.DB) *gorm.DB {
+		if filter.Name != "" {
+			query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+		}
+		return query
+	}, &nodes)
+	return nodes, total, err
+}
+
+// WorkloadsAt 查询某一时间点存在的工作负载
+func (r *k8sInventoryRepository) WorkloadsAt(filter *K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryWorkload, int64, error) {
+	var workloads []*model.K8sInventoryWorkload
+	total, err := r.listAt(workloadInventorySource, filter, page, pageSize, scope, func(query *gorm.DB) *gorm.DB {
+		if filter.Namespace != "" {
+			query = query.Where("namespace = ?", filter.Namespace)
+		}
+		if filter.Name != "" {
+			query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+		}
+		if filter.WorkloadKind != "" {
+			query = query.Where("kind = ?", filter.WorkloadKind)
+		}
+		return query
+	}, &workloads)
+	return workloads, total, err
+}
+
+// listAt 合并实时表和历史表得到某一时间点存在的对象：
+// 实时表中创建时间不晚于该时间点的对象至今仍存在；历史表中创建时间不晚于该时间点、且删除（或同步发现其消失而归档）晚于该时间点的对象当时存在。
+// 对象消失后又重新出现时，历史表和实时表中会各有一条创建时间相同的记录，此时只取实时表中的记录。
+// 时间精度受同步间隔限制，对象实际删除的时间早于归档时间
+func (r *k8sInventoryRepository) listAt(source inventorySource, filter *K8sInventoryFilter, page, pageSize int, scope *model.DataScope, apply func(*gorm.DB) *gorm.DB, dest interface{}) (int64, error) {
+	live := r.db.Table(source.liveTable).
+		Select("id AS original_id, "+source.columns+", NULL AS removed_at, ? AS source", model.K8sInventorySourceLive).
+		Where("config_id = ? AND created_at <= ? AND deleted_at IS NULL", filter.ConfigID, filter.At)
+	live = apply(applyClusterScope(live, scope, "config_id", source.namespaceColumn))
+
+	twin := make([]string, 0, len(source.identity)+2)
+	for _, column := range append([]string{"config_id", "created_at"}, source.identity...) {
+		twin = append(twin, "l."+column+" = "+source.historyTable+"."+column)
+	}
+	history := r.db.Table(source.historyTable).
+		Select("original_id, "+source.columns+", COALESCE(deleted_at, archived_at) AS removed_at, ? AS source", model.K8sInventorySourceHistory).
+		Where("config_id = ? AND archived_at > ? AND created_at <= ? AND (deleted_at IS NULL OR deleted_at > ?)",
+			filter.ConfigID, filter.At, filter.At, filter.At).
+		Where("NOT EXISTS (SELECT 1 FROM " + source.liveTable + " l WHERE " + strings.Join(twin, " AND ") + " AND l.deleted_at IS NULL)")
+	history = apply(applyClusterScope(history, scope, "config_id", source.namespaceColumn))
+
+	inventory := r.db.Table("(?) AS inventory", r.db.Raw("(?) UNION ALL (?)", live, history))
+	var total int64
+	if err := inventory.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	err := inventory.Order(source.order).Offset((page - 1) * pageSize).Limit(pageSize).Find(dest).Error
+	return total, err
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// 标签选择器运算符
+const (
+	LabelOpIn           = "in"     // =、==、in
+	LabelOpNotIn        = "notin"  // !=、notin，没有该标签的对象同样满足
+	LabelOpExists       = "exists" // 只写标签名
+	LabelOpDoesNotExist = "!"      // !标签名
+	LabelOpGreaterThan  = "gt"
+	LabelOpLessThan     = "lt"
+)
+
+// LabelRequirement 标签选择器中的一个条件，多个条件之间为与的关系
+type LabelRequirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// K8sLabelRepository K8s资源标签索引仓库接口
+type K8sLabelRepository interface {
+	Sync(configID int64, kind string, labels []*model.K8sLabel) error
+}
+
+// k8sLabelRepository K8s资源标签索引仓库实现
+type k8sLabelRepository struct {
+	db *gorm.DB
+}
+
+// NewK8sLabelRepository 创建K8s资源标签索引仓库
+func NewK8sLabelRepository(db *gorm.DB) K8sLabelRepository {
+	return &k8sLabelRepository{db: db}
+}
+
+// labelBatchSize 标签索引每批写入和删除的条数
+const labelBatchSize = 500
+
+// labelIndexKey 同一对象的同一标签
+type labelIndexKey struct {
+	objectID int64
+	key      string
+}
+
+// Sync 将某个集群某类对象的标签索引更新为 labels，只写入新增和值有变化的标签，删除已不存在的标签
+func (r *k8sLabelRepository) Sync(configID int64, kind string, labels []*model.K8sLabel) error {
+	var existing []model.K8sLabel
+	if err := r.db.Select("id, object_id, label_key, label_value").
+		Where("config_id = ? AND kind = ?", configID, kind).Find(&existing).Error; err != nil {
+		return err
+	}
+	current := make(map[labelIndexKey]model.K8sLabel, len(existing))
+	for _, label := range existing {
+		current[labelIndexKey{label.ObjectID, label.LabelKey}] = label
+	}
+
+	created := make([]*model.K8sLabel, 0)
+	for _, label := range labels {
+		key := labelIndexKey{label.ObjectID, label.LabelKey}
+		if old, ok := current[key]; ok && old.LabelValue == label.LabelValue {
+			delete(current, key)
+			continue
+		}
+		created = append(created, label)
+	}
+	stale := make([]int64, 0, len(current))
+	for _, label := range current {
+		stale = append(stale, label.ID)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(stale); start += labelBatchSize {
+			end := start + labelBatchSize
+			if end > len(stale) {
+				end = len(stale)
+			}
+			if err := tx.Where("id IN ?", stale[start:end]).Delete(&model.K8sLabel{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(created) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(created, labelBatchSize).Error
+	})
+}
+
+// integerLabelPattern gt、lt 只比较值为整数的标签
+const integerLabelPattern = "^-?[0-9]+$"
+
+// applyLabelSelector 按标签选择器过滤对象，idColumn 为带表名的对象ID列，避免与子查询中的列混淆
+func applyLabelSelector(query *gorm.DB, kind, idColumn string, requirements []LabelRequirement) *gorm.DB {
+	exists := "SELECT 1 FROM infra_k8s_label l WHERE l.kind = ? AND l.object_id = " + idColumn + " AND l.label_key = ?"
+	for _, req := range requirements {
+		switch req.Operator {
+		case LabelOpIn:
+			query = query.Where("EXISTS ("+exists+" AND l.label_value IN ?)", kind, req.Key, req.Values)
+		case LabelOpNotIn:
+			query = query.Where("NOT EXISTS ("+exists+" AND l.label_value IN ?)", kind, req.Key, req.Values)
+		case LabelOpExists:
+			query = query.Where("EXISTS ("+exists+")", kind, req.Key)
+		case LabelOpDoesNotExist:
+			query = query.Where("NOT EXISTS ("+exists+")", kind, req.Key)
+		case LabelOpGreaterThan:
+			query = query.Where("EXISTS ("+exists+" AND l.label_value REGEXP ? AND CAST(l.label_value AS SIGNED) > ?)", kind, req.Key, integerLabelPattern, req.Values[0])
+		case LabelOpLessThan:
+			query = query.Where("EXISTS ("+exists+" AND l.label_value REGEXP ? AND CAST(l.label_value AS SIGNED) < ?)", kind, req.Key, integerLabelPattern, req.Values[0])
+		}
+	}
+	return query
+}
+
+// labelKeywordCondition 标签的 key=value 文本包含关键字，用于全局搜索，参数依次为对象类型和 LIKE 模式
+func labelKeywordCondition(idColumn string) string {
+	return idColumn + " IN (SELECT l.object_id FROM infra_k8s_label l WHERE l.kind = ? AND CONCAT(l.label_key, '=', l.label_value) LIKE ?)"
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
 	"fmt"
 	"strings"
 	"time"
@@ -13,8 +15,11 @@ import (
 type K8sNodeHistoryRepository interface {
 	// Node历史操作
 	ArchiveNodesNotInList(configID int64, currentNodes []model.K8sNode, reason string) error
-	GetNodeHistory(configID int64, page, pageSize int, startTime, endTime *time.Time) ([]model.K8sNodeHistory, int64, error)
-	CleanupNodeHistory(beforeDate time.Time) error
+	GetNodeHistory(configID int64, q *listquery.Query, startTime, endTime *time.Time) (*listquery.Result[model.K8sNodeHistory], error)
+	EachNodeHistory(configID int64, startTime, endTime time.Time, limit, batchSize int, fn func([]model.K8sNodeHistory) error) error
+	CleanupNodeHistory(ctx context.Context, configID int64, beforeDate time.Time, opts BatchDeleteOptions) (int64, error)
+	EachNodeHistoryForArchive(ctx context.Context, configID int64, beforeDate time.Time, batchSize int, fn func([]model.K8sNodeHistory) error) error
+	RestoreNodeHistory(ctx context.Context, configID int64, rows []model.K8sNodeHistory) (int64, error)
 	CountNodeHistory(configID int64) (int64, error)
 
 	// 事务支持
@@ -95,10 +100,7 @@ func (r *k8sNodeHistoryRepository) ArchiveNodesNotInList(configID int64, current
 }
 
 // GetNodeHistory 获取Node历史记录
-func (r *k8sNodeHistoryRepository) GetNodeHistory(configID int64, page, pageSize int, startTime, endTime *time.Time) ([]model.K8sNodeHistory, int64, error) {
-	var histories []model.K8sNodeHistory
-	var total int64
-
+func (r *k8sNodeHistoryRepository) GetNodeHistory(configID int64, q *listquery.Query, startTime, endTime *time.Time) (*listquery.Result[model.K8sNodeHistory], error) {
 	query := r.db.Model(&model.K8sNodeHistory{}).Where("config_id = ?", configID)
 
 	if startTime != nil {
@@ -108,23 +110,39 @@ func (r *k8sNodeHistoryRepository) GetNodeHistory(configID int64, page, pageSize
 		query = query.Where("archived_at <= ?", *endTime)
 	}
 
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
+	return listquery.Find[model.K8sNodeHistory](query, q)
+}
 
-	// 分页查询
-	offset := (page - 1) * pageSize
-	if err := query.Order("archived_at DESC").Offset(offset).Limit(pageSize).Find(&histories).Error; err != nil {
-		return nil, 0, err
-	}
+// EachNodeHistory 按ID升序分批遍历归档时间在 [startTime, endTime] 内的Node历史记录，最多遍历 limit 条，用于导出
+func (r *k8sNodeHistoryRepository) EachNodeHistory(configID int64, startTime, endTime time.Time, limit, batchSize int, fn func([]model.K8sNodeHistory) error) error {
+	return eachInBatches(func() *gorm.DB {
+		return r.db.Model(&model.K8sNodeHistory{}).
+			Where("config_id = ? AND archived_at >= ? AND archived_at <= ?", configID, startTime, endTime)
+	}, "id", limit, batchSize, func(history *model.K8sNodeHistory) int64 { return int64(history.ID) }, fn)
+}
+
+// CleanupNodeHistory 分批清理Node历史记录，configID 为 0 时清理全部集群，返回删除的行数
+func (r *k8sNodeHistoryRepository) CleanupNodeHistory(ctx context.Context, configID int64, beforeDate time.Time, opts BatchDeleteOptions) (int64, error) {
+	return deleteHistoryInBatches(ctx, r.db, model.K8sNodeHistory{}.TableName(), configID, beforeDate, opts)
+}
+
+// EachNodeHistoryForArchive 分批读取待归档的Node历史数据
+func (r *k8sNodeHistoryRepository) EachNodeHistoryForArchive(ctx context.Context, configID int64, beforeDate time.Time, batchSize int, fn func([]model.K8sNodeHistory) error) error {
+	return eachHistoryForArchive(ctx, r.db, configID, beforeDate, batchSize, nodeHistoryKey, fn)
+}
 
-	return histories, total, nil
+// RestoreNodeHistory 从归档数据恢复Node历史记录
+func (r *k8sNodeHistoryRepository) RestoreNodeHistory(ctx context.Context, configID int64, rows []model.K8sNodeHistory) (int64, error) {
+	return restoreHistory(ctx, r.db, model.K8sNodeHistory{}.TableName(), configID, rows, nodeHistoryKey,
+		func(h *model.K8sNodeHistory, restoredAt time.Time) {
+			h.ID = 0
+			h.ConfigID = configID
+			h.RestoredAt = &restoredAt
+		})
 }
 
-// CleanupNodeHistory 清理Node历史记录
-func (r *k8sNodeHistoryRepository) CleanupNodeHistory(beforeDate time.Time) error {
-	return r.db.Where("archived_at < ?", beforeDate).Delete(&model.K8sNodeHistory{}).Error
+func nodeHistoryKey(h *model.K8sNodeHistory) historyRowKey {
+	return historyRowKey{ID: h.ID, OriginalID: h.OriginalID, ArchivedAt: h.ArchivedAt}
 }
 
 // CountNodeHistory 统计Node历史记录数量
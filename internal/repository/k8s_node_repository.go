@@ -2,6 +2,7 @@ package repository
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
 	"fmt"
 	"strings"
 
@@ -15,10 +16,14 @@ type K8sNodeRepository interface {
 	Delete(id int64) error
 	GetByID(id int64) (*model.K8sNode, error)
 	GetByConfigAndName(configID int64, name string) (*model.K8sNode, error)
-	List(page, pageSize int, configID int64, name, internalIP, status string, ready *bool) (int64, []model.K8sNode, error)
+	List(q *listquery.Query, configID int64, name, internalIP, status string, ready *bool, labelSelector []LabelRequirement, scope *model.DataScope) (*listquery.Result[model.K8sNode], error)
+	Each(filter *K8sNodeFilter, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sNode) error) error
+	ListByConfigID(configID int64) ([]model.K8sNode, error)
 	DeleteByConfigID(configID int64) error
 	DeleteNotInList(configID int64, currentNodes []model.K8sNode) error
 	BatchCreateOrUpdate(nodes []model.K8sNode) error
+	FindByIP(ip string) ([]model.K8sNode, error)
+	Search(keyword string, limit int, scope *model.DataScope) ([]model.K8sNode, error)
 	// 事务支持
 	WithTx(tx *gorm.DB) K8sNodeRepository
 	Transaction(fn func(K8sNodeRepository) error) error
@@ -69,41 +74,55 @@ func (r *k8sNodeRepository) GetByConfigAndName(configID int64, name string) (*mo
 	return &node, nil
 }
 
+// K8sNodeFilter 节点列表的筛选条件，零值表示不限制
+type K8sNodeFilter struct {
+	ConfigID      int64
+	Name          string // 名称模糊匹配
+	InternalIP    string // 内网IP模糊匹配
+	Status        string
+	Ready         *bool
+	LabelSelector []LabelRequirement
+}
+
 // List 获取节点列表
-func (r *k8sNodeRepository) List(page, pageSize int, configID int64, name, internalIP, status string, ready *bool) (int64, []model.K8sNode, error) {
-	var nodes []model.K8sNode
-	var total int64
+func (r *k8sNodeRepository) List(q *listquery.Query, configID int64, name, internalIP, status string, ready *bool, labelSelector []LabelRequirement, scope *model.DataScope) (*listquery.Result[model.K8sNode], error) {
+	query := applyK8sNodeFilter(r.db.Model(&model.K8sNode{}), &K8sNodeFilter{
+		ConfigID:      configID,
+		Name:          name,
+		InternalIP:    internalIP,
+		Status:        status,
+		Ready:         ready,
+		LabelSelector: labelSelector,
+	}, scope)
+	return listquery.Find[model.K8sNode](query, q)
+}
 
-	query := r.db.Model(&model.K8sNode{})
+// Each 按ID升序分批遍历符合条件的节点，最多遍历 limit 条，用于导出
+func (r *k8sNodeRepository) Each(filter *K8sNodeFilter, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sNode) error) error {
+	return eachInBatches(func() *gorm.DB {
+		return applyK8sNodeFilter(r.db.Model(&model.K8sNode{}), filter, scope)
+	}, "infra_k8s_node.id", limit, batchSize, func(node *model.K8sNode) int64 { return node.ID }, fn)
+}
 
-	if configID > 0 {
-		query = query.Where("config_id = ?", configID)
+// applyK8sNodeFilter 应用节点筛选条件和数据范围
+func applyK8sNodeFilter(query *gorm.DB, filter *K8sNodeFilter, scope *model.DataScope) *gorm.DB {
+	if filter.ConfigID > 0 {
+		query = query.Where("config_id = ?", filter.ConfigID)
 	}
-	if name != "" {
-		query = query.Where("name LIKE ?", "%"+name+"%")
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
 	}
-	if internalIP != "" {
-		query = query.Where("internal_ip LIKE ?", "%"+internalIP+"%")
+	if filter.InternalIP != "" {
+		query = query.Where("internal_ip LIKE ?", "%"+filter.InternalIP+"%")
 	}
-	if status != "" {
-		query = query.Where("status = ?", status)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
 	}
-	if ready != nil {
-		query = query.Where("ready = ?", *ready)
+	if filter.Ready != nil {
+		query = query.Where("ready = ?", *filter.Ready)
 	}
-
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return 0, nil, err
-	}
-
-	// 分页查询
-	offset := (page - 1) * pageSize
-	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&nodes).Error; err != nil {
-		return 0, nil, err
-	}
-
-	return total, nodes, nil
+	query = applyLabelSelector(query, model.K8sHistoryKindNode, "infra_k8s_node.id", filter.LabelSelector)
+	return applyClusterScope(query, scope, "config_id", "")
 }
 
 // DeleteByConfigID 根据配置ID删除所有节点
@@ -111,6 +130,13 @@ func (r *k8sNodeRepository) DeleteByConfigID(configID int64) error {
 	return r.db.Where("config_id = ?", configID).Delete(&model.K8sNode{}).Error
 }
 
+// ListByConfigID 根据配置ID获取所有节点
+func (r *k8sNodeRepository) ListByConfigID(configID int64) ([]model.K8sNode, error) {
+	var nodes []model.K8sNode
+	err := r.db.Where("config_id = ?", configID).Find(&nodes).Error
+	return nodes, err
+}
+
 // BatchCreateOrUpdate 批量创建或更新节点
 func (r *k8sNodeRepository) BatchCreateOrUpdate(nodes []model.K8sNode) error {
 	if len(nodes) == 0 {
@@ -193,3 +219,22 @@ func (r *k8sNodeRepository) Transaction(fn func(K8sNodeRepository) error) error
 		return fn(r.WithTx(tx))
 	})
 }
+
+// FindByIP 根据内部IP或外部IP查找节点
+func (r *k8sNodeRepository) FindByIP(ip string) ([]model.K8sNode, error) {
+	var nodes []model.K8sNode
+	err := r.db.Where("deleted_at IS NULL AND (internal_ip = ? OR external_ip = ?)", ip, ip).Find(&nodes).Error
+	return nodes, err
+}
+
+// Search 按名称、主机名、IP和标签搜索节点
+func (r *k8sNodeRepository) Search(keyword string, limit int, scope *model.DataScope) ([]model.K8sNode, error) {
+	var nodes []model.K8sNode
+	pattern := "%" + keyword + "%"
+	query := r.db.Model(&model.K8sNode{}).
+		Where("name LIKE ? OR hostname LIKE ? OR internal_ip LIKE ? OR external_ip LIKE ? OR "+labelKeywordCondition("infra_k8s_node.id"),
+			pattern, pattern, pattern, pattern, model.K8sHistoryKindNode, pattern)
+	err := applyClusterScope(query, scope, "config_id", "").
+		Order("config_id, name").Limit(limit).Find(&nodes).Error
+	return nodes, err
+}
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
 	"fmt"
 	"strings"
 	"time"
@@ -13,9 +15,13 @@ import (
 type K8sPodHistoryRepository interface {
 	// Pod历史操作
 	ArchivePodsNotInList(configID int64, currentPods []model.K8sPod, reason string) error
-	GetPodHistory(configID int64, page, pageSize int, startTime, endTime *time.Time) ([]model.K8sPodHistory, int64, error)
-	CleanupPodHistory(beforeDate time.Time) error
+	GetPodHistory(configID int64, q *listquery.Query, startTime, endTime *time.Time, scope *model.DataScope) (*listquery.Result[model.K8sPodHistory], error)
+	EachPodHistory(configID int64, startTime, endTime time.Time, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sPodHistory) error) error
+	CleanupPodHistory(ctx context.Context, configID int64, beforeDate time.Time, opts BatchDeleteOptions) (int64, error)
+	EachPodHistoryForArchive(ctx context.Context, configID int64, beforeDate time.Time, batchSize int, fn func([]model.K8sPodHistory) error) error
+	RestorePodHistory(ctx context.Context, configID int64, rows []model.K8sPodHistory) (int64, error)
 	CountPodHistory(configID int64) (int64, error)
+	FindByIP(ip string, limit int) ([]model.K8sPodHistory, error)
 
 	// 事务支持
 	WithTx(tx *gorm.DB) K8sPodHistoryRepository
@@ -87,11 +93,9 @@ func (r *k8sPodHistoryRepository) ArchivePodsNotInList(configID int64, currentPo
 }
 
 // GetPodHistory 获取Pod历史记录
-func (r *k8sPodHistoryRepository) GetPodHistory(configID int64, page, pageSize int, startTime, endTime *time.Time) ([]model.K8sPodHistory, int64, error) {
-	var histories []model.K8sPodHistory
-	var total int64
-
+func (r *k8sPodHistoryRepository) GetPodHistory(configID int64, q *listquery.Query, startTime, endTime *time.Time, scope *model.DataScope) (*listquery.Result[model.K8sPodHistory], error) {
 	query := r.db.Model(&model.K8sPodHistory{}).Where("config_id = ?", configID)
+	query = applyClusterScope(query, scope, "config_id", "namespace")
 
 	if startTime != nil {
 		query = query.Where("archived_at >= ?", *startTime)
@@ -100,23 +104,40 @@ func (r *k8sPodHistoryRepository) GetPodHistory(configID int64, page, pageSize i
 		query = query.Where("archived_at <= ?", *endTime)
 	}
 
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
+	return listquery.Find[model.K8sPodHistory](query, q)
+}
 
-	// 分页查询
-	offset := (page - 1) * pageSize
-	if err := query.Order("archived_at DESC").Offset(offset).Limit(pageSize).Find(&histories).Error; err != nil {
-		return nil, 0, err
-	}
+// EachPodHistory 按ID升序分批遍历归档时间在 [startTime, endTime] 内的Pod历史记录，最多遍历 limit 条，用于导出
+func (r *k8sPodHistoryRepository) EachPodHistory(configID int64, startTime, endTime time.Time, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sPodHistory) error) error {
+	return eachInBatches(func() *gorm.DB {
+		query := r.db.Model(&model.K8sPodHistory{}).
+			Where("config_id = ? AND archived_at >= ? AND archived_at <= ?", configID, startTime, endTime)
+		return applyClusterScope(query, scope, "config_id", "namespace")
+	}, "id", limit, batchSize, func(history *model.K8sPodHistory) int64 { return int64(history.ID) }, fn)
+}
 
-	return histories, total, nil
+// CleanupPodHistory 分批清理Pod历史记录，configID 为 0 时清理全部集群，返回删除的行数
+func (r *k8sPodHistoryRepository) CleanupPodHistory(ctx context.Context, configID int64, beforeDate time.Time, opts BatchDeleteOptions) (int64, error) {
+	return deleteHistoryInBatches(ctx, r.db, model.K8sPodHistory{}.TableName(), configID, beforeDate, opts)
 }
 
-// CleanupPodHistory 清理Pod历史记录
-func (r *k8sPodHistoryRepository) CleanupPodHistory(beforeDate time.Time) error {
-	return r.db.Where("archived_at < ?", beforeDate).Delete(&model.K8sPodHistory{}).Error
+// EachPodHistoryForArchive 分批读取待归档的Pod历史数据
+func (r *k8sPodHistoryRepository) EachPodHistoryForArchive(ctx context.Context, configID int64, beforeDate time.Time, batchSize int, fn func([]model.K8sPodHistory) error) error {
+	return eachHistoryForArchive(ctx, r.db, configID, beforeDate, batchSize, podHistoryKey, fn)
+}
+
+// RestorePodHistory 从归档数据恢复Pod历史记录
+func (r *k8sPodHistoryRepository) RestorePodHistory(ctx context.Context, configID int64, rows []model.K8sPodHistory) (int64, error) {
+	return restoreHistory(ctx, r.db, model.K8sPodHistory{}.TableName(), configID, rows, podHistoryKey,
+		func(h *model.K8sPodHistory, restoredAt time.Time) {
+			h.ID = 0
+			h.ConfigID = configID
+			h.RestoredAt = &restoredAt
+		})
+}
+
+func podHistoryKey(h *model.K8sPodHistory) historyRowKey {
+	return historyRowKey{ID: h.ID, OriginalID: h.OriginalID, ArchivedAt: h.ArchivedAt}
 }
 
 // CountPodHistory 统计Pod历史记录数量
@@ -125,3 +146,10 @@ func (r *k8sPodHistoryRepository) CountPodHistory(configID int64) (int64, error)
 	err := r.db.Model(&model.K8sPodHistory{}).Where("config_id = ?", configID).Count(&count).Error
 	return count, err
 }
+
+// FindByIP 根据Pod IP查找最近归档的Pod
+func (r *k8sPodHistoryRepository) FindByIP(ip string, limit int) ([]model.K8sPodHistory, error) {
+	var histories []model.K8sPodHistory
+	err := r.db.Where("pod_ip = ?", ip).Order("archived_at DESC").Limit(limit).Find(&histories).Error
+	return histories, err
+}
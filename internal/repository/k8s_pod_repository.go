@@ -15,12 +15,15 @@ type K8sPodRepository interface {
 	Delete(id int64) error
 	Get(id int64) (*model.K8sPod, error)
 	List(configID int64, page, pageSize int) (int64, []model.K8sPod, error)
-	ListWithFilter(page, pageSize int, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder string, startTime, endTime *string, configId *int64) (int64, []model.K8sPod, error)
+	ListWithFilter(page, pageSize int, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder string, startTime, endTime *string, configId *int64, labelSelector []LabelRequirement, scope *model.DataScope) (int64, []model.K8sPod, error)
+	Each(filter *K8sPodFilter, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sPod) error) error
 	ListByConfigID(configID int64) ([]model.K8sPod, error)
 	DeleteByConfigID(configID int64) error
 	DeleteNotInList(configID int64, currentPods []model.K8sPod) error
+	Search(keyword string, limit int, scope *model.DataScope) ([]model.K8sPod, error)
 	BatchCreate(pods []model.K8sPod) error
 	BatchCreateOrUpdate(pods []model.K8sPod) error
+	FindByIP(ip string, limit int) ([]model.K8sPod, error)
 	// 事务支持
 	WithTx(tx *gorm.DB) K8sPodRepository
 	Transaction(fn func(K8sPodRepository) error) error
@@ -80,60 +83,108 @@ func (r *k8sPodRepository) List(configID int64, page, pageSize int) (int64, []mo
 	return total, pods, nil
 }
 
+// K8sPodFilter Pod列表的筛选条件，零值表示不限制
+type K8sPodFilter struct {
+	ConfigID      *int64
+	Name          string // 名称模糊匹配
+	Namespace     string
+	WorkloadName  string // 工作负载名称模糊匹配
+	Status        string // Error 表示非 Running 的异常状态
+	InstanceIP    string // 实例IP模糊匹配
+	StartTime     *string
+	EndTime       *string
+	LabelSelector []LabelRequirement
+}
+
 // ListWithFilter 获取Pod列表（支持筛选）
-func (r *k8sPodRepository) ListWithFilter(page, pageSize int, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder string, startTime, endTime *string, configId *int64) (int64, []model.K8sPod, error) {
+func (r *k8sPodRepository) ListWithFilter(page, pageSize int, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder string, startTime, endTime *string, configId *int64, labelSelector []LabelRequirement, scope *model.DataScope) (int64, []model.K8sPod, error) {
 	var pods []model.K8sPod
 	var total int64
 
-	query := r.db.Model(&model.K8sPod{})
+	query := applyK8sPodFilter(r.db.Model(&model.K8sPod{}), &K8sPodFilter{
+		ConfigID:      configId,
+		Name:          name,
+		Namespace:     namespace,
+		WorkloadName:  workloadName,
+		Status:        status,
+		InstanceIP:    instanceIP,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		LabelSelector: labelSelector,
+	}, scope)
+
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+
+	// 构建排序条件
+	orderClause := r.buildOrderClause(sortBy, sortOrder)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order(orderClause).Find(&pods).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return total, pods, nil
+}
 
+// Each 按ID升序分批遍历符合条件的Pod，最多遍历 limit 条，用于导出
+func (r *k8sPodRepository) Each(filter *K8sPodFilter, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sPod) error) error {
+	return eachInBatches(func() *gorm.DB {
+		return applyK8sPodFilter(r.db.Model(&model.K8sPod{}), filter, scope)
+	}, "infra_k8s_pod.id", limit, batchSize, func(pod *model.K8sPod) int64 { return pod.ID }, fn)
+}
+
+// applyK8sPodFilter 应用Pod筛选条件和数据范围
+func applyK8sPodFilter(query *gorm.DB, filter *K8sPodFilter, scope *model.DataScope) *gorm.DB {
 	// 添加筛选条件
-	if configId != nil {
-		query = query.Where("config_id = ?", *configId)
+	if filter.ConfigID != nil {
+		query = query.Where("config_id = ?", *filter.ConfigID)
 	}
-	if name != "" {
-		query = query.Where("name LIKE ?", "%"+name+"%")
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
 	}
-	if namespace != "" {
-		query = query.Where("namespace = ?", namespace)
+	if filter.Namespace != "" {
+		query = query.Where("namespace = ?", filter.Namespace)
 	}
-	if workloadName != "" {
-		query = query.Where("workload_name LIKE ?", "%"+workloadName+"%")
+	if filter.WorkloadName != "" {
+		query = query.Where("workload_name LIKE ?", "%"+filter.WorkloadName+"%")
 	}
-	if status != "" {
-		if status == "Error" {
+	if filter.Status != "" {
+		if filter.Status == "Error" {
 			// 异常状态：非Running状态
 			query = query.Where("status != ?", "Running")
 		} else {
 			// 其他状态按原来的逻辑
-			query = query.Where("status = ?", status)
+			query = query.Where("status = ?", filter.Status)
 		}
 	}
-	if instanceIP != "" {
-		query = query.Where("instance_ip LIKE ?", "%"+instanceIP+"%")
+	if filter.InstanceIP != "" {
+		query = query.Where("instance_ip LIKE ?", "%"+filter.InstanceIP+"%")
 	}
+	query = applyLabelSelector(query, model.K8sHistoryKindPod, "infra_k8s_pod.id", filter.LabelSelector)
+	query = applyClusterScope(query, scope, "config_id", "namespace")
 
 	// 时间范围筛选
-	if startTime != nil && *startTime != "" {
-		query = query.Where("created_at >= ?", *startTime)
+	if filter.StartTime != nil && *filter.StartTime != "" {
+		query = query.Where("created_at >= ?", *filter.StartTime)
 	}
-	if endTime != nil && *endTime != "" {
-		query = query.Where("created_at <= ?", *endTime)
-	}
-
-	if err := query.Count(&total).Error; err != nil {
-		return 0, nil, err
-	}
-
-	// 构建排序条件
-	orderClause := r.buildOrderClause(sortBy, sortOrder)
-
-	offset := (page - 1) * pageSize
-	if err := query.Offset(offset).Limit(pageSize).Order(orderClause).Find(&pods).Error; err != nil {
-		return 0, nil, err
+	if filter.EndTime != nil && *filter.EndTime != "" {
+		query = query.Where("created_at <= ?", *filter.EndTime)
 	}
+	return query
+}
 
-	return total, pods, nil
+// Search 按名称、IP和标签搜索Pod
+func (r *k8sPodRepository) Search(keyword string, limit int, scope *model.DataScope) ([]model.K8sPod, error) {
+	var pods []model.K8sPod
+	pattern := "%" + keyword + "%"
+	query := r.db.Model(&model.K8sPod{}).
+		Where("name LIKE ? OR pod_ip LIKE ? OR host_ip LIKE ? OR instance_ip LIKE ? OR "+labelKeywordCondition("infra_k8s_pod.id"),
+			pattern, pattern, pattern, pattern, model.K8sHistoryKindPod, pattern)
+	err := applyClusterScope(query, scope, "config_id", "namespace").
+		Order("config_id, namespace, name").Limit(limit).Find(&pods).Error
+	return pods, err
 }
 
 // buildOrderClause 构建排序条件
@@ -303,3 +354,11 @@ func (r *k8sPodRepository) Transaction(fn func(K8sPodRepository) error) error {
 		return fn(r.WithTx(tx))
 	})
 }
+
+// FindByIP 根据Pod IP、主机IP或实例IP查找Pod
+func (r *k8sPodRepository) FindByIP(ip string, limit int) ([]model.K8sPod, error) {
+	var pods []model.K8sPod
+	err := r.db.Where("deleted_at IS NULL AND (pod_ip = ? OR host_ip = ? OR instance_ip = ?)", ip, ip, ip).
+		Order("updated_at DESC").Limit(limit).Find(&pods).Error
+	return pods, err
+}
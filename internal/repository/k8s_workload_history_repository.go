@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
 	"fmt"
 	"strings"
 	"time"
@@ -13,8 +15,11 @@ import (
 type K8sWorkloadHistoryRepository interface {
 	// Workload历史操作
 	ArchiveWorkloadsNotInList(configID int64, currentWorkloads []model.K8sWorkload, reason string) error
-	GetWorkloadHistory(configID int64, page, pageSize int, startTime, endTime *time.Time) ([]model.K8sWorkloadHistory, int64, error)
-	CleanupWorkloadHistory(beforeDate time.Time) error
+	GetWorkloadHistory(configID int64, q *listquery.Query, startTime, endTime *time.Time, scope *model.DataScope) (*listquery.Result[model.K8sWorkloadHistory], error)
+	EachWorkloadHistory(configID int64, startTime, endTime time.Time, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sWorkloadHistory) error) error
+	CleanupWorkloadHistory(ctx context.Context, configID int64, beforeDate time.Time, opts BatchDeleteOptions) (int64, error)
+	EachWorkloadHistoryForArchive(ctx context.Context, configID int64, beforeDate time.Time, batchSize int, fn func([]model.K8sWorkloadHistory) error) error
+	RestoreWorkloadHistory(ctx context.Context, configID int64, rows []model.K8sWorkloadHistory) (int64, error)
 	CountWorkloadHistory(configID int64) (int64, error)
 
 	// 事务支持
@@ -86,11 +91,9 @@ func (r *k8sWorkloadHistoryRepository) ArchiveWorkloadsNotInList(configID int64,
 }
 
 // GetWorkloadHistory 获取Workload历史记录
-func (r *k8sWorkloadHistoryRepository) GetWorkloadHistory(configID int64, page, pageSize int, startTime, endTime *time.Time) ([]model.K8sWorkloadHistory, int64, error) {
-	var histories []model.K8sWorkloadHistory
-	var total int64
-
+func (r *k8sWorkloadHistoryRepository) GetWorkloadHistory(configID int64, q *listquery.Query, startTime, endTime *time.Time, scope *model.DataScope) (*listquery.Result[model.K8sWorkloadHistory], error) {
 	query := r.db.Model(&model.K8sWorkloadHistory{}).Where("config_id = ?", configID)
+	query = applyClusterScope(query, scope, "config_id", "namespace")
 
 	if startTime != nil {
 		query = query.Where("archived_at >= ?", *startTime)
@@ -99,23 +102,40 @@ func (r *k8sWorkloadHistoryRepository) GetWorkloadHistory(configID int64, page,
 		query = query.Where("archived_at <= ?", *endTime)
 	}
 
-	// 获取总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
+	return listquery.Find[model.K8sWorkloadHistory](query, q)
+}
 
-	// 分页查询
-	offset := (page - 1) * pageSize
-	if err := query.Order("archived_at DESC").Offset(offset).Limit(pageSize).Find(&histories).Error; err != nil {
-		return nil, 0, err
-	}
+// EachWorkloadHistory 按ID升序分批遍历归档时间在 [startTime, endTime] 内的Workload历史记录，最多遍历 limit 条，用于导出
+func (r *k8sWorkloadHistoryRepository) EachWorkloadHistory(configID int64, startTime, endTime time.Time, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sWorkloadHistory) error) error {
+	return eachInBatches(func() *gorm.DB {
+		query := r.db.Model(&model.K8sWorkloadHistory{}).
+			Where("config_id = ? AND archived_at >= ? AND archived_at <= ?", configID, startTime, endTime)
+		return applyClusterScope(query, scope, "config_id", "namespace")
+	}, "id", limit, batchSize, func(history *model.K8sWorkloadHistory) int64 { return int64(history.ID) }, fn)
+}
+
+// CleanupWorkloadHistory 分批清理Workload历史记录，configID 为 0 时清理全部集群，返回删除的行数
+func (r *k8sWorkloadHistoryRepository) CleanupWorkloadHistory(ctx context.Context, configID int64, beforeDate time.Time, opts BatchDeleteOptions) (int64, error) {
+	return deleteHistoryInBatches(ctx, r.db, model.K8sWorkloadHistory{}.TableName(), configID, beforeDate, opts)
+}
+
+// EachWorkloadHistoryForArchive 分批读取待归档的Workload历史数据
+func (r *k8sWorkloadHistoryRepository) EachWorkloadHistoryForArchive(ctx context.Context, configID int64, beforeDate time.Time, batchSize int, fn func([]model.K8sWorkloadHistory) error) error {
+	return eachHistoryForArchive(ctx, r.db, configID, beforeDate, batchSize, workloadHistoryKey, fn)
+}
 
-	return histories, total, nil
+// RestoreWorkloadHistory 从归档数据恢复Workload历史记录
+func (r *k8sWorkloadHistoryRepository) RestoreWorkloadHistory(ctx context.Context, configID int64, rows []model.K8sWorkloadHistory) (int64, error) {
+	return restoreHistory(ctx, r.db, model.K8sWorkloadHistory{}.TableName(), configID, rows, workloadHistoryKey,
+		func(h *model.K8sWorkloadHistory, restoredAt time.Time) {
+			h.ID = 0
+			h.ConfigID = configID
+			h.RestoredAt = &restoredAt
+		})
 }
 
-// CleanupWorkloadHistory 清理Workload历史记录
-func (r *k8sWorkloadHistoryRepository) CleanupWorkloadHistory(beforeDate time.Time) error {
-	return r.db.Where("archived_at < ?", beforeDate).Delete(&model.K8sWorkloadHistory{}).Error
+func workloadHistoryKey(h *model.K8sWorkloadHistory) historyRowKey {
+	return historyRowKey{ID: h.ID, OriginalID: h.OriginalID, ArchivedAt: h.ArchivedAt}
 }
 
 // CountWorkloadHistory 统计Workload历史记录数量
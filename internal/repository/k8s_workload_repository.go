@@ -15,10 +15,12 @@ type K8sWorkloadRepository interface {
 	Delete(id int64) error
 	Get(id int64) (*model.K8sWorkload, error)
 	List(configID int64, page, pageSize int) (int64, []model.K8sWorkload, error)
-	ListWithFilter(page, pageSize int, name, namespace, workloadType, status, replicas, sortBy, sortOrder string, startTime, endTime *string, configId *int64) (int64, []model.K8sWorkload, error)
+	ListWithFilter(page, pageSize int, name, namespace, workloadType, status, replicas, sortBy, sortOrder string, startTime, endTime *string, configId *int64, labelSelector []LabelRequirement, scope *model.DataScope) (int64, []model.K8sWorkload, error)
+	Each(filter *K8sWorkloadFilter, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sWorkload) error) error
 	ListByConfigID(configID int64) ([]model.K8sWorkload, error)
 	DeleteByConfigID(configID int64) error
 	DeleteNotInList(configID int64, currentWorkloads []model.K8sWorkload) error
+	Search(keyword string, limit int, scope *model.DataScope) ([]model.K8sWorkload, error)
 	BatchCreate(workloads []model.K8sWorkload) error
 	BatchUpdate(workloads []model.K8sWorkload) error
 	BatchCreateOrUpdate(workloads []model.K8sWorkload) error
@@ -82,71 +84,108 @@ func (r *k8sWorkloadRepository) List(configID int64, page, pageSize int) (int64,
 	return total, workloads, nil
 }
 
+// K8sWorkloadFilter 工作负载列表的筛选条件，零值表示不限制
+type K8sWorkloadFilter struct {
+	ConfigID      *int64
+	Name          string // 名称模糊匹配
+	Namespace     string
+	WorkloadType  string
+	Status        string // Running 表示副本数大于0，Other 表示副本数为0
+	Replicas      string // gt0、eq0
+	StartTime     *string
+	EndTime       *string
+	LabelSelector []LabelRequirement
+}
+
 // ListWithFilter 获取工作负载列表（支持筛选）
-func (r *k8sWorkloadRepository) ListWithFilter(page, pageSize int, name, namespace, workloadType, status, replicas, sortBy, sortOrder string, startTime, endTime *string, configId *int64) (int64, []model.K8sWorkload, error) {
+func (r *k8sWorkloadRepository) ListWithFilter(page, pageSize int, name, namespace, workloadType, status, replicas, sortBy, sortOrder string, startTime, endTime *string, configId *int64, labelSelector []LabelRequirement, scope *model.DataScope) (int64, []model.K8sWorkload, error) {
 	var workloads []model.K8sWorkload
 	var total int64
 
-	query := r.db.Model(&model.K8sWorkload{})
+	query := applyK8sWorkloadFilter(r.db.Model(&model.K8sWorkload{}), &K8sWorkloadFilter{
+		ConfigID:      configId,
+		Name:          name,
+		Namespace:     namespace,
+		WorkloadType:  workloadType,
+		Status:        status,
+		Replicas:      replicas,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		LabelSelector: labelSelector,
+	}, scope)
+
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+
+	// 构建排序条件
+	orderClause := r.buildOrderClause(sortBy, sortOrder)
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order(orderClause).Find(&workloads).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return total, workloads, nil
+}
+
+// Each 按ID升序分批遍历符合条件的工作负载，最多遍历 limit 条，用于导出
+func (r *k8sWorkloadRepository) Each(filter *K8sWorkloadFilter, scope *model.DataScope, limit, batchSize int, fn func([]model.K8sWorkload) error) error {
+	return eachInBatches(func() *gorm.DB {
+		return applyK8sWorkloadFilter(r.db.Model(&model.K8sWorkload{}), filter, scope)
+	}, "infra_k8s_workload.id", limit, batchSize, func(workload *model.K8sWorkload) int64 { return workload.ID }, fn)
+}
 
+// applyK8sWorkloadFilter 应用工作负载筛选条件和数据范围
+func applyK8sWorkloadFilter(query *gorm.DB, filter *K8sWorkloadFilter, scope *model.DataScope) *gorm.DB {
 	// 添加筛选条件
-	if configId != nil {
-		query = query.Where("config_id = ?", *configId)
+	if filter.ConfigID != nil {
+		query = query.Where("config_id = ?", *filter.ConfigID)
 	}
-	if name != "" {
-		query = query.Where("name LIKE ?", "%"+name+"%")
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
 	}
-	if namespace != "" {
-		query = query.Where("namespace = ?", namespace)
+	if filter.Namespace != "" {
+		query = query.Where("namespace = ?", filter.Namespace)
 	}
-	if workloadType != "" {
-		query = query.Where("kind = ?", workloadType)
+	if filter.WorkloadType != "" {
+		query = query.Where("kind = ?", filter.WorkloadType)
 	}
-	if status != "" {
-		if status == "Running" {
+	if filter.Status != "" {
+		if filter.Status == "Running" {
 			// 运行中：replicas 大于 0
 			query = query.Where("replicas > 0")
-		} else if status == "Other" {
+		} else if filter.Status == "Other" {
 			// 闲置：replicas 等于 0
 			query = query.Where("replicas = 0")
 		} else {
 			// 其他状态按原来的逻辑
-			query = query.Where("status = ?", status)
+			query = query.Where("status = ?", filter.Status)
 		}
 	}
 
+	query = applyLabelSelector(query, model.K8sHistoryKindWorkload, "infra_k8s_workload.id", filter.LabelSelector)
+	query = applyClusterScope(query, scope, "config_id", "namespace")
+
 	// replicas过滤
-	if replicas != "" {
-		if replicas == "gt0" {
+	if filter.Replicas != "" {
+		if filter.Replicas == "gt0" {
 			// 大于0
 			query = query.Where("replicas > 0")
-		} else if replicas == "eq0" {
+		} else if filter.Replicas == "eq0" {
 			// 等于0
 			query = query.Where("replicas = 0")
 		}
 	}
 
 	// 时间范围筛选
-	if startTime != nil && *startTime != "" {
-		query = query.Where("created_at >= ?", *startTime)
-	}
-	if endTime != nil && *endTime != "" {
-		query = query.Where("created_at <= ?", *endTime)
+	if filter.StartTime != nil && *filter.StartTime != "" {
+		query = query.Where("created_at >= ?", *filter.StartTime)
 	}
-
-	if err := query.Count(&total).Error; err != nil {
-		return 0, nil, err
+	if filter.EndTime != nil && *filter.EndTime != "" {
+		query = query.Where("created_at <= ?", *filter.EndTime)
 	}
-
-	// 构建排序条件
-	orderClause := r.buildOrderClause(sortBy, sortOrder)
-
-	offset := (page - 1) * pageSize
-	if err := query.Offset(offset).Limit(pageSize).Order(orderClause).Find(&workloads).Error; err != nil {
-		return 0, nil, err
-	}
-
-	return total, workloads, nil
+	return query
 }
 
 // buildOrderClause 构建排序条件
@@ -362,3 +401,15 @@ func (r *k8sWorkloadRepository) Transaction(fn func(K8sWorkloadRepository) error
 		return fn(r.WithTx(tx))
 	})
 }
+
+// Search 按名称、镜像和标签搜索工作负载
+func (r *k8sWorkloadRepository) Search(keyword string, limit int, scope *model.DataScope) ([]model.K8sWorkload, error) {
+	var workloads []model.K8sWorkload
+	pattern := "%" + keyword + "%"
+	query := r.db.Model(&model.K8sWorkload{}).
+		Where("name LIKE ? OR images LIKE ? OR "+labelKeywordCondition("infra_k8s_workload.id"),
+			pattern, pattern, model.K8sHistoryKindWorkload, pattern)
+	err := applyClusterScope(query, scope, "config_id", "namespace").
+		Order("config_id, namespace, kind, name").Limit(limit).Find(&workloads).Error
+	return workloads, err
+}
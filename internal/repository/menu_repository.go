@@ -14,6 +14,7 @@ type MenuRepository interface {
 	FindByID(id uint) (*model.Menu, error)
 	List() ([]*model.Menu, error)
 	ListByRoleID(roleID uint) ([]*model.Menu, error)
+	ListIDsByUserID(userID uint) ([]uint, error)
 }
 
 // MenuRepositoryImpl 菜单仓储实现
@@ -61,7 +62,7 @@ func (r *MenuRepositoryImpl) FindByID(id uint) (*model.Menu, error) {
 // List 获取菜单列表
 func (r *MenuRepositoryImpl) List() ([]*model.Menu, error) {
 	var menus []*model.Menu
-	err := r.db.Order("sort").Find(&menus).Error
+	err := r.db.Order("sort_order, id").Find(&menus).Error
 	return menus, err
 }
 
@@ -70,7 +71,19 @@ func (r *MenuRepositoryImpl) ListByRoleID(roleID uint) ([]*model.Menu, error) {
 	var menus []*model.Menu
 	err := r.db.Joins("JOIN sys_role_menu ON sys_role_menu.menu_id = sys_menu.id").
 		Where("sys_role_menu.role_id = ?", roleID).
-		Order("sort").
+		Order("sys_menu.sort_order, sys_menu.id").
 		Find(&menus).Error
 	return menus, err
 }
+
+// ListIDsByUserID 获取用户通过已启用角色获得的菜单ID，多个角色的菜单合并去重
+func (r *MenuRepositoryImpl) ListIDsByUserID(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.RoleMenu{}).
+		Distinct("sys_role_menu.menu_id").
+		Joins("JOIN sys_role ON sys_role.id = sys_role_menu.role_id AND sys_role.status = 1 AND sys_role.deleted_at IS NULL").
+		Joins("JOIN sys_user_role ON sys_user_role.role_id = sys_role.id").
+		Where("sys_user_role.user_id = ?", userID).
+		Pluck("sys_role_menu.menu_id", &ids).Error
+	return ids, err
+}
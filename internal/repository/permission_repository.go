@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// PermissionRepository 权限仓储接口
+type PermissionRepository interface {
+	ListAPIPermissions() ([]*model.APIPermission, error)
+	ListUserPerms(userID uint) ([]string, error)
+	ListUserRoles(userID uint) ([]*model.Role, error)
+	ListRoleDataScopes(roleIDs []uint) ([]*model.RoleDataScope, error)
+	GetUserStatus(userID uint) (int, error)
+}
+
+// PermissionRepositoryImpl 权限仓储实现
+type PermissionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPermissionRepository 创建权限仓储实例
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &PermissionRepositoryImpl{db: db}
+}
+
+// ListAPIPermissions 获取全部接口权限映射
+func (r *PermissionRepositoryImpl) ListAPIPermissions() ([]*model.APIPermission, error) {
+	var permissions []*model.APIPermission
+	err := r.db.Order("path, method").Find(&permissions).Error
+	return permissions, err
+}
+
+// ListUserPerms 获取用户通过已启用角色获得的权限标识，忽略已禁用的菜单和按钮
+func (r *PermissionRepositoryImpl) ListUserPerms(userID uint) ([]string, error) {
+	var perms []string
+	err := r.db.Model(&model.Menu{}).
+		Distinct("sys_menu.perms").
+		Joins("JOIN sys_role_menu ON sys_role_menu.menu_id = sys_menu.id").
+		Joins("JOIN sys_role ON sys_role.id = sys_role_menu.role_id AND sys_role.status = 1 AND sys_role.deleted_at IS NULL").
+		Joins("JOIN sys_user_role ON sys_user_role.role_id = sys_role.id").
+		Where("sys_user_role.user_id = ? AND sys_menu.status = 1 AND sys_menu.perms IS NOT NULL AND sys_menu.perms <> ''", userID).
+		Pluck("sys_menu.perms", &perms).Error
+	return perms, err
+}
+
+// ListUserRoles 获取用户已启用的角色
+func (r *PermissionRepositoryImpl) ListUserRoles(userID uint) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Select("sys_role.id", "sys_role.code", "sys_role.data_scope").
+		Joins("JOIN sys_user_role ON sys_user_role.role_id = sys_role.id").
+		Where("sys_user_role.user_id = ? AND sys_role.status = 1", userID).
+		Find(&roles).Error
+	return roles, err
+}
+
+// ListRoleDataScopes 获取角色的数据范围规则
+func (r *PermissionRepositoryImpl) ListRoleDataScopes(roleIDs []uint) ([]*model.RoleDataScope, error) {
+	var rules []*model.RoleDataScope
+	if len(roleIDs) == 0 {
+		return rules, nil
+	}
+	err := r.db.Where("role_id IN ?", roleIDs).Find(&rules).Error
+	return rules, err
+}
+
+// GetUserStatus 获取用户状态
+func (r *PermissionRepositoryImpl) GetUserStatus(userID uint) (int, error) {
+	var user model.User
+	if err := r.db.Select("id", "status").First(&user, userID).Error; err != nil {
+		return 0, err
+	}
+	return user.Status, nil
+}
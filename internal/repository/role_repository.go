@@ -12,9 +12,15 @@ type RoleRepository interface {
 	Update(role *model.Role) error
 	Delete(id uint) error
 	FindByID(id uint) (*model.Role, error)
+	FindByCodes(codes []string) ([]*model.Role, error)
 	List(page, pageSize int) ([]*model.Role, int64, error)
 	AssignMenus(roleID uint, menuIDs []uint) error
 	AssignUserRoles(userID uint, roleIDs []uint) error
+	ListDataScopes(roleID uint) ([]*model.RoleDataScope, error)
+	AssignDataScopes(roleID uint, dataScope string, rules []*model.RoleDataScope) error
+	ListAll() ([]*model.Role, error)
+	FindByIDs(ids []uint) ([]*model.Role, error)
+	Clone(sourceID uint, role *model.Role) error
 }
 
 // RoleRepositoryImpl 角色仓储实现
@@ -48,6 +54,10 @@ func (r *RoleRepositoryImpl) Delete(id uint) error {
 		if err := tx.Where("role_id = ?", id).Delete(&model.UserRole{}).Error; err != nil {
 			return err
 		}
+		// 删除角色数据范围
+		if err := tx.Where("role_id = ?", id).Delete(&model.RoleDataScope{}).Error; err != nil {
+			return err
+		}
 		// 删除角色
 		return tx.Delete(&model.Role{}, id).Error
 	})
@@ -63,6 +73,16 @@ func (r *RoleRepositoryImpl) FindByID(id uint) (*model.Role, error) {
 	return &role, nil
 }
 
+// FindByCodes 根据编码查找已启用的角色
+func (r *RoleRepositoryImpl) FindByCodes(codes []string) ([]*model.Role, error) {
+	var roles []*model.Role
+	if len(codes) == 0 {
+		return roles, nil
+	}
+	err := r.db.Where("code IN ? AND status = 1", codes).Find(&roles).Error
+	return roles, err
+}
+
 // List 获取角色列表
 func (r *RoleRepositoryImpl) List(page, pageSize int) ([]*model.Role, int64, error) {
 	var roles []*model.Role
@@ -122,3 +142,82 @@ func (r *RoleRepositoryImpl) AssignUserRoles(userID uint, roleIDs []uint) error
 		return tx.Create(&userRoles).Error
 	})
 }
+
+// ListDataScopes 获取角色数据范围规则
+func (r *RoleRepositoryImpl) ListDataScopes(roleID uint) ([]*model.RoleDataScope, error) {
+	var rules []*model.RoleDataScope
+	err := r.db.Where("role_id = ?", roleID).Order("scope_type, resource_id, id").Find(&rules).Error
+	return rules, err
+}
+
+// AssignDataScopes 设置角色数据范围及规则
+func (r *RoleRepositoryImpl) AssignDataScopes(roleID uint, dataScope string, rules []*model.RoleDataScope) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Role{}).Where("id = ?", roleID).Update("data_scope", dataScope).Error; err != nil {
+			return err
+		}
+
+		// 删除原有的数据范围规则
+		if err := tx.Where("role_id = ?", roleID).Delete(&model.RoleDataScope{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+
+		for _, rule := range rules {
+			rule.ID = 0
+			rule.RoleID = roleID
+		}
+		return tx.Create(&rules).Error
+	})
+}
+
+// ListAll 获取全部角色及其菜单
+func (r *RoleRepositoryImpl) ListAll() ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Preload("Menus").Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// FindByIDs 根据ID查找角色
+func (r *RoleRepositoryImpl) FindByIDs(ids []uint) ([]*model.Role, error) {
+	var roles []*model.Role
+	if len(ids) == 0 {
+		return roles, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&roles).Error
+	return roles, err
+}
+
+// Clone 以 sourceID 角色为模板创建新角色，复制菜单权限、数据范围及规则
+func (r *RoleRepositoryImpl) Clone(sourceID uint, role *model.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var source model.Role
+		if err := tx.First(&source, sourceID).Error; err != nil {
+			return err
+		}
+		role.DataScope = source.DataScope
+		if err := tx.Omit("Menus", "Users").Create(role).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("INSERT INTO sys_role_menu (role_id, menu_id) SELECT ?, menu_id FROM sys_role_menu WHERE role_id = ?",
+			role.ID, sourceID).Error; err != nil {
+			return err
+		}
+
+		var rules []*model.RoleDataScope
+		if err := tx.Where("role_id = ?", sourceID).Find(&rules).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		for _, rule := range rules {
+			rule.ID = 0
+			rule.RoleID = role.ID
+		}
+		return tx.Create(&rules).Error
+	})
+}
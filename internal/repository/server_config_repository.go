@@ -2,6 +2,7 @@ package repository
 
 import (
 	"eden-ops/internal/model"
+	"fmt"
 
 	"gorm.io/gorm"
 )
@@ -19,14 +20,11 @@ func NewServerConfigRepository(db *gorm.DB) *ServerConfigRepository {
 }
 
 // List 获取服务器配置列表
-func (r *ServerConfigRepository) List(page, pageSize int, name string) (int64, []model.ServerConfig, error) {
+func (r *ServerConfigRepository) List(page, pageSize int, name string, scope *model.DataScope) (int64, []model.ServerConfig, error) {
 	var total int64
 	var configs []model.ServerConfig
 
-	query := r.db.Model(&model.ServerConfig{})
-	if name != "" {
-		query = query.Where("name LIKE ?", "%"+name+"%")
-	}
+	query := r.filter(name, scope)
 
 	err := query.Count(&total).Error
 	if err != nil {
@@ -42,6 +40,22 @@ func (r *ServerConfigRepository) List(page, pageSize int, name string) (int64, [
 	return total, configs, nil
 }
 
+// Each 按ID升序分批遍历符合条件的服务器配置，最多遍历 limit 条，用于导出
+func (r *ServerConfigRepository) Each(name string, scope *model.DataScope, limit, batchSize int, fn func([]model.ServerConfig) error) error {
+	return eachInBatches(func() *gorm.DB {
+		return r.filter(name, scope)
+	}, "id", limit, batchSize, func(config *model.ServerConfig) int64 { return int64(config.ID) }, fn)
+}
+
+// filter 按名称和数据范围过滤服务器配置
+func (r *ServerConfigRepository) filter(name string, scope *model.DataScope) *gorm.DB {
+	query := r.db.Model(&model.ServerConfig{})
+	if name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+	return applyIDScope(query, scope, "id", scope.ServerIDs())
+}
+
 // Create 创建服务器配置
 func (r *ServerConfigRepository) Create(config *model.ServerConfig) error {
 	return r.db.Create(config).Error
@@ -62,7 +76,28 @@ func (r *ServerConfigRepository) Update(config *model.ServerConfig) error {
 	return r.db.Save(config).Error
 }
 
+// TrustHostKey 登记首次连接时的主机公钥指纹，已登记指纹的服务器不会被覆盖
+func (r *ServerConfigRepository) TrustHostKey(id uint, fingerprint string) error {
+	result := r.db.Model(&model.ServerConfig{}).
+		Where("id = ? AND (host_key_fingerprint IS NULL OR host_key_fingerprint = '')", id).
+		Update("host_key_fingerprint", fingerprint)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("服务器 %d 已登记主机公钥指纹", id)
+	}
+	return nil
+}
+
 // Delete 删除服务器配置
 func (r *ServerConfigRepository) Delete(id uint) error {
 	return r.db.Delete(&model.ServerConfig{}, id).Error
 }
+
+// FindByHost 根据主机地址查找配置
+func (r *ServerConfigRepository) FindByHost(host string) ([]model.ServerConfig, error) {
+	var configs []model.ServerConfig
+	err := r.db.Where("host = ?", host).Find(&configs).Error
+	return configs, err
+}
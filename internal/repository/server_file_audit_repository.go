@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ServerFileAuditRepository 服务器文件操作审计仓库接口
+type ServerFileAuditRepository interface {
+	Create(audit *model.ServerFileAudit) error
+	List(page, pageSize int, serverID, userID uint, action string) (int64, []model.ServerFileAudit, error)
+}
+
+// serverFileAuditRepository 服务器文件操作审计仓库实现
+type serverFileAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewServerFileAuditRepository 创建服务器文件操作审计仓库
+func NewServerFileAuditRepository(db *gorm.DB) ServerFileAuditRepository {
+	return &serverFileAuditRepository{db: db}
+}
+
+// Create 写入审计记录
+func (r *serverFileAuditRepository) Create(audit *model.ServerFileAudit) error {
+	return r.db.Create(audit).Error
+}
+
+// List 分页查询审计记录
+func (r *serverFileAuditRepository) List(page, pageSize int, serverID, userID uint, action string) (int64, []model.ServerFileAudit, error) {
+	var total int64
+	var audits []model.ServerFileAudit
+
+	query := r.db.Model(&model.ServerFileAudit{})
+	if serverID > 0 {
+		query = query.Where("server_id = ?", serverID)
+	}
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&audits).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return total, audits, nil
+}
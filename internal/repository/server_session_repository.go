@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ServerSessionRepository 服务器终端会话仓库接口
+type ServerSessionRepository interface {
+	Create(session *model.ServerSession) error
+	Update(session *model.ServerSession) error
+	Get(id uint) (*model.ServerSession, error)
+	List(page, pageSize int, serverID, userID uint, status string) (int64, []model.ServerSession, error)
+	CloseActive(reason string) (int64, error)
+	ListPermissions(serverID uint) ([]model.ServerPermission, error)
+	ReplacePermissions(serverID uint, userIDs, roleIDs []uint) error
+	HasPermission(serverID, userID uint, roleIDs []uint) (bool, error)
+}
+
+// serverSessionRepository 服务器终端会话仓库实现
+type serverSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewServerSessionRepository 创建服务器终端会话仓库
+func NewServerSessionRepository(db *gorm.DB) ServerSessionRepository {
+	return &serverSessionRepository{db: db}
+}
+
+// Create 创建会话记录
+func (r *serverSessionRepository) Create(session *model.ServerSession) error {
+	return r.db.Create(session).Error
+}
+
+// Update 更新会话记录
+func (r *serverSessionRepository) Update(session *model.ServerSession) error {
+	return r.db.Save(session).Error
+}
+
+// Get 获取会话记录
+func (r *serverSessionRepository) Get(id uint) (*model.ServerSession, error) {
+	var session model.ServerSession
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// List 分页查询会话记录
+func (r *serverSessionRepository) List(page, pageSize int, serverID, userID uint, status string) (int64, []model.ServerSession, error) {
+	var total int64
+	var sessions []model.ServerSession
+
+	query := r.db.Model(&model.ServerSession{})
+	if serverID > 0 {
+		query = query.Where("server_id = ?", serverID)
+	}
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("started_at DESC").Offset(offset).Limit(pageSize).Find(&sessions).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return total, sessions, nil
+}
+
+// CloseActive 将遗留的进行中会话标记为已关闭（服务重启后调用）
+func (r *serverSessionRepository) CloseActive(reason string) (int64, error) {
+	result := r.db.Model(&model.ServerSession{}).
+		Where("status = ?", model.ServerSessionStatusActive).
+		Updates(map[string]interface{}{
+			"status":       model.ServerSessionStatusClosed,
+			"close_reason": reason,
+			"ended_at":     gorm.Expr("NOW()"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListPermissions 获取服务器的终端授权列表
+func (r *serverSessionRepository) ListPermissions(serverID uint) ([]model.ServerPermission, error) {
+	var permissions []model.ServerPermission
+	err := r.db.Where("server_id = ?", serverID).Find(&permissions).Error
+	return permissions, err
+}
+
+// ReplacePermissions 覆盖服务器的终端授权
+func (r *serverSessionRepository) ReplacePermissions(serverID uint, userIDs, roleIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("server_id = ?", serverID).Delete(&model.ServerPermission{}).Error; err != nil {
+			return err
+		}
+
+		var permissions []model.ServerPermission
+		for _, userID := range userIDs {
+			permissions = append(permissions, model.ServerPermission{ServerID: serverID, UserID: userID})
+		}
+		for _, roleID := range roleIDs {
+			permissions = append(permissions, model.ServerPermission{ServerID: serverID, RoleID: roleID})
+		}
+		if len(permissions) == 0 {
+			return nil
+		}
+		return tx.Create(&permissions).Error
+	})
+}
+
+// HasPermission 判断用户（或其角色）是否被授权访问服务器终端
+func (r *serverSessionRepository) HasPermission(serverID, userID uint, roleIDs []uint) (bool, error) {
+	var count int64
+	query := r.db.Model(&model.ServerPermission{}).Where("server_id = ?", serverID)
+	if len(roleIDs) > 0 {
+		query = query.Where("(user_id = ? OR role_id IN ?)", userID, roleIDs)
+	} else {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
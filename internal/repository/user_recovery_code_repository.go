@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRecoveryCodeRepository 两步验证恢复码仓储接口
+type UserRecoveryCodeRepository interface {
+	Replace(userID uint, hashes []string) error
+	Use(userID uint, hash string) (bool, error)
+	CountUnused(userID uint) (int64, error)
+	DeleteByUser(userID uint) error
+}
+
+// UserRecoveryCodeRepositoryImpl 两步验证恢复码仓储实现
+type UserRecoveryCodeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewUserRecoveryCodeRepository 创建两步验证恢复码仓储实例
+func NewUserRecoveryCodeRepository(db *gorm.DB) UserRecoveryCodeRepository {
+	return &UserRecoveryCodeRepositoryImpl{db: db}
+}
+
+// Replace 使用新的恢复码替换用户已有的全部恢复码
+func (r *UserRecoveryCodeRepositoryImpl) Replace(userID uint, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]*model.UserRecoveryCode, 0, len(hashes))
+		for _, hash := range hashes {
+			codes = append(codes, &model.UserRecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Use 使用恢复码，恢复码不存在或已使用时返回 false
+func (r *UserRecoveryCodeRepositoryImpl) Use(userID uint, hash string) (bool, error) {
+	result := r.db.Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountUnused 统计用户未使用的恢复码数量
+func (r *UserRecoveryCodeRepositoryImpl) CountUnused(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteByUser 删除用户的全部恢复码
+func (r *UserRecoveryCodeRepositoryImpl) DeleteByUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error
+}
@@ -11,12 +11,18 @@ import (
 type UserRepository interface {
 	Create(user *model.User) error
 	Update(user *model.User) error
+	UpdateFields(id uint, fields map[string]interface{}) error
+	UpdateTOTPStep(id uint, step int64) (bool, error)
 	Delete(id uint) error
 	Get(id uint) (*model.User, error)
 	GetByUsername(username string) (*model.User, error)
 	List(page, size int) ([]*model.User, int64, error)
 	GetUserRoles(userID uint) ([]*model.Role, error)
 	AssignRoles(userID uint, roleIDs []uint) error
+	CreateWithRoles(user *model.User, roleIDs []uint) error
+	ListAll() ([]*model.User, error)
+	UpdateStatus(ids []uint, status int) (int64, error)
+	BatchAssignRoles(userIDs, roleIDs []uint, mode string) error
 }
 
 // userRepository 用户仓库实现
@@ -39,6 +45,22 @@ func (r *userRepository) Update(user *model.User) error {
 	return r.db.Save(user).Error
 }
 
+// UpdateFields 更新用户的指定字段
+func (r *userRepository) UpdateFields(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// UpdateTOTPStep 记录已使用的验证码时间步，仅当新时间步大于已记录值时更新，并发提交同一验证码时只有一次成功
+func (r *userRepository) UpdateTOTPStep(id uint, step int64) (bool, error) {
+	result := r.db.Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // Delete 删除用户
 func (r *userRepository) Delete(id uint) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
@@ -119,3 +141,79 @@ func (r *userRepository) AssignRoles(userID uint, roleIDs []uint) error {
 		return nil
 	})
 }
+
+// CreateWithRoles 在同一事务中创建用户并分配角色
+func (r *userRepository) CreateWithRoles(user *model.User, roleIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Roles").Create(user).Error; err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			if err := tx.Create(&model.UserRole{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListAll 获取全部用户及其角色
+func (r *userRepository) ListAll() ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.Preload("Roles").Order("id").Find(&users).Error
+	return users, err
+}
+
+// UpdateStatus 批量修改用户状态，返回实际修改的用户数
+func (r *userRepository) UpdateStatus(ids []uint, status int) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Model(&model.User{}).Where("id IN ? AND status <> ?", ids, status).Update("status", status)
+	return result.RowsAffected, result.Error
+}
+
+// BatchAssignRoles 批量分配用户角色，mode 为 replace、add 或 remove
+func (r *userRepository) BatchAssignRoles(userIDs, roleIDs []uint, mode string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		switch mode {
+		case model.RoleAssignReplace:
+			if err := tx.Where("user_id IN ?", userIDs).Delete(&model.UserRole{}).Error; err != nil {
+				return err
+			}
+		case model.RoleAssignRemove:
+			if len(roleIDs) == 0 {
+				return nil
+			}
+			return tx.Where("user_id IN ? AND role_id IN ?", userIDs, roleIDs).Delete(&model.UserRole{}).Error
+		}
+		if len(roleIDs) == 0 {
+			return nil
+		}
+
+		// 追加时跳过已有的关联
+		existing := make(map[[2]uint]bool)
+		if mode == model.RoleAssignAdd {
+			var current []model.UserRole
+			if err := tx.Where("user_id IN ? AND role_id IN ?", userIDs, roleIDs).Find(&current).Error; err != nil {
+				return err
+			}
+			for _, ur := range current {
+				existing[[2]uint{ur.UserID, ur.RoleID}] = true
+			}
+		}
+
+		userRoles := make([]model.UserRole, 0, len(userIDs)*len(roleIDs))
+		for _, userID := range userIDs {
+			for _, roleID := range roleIDs {
+				if !existing[[2]uint{userID, roleID}] {
+					userRoles = append(userRoles, model.UserRole{UserID: userID, RoleID: roleID})
+				}
+			}
+		}
+		if len(userRoles) == 0 {
+			return nil
+		}
+		return tx.Create(&userRoles).Error
+	})
+}
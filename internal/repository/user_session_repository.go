@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"eden-ops/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserSessionRepository 用户会话仓储接口
+type UserSessionRepository interface {
+	Create(session *model.UserSession) error
+	Get(id uint) (*model.UserSession, error)
+	GetByTokenHash(hash string) (*model.UserSession, error)
+	GetByPreviousTokenHash(hash string) (*model.UserSession, error)
+	Rotate(id uint, oldHash, newHash string) (bool, error)
+	Revoke(id uint, reason string) error
+	RevokeByUser(userID uint, reason string) (int64, error)
+	AddRevokedToken(token *model.RevokedToken) error
+	IsTokenRevoked(jti string) (bool, error)
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+// UserSessionRepositoryImpl 用户会话仓储实现
+type UserSessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewUserSessionRepository 创建用户会话仓储实例
+func NewUserSessionRepository(db *gorm.DB) UserSessionRepository {
+	return &UserSessionRepositoryImpl{db: db}
+}
+
+// Create 创建会话
+func (r *UserSessionRepositoryImpl) Create(session *model.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+// Get 获取会话
+func (r *UserSessionRepositoryImpl) Get(id uint) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByTokenHash 根据刷新令牌摘要获取会话
+func (r *UserSessionRepositoryImpl) GetByTokenHash(hash string) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := r.db.Where("token_hash = ?", hash).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByPreviousTokenHash 根据上一个刷新令牌摘要获取会话，用于识别已轮换令牌的重放
+func (r *UserSessionRepositoryImpl) GetByPreviousTokenHash(hash string) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := r.db.Where("previous_token_hash = ?", hash).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Rotate 轮换刷新令牌，仅当会话有效且令牌未被并发轮换时成功
+func (r *UserSessionRepositoryImpl) Rotate(id uint, oldHash, newHash string) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&model.UserSession{}).
+		Where("id = ? AND token_hash = ? AND revoked_at IS NULL AND expires_at > ?", id, oldHash, now).
+		Updates(map[string]interface{}{
+			"token_hash":          newHash,
+			"previous_token_hash": oldHash,
+			"last_refreshed_at":   now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Revoke 吊销会话
+func (r *UserSessionRepositoryImpl) Revoke(id uint, reason string) error {
+	return r.db.Model(&model.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at":    time.Now(),
+			"revoke_reason": reason,
+		}).Error
+}
+
+// RevokeByUser 吊销用户的全部有效会话
+func (r *UserSessionRepositoryImpl) RevokeByUser(userID uint, reason string) (int64, error) {
+	result := r.db.Model(&model.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"revoked_at":    time.Now(),
+			"revoke_reason": reason,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// AddRevokedToken 将访问令牌加入吊销列表
+func (r *UserSessionRepositoryImpl) AddRevokedToken(token *model.RevokedToken) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// IsTokenRevoked 访问令牌是否已吊销
+func (r *UserSessionRepositoryImpl) IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteExpired 删除已过期的会话和吊销记录
+func (r *UserSessionRepositoryImpl) DeleteExpired(before time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at < ?", before).Delete(&model.UserSession{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted += result.RowsAffected
+
+		result = tx.Where("expires_at < ?", before).Delete(&model.RevokedToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted += result.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
@@ -9,24 +9,57 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// publicRoutes 无需登录的公共路由（method + " " + path），不经过权限校验，新增公共路由时需同步
+var publicRoutes = map[string]bool{
+	"POST /api/v1/login":                  true,
+	"POST /api/v1/login/totp":             true,
+	"POST /api/v1/login/password":         true,
+	"POST /api/v1/refresh":                true,
+	"GET /api/v1/oidc/providers":          true,
+	"GET /api/v1/oidc/:provider/login":    true,
+	"GET /api/v1/oidc/:provider/callback": true,
+}
+
+// IsPublicRoute 判断路由（method + " " + path）是否为无需登录的公共路由
+func IsPublicRoute(route string) bool {
+	return publicRoutes[route]
+}
+
 // NewRouter 创建路由
 func NewRouter(
 	ginMode string,
 	jwtAuth *auth.JWTAuth,
+	tokenChecker middleware.TokenRevocationChecker,
+	apiTokenAuthenticator middleware.APITokenAuthenticator,
+	permissionChecker middleware.PermissionChecker,
+	dataScopeResolver middleware.DataScopeResolver,
+	auditRecorder middleware.AuditRecorder,
 	cloudAccountHandler *handler.CloudAccountHandler,
 	cloudProviderHandler *handler.CloudProviderHandler,
+	cloudResourceHandler *handler.CloudResourceHandler,
+	ipLocatorHandler *handler.IPLocatorHandler,
 	databaseConfigHandler *handler.DatabaseConfigHandler,
 	serverConfigHandler *handler.ServerConfigHandler,
+	serverTerminalHandler *handler.ServerTerminalHandler,
+	serverFileHandler *handler.ServerFileHandler,
 	k8sConfigHandler *handler.K8sConfigHandler,
 	k8sWorkloadHandler *handler.K8sWorkloadHandler,
 	k8sNamespaceHandler *handler.K8sNamespaceHandler,
 	k8sPodHandler *handler.K8sPodHandler,
 	k8sNodeHandler *handler.K8sNodeHandler,
 	k8sHistoryHandler *handler.K8sHistoryHandler,
+	k8sChangeEventHandler *handler.K8sChangeEventHandler,
+	k8sInventoryHandler *handler.K8sInventoryHandler,
+	k8sSearchHandler *handler.K8sSearchHandler,
+	k8sTopologyHandler *handler.K8sTopologyHandler,
+	k8sCapacityHandler *handler.K8sCapacityHandler,
 	userHandler *handler.UserHandler,
 	roleHandler *handler.RoleHandler,
 	menuHandler *handler.MenuHandler,
 	authHandler *handler.AuthHandler,
+	oidcHandler *handler.OIDCHandler,
+	apiTokenHandler *handler.APITokenHandler,
+	auditLogHandler *handler.AuditLogHandler,
 ) *gin.Engine {
 	// 设置GIN模式
 	if ginMode == "" {
@@ -55,38 +88,96 @@ func NewRouter(
 	{
 		// 用户认证
 		api.POST("/login", authHandler.Login)
-		api.POST("/logout", authHandler.Logout)
+		api.POST("/login/totp", authHandler.LoginTOTP)
+		api.POST("/login/password", authHandler.LoginPassword)
+		api.POST("/refresh", authHandler.Refresh)
+
+		// 单点登录
+		api.GET("/oidc/providers", oidcHandler.Providers)
+		api.GET("/oidc/:provider/login", oidcHandler.Login)
+		api.GET("/oidc/:provider/callback", oidcHandler.Callback)
 	}
 
-	// 需要认证的路由
-	auth := api.Group("/", middleware.JWT(jwtAuth))
+	// 需要认证的路由，审计中间件位于权限校验之前以记录被拒绝的操作
+	auth := api.Group("/", middleware.JWT(jwtAuth, tokenChecker, apiTokenAuthenticator), middleware.Audit(auditRecorder), middleware.Permission(permissionChecker), middleware.DataScope(dataScopeResolver))
 	{
 		// 获取当前用户信息
 		auth.GET("/users/info", userHandler.GetUserInfo)
+		auth.GET("/users/info/permissions", userHandler.GetPermissions)
+		auth.GET("/users/info/menus", menuHandler.GetUserMenus)
+
+		// 账号安全操作仅允许登录会话访问，不接受API令牌
+		interactive := auth.Group("/", middleware.InteractiveOnly())
+		{
+			// 登出
+			interactive.POST("/logout", authHandler.Logout)
+			interactive.POST("/logout/all", authHandler.LogoutAll)
+
+			interactive.PUT("/users/info/password", userHandler.ChangePassword)
+			interactive.GET("/users/info/totp", userHandler.GetTOTPStatus)
+			interactive.POST("/users/info/totp/setup", userHandler.SetupTOTP)
+			interactive.POST("/users/info/totp/enable", userHandler.EnableTOTP)
+			interactive.POST("/users/info/totp/disable", userHandler.DisableTOTP)
+			interactive.POST("/users/info/totp/recovery-codes", userHandler.RegenerateRecoveryCodes)
+
+			// 个人API令牌
+			interactive.GET("/api-tokens", apiTokenHandler.List)
+			interactive.POST("/api-tokens", apiTokenHandler.Create)
+			interactive.DELETE("/api-tokens/:id", apiTokenHandler.Revoke)
+
+			// 服务账号及用户API令牌管理
+			interactive.POST("/service-accounts", apiTokenHandler.CreateServiceAccount)
+			interactive.GET("/users/:id/api-tokens", apiTokenHandler.ListUserTokens)
+			interactive.POST("/users/:id/api-tokens", apiTokenHandler.CreateUserToken)
+			interactive.DELETE("/users/:id/api-tokens/:tokenId", apiTokenHandler.RevokeUserToken)
+		}
+
 		// 用户管理
 		auth.GET("/users", userHandler.List)
+		auth.GET("/users/export", userHandler.Export)
+		auth.GET("/users/import/template", userHandler.ImportTemplate)
+		auth.POST("/users/import", userHandler.Import)
+		auth.POST("/users/batch/status", userHandler.BatchStatus)
+		auth.POST("/users/batch/roles", userHandler.BatchRoles)
 		auth.GET("/users/:id", userHandler.Get)
 		auth.POST("/users", userHandler.Create)
 		auth.PUT("/users/:id", userHandler.Update)
 		auth.DELETE("/users/:id", userHandler.Delete)
 		auth.GET("/users/:id/roles", userHandler.GetRoles)
 		auth.PUT("/users/:id/roles", userHandler.AssignRoles)
+		auth.DELETE("/users/:id/totp", userHandler.ResetTOTP)
+		auth.POST("/users/:id/unlock", userHandler.Unlock)
 
 		// 角色管理
 		auth.GET("/roles", roleHandler.List)
+		auth.GET("/roles/export", roleHandler.Export)
 		auth.GET("/roles/:id", roleHandler.Get)
 		auth.POST("/roles", roleHandler.Create)
 		auth.PUT("/roles/:id", roleHandler.Update)
 		auth.DELETE("/roles/:id", roleHandler.Delete)
 		auth.PUT("/roles/:id/menus", roleHandler.AssignMenus)
+		auth.GET("/roles/:id/data-scopes", roleHandler.GetDataScopes)
+		auth.PUT("/roles/:id/data-scopes", roleHandler.AssignDataScopes)
+		auth.POST("/roles/:id/clone", roleHandler.Clone)
+		auth.GET("/roles/:id/diff", roleHandler.Diff)
+		auth.POST("/roles/:id/diff", roleHandler.Diff)
 
 		// 菜单管理
 		auth.GET("/menus", menuHandler.List)
+		auth.GET("/menus/export", menuHandler.Export)
 		auth.GET("/menus/:id", menuHandler.Get)
 		auth.POST("/menus", menuHandler.Create)
 		auth.PUT("/menus/:id", menuHandler.Update)
 		auth.DELETE("/menus/:id", menuHandler.Delete)
 
+		// 接口权限
+		auth.GET("/api-permissions", menuHandler.ListAPIPermissions)
+
+		// 操作审计日志
+		auth.GET("/audit-logs", auditLogHandler.List)
+		auth.GET("/audit-logs/export", auditLogHandler.Export)
+		auth.GET("/audit-logs/:id", auditLogHandler.Get)
+
 		// 云账号管理
 		auth.GET("/cloud-accounts", cloudAccountHandler.List)
 		auth.GET("/cloud-accounts/:id", cloudAccountHandler.Get)
@@ -94,9 +185,21 @@ func NewRouter(
 		auth.PUT("/cloud-accounts/:id", cloudAccountHandler.Update)
 		auth.DELETE("/cloud-accounts/:id", cloudAccountHandler.Delete)
 		auth.POST("/cloud-accounts/test", cloudAccountHandler.TestConnection)
+		auth.GET("/cloud-accounts/:id/regions", cloudAccountHandler.ListRegions)
+		auth.POST("/cloud-accounts/:id/sync", cloudResourceHandler.Sync)
+
+		// 云资源清单
+		auth.GET("/cloud-resources", cloudResourceHandler.List)
+		auth.GET("/cloud-resources/search-ip", cloudResourceHandler.SearchByIP)
+		auth.GET("/cloud-resources/history", cloudResourceHandler.GetHistory)
+		auth.GET("/cloud-resources/:id", cloudResourceHandler.Get)
+
+		// IP归属查询
+		auth.GET("/ip/locate", ipLocatorHandler.Locate)
 
 		// 数据库配置管理
 		auth.GET("/database-configs", databaseConfigHandler.List)
+		auth.GET("/database-configs/export", databaseConfigHandler.Export)
 		auth.GET("/database-configs/:id", databaseConfigHandler.Get)
 		auth.POST("/database-configs", databaseConfigHandler.Create)
 		auth.PUT("/database-configs/:id", databaseConfigHandler.Update)
@@ -105,15 +208,34 @@ func NewRouter(
 
 		// 服务器配置管理
 		auth.GET("/server-configs", serverConfigHandler.List)
+		auth.GET("/server-configs/export", serverConfigHandler.Export)
 		auth.GET("/server-configs/:id", serverConfigHandler.Get)
 		auth.POST("/server-configs", serverConfigHandler.Create)
 		auth.PUT("/server-configs/:id", serverConfigHandler.Update)
 		auth.DELETE("/server-configs/:id", serverConfigHandler.Delete)
 		auth.POST("/server-configs/test", serverConfigHandler.TestConnection)
+		auth.GET("/server-configs/:id/terminal", serverTerminalHandler.Terminal)
+		auth.GET("/server-configs/:id/permissions", serverTerminalHandler.GetPermissions)
+		auth.PUT("/server-configs/:id/permissions", serverTerminalHandler.UpdatePermissions)
+
+		// 服务器文件传输
+		auth.GET("/server-configs/:id/files", serverFileHandler.List)
+		auth.GET("/server-configs/:id/files/stat", serverFileHandler.Stat)
+		auth.GET("/server-configs/:id/files/download", serverFileHandler.Download)
+		auth.POST("/server-configs/:id/files/upload", serverFileHandler.Upload)
+		auth.POST("/server-configs/:id/files/mkdir", serverFileHandler.Mkdir)
+		auth.DELETE("/server-configs/:id/files", serverFileHandler.Delete)
+		auth.GET("/server-file-audits", serverFileHandler.ListAudits)
+
+		// 服务器终端会话
+		auth.GET("/server-sessions", serverTerminalHandler.ListSessions)
+		auth.GET("/server-sessions/:id", serverTerminalHandler.GetSession)
+		auth.GET("/server-sessions/:id/recording", serverTerminalHandler.GetRecording)
 
 		// Kubernetes配置管理
 		auth.GET("/k8s-configs", k8sConfigHandler.List)
 		auth.GET("/k8s-configs/with-workload-count", k8sConfigHandler.ListWithWorkloadCount)
+		auth.GET("/k8s-configs/export", k8sConfigHandler.Export)
 		auth.GET("/k8s-configs/:id", k8sConfigHandler.Get)
 		auth.POST("/k8s-configs", k8sConfigHandler.Create)
 		auth.PUT("/k8s-configs/:id", k8sConfigHandler.Update)
@@ -122,10 +244,12 @@ func NewRouter(
 
 		// Kubernetes工作负载管理
 		auth.GET("/k8s-workloads", k8sWorkloadHandler.List)
+		auth.GET("/k8s-workloads/export", k8sWorkloadHandler.Export)
 		auth.GET("/k8s-workloads/:id", k8sWorkloadHandler.Get)
 
 		// Kubernetes Pod管理
 		auth.GET("/k8s-pods", k8sPodHandler.List)
+		auth.GET("/k8s-pods/export", k8sPodHandler.Export)
 		auth.GET("/k8s-pods/:id", k8sPodHandler.Get)
 
 		// Kubernetes命名空间管理
@@ -133,6 +257,7 @@ func NewRouter(
 
 		// Kubernetes节点管理
 		auth.GET("/k8s-nodes", k8sNodeHandler.List)
+		auth.GET("/k8s-nodes/export", k8sNodeHandler.Export)
 		auth.GET("/k8s-nodes/:id", k8sNodeHandler.GetByID)
 		auth.DELETE("/k8s-nodes/:id", k8sNodeHandler.Delete)
 
@@ -140,8 +265,38 @@ func NewRouter(
 		auth.GET("/k8s-history/:configId/pods", k8sHistoryHandler.GetPodHistory)
 		auth.GET("/k8s-history/:configId/nodes", k8sHistoryHandler.GetNodeHistory)
 		auth.GET("/k8s-history/:configId/workloads", k8sHistoryHandler.GetWorkloadHistory)
+		auth.GET("/k8s-history/:configId/pods/export", k8sHistoryHandler.ExportPodHistory)
+		auth.GET("/k8s-history/:configId/nodes/export", k8sHistoryHandler.ExportNodeHistory)
+		auth.GET("/k8s-history/:configId/workloads/export", k8sHistoryHandler.ExportWorkloadHistory)
+		auth.GET("/k8s-history/statistics", k8sHistoryHandler.GetHistoryStatistics)
 		auth.GET("/k8s-history/:configId/statistics", k8sHistoryHandler.GetHistoryStatistics)
 		auth.POST("/k8s-history/cleanup", k8sHistoryHandler.CleanupHistory)
+		auth.GET("/k8s-history/:configId/retention", k8sHistoryHandler.GetRetention)
+		auth.GET("/k8s-history/retention-policies", k8sHistoryHandler.ListRetentionPolicies)
+		auth.POST("/k8s-history/retention-policies", k8sHistoryHandler.CreateRetentionPolicy)
+		auth.PUT("/k8s-history/retention-policies/:id", k8sHistoryHandler.UpdateRetentionPolicy)
+		auth.DELETE("/k8s-history/retention-policies/:id", k8sHistoryHandler.DeleteRetentionPolicy)
+		auth.GET("/k8s-history/archives", k8sHistoryHandler.ListArchives)
+		auth.POST("/k8s-history/restore", k8sHistoryHandler.RestoreArchive)
+
+		// Kubernetes资源变更
+		auth.GET("/k8s-changes", k8sChangeEventHandler.Recent)
+		auth.GET("/k8s-changes/timeline", k8sChangeEventHandler.Timeline)
+
+		// Kubernetes时间点清单
+		auth.GET("/k8s-inventory/:configId/pods", k8sInventoryHandler.PodsAt)
+		auth.GET("/k8s-inventory/:configId/nodes", k8sInventoryHandler.NodesAt)
+		auth.GET("/k8s-inventory/:configId/workloads", k8sInventoryHandler.WorkloadsAt)
+
+		// Kubernetes资源全局搜索
+		auth.GET("/k8s-search", k8sSearchHandler.Search)
+
+		// Kubernetes拓扑
+		auth.GET("/k8s-topology/:configId", k8sTopologyHandler.Topology)
+
+		// Kubernetes容量规划
+		auth.GET("/k8s-capacity/:configId", k8sCapacityHandler.Report)
+		auth.GET("/k8s-capacity/:configId/summaries", k8sCapacityHandler.ListSummaries)
 
 		// 基础设施路由组
 		infrastructure := auth.Group("/infrastructure")
@@ -159,9 +314,19 @@ func NewRouter(
 			infrastructure.POST("/cloud-accounts", cloudAccountHandler.Create)
 			infrastructure.PUT("/cloud-accounts/:id", cloudAccountHandler.Update)
 			infrastructure.DELETE("/cloud-accounts/:id", cloudAccountHandler.Delete)
+			infrastructure.POST("/cloud-accounts/test", cloudAccountHandler.TestConnection)
+			infrastructure.GET("/cloud-accounts/:id/regions", cloudAccountHandler.ListRegions)
+			infrastructure.POST("/cloud-accounts/:id/sync", cloudResourceHandler.Sync)
+
+			// 云资源清单
+			infrastructure.GET("/cloud-resources", cloudResourceHandler.List)
+			infrastructure.GET("/cloud-resources/search-ip", cloudResourceHandler.SearchByIP)
+			infrastructure.GET("/cloud-resources/history", cloudResourceHandler.GetHistory)
+			infrastructure.GET("/cloud-resources/:id", cloudResourceHandler.Get)
 
 			// 数据库
 			infrastructure.GET("/database", databaseConfigHandler.List)
+			infrastructure.GET("/database/export", databaseConfigHandler.Export)
 			infrastructure.GET("/database/:id", databaseConfigHandler.Get)
 			infrastructure.POST("/database", databaseConfigHandler.Create)
 			infrastructure.PUT("/database/:id", databaseConfigHandler.Update)
@@ -169,13 +334,16 @@ func NewRouter(
 
 			// 服务器
 			infrastructure.GET("/server", serverConfigHandler.List)
+			infrastructure.GET("/server/export", serverConfigHandler.Export)
 			infrastructure.GET("/server/:id", serverConfigHandler.Get)
 			infrastructure.POST("/server", serverConfigHandler.Create)
 			infrastructure.PUT("/server/:id", serverConfigHandler.Update)
 			infrastructure.DELETE("/server/:id", serverConfigHandler.Delete)
+			infrastructure.GET("/server/:id/terminal", serverTerminalHandler.Terminal)
 
 			// Kubernetes
 			infrastructure.GET("/kubernetes", k8sConfigHandler.ListWithWorkloadCount)
+			infrastructure.GET("/kubernetes/export", k8sConfigHandler.Export)
 			infrastructure.GET("/kubernetes/:id", k8sConfigHandler.Get)
 			infrastructure.POST("/kubernetes", k8sConfigHandler.Create)
 			infrastructure.PUT("/kubernetes/:id", k8sConfigHandler.Update)
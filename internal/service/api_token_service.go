@@ -0,0 +1,346 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/auth"
+	"eden-ops/pkg/config"
+	"eden-ops/pkg/logger"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// API令牌限制
+const (
+	defaultAPITokenDays    = 90
+	defaultAPITokenMaxDays = 365
+	// maxAPITokensPerUser 每个用户可同时持有的有效令牌数量
+	maxAPITokensPerUser = 50
+	// apiTokenCacheTTL 认证结果缓存时长，吊销令牌时主动失效，用户禁用最迟在该时长后生效
+	apiTokenCacheTTL = 30 * time.Second
+	// apiTokenTouchInterval 最近使用时间的最小记录间隔，避免每次请求都写数据库
+	apiTokenTouchInterval = time.Minute
+	// apiTokenDisplayLength 令牌列表中展示的令牌开头长度
+	apiTokenDisplayLength = 12
+)
+
+// ErrAPITokenNotFound 令牌不存在
+var ErrAPITokenNotFound = errors.New("令牌不存在")
+
+// CreateAPITokenRequest 创建API令牌请求
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=64"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expiresInDays"` // 有效天数，为0时使用默认值
+}
+
+// CreatedAPIToken 新创建的API令牌，令牌明文仅在创建时返回一次
+type CreatedAPIToken struct {
+	*model.APIToken
+	Token string `json:"token"`
+}
+
+// APITokenService API令牌服务接口
+type APITokenService interface {
+	Create(userID uint, req *CreateAPITokenRequest) (*CreatedAPIToken, error)
+	List(userID uint) ([]*model.APIToken, error)
+	Revoke(userID, tokenID uint) error
+	CreateServiceAccount(user *model.User, roleIDs []uint) error
+	AuthenticateAPIToken(token, clientIP string) (*auth.APITokenClaims, error)
+}
+
+// cachedAPIToken 缓存的令牌认证结果
+type cachedAPIToken struct {
+	claims    *auth.APITokenClaims
+	expiresAt *time.Time
+	cachedAt  time.Time
+	touchedAt time.Time
+}
+
+type apiTokenService struct {
+	tokenRepo         repository.APITokenRepository
+	userRepo          repository.UserRepository
+	permissionService PermissionService
+	maxDays           int
+
+	mu    sync.Mutex
+	cache map[string]*cachedAPIToken // 令牌摘要 -> 认证结果
+}
+
+// NewAPITokenService 创建API令牌服务
+func NewAPITokenService(tokenRepo repository.APITokenRepository, userRepo repository.UserRepository, permissionService PermissionService, cfg config.SecurityConfig) APITokenService {
+	maxDays := cfg.APITokenMaxDays
+	if maxDays <= 0 {
+		maxDays = defaultAPITokenMaxDays
+	}
+	return &apiTokenService{
+		tokenRepo:         tokenRepo,
+		userRepo:          userRepo,
+		permissionService: permissionService,
+		maxDays:           maxDays,
+		cache:             make(map[string]*cachedAPIToken),
+	}
+}
+
+// Create 为用户创建令牌，令牌权限不能超出用户自身拥有的权限
+func (s *apiTokenService) Create(userID uint, req *CreateAPITokenRequest) (*CreatedAPIToken, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("令牌名称不能为空")
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPITokenDays
+		if days > s.maxDays {
+			days = s.maxDays
+		}
+	}
+	if days < 0 || days > s.maxDays {
+		return nil, fmt.Errorf("令牌有效期须在1到%d天之间", s.maxDays)
+	}
+
+	user, err := s.userRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != 1 {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	scopes, err := s.validateScopes(userID, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.tokenRepo.CountActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAPITokensPerUser {
+		return nil, fmt.Errorf("每个用户最多持有%d个有效令牌", maxAPITokensPerUser)
+	}
+
+	plain, err := newAPIToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().AddDate(0, 0, days)
+	token := &model.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plain[:apiTokenDisplayLength],
+		TokenHash: hashAPIToken(plain),
+		ExpiresAt: &expiresAt,
+	}
+	token.SetScopes(scopes)
+	if err := s.tokenRepo.Create(token); err != nil {
+		return nil, err
+	}
+
+	logger.Info("创建API令牌: username=%s, userID=%d, tokenID=%d, name=%s, scopes=%s", user.Username, userID, token.ID, name, token.Scopes)
+	return &CreatedAPIToken{APIToken: token, Token: plain}, nil
+}
+
+// List 获取用户的令牌
+func (s *apiTokenService) List(userID uint) ([]*model.APIToken, error) {
+	return s.tokenRepo.ListByUser(userID)
+}
+
+// Revoke 吊销用户的令牌，立即生效
+func (s *apiTokenService) Revoke(userID, tokenID uint) error {
+	token, err := s.tokenRepo.Get(tokenID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPITokenNotFound
+		}
+		return err
+	}
+	if token.UserID != userID {
+		return ErrAPITokenNotFound
+	}
+	if err := s.tokenRepo.Revoke(tokenID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for hash, entry := range s.cache {
+		if entry.claims.TokenID == tokenID {
+			delete(s.cache, hash)
+		}
+	}
+	s.mu.Unlock()
+
+	logger.Info("吊销API令牌: userID=%d, tokenID=%d", userID, tokenID)
+	return nil
+}
+
+// CreateServiceAccount 创建服务账号，密码保存为随机密码的 bcrypt 哈希，无法登录，只能通过API令牌访问接口
+func (s *apiTokenService) CreateServiceAccount(user *model.User, roleIDs []uint) error {
+	passwordHash, err := randomPasswordHash()
+	if err != nil {
+		return err
+	}
+	user.Password = passwordHash
+	user.Source = model.UserSourceService
+	user.PasswordChangeRequired = false
+	if err := s.userRepo.Create(user); err != nil {
+		return err
+	}
+	if len(roleIDs) > 0 {
+		if err := s.userRepo.AssignRoles(user.ID, roleIDs); err != nil {
+			return err
+		}
+		s.permissionService.InvalidateAll()
+	}
+	logger.Info("创建服务账号: username=%s, userID=%d", user.Username, user.ID)
+	return nil
+}
+
+// AuthenticateAPIToken 校验API令牌，返回令牌身份信息
+func (s *apiTokenService) AuthenticateAPIToken(plain, clientIP string) (*auth.APITokenClaims, error) {
+	if !strings.HasPrefix(plain, model.APITokenPrefix) {
+		return nil, auth.ErrTokenMalformed
+	}
+	hash := hashAPIToken(plain)
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[hash]
+	if ok && (now.Sub(entry.cachedAt) > apiTokenCacheTTL || (entry.expiresAt != nil && !now.Before(*entry.expiresAt))) {
+		delete(s.cache, hash)
+		ok = false
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		var err error
+		if entry, err = s.load(hash, now); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	touch := now.Sub(entry.touchedAt) >= apiTokenTouchInterval
+	if touch {
+		entry.touchedAt = now
+	}
+	s.mu.Unlock()
+	if touch {
+		if err := s.tokenRepo.TouchLastUsed(entry.claims.TokenID, clientIP, now); err != nil {
+			logger.Warn("记录API令牌使用时间失败: tokenID=%d, %v", entry.claims.TokenID, err)
+		}
+	}
+	return entry.claims, nil
+}
+
+// load 从数据库加载令牌并校验令牌和所属用户的状态
+func (s *apiTokenService) load(hash string, now time.Time) (*cachedAPIToken, error) {
+	token, err := s.tokenRepo.GetByHash(hash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrTokenInvalid
+		}
+		return nil, err
+	}
+	if token.RevokedAt != nil {
+		return nil, auth.ErrTokenRevoked
+	}
+	if !token.Active(now) {
+		return nil, auth.ErrTokenExpired
+	}
+
+	user, err := s.userRepo.Get(token.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrTokenInvalid
+		}
+		return nil, err
+	}
+	if user.Status != 1 {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	entry := &cachedAPIToken{
+		claims: &auth.APITokenClaims{
+			TokenID:  token.ID,
+			UserID:   user.ID,
+			Username: user.Username,
+			Scopes:   token.ScopeList,
+		},
+		expiresAt: token.ExpiresAt,
+		cachedAt:  now,
+	}
+	if token.LastUsedAt != nil {
+		entry.touchedAt = *token.LastUsedAt
+	}
+
+	s.mu.Lock()
+	s.cache[hash] = entry
+	s.mu.Unlock()
+	return entry, nil
+}
+
+// validateScopes 校验令牌权限，每个权限标识都须为用户已拥有的权限；*:*:* 仅管理员可用
+func (s *apiTokenService) validateScopes(userID uint, scopes []string) ([]string, error) {
+	perms, err := s.permissionService.GetUserPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[string]struct{}, len(perms))
+	admin := false
+	for _, perm := range perms {
+		owned[perm] = struct{}{}
+		if perm == model.PermsAll {
+			admin = true
+		}
+	}
+
+	result := make([]string, 0, len(scopes))
+	seen := make(map[string]struct{}, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || strings.Contains(scope, ",") {
+			return nil, fmt.Errorf("无效的权限标识: %q", scope)
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		if _, ok := owned[scope]; !ok && !admin {
+			return nil, fmt.Errorf("不能授予未拥有的权限: %s", scope)
+		}
+		seen[scope] = struct{}{}
+		result = append(result, scope)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("请至少选择一个权限")
+	}
+	if len(strings.Join(result, ",")) > 1024 {
+		return nil, errors.New("权限数量过多，请使用 " + model.PermsAll + " 或减少权限")
+	}
+	return result, nil
+}
+
+// newAPIToken 生成API令牌，带固定前缀便于识别和密钥扫描
+func newAPIToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return model.APITokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashAPIToken 计算令牌摘要，令牌本身为高熵随机值，无需加盐
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
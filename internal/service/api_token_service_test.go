@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/config"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepo 记录创建的用户和分配的角色
+type fakeUserRepo struct {
+	repository.UserRepository
+	created  []*model.User
+	assigned map[uint][]uint
+}
+
+func (r *fakeUserRepo) Create(user *model.User) error {
+	user.ID = uint(len(r.created) + 1)
+	r.created = append(r.created, user)
+	return nil
+}
+
+func (r *fakeUserRepo) AssignRoles(userID uint, roleIDs []uint) error {
+	if r.assigned == nil {
+		r.assigned = make(map[uint][]uint)
+	}
+	r.assigned[userID] = roleIDs
+	return nil
+}
+
+func (r *fakeUserRepo) GetByUsername(username string) (*model.User, error) {
+	for _, user := range r.created {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) UpdateFields(id uint, fields map[string]interface{}) error {
+	for _, user := range r.created {
+		if user.ID == id {
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// fakePermissionService 记录权限缓存是否被清空
+type fakePermissionService struct {
+	PermissionService
+	invalidated int
+}
+
+func (s *fakePermissionService) InvalidateAll() { s.invalidated++ }
+
+func TestCreateServiceAccountStoresPasswordHash(t *testing.T) {
+	users := &fakeUserRepo{}
+	perms := &fakePermissionService{}
+	svc := NewAPITokenService(nil, users, perms, config.SecurityConfig{})
+
+	user := &model.User{Username: "ci-bot", Password: "ignored"}
+	if err := svc.CreateServiceAccount(user, []uint{3}); err != nil {
+		t.Fatalf("CreateServiceAccount() error = %v", err)
+	}
+
+	if len(users.created) != 1 {
+		t.Fatalf("created %d users, want 1", len(users.created))
+	}
+	stored := users.created[0]
+	if _, err := bcrypt.Cost([]byte(stored.Password)); err != nil {
+		t.Fatalf("stored password %q is not a bcrypt hash: %v", stored.Password, err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("ignored")) == nil {
+		t.Fatal("stored hash matches the password from the request")
+	}
+	if stored.Source != model.UserSourceService || stored.PasswordChangeRequired {
+		t.Fatalf("source = %q, passwordChangeRequired = %v", stored.Source, stored.PasswordChangeRequired)
+	}
+	if got := users.assigned[stored.ID]; len(got) != 1 || got[0] != 3 {
+		t.Fatalf("assigned roles = %v, want [3]", got)
+	}
+	if perms.invalidated != 1 {
+		t.Fatalf("permission cache invalidated %d times, want 1", perms.invalidated)
+	}
+}
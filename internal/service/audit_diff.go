@@ -0,0 +1,173 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"eden-ops/internal/model"
+)
+
+// 审计变更相关限制
+const (
+	auditRedacted = "******"
+	// auditValueMaxLength 变更中单个字符串值的最大长度
+	auditValueMaxLength = 1024
+)
+
+// auditSensitiveSuffixes 敏感字段名后缀（忽略大小写、下划线和连字符），值不记录到审计日志
+var auditSensitiveSuffixes = []string{
+	"password", "passwd", "secret", "secretkey", "accesskey", "privatekey", "passphrase",
+	"token", "apikey", "kubeconfig", "credential", "credentials", "authorization", "cookie",
+}
+
+// isAuditSensitive 判断字段是否敏感
+func isAuditSensitive(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, suffix := range auditSensitiveSuffixes {
+		if strings.HasSuffix(normalized, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSON 脱敏JSON中的敏感字段，无法解析的内容不记录原文
+func redactJSON(data []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return ""
+	}
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return ""
+	}
+	return string(redacted)
+}
+
+// redactValue 递归脱敏
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isAuditSensitive(key) {
+				result[key] = redactScalar(item)
+				continue
+			}
+			result[key] = redactValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = redactValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// redactScalar 敏感值为空时保留空值，便于区分“未设置”和“已设置”
+func redactScalar(value interface{}) interface{} {
+	if isEmptyAuditValue(value) {
+		return value
+	}
+	return auditRedacted
+}
+
+// diffAuditFields 比较资源修改前后的字段；before 为空表示新建，after 为空表示删除
+func diffAuditFields(before, after map[string]interface{}) []model.AuditChange {
+	if before == nil && after == nil {
+		return nil
+	}
+
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		if _, ignored := auditIgnoredFields[key]; !ignored {
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Strings(sorted)
+
+	changes := make([]model.AuditChange, 0)
+	for _, key := range sorted {
+		oldValue := normalizeAuditValue(before[key])
+		newValue := normalizeAuditValue(after[key])
+		// 新建或删除时只记录有值的字段
+		if (before == nil || after == nil) && isEmptyAuditValue(oldValue) && isEmptyAuditValue(newValue) {
+			continue
+		}
+		if before != nil && after != nil && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		if isAuditSensitive(key) {
+			oldValue, newValue = redactScalar(oldValue), redactScalar(newValue)
+		} else {
+			oldValue, newValue = limitAuditValue(redactValue(oldValue)), limitAuditValue(redactValue(newValue))
+		}
+		changes = append(changes, model.AuditChange{Field: key, Before: oldValue, After: newValue})
+	}
+	return changes
+}
+
+// normalizeAuditValue 关联对象只比较ID，如用户的角色列表比较角色ID
+func normalizeAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if id, ok := v["id"]; ok {
+			return id
+		}
+		return v
+	case []interface{}:
+		ids := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				return v
+			}
+			id, ok := object["id"]
+			if !ok {
+				return v
+			}
+			ids = append(ids, id)
+		}
+		return ids
+	default:
+		return value
+	}
+}
+
+// isEmptyAuditValue 是否为空值
+func isEmptyAuditValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// limitAuditValue 截断过长的字符串值
+func limitAuditValue(value interface{}) interface{} {
+	if s, ok := value.(string); ok && len(s) > auditValueMaxLength {
+		return truncateString(s, auditValueMaxLength) + "..."
+	}
+	return value
+}
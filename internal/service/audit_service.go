@@ -0,0 +1,298 @@
+package service
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 审计日志限制
+const (
+	// auditRequestMaxLength 请求参数最大保存长度
+	auditRequestMaxLength = 4096
+	// AuditExportMaxRows 单次导出的最大记录数
+	AuditExportMaxRows   = 100000
+	auditExportBatchSize = 500
+)
+
+// auditRouteOverrides 无法从路由推导出操作含义的接口，键为 method + " " + 路由模板
+var auditRouteOverrides = map[string]model.AuditTarget{
+	"POST /api/v1/logout":                                {Action: "logout", ResourceType: "sessions"},
+	"POST /api/v1/logout/all":                            {Action: "logout-all", ResourceType: "sessions"},
+	"PUT /api/v1/users/info/password":                    {Action: "change-password", ResourceType: "users", SkipRequest: true},
+	"POST /api/v1/users/info/totp/setup":                 {Action: "totp-setup", ResourceType: "users"},
+	"POST /api/v1/users/info/totp/enable":                {Action: "totp-enable", ResourceType: "users", SkipRequest: true},
+	"POST /api/v1/users/info/totp/disable":               {Action: "totp-disable", ResourceType: "users", SkipRequest: true},
+	"POST /api/v1/users/info/totp/recovery-codes":        {Action: "totp-recovery-codes", ResourceType: "users", SkipRequest: true},
+	"GET /api/v1/server-configs/:id/terminal":            {Action: "terminal", ResourceType: "server-configs", IDParam: "id"},
+	"GET /api/v1/infrastructure/server/:id/terminal":     {Action: "terminal", ResourceType: "server-configs", IDParam: "id"},
+	"GET /api/v1/server-configs/:id/files/download":      {Action: "download", ResourceType: "server-configs", IDParam: "id"},
+	"GET /api/v1/server-sessions/:id/recording":          {Action: "view-recording", ResourceType: "server-sessions", IDParam: "id"},
+	"POST /api/v1/k8s-history/cleanup":                   {Action: "cleanup", ResourceType: "k8s-history"},
+	"POST /api/v1/users/:id/api-tokens":                  {Action: "create-api-token", ResourceType: "users", IDParam: "id"},
+	"DELETE /api/v1/users/:id/api-tokens/:tokenId":       {Action: "revoke-api-token", ResourceType: "users", IDParam: "id"},
+	"DELETE /api/v1/api-tokens/:id":                      {Action: "revoke", ResourceType: "api-tokens", IDParam: "id"},
+	"DELETE /api/v1/users/:id/totp":                      {Action: "reset-totp", ResourceType: "users", IDParam: "id"},
+	"PUT /api/v1/users/:id/roles":                        {Action: "assign-roles", ResourceType: "users", IDParam: "id"},
+	"PUT /api/v1/roles/:id/menus":                        {Action: "assign-menus", ResourceType: "roles", IDParam: "id"},
+	"PUT /api/v1/roles/:id/data-scopes":                  {Action: "assign-data-scopes", ResourceType: "roles", IDParam: "id"},
+	"PUT /api/v1/server-configs/:id/permissions":         {Action: "assign-permissions", ResourceType: "server-configs", IDParam: "id"},
+	"POST /api/v1/server-configs/:id/files/upload":       {Action: "upload", ResourceType: "server-configs", IDParam: "id"},
+	"POST /api/v1/server-configs/:id/files/mkdir":        {Action: "mkdir", ResourceType: "server-configs", IDParam: "id"},
+	"DELETE /api/v1/server-configs/:id/files":            {Action: "delete-file", ResourceType: "server-configs", IDParam: "id"},
+	"POST /api/v1/service-accounts":                      {Action: "create", ResourceType: "users"},
+	"GET /api/v1/users/export":                           {Action: "export", ResourceType: "users"},
+	"GET /api/v1/roles/export":                           {Action: "export", ResourceType: "roles"},
+	"GET /api/v1/menus/export":                           {Action: "export", ResourceType: "menus"},
+	"GET /api/v1/k8s-configs/export":                     {Action: "export", ResourceType: "k8s-configs"},
+	"GET /api/v1/infrastructure/kubernetes/export":       {Action: "export", ResourceType: "k8s-configs"},
+	"GET /api/v1/k8s-workloads/export":                   {Action: "export", ResourceType: "k8s-workloads"},
+	"GET /api/v1/k8s-pods/export":                        {Action: "export", ResourceType: "k8s-pods"},
+	"GET /api/v1/k8s-nodes/export":                       {Action: "export", ResourceType: "k8s-nodes"},
+	"GET /api/v1/server-configs/export":                  {Action: "export", ResourceType: "server-configs"},
+	"GET /api/v1/infrastructure/server/export":           {Action: "export", ResourceType: "server-configs"},
+	"GET /api/v1/database-configs/export":                {Action: "export", ResourceType: "database-configs"},
+	"GET /api/v1/infrastructure/database/export":         {Action: "export", ResourceType: "database-configs"},
+	"GET /api/v1/k8s-history/:configId/pods/export":      {Action: "export-pods", ResourceType: "k8s-history", IDParam: "configId"},
+	"GET /api/v1/k8s-history/:configId/nodes/export":     {Action: "export-nodes", ResourceType: "k8s-history", IDParam: "configId"},
+	"GET /api/v1/k8s-history/:configId/workloads/export": {Action: "export-workloads", ResourceType: "k8s-history", IDParam: "configId"},
+}
+
+// auditResourceAliases 基础设施路由组下的资源与独立路由的资源类型保持一致
+var auditResourceAliases = map[string]string{
+	"kubernetes": "k8s-configs",
+	"server":     "server-configs",
+	"database":   "database-configs",
+}
+
+// auditIgnoredFields 不参与变更比较的字段
+var auditIgnoredFields = map[string]struct{}{
+	"created_at": {}, "createdAt": {}, "updated_at": {}, "updatedAt": {},
+	"deleted_at": {}, "deletedAt": {},
+}
+
+// auditNameFields 用于识别资源名称的字段，按优先级排列
+var auditNameFields = []string{"name", "username", "title", "host"}
+
+// AuditSnapshotLoader 加载资源当前状态
+type AuditSnapshotLoader func(id uint) (interface{}, error)
+
+// AuditService 操作审计服务接口
+type AuditService interface {
+	RegisterSnapshot(resourceType string, loader AuditSnapshotLoader)
+	Target(method, route string) (*model.AuditTarget, bool)
+	Snapshot(resourceType, resourceID string) interface{}
+	Record(entry *model.AuditLog, request []byte, before interface{}, response json.RawMessage)
+	Get(id uint) (*model.AuditLog, error)
+	List(q *listquery.Query, filter *repository.AuditLogFilter) (*listquery.Result[*model.AuditLog], error)
+	Export(filter *repository.AuditLogFilter, fn func([]*model.AuditLog) error) error
+}
+
+type auditService struct {
+	repo repository.AuditLogRepository
+
+	mu      sync.RWMutex
+	loaders map[string]AuditSnapshotLoader
+}
+
+// NewAuditService 创建操作审计服务
+func NewAuditService(repo repository.AuditLogRepository) AuditService {
+	return &auditService{
+		repo:    repo,
+		loaders: make(map[string]AuditSnapshotLoader),
+	}
+}
+
+// RegisterSnapshot 注册资源状态加载函数，注册后该类资源的修改和删除会记录字段变更
+func (s *auditService) RegisterSnapshot(resourceType string, loader AuditSnapshotLoader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loaders[resourceType] = loader
+}
+
+// Target 判断接口是否需要审计；所有写操作都会审计，读操作仅审计终端、文件下载等敏感操作
+func (s *auditService) Target(method, route string) (*model.AuditTarget, bool) {
+	if route == "" {
+		return nil, false
+	}
+	if target, ok := auditRouteOverrides[method+" "+route]; ok {
+		return &target, true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil, false
+	}
+	return deriveAuditTarget(method, route), true
+}
+
+// Snapshot 获取资源当前状态，未注册或加载失败时返回 nil
+func (s *auditService) Snapshot(resourceType, resourceID string) interface{} {
+	s.mu.RLock()
+	loader, ok := s.loaders[resourceType]
+	s.mu.RUnlock()
+	if !ok || resourceID == "" {
+		return nil
+	}
+	id, err := strconv.ParseUint(resourceID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	value, err := loader(uint(id))
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// Record 计算变更并写入审计日志，写入失败只记录错误日志，不影响业务请求
+func (s *auditService) Record(entry *model.AuditLog, request []byte, before interface{}, response json.RawMessage) {
+	if len(request) > 0 {
+		entry.Request = truncateString(redactJSON(request), auditRequestMaxLength)
+	}
+
+	beforeFields := auditFields(before)
+	var afterFields map[string]interface{}
+	if entry.Result == model.AuditResultSuccess {
+		switch {
+		case entry.Method == http.MethodDelete && entry.Action == "delete":
+			// 资源已删除，变更为删除前的全部字段
+		case entry.ResourceID != "" && before != nil:
+			afterFields = auditFields(s.Snapshot(entry.ResourceType, entry.ResourceID))
+		case entry.Action == "create":
+			// 新建资源从响应数据中获取ID和字段
+			afterFields = auditFieldsFromJSON(response)
+			if entry.ResourceID == "" && afterFields != nil {
+				entry.ResourceID = auditString(afterFields["id"])
+			}
+		}
+		entry.Changes = diffAuditFields(beforeFields, afterFields)
+	}
+
+	if entry.ResourceName == "" {
+		entry.ResourceName = auditName(afterFields)
+	}
+	if entry.ResourceName == "" {
+		entry.ResourceName = auditName(beforeFields)
+	}
+	entry.ResourceName = truncateString(entry.ResourceName, 255)
+	entry.Message = truncateString(entry.Message, 512)
+
+	if err := s.repo.Create(entry); err != nil {
+		logger.Error("写入审计日志失败: user=%s, %s %s, %v", entry.Username, entry.Method, entry.Path, err)
+	}
+}
+
+// Get 获取审计日志详情
+func (s *auditService) Get(id uint) (*model.AuditLog, error) {
+	return s.repo.Get(id)
+}
+
+// List 分页查询审计日志
+func (s *auditService) List(q *listquery.Query, filter *repository.AuditLogFilter) (*listquery.Result[*model.AuditLog], error) {
+	return s.repo.List(q, filter)
+}
+
+// Export 分批遍历待导出的审计日志，最多导出 AuditExportMaxRows 条
+func (s *auditService) Export(filter *repository.AuditLogFilter, fn func([]*model.AuditLog) error) error {
+	return s.repo.Each(filter, AuditExportMaxRows, auditExportBatchSize, fn)
+}
+
+// deriveAuditTarget 从路由模板推导操作和资源，如 PUT /api/v1/users/:id 为 users 的 update 操作
+func deriveAuditTarget(method, route string) *model.AuditTarget {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(route, "/api/v1"), "/"), "/")
+	if len(segments) > 1 && segments[0] == "infrastructure" {
+		segments = segments[1:]
+	}
+
+	target := &model.AuditTarget{ResourceType: segments[0]}
+	if alias, ok := auditResourceAliases[target.ResourceType]; ok {
+		target.ResourceType = alias
+	}
+
+	var rest []string
+	for _, segment := range segments[1:] {
+		switch {
+		case strings.HasPrefix(segment, ":") && target.IDParam == "":
+			target.IDParam = segment[1:]
+		case strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*"):
+		default:
+			rest = append(rest, segment)
+		}
+	}
+
+	var verb string
+	switch method {
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodPut, http.MethodPatch:
+		verb = "update"
+	case http.MethodDelete:
+		verb = "delete"
+	default:
+		verb = strings.ToLower(method)
+	}
+	switch {
+	case len(rest) == 0:
+		target.Action = verb
+	case method == http.MethodPost:
+		// 子路径表示的动作，如 POST /k8s-configs/test
+		target.Action = strings.Join(rest, "-")
+	default:
+		target.Action = verb + "-" + strings.Join(rest, "-")
+	}
+	return target
+}
+
+// auditFields 将资源转换为字段映射
+func auditFields(value interface{}) map[string]interface{} {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return auditFieldsFromJSON(data)
+}
+
+// auditFieldsFromJSON 解析JSON对象为字段映射，非对象返回 nil
+func auditFieldsFromJSON(data []byte) map[string]interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// auditName 从字段中识别资源名称
+func auditName(fields map[string]interface{}) string {
+	for _, key := range auditNameFields {
+		if name := auditString(fields[key]); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// auditString 将标量转换为字符串
+func auditString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
@@ -1,10 +1,18 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/cloud"
 	"eden-ops/internal/repository"
 )
 
+// cloudRequestTimeout 调用云厂商接口的超时时间
+const cloudRequestTimeout = 30 * time.Second
+
 // CloudAccountService 云账号服务接口
 type CloudAccountService interface {
 	Create(account *model.CloudAccount) error
@@ -12,19 +20,23 @@ type CloudAccountService interface {
 	Delete(id uint) error
 	Get(id uint) (*model.CloudAccount, error)
 	List(page, pageSize int, name string) ([]*model.CloudAccount, int64, error)
-	ListWithFilter(page, pageSize int, name string, providerID *int64, status *int) ([]*model.CloudAccount, int64, error)
+	ListWithFilter(page, pageSize int, name string, providerID *int64, status *int, scope *model.DataScope) ([]*model.CloudAccount, int64, error)
 	TestConnection(account *model.CloudAccount) error
+	ListRegions(id uint) ([]cloud.Region, error)
+	Driver(account *model.CloudAccount) (cloud.Driver, error)
 }
 
 // cloudAccountService 云账号服务实现
 type cloudAccountService struct {
-	repo *repository.CloudAccountRepository
+	repo         *repository.CloudAccountRepository
+	providerRepo repository.CloudProviderRepository
 }
 
 // NewCloudAccountService 创建云账号服务
-func NewCloudAccountService(repo *repository.CloudAccountRepository) CloudAccountService {
+func NewCloudAccountService(repo *repository.CloudAccountRepository, providerRepo repository.CloudProviderRepository) CloudAccountService {
 	return &cloudAccountService{
-		repo: repo,
+		repo:         repo,
+		providerRepo: providerRepo,
 	}
 }
 
@@ -44,8 +56,8 @@ func (s *cloudAccountService) List(page, pageSize int, name string) ([]*model.Cl
 }
 
 // ListWithFilter 获取云账号列表（支持过滤）
-func (s *cloudAccountService) ListWithFilter(page, pageSize int, name string, providerID *int64, status *int) ([]*model.CloudAccount, int64, error) {
-	total, accounts, err := s.repo.ListWithFilter(page, pageSize, name, providerID, status)
+func (s *cloudAccountService) ListWithFilter(page, pageSize int, name string, providerID *int64, status *int, scope *model.DataScope) ([]*model.CloudAccount, int64, error) {
+	total, accounts, err := s.repo.ListWithFilter(page, pageSize, name, providerID, status, scope)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -78,8 +90,65 @@ func (s *cloudAccountService) Delete(id uint) error {
 	return s.repo.Delete(id)
 }
 
-// TestConnection 测试云账号连接
+// Driver 根据云账号所属云厂商获取驱动
+func (s *cloudAccountService) Driver(account *model.CloudAccount) (cloud.Driver, error) {
+	if account.ProviderID == nil || *account.ProviderID == 0 {
+		return nil, fmt.Errorf("云账号未关联云厂商")
+	}
+	provider, err := s.providerRepo.Get(uint(*account.ProviderID))
+	if err != nil {
+		return nil, fmt.Errorf("云厂商不存在: %v", err)
+	}
+	if provider.Status != 1 {
+		return nil, fmt.Errorf("云厂商 %s 已禁用", provider.Name)
+	}
+	return cloud.NewDriver(provider.Code, cloud.Options{Timeout: cloudRequestTimeout})
+}
+
+// TestConnection 测试云账号连接，编辑已有账号时未填写 SecretKey 则使用已保存的密钥
 func (s *cloudAccountService) TestConnection(account *model.CloudAccount) error {
-	// TODO: 实现云账号连接测试逻辑
-	return nil
+	if account.ID != 0 && account.SecretKey == "" {
+		stored, err := s.repo.Get(uint(account.ID))
+		if err != nil {
+			return fmt.Errorf("云账号不存在: %v", err)
+		}
+		account.SecretKey = stored.SecretKey
+		if account.AccessKey == "" {
+			account.AccessKey = stored.AccessKey
+		}
+		if account.ProviderID == nil {
+			account.ProviderID = stored.ProviderID
+		}
+	}
+
+	driver, err := s.Driver(account)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cloudRequestTimeout)
+	defer cancel()
+	return driver.ValidateCredentials(ctx, cloud.Credential{
+		AccessKey: account.AccessKey,
+		SecretKey: account.SecretKey,
+	})
+}
+
+// ListRegions 获取云账号可用地域
+func (s *cloudAccountService) ListRegions(id uint) ([]cloud.Region, error) {
+	account, err := s.repo.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("云账号不存在: %v", err)
+	}
+	driver, err := s.Driver(account)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cloudRequestTimeout)
+	defer cancel()
+	return driver.ListRegions(ctx, cloud.Credential{
+		AccessKey: account.AccessKey,
+		SecretKey: account.SecretKey,
+	})
 }
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/cloud"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/config"
+	"eden-ops/pkg/logger"
+)
+
+// CloudResourceService 云资源清单服务接口
+type CloudResourceService interface {
+	List(page, pageSize int, filter *repository.CloudResourceFilter) ([]*model.CloudResourceResponse, int64, error)
+	Get(id int64) (*model.CloudResourceResponse, error)
+	FindByIP(ip string) ([]*model.CloudResourceResponse, error)
+	GetHistory(q *listquery.Query, filter *repository.CloudResourceFilter, startTime, endTime *time.Time) (*listquery.Result[model.CloudResourceHistory], error)
+	SyncAccount(accountID int64) error
+	SyncAll()
+}
+
+// cloudResourceService 云资源清单服务实现
+type cloudResourceService struct {
+	accountRepo    *repository.CloudAccountRepository
+	accountService CloudAccountService
+	resourceRepo   repository.CloudResourceRepository
+	historyRepo    repository.CloudResourceHistoryRepository
+	regions        []string
+	running        sync.Map // 正在同步的云账号，避免重复执行
+}
+
+// NewCloudResourceService 创建云资源清单服务
+func NewCloudResourceService(
+	accountRepo *repository.CloudAccountRepository,
+	accountService CloudAccountService,
+	resourceRepo repository.CloudResourceRepository,
+	historyRepo repository.CloudResourceHistoryRepository,
+	cfg config.CloudSyncConfig,
+) CloudResourceService {
+	return &cloudResourceService{
+		accountRepo:    accountRepo,
+		accountService: accountService,
+		resourceRepo:   resourceRepo,
+		historyRepo:    historyRepo,
+		regions:        cfg.Regions,
+	}
+}
+
+// toCloudResourceResponses 转换为响应模型
+func toCloudResourceResponses(resources []model.CloudResource) []*model.CloudResourceResponse {
+	result := make([]*model.CloudResourceResponse, 0, len(resources))
+	for i := range resources {
+		result = append(result, resources[i].ToResponse())
+	}
+	return result
+}
+
+// List 分页查询云资源
+func (s *cloudResourceService) List(page, pageSize int, filter *repository.CloudResourceFilter) ([]*model.CloudResourceResponse, int64, error) {
+	total, resources, err := s.resourceRepo.List(page, pageSize, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return toCloudResourceResponses(resources), total, nil
+}
+
+// Get 获取云资源详情
+func (s *cloudResourceService) Get(id int64) (*model.CloudResourceResponse, error) {
+	resource, err := s.resourceRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return resource.ToResponse(), nil
+}
+
+// FindByIP 根据IP查找云资源
+func (s *cloudResourceService) FindByIP(ip string) ([]*model.CloudResourceResponse, error) {
+	resources, err := s.resourceRepo.FindByIP(ip)
+	if err != nil {
+		return nil, err
+	}
+	return toCloudResourceResponses(resources), nil
+}
+
+// GetHistory 获取云资源历史记录
+func (s *cloudResourceService) GetHistory(q *listquery.Query, filter *repository.CloudResourceFilter, startTime, endTime *time.Time) (*listquery.Result[model.CloudResourceHistory], error) {
+	return s.historyRepo.GetHistory(q, filter, startTime, endTime)
+}
+
+// SyncAll 同步所有启用的云账号，并归档已删除云账号的资源
+func (s *cloudResourceService) SyncAll() {
+	accounts, err := s.accountRepo.ListByStatus(1)
+	if err != nil {
+		logger.Error("获取云账号列表失败: %v", err)
+		return
+	}
+
+	for _, account := range accounts {
+		if err := s.SyncAccount(account.ID); err != nil {
+			logger.Error("同步云账号 %s 资源失败: %v", account.Name, err)
+		}
+	}
+
+	s.archiveRemovedAccounts()
+}
+
+// archiveRemovedAccounts 归档已删除云账号遗留的资源，按云资源中的账号ID查询账号是否仍然存在
+func (s *cloudResourceService) archiveRemovedAccounts() {
+	accountIDs, err := s.resourceRepo.ListAccountIDs()
+	if err != nil {
+		logger.Error("获取云资源账号列表失败: %v", err)
+		return
+	}
+	existingIDs, err := s.accountRepo.ExistingIDs(accountIDs)
+	if err != nil {
+		logger.Error("查询云账号是否存在失败: %v", err)
+		return
+	}
+	existing := make(map[int64]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+	for _, accountID := range accountIDs {
+		if existing[accountID] {
+			continue
+		}
+		if err := s.historyRepo.ArchiveByAccountID(accountID, model.ArchiveReasonAccountRemoved); err != nil {
+			logger.Error("归档云账号 %d 的资源失败: %v", accountID, err)
+			continue
+		}
+		if err := s.resourceRepo.DeleteByAccountID(accountID); err != nil {
+			logger.Error("删除云账号 %d 的资源失败: %v", accountID, err)
+			continue
+		}
+		logger.Info("云账号 %d 已删除，归档其云资源", accountID)
+	}
+}
+
+// SyncAccount 同步单个云账号在各地域下的资源
+func (s *cloudResourceService) SyncAccount(accountID int64) error {
+	if _, loaded := s.running.LoadOrStore(accountID, true); loaded {
+		return fmt.Errorf("云账号正在同步中")
+	}
+	defer s.running.Delete(accountID)
+
+	account, err := s.accountRepo.Get(uint(accountID))
+	if err != nil {
+		return fmt.Errorf("云账号不存在: %v", err)
+	}
+	if account.Status != 1 {
+		return fmt.Errorf("云账号 %s 已禁用", account.Name)
+	}
+
+	driver, err := s.accountService.Driver(account)
+	if err != nil {
+		return err
+	}
+	cred := cloud.Credential{AccessKey: account.AccessKey, SecretKey: account.SecretKey}
+
+	regions, err := s.syncRegions(driver, cred)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var total, failed int
+	for _, region := range regions {
+		for _, resourceType := range driver.ResourceTypes() {
+			count, err := s.syncResources(driver, cred, account, region, resourceType)
+			if err != nil {
+				// 查询失败时保留原有数据，避免误归档
+				failed++
+				logger.Warn("同步云账号 %s 地域 %s 的 %s 资源失败: %v", account.Name, region, resourceType, err)
+				continue
+			}
+			total += count
+		}
+	}
+
+	logger.Info("云账号 %s 资源同步完成，共 %d 个资源，失败 %d 项，耗时 %v", account.Name, total, failed, time.Since(start))
+	if failed > 0 && total == 0 {
+		return fmt.Errorf("同步失败 %d 项", failed)
+	}
+	return nil
+}
+
+// syncRegions 确定需要同步的地域
+func (s *cloudResourceService) syncRegions(driver cloud.Driver, cred cloud.Credential) ([]string, error) {
+	if len(s.regions) > 0 {
+		return s.regions, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cloudRequestTimeout)
+	defer cancel()
+	list, err := driver.ListRegions(ctx, cred)
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []string
+	for _, region := range list {
+		if region.State == "" || region.State == "AVAILABLE" {
+			regions = append(regions, region.ID)
+		}
+	}
+	return regions, nil
+}
+
+// syncResources 同步指定地域下某类资源：归档消失的资源后再更新当前资源
+func (s *cloudResourceService) syncResources(driver cloud.Driver, cred cloud.Credential, account *model.CloudAccount, region string, resourceType cloud.ResourceType) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cloudRequestTimeout)
+	defer cancel()
+	items, err := driver.ListResources(ctx, cred, region, resourceType)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	resources := make([]model.CloudResource, 0, len(items))
+	instanceIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.InstanceID == "" {
+			continue
+		}
+		tags, _ := json.Marshal(item.Tags)
+		spec, _ := json.Marshal(item.Spec)
+		resources = append(resources, model.CloudResource{
+			AccountID:    account.ID,
+			Provider:     driver.Code(),
+			Region:       region,
+			Zone:         item.Zone,
+			ResourceType: string(resourceType),
+			InstanceID:   item.InstanceID,
+			Name:         truncateString(item.Name, 255),
+			Status:       item.Status,
+			PrivateIPs:   truncateString(model.JoinIPs(item.PrivateIPs), 1024),
+			PublicIPs:    truncateString(model.JoinIPs(item.PublicIPs), 1024),
+			Tags:         string(tags),
+			Spec:         string(spec),
+			CreateTime:   item.CreateTime,
+			SyncedAt:     now,
+		})
+		instanceIDs = append(instanceIDs, item.InstanceID)
+	}
+
+	// 1. 先归档已消失的资源到历史表
+	if err := s.historyRepo.ArchiveNotInList(account.ID, region, string(resourceType), instanceIDs, model.ArchiveReasonSyncCleanup); err != nil {
+		return 0, fmt.Errorf("归档云资源失败: %v", err)
+	}
+
+	// 2. 删除已归档的资源
+	if err := s.resourceRepo.DeleteNotInList(account.ID, region, string(resourceType), instanceIDs); err != nil {
+		return 0, fmt.Errorf("删除云资源失败: %v", err)
+	}
+
+	// 3. 批量创建或更新当前资源
+	if err := s.resourceRepo.BatchCreateOrUpdate(resources); err != nil {
+		return 0, fmt.Errorf("保存云资源失败: %v", err)
+	}
+
+	return len(resources), nil
+}
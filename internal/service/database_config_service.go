@@ -2,6 +2,7 @@ package service
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/export"
 	"eden-ops/internal/repository"
 )
 
@@ -11,7 +12,8 @@ type DatabaseConfigService interface {
 	Update(config *model.DatabaseConfig) error
 	Delete(id uint) error
 	Get(id uint) (*model.DatabaseConfig, error)
-	List(page, pageSize int, name string) (int64, []model.DatabaseConfig, error)
+	List(page, pageSize int, name string, scope *model.DataScope) (int64, []model.DatabaseConfig, error)
+	Export(name string, scope *model.DataScope, fn func([]model.DatabaseConfig) error) error
 	TestConnection(config *model.DatabaseConfig) error
 }
 
@@ -26,8 +28,13 @@ func NewDatabaseConfigService(repo *repository.DatabaseConfigRepository) Databas
 }
 
 // List 获取数据库配置列表
-func (s *databaseConfigService) List(page, pageSize int, name string) (int64, []model.DatabaseConfig, error) {
-	return s.repo.List(page, pageSize, name)
+func (s *databaseConfigService) List(page, pageSize int, name string, scope *model.DataScope) (int64, []model.DatabaseConfig, error) {
+	return s.repo.List(page, pageSize, name, scope)
+}
+
+// Export 分批遍历待导出的数据库配置，最多导出 export.MaxRows 条
+func (s *databaseConfigService) Export(name string, scope *model.DataScope, fn func([]model.DatabaseConfig) error) error {
+	return s.repo.Each(name, scope, export.MaxRows, export.BatchSize, fn)
 }
 
 // Create 创建数据库配置
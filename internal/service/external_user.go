@@ -0,0 +1,198 @@
+package service
+
+import (
+	"crypto/rand"
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// externalIdentity 外部身份源（LDAP、OIDC）认证通过的用户信息
+type externalIdentity struct {
+	Source    string
+	Username  string
+	Nickname  string
+	Email     string
+	Phone     string
+	RoleCodes []string
+}
+
+// externalUserSyncer 外部用户同步，首次登录时创建用户，每次登录同步用户信息和角色
+type externalUserSyncer struct {
+	userRepo          repository.UserRepository
+	roleRepo          repository.RoleRepository
+	permissionService PermissionService
+}
+
+// sync 同步外部用户并校验状态
+func (s *externalUserSyncer) sync(identity *externalIdentity) (*model.User, error) {
+	user, err := s.provision(identity)
+	if err != nil {
+		logger.Error("同步外部用户失败: source=%s, username=%s, %v", identity.Source, identity.Username, err)
+		return nil, err
+	}
+	if user.Status != 1 {
+		logger.Info("登录验证失败: 用户已禁用, source=%s, username=%s", identity.Source, user.Username)
+		return nil, errors.New("用户已被禁用")
+	}
+
+	if err := s.syncRoles(user, identity.RoleCodes); err != nil {
+		logger.Error("同步外部用户角色失败: source=%s, username=%s, %v", identity.Source, user.Username, err)
+		return nil, errors.New("同步用户角色失败")
+	}
+	return user, nil
+}
+
+// provision 创建或更新外部用户，用户名已被其他来源的用户占用时拒绝登录
+func (s *externalUserSyncer) provision(identity *externalIdentity) (*model.User, error) {
+	user, err := s.userRepo.GetByUsername(identity.Username)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		// 外部用户不使用本地密码，写入随机密码的哈希占位
+		passwordHash, err := randomPasswordHash()
+		if err != nil {
+			return nil, err
+		}
+		user = &model.User{
+			Username: identity.Username,
+			Password: passwordHash,
+			Nickname: identity.Nickname,
+			Email:    identity.Email,
+			Phone:    identity.Phone,
+			Status:   1,
+			Source:   identity.Source,
+		}
+		if err := s.userRepo.Create(user); err != nil {
+			return nil, err
+		}
+		logger.Info("创建外部用户: source=%s, username=%s", identity.Source, user.Username)
+		return user, nil
+	}
+
+	if user.Source != identity.Source {
+		return nil, errors.New("用户名与其他来源的用户冲突，请联系管理员")
+	}
+
+	fields := make(map[string]interface{})
+	if identity.Nickname != user.Nickname {
+		fields["nickname"] = identity.Nickname
+		user.Nickname = identity.Nickname
+	}
+	if identity.Email != user.Email {
+		fields["email"] = identity.Email
+		user.Email = identity.Email
+	}
+	if identity.Phone != user.Phone {
+		fields["phone"] = identity.Phone
+		user.Phone = identity.Phone
+	}
+	if len(fields) > 0 {
+		if err := s.userRepo.UpdateFields(user.ID, fields); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// syncRoles 将用户角色同步为映射得到的角色，角色变化时刷新权限缓存
+func (s *externalUserSyncer) syncRoles(user *model.User, codes []string) error {
+	roles, err := s.roleRepo.FindByCodes(codes)
+	if err != nil {
+		return err
+	}
+	if len(roles) != len(codes) {
+		found := make(map[string]struct{}, len(roles))
+		for _, role := range roles {
+			found[role.Code] = struct{}{}
+		}
+		for _, code := range codes {
+			if _, ok := found[code]; !ok {
+				logger.Warn("外部身份映射的角色不存在或已禁用: role=%s", code)
+			}
+		}
+	}
+
+	roleIDs := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	sort.Slice(roleIDs, func(i, j int) bool { return roleIDs[i] < roleIDs[j] })
+
+	current := make([]uint, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		current = append(current, role.ID)
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i] < current[j] })
+	if equalIDs(current, roleIDs) {
+		return nil
+	}
+
+	if err := s.userRepo.AssignRoles(user.ID, roleIDs); err != nil {
+		return err
+	}
+	user.Roles = roles
+	s.permissionService.InvalidateAll()
+	logger.Info("同步外部用户角色: username=%s, roles=%v", user.Username, codes)
+	return nil
+}
+
+// mapRoleCodes 按映射表将组或声明值转换为角色编码，键不区分大小写，结果包含默认角色
+func mapRoleCodes(mapping map[string][]string, defaults []string, values []string) []string {
+	seen := make(map[string]struct{})
+	codes := make([]string, 0)
+	add := func(roleCodes []string) {
+		for _, code := range roleCodes {
+			if _, ok := seen[code]; !ok {
+				seen[code] = struct{}{}
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	add(defaults)
+	for _, value := range values {
+		for key, roleCodes := range mapping {
+			if strings.EqualFold(key, value) {
+				add(roleCodes)
+			}
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// equalIDs 比较两个已排序的ID列表
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// randomPasswordHash 生成随机密码的 bcrypt 哈希，明文不保存也不返回，因此该密码无法用于登录
+func randomPasswordHash() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(b)), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/cloud"
+	"eden-ops/internal/pkg/iplocator"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
+)
+
+// IP归属类型
+const (
+	IPOwnerKindPod        = "pod"
+	IPOwnerKindPodHost    = "pod_host"    // Pod所在主机IP
+	IPOwnerKindPodHistory = "pod_history" // 已归档的Pod
+	IPOwnerKindNode       = "node"
+	IPOwnerKindServer     = "server"
+	IPOwnerKindDatabase   = "database"
+	IPOwnerKindCloud      = "cloud"      // 同步的云资源清单
+	IPOwnerKindCloudLive  = "cloud_live" // 实时查询的云资源
+)
+
+// IP归属排序分值，分值越高越可能是IP的直接持有者
+const (
+	ipScorePod         = 100
+	ipScoreNode        = 95
+	ipScoreCloud       = 90
+	ipScoreCloudLive   = 85
+	ipScoreServer      = 80
+	ipScoreDatabase    = 75
+	ipScoreHostNetwork = 50
+	ipScorePodHost     = 40
+	ipScorePodHistory  = 20
+)
+
+const (
+	ipLocatePodLimit     = 50
+	ipLocateHistoryLimit = 20
+)
+
+// IPOwner IP归属对象
+type IPOwner struct {
+	Kind         string     `json:"kind"`
+	Score        int        `json:"score"`
+	Match        string     `json:"match"` // 匹配的字段
+	ID           int64      `json:"id,omitempty"`
+	Name         string     `json:"name"`
+	Namespace    string     `json:"namespace,omitempty"`
+	ClusterID    int64      `json:"clusterId,omitempty"`
+	ClusterName  string     `json:"clusterName,omitempty"`
+	AccountID    int64      `json:"accountId,omitempty"`
+	Provider     string     `json:"provider,omitempty"`
+	Region       string     `json:"region,omitempty"`
+	ResourceType string     `json:"resourceType,omitempty"`
+	InstanceID   string     `json:"instanceId,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	Description  string     `json:"description,omitempty"`
+	LastSeen     *time.Time `json:"lastSeen,omitempty"`
+}
+
+// IPLocateRequest IP归属查询请求
+type IPLocateRequest struct {
+	IP        string
+	Live      bool             // 是否实时查询云厂商接口
+	AccountID int64            // 实时查询的云账号，为空时查询所有启用的云账号
+	Region    string           // 实时查询的地域
+	Scope     *model.DataScope // 数据范围，范围外的归属对象不返回
+}
+
+// IPLocateResult IP归属查询结果
+type IPLocateResult struct {
+	IP     string    `json:"ip"`
+	Owners []IPOwner `json:"owners"`
+	Errors []string  `json:"errors,omitempty"`
+}
+
+// IPLocateService IP归属查询服务接口
+type IPLocateService interface {
+	Locate(ctx context.Context, req *IPLocateRequest) (*IPLocateResult, error)
+}
+
+// ipLocateService IP归属查询服务实现
+type ipLocateService struct {
+	podRepo        repository.K8sPodRepository
+	podHistoryRepo repository.K8sPodHistoryRepository
+	nodeRepo       repository.K8sNodeRepository
+	k8sConfigRepo  repository.K8sConfigRepository
+	serverRepo     *repository.ServerConfigRepository
+	databaseRepo   *repository.DatabaseConfigRepository
+	resourceRepo   repository.CloudResourceRepository
+	accountRepo    *repository.CloudAccountRepository
+	accountService CloudAccountService
+}
+
+// NewIPLocateService 创建IP归属查询服务
+func NewIPLocateService(
+	podRepo repository.K8sPodRepository,
+	podHistoryRepo repository.K8sPodHistoryRepository,
+	nodeRepo repository.K8sNodeRepository,
+	k8sConfigRepo repository.K8sConfigRepository,
+	serverRepo *repository.ServerConfigRepository,
+	databaseRepo *repository.DatabaseConfigRepository,
+	resourceRepo repository.CloudResourceRepository,
+	accountRepo *repository.CloudAccountRepository,
+	accountService CloudAccountService,
+) IPLocateService {
+	return &ipLocateService{
+		podRepo:        podRepo,
+		podHistoryRepo: podHistoryRepo,
+		nodeRepo:       nodeRepo,
+		k8sConfigRepo:  k8sConfigRepo,
+		serverRepo:     serverRepo,
+		databaseRepo:   databaseRepo,
+		resourceRepo:   resourceRepo,
+		accountRepo:    accountRepo,
+		accountService: accountService,
+	}
+}
+
+// Locate 在K8s、服务器、数据库及云资源中查找IP的归属，按可信度排序
+func (s *ipLocateService) Locate(ctx context.Context, req *IPLocateRequest) (*IPLocateResult, error) {
+	result := &IPLocateResult{IP: req.IP, Owners: []IPOwner{}}
+	clusterNames := make(map[int64]string)
+
+	fail := func(source string, err error) {
+		logger.Error("查询%s IP归属失败: %v", source, err)
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", source, err))
+	}
+
+	// Pod
+	pods, err := s.podRepo.FindByIP(req.IP, ipLocatePodLimit)
+	if err != nil {
+		fail("Pod", err)
+	}
+	for _, pod := range pods {
+		if !req.Scope.AllowsNamespace(pod.ConfigID, pod.Namespace) {
+			continue
+		}
+		owner := IPOwner{
+			Kind:        IPOwnerKindPod,
+			ID:          pod.ID,
+			Name:        pod.Name,
+			Namespace:   pod.Namespace,
+			ClusterID:   pod.ConfigID,
+			ClusterName: s.clusterName(clusterNames, pod.ConfigID),
+			Status:      pod.Status,
+			Description: pod.WorkloadKind + "/" + pod.WorkloadName,
+			LastSeen:    timePtr(pod.UpdatedAt),
+		}
+		switch {
+		case pod.PodIP == req.IP && pod.PodIP == pod.HostIP:
+			// hostNetwork Pod 与节点共用IP
+			owner.Match, owner.Score = "pod_ip", ipScoreHostNetwork
+		case pod.PodIP == req.IP:
+			owner.Match, owner.Score = "pod_ip", ipScorePod
+		case pod.InstanceIP == req.IP:
+			owner.Match, owner.Score = "instance_ip", ipScorePod
+		default:
+			owner.Kind, owner.Match, owner.Score = IPOwnerKindPodHost, "host_ip", ipScorePodHost
+			owner.Description = "运行于节点 " + pod.NodeName
+		}
+		result.Owners = append(result.Owners, owner)
+	}
+
+	// 节点
+	nodes, err := s.nodeRepo.FindByIP(req.IP)
+	if err != nil {
+		fail("Node", err)
+	}
+	for _, node := range nodes {
+		if !req.Scope.AllowsCluster(node.ConfigID) {
+			continue
+		}
+		match := "internal_ip"
+		if node.ExternalIP == req.IP {
+			match = "external_ip"
+		}
+		result.Owners = append(result.Owners, IPOwner{
+			Kind:        IPOwnerKindNode,
+			Score:       ipScoreNode,
+			Match:       match,
+			ID:          node.ID,
+			Name:        node.Name,
+			ClusterID:   node.ConfigID,
+			ClusterName: s.clusterName(clusterNames, node.ConfigID),
+			Status:      node.Status,
+			LastSeen:    timePtr(node.UpdatedAt),
+		})
+	}
+
+	// 服务器
+	servers, err := s.serverRepo.FindByHost(req.IP)
+	if err != nil {
+		fail("服务器", err)
+	}
+	for _, server := range servers {
+		if !req.Scope.AllowsServer(int64(server.ID)) {
+			continue
+		}
+		result.Owners = append(result.Owners, IPOwner{
+			Kind:        IPOwnerKindServer,
+			Score:       ipScoreServer,
+			Match:       "host",
+			ID:          int64(server.ID),
+			Name:        server.Name,
+			Status:      string(server.Status),
+			Description: server.Description,
+		})
+	}
+
+	// 数据库
+	databases, err := s.databaseRepo.FindByHost(req.IP)
+	if err != nil {
+		fail("数据库", err)
+	}
+	for _, db := range databases {
+		if !req.Scope.AllowsDatabase(int64(db.ID)) {
+			continue
+		}
+		result.Owners = append(result.Owners, IPOwner{
+			Kind:        IPOwnerKindDatabase,
+			Score:       ipScoreDatabase,
+			Match:       "host",
+			ID:          int64(db.ID),
+			Name:        db.Name,
+			Status:      db.Status,
+			Description: fmt.Sprintf("%s %s", db.Type, db.Database),
+		})
+	}
+
+	// 云资源清单
+	resources, err := s.resourceRepo.FindByIP(req.IP)
+	if err != nil {
+		fail("云资源", err)
+	}
+	seen := make(map[string]bool)
+	for _, r := range resources {
+		if !req.Scope.AllowsCloudAccount(r.AccountID) {
+			continue
+		}
+		seen[cloudOwnerKey(r.AccountID, r.Region, r.ResourceType, r.InstanceID)] = true
+		result.Owners = append(result.Owners, IPOwner{
+			Kind:         IPOwnerKindCloud,
+			Score:        ipScoreCloud,
+			Match:        cloudMatch(model.SplitIPs(r.PublicIPs), req.IP),
+			ID:           r.ID,
+			Name:         r.Name,
+			AccountID:    r.AccountID,
+			Provider:     r.Provider,
+			Region:       r.Region,
+			ResourceType: r.ResourceType,
+			InstanceID:   r.InstanceID,
+			Status:       r.Status,
+			LastSeen:     timePtr(r.SyncedAt),
+		})
+	}
+
+	// 实时查询云厂商
+	if req.Live {
+		s.locateLive(ctx, req, seen, result)
+	}
+
+	// 历史Pod
+	histories, err := s.podHistoryRepo.FindByIP(req.IP, ipLocateHistoryLimit)
+	if err != nil {
+		fail("Pod历史", err)
+	}
+	for _, h := range histories {
+		if !req.Scope.AllowsNamespace(h.ConfigID, h.Namespace) {
+			continue
+		}
+		archivedAt := h.ArchivedAt
+		result.Owners = append(result.Owners, IPOwner{
+			Kind:        IPOwnerKindPodHistory,
+			Score:       ipScorePodHistory,
+			Match:       "pod_ip",
+			ID:          int64(h.OriginalID),
+			Name:        h.Name,
+			Namespace:   h.Namespace,
+			ClusterID:   h.ConfigID,
+			ClusterName: s.clusterName(clusterNames, h.ConfigID),
+			Status:      h.Status,
+			Description: "已于 " + archivedAt.Format("2006-01-02 15:04:05") + " 归档",
+			LastSeen:    &archivedAt,
+		})
+	}
+
+	sort.SliceStable(result.Owners, func(i, j int) bool {
+		a, b := result.Owners[i], result.Owners[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.LastSeen != nil && b.LastSeen != nil {
+			return a.LastSeen.After(*b.LastSeen)
+		}
+		return a.LastSeen != nil
+	})
+
+	return result, nil
+}
+
+// locateLive 使用云账号凭据实时查询云资源，跳过清单中已存在的资源
+func (s *ipLocateService) locateLive(ctx context.Context, req *IPLocateRequest, seen map[string]bool, result *IPLocateResult) {
+	if req.Region == "" {
+		result.Errors = append(result.Errors, "实时查询需要指定地域")
+		return
+	}
+
+	var accounts []model.CloudAccount
+	if req.AccountID > 0 {
+		account, err := s.accountRepo.Get(uint(req.AccountID))
+		if err != nil || !req.Scope.AllowsCloudAccount(account.ID) {
+			result.Errors = append(result.Errors, "云账号不存在")
+			return
+		}
+		accounts = append(accounts, *account)
+	} else {
+		_, list, err := s.accountRepo.List(1, 1000, "")
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("获取云账号列表失败: %v", err))
+			return
+		}
+		for _, account := range list {
+			if account.Status == 1 && req.Scope.AllowsCloudAccount(account.ID) {
+				accounts = append(accounts, account)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cloudRequestTimeout)
+	defer cancel()
+	for i := range accounts {
+		account := &accounts[i]
+		driver, err := s.accountService.Driver(account)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("云账号 %s: %v", account.Name, err))
+			continue
+		}
+		locator := iplocator.NewLocator(driver, cloud.Credential{
+			AccessKey: account.AccessKey,
+			SecretKey: account.SecretKey,
+		}, req.Region)
+
+		infos, err := locator.LocateIP(ctx, req.IP)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("云账号 %s: %v", account.Name, err))
+		}
+		for _, info := range infos {
+			key := cloudOwnerKey(account.ID, info.Region, string(info.Type), info.InstanceID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			match := "private_ip"
+			if info.Public {
+				match = "public_ip"
+			}
+			result.Owners = append(result.Owners, IPOwner{
+				Kind:         IPOwnerKindCloudLive,
+				Score:        ipScoreCloudLive,
+				Match:        match,
+				Name:         info.Name,
+				AccountID:    account.ID,
+				Provider:     driver.Code(),
+				Region:       info.Region,
+				ResourceType: string(info.Type),
+				InstanceID:   info.InstanceID,
+				Status:       info.Status,
+			})
+		}
+	}
+}
+
+// clusterName 获取集群名称，按请求缓存
+func (s *ipLocateService) clusterName(cache map[int64]string, configID int64) string {
+	if name, ok := cache[configID]; ok {
+		return name
+	}
+	name := ""
+	if config, err := s.k8sConfigRepo.Get(configID); err == nil {
+		name = config.Name
+	}
+	cache[configID] = name
+	return name
+}
+
+// cloudOwnerKey 云资源唯一键
+func cloudOwnerKey(accountID int64, region, resourceType, instanceID string) string {
+	return fmt.Sprintf("%d/%s/%s/%s", accountID, region, resourceType, instanceID)
+}
+
+// cloudMatch 判断匹配的是公网还是内网IP
+func cloudMatch(publicIPs []string, ip string) string {
+	for _, v := range publicIPs {
+		if v == ip {
+			return "public_ip"
+		}
+	}
+	return "private_ip"
+}
+
+// timePtr 返回非零时间的指针
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,515 @@
+package service
+
+import (
+	"context"
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// 容量报告限制和调整建议参数
+const (
+	// MaxK8sCapacityPods 容量报告最多统计的Pod数，工作负载数使用同样的上限
+	MaxK8sCapacityPods = 20000
+	// maxK8sCapacityPodList 报告中最多列出的 BestEffort Pod 数
+	maxK8sCapacityPodList = 500
+	// maxK8sCapacitySummaryWorkloads 每周汇总中最多保存的需要关注的工作负载数
+	maxK8sCapacitySummaryWorkloads = 100
+	// k8sCapacityBatchSize 从库中分批读取资源的批大小
+	k8sCapacityBatchSize = 1000
+	// k8sCapacityUsageTimeout 从 metrics-server 获取用量的超时时间
+	k8sCapacityUsageTimeout = 15 * time.Second
+
+	// rightsizingHeadroom 建议请求在平均用量之上预留的余量
+	rightsizingHeadroom = 1.2
+	// rightsizingLowWatermark 用量低于请求的该比例时建议降低请求
+	rightsizingLowWatermark = 0.5
+	// rightsizingHighWatermark 用量高于请求的该比例时建议提高请求
+	rightsizingHighWatermark = 0.9
+	// rightsizingMinCPU 建议的最小CPU请求，核
+	rightsizingMinCPU = 0.01
+	// rightsizingMinMemory 建议的最小内存请求，字节
+	rightsizingMinMemory = 16 << 20
+)
+
+// ErrInvalidK8sCapacityQuery 容量报告查询参数无效
+var ErrInvalidK8sCapacityQuery = errors.New("无效的容量报告查询")
+
+// K8sCapacityQuery 容量报告查询条件
+type K8sCapacityQuery struct {
+	ConfigID     int64
+	Namespace    string // 为空表示整个集群
+	IncludeUsage bool   // 是否从 metrics-server 获取用量并给出调整建议
+}
+
+// K8sCapacityService K8s容量规划报告服务接口
+type K8sCapacityService interface {
+	Report(query *K8sCapacityQuery, scope *model.DataScope) (*model.K8sCapacityReport, error)
+	ListSummaries(configID int64, q *listquery.Query) (*listquery.Result[*model.K8sCapacitySummary], error)
+	GenerateWeeklySummaries(includeUsage bool) error
+}
+
+// k8sCapacityService K8s容量规划报告服务实现
+type k8sCapacityService struct {
+	podRepo      repository.K8sPodRepository
+	workloadRepo repository.K8sWorkloadRepository
+	nodeRepo     repository.K8sNodeRepository
+	configRepo   repository.K8sConfigRepository
+	summaryRepo  repository.K8sCapacitySummaryRepository
+}
+
+// NewK8sCapacityService 创建K8s容量规划报告服务
+func NewK8sCapacityService(podRepo repository.K8sPodRepository, workloadRepo repository.K8sWorkloadRepository, nodeRepo repository.K8sNodeRepository, configRepo repository.K8sConfigRepository, summaryRepo repository.K8sCapacitySummaryRepository) K8sCapacityService {
+	return &k8sCapacityService{
+		podRepo:      podRepo,
+		workloadRepo: workloadRepo,
+		nodeRepo:     nodeRepo,
+		configRepo:   configRepo,
+		summaryRepo:  summaryRepo,
+	}
+}
+
+// k8sPodResources 解析后的Pod资源配置
+type k8sPodResources struct {
+	requests model.K8sResourceAmount
+	limits   model.K8sResourceAmount
+}
+
+// Report 按同步的数据生成集群或命名空间的容量报告：请求占可分配资源的比例、限制与请求的比值、
+// 缺少请求或限制的工作负载和 BestEffort Pod。IncludeUsage 时从 metrics-server 获取用量，给出请求的调整建议
+func (s *k8sCapacityService) Report(query *K8sCapacityQuery, scope *model.DataScope) (*model.K8sCapacityReport, error) {
+	if query.ConfigID <= 0 {
+		return nil, fmt.Errorf("%w: 集群ID无效", ErrInvalidK8sCapacityQuery)
+	}
+	configID := query.ConfigID
+	report := &model.K8sCapacityReport{
+		ConfigID:       configID,
+		Namespace:      query.Namespace,
+		GeneratedAt:    time.Now(),
+		Namespaces:     []*model.K8sNamespaceCapacity{},
+		Workloads:      []*model.K8sWorkloadCapacity{},
+		BestEffortPods: []*model.K8sCapacityPod{},
+	}
+
+	// 可分配资源按全部节点计算，命名空间的占比也相对于整个集群
+	err := s.nodeRepo.Each(&repository.K8sNodeFilter{ConfigID: configID}, scope, MaxK8sCapacityPods, k8sCapacityBatchSize, func(batch []model.K8sNode) error {
+		for _, node := range batch {
+			report.NodeCount++
+			report.Allocatable.CPU += parseCPUCores(node.CPUAllocatable)
+			report.Allocatable.Memory += parseMemoryBytes(node.MemoryAllocatable)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make(map[string]*model.K8sNamespaceCapacity)
+	namespaceOf := func(name string) *model.K8sNamespaceCapacity {
+		ns, ok := namespaces[name]
+		if !ok {
+			ns = &model.K8sNamespaceCapacity{Namespace: name}
+			namespaces[name] = ns
+		}
+		return ns
+	}
+
+	// 工作负载：每副本的请求和限制
+	workloads := make(map[string]*model.K8sWorkloadCapacity)
+	err = s.workloadRepo.Each(&repository.K8sWorkloadFilter{ConfigID: &configID, Namespace: query.Namespace}, scope, MaxK8sCapacityPods+1, k8sCapacityBatchSize, func(batch []model.K8sWorkload) error {
+		for _, w := range batch {
+			if len(report.Workloads) == MaxK8sCapacityPods {
+				report.Truncated = true
+				return nil
+			}
+			workload := &model.K8sWorkloadCapacity{
+				ID:        w.ID,
+				Name:      w.Name,
+				Namespace: w.Namespace,
+				Kind:      w.Kind,
+				Replicas:  w.Replicas,
+				ReplicaRequests: model.K8sResourceAmount{
+					CPU:    parseCPUCores(derefString(w.CPURequest)),
+					Memory: parseMemoryBytes(derefString(w.MemoryRequest)),
+				},
+				ReplicaLimits: model.K8sResourceAmount{
+					CPU:    parseCPUCores(derefString(w.CPULimit)),
+					Memory: parseMemoryBytes(derefString(w.MemoryLimit)),
+				},
+			}
+			workload.MissingRequests = missingResources(workload.ReplicaRequests)
+			workload.MissingLimits = missingResources(workload.ReplicaLimits)
+			workloads[workloadKey(w.Namespace, w.Kind, w.Name)] = workload
+			report.Workloads = append(report.Workloads, workload)
+
+			ns := namespaceOf(w.Namespace)
+			ns.WorkloadCount++
+			if len(workload.MissingRequests) > 0 {
+				ns.MissingRequestWorkloads++
+				report.MissingRequestWorkloads++
+			}
+			if len(workload.MissingLimits) > 0 {
+				ns.MissingLimitWorkloads++
+				report.MissingLimitWorkloads++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.WorkloadCount = len(report.Workloads)
+
+	// Pod：按实际运行的Pod累加请求和限制，并按 QoS 等级分类
+	var pods []*model.K8sPod
+	err = s.podRepo.Each(&repository.K8sPodFilter{ConfigID: &configID, Namespace: query.Namespace}, scope, MaxK8sCapacityPods+1, k8sCapacityBatchSize, func(batch []model.K8sPod) error {
+		for i := range batch {
+			if len(pods) == MaxK8sCapacityPods {
+				report.Truncated = true
+				return nil
+			}
+			pod := &batch[i]
+			pods = append(pods, pod)
+			res := podResources(pod)
+			ns := namespaceOf(pod.Namespace)
+			ns.PodCount++
+			addAmount(&ns.Requests, res.requests)
+			addAmount(&ns.Limits, res.limits)
+			addAmount(&report.Requests, res.requests)
+			addAmount(&report.Limits, res.limits)
+
+			workload := workloads[workloadKey(pod.Namespace, pod.WorkloadKind, pod.WorkloadName)]
+			if workload != nil {
+				workload.PodCount++
+				addAmount(&workload.Requests, res.requests)
+				addAmount(&workload.Limits, res.limits)
+			}
+
+			switch podQoSClass(pod, res) {
+			case model.K8sQoSGuaranteed:
+				ns.QoS.Guaranteed++
+				report.QoS.Guaranteed++
+			case model.K8sQoSBurstable:
+				ns.QoS.Burstable++
+				report.QoS.Burstable++
+			default:
+				ns.QoS.BestEffort++
+				report.QoS.BestEffort++
+				if workload != nil {
+					workload.BestEffortPods++
+				}
+				if len(report.BestEffortPods) < maxK8sCapacityPodList {
+					report.BestEffortPods = append(report.BestEffortPods, &model.K8sCapacityPod{
+						ID: pod.ID, Name: pod.Name, Namespace: pod.Namespace,
+						WorkloadKind: pod.WorkloadKind, WorkloadName: pod.WorkloadName, NodeName: pod.NodeName,
+					})
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.PodCount = len(pods)
+
+	if query.IncludeUsage {
+		if err := s.addUsage(report, query, pods, workloads, namespaceOf); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("获取用量失败，未给出调整建议: %v", err))
+		}
+	}
+
+	report.Share = capacityShare(report.Requests, report.Limits, report.Allocatable)
+	for _, ns := range namespaces {
+		ns.Share = capacityShare(ns.Requests, ns.Limits, report.Allocatable)
+		report.Namespaces = append(report.Namespaces, ns)
+	}
+	for _, workload := range report.Workloads {
+		workload.Share = capacityShare(workload.Requests, workload.Limits, report.Allocatable)
+		// 限制与请求的比值按工作负载定义计算，不受运行中的Pod数影响
+		workload.Share.CPULimitRatio = capacityRatio(workload.ReplicaLimits.CPU, workload.ReplicaRequests.CPU)
+		workload.Share.MemoryLimitRatio = capacityRatio(float64(workload.ReplicaLimits.Memory), float64(workload.ReplicaRequests.Memory))
+		report.RecommendationCount += len(workload.Recommendations)
+	}
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace
+	})
+	sort.SliceStable(report.Workloads, func(i, j int) bool {
+		a, b := report.Workloads[i], report.Workloads[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return report, nil
+}
+
+// addUsage 从 metrics-server 获取Pod用量，汇总到工作负载和命名空间，并按工作负载的平均用量给出请求调整建议
+func (s *k8sCapacityService) addUsage(report *model.K8sCapacityReport, query *K8sCapacityQuery, pods []*model.K8sPod, workloads map[string]*model.K8sWorkloadCapacity, namespaceOf func(string) *model.K8sNamespaceCapacity) error {
+	config, err := s.configRepo.Get(query.ConfigID)
+	if err != nil {
+		return err
+	}
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(config.Kubeconfig))
+	if err != nil {
+		return fmt.Errorf("failed to parse kubeconfig: %v", err)
+	}
+	restConfig.Timeout = k8sCapacityUsageTimeout
+	client, err := metricsclient.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), k8sCapacityUsageTimeout)
+	defer cancel()
+	metrics, err := client.MetricsV1beta1().PodMetricses(query.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pod metrics: %v", err)
+	}
+	usages := make(map[string]model.K8sResourceAmount, len(metrics.Items))
+	for _, item := range metrics.Items {
+		var usage model.K8sResourceAmount
+		for _, container := range item.Containers {
+			usage.CPU += float64(container.Usage.Cpu().MilliValue()) / 1000
+			usage.Memory += container.Usage.Memory().Value()
+		}
+		usages[item.Namespace+"/"+item.Name] = usage
+	}
+
+	// 工作负载按有用量数据的Pod求平均
+	sampled := make(map[*model.K8sWorkloadCapacity]int)
+	for _, pod := range pods {
+		usage, ok := usages[pod.Namespace+"/"+pod.Name]
+		if !ok {
+			continue
+		}
+		ns := namespaceOf(pod.Namespace)
+		if ns.Usage == nil {
+			ns.Usage = &model.K8sResourceAmount{}
+		}
+		addAmount(ns.Usage, usage)
+		if workload := workloads[workloadKey(pod.Namespace, pod.WorkloadKind, pod.WorkloadName)]; workload != nil {
+			if workload.Usage == nil {
+				workload.Usage = &model.K8sResourceAmount{}
+			}
+			addAmount(workload.Usage, usage)
+			sampled[workload]++
+		}
+	}
+	for workload, count := range sampled {
+		workload.Usage.CPU /= float64(count)
+		workload.Usage.Memory /= int64(count)
+		workload.Recommendations = rightsizingRecommendations(workload)
+	}
+	report.UsageAvailable = true
+	return nil
+}
+
+// rightsizingRecommendations 按每个Pod的平均用量给出每副本请求的调整建议，建议值为用量加上余量
+func rightsizingRecommendations(workload *model.K8sWorkloadCapacity) []*model.K8sRightsizingRecommendation {
+	var recommendations []*model.K8sRightsizingRecommendation
+	if rec := rightsize("cpu", workload.ReplicaRequests.CPU, workload.Usage.CPU, rightsizingMinCPU, 0.001); rec != nil {
+		recommendations = append(recommendations, rec)
+	}
+	if rec := rightsize("memory", float64(workload.ReplicaRequests.Memory), float64(workload.Usage.Memory), rightsizingMinMemory, 1<<20); rec != nil {
+		recommendations = append(recommendations, rec)
+	}
+	return recommendations
+}
+
+// rightsize 单项资源的调整建议，建议值不低于 minimum 并向上取整到 step，不需要调整时返回 nil
+func rightsize(resourceName string, request, usage, minimum, step float64) *model.K8sRightsizingRecommendation {
+	recommended := math.Max(math.Ceil(usage*rightsizingHeadroom/step)*step, minimum)
+	rec := &model.K8sRightsizingRecommendation{Resource: resourceName, Current: request, Usage: usage, Recommended: recommended}
+	switch {
+	case request <= 0:
+		rec.Action, rec.Reason = model.K8sRightsizingSet, "未设置请求，调度时不会为其预留资源"
+	case usage > request*rightsizingHighWatermark:
+		rec.Action, rec.Reason = model.K8sRightsizingIncrease, fmt.Sprintf("平均用量为请求的 %.0f%%", usage/request*100)
+	case usage < request*rightsizingLowWatermark && recommended < request:
+		rec.Action, rec.Reason = model.K8sRightsizingDecrease, fmt.Sprintf("平均用量仅为请求的 %.0f%%", usage/request*100)
+	default:
+		return nil
+	}
+	return rec
+}
+
+// ListSummaries 查询集群的每周容量汇总
+func (s *k8sCapacityService) ListSummaries(configID int64, q *listquery.Query) (*listquery.Result[*model.K8sCapacitySummary], error) {
+	return s.summaryRepo.List(configID, q)
+}
+
+// GenerateWeeklySummaries 为全部启用的集群生成本周的容量汇总，单个集群失败时继续处理其余集群
+func (s *k8sCapacityService) GenerateWeeklySummaries(includeUsage bool) error {
+	enabled := 1
+	var configs []model.K8sConfig
+	err := s.configRepo.Each("", &enabled, nil, "", nil, MaxK8sCapacityPods, k8sCapacityBatchSize, func(batch []model.K8sConfig) error {
+		configs = append(configs, batch...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	weekStart := startOfWeek(time.Now())
+	var failed int
+	for _, config := range configs {
+		report, err := s.Report(&K8sCapacityQuery{ConfigID: config.ID, IncludeUsage: includeUsage}, nil)
+		if err == nil {
+			err = s.summaryRepo.Save(newCapacitySummary(&config, weekStart, report))
+		}
+		if err != nil {
+			failed++
+			logger.Error("生成集群 %s 的每周容量汇总失败: %v", config.Name, err)
+			continue
+		}
+		logger.Info("集群 %s 本周容量汇总: CPU请求占比 %.1f%%，内存请求占比 %.1f%%，缺少请求的工作负载 %d 个，BestEffort Pod %d 个，调整建议 %d 条",
+			config.Name, report.Share.CPURequestShare, report.Share.MemoryRequestShare, report.MissingRequestWorkloads, report.QoS.BestEffort, report.RecommendationCount)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个集群的每周容量汇总生成失败", failed)
+	}
+	return nil
+}
+
+// newCapacitySummary 由容量报告生成每周汇总，只保存需要关注的工作负载
+func newCapacitySummary(config *model.K8sConfig, weekStart time.Time, report *model.K8sCapacityReport) *model.K8sCapacitySummary {
+	summary := &model.K8sCapacitySummary{
+		ConfigID:                config.ID,
+		ConfigName:              config.Name,
+		WeekStart:               weekStart,
+		NodeCount:               report.NodeCount,
+		PodCount:                report.PodCount,
+		WorkloadCount:           report.WorkloadCount,
+		CPUAllocatable:          report.Allocatable.CPU,
+		CPURequested:            report.Requests.CPU,
+		CPURequestShare:         report.Share.CPURequestShare,
+		MemoryAllocatable:       report.Allocatable.Memory,
+		MemoryRequested:         report.Requests.Memory,
+		MemoryRequestShare:      report.Share.MemoryRequestShare,
+		MissingRequestWorkloads: report.MissingRequestWorkloads,
+		MissingLimitWorkloads:   report.MissingLimitWorkloads,
+		BestEffortPods:          report.QoS.BestEffort,
+		RecommendationCount:     report.RecommendationCount,
+		UsageAvailable:          report.UsageAvailable,
+		Namespaces:              report.Namespaces,
+		Workloads:               []*model.K8sWorkloadCapacity{},
+		Warnings:                report.Warnings,
+		CreatedAt:               time.Now(),
+	}
+	for _, workload := range report.Workloads {
+		if len(summary.Workloads) == maxK8sCapacitySummaryWorkloads {
+			break
+		}
+		if len(workload.MissingRequests) > 0 || len(workload.MissingLimits) > 0 || len(workload.Recommendations) > 0 {
+			summary.Workloads = append(summary.Workloads, workload)
+		}
+	}
+	return summary
+}
+
+// startOfWeek 所在周的周一零点
+func startOfWeek(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// podResources 解析Pod的请求和限制
+func podResources(pod *model.K8sPod) k8sPodResources {
+	return k8sPodResources{
+		requests: model.K8sResourceAmount{
+			CPU:    parseCPUCores(derefString(pod.CPURequest)),
+			Memory: parseMemoryBytes(derefString(pod.MemoryRequest)),
+		},
+		limits: model.K8sResourceAmount{
+			CPU:    parseCPUCores(derefString(pod.CPULimit)),
+			Memory: parseMemoryBytes(derefString(pod.MemoryLimit)),
+		},
+	}
+}
+
+// podQoSClass Pod的 QoS 等级，优先使用同步时记录的 Kubernetes 按全部容器计算的等级。
+// 没有记录时按合计的资源近似判断：CPU和内存的请求都等于限制为 Guaranteed，都未设置为 BestEffort，其余为 Burstable
+func podQoSClass(pod *model.K8sPod, res k8sPodResources) string {
+	if pod.QoSClass != "" {
+		return pod.QoSClass
+	}
+	if res.requests == (model.K8sResourceAmount{}) && res.limits == (model.K8sResourceAmount{}) {
+		return model.K8sQoSBestEffort
+	}
+	if res.limits.CPU > 0 && res.limits.Memory > 0 && res.requests == res.limits {
+		return model.K8sQoSGuaranteed
+	}
+	return model.K8sQoSBurstable
+}
+
+// missingResources 未设置（或为 0）的资源
+func missingResources(amount model.K8sResourceAmount) []string {
+	var missing []string
+	if amount.CPU <= 0 {
+		missing = append(missing, "cpu")
+	}
+	if amount.Memory <= 0 {
+		missing = append(missing, "memory")
+	}
+	return missing
+}
+
+// capacityShare 计算请求占可分配资源的百分比和限制与请求的比值
+func capacityShare(requests, limits, allocatable model.K8sResourceAmount) model.K8sCapacityShare {
+	return model.K8sCapacityShare{
+		CPURequestShare:    capacityPercent(requests.CPU, allocatable.CPU),
+		MemoryRequestShare: capacityPercent(float64(requests.Memory), float64(allocatable.Memory)),
+		CPULimitRatio:      capacityRatio(limits.CPU, requests.CPU),
+		MemoryLimitRatio:   capacityRatio(float64(limits.Memory), float64(requests.Memory)),
+	}
+}
+
+// addAmount 累加资源量
+func addAmount(total *model.K8sResourceAmount, amount model.K8sResourceAmount) {
+	total.CPU += amount.CPU
+	total.Memory += amount.Memory
+}
+
+// capacityPercent 百分比，保留两位小数，分母为 0 时为 0
+func capacityPercent(value, total float64) float64 {
+	return capacityRatio(value*100, total)
+}
+
+// capacityRatio 比值，保留两位小数，分母为 0 时为 0
+func capacityRatio(value, base float64) float64 {
+	if base <= 0 {
+		return 0
+	}
+	return math.Round(value/base*100) / 100
+}
+
+// parseCPUCores 解析CPU数量（如 500m、2），返回核数，为空或无法解析时为 0
+func parseCPUCores(value string) float64 {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0
+	}
+	return float64(q.MilliValue()) / 1000
+}
+
+// parseMemoryBytes 解析内存数量（如 512Mi、1G），返回字节数，为空或无法解析时为 0
+func parseMemoryBytes(value string) int64 {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0
+	}
+	return q.Value()
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"eden-ops/internal/model"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// k8sChangeFieldsMaxLength 变更事件中字段名列表的最大长度，与表结构一致
+const k8sChangeFieldsMaxLength = 1024
+
+// k8sSnapshot 参与比较的字段快照，键为数据库列名
+type k8sSnapshot map[string]interface{}
+
+// podSnapshot Pod参与比较的字段
+func podSnapshot(p *model.K8sPod) k8sSnapshot {
+	return k8sSnapshot{
+		"workload_name":  p.WorkloadName,
+		"status":         p.Status,
+		"phase":          p.Phase,
+		"node_name":      p.NodeName,
+		"pod_ip":         p.PodIP,
+		"host_ip":        p.HostIP,
+		"cpu_request":    derefString(p.CPURequest),
+		"cpu_limit":      derefString(p.CPULimit),
+		"memory_request": derefString(p.MemoryRequest),
+		"memory_limit":   derefString(p.MemoryLimit),
+		"restart_count":  p.RestartCount,
+	}
+}
+
+// nodeSnapshot 节点参与比较的字段。资源使用量、注解和状态条件每次同步都会变化，不参与比较
+func nodeSnapshot(n *model.K8sNode) k8sSnapshot {
+	snapshot := k8sSnapshot{
+		"internal_ip":        n.InternalIP,
+		"external_ip":        n.ExternalIP,
+		"os_image":           n.OSImage,
+		"kernel_version":     n.KernelVersion,
+		"container_runtime":  n.ContainerRuntime,
+		"kubelet_version":    n.KubeletVersion,
+		"kube_proxy_version": n.KubeProxyVersion,
+		"cpu_capacity":       n.CPUCapacity,
+		"memory_capacity":    n.MemoryCapacity,
+		"pods_capacity":      n.PodsCapacity,
+		"cpu_allocatable":    n.CPUAllocatable,
+		"memory_allocatable": n.MemoryAllocatable,
+		"pods_allocatable":   n.PodsAllocatable,
+		"status":             n.Status,
+		"ready":              n.Ready,
+		"schedulable":        n.Schedulable,
+		"taints":             parseJSONValue(n.Taints),
+	}
+	addLabelFields(snapshot, "labels", n.Labels)
+	return snapshot
+}
+
+// workloadSnapshot 工作负载参与比较的字段
+func workloadSnapshot(w *model.K8sWorkload) k8sSnapshot {
+	snapshot := k8sSnapshot{
+		"replicas":       w.Replicas,
+		"ready_replicas": w.ReadyReplicas,
+		"status":         w.Status,
+		"images":         parseJSONValue(derefString(w.Images)),
+		"selector":       parseJSONValue(derefString(w.Selector)),
+		"cpu_request":    derefString(w.CPURequest),
+		"cpu_limit":      derefString(w.CPULimit),
+		"memory_request": derefString(w.MemoryRequest),
+		"memory_limit":   derefString(w.MemoryLimit),
+	}
+	addLabelFields(snapshot, "labels", derefString(w.Labels))
+	return snapshot
+}
+
+// addLabelFields 将 JSON 格式的标签展开为 prefix.<key>，单个标签的增删改各自成为一个字段变更
+func addLabelFields(snapshot k8sSnapshot, prefix, labelsJSON string) {
+	if labelsJSON == "" {
+		return
+	}
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(labelsJSON), &labels); err != nil {
+		snapshot[prefix] = labelsJSON
+		return
+	}
+	for key, value := range labels {
+		snapshot[prefix+"."+key] = value
+	}
+}
+
+// parseJSONValue 解析 JSON 字段以便按内容比较，无法解析时按原文比较
+func parseJSONValue(data string) interface{} {
+	if data == "" {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return data
+	}
+	return value
+}
+
+// derefString 空指针视为空字符串
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// diffK8sSnapshots 比较两次同步的字段快照，before 为空表示新建，after 为空表示删除，新建和删除时只记录有值的字段
+func diffK8sSnapshots(before, after k8sSnapshot) []model.K8sFieldChange {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	changes := make([]model.K8sFieldChange, 0)
+	for _, key := range sorted {
+		oldValue, newValue := before[key], after[key]
+		if (before == nil || after == nil) && isEmptyK8sValue(oldValue) && isEmptyK8sValue(newValue) {
+			continue
+		}
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, model.K8sFieldChange{Field: key, Old: oldValue, New: newValue})
+	}
+	return changes
+}
+
+// isEmptyK8sValue 是否为空值
+func isEmptyK8sValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case int:
+		return v == 0
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// k8sChangeObject 参与比较的对象
+type k8sChangeObject struct {
+	workloadKind string
+	namespace    string
+	name         string
+	snapshot     k8sSnapshot
+}
+
+// diffK8sObjects 比较同一集群同一类对象在上次同步和本次同步时的状态，生成变更事件。
+// 上次同步没有任何对象时视为首次同步，不为已有对象生成新建事件
+func diffK8sObjects(configID int64, kind string, previous, current map[string]k8sChangeObject, observedAt time.Time) []*model.K8sChangeEvent {
+	events := make([]*model.K8sChangeEvent, 0)
+	newEvent := func(object k8sChangeObject, action string, changes []model.K8sFieldChange) *model.K8sChangeEvent {
+		fields := make([]string, len(changes))
+		for i, change := range changes {
+			fields[i] = change.Field
+		}
+		return &model.K8sChangeEvent{
+			ConfigID:     configID,
+			Kind:         kind,
+			WorkloadKind: object.workloadKind,
+			Namespace:    object.namespace,
+			Name:         object.name,
+			Action:       action,
+			Changes:      changes,
+			Fields:       truncateFieldList(fields, k8sChangeFieldsMaxLength),
+			Source:       model.K8sChangeSourceSync,
+			ObservedAt:   observedAt,
+		}
+	}
+
+	for _, key := range sortedObjectKeys(current) {
+		object := current[key]
+		old, exists := previous[key]
+		switch {
+		case !exists && len(previous) > 0:
+			events = append(events, newEvent(object, model.K8sChangeActionCreated, diffK8sSnapshots(nil, object.snapshot)))
+		case exists:
+			if changes := diffK8sSnapshots(old.snapshot, object.snapshot); len(changes) > 0 {
+				events = append(events, newEvent(object, model.K8sChangeActionUpdated, changes))
+			}
+		}
+	}
+	for _, key := range sortedObjectKeys(previous) {
+		if _, exists := current[key]; !exists {
+			object := previous[key]
+			events = append(events, newEvent(object, model.K8sChangeActionDeleted, diffK8sSnapshots(object.snapshot, nil)))
+		}
+	}
+	return events
+}
+
+// sortedObjectKeys 按对象标识排序，使同一次同步产生的事件顺序稳定
+func sortedObjectKeys(objects map[string]k8sChangeObject) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// truncateFieldList 以逗号连接字段名，超出长度时丢弃后面的完整字段名
+func truncateFieldList(fields []string, maxLength int) string {
+	var b strings.Builder
+	for _, field := range fields {
+		if b.Len()+len(field)+1 > maxLength {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(field)
+	}
+	return b.String()
+}
+
+// podChangeObjects 按命名空间和名称索引Pod
+func podChangeObjects(pods []model.K8sPod) map[string]k8sChangeObject {
+	objects := make(map[string]k8sChangeObject, len(pods))
+	for i := range pods {
+		p := &pods[i]
+		objects[p.Namespace+"/"+p.Name] = k8sChangeObject{namespace: p.Namespace, name: p.Name, snapshot: podSnapshot(p)}
+	}
+	return objects
+}
+
+// nodeChangeObjects 按名称索引节点
+func nodeChangeObjects(nodes []model.K8sNode) map[string]k8sChangeObject {
+	objects := make(map[string]k8sChangeObject, len(nodes))
+	for i := range nodes {
+		n := &nodes[i]
+		objects[n.Name] = k8sChangeObject{name: n.Name, snapshot: nodeSnapshot(n)}
+	}
+	return objects
+}
+
+// workloadChangeObjects 按命名空间、类型和名称索引工作负载
+func workloadChangeObjects(workloads []model.K8sWorkload) map[string]k8sChangeObject {
+	objects := make(map[string]k8sChangeObject, len(workloads))
+	for i := range workloads {
+		w := &workloads[i]
+		objects[w.Namespace+"/"+w.Kind+"/"+w.Name] = k8sChangeObject{
+			workloadKind: w.Kind,
+			namespace:    w.Namespace,
+			name:         w.Name,
+			snapshot:     workloadSnapshot(w),
+		}
+	}
+	return objects
+}
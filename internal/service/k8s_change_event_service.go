@@ -0,0 +1,79 @@
+package service
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/listquery"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 变更事件查询限制
+const (
+	// DefaultK8sChangeFeedWindow 最近变更默认查询的时间范围
+	DefaultK8sChangeFeedWindow = time.Hour
+	// MaxK8sChangeFeedWindow 最近变更最多查询的时间范围
+	MaxK8sChangeFeedWindow = 7 * 24 * time.Hour
+)
+
+// ErrInvalidK8sChangeQuery 变更事件查询参数无效
+var ErrInvalidK8sChangeQuery = errors.New("无效的变更查询参数")
+
+// K8sChangeEventService K8s资源变更事件服务接口
+type K8sChangeEventService interface {
+	Timeline(filter *repository.K8sChangeEventFilter, q *listquery.Query, scope *model.DataScope) (*listquery.Result[*model.K8sChangeEvent], error)
+	Recent(filter *repository.K8sChangeEventFilter, window time.Duration, q *listquery.Query, scope *model.DataScope) (*listquery.Result[*model.K8sChangeEvent], error)
+}
+
+// k8sChangeEventService K8s资源变更事件服务实现
+type k8sChangeEventService struct {
+	repo repository.K8sChangeEventRepository
+}
+
+// NewK8sChangeEventService 创建K8s资源变更事件服务
+func NewK8sChangeEventService(repo repository.K8sChangeEventRepository) K8sChangeEventService {
+	return &k8sChangeEventService{repo: repo}
+}
+
+// Timeline 单个对象的变更时间线
+func (s *k8sChangeEventService) Timeline(filter *repository.K8sChangeEventFilter, q *listquery.Query, scope *model.DataScope) (*listquery.Result[*model.K8sChangeEvent], error) {
+	if filter.ConfigID <= 0 || filter.Kind == "" || filter.Name == "" {
+		return nil, fmt.Errorf("%w: 必须指定集群、资源类型和名称", ErrInvalidK8sChangeQuery)
+	}
+	if !isK8sHistoryKind(filter.Kind) {
+		return nil, fmt.Errorf("%w: 无效的资源类型", ErrInvalidK8sChangeQuery)
+	}
+	if filter.Kind != model.K8sHistoryKindNode && filter.Namespace == "" {
+		return nil, fmt.Errorf("%w: 必须指定命名空间", ErrInvalidK8sChangeQuery)
+	}
+	return s.repo.List(filter, q, scope)
+}
+
+// Recent 最近一段时间内的变更，不指定集群时查询数据范围内的全部集群
+func (s *k8sChangeEventService) Recent(filter *repository.K8sChangeEventFilter, window time.Duration, q *listquery.Query, scope *model.DataScope) (*listquery.Result[*model.K8sChangeEvent], error) {
+	if window <= 0 {
+		window = DefaultK8sChangeFeedWindow
+	}
+	if window > MaxK8sChangeFeedWindow {
+		return nil, fmt.Errorf("%w: 最多查询最近7天的变更", ErrInvalidK8sChangeQuery)
+	}
+	if filter.Kind != "" && !isK8sHistoryKind(filter.Kind) {
+		return nil, fmt.Errorf("%w: 无效的资源类型", ErrInvalidK8sChangeQuery)
+	}
+	since := time.Now().Add(-window)
+	filter.StartTime = &since
+	filter.EndTime = nil
+	return s.repo.List(filter, q, scope)
+}
+
+// recordK8sChanges 写入同步中发现的变更事件，写入失败只记录日志，不影响同步
+func recordK8sChanges(repo repository.K8sChangeEventRepository, configID int64, kind string, events []*model.K8sChangeEvent) {
+	if repo == nil || len(events) == 0 {
+		return
+	}
+	if err := repo.BatchCreate(events); err != nil {
+		logger.Error("写入集群 %d 的%s变更事件失败: %v", configID, kind, err)
+	}
+}
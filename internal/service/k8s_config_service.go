@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/export"
 	"eden-ops/internal/repository"
 	"eden-ops/internal/utils"
 	"eden-ops/pkg/logger"
@@ -29,8 +30,9 @@ type K8sConfigService interface {
 	UpdateWithClusterInfo(config *model.K8sConfig) error
 	Delete(id uint) error
 	Get(id uint) (*model.K8sConfig, error)
-	List(page, pageSize int, name string, status *int, providerId *int64, clusterID string) ([]*model.K8sConfig, int64, error)
-	ListWithWorkloadCount(page, pageSize int, name string, status *int, providerId *int64, clusterID string) ([]*model.K8sConfigResponse, int64, error)
+	List(page, pageSize int, name string, status *int, providerId *int64, clusterID string, scope *model.DataScope) ([]*model.K8sConfig, int64, error)
+	ListWithWorkloadCount(page, pageSize int, name string, status *int, providerId *int64, clusterID string, scope *model.DataScope) ([]*model.K8sConfigResponse, int64, error)
+	Export(name string, status *int, providerId *int64, clusterID string, scope *model.DataScope, fn func([]model.K8sConfig) error) error
 	TestConnection(config *model.K8sConfig) error
 	SyncCluster(id int64) error
 	GetNamespaces(id int64) ([]string, error)
@@ -140,8 +142,8 @@ func (s *k8sConfigService) Get(id uint) (*model.K8sConfig, error) {
 }
 
 // List 获取Kubernetes配置列表
-func (s *k8sConfigService) List(page, pageSize int, name string, status *int, providerId *int64, clusterID string) ([]*model.K8sConfig, int64, error) {
-	total, configs, err := s.repo.List(page, pageSize, name, status, providerId, clusterID)
+func (s *k8sConfigService) List(page, pageSize int, name string, status *int, providerId *int64, clusterID string, scope *model.DataScope) ([]*model.K8sConfig, int64, error) {
+	total, configs, err := s.repo.List(page, pageSize, name, status, providerId, clusterID, scope)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -155,8 +157,8 @@ func (s *k8sConfigService) List(page, pageSize int, name string, status *int, pr
 }
 
 // ListWithWorkloadCount 获取Kubernetes配置列表（包含工作负载统计）
-func (s *k8sConfigService) ListWithWorkloadCount(page, pageSize int, name string, status *int, providerId *int64, clusterID string) ([]*model.K8sConfigResponse, int64, error) {
-	total, configs, err := s.repo.List(page, pageSize, name, status, providerId, clusterID)
+func (s *k8sConfigService) ListWithWorkloadCount(page, pageSize int, name string, status *int, providerId *int64, clusterID string, scope *model.DataScope) ([]*model.K8sConfigResponse, int64, error) {
+	total, configs, err := s.repo.List(page, pageSize, name, status, providerId, clusterID, scope)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -169,6 +171,11 @@ func (s *k8sConfigService) ListWithWorkloadCount(page, pageSize int, name string
 	return result, total, nil
 }
 
+// Export 按列表的筛选条件分批遍历待导出的Kubernetes配置，最多导出 export.MaxRows 条
+func (s *k8sConfigService) Export(name string, status *int, providerId *int64, clusterID string, scope *model.DataScope, fn func([]model.K8sConfig) error) error {
+	return s.repo.Each(name, status, providerId, clusterID, scope, export.MaxRows, export.BatchSize, fn)
+}
+
 // TestConnection 测试Kubernetes连接
 func (s *k8sConfigService) TestConnection(config *model.K8sConfig) error {
 	k8sConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(config.Kubeconfig))
@@ -730,28 +737,8 @@ func (s *k8sConfigService) getWorkloadsFromCluster(clientset *kubernetes.Clients
 				}
 			}
 
-			// 获取第一个容器的资源配置（通常主容器）
-			container := d.Spec.Template.Spec.Containers[0]
-			if container.Resources.Requests != nil {
-				if cpu := container.Resources.Requests.Cpu(); cpu != nil {
-					cpuReq := cpu.String()
-					cpuRequest = &cpuReq
-				}
-				if memory := container.Resources.Requests.Memory(); memory != nil {
-					memReq := memory.String()
-					memoryRequest = &memReq
-				}
-			}
-			if container.Resources.Limits != nil {
-				if cpu := container.Resources.Limits.Cpu(); cpu != nil {
-					cpuLim := cpu.String()
-					cpuLimit = &cpuLim
-				}
-				if memory := container.Resources.Limits.Memory(); memory != nil {
-					memLim := memory.String()
-					memoryLimit = &memLim
-				}
-			}
+			// 按全部容器计算有效的资源配置
+			cpuRequest, cpuLimit, memoryRequest, memoryLimit = podSpecResources(&d.Spec.Template.Spec)
 		}
 
 		workload := model.K8sWorkload{
@@ -821,28 +808,8 @@ func (s *k8sConfigService) getWorkloadsFromCluster(clientset *kubernetes.Clients
 				}
 			}
 
-			// 获取第一个容器的资源配置（通常主容器）
-			container := s.Spec.Template.Spec.Containers[0]
-			if container.Resources.Requests != nil {
-				if cpu := container.Resources.Requests.Cpu(); cpu != nil {
-					cpuReq := cpu.String()
-					cpuRequest = &cpuReq
-				}
-				if memory := container.Resources.Requests.Memory(); memory != nil {
-					memReq := memory.String()
-					memoryRequest = &memReq
-				}
-			}
-			if container.Resources.Limits != nil {
-				if cpu := container.Resources.Limits.Cpu(); cpu != nil {
-					cpuLim := cpu.String()
-					cpuLimit = &cpuLim
-				}
-				if memory := container.Resources.Limits.Memory(); memory != nil {
-					memLim := memory.String()
-					memoryLimit = &memLim
-				}
-			}
+			// 按全部容器计算有效的资源配置
+			cpuRequest, cpuLimit, memoryRequest, memoryLimit = podSpecResources(&s.Spec.Template.Spec)
 		}
 
 		workload := model.K8sWorkload{
@@ -907,28 +874,8 @@ func (s *k8sConfigService) getWorkloadsFromCluster(clientset *kubernetes.Clients
 				}
 			}
 
-			// 获取第一个容器的资源配置（通常主容器）
-			container := d.Spec.Template.Spec.Containers[0]
-			if container.Resources.Requests != nil {
-				if cpu := container.Resources.Requests.Cpu(); cpu != nil {
-					cpuReq := cpu.String()
-					cpuRequest = &cpuReq
-				}
-				if memory := container.Resources.Requests.Memory(); memory != nil {
-					memReq := memory.String()
-					memoryRequest = &memReq
-				}
-			}
-			if container.Resources.Limits != nil {
-				if cpu := container.Resources.Limits.Cpu(); cpu != nil {
-					cpuLim := cpu.String()
-					cpuLimit = &cpuLim
-				}
-				if memory := container.Resources.Limits.Memory(); memory != nil {
-					memLim := memory.String()
-					memoryLimit = &memLim
-				}
-			}
+			// 按全部容器计算有效的资源配置
+			cpuRequest, cpuLimit, memoryRequest, memoryLimit = podSpecResources(&d.Spec.Template.Spec)
 		}
 
 		workload := model.K8sWorkload{
@@ -992,31 +939,8 @@ func (s *k8sConfigService) getPods(clientset *kubernetes.Clientset, configID int
 			restartCount += int(containerStatus.RestartCount)
 		}
 
-		// 获取容器资源配置（第一个容器）
-		var cpuRequest, cpuLimit, memoryRequest, memoryLimit *string
-		if len(p.Spec.Containers) > 0 {
-			container := p.Spec.Containers[0]
-			if container.Resources.Requests != nil {
-				if cpu := container.Resources.Requests.Cpu(); cpu != nil {
-					cpuReq := cpu.String()
-					cpuRequest = &cpuReq
-				}
-				if memory := container.Resources.Requests.Memory(); memory != nil {
-					memReq := memory.String()
-					memoryRequest = &memReq
-				}
-			}
-			if container.Resources.Limits != nil {
-				if cpu := container.Resources.Limits.Cpu(); cpu != nil {
-					cpuLim := cpu.String()
-					cpuLimit = &cpuLim
-				}
-				if memory := container.Resources.Limits.Memory(); memory != nil {
-					memLim := memory.String()
-					memoryLimit = &memLim
-				}
-			}
-		}
+		// 按全部容器计算有效的资源配置
+		cpuRequest, cpuLimit, memoryRequest, memoryLimit := podSpecResources(&p.Spec)
 
 		// 获取启动时间
 		var startTime *time.Time
@@ -1024,6 +948,15 @@ func (s *k8sConfigService) getPods(clientset *kubernetes.Clientset, configID int
 			startTime = &p.Status.StartTime.Time
 		}
 
+		// 获取标签并序列化为JSON字符串
+		var labelsJSON *string
+		if len(p.Labels) > 0 {
+			if labelsBytes, err := json.Marshal(p.Labels); err == nil {
+				labelsStr := string(labelsBytes)
+				labelsJSON = &labelsStr
+			}
+		}
+
 		// 实例IP通常就是Pod IP，如果没有则使用Host IP
 		instanceIP := p.Status.PodIP
 		if instanceIP == "" {
@@ -1046,6 +979,8 @@ func (s *k8sConfigService) getPods(clientset *kubernetes.Clientset, configID int
 			CPULimit:      cpuLimit,
 			MemoryRequest: memoryRequest,
 			MemoryLimit:   memoryLimit,
+			QoSClass:      string(p.Status.QOSClass),
+			Labels:        labelsJSON,
 			RestartCount:  restartCount,
 			StartTime:     startTime,
 			CreatedAt:     p.CreationTimestamp.Time,
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/archive"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 归档相关限制
+const (
+	defaultArchivePrefix = "k8s-history"
+	archiveDateLayout    = "2006-01-02"
+	maxRestoreDays       = 31               // 单次恢复的最大天数
+	maxArchiveLineSize   = 16 * 1024 * 1024 // 归档文件中单行的最大长度
+)
+
+// ErrInvalidArchiveQuery 归档查询或恢复参数无效
+var ErrInvalidArchiveQuery = errors.New("无效的归档查询参数")
+
+// K8sHistoryArchiveService K8s历史数据归档服务。
+// 数据按类型、集群和归档日期分区，写为 gzip 压缩的 JSONL 文件：
+// {prefix}/{kind}/config_id={id}/date={YYYY-MM-DD}/{kind}-{id}-{date}-{纳秒时间戳}.jsonl.gz
+type K8sHistoryArchiveService struct {
+	sink                archive.Sink
+	prefix              string
+	batchSize           int
+	podHistoryRepo      repository.K8sPodHistoryRepository
+	nodeHistoryRepo     repository.K8sNodeHistoryRepository
+	workloadHistoryRepo repository.K8sWorkloadHistoryRepository
+}
+
+// NewK8sHistoryArchiveService 创建K8s历史数据归档服务
+func NewK8sHistoryArchiveService(
+	sink archive.Sink,
+	prefix string,
+	batchSize int,
+	podHistoryRepo repository.K8sPodHistoryRepository,
+	nodeHistoryRepo repository.K8sNodeHistoryRepository,
+	workloadHistoryRepo repository.K8sWorkloadHistoryRepository) *K8sHistoryArchiveService {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		prefix = defaultArchivePrefix
+	}
+	return &K8sHistoryArchiveService{
+		sink:                sink,
+		prefix:              prefix,
+		batchSize:           batchSize,
+		podHistoryRepo:      podHistoryRepo,
+		nodeHistoryRepo:     nodeHistoryRepo,
+		workloadHistoryRepo: workloadHistoryRepo,
+	}
+}
+
+// K8sHistoryArchiveResult 一次归档的结果
+type K8sHistoryArchiveResult struct {
+	Rows  int64                           `json:"rows"`
+	Files int                             `json:"files"`
+	Last  *repository.HistoryArchiveBound `json:"-"` // 已归档的最后一条数据，没有归档任何数据时为空
+}
+
+// Archive 归档某个集群某类历史数据中 beforeDate 之前的部分，从归档文件恢复的数据不再重复归档。
+// 中途失败时已写入的文件会保留，下次归档会再次导出这些数据，恢复时会自动去重
+func (s *K8sHistoryArchiveService) Archive(ctx context.Context, kind string, configID int64, beforeDate time.Time) (*K8sHistoryArchiveResult, error) {
+	if configID <= 0 {
+		return nil, errors.New("归档时必须指定集群")
+	}
+	w := &archivePartitionWriter{ctx: ctx, sink: s.sink, prefix: s.prefix, kind: kind, configID: configID}
+	defer w.discard()
+
+	var err error
+	switch kind {
+	case model.K8sHistoryKindPod:
+		err = s.podHistoryRepo.EachPodHistoryForArchive(ctx, configID, beforeDate, s.batchSize, func(rows []model.K8sPodHistory) error {
+			return writeArchiveRows(w, rows, func(h *model.K8sPodHistory) repository.HistoryArchiveBound {
+				return repository.HistoryArchiveBound{ArchivedAt: h.ArchivedAt, ID: h.ID}
+			})
+		})
+	case model.K8sHistoryKindNode:
+		err = s.nodeHistoryRepo.EachNodeHistoryForArchive(ctx, configID, beforeDate, s.batchSize, func(rows []model.K8sNodeHistory) error {
+			return writeArchiveRows(w, rows, func(h *model.K8sNodeHistory) repository.HistoryArchiveBound {
+				return repository.HistoryArchiveBound{ArchivedAt: h.ArchivedAt, ID: h.ID}
+			})
+		})
+	case model.K8sHistoryKindWorkload:
+		err = s.workloadHistoryRepo.EachWorkloadHistoryForArchive(ctx, configID, beforeDate, s.batchSize, func(rows []model.K8sWorkloadHistory) error {
+			return writeArchiveRows(w, rows, func(h *model.K8sWorkloadHistory) repository.HistoryArchiveBound {
+				return repository.HistoryArchiveBound{ArchivedAt: h.ArchivedAt, ID: h.ID}
+			})
+		})
+	default:
+		err = fmt.Errorf("未知的历史数据类型: %s", kind)
+	}
+	if err == nil {
+		err = w.flush()
+	}
+	result := &K8sHistoryArchiveResult{Rows: w.rows, Files: w.files}
+	if err == nil {
+		result.Last = w.last
+	}
+	return result, err
+}
+
+// writeArchiveRows 将一批历史数据写入对应日期的分区，并记录写入的最后一条数据的位置
+func writeArchiveRows[T any](w *archivePartitionWriter, rows []T, key func(*T) repository.HistoryArchiveBound) error {
+	for i := range rows {
+		bound := key(&rows[i])
+		if err := w.write(bound.ArchivedAt.Format(archiveDateLayout), &rows[i]); err != nil {
+			return err
+		}
+		w.last = &bound
+	}
+	return nil
+}
+
+// archivePartitionWriter 按日期分区写归档文件。数据按归档时间顺序到达，
+// 同一时间只有一个分区在写，日期变化时上传上一个分区
+type archivePartitionWriter struct {
+	ctx      context.Context
+	sink     archive.Sink
+	prefix   string
+	kind     string
+	configID int64
+
+	date string
+	file *os.File
+	gz   *gzip.Writer
+	enc  *json.Encoder
+
+	rows  int64
+	files int
+	last  *repository.HistoryArchiveBound // 数据按 (archived_at, id) 顺序到达，最后写入的即为最大位置
+}
+
+// write 写入一行，日期变化时切换分区
+func (w *archivePartitionWriter) write(date string, row interface{}) error {
+	if w.file != nil && date != w.date {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		file, err := os.CreateTemp("", "k8s-history-archive-*.jsonl.gz")
+		if err != nil {
+			return fmt.Errorf("创建临时归档文件失败: %v", err)
+		}
+		w.date = date
+		w.file = file
+		w.gz = gzip.NewWriter(file)
+		w.enc = json.NewEncoder(w.gz)
+	}
+	if err := w.enc.Encode(row); err != nil {
+		return fmt.Errorf("写入归档文件失败: %v", err)
+	}
+	w.rows++
+	return nil
+}
+
+// flush 上传当前分区
+func (w *archivePartitionWriter) flush() error {
+	if w.file == nil {
+		return nil
+	}
+	defer w.discard()
+
+	if err := w.gz.Close(); err != nil {
+		return fmt.Errorf("写入归档文件失败: %v", err)
+	}
+	size, err := w.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	key := archiveObjectKey(w.prefix, w.kind, w.configID, w.date, time.Now())
+	if err := w.sink.Put(w.ctx, key, w.file, size); err != nil {
+		return fmt.Errorf("上传归档文件 %s 失败: %v", key, err)
+	}
+	w.files++
+	logger.Info("已归档K8s历史数据文件: %s，%d 字节", key, size)
+	return nil
+}
+
+// discard 删除当前分区的临时文件
+func (w *archivePartitionWriter) discard() {
+	if w.file == nil {
+		return
+	}
+	w.file.Close()
+	os.Remove(w.file.Name())
+	w.file, w.gz, w.enc = nil, nil, nil
+}
+
+// archivePartitionPrefix 某类型某集群的归档路径前缀，configID 为 0 时为该类型的全部集群
+func archivePartitionPrefix(prefix, kind string, configID int64) string {
+	if configID == 0 {
+		return fmt.Sprintf("%s/%s/", prefix, kind)
+	}
+	return fmt.Sprintf("%s/%s/config_id=%d/", prefix, kind, configID)
+}
+
+// archiveObjectKey 归档文件路径，同一分区多次归档时以时间戳区分
+func archiveObjectKey(prefix, kind string, configID int64, date string, now time.Time) string {
+	return fmt.Sprintf("%sdate=%s/%s-%d-%s-%d.jsonl.gz",
+		archivePartitionPrefix(prefix, kind, configID), date, kind, configID, date, now.UnixNano())
+}
+
+// K8sHistoryArchiveFile 归档文件
+type K8sHistoryArchiveFile struct {
+	archive.Object
+	Kind     string `json:"kind"`
+	ConfigID int64  `json:"configId"`
+	Date     string `json:"date"`
+}
+
+// parseArchiveKey 从归档文件路径解析类型、集群和日期，不符合格式的路径返回 false
+func (s *K8sHistoryArchiveService) parseArchiveKey(key string) (kind string, configID int64, date string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(key, s.prefix+"/"), "/")
+	if len(parts) != 4 || !strings.HasSuffix(parts[3], ".jsonl.gz") {
+		return "", 0, "", false
+	}
+	if !strings.HasPrefix(parts[1], "config_id=") || !strings.HasPrefix(parts[2], "date=") {
+		return "", 0, "", false
+	}
+	configID, err := strconv.ParseInt(strings.TrimPrefix(parts[1], "config_id="), 10, 64)
+	if err != nil {
+		return "", 0, "", false
+	}
+	date = strings.TrimPrefix(parts[2], "date=")
+	if _, err := time.Parse(archiveDateLayout, date); err != nil {
+		return "", 0, "", false
+	}
+	return parts[0], configID, date, true
+}
+
+// ListArchives 列出归档文件，kind 为空时列出全部类型，configID 为 0 时列出全部集群，
+// startDate、endDate 为 YYYY-MM-DD 格式的日期范围，为空时不限制
+func (s *K8sHistoryArchiveService) ListArchives(ctx context.Context, kind string, configID int64, startDate, endDate string) ([]*K8sHistoryArchiveFile, error) {
+	kinds := model.K8sHistoryKinds
+	if kind != "" && kind != model.K8sHistoryKindAll {
+		if !isK8sHistoryKind(kind) {
+			return nil, fmt.Errorf("%w: 未知的历史数据类型 %s", ErrInvalidArchiveQuery, kind)
+		}
+		kinds = []string{kind}
+	}
+
+	files := make([]*K8sHistoryArchiveFile, 0)
+	for _, k := range kinds {
+		objects, err := s.sink.List(ctx, archivePartitionPrefix(s.prefix, k, configID))
+		if err != nil {
+			return nil, fmt.Errorf("列出归档文件失败: %v", err)
+		}
+		for _, object := range objects {
+			objKind, objConfigID, date, ok := s.parseArchiveKey(object.Key)
+			if !ok || objKind != k || (configID > 0 && objConfigID != configID) {
+				continue
+			}
+			if (startDate != "" && date < startDate) || (endDate != "" && date > endDate) {
+				continue
+			}
+			files = append(files, &K8sHistoryArchiveFile{Object: object, Kind: k, ConfigID: objConfigID, Date: date})
+		}
+	}
+	return files, nil
+}
+
+// K8sHistoryRestoreResult 恢复结果
+type K8sHistoryRestoreResult struct {
+	Files    int   `json:"files"`    // 读取的归档文件数
+	Rows     int64 `json:"rows"`     // 归档文件中的行数
+	Restored int64 `json:"restored"` // 实际写回的行数，已存在的记录会被跳过
+}
+
+// Restore 将某个集群在日期范围内归档的数据写回历史表，kind 为空或 all 时恢复全部类型。
+// 恢复的数据从恢复时间起重新计算保留期
+func (s *K8sHistoryArchiveService) Restore(ctx context.Context, kind string, configID int64, startDate, endDate string) (*K8sHistoryRestoreResult, error) {
+	if configID <= 0 {
+		return nil, fmt.Errorf("%w: 恢复时必须指定集群", ErrInvalidArchiveQuery)
+	}
+	start, err := time.Parse(archiveDateLayout, startDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无效的开始日期 %s", ErrInvalidArchiveQuery, startDate)
+	}
+	end, err := time.Parse(archiveDateLayout, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无效的结束日期 %s", ErrInvalidArchiveQuery, endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: 结束日期不能早于开始日期", ErrInvalidArchiveQuery)
+	}
+	if end.Sub(start) >= maxRestoreDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: 单次最多恢复 %d 天的数据", ErrInvalidArchiveQuery, maxRestoreDays)
+	}
+
+	files, err := s.ListArchives(ctx, kind, configID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &K8sHistoryRestoreResult{}
+	for _, file := range files {
+		var rows, restored int64
+		switch file.Kind {
+		case model.K8sHistoryKindPod:
+			rows, restored, err = restoreArchiveFile(ctx, s.sink, file.Key, s.batchSize, func(batch []model.K8sPodHistory) (int64, error) {
+				return s.podHistoryRepo.RestorePodHistory(ctx, configID, batch)
+			})
+		case model.K8sHistoryKindNode:
+			rows, restored, err = restoreArchiveFile(ctx, s.sink, file.Key, s.batchSize, func(batch []model.K8sNodeHistory) (int64, error) {
+				return s.nodeHistoryRepo.RestoreNodeHistory(ctx, configID, batch)
+			})
+		case model.K8sHistoryKindWorkload:
+			rows, restored, err = restoreArchiveFile(ctx, s.sink, file.Key, s.batchSize, func(batch []model.K8sWorkloadHistory) (int64, error) {
+				return s.workloadHistoryRepo.RestoreWorkloadHistory(ctx, configID, batch)
+			})
+		}
+		result.Rows += rows
+		result.Restored += restored
+		if err != nil {
+			return result, fmt.Errorf("恢复归档文件 %s 失败: %v", file.Key, err)
+		}
+		result.Files++
+	}
+	logger.Info("已从 %d 个归档文件恢复集群 %d 的历史数据 %d 行（共 %d 行）",
+		result.Files, configID, result.Restored, result.Rows)
+	return result, nil
+}
+
+// restoreArchiveFile 读取一个归档文件并分批写回，返回读取的行数和写回的行数
+func restoreArchiveFile[T any](ctx context.Context, sink archive.Sink, key string, batchSize int,
+	restore func([]T) (int64, error)) (int64, int64, error) {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	body, err := sink.Get(ctx, key)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer body.Close()
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), maxArchiveLineSize)
+
+	var rows, restored int64
+	batch := make([]T, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := restore(batch)
+		restored += n
+		batch = make([]T, 0, batchSize)
+		return err
+	}
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var row T
+		if err := json.Unmarshal(line, &row); err != nil {
+			return rows, restored, fmt.Errorf("第 %d 行格式错误: %v", rows+1, err)
+		}
+		rows++
+		batch = append(batch, row)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return rows, restored, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return rows, restored, err
+	}
+	return rows, restored, flush()
+}
+
+// isK8sHistoryKind 是否为单个历史数据类型
+func isK8sHistoryKind(kind string) bool {
+	for _, k := range model.K8sHistoryKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
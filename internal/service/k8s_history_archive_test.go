@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/archive"
+	"eden-ops/internal/repository"
+)
+
+// fakePodHistoryRepo 按归档顺序分批返回预置的Pod历史数据，并记录恢复写回的数据
+type fakePodHistoryRepo struct {
+	repository.K8sPodHistoryRepository
+	rows     []model.K8sPodHistory
+	restored []model.K8sPodHistory
+}
+
+func (r *fakePodHistoryRepo) EachPodHistoryForArchive(ctx context.Context, configID int64, beforeDate time.Time, batchSize int, fn func([]model.K8sPodHistory) error) error {
+	var batch []model.K8sPodHistory
+	for _, row := range r.rows {
+		if row.ConfigID != configID || !row.ArchivedAt.Before(beforeDate) {
+			continue
+		}
+		batch = append(batch, row)
+		if len(batch) == batchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+func (r *fakePodHistoryRepo) RestorePodHistory(ctx context.Context, configID int64, rows []model.K8sPodHistory) (int64, error) {
+	r.restored = append(r.restored, rows...)
+	return int64(len(rows)), nil
+}
+
+func testPodHistoryRows() []model.K8sPodHistory {
+	node := "node-1"
+	var rows []model.K8sPodHistory
+	for i, archivedAt := range []time.Time{
+		time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 5, 1, 23, 59, 59, 0, time.UTC),
+		time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 5, 2, 12, 30, 0, 0, time.UTC),
+		// 归档截止日期之后的数据不归档
+		time.Date(2024, 5, 3, 1, 0, 0, 0, time.UTC),
+	} {
+		rows = append(rows, model.K8sPodHistory{
+			ID:            uint(i + 1),
+			OriginalID:    uint(100 + i),
+			ConfigID:      7,
+			Name:          "web-" + string(rune('a'+i)),
+			Namespace:     "default",
+			Status:        "Running",
+			NodeName:      &node,
+			CreatedAt:     archivedAt.Add(-time.Hour),
+			UpdatedAt:     archivedAt.Add(-time.Minute),
+			ArchivedAt:    archivedAt,
+			ArchiveReason: model.ArchiveReasonSyncCleanup,
+		})
+	}
+	return rows
+}
+
+func TestK8sHistoryArchiveWriteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	sink := archive.NewLocalSink(t.TempDir())
+	repo := &fakePodHistoryRepo{rows: testPodHistoryRows()}
+	svc := NewK8sHistoryArchiveService(sink, "/history/", 2, repo, nil, nil)
+
+	result, err := svc.Archive(ctx, model.K8sHistoryKindPod, 7, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	want := repository.HistoryArchiveBound{ArchivedAt: repo.rows[4].ArchivedAt, ID: 5}
+	if result.Rows != 5 || result.Files != 2 || result.Last == nil || *result.Last != want {
+		t.Fatalf("Archive() = %+v (last %+v), want 5 rows in 2 files ending at %+v", result, result.Last, want)
+	}
+
+	// 每个日期一个 gzip 压缩的 JSONL 文件
+	files, err := svc.ListArchives(ctx, model.K8sHistoryKindPod, 7, "", "")
+	if err != nil {
+		t.Fatalf("ListArchives() error = %v", err)
+	}
+	if len(files) != 2 || files[0].Date != "2024-05-01" || files[1].Date != "2024-05-02" {
+		t.Fatalf("ListArchives() = %+v, want one file per date", files)
+	}
+	for i, file := range files {
+		if !strings.HasPrefix(file.Key, "history/pod/config_id=7/date="+file.Date+"/pod-7-"+file.Date+"-") ||
+			!strings.HasSuffix(file.Key, ".jsonl.gz") || file.ConfigID != 7 || file.Kind != model.K8sHistoryKindPod {
+			t.Fatalf("archive file = %+v", file)
+		}
+		if lines := readArchiveLines(t, sink, file.Key); len(lines) != []int{3, 2}[i] {
+			t.Fatalf("%s has %d lines, want %d", file.Key, len(lines), []int{3, 2}[i])
+		}
+	}
+
+	restore, err := svc.Restore(ctx, model.K8sHistoryKindPod, 7, "2024-05-01", "2024-05-01")
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if restore.Files != 1 || restore.Rows != 3 || restore.Restored != 3 {
+		t.Fatalf("Restore(2024-05-01) = %+v, want 3 rows from 1 file", restore)
+	}
+	if !reflect.DeepEqual(repo.restored, repo.rows[:3]) {
+		t.Fatalf("restored rows = %+v, want %+v", repo.restored, repo.rows[:3])
+	}
+
+	repo.restored = nil
+	restore, err = svc.Restore(ctx, "", 7, "2024-05-01", "2024-05-31")
+	if err != nil {
+		t.Fatalf("Restore(all kinds) error = %v", err)
+	}
+	if restore.Files != 2 || restore.Rows != 5 || !reflect.DeepEqual(repo.restored, repo.rows[:5]) {
+		t.Fatalf("Restore(2024-05) = %+v with rows %+v, want all archived rows", restore, repo.restored)
+	}
+}
+
+func TestK8sHistoryRestoreRejectsInvalidQuery(t *testing.T) {
+	svc := NewK8sHistoryArchiveService(archive.NewLocalSink(t.TempDir()), "", 100, &fakePodHistoryRepo{}, nil, nil)
+	tests := []struct {
+		configID           int64
+		startDate, endDate string
+	}{
+		{0, "2024-05-01", "2024-05-01"},
+		{7, "2024/05/01", "2024-05-01"},
+		{7, "2024-05-02", "2024-05-01"},
+		{7, "2024-05-01", "2024-06-01"},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Restore(context.Background(), model.K8sHistoryKindPod, tt.configID, tt.startDate, tt.endDate); !errors.Is(err, ErrInvalidArchiveQuery) {
+			t.Errorf("Restore(%d, %s, %s) error = %v, want ErrInvalidArchiveQuery", tt.configID, tt.startDate, tt.endDate, err)
+		}
+	}
+}
+
+// readArchiveLines 解压归档文件并逐行解析为JSON对象
+func readArchiveLines(t *testing.T, sink archive.Sink, key string) []map[string]interface{} {
+	t.Helper()
+	body, err := sink.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		t.Fatalf("%s is not gzip compressed: %v", key, err)
+	}
+	defer gz.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		line := make(map[string]interface{})
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("%s line %d is not JSON: %v", key, len(lines)+1, err)
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
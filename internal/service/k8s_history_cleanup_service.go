@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
+	"eden-ops/internal/model"
 	"eden-ops/internal/repository"
 	"eden-ops/pkg/logger"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -11,9 +15,10 @@ import (
 type K8sHistoryCleanupConfig struct {
 	Enabled         bool          `mapstructure:"enabled"`
 	CleanupEnabled  bool          `mapstructure:"cleanup_enabled"`
-	CleanupDays     int           `mapstructure:"cleanup_days"`
+	CleanupDays     int           `mapstructure:"cleanup_days"` // 没有匹配的保留策略时的保留天数，不大于 0 表示永久保留
 	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
-	BatchSize       int           `mapstructure:"batch_size"`
+	BatchSize       int           `mapstructure:"batch_size"`  // 每批删除的行数
+	BatchSleep      time.Duration `mapstructure:"batch_sleep"` // 批次之间的间隔
 }
 
 // K8sHistoryCleanupService K8s历史表清理服务
@@ -21,8 +26,15 @@ type K8sHistoryCleanupService struct {
 	podHistoryRepo      repository.K8sPodHistoryRepository
 	nodeHistoryRepo     repository.K8sNodeHistoryRepository
 	workloadHistoryRepo repository.K8sWorkloadHistoryRepository
+	statisticsRepo      repository.K8sHistoryStatisticsRepository
+	retentionRepo       repository.K8sHistoryRetentionPolicyRepository
+	configRepo          repository.K8sConfigRepository
+	changeEventRepo     repository.K8sChangeEventRepository // 变更事件按默认保留天数清理
+	archiver            *K8sHistoryArchiveService           // 为空时不归档，直接删除
 	config              K8sHistoryCleanupConfig
-	stopChan            chan struct{}
+	ctx                 context.Context // 服务停止时取消，正在进行的清理会在当前批次后停止
+	cancel              context.CancelFunc
+	mu                  sync.Mutex // 防止定时清理和手动清理同时执行
 }
 
 // NewK8sHistoryCleanupService 创建K8s历史表清理服务
@@ -30,13 +42,25 @@ func NewK8sHistoryCleanupService(
 	podHistoryRepo repository.K8sPodHistoryRepository,
 	nodeHistoryRepo repository.K8sNodeHistoryRepository,
 	workloadHistoryRepo repository.K8sWorkloadHistoryRepository,
+	statisticsRepo repository.K8sHistoryStatisticsRepository,
+	retentionRepo repository.K8sHistoryRetentionPolicyRepository,
+	configRepo repository.K8sConfigRepository,
+	changeEventRepo repository.K8sChangeEventRepository,
+	archiver *K8sHistoryArchiveService,
 	config K8sHistoryCleanupConfig) *K8sHistoryCleanupService {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &K8sHistoryCleanupService{
 		podHistoryRepo:      podHistoryRepo,
 		nodeHistoryRepo:     nodeHistoryRepo,
 		workloadHistoryRepo: workloadHistoryRepo,
+		statisticsRepo:      statisticsRepo,
+		retentionRepo:       retentionRepo,
+		configRepo:          configRepo,
+		changeEventRepo:     changeEventRepo,
+		archiver:            archiver,
 		config:              config,
-		stopChan:            make(chan struct{}),
+		ctx:                 ctx,
+		cancel:              cancel,
 	}
 }
 
@@ -47,7 +71,8 @@ func (s *K8sHistoryCleanupService) Start() {
 		return
 	}
 
-	logger.Info("启动K8s历史表清理服务，清理间隔: %v，保留天数: %d", s.config.CleanupInterval, s.config.CleanupDays)
+	logger.Info("启动K8s历史表清理服务，清理间隔: %v，默认保留天数: %d，每批 %d 行，批次间隔 %v",
+		s.config.CleanupInterval, s.config.CleanupDays, s.config.BatchSize, s.config.BatchSleep)
 
 	ticker := time.NewTicker(s.config.CleanupInterval)
 	defer ticker.Stop()
@@ -59,86 +84,331 @@ func (s *K8sHistoryCleanupService) Start() {
 		select {
 		case <-ticker.C:
 			s.cleanup()
-		case <-s.stopChan:
+		case <-s.ctx.Done():
 			logger.Info("K8s历史表清理服务已停止")
 			return
 		}
 	}
 }
 
-// Stop 停止清理服务
+// Stop 停止清理服务，并中止正在进行的清理
 func (s *K8sHistoryCleanupService) Stop() {
-	close(s.stopChan)
+	s.cancel()
 }
 
-// cleanup 执行清理操作
+// cleanupTarget 一次清理中的一项：某个集群某类历史数据在 BeforeDate 之前的部分
+type cleanupTarget struct {
+	configID      int64
+	kind          string
+	retentionDays int
+	beforeDate    time.Time
+}
+
+// cleanup 执行定时清理，按保留策略逐个集群、逐类清理
 func (s *K8sHistoryCleanupService) cleanup() {
-	beforeDate := time.Now().AddDate(0, 0, -s.config.CleanupDays)
+	now := time.Now()
+	targets, err := s.planScheduledCleanup(now)
+	if err != nil {
+		logger.Error("生成K8s历史数据清理计划失败: %v", err)
+		return
+	}
+	// 默认保留期的截止时间仅用于记录，永久保留时记录为当前时间
+	beforeDate := now
+	if s.config.CleanupDays > 0 {
+		beforeDate = now.AddDate(0, 0, -s.config.CleanupDays)
+	}
+	if _, err := s.runCleanup(model.CleanupTriggerScheduled, "", beforeDate, targets); err != nil {
+		logger.Error("K8s历史数据清理失败: %v", err)
+	}
+	s.cleanupChangeEvents(now)
+}
+
+// cleanupChangeEvents 按默认保留天数清理资源变更事件
+func (s *K8sHistoryCleanupService) cleanupChangeEvents(now time.Time) {
+	if s.changeEventRepo == nil || s.config.CleanupDays <= 0 {
+		return
+	}
+	opts := repository.BatchDeleteOptions{
+		BatchSize: s.config.BatchSize,
+		Sleep:     s.config.BatchSleep,
+	}
+	deleted, err := s.changeEventRepo.CleanupBefore(s.ctx, now.AddDate(0, 0, -s.config.CleanupDays), opts)
+	if err != nil {
+		logger.Error("清理K8s资源变更事件失败，已删除 %d 行: %v", deleted, err)
+		return
+	}
+	logger.Info("已清理K8s资源变更事件 %d 行", deleted)
+}
+
+// planScheduledCleanup 为历史表中出现的每个集群和类型确定保留天数，永久保留的不清理
+func (s *K8sHistoryCleanupService) planScheduledCleanup(now time.Time) ([]cleanupTarget, error) {
+	policies, err := s.retentionRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []cleanupTarget
+	for _, kind := range model.K8sHistoryKinds {
+		configIDs, err := s.statisticsRepo.ListConfigIDs(historyTableName(kind))
+		if err != nil {
+			return nil, err
+		}
+		for _, configID := range configIDs {
+			days := resolveRetentionDays(policies, configID, kind, s.config.CleanupDays)
+			if days <= 0 {
+				continue
+			}
+			targets = append(targets, cleanupTarget{
+				configID:      configID,
+				kind:          kind,
+				retentionDays: days,
+				beforeDate:    now.AddDate(0, 0, -days),
+			})
+		}
+	}
+	return targets, nil
+}
+
+// ManualCleanup 手动清理 beforeDate 之前归档的历史数据，configID 为 0 时逐个清理全部集群，不受保留策略限制
+func (s *K8sHistoryCleanupService) ManualCleanup(configID int64, beforeDate time.Time, operator string) (*model.K8sHistoryCleanupRun, error) {
+	targets := make([]cleanupTarget, 0, len(model.K8sHistoryKinds))
+	for _, kind := range model.K8sHistoryKinds {
+		configIDs := []int64{configID}
+		if configID == 0 {
+			var err error
+			if configIDs, err = s.statisticsRepo.ListConfigIDs(historyTableName(kind)); err != nil {
+				return nil, err
+			}
+		}
+		for _, id := range configIDs {
+			targets = append(targets, cleanupTarget{configID: id, kind: kind, beforeDate: beforeDate})
+		}
+	}
+	return s.runCleanup(model.CleanupTriggerManual, operator, beforeDate, targets)
+}
+
+// runCleanup 依次执行清理并记录本次清理。启用归档时每项先归档再删除，归档失败的项不删除。
+// 同一时间只允许一次清理，某项清理失败或服务停止时不再执行后续各项，已归档和已删除的行数仍会记录
+func (s *K8sHistoryCleanupService) runCleanup(trigger, operator string, beforeDate time.Time, targets []cleanupTarget) (*model.K8sHistoryCleanupRun, error) {
+	if !s.mu.TryLock() {
+		return nil, errors.New("历史数据清理正在进行中，请稍后再试")
+	}
+	defer s.mu.Unlock()
+
+	logger.Info("开始清理K8s历史数据，共 %d 项", len(targets))
+
+	run := &model.K8sHistoryCleanupRun{
+		Trigger:    trigger,
+		Operator:   operator,
+		BeforeDate: beforeDate,
+		Status:     model.CleanupStatusRunning,
+		Details:    make([]model.K8sHistoryCleanupDetail, 0, len(targets)),
+		StartedAt:  time.Now(),
+	}
+	// 清理记录写入失败不影响清理本身
+	recorded := true
+	if err := s.statisticsRepo.CreateCleanupRun(run); err != nil {
+		logger.Error("创建清理记录失败: %v", err)
+		recorded = false
+	}
+
+	var err error
+	for _, target := range targets {
+		var detail model.K8sHistoryCleanupDetail
+		detail, err = s.archiveAndDelete(target)
+		run.Details = append(run.Details, detail)
+		run.ArchivedRows += detail.Archived
+		run.ArchivedFiles += detail.ArchivedFiles
+		switch target.kind {
+		case model.K8sHistoryKindPod:
+			run.PodDeleted += detail.Deleted
+		case model.K8sHistoryKindNode:
+			run.NodeDeleted += detail.Deleted
+		case model.K8sHistoryKindWorkload:
+			run.WorkloadDeleted += detail.Deleted
+		}
+		if err != nil {
+			break
+		}
+	}
 
-	logger.Info("开始清理K8s历史数据，清理 %s 之前的数据", beforeDate.Format("2006-01-02 15:04:05"))
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Duration = finishedAt.Sub(run.StartedAt).Milliseconds()
+	run.TotalDeleted = run.PodDeleted + run.NodeDeleted + run.WorkloadDeleted
+	run.Status = model.CleanupStatusSuccess
+	if err != nil {
+		run.Status = model.CleanupStatusFailed
+		run.Error = err.Error()
+	}
+	if recorded {
+		if updateErr := s.statisticsRepo.UpdateCleanupRun(run); updateErr != nil {
+			logger.Error("更新清理记录失败: %v", updateErr)
+		}
+	}
 
-	// 清理Pod历史
-	if err := s.podHistoryRepo.CleanupPodHistory(beforeDate); err != nil {
-		logger.Error("清理Pod历史数据失败: %v", err)
-	} else {
-		logger.Info("Pod历史数据清理完成")
+	if err != nil {
+		return run, err
 	}
+	logger.Info("K8s历史数据清理完成，共删除 %d 行（Pod %d，Node %d，Workload %d），归档 %d 行到 %d 个文件，耗时 %dms",
+		run.TotalDeleted, run.PodDeleted, run.NodeDeleted, run.WorkloadDeleted, run.ArchivedRows, run.ArchivedFiles, run.Duration)
+	return run, nil
+}
 
-	// 清理Node历史
-	if err := s.nodeHistoryRepo.CleanupNodeHistory(beforeDate); err != nil {
-		logger.Error("清理Node历史数据失败: %v", err)
-	} else {
-		logger.Info("Node历史数据清理完成")
+// archiveAndDelete 清理一项，启用归档时先归档
+func (s *K8sHistoryCleanupService) archiveAndDelete(target cleanupTarget) (model.K8sHistoryCleanupDetail, error) {
+	detail := model.K8sHistoryCleanupDetail{
+		ConfigID:      target.configID,
+		Kind:          target.kind,
+		RetentionDays: target.retentionDays,
+		BeforeDate:    target.beforeDate,
+	}
+	opts := repository.BatchDeleteOptions{
+		BatchSize: s.config.BatchSize,
+		Sleep:     s.config.BatchSleep,
+	}
+	if s.archiver != nil {
+		archived, err := s.archiver.Archive(s.ctx, target.kind, target.configID, target.beforeDate)
+		if archived != nil {
+			detail.Archived = archived.Rows
+			detail.ArchivedFiles = archived.Files
+		}
+		if err != nil {
+			return detail, fmt.Errorf("归档集群 %d 的%s历史数据失败: %v", target.configID, target.kind, err)
+		}
+		// 只删除实际归档了的数据，归档之后同步写入的数据留到下次清理
+		opts.OnlyArchived = true
+		opts.ArchivedUpTo = archived.Last
 	}
 
-	// 清理Workload历史
-	if err := s.workloadHistoryRepo.CleanupWorkloadHistory(beforeDate); err != nil {
-		logger.Error("清理Workload历史数据失败: %v", err)
-	} else {
-		logger.Info("Workload历史数据清理完成")
+	deleted, err := s.deleteHistory(target, opts)
+	detail.Deleted = deleted
+	if err != nil {
+		return detail, fmt.Errorf("清理集群 %d 的%s历史数据失败: %v", target.configID, target.kind, err)
 	}
+	return detail, nil
+}
 
-	logger.Info("K8s历史数据清理完成")
+// deleteHistory 分批删除一项清理目标
+func (s *K8sHistoryCleanupService) deleteHistory(target cleanupTarget, opts repository.BatchDeleteOptions) (int64, error) {
+	switch target.kind {
+	case model.K8sHistoryKindPod:
+		return s.podHistoryRepo.CleanupPodHistory(s.ctx, target.configID, target.beforeDate, opts)
+	case model.K8sHistoryKindNode:
+		return s.nodeHistoryRepo.CleanupNodeHistory(s.ctx, target.configID, target.beforeDate, opts)
+	case model.K8sHistoryKindWorkload:
+		return s.workloadHistoryRepo.CleanupWorkloadHistory(s.ctx, target.configID, target.beforeDate, opts)
+	default:
+		return 0, fmt.Errorf("未知的历史数据类型: %s", target.kind)
+	}
 }
 
-// GetHistoryStatistics 获取历史数据统计
-func (s *K8sHistoryCleanupService) GetHistoryStatistics() (*K8sHistoryStatistics, error) {
-	// 这里可以添加统计逻辑，比如各个历史表的记录数量、占用空间等
-	return &K8sHistoryStatistics{
-		PodHistoryCount:      0, // 实际实现时需要查询数据库
-		NodeHistoryCount:     0,
-		WorkloadHistoryCount: 0,
-		LastCleanupTime:      time.Now(), // 实际实现时需要记录上次清理时间
-	}, nil
+// historyTableName 历史数据类型对应的表名
+func historyTableName(kind string) string {
+	switch kind {
+	case model.K8sHistoryKindPod:
+		return model.K8sPodHistory{}.TableName()
+	case model.K8sHistoryKindNode:
+		return model.K8sNodeHistory{}.TableName()
+	default:
+		return model.K8sWorkloadHistory{}.TableName()
+	}
+}
+
+// K8sHistoryTableStatistics 单个历史表的统计
+type K8sHistoryTableStatistics struct {
+	Kind  string `json:"kind"` // pod、node、workload
+	Table string `json:"table"`
+	repository.K8sHistoryTableSummary
+	Size     *repository.K8sHistoryTableSize        `json:"size"`               // 整张表的空间占用，不区分集群
+	Clusters []*repository.K8sHistoryClusterSummary `json:"clusters,omitempty"` // 统计全部集群时按集群分组
 }
 
 // K8sHistoryStatistics K8s历史数据统计
 type K8sHistoryStatistics struct {
-	PodHistoryCount      int64     `json:"pod_history_count"`
-	NodeHistoryCount     int64     `json:"node_history_count"`
-	WorkloadHistoryCount int64     `json:"workload_history_count"`
-	LastCleanupTime      time.Time `json:"last_cleanup_time"`
+	ConfigID             int64                         `json:"configId"` // 为 0 表示全部集群
+	PodHistoryCount      int64                         `json:"podHistoryCount"`
+	NodeHistoryCount     int64                         `json:"nodeHistoryCount"`
+	WorkloadHistoryCount int64                         `json:"workloadHistoryCount"`
+	TotalHistoryCount    int64                         `json:"totalHistoryCount"`
+	OldestArchivedAt     *time.Time                    `json:"oldestArchivedAt"`
+	LastArchivedAt       *time.Time                    `json:"lastArchivedAt"`
+	Tables               []*K8sHistoryTableStatistics  `json:"tables"`
+	LastCleanupTime      *time.Time                    `json:"lastCleanupTime"` // 最近一次成功清理的完成时间
+	RecentCleanups       []*model.K8sHistoryCleanupRun `json:"recentCleanups"`
 }
 
-// ManualCleanup 手动清理历史数据
-func (s *K8sHistoryCleanupService) ManualCleanup(beforeDate time.Time) error {
-	logger.Info("开始手动清理K8s历史数据，清理 %s 之前的数据", beforeDate.Format("2006-01-02 15:04:05"))
+// recentCleanupLimit 统计中返回的最近清理记录数
+const recentCleanupLimit = 10
 
-	// 清理Pod历史
-	if err := s.podHistoryRepo.CleanupPodHistory(beforeDate); err != nil {
-		return fmt.Errorf("清理Pod历史数据失败: %v", err)
+// GetHistoryStatistics 获取历史数据统计，configID 为 0 时统计全部集群并按集群分组
+func (s *K8sHistoryCleanupService) GetHistoryStatistics(configID int64) (*K8sHistoryStatistics, error) {
+	stats := &K8sHistoryStatistics{
+		ConfigID: configID,
+		Tables:   make([]*K8sHistoryTableStatistics, 0, 3),
 	}
 
-	// 清理Node历史
-	if err := s.nodeHistoryRepo.CleanupNodeHistory(beforeDate); err != nil {
-		return fmt.Errorf("清理Node历史数据失败: %v", err)
+	tables := []struct {
+		kind  string
+		table string
+		count *int64
+	}{
+		{model.K8sHistoryKindPod, historyTableName(model.K8sHistoryKindPod), &stats.PodHistoryCount},
+		{model.K8sHistoryKindNode, historyTableName(model.K8sHistoryKindNode), &stats.NodeHistoryCount},
+		{model.K8sHistoryKindWorkload, historyTableName(model.K8sHistoryKindWorkload), &stats.WorkloadHistoryCount},
 	}
+	for _, t := range tables {
+		summary, err := s.statisticsRepo.SummarizeTable(t.table, configID)
+		if err != nil {
+			return nil, fmt.Errorf("统计%s历史数据失败: %v", t.kind, err)
+		}
+		size, err := s.statisticsRepo.GetTableSize(t.table)
+		if err != nil {
+			return nil, fmt.Errorf("获取%s历史表大小失败: %v", t.kind, err)
+		}
+		tableStats := &K8sHistoryTableStatistics{
+			Kind:                   t.kind,
+			Table:                  t.table,
+			K8sHistoryTableSummary: *summary,
+			Size:                   size,
+		}
+		if configID == 0 {
+			if tableStats.Clusters, err = s.statisticsRepo.SummarizeByCluster(t.table); err != nil {
+				return nil, fmt.Errorf("按集群统计%s历史数据失败: %v", t.kind, err)
+			}
+		}
+		stats.Tables = append(stats.Tables, tableStats)
 
-	// 清理Workload历史
-	if err := s.workloadHistoryRepo.CleanupWorkloadHistory(beforeDate); err != nil {
-		return fmt.Errorf("清理Workload历史数据失败: %v", err)
+		*t.count = summary.Count
+		stats.TotalHistoryCount += summary.Count
+		if summary.OldestArchivedAt != nil && (stats.OldestArchivedAt == nil || summary.OldestArchivedAt.Before(*stats.OldestArchivedAt)) {
+			stats.OldestArchivedAt = summary.OldestArchivedAt
+		}
+		if summary.NewestArchivedAt != nil && (stats.LastArchivedAt == nil || summary.NewestArchivedAt.After(*stats.LastArchivedAt)) {
+			stats.LastArchivedAt = summary.NewestArchivedAt
+		}
 	}
 
-	logger.Info("手动清理K8s历史数据完成")
-	return nil
+	runs, err := s.statisticsRepo.ListCleanupRuns(recentCleanupLimit)
+	if err != nil {
+		return nil, fmt.Errorf("获取清理记录失败: %v", err)
+	}
+	stats.RecentCleanups = runs
+	for _, run := range runs {
+		if run.Status == model.CleanupStatusSuccess && run.FinishedAt != nil {
+			stats.LastCleanupTime = run.FinishedAt
+			break
+		}
+	}
+	return stats, nil
+}
+
+// Archiver 归档服务，未启用归档时为空
+func (s *K8sHistoryCleanupService) Archiver() *K8sHistoryArchiveService {
+	return s.archiver
+}
+
+// ListCleanupRuns 获取最近的清理记录
+func (s *K8sHistoryCleanupService) ListCleanupRuns(limit int) ([]*model.K8sHistoryCleanupRun, error) {
+	return s.statisticsRepo.ListCleanupRuns(limit)
 }
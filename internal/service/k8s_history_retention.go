@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"eden-ops/internal/model"
+)
+
+// maxRetentionDays 保留策略允许的最大保留天数
+const maxRetentionDays = 3650
+
+// K8sHistoryRetention 某个集群各类历史数据实际生效的保留天数，0 表示永久保留
+type K8sHistoryRetention struct {
+	ConfigID    int64          `json:"configId"`
+	DefaultDays int            `json:"defaultDays"` // 配置文件中的默认保留天数
+	Days        map[string]int `json:"days"`        // 历史数据类型到保留天数
+}
+
+// resolveRetentionDays 确定集群某类历史数据的保留天数，优先级依次为：
+// 该集群该类型、该集群全部类型、默认策略该类型、默认策略全部类型、配置文件中的保留天数
+func resolveRetentionDays(policies []*model.K8sHistoryRetentionPolicy, configID int64, kind string, defaultDays int) int {
+	candidates := []struct {
+		configID int64
+		kind     string
+	}{
+		{configID, kind},
+		{configID, model.K8sHistoryKindAll},
+		{0, kind},
+		{0, model.K8sHistoryKindAll},
+	}
+	for _, candidate := range candidates {
+		for _, policy := range policies {
+			if policy.ConfigID == candidate.configID && policy.Kind == candidate.kind {
+				return policy.RetentionDays
+			}
+		}
+	}
+	if defaultDays < 0 {
+		return 0
+	}
+	return defaultDays
+}
+
+// ListRetentionPolicies 获取全部保留策略
+func (s *K8sHistoryCleanupService) ListRetentionPolicies() ([]*model.K8sHistoryRetentionPolicy, error) {
+	return s.retentionRepo.List()
+}
+
+// GetRetention 获取集群各类历史数据实际生效的保留天数
+func (s *K8sHistoryCleanupService) GetRetention(configID int64) (*K8sHistoryRetention, error) {
+	policies, err := s.retentionRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	retention := &K8sHistoryRetention{
+		ConfigID:    configID,
+		DefaultDays: s.config.CleanupDays,
+		Days:        make(map[string]int, len(model.K8sHistoryKinds)),
+	}
+	for _, kind := range model.K8sHistoryKinds {
+		retention.Days[kind] = resolveRetentionDays(policies, configID, kind, s.config.CleanupDays)
+	}
+	return retention, nil
+}
+
+// CreateRetentionPolicy 创建保留策略，同一集群和类型只能有一条
+func (s *K8sHistoryCleanupService) CreateRetentionPolicy(policy *model.K8sHistoryRetentionPolicy) error {
+	if err := s.validateRetentionPolicy(policy); err != nil {
+		return err
+	}
+	if existing, err := s.retentionRepo.FindByConfigAndKind(policy.ConfigID, policy.Kind); err == nil && existing != nil {
+		return errors.New("该集群和类型的保留策略已存在")
+	}
+	return s.retentionRepo.Create(policy)
+}
+
+// UpdateRetentionPolicy 更新保留策略的保留天数和备注，集群和类型不可修改
+func (s *K8sHistoryCleanupService) UpdateRetentionPolicy(id uint, retentionDays int, remark string) (*model.K8sHistoryRetentionPolicy, error) {
+	policy, err := s.retentionRepo.Get(id)
+	if err != nil {
+		return nil, errors.New("保留策略不存在")
+	}
+	policy.RetentionDays = retentionDays
+	policy.Remark = remark
+	if err := s.validateRetentionPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := s.retentionRepo.Update(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// DeleteRetentionPolicy 删除保留策略
+func (s *K8sHistoryCleanupService) DeleteRetentionPolicy(id uint) error {
+	if _, err := s.retentionRepo.Get(id); err != nil {
+		return errors.New("保留策略不存在")
+	}
+	return s.retentionRepo.Delete(id)
+}
+
+// validateRetentionPolicy 校验保留策略
+func (s *K8sHistoryCleanupService) validateRetentionPolicy(policy *model.K8sHistoryRetentionPolicy) error {
+	switch policy.Kind {
+	case model.K8sHistoryKindPod, model.K8sHistoryKindNode, model.K8sHistoryKindWorkload, model.K8sHistoryKindAll:
+	default:
+		return fmt.Errorf("无效的历史数据类型: %s", policy.Kind)
+	}
+	if policy.RetentionDays < 0 || policy.RetentionDays > maxRetentionDays {
+		return fmt.Errorf("保留天数必须在 0 到 %d 之间，0 表示永久保留", maxRetentionDays)
+	}
+	if policy.ConfigID < 0 {
+		return errors.New("无效的集群ID")
+	}
+	if policy.ConfigID > 0 {
+		if _, err := s.configRepo.Get(policy.ConfigID); err != nil {
+			return errors.New("集群不存在")
+		}
+	}
+	policy.Remark = truncateString(policy.Remark, 255)
+	return nil
+}
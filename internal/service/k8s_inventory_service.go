@@ -0,0 +1,67 @@
+package service
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidK8sInventoryQuery 时间点清单查询参数无效
+var ErrInvalidK8sInventoryQuery = errors.New("无效的时间点查询参数")
+
+// K8sInventoryService K8s时间点清单服务接口，回答"某一时刻集群里运行着什么"
+type K8sInventoryService interface {
+	PodsAt(filter *repository.K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryPod, int64, error)
+	NodesAt(filter *repository.K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryNode, int64, error)
+	WorkloadsAt(filter *repository.K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryWorkload, int64, error)
+}
+
+// k8sInventoryService K8s时间点清单服务实现
+type k8sInventoryService struct {
+	repo repository.K8sInventoryRepository
+}
+
+// NewK8sInventoryService 创建K8s时间点清单服务
+func NewK8sInventoryService(repo repository.K8sInventoryRepository) K8sInventoryService {
+	return &k8sInventoryService{repo: repo}
+}
+
+// PodsAt 某一时间点存在的Pod
+func (s *k8sInventoryService) PodsAt(filter *repository.K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryPod, int64, error) {
+	if err := validateK8sInventoryFilter(filter); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.PodsAt(filter, page, pageSize, scope)
+}
+
+// NodesAt 某一时间点存在的节点
+func (s *k8sInventoryService) NodesAt(filter *repository.K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryNode, int64, error) {
+	if err := validateK8sInventoryFilter(filter); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.NodesAt(filter, page, pageSize, scope)
+}
+
+// WorkloadsAt 某一时间点存在的工作负载
+func (s *k8sInventoryService) WorkloadsAt(filter *repository.K8sInventoryFilter, page, pageSize int, scope *model.DataScope) ([]*model.K8sInventoryWorkload, int64, error) {
+	if err := validateK8sInventoryFilter(filter); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.WorkloadsAt(filter, page, pageSize, scope)
+}
+
+// validateK8sInventoryFilter 必须指定集群和不晚于当前的时间点
+func validateK8sInventoryFilter(filter *repository.K8sInventoryFilter) error {
+	if filter.ConfigID <= 0 {
+		return fmt.Errorf("%w: 必须指定集群", ErrInvalidK8sInventoryQuery)
+	}
+	if filter.At.IsZero() {
+		return fmt.Errorf("%w: 必须指定时间点", ErrInvalidK8sInventoryQuery)
+	}
+	if filter.At.After(time.Now()) {
+		return fmt.Errorf("%w: 时间点不能晚于当前时间", ErrInvalidK8sInventoryQuery)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// ErrInvalidLabelSelector 标签选择器语法错误
+var ErrInvalidLabelSelector = errors.New("无效的标签选择器")
+
+// parseLabelSelector 解析 Kubernetes 风格的标签选择器，如 app=foo,tier in (web,api),!canary，为空表示不过滤
+func parseLabelSelector(selector string) ([]repository.LabelRequirement, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLabelSelector, err)
+	}
+	requirements, _ := parsed.Requirements()
+
+	result := make([]repository.LabelRequirement, 0, len(requirements))
+	for _, req := range requirements {
+		requirement := repository.LabelRequirement{Key: req.Key(), Values: req.Values().List()}
+		switch req.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			requirement.Operator = repository.LabelOpIn
+		case selection.NotEquals, selection.NotIn:
+			requirement.Operator = repository.LabelOpNotIn
+		case selection.Exists:
+			requirement.Operator = repository.LabelOpExists
+		case selection.DoesNotExist:
+			requirement.Operator = repository.LabelOpDoesNotExist
+		case selection.GreaterThan:
+			requirement.Operator = repository.LabelOpGreaterThan
+		case selection.LessThan:
+			requirement.Operator = repository.LabelOpLessThan
+		default:
+			return nil, fmt.Errorf("%w: 不支持的运算符 %s", ErrInvalidLabelSelector, req.Operator())
+		}
+		result = append(result, requirement)
+	}
+	return result, nil
+}
+
+// k8sLabelRows 将对象的标签 JSON 展开为标签索引，无法解析的标签忽略
+func k8sLabelRows(configID int64, kind string, objectID int64, labelsJSON string) []*model.K8sLabel {
+	if labelsJSON == "" {
+		return nil
+	}
+	var values map[string]string
+	if err := json.Unmarshal([]byte(labelsJSON), &values); err != nil {
+		return nil
+	}
+	rows := make([]*model.K8sLabel, 0, len(values))
+	for key, value := range values {
+		rows = append(rows, &model.K8sLabel{ConfigID: configID, Kind: kind, ObjectID: objectID, LabelKey: key, LabelValue: value})
+	}
+	return rows
+}
+
+// syncK8sLabelIndex 同步成功后按实时表中的对象更新标签索引，失败只记录日志，下次同步时会再次更新
+func syncK8sLabelIndex(repo repository.K8sLabelRepository, configID int64, kind string, load func() ([]*model.K8sLabel, error)) {
+	if repo == nil {
+		return
+	}
+	rows, err := load()
+	if err == nil {
+		err = repo.Sync(configID, kind, rows)
+	}
+	if err != nil {
+		logger.Warn("更新集群 %d 的%s标签索引失败: %v", configID, kind, err)
+	}
+}
@@ -2,8 +2,12 @@ package service
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/export"
+	"eden-ops/internal/pkg/listquery"
 	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
 	"fmt"
+	"time"
 )
 
 // K8sNodeService 节点服务接口
@@ -12,7 +16,8 @@ type K8sNodeService interface {
 	Update(node *model.K8sNode) error
 	Delete(id int64) error
 	GetByID(id int64) (*model.K8sNode, error)
-	List(page, pageSize int, configID int64, name, internalIP, status string, ready *bool) ([]*model.K8sNodeResponse, int64, error)
+	List(q *listquery.Query, configID int64, name, internalIP, status string, ready *bool, labelSelector string, scope *model.DataScope) (*listquery.Result[*model.K8sNodeResponse], error)
+	Export(filter *repository.K8sNodeFilter, labelSelector string, scope *model.DataScope, fn func([]model.K8sNode) error) error
 	BatchCreateOrUpdate(nodes []model.K8sNode) error
 	SyncNodes(configID int64, nodes []model.K8sNode) error
 }
@@ -21,13 +26,17 @@ type K8sNodeService interface {
 type k8sNodeService struct {
 	repo        repository.K8sNodeRepository
 	historyRepo repository.K8sNodeHistoryRepository
+	changeRepo  repository.K8sChangeEventRepository
+	labelRepo   repository.K8sLabelRepository
 }
 
 // NewK8sNodeService 创建节点服务
-func NewK8sNodeService(repo repository.K8sNodeRepository, historyRepo repository.K8sNodeHistoryRepository) K8sNodeService {
+func NewK8sNodeService(repo repository.K8sNodeRepository, historyRepo repository.K8sNodeHistoryRepository, changeRepo repository.K8sChangeEventRepository, labelRepo repository.K8sLabelRepository) K8sNodeService {
 	return &k8sNodeService{
 		repo:        repo,
 		historyRepo: historyRepo,
+		changeRepo:  changeRepo,
+		labelRepo:   labelRepo,
 	}
 }
 
@@ -52,18 +61,28 @@ func (s *k8sNodeService) GetByID(id int64) (*model.K8sNode, error) {
 }
 
 // List 获取节点列表
-func (s *k8sNodeService) List(page, pageSize int, configID int64, name, internalIP, status string, ready *bool) ([]*model.K8sNodeResponse, int64, error) {
-	total, nodes, err := s.repo.List(page, pageSize, configID, name, internalIP, status, ready)
+func (s *k8sNodeService) List(q *listquery.Query, configID int64, name, internalIP, status string, ready *bool, labelSelector string, scope *model.DataScope) (*listquery.Result[*model.K8sNodeResponse], error) {
+	requirements, err := parseLabelSelector(labelSelector)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-
-	var result []*model.K8sNodeResponse
-	for i := range nodes {
-		result = append(result, nodes[i].ToResponse())
+	result, err := s.repo.List(q, configID, name, internalIP, status, ready, requirements, scope)
+	if err != nil {
+		return nil, err
 	}
+	return listquery.Convert(result, func(node model.K8sNode) *model.K8sNodeResponse {
+		return node.ToResponse()
+	}), nil
+}
 
-	return result, total, nil
+// Export 按列表的筛选条件分批遍历待导出的节点，最多导出 export.MaxRows 条
+func (s *k8sNodeService) Export(filter *repository.K8sNodeFilter, labelSelector string, scope *model.DataScope, fn func([]model.K8sNode) error) error {
+	requirements, err := parseLabelSelector(labelSelector)
+	if err != nil {
+		return err
+	}
+	filter.LabelSelector = requirements
+	return s.repo.Each(filter, scope, export.MaxRows, export.BatchSize, fn)
 }
 
 // BatchCreateOrUpdate 批量创建或更新节点
@@ -73,6 +92,15 @@ func (s *k8sNodeService) BatchCreateOrUpdate(nodes []model.K8sNode) error {
 
 // SyncNodes 同步Node数据（支持历史表归档）
 func (s *k8sNodeService) SyncNodes(configID int64, nodes []model.K8sNode) error {
+	// 与上次同步的状态比较，记录字段级变更
+	observedAt := time.Now()
+	var changes []*model.K8sChangeEvent
+	if previous, err := s.repo.ListByConfigID(configID); err != nil {
+		logger.Warn("获取集群 %d 上次同步的Node失败，本次不记录变更: %v", configID, err)
+	} else {
+		changes = diffK8sObjects(configID, model.K8sHistoryKindNode, nodeChangeObjects(previous), nodeChangeObjects(nodes), observedAt)
+	}
+
 	// 顺序执行，每个步骤使用独立的小事务，避免长事务
 
 	// 1. 先归档不存在的Node到历史表（独立小事务）
@@ -92,5 +120,17 @@ func (s *k8sNodeService) SyncNodes(configID int64, nodes []model.K8sNode) error
 		}
 	}
 
+	// 4. 同步成功后记录变更事件，写入失败不影响同步
+	recordK8sChanges(s.changeRepo, configID, model.K8sHistoryKindNode, changes)
+
+	// 5. 更新标签索引，供标签选择器和全局搜索使用
+	syncK8sLabelIndex(s.labelRepo, configID, model.K8sHistoryKindNode, func() ([]*model.K8sLabel, error) {
+		current, err := s.repo.ListByConfigID(configID)
+		rows := make([]*model.K8sLabel, 0)
+		for i := range current {
+			rows = append(rows, k8sLabelRows(configID, model.K8sHistoryKindNode, current[i].ID, current[i].Labels)...)
+		}
+		return rows, err
+	})
 	return nil
 }
@@ -0,0 +1,86 @@
+package service
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podSpecResources 计算Pod的有效请求和限制，按 Kubernetes 的规则统计全部容器：
+// 应用容器与边车容器（restartPolicy 为 Always 的 init 容器）累加，再与每个普通 init 容器运行时的用量取较大值。
+// 所有容器都未设置的资源返回 nil
+func podSpecResources(spec *corev1.PodSpec) (cpuRequest, cpuLimit, memoryRequest, memoryLimit *string) {
+	requests := effectivePodResources(spec, containerRequests)
+	limits := effectivePodResources(spec, func(c *corev1.Container) corev1.ResourceList { return c.Resources.Limits })
+	return quantityString(requests, corev1.ResourceCPU), quantityString(limits, corev1.ResourceCPU),
+		quantityString(requests, corev1.ResourceMemory), quantityString(limits, corev1.ResourceMemory)
+}
+
+// effectivePodResources 按有效资源规则合计Pod内全部容器的资源
+func effectivePodResources(spec *corev1.PodSpec, resourcesOf func(*corev1.Container) corev1.ResourceList) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for i := range spec.Containers {
+		addResourceList(total, resourcesOf(&spec.Containers[i]))
+	}
+
+	// init 容器按顺序启动，普通 init 容器运行时只有之前启动的边车容器同时在运行
+	sidecars := corev1.ResourceList{}
+	initMax := corev1.ResourceList{}
+	for i := range spec.InitContainers {
+		c := &spec.InitContainers[i]
+		running := sidecars.DeepCopy()
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResourceList(total, resourcesOf(c))
+			addResourceList(sidecars, resourcesOf(c))
+			running = sidecars.DeepCopy()
+		} else {
+			addResourceList(running, resourcesOf(c))
+		}
+		maxResourceList(initMax, running)
+	}
+	maxResourceList(total, initMax)
+	return total
+}
+
+// containerRequests 容器的请求，只设置了限制的资源按 Kubernetes 的默认规则以限制作为请求
+func containerRequests(c *corev1.Container) corev1.ResourceList {
+	requests := c.Resources.Requests.DeepCopy()
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	for name, limit := range c.Resources.Limits {
+		if _, ok := requests[name]; !ok {
+			requests[name] = limit.DeepCopy()
+		}
+	}
+	return requests
+}
+
+// addResourceList 将 list 累加到 total
+func addResourceList(total, list corev1.ResourceList) {
+	for name, quantity := range list {
+		if value, ok := total[name]; ok {
+			value.Add(quantity)
+			total[name] = value
+		} else {
+			total[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// maxResourceList 逐项取 total 与 list 中的较大值
+func maxResourceList(total, list corev1.ResourceList) {
+	for name, quantity := range list {
+		if value, ok := total[name]; !ok || quantity.Cmp(value) > 0 {
+			total[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// quantityString 资源量的字符串形式，未设置时为 nil
+func quantityString(list corev1.ResourceList, name corev1.ResourceName) *string {
+	quantity, ok := list[name]
+	if !ok {
+		return nil
+	}
+	value := quantity.String()
+	return &value
+}
@@ -2,8 +2,11 @@ package service
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/export"
 	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
 	"fmt"
+	"time"
 )
 
 // K8sPodService K8s Pod服务接口
@@ -13,7 +16,8 @@ type K8sPodService interface {
 	Delete(id int64) error
 	Get(id int64) (*model.K8sPod, error)
 	List(configID int64, page, pageSize int) ([]model.K8sPod, int64, error)
-	ListWithFilter(page, pageSize int, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder string, startTime, endTime *string, configId *int64) ([]*model.K8sPodResponse, int64, error)
+	ListWithFilter(page, pageSize int, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder string, startTime, endTime *string, configId *int64, labelSelector string, scope *model.DataScope) ([]*model.K8sPodResponse, int64, error)
+	Export(filter *repository.K8sPodFilter, labelSelector string, scope *model.DataScope, fn func([]model.K8sPod) error) error
 	ListByConfigID(configID int64) ([]model.K8sPod, error)
 	DeleteByConfigID(configID int64) error
 	SyncPods(configID int64, pods []model.K8sPod) error
@@ -23,13 +27,17 @@ type K8sPodService interface {
 type k8sPodService struct {
 	repo        repository.K8sPodRepository
 	historyRepo repository.K8sPodHistoryRepository
+	changeRepo  repository.K8sChangeEventRepository
+	labelRepo   repository.K8sLabelRepository
 }
 
 // NewK8sPodService 创建K8s Pod服务
-func NewK8sPodService(repo repository.K8sPodRepository, historyRepo repository.K8sPodHistoryRepository) K8sPodService {
+func NewK8sPodService(repo repository.K8sPodRepository, historyRepo repository.K8sPodHistoryRepository, changeRepo repository.K8sChangeEventRepository, labelRepo repository.K8sLabelRepository) K8sPodService {
 	return &k8sPodService{
 		repo:        repo,
 		historyRepo: historyRepo,
+		changeRepo:  changeRepo,
+		labelRepo:   labelRepo,
 	}
 }
 
@@ -63,8 +71,12 @@ func (s *k8sPodService) List(configID int64, page, pageSize int) ([]model.K8sPod
 }
 
 // ListWithFilter 获取Pod列表（支持筛选）
-func (s *k8sPodService) ListWithFilter(page, pageSize int, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder string, startTime, endTime *string, configId *int64) ([]*model.K8sPodResponse, int64, error) {
-	total, pods, err := s.repo.ListWithFilter(page, pageSize, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder, startTime, endTime, configId)
+func (s *k8sPodService) ListWithFilter(page, pageSize int, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder string, startTime, endTime *string, configId *int64, labelSelector string, scope *model.DataScope) ([]*model.K8sPodResponse, int64, error) {
+	requirements, err := parseLabelSelector(labelSelector)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, pods, err := s.repo.ListWithFilter(page, pageSize, name, namespace, workloadName, status, instanceIP, sortBy, sortOrder, startTime, endTime, configId, requirements, scope)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -78,6 +90,16 @@ func (s *k8sPodService) ListWithFilter(page, pageSize int, name, namespace, work
 	return result, total, nil
 }
 
+// Export 按列表的筛选条件分批遍历待导出的Pod，最多导出 export.MaxRows 条
+func (s *k8sPodService) Export(filter *repository.K8sPodFilter, labelSelector string, scope *model.DataScope, fn func([]model.K8sPod) error) error {
+	requirements, err := parseLabelSelector(labelSelector)
+	if err != nil {
+		return err
+	}
+	filter.LabelSelector = requirements
+	return s.repo.Each(filter, scope, export.MaxRows, export.BatchSize, fn)
+}
+
 // ListByConfigID 根据配置ID获取所有Pod
 func (s *k8sPodService) ListByConfigID(configID int64) ([]model.K8sPod, error) {
 	return s.repo.ListByConfigID(configID)
@@ -90,6 +112,15 @@ func (s *k8sPodService) DeleteByConfigID(configID int64) error {
 
 // SyncPods 同步Pod数据（支持历史表归档）
 func (s *k8sPodService) SyncPods(configID int64, pods []model.K8sPod) error {
+	// 与上次同步的状态比较，记录字段级变更
+	observedAt := time.Now()
+	var changes []*model.K8sChangeEvent
+	if previous, err := s.repo.ListByConfigID(configID); err != nil {
+		logger.Warn("获取集群 %d 上次同步的Pod失败，本次不记录变更: %v", configID, err)
+	} else {
+		changes = diffK8sObjects(configID, model.K8sHistoryKindPod, podChangeObjects(previous), podChangeObjects(pods), observedAt)
+	}
+
 	// 顺序执行，每个步骤使用独立的小事务，避免长事务
 
 	// 1. 先归档不存在的Pod到历史表（独立小事务）
@@ -109,5 +140,17 @@ func (s *k8sPodService) SyncPods(configID int64, pods []model.K8sPod) error {
 		}
 	}
 
+	// 4. 同步成功后记录变更事件，写入失败不影响同步
+	recordK8sChanges(s.changeRepo, configID, model.K8sHistoryKindPod, changes)
+
+	// 5. 更新标签索引，供标签选择器和全局搜索使用
+	syncK8sLabelIndex(s.labelRepo, configID, model.K8sHistoryKindPod, func() ([]*model.K8sLabel, error) {
+		current, err := s.repo.ListByConfigID(configID)
+		rows := make([]*model.K8sLabel, 0)
+		for i := range current {
+			rows = append(rows, k8sLabelRows(configID, model.K8sHistoryKindPod, current[i].ID, derefString(current[i].Labels))...)
+		}
+		return rows, err
+	})
 	return nil
 }
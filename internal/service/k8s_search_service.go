@@ -0,0 +1,167 @@
+package service
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 全局搜索限制
+const (
+	// DefaultK8sSearchLimit 每类资源默认返回的条数
+	DefaultK8sSearchLimit = 20
+	// MaxK8sSearchLimit 每类资源最多返回的条数
+	MaxK8sSearchLimit = 100
+	// minK8sSearchKeywordLength 关键字最少字符数，避免过短的关键字扫描全部资源
+	minK8sSearchKeywordLength = 2
+)
+
+// ErrInvalidK8sSearch 全局搜索参数无效
+var ErrInvalidK8sSearch = errors.New("无效的搜索条件")
+
+// K8sSearchService K8s资源全局搜索服务接口
+type K8sSearchService interface {
+	Search(keyword string, limit int, scope *model.DataScope) (*model.K8sSearchResult, error)
+}
+
+// k8sSearchService K8s资源全局搜索服务实现
+type k8sSearchService struct {
+	podRepo      repository.K8sPodRepository
+	workloadRepo repository.K8sWorkloadRepository
+	nodeRepo     repository.K8sNodeRepository
+	configRepo   repository.K8sConfigRepository
+}
+
+// NewK8sSearchService 创建K8s资源全局搜索服务
+func NewK8sSearchService(podRepo repository.K8sPodRepository, workloadRepo repository.K8sWorkloadRepository, nodeRepo repository.K8sNodeRepository, configRepo repository.K8sConfigRepository) K8sSearchService {
+	return &k8sSearchService{
+		podRepo:      podRepo,
+		workloadRepo: workloadRepo,
+		nodeRepo:     nodeRepo,
+		configRepo:   configRepo,
+	}
+}
+
+// k8sSearchField 参与搜索的字段
+type k8sSearchField struct {
+	name  string
+	value string
+}
+
+// Search 在数据范围内的全部集群中按名称、镜像、IP和标签搜索Pod、工作负载和节点
+func (s *k8sSearchService) Search(keyword string, limit int, scope *model.DataScope) (*model.K8sSearchResult, error) {
+	keyword = strings.TrimSpace(keyword)
+	if utf8.RuneCountInString(keyword) < minK8sSearchKeywordLength {
+		return nil, fmt.Errorf("%w: 关键字至少%d个字符", ErrInvalidK8sSearch, minK8sSearchKeywordLength)
+	}
+	if limit <= 0 {
+		limit = DefaultK8sSearchLimit
+	}
+	if limit > MaxK8sSearchLimit {
+		limit = MaxK8sSearchLimit
+	}
+
+	pods, err := s.podRepo.Search(keyword, limit, scope)
+	if err != nil {
+		return nil, err
+	}
+	workloads, err := s.workloadRepo.Search(keyword, limit, scope)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := s.nodeRepo.Search(keyword, limit, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.K8sSearchResult{
+		Keyword:   keyword,
+		Pods:      make([]*model.K8sSearchHit, 0, len(pods)),
+		Workloads: make([]*model.K8sSearchHit, 0, len(workloads)),
+		Nodes:     make([]*model.K8sSearchHit, 0, len(nodes)),
+	}
+	for _, p := range pods {
+		field, value := matchK8sSearchField(keyword, []k8sSearchField{
+			{"name", p.Name}, {"pod_ip", p.PodIP}, {"host_ip", p.HostIP}, {"instance_ip", p.InstanceIP},
+		}, derefString(p.Labels))
+		result.Pods = append(result.Pods, &model.K8sSearchHit{
+			Kind: model.K8sHistoryKindPod, ID: p.ID, ConfigID: p.ConfigID, Namespace: p.Namespace, Name: p.Name,
+			WorkloadKind: p.WorkloadKind, Status: p.Status, MatchField: field, MatchValue: value,
+		})
+	}
+	for _, w := range workloads {
+		fields := []k8sSearchField{{"name", w.Name}}
+		var images []string
+		if err := json.Unmarshal([]byte(derefString(w.Images)), &images); err == nil {
+			for _, image := range images {
+				fields = append(fields, k8sSearchField{"images", image})
+			}
+		}
+		field, value := matchK8sSearchField(keyword, fields, derefString(w.Labels))
+		result.Workloads = append(result.Workloads, &model.K8sSearchHit{
+			Kind: model.K8sHistoryKindWorkload, ID: w.ID, ConfigID: w.ConfigID, Namespace: w.Namespace, Name: w.Name,
+			WorkloadKind: w.Kind, Status: w.Status, MatchField: field, MatchValue: value,
+		})
+	}
+	for _, n := range nodes {
+		field, value := matchK8sSearchField(keyword, []k8sSearchField{
+			{"name", n.Name}, {"hostname", n.Hostname}, {"internal_ip", n.InternalIP}, {"external_ip", n.ExternalIP},
+		}, n.Labels)
+		result.Nodes = append(result.Nodes, &model.K8sSearchHit{
+			Kind: model.K8sHistoryKindNode, ID: n.ID, ConfigID: n.ConfigID, Name: n.Name,
+			Status: n.Status, MatchField: field, MatchValue: value,
+		})
+	}
+
+	s.fillConfigNames(result)
+	return result, nil
+}
+
+// matchK8sSearchField 找出命中关键字的字段，标签按 key=value 匹配，字段名为 labels.<key>
+func matchK8sSearchField(keyword string, fields []k8sSearchField, labelsJSON string) (string, string) {
+	keyword = strings.ToLower(keyword)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field.value), keyword) {
+			return field.name, field.value
+		}
+	}
+
+	var labels map[string]string
+	if labelsJSON == "" || json.Unmarshal([]byte(labelsJSON), &labels) != nil {
+		return "", ""
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.Contains(strings.ToLower(key+"="+labels[key]), keyword) {
+			return "labels." + key, labels[key]
+		}
+	}
+	return "", ""
+}
+
+// fillConfigNames 填充命中资源所属集群的名称
+func (s *k8sSearchService) fillConfigNames(result *model.K8sSearchResult) {
+	names := make(map[int64]string)
+	groups := [][]*model.K8sSearchHit{result.Pods, result.Workloads, result.Nodes}
+	for _, hits := range groups {
+		for _, hit := range hits {
+			name, ok := names[hit.ConfigID]
+			if !ok {
+				if config, err := s.configRepo.Get(hit.ConfigID); err == nil {
+					name = config.Name
+				}
+				names[hit.ConfigID] = name
+			}
+			hit.ConfigName = name
+		}
+	}
+}
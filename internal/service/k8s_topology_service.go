@@ -0,0 +1,473 @@
+package service
+
+import (
+	"context"
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// 拓扑查询限制
+const (
+	// MaxK8sTopologyPods 拓扑中最多包含的Pod数，超出时只返回部分Pod并标记 truncated
+	MaxK8sTopologyPods = 5000
+	// k8sTopologyBatchSize 从库中分批读取资源的批大小
+	k8sTopologyBatchSize = 500
+	// k8sTopologyLiveTimeout 实时查询Service、Ingress的超时时间
+	k8sTopologyLiveTimeout = 10 * time.Second
+	// k8sTopologyRestartWarning Pod重启次数达到该值时标记为 warning
+	k8sTopologyRestartWarning = 5
+)
+
+// ErrInvalidK8sTopology 拓扑查询参数无效
+var ErrInvalidK8sTopology = errors.New("无效的拓扑查询")
+
+// K8sTopologyQuery 拓扑查询条件
+type K8sTopologyQuery struct {
+	ConfigID        int64
+	Namespace       string // 为空表示整个集群
+	IncludeServices bool   // 是否实时查询Service和Ingress
+}
+
+// K8sTopologyService K8s工作负载、Pod、节点拓扑服务接口
+type K8sTopologyService interface {
+	Topology(query *K8sTopologyQuery, scope *model.DataScope) (*model.K8sTopology, error)
+}
+
+// k8sTopologyService K8s拓扑服务实现
+type k8sTopologyService struct {
+	podRepo      repository.K8sPodRepository
+	workloadRepo repository.K8sWorkloadRepository
+	nodeRepo     repository.K8sNodeRepository
+	configRepo   repository.K8sConfigRepository
+}
+
+// NewK8sTopologyService 创建K8s拓扑服务
+func NewK8sTopologyService(podRepo repository.K8sPodRepository, workloadRepo repository.K8sWorkloadRepository, nodeRepo repository.K8sNodeRepository, configRepo repository.K8sConfigRepository) K8sTopologyService {
+	return &k8sTopologyService{
+		podRepo:      podRepo,
+		workloadRepo: workloadRepo,
+		nodeRepo:     nodeRepo,
+		configRepo:   configRepo,
+	}
+}
+
+// k8sTopologyPod 拓扑中的Pod及解析后的标签
+type k8sTopologyPod struct {
+	vertex *model.K8sTopologyVertex
+	pod    *model.K8sPod
+	labels map[string]string
+}
+
+// Topology 构建集群或命名空间内 工作负载 -> Pod -> 节点 的拓扑，
+// IncludeServices 时再实时查询 Service、Ingress，按选择器和后端连接到Pod和Service
+func (s *k8sTopologyService) Topology(query *K8sTopologyQuery, scope *model.DataScope) (*model.K8sTopology, error) {
+	if query.ConfigID <= 0 {
+		return nil, fmt.Errorf("%w: 集群ID无效", ErrInvalidK8sTopology)
+	}
+	configID := query.ConfigID
+
+	topology := &model.K8sTopology{
+		ConfigID:  configID,
+		Namespace: query.Namespace,
+		Vertices:  []*model.K8sTopologyVertex{},
+		Edges:     []*model.K8sTopologyEdge{},
+		Insights:  []*model.K8sTopologyInsight{},
+	}
+
+	// 工作负载
+	workloads := make(map[string]*model.K8sTopologyVertex)
+	workloadsByID := make(map[int64]*model.K8sTopologyVertex)
+	err := s.workloadRepo.Each(&repository.K8sWorkloadFilter{ConfigID: &configID, Namespace: query.Namespace}, scope, MaxK8sTopologyPods, k8sTopologyBatchSize, func(batch []model.K8sWorkload) error {
+		for i := range batch {
+			w := &batch[i]
+			vertex := workloadVertex(w)
+			topology.Vertices = append(topology.Vertices, vertex)
+			workloads[workloadKey(w.Namespace, w.Kind, w.Name)] = vertex
+			workloadsByID[w.ID] = vertex
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Pod，多读一条用于判断是否超出上限
+	var pods []*k8sTopologyPod
+	err = s.podRepo.Each(&repository.K8sPodFilter{ConfigID: &configID, Namespace: query.Namespace}, scope, MaxK8sTopologyPods+1, k8sTopologyBatchSize, func(batch []model.K8sPod) error {
+		for i := range batch {
+			if len(pods) == MaxK8sTopologyPods {
+				topology.Truncated = true
+				return nil
+			}
+			p := &batch[i]
+			pod := &k8sTopologyPod{vertex: podVertex(p), pod: p}
+			if p.Labels != nil {
+				_ = json.Unmarshal([]byte(*p.Labels), &pod.labels)
+			}
+			pods = append(pods, pod)
+			topology.Vertices = append(topology.Vertices, pod.vertex)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 节点：查看整个集群时包含全部节点，否则只包含Pod所在的节点
+	nodes := make(map[string]*model.K8sTopologyVertex)
+	var nodeVertices []*model.K8sTopologyVertex
+	err = s.nodeRepo.Each(&repository.K8sNodeFilter{ConfigID: configID}, scope, MaxK8sTopologyPods, k8sTopologyBatchSize, func(batch []model.K8sNode) error {
+		for i := range batch {
+			vertex := nodeVertex(&batch[i])
+			nodes[vertex.Name] = vertex
+			nodeVertices = append(nodeVertices, vertex)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	usedNodes := make(map[string]bool)
+
+	// 工作负载 -> Pod -> 节点
+	workloadNodes := make(map[*model.K8sTopologyVertex]map[string]int)
+	for _, pod := range pods {
+		p := pod.pod
+		owner := workloads[workloadKey(p.Namespace, p.WorkloadKind, p.WorkloadName)]
+		if p.WorkloadID != nil && workloadsByID[*p.WorkloadID] != nil {
+			owner = workloadsByID[*p.WorkloadID]
+		}
+		if owner != nil {
+			topology.Edges = append(topology.Edges, &model.K8sTopologyEdge{Source: owner.ID, Target: pod.vertex.ID, Type: model.K8sTopologyEdgeOwns})
+		}
+		if p.NodeName == "" {
+			continue
+		}
+		node, ok := nodes[p.NodeName]
+		if !ok {
+			// 节点尚未同步或已删除
+			node = &model.K8sTopologyVertex{
+				ID: topologyVertexID(model.K8sTopologyKindNode, p.NodeName), Kind: model.K8sTopologyKindNode, Name: p.NodeName,
+				Status: "Unknown", Health: model.K8sTopologyUnknown, Reason: "节点信息未同步",
+			}
+			nodes[p.NodeName] = node
+			nodeVertices = append(nodeVertices, node)
+		}
+		usedNodes[p.NodeName] = true
+		topology.Edges = append(topology.Edges, &model.K8sTopologyEdge{Source: pod.vertex.ID, Target: node.ID, Type: model.K8sTopologyEdgeRunsOn})
+		if owner != nil {
+			if workloadNodes[owner] == nil {
+				workloadNodes[owner] = make(map[string]int)
+			}
+			workloadNodes[owner][p.NodeName]++
+		}
+	}
+
+	wholeCluster := query.Namespace == "" && scope.AllowsWholeCluster(configID)
+	sort.Slice(nodeVertices, func(i, j int) bool { return nodeVertices[i].Name < nodeVertices[j].Name })
+	for _, node := range nodeVertices {
+		if wholeCluster || usedNodes[node.Name] {
+			topology.Vertices = append(topology.Vertices, node)
+		}
+	}
+
+	// 多副本工作负载的全部Pod落在同一节点，节点故障时整个工作负载不可用
+	for _, vertex := range topology.Vertices {
+		placement := workloadNodes[vertex]
+		if vertex.Kind != model.K8sTopologyKindWorkload || len(placement) != 1 {
+			continue
+		}
+		for nodeName, count := range placement {
+			if count < 2 {
+				continue
+			}
+			topology.Insights = append(topology.Insights, &model.K8sTopologyInsight{
+				Type:     "single_node_replicas",
+				VertexID: vertex.ID,
+				Message:  fmt.Sprintf("%s/%s 的 %d 个副本都运行在节点 %s 上", vertex.Namespace, vertex.Name, count, nodeName),
+				Related:  []string{nodes[nodeName].ID},
+			})
+		}
+	}
+
+	if query.IncludeServices {
+		if err := s.addNetwork(topology, query, scope, pods); err != nil {
+			topology.Warnings = append(topology.Warnings, fmt.Sprintf("查询Service和Ingress失败: %v", err))
+		}
+	}
+	return topology, nil
+}
+
+// addNetwork 实时查询 Service、Ingress 并加入拓扑：Service 按选择器连接到Pod，Ingress 按后端连接到Service
+func (s *k8sTopologyService) addNetwork(topology *model.K8sTopology, query *K8sTopologyQuery, scope *model.DataScope, pods []*k8sTopologyPod) error {
+	config, err := s.configRepo.Get(query.ConfigID)
+	if err != nil {
+		return err
+	}
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(config.Kubeconfig))
+	if err != nil {
+		return fmt.Errorf("failed to parse kubeconfig: %v", err)
+	}
+	restConfig.Timeout = k8sTopologyLiveTimeout
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), k8sTopologyLiveTimeout)
+	defer cancel()
+	services, err := clientset.CoreV1().Services(query.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list services: %v", err)
+	}
+
+	serviceVertices := make(map[string]*model.K8sTopologyVertex)
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if !scope.AllowsNamespace(query.ConfigID, svc.Namespace) {
+			continue
+		}
+		vertex := &model.K8sTopologyVertex{
+			ID:        topologyVertexID(model.K8sTopologyKindService, svc.Namespace+"/"+svc.Name),
+			Kind:      model.K8sTopologyKindService,
+			Name:      svc.Name,
+			Namespace: svc.Namespace,
+			Status:    string(svc.Spec.Type),
+		}
+		var selected []*model.K8sTopologyVertex
+		if len(svc.Spec.Selector) > 0 {
+			for _, pod := range pods {
+				if pod.pod.Namespace == svc.Namespace && matchesSelector(pod.labels, svc.Spec.Selector) {
+					selected = append(selected, pod.vertex)
+					topology.Edges = append(topology.Edges, &model.K8sTopologyEdge{Source: vertex.ID, Target: pod.vertex.ID, Type: model.K8sTopologyEdgeSelects})
+				}
+			}
+		}
+		vertex.Health, vertex.Reason = serviceHealth(svc, selected)
+		serviceVertices[svc.Namespace+"/"+svc.Name] = vertex
+		topology.Vertices = append(topology.Vertices, vertex)
+	}
+
+	ingresses, err := clientset.NetworkingV1().Ingresses(query.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list ingresses: %v", err)
+	}
+	for i := range ingresses.Items {
+		ing := &ingresses.Items[i]
+		if !scope.AllowsNamespace(query.ConfigID, ing.Namespace) {
+			continue
+		}
+		vertex := &model.K8sTopologyVertex{
+			ID:        topologyVertexID(model.K8sTopologyKindIngress, ing.Namespace+"/"+ing.Name),
+			Kind:      model.K8sTopologyKindIngress,
+			Name:      ing.Name,
+			Namespace: ing.Namespace,
+			Status:    ingressStatus(ing),
+		}
+		var backends []*model.K8sTopologyVertex
+		var missing []string
+		for _, name := range ingressBackendServices(ing) {
+			backend, ok := serviceVertices[ing.Namespace+"/"+name]
+			if !ok {
+				missing = append(missing, name)
+				continue
+			}
+			backends = append(backends, backend)
+			topology.Edges = append(topology.Edges, &model.K8sTopologyEdge{Source: vertex.ID, Target: backend.ID, Type: model.K8sTopologyEdgeRoutes})
+		}
+		vertex.Health, vertex.Reason = ingressHealth(backends, missing)
+		topology.Vertices = append(topology.Vertices, vertex)
+	}
+	return nil
+}
+
+// workloadVertex 工作负载顶点，按就绪副本数判断健康状态
+func workloadVertex(w *model.K8sWorkload) *model.K8sTopologyVertex {
+	vertex := &model.K8sTopologyVertex{
+		ID:           topologyVertexID(model.K8sTopologyKindWorkload, fmt.Sprint(w.ID)),
+		Kind:         model.K8sTopologyKindWorkload,
+		ResourceID:   w.ID,
+		Name:         w.Name,
+		Namespace:    w.Namespace,
+		WorkloadKind: w.Kind,
+		Status:       w.Status,
+	}
+	switch {
+	case w.Replicas == 0:
+		vertex.Health, vertex.Reason = model.K8sTopologyUnknown, "副本数为0"
+	case w.ReadyReplicas >= w.Replicas:
+		vertex.Health = model.K8sTopologyHealthy
+	case w.ReadyReplicas == 0:
+		vertex.Health, vertex.Reason = model.K8sTopologyCritical, fmt.Sprintf("就绪副本 0/%d", w.Replicas)
+	default:
+		vertex.Health, vertex.Reason = model.K8sTopologyWarning, fmt.Sprintf("就绪副本 %d/%d", w.ReadyReplicas, w.Replicas)
+	}
+	return vertex
+}
+
+// podVertex Pod顶点，按状态和重启次数判断健康状态
+func podVertex(p *model.K8sPod) *model.K8sTopologyVertex {
+	vertex := &model.K8sTopologyVertex{
+		ID:           topologyVertexID(model.K8sTopologyKindPod, fmt.Sprint(p.ID)),
+		Kind:         model.K8sTopologyKindPod,
+		ResourceID:   p.ID,
+		Name:         p.Name,
+		Namespace:    p.Namespace,
+		WorkloadKind: p.WorkloadKind,
+		Status:       p.Status,
+	}
+	switch p.Status {
+	case "Running", "Succeeded":
+		vertex.Health = model.K8sTopologyHealthy
+		if p.RestartCount >= k8sTopologyRestartWarning {
+			vertex.Health, vertex.Reason = model.K8sTopologyWarning, fmt.Sprintf("重启 %d 次", p.RestartCount)
+		}
+	case "Pending":
+		vertex.Health, vertex.Reason = model.K8sTopologyWarning, p.Status
+	case "Failed", "CrashLoopBackOff", "ImagePullBackOff":
+		vertex.Health, vertex.Reason = model.K8sTopologyCritical, p.Status
+	default:
+		vertex.Health = model.K8sTopologyUnknown
+	}
+	return vertex
+}
+
+// nodeVertex 节点顶点，按就绪和可调度状态判断健康状态
+func nodeVertex(n *model.K8sNode) *model.K8sTopologyVertex {
+	vertex := &model.K8sTopologyVertex{
+		ID:         topologyVertexID(model.K8sTopologyKindNode, n.Name),
+		Kind:       model.K8sTopologyKindNode,
+		ResourceID: n.ID,
+		Name:       n.Name,
+		Status:     n.Status,
+	}
+	switch {
+	case n.Status == "NotReady":
+		vertex.Health, vertex.Reason = model.K8sTopologyCritical, "节点未就绪"
+	case !n.Ready:
+		vertex.Health = model.K8sTopologyUnknown
+	case !n.Schedulable:
+		vertex.Health, vertex.Reason = model.K8sTopologyWarning, "节点不可调度"
+	default:
+		vertex.Health = model.K8sTopologyHealthy
+	}
+	return vertex
+}
+
+// serviceHealth 按选中Pod的健康状态判断Service健康状态，没有选择器的Service无法判断
+func serviceHealth(svc *corev1.Service, selected []*model.K8sTopologyVertex) (string, string) {
+	if len(svc.Spec.Selector) == 0 {
+		return model.K8sTopologyUnknown, "没有选择器"
+	}
+	if len(selected) == 0 {
+		return model.K8sTopologyCritical, "没有匹配的Pod"
+	}
+	healthy := 0
+	for _, pod := range selected {
+		if pod.Health == model.K8sTopologyHealthy {
+			healthy++
+		}
+	}
+	switch healthy {
+	case len(selected):
+		return model.K8sTopologyHealthy, ""
+	case 0:
+		return model.K8sTopologyCritical, "匹配的Pod都不健康"
+	default:
+		return model.K8sTopologyWarning, fmt.Sprintf("健康的Pod %d/%d", healthy, len(selected))
+	}
+}
+
+// ingressHealth 取后端Service中最差的健康状态，后端Service不存在时为 critical
+func ingressHealth(backends []*model.K8sTopologyVertex, missing []string) (string, string) {
+	if len(missing) > 0 {
+		return model.K8sTopologyCritical, "后端Service不存在: " + strings.Join(missing, ", ")
+	}
+	if len(backends) == 0 {
+		return model.K8sTopologyUnknown, "没有后端Service"
+	}
+	health, reason := model.K8sTopologyHealthy, ""
+	for _, backend := range backends {
+		if topologyHealthRank(backend.Health) > topologyHealthRank(health) {
+			health, reason = backend.Health, fmt.Sprintf("后端Service %s: %s", backend.Name, backend.Reason)
+		}
+	}
+	return health, reason
+}
+
+// topologyHealthRank 健康状态的严重程度
+func topologyHealthRank(health string) int {
+	switch health {
+	case model.K8sTopologyHealthy:
+		return 0
+	case model.K8sTopologyUnknown:
+		return 1
+	case model.K8sTopologyWarning:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// ingressBackendServices Ingress 引用的后端Service名称，去重并保持出现顺序
+func ingressBackendServices(ing *networkingv1.Ingress) []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(backend *networkingv1.IngressBackend) {
+		if backend == nil || backend.Service == nil || seen[backend.Service.Name] {
+			return
+		}
+		seen[backend.Service.Name] = true
+		names = append(names, backend.Service.Name)
+	}
+	add(ing.Spec.DefaultBackend)
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for i := range rule.HTTP.Paths {
+			add(&rule.HTTP.Paths[i].Backend)
+		}
+	}
+	return names
+}
+
+// ingressStatus Ingress 的状态，已分配负载均衡地址时为 Ready
+func ingressStatus(ing *networkingv1.Ingress) string {
+	if len(ing.Status.LoadBalancer.Ingress) > 0 {
+		return "Ready"
+	}
+	return "Pending"
+}
+
+// matchesSelector 标签是否满足 Service 的等值选择器
+func matchesSelector(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// workloadKey 按命名空间、类型和名称标识工作负载，用于关联Pod
+func workloadKey(namespace, kind, name string) string {
+	return namespace + "/" + kind + "/" + name
+}
+
+// topologyVertexID 拓扑顶点ID
+func topologyVertexID(kind, key string) string {
+	return kind + ":" + key
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/export"
 	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
 	"fmt"
 	"strings"
 	"time"
@@ -15,7 +17,8 @@ type K8sWorkloadService interface {
 	Delete(id int64) error
 	Get(id int64) (*model.K8sWorkload, error)
 	List(configID int64, page, pageSize int) ([]model.K8sWorkload, int64, error)
-	ListWithFilter(page, pageSize int, name, namespace, workloadType, status, replicas, sortBy, sortOrder string, startTime, endTime *string, configId *int64) ([]*model.K8sWorkloadResponse, int64, error)
+	ListWithFilter(page, pageSize int, name, namespace, workloadType, status, replicas, sortBy, sortOrder string, startTime, endTime *string, configId *int64, labelSelector string, scope *model.DataScope) ([]*model.K8sWorkloadResponse, int64, error)
+	Export(filter *repository.K8sWorkloadFilter, labelSelector string, scope *model.DataScope, fn func([]model.K8sWorkload) error) error
 	ListByConfigID(configID int64) ([]model.K8sWorkload, error)
 	DeleteByConfigID(configID int64) error
 	SyncWorkloads(configID int64, workloads []model.K8sWorkload) error
@@ -25,13 +28,17 @@ type K8sWorkloadService interface {
 type k8sWorkloadService struct {
 	repo        repository.K8sWorkloadRepository
 	historyRepo repository.K8sWorkloadHistoryRepository
+	changeRepo  repository.K8sChangeEventRepository
+	labelRepo   repository.K8sLabelRepository
 }
 
 // NewK8sWorkloadService 创建Kubernetes工作负载服务
-func NewK8sWorkloadService(repo repository.K8sWorkloadRepository, historyRepo repository.K8sWorkloadHistoryRepository) K8sWorkloadService {
+func NewK8sWorkloadService(repo repository.K8sWorkloadRepository, historyRepo repository.K8sWorkloadHistoryRepository, changeRepo repository.K8sChangeEventRepository, labelRepo repository.K8sLabelRepository) K8sWorkloadService {
 	return &k8sWorkloadService{
 		repo:        repo,
 		historyRepo: historyRepo,
+		changeRepo:  changeRepo,
+		labelRepo:   labelRepo,
 	}
 }
 
@@ -65,8 +72,12 @@ func (s *k8sWorkloadService) List(configID int64, page, pageSize int) ([]model.K
 }
 
 // ListWithFilter 获取工作负载列表（支持筛选）
-func (s *k8sWorkloadService) ListWithFilter(page, pageSize int, name, namespace, workloadType, status, replicas, sortBy, sortOrder string, startTime, endTime *string, configId *int64) ([]*model.K8sWorkloadResponse, int64, error) {
-	total, workloads, err := s.repo.ListWithFilter(page, pageSize, name, namespace, workloadType, status, replicas, sortBy, sortOrder, startTime, endTime, configId)
+func (s *k8sWorkloadService) ListWithFilter(page, pageSize int, name, namespace, workloadType, status, replicas, sortBy, sortOrder string, startTime, endTime *string, configId *int64, labelSelector string, scope *model.DataScope) ([]*model.K8sWorkloadResponse, int64, error) {
+	requirements, err := parseLabelSelector(labelSelector)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, workloads, err := s.repo.ListWithFilter(page, pageSize, name, namespace, workloadType, status, replicas, sortBy, sortOrder, startTime, endTime, configId, requirements, scope)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -80,6 +91,16 @@ func (s *k8sWorkloadService) ListWithFilter(page, pageSize int, name, namespace,
 	return result, total, nil
 }
 
+// Export 按列表的筛选条件分批遍历待导出的工作负载，最多导出 export.MaxRows 条
+func (s *k8sWorkloadService) Export(filter *repository.K8sWorkloadFilter, labelSelector string, scope *model.DataScope, fn func([]model.K8sWorkload) error) error {
+	requirements, err := parseLabelSelector(labelSelector)
+	if err != nil {
+		return err
+	}
+	filter.LabelSelector = requirements
+	return s.repo.Each(filter, scope, export.MaxRows, export.BatchSize, fn)
+}
+
 // ListByConfigID 根据配置ID获取所有工作负载
 func (s *k8sWorkloadService) ListByConfigID(configID int64) ([]model.K8sWorkload, error) {
 	return s.repo.ListByConfigID(configID)
@@ -92,6 +113,15 @@ func (s *k8sWorkloadService) DeleteByConfigID(configID int64) error {
 
 // SyncWorkloads 同步工作负载数据（支持历史表归档）
 func (s *k8sWorkloadService) SyncWorkloads(configID int64, workloads []model.K8sWorkload) error {
+	// 与上次同步的状态比较，记录字段级变更
+	observedAt := time.Now()
+	var changes []*model.K8sChangeEvent
+	if previous, err := s.repo.ListByConfigID(configID); err != nil {
+		logger.Warn("获取集群 %d 上次同步的工作负载失败，本次不记录变更: %v", configID, err)
+	} else {
+		changes = diffK8sObjects(configID, model.K8sHistoryKindWorkload, workloadChangeObjects(previous), workloadChangeObjects(workloads), observedAt)
+	}
+
 	// 顺序执行，每个步骤使用独立的小事务，避免长事务
 
 	// 1. 先归档不存在的工作负载到历史表（独立小事务）
@@ -117,6 +147,18 @@ func (s *k8sWorkloadService) SyncWorkloads(configID int64, workloads []model.K8s
 		}
 	}
 
+	// 4. 同步成功后记录变更事件，写入失败不影响同步
+	recordK8sChanges(s.changeRepo, configID, model.K8sHistoryKindWorkload, changes)
+
+	// 5. 更新标签索引，供标签选择器和全局搜索使用
+	syncK8sLabelIndex(s.labelRepo, configID, model.K8sHistoryKindWorkload, func() ([]*model.K8sLabel, error) {
+		current, err := s.repo.ListByConfigID(configID)
+		rows := make([]*model.K8sLabel, 0)
+		for i := range current {
+			rows = append(rows, k8sLabelRows(configID, model.K8sHistoryKindWorkload, current[i].ID, derefString(current[i].Labels))...)
+		}
+		return rows, err
+	})
 	return nil
 }
 
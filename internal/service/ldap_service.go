@@ -0,0 +1,68 @@
+package service
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/ldap"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/config"
+	"eden-ops/pkg/logger"
+	"errors"
+)
+
+// LDAPService LDAP登录服务接口
+type LDAPService interface {
+	Enabled() bool
+	Login(username, password string) (*model.User, error)
+}
+
+type ldapService struct {
+	cfg           config.LDAPConfig
+	authenticator *ldap.Authenticator
+	syncer        *externalUserSyncer
+}
+
+// NewLDAPService 创建LDAP登录服务
+func NewLDAPService(cfg config.LDAPConfig, userRepo repository.UserRepository, roleRepo repository.RoleRepository, permissionService PermissionService) LDAPService {
+	return &ldapService{
+		cfg:           cfg,
+		authenticator: ldap.NewAuthenticator(cfg),
+		syncer: &externalUserSyncer{
+			userRepo:          userRepo,
+			roleRepo:          roleRepo,
+			permissionService: permissionService,
+		},
+	}
+}
+
+// Enabled 是否启用LDAP登录
+func (s *ldapService) Enabled() bool {
+	return s.cfg.Enabled && s.cfg.URL != ""
+}
+
+// Login LDAP登录，首次登录时创建用户，每次登录同步用户信息和组映射的角色
+func (s *ldapService) Login(username, password string) (*model.User, error) {
+	entry, err := s.authenticator.Authenticate(username, password)
+	if err != nil {
+		if errors.Is(err, ldap.ErrInvalidCredentials) || errors.Is(err, ldap.ErrUserNotFound) {
+			logger.Info("LDAP登录验证失败: username=%s, %v", username, err)
+			return nil, ErrInvalidCredentials
+		}
+		logger.Error("LDAP认证失败: username=%s, %v", username, err)
+		return nil, errors.New("LDAP认证服务不可用，请稍后重试")
+	}
+
+	// 组映射的键可以是组DN或CN
+	groups := make([]string, 0, len(entry.Groups)*2)
+	for _, group := range entry.Groups {
+		groups = append(groups, group, ldap.GroupName(group))
+	}
+
+	return s.syncer.sync(&externalIdentity{
+		Source:    model.UserSourceLDAP,
+		Username:  entry.Username,
+		Nickname:  entry.Nickname,
+		Email:     entry.Email,
+		Phone:     entry.Phone,
+		RoleCodes: mapRoleCodes(s.cfg.GroupRoles, s.cfg.DefaultRoles, groups),
+	})
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/ldap/ldaptest"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/config"
+)
+
+// fakeRoleRepo 按编码返回预置的启用角色
+type fakeRoleRepo struct {
+	repository.RoleRepository
+	roles []*model.Role
+}
+
+func (r *fakeRoleRepo) FindByCodes(codes []string) ([]*model.Role, error) {
+	var roles []*model.Role
+	for _, role := range r.roles {
+		for _, code := range codes {
+			if role.Code == code {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles, nil
+}
+
+func newTestLDAPService(t *testing.T, users *fakeUserRepo, perms *fakePermissionService) LDAPService {
+	t.Helper()
+	server := ldaptest.NewServer(t,
+		ldaptest.Entry{
+			DN:         "uid=alice,ou=people,dc=example,dc=com",
+			Password:   "alice-secret",
+			Attributes: map[string][]string{"uid": {"alice"}, "cn": {"Alice"}, "mail": {"alice@example.com"}},
+		},
+		ldaptest.Entry{
+			DN:         "cn=SRE,ou=groups,dc=example,dc=com",
+			Attributes: map[string][]string{"member": {"uid=alice,ou=people,dc=example,dc=com"}},
+		},
+		ldaptest.Entry{
+			DN:         "cn=dev,ou=groups,dc=example,dc=com",
+			Attributes: map[string][]string{"member": {"uid=alice,ou=people,dc=example,dc=com"}},
+		},
+	)
+	roles := &fakeRoleRepo{roles: []*model.Role{
+		{ID: 1, Code: "viewer"},
+		{ID: 2, Code: "ops"},
+		{ID: 3, Code: "developer"},
+	}}
+	return NewLDAPService(config.LDAPConfig{
+		Enabled:           true,
+		URL:               server.URL,
+		Timeout:           "5s",
+		BaseDN:            "dc=example,dc=com",
+		NicknameAttribute: "cn",
+		EmailAttribute:    "mail",
+		GroupBaseDN:       "ou=groups,dc=example,dc=com",
+		// 组映射的键可以是 CN 或完整 DN，不区分大小写；未创建的角色被忽略
+		GroupRoles: map[string][]string{
+			"sre":                                {"ops"},
+			"CN=DEV,OU=GROUPS,DC=EXAMPLE,DC=COM": {"developer", "missing"},
+			"qa":                                 {"tester"},
+		},
+		DefaultRoles: []string{"viewer"},
+	}, users, roles, perms)
+}
+
+func TestLDAPLoginProvisionsUserAndSyncsGroupRoles(t *testing.T) {
+	users := &fakeUserRepo{}
+	perms := &fakePermissionService{}
+	svc := newTestLDAPService(t, users, perms)
+
+	user, err := svc.Login("alice", "alice-secret")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if len(users.created) != 1 || user != users.created[0] {
+		t.Fatalf("created users = %v, want the logged in user", users.created)
+	}
+	if user.Source != model.UserSourceLDAP || user.Nickname != "Alice" || user.Email != "alice@example.com" {
+		t.Fatalf("user = %+v", user)
+	}
+	if want := []uint{1, 2, 3}; !reflect.DeepEqual(users.assigned[user.ID], want) {
+		t.Fatalf("assigned roles = %v, want %v", users.assigned[user.ID], want)
+	}
+	if perms.invalidated != 1 {
+		t.Fatalf("permission cache invalidated %d times, want 1", perms.invalidated)
+	}
+
+	// 再次登录时角色未变化，不重复分配也不清空缓存
+	users.assigned = nil
+	if _, err := svc.Login("alice", "alice-secret"); err != nil {
+		t.Fatalf("second Login() error = %v", err)
+	}
+	if len(users.created) != 1 || users.assigned != nil || perms.invalidated != 1 {
+		t.Fatalf("second login created %d users, assigned %v, invalidated %d times", len(users.created), users.assigned, perms.invalidated)
+	}
+}
+
+func TestLDAPLoginRejectsInvalidCredentials(t *testing.T) {
+	users := &fakeUserRepo{}
+	svc := newTestLDAPService(t, users, &fakePermissionService{})
+
+	for _, username := range []string{"alice", "bob"} {
+		if _, err := svc.Login(username, "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Login(%q) error = %v, want ErrInvalidCredentials", username, err)
+		}
+	}
+	if len(users.created) != 0 {
+		t.Fatalf("failed logins created %d users", len(users.created))
+	}
+}
+
+func TestLDAPLoginRejectsLocalUsernameConflict(t *testing.T) {
+	users := &fakeUserRepo{created: []*model.User{{ID: 1, Username: "alice", Status: 1, Source: model.UserSourceLocal}}}
+	perms := &fakePermissionService{}
+	svc := newTestLDAPService(t, users, perms)
+
+	if _, err := svc.Login("alice", "alice-secret"); err == nil {
+		t.Fatal("LDAP login took over a local user with the same username")
+	}
+	if users.assigned != nil || perms.invalidated != 0 {
+		t.Fatalf("conflicting login assigned roles %v", users.assigned)
+	}
+}
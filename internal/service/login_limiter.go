@@ -0,0 +1,177 @@
+package service
+
+import (
+	"eden-ops/pkg/config"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 登录锁定默认值
+const (
+	defaultLoginMaxFailures     = 5
+	defaultLoginIPMaxFailures   = 20
+	defaultLoginFailureWindow   = 15 * time.Minute
+	defaultLoginLockDuration    = 5 * time.Minute
+	defaultLoginMaxLockDuration = time.Hour
+	// loginLimiterPruneSize 记录数超过该值时清理过期记录
+	loginLimiterPruneSize = 10000
+)
+
+// loginAttempts 登录失败记录
+type loginAttempts struct {
+	failures    int       // 统计窗口内的失败次数
+	windowStart time.Time // 统计窗口开始时间
+	lockedUntil time.Time // 锁定截止时间
+	lockouts    int       // 连续锁定次数，用于计算退避时长
+	lastFailure time.Time
+}
+
+// loginLimiter 按用户名和IP统计登录失败次数，超过上限后锁定，同一对象再次被锁定时锁定时长翻倍
+type loginLimiter struct {
+	maxFailures     int
+	ipMaxFailures   int
+	window          time.Duration
+	lockDuration    time.Duration
+	maxLockDuration time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*loginAttempts
+}
+
+// newLoginLimiter 创建登录失败限制器
+func newLoginLimiter(cfg config.LoginLockoutConfig) *loginLimiter {
+	l := &loginLimiter{
+		maxFailures:     cfg.MaxFailures,
+		ipMaxFailures:   cfg.IPMaxFailures,
+		window:          parseDurationOrDefault(cfg.Window, defaultLoginFailureWindow),
+		lockDuration:    parseDurationOrDefault(cfg.LockDuration, defaultLoginLockDuration),
+		maxLockDuration: parseDurationOrDefault(cfg.MaxLockDuration, defaultLoginMaxLockDuration),
+		entries:         make(map[string]*loginAttempts),
+	}
+	if l.maxFailures <= 0 {
+		l.maxFailures = defaultLoginMaxFailures
+	}
+	if l.ipMaxFailures <= 0 {
+		l.ipMaxFailures = defaultLoginIPMaxFailures
+	}
+	if l.maxLockDuration < l.lockDuration {
+		l.maxLockDuration = l.lockDuration
+	}
+	return l
+}
+
+// check 返回用户名或IP的剩余锁定时长，未锁定时返回0
+func (l *loginLimiter) check(username, clientIP string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var wait time.Duration
+	for _, key := range []string{userLimiterKey(username), ipLimiterKey(clientIP)} {
+		if entry, ok := l.entries[key]; ok && now.Before(entry.lockedUntil) {
+			if d := entry.lockedUntil.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+	return wait
+}
+
+// fail 记录一次登录失败，返回本次触发的锁定时长，未触发锁定时返回0
+func (l *loginLimiter) fail(username, clientIP string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.entries) > loginLimiterPruneSize {
+		l.pruneLocked(now)
+	}
+
+	var locked time.Duration
+	if d := l.failLocked(userLimiterKey(username), l.maxFailures, now); d > locked {
+		locked = d
+	}
+	if clientIP != "" {
+		if d := l.failLocked(ipLimiterKey(clientIP), l.ipMaxFailures, now); d > locked {
+			locked = d
+		}
+	}
+	return locked
+}
+
+// succeed 登录成功后清除用户名的失败记录；IP的失败记录保留到统计窗口结束，避免通过登录自有账号重置计数
+func (l *loginLimiter) succeed(username string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, userLimiterKey(username))
+}
+
+// failLocked 累加失败次数，达到上限时锁定
+func (l *loginLimiter) failLocked(key string, maxFailures int, now time.Time) time.Duration {
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &loginAttempts{}
+		l.entries[key] = entry
+	}
+	// 长时间没有失败后重新计算退避
+	if !entry.lastFailure.IsZero() && now.Sub(entry.lastFailure) > l.maxLockDuration+l.window {
+		entry.lockouts = 0
+	}
+	if now.Sub(entry.windowStart) > l.window {
+		entry.windowStart = now
+		entry.failures = 0
+	}
+	entry.failures++
+	entry.lastFailure = now
+
+	if entry.failures < maxFailures {
+		return 0
+	}
+
+	duration := l.lockDuration
+	for i := 0; i < entry.lockouts && duration < l.maxLockDuration; i++ {
+		duration *= 2
+	}
+	if duration > l.maxLockDuration {
+		duration = l.maxLockDuration
+	}
+	entry.lockouts++
+	entry.failures = 0
+	entry.windowStart = now
+	entry.lockedUntil = now.Add(duration)
+	return duration
+}
+
+// pruneLocked 清理已不影响判断的记录
+func (l *loginLimiter) pruneLocked(now time.Time) {
+	for key, entry := range l.entries {
+		if now.After(entry.lockedUntil) && now.Sub(entry.lastFailure) > l.maxLockDuration+l.window {
+			delete(l.entries, key)
+		}
+	}
+}
+
+// userLimiterKey 用户名不区分大小写，避免通过大小写变化绕过限制
+func userLimiterKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+// ipLimiterKey IP记录键
+func ipLimiterKey(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+// lockoutMessage 锁定提示
+func lockoutMessage(wait time.Duration) string {
+	minutes := int((wait + time.Minute - 1) / time.Minute)
+	return fmt.Sprintf("登录失败次数过多，请%d分钟后重试", minutes)
+}
+
+// parseDurationOrDefault 解析时长配置，为空或无效时使用默认值
+func parseDurationOrDefault(value string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
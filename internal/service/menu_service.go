@@ -1,6 +1,10 @@
 package service
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+
 	"eden-ops/internal/model"
 	"eden-ops/internal/repository"
 )
@@ -13,27 +17,53 @@ type MenuService interface {
 	Get(id uint) (*model.Menu, error)
 	List() ([]*model.Menu, error)
 	ListByRoleID(roleID uint) ([]*model.Menu, error)
+	GetUserMenuTree(userID uint) ([]*model.MenuRoute, error)
+	ExportTree() ([]*MenuExportNode, error)
+}
+
+// MenuExportNode 导出的菜单节点，Key 为不同环境间通用的菜单标识
+type MenuExportNode struct {
+	Key       string            `json:"key"`
+	Name      string            `json:"name"`
+	Type      int               `json:"type"`
+	Path      string            `json:"path,omitempty"`
+	Component string            `json:"component,omitempty"`
+	Perms     string            `json:"perms,omitempty"`
+	Icon      string            `json:"icon,omitempty"`
+	Sort      int               `json:"sort"`
+	Status    int               `json:"status"`
+	Hidden    bool              `json:"hidden"`
+	Cache     bool              `json:"cache"`
+	Children  []*MenuExportNode `json:"children,omitempty"`
 }
 
 // MenuServiceImpl 菜单服务实现
 type MenuServiceImpl struct {
-	menuRepo repository.MenuRepository
+	menuRepo          repository.MenuRepository
+	permissionService PermissionService
 }
 
 // NewMenuService 创建菜单服务实例
-func NewMenuService(menuRepo repository.MenuRepository) MenuService {
+func NewMenuService(menuRepo repository.MenuRepository, permissionService PermissionService) MenuService {
 	return &MenuServiceImpl{
-		menuRepo: menuRepo,
+		menuRepo:          menuRepo,
+		permissionService: permissionService,
 	}
 }
 
 // Create 创建菜单
 func (s *MenuServiceImpl) Create(menu *model.Menu) error {
+	if err := s.validateParent(menu); err != nil {
+		return err
+	}
 	return s.menuRepo.Create(menu)
 }
 
 // Update 更新菜单
 func (s *MenuServiceImpl) Update(menu *model.Menu) error {
+	if err := s.validateParent(menu); err != nil {
+		return err
+	}
 	return s.menuRepo.Update(menu)
 }
 
@@ -56,3 +86,234 @@ func (s *MenuServiceImpl) List() ([]*model.Menu, error) {
 func (s *MenuServiceImpl) ListByRoleID(roleID uint) ([]*model.Menu, error) {
 	return s.menuRepo.ListByRoleID(roleID)
 }
+
+// GetUserMenuTree 获取用户可见的菜单路由树，合并用户全部已启用角色的菜单
+func (s *MenuServiceImpl) GetUserMenuTree(userID uint) ([]*model.MenuRoute, error) {
+	perms, err := s.permissionService.GetUserPermissions(userID)
+	if err != nil {
+		return nil, err
+	}
+	menus, err := s.menuRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	// 超级管理员可见全部菜单
+	granted := make(map[uint]bool)
+	if len(perms) == 1 && perms[0] == model.PermsAll {
+		for _, menu := range menus {
+			granted[menu.ID] = true
+		}
+	} else {
+		ids, err := s.menuRepo.ListIDsByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			granted[id] = true
+		}
+	}
+
+	return buildMenuRoutes(menus, granted), nil
+}
+
+// ExportTree 导出全部菜单的树形结构，包含按钮和已禁用的菜单；上级不存在的菜单作为顶级菜单导出
+func (s *MenuServiceImpl) ExportTree() ([]*MenuExportNode, error) {
+	menus, err := s.menuRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	keys := MenuKeys(menus)
+
+	nodes := make(map[uint]*MenuExportNode, len(menus))
+	for _, menu := range menus {
+		nodes[menu.ID] = &MenuExportNode{
+			Key:       keys[menu.ID],
+			Name:      menu.Name,
+			Type:      menu.Type,
+			Path:      menu.Path,
+			Component: menu.Component,
+			Perms:     menu.Perms,
+			Icon:      menu.Icon,
+			Sort:      menu.Sort,
+			Status:    menu.Status,
+			Hidden:    menu.Hidden,
+			Cache:     menu.Cache,
+		}
+	}
+
+	roots := make([]*MenuExportNode, 0)
+	for _, menu := range menus {
+		parent, ok := nodes[menu.ParentID]
+		if menu.ParentID == 0 || !ok || menu.ParentID == menu.ID {
+			roots = append(roots, nodes[menu.ID])
+			continue
+		}
+		parent.Children = append(parent.Children, nodes[menu.ID])
+	}
+	return roots, nil
+}
+
+// validateParent 校验上级菜单：必须存在、不能是按钮，且不能是菜单自身或其子菜单
+func (s *MenuServiceImpl) validateParent(menu *model.Menu) error {
+	if menu.ParentID == 0 {
+		return nil
+	}
+	if menu.ID != 0 && menu.ParentID == menu.ID {
+		return errors.New("上级菜单不能是菜单自身")
+	}
+
+	menus, err := s.menuRepo.List()
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]*model.Menu, len(menus))
+	for _, m := range menus {
+		byID[m.ID] = m
+	}
+
+	parent, ok := byID[menu.ParentID]
+	if !ok {
+		return errors.New("上级菜单不存在")
+	}
+	if parent.Type == model.MenuTypeButton {
+		return errors.New("上级菜单不能是按钮")
+	}
+
+	// 沿上级链向上查找，遇到菜单自身说明会形成循环
+	visited := make(map[uint]bool)
+	for id := menu.ParentID; id != 0; {
+		if id == menu.ID {
+			return errors.New("不能将菜单移动到其子菜单下")
+		}
+		if visited[id] {
+			return errors.New("上级菜单存在循环引用")
+		}
+		visited[id] = true
+		current, ok := byID[id]
+		if !ok {
+			break
+		}
+		id = current.ParentID
+	}
+	return nil
+}
+
+// buildMenuRoutes 由菜单列表构建路由树。已授权菜单的上级目录自动可见；
+// 按钮、已禁用菜单及其子菜单不输出，没有可见子菜单的目录不输出。menus 需已按排序字段排序
+func buildMenuRoutes(menus []*model.Menu, granted map[uint]bool) []*model.MenuRoute {
+	byID := make(map[uint]*model.Menu, len(menus))
+	for _, menu := range menus {
+		byID[menu.ID] = menu
+	}
+
+	visible := make(map[uint]bool)
+	for _, menu := range menus {
+		if !granted[menu.ID] || menu.Type == model.MenuTypeButton {
+			continue
+		}
+		// 上级链中有禁用、不存在或循环引用的菜单时整条链不可见
+		chain := make([]uint, 0, 4)
+		seen := make(map[uint]bool)
+		ok := true
+		for current := menu; ; {
+			if current.Status != 1 || seen[current.ID] {
+				ok = false
+				break
+			}
+			seen[current.ID] = true
+			chain = append(chain, current.ID)
+			if current.ParentID == 0 {
+				break
+			}
+			parent, exists := byID[current.ParentID]
+			if !exists || parent.Type == model.MenuTypeButton {
+				ok = false
+				break
+			}
+			current = parent
+		}
+		if ok {
+			for _, id := range chain {
+				visible[id] = true
+			}
+		}
+	}
+
+	children := make(map[uint][]*model.Menu)
+	for _, menu := range menus {
+		if visible[menu.ID] {
+			children[menu.ParentID] = append(children[menu.ParentID], menu)
+		}
+	}
+
+	names := make(map[string]bool)
+	var build func(parentID uint, basePath string) []*model.MenuRoute
+	build = func(parentID uint, basePath string) []*model.MenuRoute {
+		routes := make([]*model.MenuRoute, 0, len(children[parentID]))
+		for _, menu := range children[parentID] {
+			fullPath := joinRoutePath(basePath, menu.Path)
+			route := &model.MenuRoute{
+				ID:        menu.ID,
+				Path:      menu.Path,
+				Component: menu.Component,
+				Meta: model.MenuRouteMeta{
+					Title:     menu.Name,
+					Icon:      menu.Icon,
+					Hidden:    menu.Hidden,
+					KeepAlive: menu.Cache,
+					Perms:     menu.Perms,
+				},
+				Children: build(menu.ID, fullPath),
+			}
+			route.Name = uniqueRouteName(names, fullPath, menu.ID)
+
+			if menu.Type == model.MenuTypeDirectory {
+				if len(route.Children) == 0 {
+					continue
+				}
+				if route.Component == "" && parentID == 0 {
+					route.Component = "Layout"
+				}
+				route.Redirect = firstRoutePath(route.Children, fullPath)
+			}
+			routes = append(routes, route)
+		}
+		return routes
+	}
+	return build(0, "")
+}
+
+// joinRoutePath 拼接路由路径，以 / 开头的路径为绝对路径
+func joinRoutePath(basePath, path string) string {
+	if strings.HasPrefix(path, "/") || basePath == "" {
+		return "/" + strings.TrimPrefix(path, "/")
+	}
+	return strings.TrimSuffix(basePath, "/") + "/" + path
+}
+
+// firstRoutePath 目录默认跳转到第一个在侧边栏显示的子菜单
+func firstRoutePath(routes []*model.MenuRoute, basePath string) string {
+	for _, route := range routes {
+		if !route.Meta.Hidden {
+			return joinRoutePath(basePath, route.Path)
+		}
+	}
+	return ""
+}
+
+// uniqueRouteName 由完整路径生成路由名称，如 /system/user 为 SystemUser，重名时追加菜单ID
+func uniqueRouteName(names map[string]bool, fullPath string, id uint) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(fullPath, func(r rune) bool {
+		return r == '/' || r == '-' || r == '_' || r == ':'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	name := b.String()
+	if name == "" || names[name] {
+		name += "Menu" + strconv.FormatUint(uint64(id), 10)
+	}
+	names[name] = true
+	return name
+}
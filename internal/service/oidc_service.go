@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/config"
+	"eden-ops/pkg/logger"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// OIDCFlowTTL 授权流程有效期，超时需重新发起登录
+const OIDCFlowTTL = 10 * time.Minute
+
+// oidcHTTPTimeout 访问身份提供方的超时时间
+const oidcHTTPTimeout = 10 * time.Second
+
+// OIDC登录错误
+var (
+	ErrOIDCProviderNotFound = errors.New("身份提供方不存在")
+	ErrOIDCInvalidFlow      = errors.New("登录流程无效或已过期，请重新登录")
+)
+
+// OIDCProviderInfo 身份提供方信息
+type OIDCProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// OIDCService OIDC单点登录服务接口，使用授权码 + PKCE 流程
+type OIDCService interface {
+	Providers() []OIDCProviderInfo
+	BeginLogin(name string) (authURL, flowToken string, err error)
+	CompleteLogin(ctx context.Context, name, flowToken, state, code string) (*model.User, error)
+}
+
+// oidcFlowClaims 授权流程状态，签名后保存在浏览器 Cookie 中，回调时校验
+type oidcFlowClaims struct {
+	Provider string `json:"prv"`
+	State    string `json:"st"`
+	Nonce    string `json:"nn"`
+	Verifier string `json:"cv"`
+	jwt.RegisteredClaims
+}
+
+// oidcProvider 身份提供方，首次使用时执行服务发现，失败后下次请求重试
+type oidcProvider struct {
+	cfg    config.OIDCProviderConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+	verifier *oidc.IDTokenVerifier
+	oauth2   *oauth2.Config
+}
+
+type oidcService struct {
+	providers map[string]*oidcProvider
+	names     []string
+	flowKey   []byte
+	syncer    *externalUserSyncer
+}
+
+// NewOIDCService 创建OIDC单点登录服务，secret 用于派生授权流程状态的签名密钥
+func NewOIDCService(cfg config.OIDCConfig, secret string, userRepo repository.UserRepository, roleRepo repository.RoleRepository, permissionService PermissionService) OIDCService {
+	key := sha256.Sum256([]byte("eden-ops/oidc-flow:" + secret))
+	s := &oidcService{
+		providers: make(map[string]*oidcProvider),
+		flowKey:   key[:],
+		syncer: &externalUserSyncer{
+			userRepo:          userRepo,
+			roleRepo:          roleRepo,
+			permissionService: permissionService,
+		},
+	}
+
+	for _, p := range cfg.Providers {
+		if p.Name == "" || p.Issuer == "" || p.ClientID == "" {
+			logger.Warn("OIDC身份提供方配置不完整，已忽略: name=%s", p.Name)
+			continue
+		}
+		if _, ok := s.providers[p.Name]; ok {
+			logger.Warn("OIDC身份提供方重复配置，已忽略: name=%s", p.Name)
+			continue
+		}
+		s.providers[p.Name] = &oidcProvider{
+			cfg:    p,
+			client: &http.Client{Timeout: oidcHTTPTimeout},
+		}
+		s.names = append(s.names, p.Name)
+	}
+	return s
+}
+
+// Providers 获取已配置的身份提供方
+func (s *oidcService) Providers() []OIDCProviderInfo {
+	infos := make([]OIDCProviderInfo, 0, len(s.names))
+	for _, name := range s.names {
+		p := s.providers[name]
+		displayName := p.cfg.DisplayName
+		if displayName == "" {
+			displayName = name
+		}
+		infos = append(infos, OIDCProviderInfo{Name: name, DisplayName: displayName})
+	}
+	return infos
+}
+
+// BeginLogin 生成 state、nonce 和 PKCE 校验码，返回授权地址和签名后的流程状态
+func (s *oidcService) BeginLogin(name string) (string, string, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return "", "", ErrOIDCProviderNotFound
+	}
+	if err := p.init(); err != nil {
+		return "", "", err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	now := time.Now()
+	flow := jwt.NewWithClaims(jwt.SigningMethodHS256, &oidcFlowClaims{
+		Provider: name,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(OIDCFlowTTL)),
+		},
+	})
+	flowToken, err := flow.SignedString(s.flowKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := p.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	return authURL, flowToken, nil
+}
+
+// CompleteLogin 校验回调的 state，使用授权码换取令牌并校验 ID Token 及 nonce，然后同步用户
+func (s *oidcService) CompleteLogin(ctx context.Context, name, flowToken, state, code string) (*model.User, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, ErrOIDCProviderNotFound
+	}
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+
+	flow, err := s.parseFlow(flowToken)
+	if err != nil {
+		logger.Warn("OIDC回调校验失败: provider=%s, %v", name, err)
+		return nil, ErrOIDCInvalidFlow
+	}
+	if flow.Provider != name || state == "" ||
+		subtle.ConstantTimeCompare([]byte(flow.State), []byte(state)) != 1 {
+		logger.Warn("OIDC回调校验失败: provider=%s, state不匹配", name)
+		return nil, ErrOIDCInvalidFlow
+	}
+
+	ctx = oidc.ClientContext(ctx, p.client)
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		logger.Error("OIDC授权码换取令牌失败: provider=%s, %v", name, err)
+		return nil, errors.New("授权码无效或已过期，请重新登录")
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("身份提供方未返回ID Token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		logger.Error("OIDC ID Token校验失败: provider=%s, %v", name, err)
+		return nil, errors.New("ID Token校验失败")
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(flow.Nonce)) != 1 {
+		logger.Warn("OIDC ID Token nonce不匹配: provider=%s, subject=%s", name, idToken.Subject)
+		return nil, errors.New("ID Token校验失败")
+	}
+
+	claims := make(map[string]interface{})
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("解析ID Token声明失败: %v", err)
+	}
+	p.mergeUserInfo(ctx, token, idToken.Subject, claims)
+
+	cfg := p.cfg
+	username := claimString(claims, defaultString(cfg.UsernameClaim, "preferred_username"))
+	if username == "" {
+		logger.Warn("OIDC身份提供方未返回用户名: provider=%s, subject=%s, claim=%s", name, idToken.Subject, cfg.UsernameClaim)
+		return nil, errors.New("身份提供方未返回用户名")
+	}
+
+	var values []string
+	if cfg.RolesClaim != "" {
+		values = claimStrings(claims, cfg.RolesClaim)
+	}
+
+	logger.Info("OIDC登录: provider=%s, subject=%s, username=%s", name, idToken.Subject, username)
+	return s.syncer.sync(&externalIdentity{
+		Source:    model.UserSourceOIDCPrefix + name,
+		Username:  username,
+		Nickname:  claimString(claims, defaultString(cfg.NicknameClaim, "name")),
+		Email:     claimString(claims, defaultString(cfg.EmailClaim, "email")),
+		RoleCodes: mapRoleCodes(cfg.ClaimRoles, cfg.DefaultRoles, values),
+	})
+}
+
+// parseFlow 校验并解析授权流程状态
+func (s *oidcService) parseFlow(flowToken string) (*oidcFlowClaims, error) {
+	if flowToken == "" {
+		return nil, errors.New("缺少登录流程状态")
+	}
+	claims := &oidcFlowClaims{}
+	_, err := jwt.ParseWithClaims(flowToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("不支持的签名算法: %v", token.Header["alg"])
+		}
+		return s.flowKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// init 执行服务发现并初始化 ID Token 校验器，JWKS 由校验器缓存并在出现未知密钥时刷新
+func (p *oidcProvider) init() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider != nil {
+		return nil
+	}
+
+	// 服务发现上下文会被 JWKS 拉取复用，不能使用请求上下文
+	ctx := oidc.ClientContext(context.Background(), p.client)
+	provider, err := oidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		logger.Error("OIDC服务发现失败: provider=%s, issuer=%s, %v", p.cfg.Name, p.cfg.Issuer, err)
+		return errors.New("身份提供方暂不可用，请稍后重试")
+	}
+
+	scopes := []string{oidc.ScopeOpenID}
+	for _, scope := range p.cfg.Scopes {
+		if scope != oidc.ScopeOpenID {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	p.provider = provider
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID})
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+	return nil
+}
+
+// mergeUserInfo ID Token 缺少用户名或角色声明时，从 UserInfo 端点补充
+func (p *oidcProvider) mergeUserInfo(ctx context.Context, token *oauth2.Token, subject string, claims map[string]interface{}) {
+	usernameClaim := defaultString(p.cfg.UsernameClaim, "preferred_username")
+	if claimString(claims, usernameClaim) != "" && (p.cfg.RolesClaim == "" || claimValue(claims, p.cfg.RolesClaim) != nil) {
+		return
+	}
+
+	userInfo, err := p.provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+	if err != nil {
+		logger.Warn("获取OIDC UserInfo失败: provider=%s, %v", p.cfg.Name, err)
+		return
+	}
+	if userInfo.Subject != subject {
+		logger.Warn("OIDC UserInfo主体与ID Token不一致: provider=%s", p.cfg.Name)
+		return
+	}
+	extra := make(map[string]interface{})
+	if err := userInfo.Claims(&extra); err != nil {
+		return
+	}
+	for key, value := range extra {
+		if _, ok := claims[key]; !ok {
+			claims[key] = value
+		}
+	}
+}
+
+// claimValue 按 a.b 路径获取声明
+func claimValue(claims map[string]interface{}, path string) interface{} {
+	var current interface{} = claims
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+// claimString 获取字符串声明
+func claimString(claims map[string]interface{}, path string) string {
+	value, _ := claimValue(claims, path).(string)
+	return value
+}
+
+// claimStrings 获取字符串或字符串数组声明
+func claimStrings(claims map[string]interface{}, path string) []string {
+	switch value := claimValue(claims, path).(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if str, ok := v.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// defaultString 为空时返回默认值
+func defaultString(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// randomToken 生成随机的 state 或 nonce
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
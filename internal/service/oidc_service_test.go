@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"eden-ops/internal/model"
+	"eden-ops/pkg/config"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testOIDCClientID = "eden-ops"
+	testOIDCKeyID    = "key-1"
+	testRedirectURL  = "https://ops.example.com/api/v1/oidc/mock/callback"
+)
+
+// mockGrant 授权端点签发的授权码，记录 PKCE 挑战和 ID Token 内容
+type mockGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+	key       *rsa.PrivateKey
+}
+
+// mockIdP 模拟身份提供方，提供服务发现、JWKS、令牌和 UserInfo 端点
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	issued int
+	grants map[string]*mockGrant
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, grants: make(map[string]*mockGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"issuer":                                idp.server.URL,
+			"authorization_endpoint":                idp.server.URL + "/authorize",
+			"token_endpoint":                        idp.server.URL + "/token",
+			"jwks_uri":                              idp.server.URL + "/jwks",
+			"userinfo_endpoint":                     idp.server.URL + "/userinfo",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testOIDCKeyID,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"sub": "user-1"})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize 模拟用户在身份提供方完成认证：校验授权请求参数并签发授权码，
+// modify 可修改将要签发的 ID Token 声明或签名密钥
+func (idp *mockIdP) authorize(t *testing.T, authURL string, modify func(*mockGrant)) (state, code string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Path != "/authorize" || q.Get("response_type") != "code" || q.Get("client_id") != testOIDCClientID ||
+		q.Get("redirect_uri") != testRedirectURL || q.Get("code_challenge_method") != "S256" ||
+		q.Get("code_challenge") == "" || q.Get("state") == "" || q.Get("nonce") == "" {
+		t.Fatalf("unexpected authorization request %s", authURL)
+	}
+
+	now := time.Now()
+	grant := &mockGrant{
+		challenge: q.Get("code_challenge"),
+		key:       idp.key,
+		claims: jwt.MapClaims{
+			"iss":                idp.server.URL,
+			"aud":                testOIDCClientID,
+			"sub":                "user-1",
+			"iat":                now.Unix(),
+			"exp":                now.Add(time.Minute).Unix(),
+			"nonce":              q.Get("nonce"),
+			"preferred_username": "alice",
+			"name":               "Alice",
+			"email":              "alice@example.com",
+			"groups":             []string{"sre", "qa"},
+		},
+	}
+	if modify != nil {
+		modify(grant)
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.issued++
+	code = "code-" + strconv.Itoa(idp.issued)
+	idp.grants[code] = grant
+	return q.Get("state"), code
+}
+
+// token 授权码只能使用一次，code_verifier 必须与授权请求中的 S256 挑战匹配
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	idp.mu.Lock()
+	grant, ok := idp.grants[r.PostForm.Get("code")]
+	delete(idp.grants, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, grant.claims)
+	idToken.Header["kid"] = testOIDCKeyID
+	signed, err := idToken.SignedString(grant.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func newTestOIDCService(t *testing.T, idp *mockIdP, users *fakeUserRepo, perms *fakePermissionService) OIDCService {
+	t.Helper()
+	roles := &fakeRoleRepo{roles: []*model.Role{{ID: 1, Code: "viewer"}, {ID: 2, Code: "ops"}}}
+	return NewOIDCService(config.OIDCConfig{Providers: []config.OIDCProviderConfig{{
+		Name:         "mock",
+		Issuer:       idp.server.URL,
+		ClientID:     testOIDCClientID,
+		RedirectURL:  testRedirectURL,
+		RolesClaim:   "groups",
+		ClaimRoles:   map[string][]string{"SRE": {"ops"}},
+		DefaultRoles: []string{"viewer"},
+	}}}, "test-secret", users, roles, perms)
+}
+
+func TestOIDCLoginProvisionsUser(t *testing.T) {
+	idp := newMockIdP(t)
+	users := &fakeUserRepo{}
+	perms := &fakePermissionService{}
+	svc := newTestOIDCService(t, idp, users, perms)
+
+	authURL, flowToken, err := svc.BeginLogin("mock")
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+	state, code := idp.authorize(t, authURL, nil)
+
+	user, err := svc.CompleteLogin(context.Background(), "mock", flowToken, state, code)
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if user.Username != "alice" || user.Source != model.UserSourceOIDCPrefix+"mock" ||
+		user.Nickname != "Alice" || user.Email != "alice@example.com" {
+		t.Fatalf("user = %+v", user)
+	}
+	if want := []uint{1, 2}; !reflect.DeepEqual(users.assigned[user.ID], want) {
+		t.Fatalf("assigned roles = %v, want %v", users.assigned[user.ID], want)
+	}
+	if perms.invalidated != 1 {
+		t.Fatalf("permission cache invalidated %d times, want 1", perms.invalidated)
+	}
+}
+
+func TestOIDCLoginRejectsInvalidFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	users := &fakeUserRepo{}
+	svc := newTestOIDCService(t, idp, users, &fakePermissionService{})
+
+	authURL, flowToken, err := svc.BeginLogin("mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, code := idp.authorize(t, authURL, nil)
+
+	// 其他密钥签名的流程状态
+	other := NewOIDCService(config.OIDCConfig{}, "other-secret", nil, nil, nil).(*oidcService)
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &oidcFlowClaims{Provider: "mock", State: state}).SignedString(other.flowKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, flowToken, state string
+	}{
+		{"state mismatch", flowToken, state + "x"},
+		{"empty state", flowToken, ""},
+		{"missing flow", "", state},
+		{"tampered flow", flowToken + "x", state},
+		{"forged flow", forged, state},
+	}
+	for _, tt := range tests {
+		if _, err := svc.CompleteLogin(context.Background(), "mock", tt.flowToken, tt.state, code); !errors.Is(err, ErrOIDCInvalidFlow) {
+			t.Errorf("%s: CompleteLogin() error = %v, want ErrOIDCInvalidFlow", tt.name, err)
+		}
+	}
+	if _, err := svc.CompleteLogin(context.Background(), "unknown", flowToken, state, code); !errors.Is(err, ErrOIDCProviderNotFound) {
+		t.Errorf("unknown provider: CompleteLogin() error = %v, want ErrOIDCProviderNotFound", err)
+	}
+	if len(users.created) != 0 {
+		t.Fatalf("invalid flows created %d users", len(users.created))
+	}
+}
+
+func TestOIDCLoginRequiresMatchingPKCEVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	users := &fakeUserRepo{}
+	svc := newTestOIDCService(t, idp, users, &fakePermissionService{})
+
+	authURL, _, err := svc.BeginLogin("mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, code := idp.authorize(t, authURL, nil)
+
+	// 另一个流程的 state 与流程状态一致，但校验码与授权码的挑战不匹配
+	otherURL, otherFlow, err := svc.BeginLogin("mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherState := mustQuery(t, otherURL).Get("state")
+
+	if _, err := svc.CompleteLogin(context.Background(), "mock", otherFlow, otherState, code); err == nil {
+		t.Fatal("CompleteLogin() with another flow's code verifier succeeded")
+	}
+	if len(users.created) != 0 {
+		t.Fatalf("PKCE failure created %d users", len(users.created))
+	}
+}
+
+func TestOIDCLoginVerifiesIDToken(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		modify func(*mockGrant)
+	}{
+		{"nonce mismatch", func(g *mockGrant) { g.claims["nonce"] = "other-nonce" }},
+		{"missing nonce", func(g *mockGrant) { delete(g.claims, "nonce") }},
+		{"wrong audience", func(g *mockGrant) { g.claims["aud"] = "other-client" }},
+		{"wrong issuer", func(g *mockGrant) { g.claims["iss"] = "https://evil.example.com" }},
+		{"expired", func(g *mockGrant) { g.claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"unknown signing key", func(g *mockGrant) { g.key = otherKey }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			users := &fakeUserRepo{}
+			svc := newTestOIDCService(t, idp, users, &fakePermissionService{})
+
+			authURL, flowToken, err := svc.BeginLogin("mock")
+			if err != nil {
+				t.Fatal(err)
+			}
+			state, code := idp.authorize(t, authURL, tt.modify)
+			if _, err := svc.CompleteLogin(context.Background(), "mock", flowToken, state, code); err == nil {
+				t.Fatal("CompleteLogin() accepted an invalid ID token")
+			}
+			if len(users.created) != 0 {
+				t.Fatalf("invalid ID token created %d users", len(users.created))
+			}
+		})
+	}
+}
+
+func mustQuery(t *testing.T, rawURL string) url.Values {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query()
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"eden-ops/pkg/config"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 密码长度限制，bcrypt 只使用前 72 个字节
+const (
+	defaultPasswordMinLength = 8
+	passwordMaxBytes         = 72
+)
+
+// passwordPolicy 本地用户密码策略
+type passwordPolicy struct {
+	cfg config.PasswordPolicyConfig
+}
+
+// newPasswordPolicy 创建密码策略
+func newPasswordPolicy(cfg config.PasswordPolicyConfig) *passwordPolicy {
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = defaultPasswordMinLength
+	}
+	return &passwordPolicy{cfg: cfg}
+}
+
+// validate 校验密码是否符合策略
+func (p *passwordPolicy) validate(username, password string) error {
+	if utf8.RuneCountInString(password) < p.cfg.MinLength {
+		return fmt.Errorf("密码长度不能少于%d个字符", p.cfg.MinLength)
+	}
+	if len(password) > passwordMaxBytes {
+		return fmt.Errorf("密码长度不能超过%d个字节", passwordMaxBytes)
+	}
+	if username != "" && strings.EqualFold(password, username) {
+		return errors.New("密码不能与用户名相同")
+	}
+
+	var upper, lower, digit, special bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsSpace(r):
+		default:
+			special = true
+		}
+	}
+
+	var missing []string
+	if p.cfg.RequireUpper && !upper {
+		missing = append(missing, "大写字母")
+	}
+	if p.cfg.RequireLower && !lower {
+		missing = append(missing, "小写字母")
+	}
+	if p.cfg.RequireDigit && !digit {
+		missing = append(missing, "数字")
+	}
+	if p.cfg.RequireSpecial && !special {
+		missing = append(missing, "特殊字符")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("密码必须包含%s", strings.Join(missing, "、"))
+	}
+	return nil
+}
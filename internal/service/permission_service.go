@@ -0,0 +1,297 @@
+package service
+
+import (
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// permissionCacheTTL 用户权限缓存有效期，角色、菜单变更时会主动失效
+const permissionCacheTTL = time.Minute
+
+// selfServiceRoutes 登录用户操作自己账号的接口，不需要配置权限映射；其余未配置权限映射的接口仅管理员可访问
+var selfServiceRoutes = map[string]bool{
+	"GET /api/v1/users/info":                      true,
+	"GET /api/v1/users/info/permissions":          true,
+	"GET /api/v1/users/info/menus":                true,
+	"POST /api/v1/logout":                         true,
+	"POST /api/v1/logout/all":                     true,
+	"PUT /api/v1/users/info/password":             true,
+	"GET /api/v1/users/info/totp":                 true,
+	"POST /api/v1/users/info/totp/setup":          true,
+	"POST /api/v1/users/info/totp/enable":         true,
+	"POST /api/v1/users/info/totp/disable":        true,
+	"POST /api/v1/users/info/totp/recovery-codes": true,
+	"GET /api/v1/api-tokens":                      true,
+	"POST /api/v1/api-tokens":                     true,
+	"DELETE /api/v1/api-tokens/:id":               true,
+}
+
+// PermissionService 权限服务接口
+type PermissionService interface {
+	GetUserPermissions(userID uint) ([]string, error)
+	Authorize(userID uint, method, path string) (bool, error)
+	AuthorizeScopes(userID uint, scopes []string, method, path string) (bool, error)
+	GetDataScope(userID uint) (*model.DataScope, error)
+	ListAPIPermissions() ([]*model.APIPermission, error)
+	UnmappedRoutes(routes []string) ([]string, error)
+	InvalidateAll()
+}
+
+// userPermissions 缓存的用户权限
+type userPermissions struct {
+	enabled  bool
+	admin    bool
+	perms    map[string]struct{}
+	scope    *model.DataScope
+	expireAt time.Time
+}
+
+type permissionService struct {
+	repo repository.PermissionRepository
+
+	mu       sync.RWMutex
+	users    map[uint]*userPermissions
+	routes   map[string][]string // method + " " + path -> 权限标识
+	routesAt time.Time
+}
+
+// NewPermissionService 创建权限服务
+func NewPermissionService(repo repository.PermissionRepository) PermissionService {
+	return &permissionService{
+		repo:  repo,
+		users: make(map[uint]*userPermissions),
+	}
+}
+
+// GetUserPermissions 获取用户拥有的权限标识，管理员返回 *:*:*
+func (s *permissionService) GetUserPermissions(userID uint) ([]string, error) {
+	up, err := s.loadUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !up.enabled {
+		return []string{}, nil
+	}
+	if up.admin {
+		return []string{model.PermsAll}, nil
+	}
+
+	perms := make([]string, 0, len(up.perms))
+	for perm := range up.perms {
+		perms = append(perms, perm)
+	}
+	sort.Strings(perms)
+	return perms, nil
+}
+
+// Authorize 判断用户是否有权访问接口；未配置权限映射的接口只允许访问自己账号的接口，其余仅管理员可访问
+func (s *permissionService) Authorize(userID uint, method, path string) (bool, error) {
+	up, err := s.loadUser(userID)
+	if err != nil {
+		return false, err
+	}
+	if !up.enabled {
+		return false, nil
+	}
+	if up.admin {
+		return true, nil
+	}
+
+	required, err := s.routePerms(method, path)
+	if err != nil {
+		return false, err
+	}
+	if len(required) == 0 {
+		return selfServiceRoutes[method+" "+path], nil
+	}
+	for _, perm := range required {
+		if _, ok := up.perms[perm]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AuthorizeScopes 判断API令牌是否有权访问接口，令牌的有效权限为令牌权限与用户权限的交集；
+// 未配置权限映射的接口只允许只读访问自己账号的接口，除非令牌拥有 *:*:*
+func (s *permissionService) AuthorizeScopes(userID uint, scopes []string, method, path string) (bool, error) {
+	for _, scope := range scopes {
+		if scope == model.PermsAll {
+			return s.Authorize(userID, method, path)
+		}
+	}
+
+	up, err := s.loadUser(userID)
+	if err != nil {
+		return false, err
+	}
+	if !up.enabled {
+		return false, nil
+	}
+
+	required, err := s.routePerms(method, path)
+	if err != nil {
+		return false, err
+	}
+	if len(required) == 0 {
+		return selfServiceRoutes[method+" "+path] && (method == http.MethodGet || method == http.MethodHead), nil
+	}
+	for _, perm := range required {
+		for _, scope := range scopes {
+			if scope != perm {
+				continue
+			}
+			if _, ok := up.perms[perm]; ok || up.admin {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// GetDataScope 获取用户的有效数据范围；管理员或拥有全部数据范围角色的用户不受限制
+func (s *permissionService) GetDataScope(userID uint) (*model.DataScope, error) {
+	up, err := s.loadUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return up.scope, nil
+}
+
+// ListAPIPermissions 获取接口权限映射
+func (s *permissionService) ListAPIPermissions() ([]*model.APIPermission, error) {
+	return s.repo.ListAPIPermissions()
+}
+
+// UnmappedRoutes 返回 routes（method + " " + path）中既未配置权限映射、也不是自身账号接口的路由，这些接口仅管理员可访问
+func (s *permissionService) UnmappedRoutes(routes []string) ([]string, error) {
+	var unmapped []string
+	for _, route := range routes {
+		if selfServiceRoutes[route] {
+			continue
+		}
+		method, path, _ := strings.Cut(route, " ")
+		required, err := s.routePerms(method, path)
+		if err != nil {
+			return nil, err
+		}
+		if len(required) == 0 {
+			unmapped = append(unmapped, route)
+		}
+	}
+	return unmapped, nil
+}
+
+// InvalidateAll 清空权限缓存
+func (s *permissionService) InvalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = make(map[uint]*userPermissions)
+	s.routes = nil
+}
+
+// loadUser 获取用户权限，优先使用缓存
+func (s *permissionService) loadUser(userID uint) (*userPermissions, error) {
+	now := time.Now()
+	s.mu.RLock()
+	up, ok := s.users[userID]
+	s.mu.RUnlock()
+	if ok && now.Before(up.expireAt) {
+		return up, nil
+	}
+
+	up = &userPermissions{
+		perms:    make(map[string]struct{}),
+		scope:    model.NewDataScope(),
+		expireAt: now.Add(permissionCacheTTL),
+	}
+	status, err := s.repo.GetUserStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+	up.enabled = status == 1
+	if up.enabled {
+		roles, err := s.repo.ListUserRoles(userID)
+		if err != nil {
+			return nil, err
+		}
+		var customRoleIDs []uint
+		for _, role := range roles {
+			switch {
+			case role.Code == model.RoleCodeAdmin:
+				up.admin = true
+				up.scope.All = true
+			case role.DataScope == model.RoleDataScopeCustom:
+				customRoleIDs = append(customRoleIDs, role.ID)
+			default:
+				up.scope.All = true
+			}
+		}
+
+		if !up.scope.All {
+			if err := s.loadDataScope(up.scope, customRoleIDs); err != nil {
+				return nil, err
+			}
+		}
+
+		perms, err := s.repo.ListUserPerms(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, perm := range perms {
+			up.perms[perm] = struct{}{}
+		}
+	}
+
+	s.mu.Lock()
+	s.users[userID] = up
+	s.mu.Unlock()
+	return up, nil
+}
+
+// loadDataScope 按角色合并自定义数据范围规则
+func (s *permissionService) loadDataScope(scope *model.DataScope, roleIDs []uint) error {
+	rules, err := s.repo.ListRoleDataScopes(roleIDs)
+	if err != nil {
+		return err
+	}
+	byRole := make(map[uint][]*model.RoleDataScope, len(roleIDs))
+	for _, rule := range rules {
+		byRole[rule.RoleID] = append(byRole[rule.RoleID], rule)
+	}
+	for _, roleID := range roleIDs {
+		scope.Merge(byRole[roleID])
+	}
+	return nil
+}
+
+// routePerms 获取接口所需的权限标识
+func (s *permissionService) routePerms(method, path string) ([]string, error) {
+	now := time.Now()
+	s.mu.RLock()
+	routes, loadedAt := s.routes, s.routesAt
+	s.mu.RUnlock()
+
+	if routes == nil || now.Sub(loadedAt) > permissionCacheTTL {
+		permissions, err := s.repo.ListAPIPermissions()
+		if err != nil {
+			return nil, err
+		}
+		routes = make(map[string][]string, len(permissions))
+		for _, p := range permissions {
+			key := p.Method + " " + p.Path
+			routes[key] = append(routes[key], p.Perms)
+		}
+
+		s.mu.Lock()
+		s.routes, s.routesAt = routes, now
+		s.mu.Unlock()
+	}
+
+	return routes[method+" "+path], nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"eden-ops/internal/model"
+)
+
+// RolePermissionSet 角色的权限集合。菜单以权限标识表示，没有权限标识的目录和菜单以完整路由路径表示，
+// 因此不同环境中菜单ID不同也可以比较；数据范围规则格式为 类型:资源ID:模式
+type RolePermissionSet struct {
+	Name           string   `json:"name"`
+	Code           string   `json:"code"`
+	Status         int      `json:"status"`
+	Remark         string   `json:"remark"`
+	DataScope      string   `json:"dataScope"`
+	Menus          []string `json:"menus"`
+	DataScopeRules []string `json:"dataScopeRules"`
+}
+
+// RoleDiff 角色权限差异，Added 表示目标角色有而源角色没有，Removed 反之
+type RoleDiff struct {
+	Source           string   `json:"source"`
+	Target           string   `json:"target"`
+	Identical        bool     `json:"identical"`
+	MenusAdded       []string `json:"menusAdded"`
+	MenusRemoved     []string `json:"menusRemoved"`
+	SourceDataScope  string   `json:"sourceDataScope"`
+	TargetDataScope  string   `json:"targetDataScope"`
+	DataScopeChanged bool     `json:"dataScopeChanged"`
+	RulesAdded       []string `json:"rulesAdded"`
+	RulesRemoved     []string `json:"rulesRemoved"`
+}
+
+// Clone 以已有角色为模板创建新角色，复制菜单权限和数据范围
+func (s *RoleServiceImpl) Clone(sourceID uint, role *model.Role) error {
+	if role.Name == "" || role.Code == "" {
+		return errors.New("角色名称和编码不能为空")
+	}
+	if role.Code == model.RoleCodeAdmin {
+		return errors.New("不能使用系统管理员角色编码")
+	}
+	return s.roleRepo.Clone(sourceID, role)
+}
+
+// GetPermissionSet 获取角色的权限集合
+func (s *RoleServiceImpl) GetPermissionSet(id uint) (*RolePermissionSet, error) {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.menuKeys()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.roleRepo.ListDataScopes(id)
+	if err != nil {
+		return nil, err
+	}
+	return buildRolePermissionSet(role, keys, rules), nil
+}
+
+// ExportPermissionSets 导出全部角色的权限集合
+func (s *RoleServiceImpl) ExportPermissionSets() ([]*RolePermissionSet, error) {
+	roles, err := s.roleRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.menuKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]*RolePermissionSet, 0, len(roles))
+	for _, role := range roles {
+		rules, err := s.roleRepo.ListDataScopes(role.ID)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, buildRolePermissionSet(role, keys, rules))
+	}
+	return sets, nil
+}
+
+// menuKeys 计算全部菜单在不同环境间通用的标识
+func (s *RoleServiceImpl) menuKeys() (map[uint]string, error) {
+	menus, err := s.menuRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	return MenuKeys(menus), nil
+}
+
+// MenuKeys 菜单ID到通用标识的映射：有权限标识时使用权限标识，否则使用完整路由路径，路径为空时使用名称
+func MenuKeys(menus []*model.Menu) map[uint]string {
+	byID := make(map[uint]*model.Menu, len(menus))
+	for _, menu := range menus {
+		byID[menu.ID] = menu
+	}
+
+	keys := make(map[uint]string, len(menus))
+	for _, menu := range menus {
+		if menu.Perms != "" {
+			keys[menu.ID] = menu.Perms
+			continue
+		}
+		// 由下向上拼接路径，防止循环引用时死循环
+		var parts []string
+		seen := make(map[uint]bool)
+		for current := menu; current != nil && !seen[current.ID]; current = byID[current.ParentID] {
+			seen[current.ID] = true
+			part := strings.Trim(current.Path, "/")
+			if part == "" {
+				part = current.Name
+			}
+			parts = append([]string{part}, parts...)
+			if strings.HasPrefix(current.Path, "/") || current.ParentID == 0 {
+				break
+			}
+		}
+		keys[menu.ID] = "/" + strings.Join(parts, "/")
+	}
+	return keys
+}
+
+// buildRolePermissionSet 构建角色权限集合，菜单和规则均已排序去重
+func buildRolePermissionSet(role *model.Role, keys map[uint]string, rules []*model.RoleDataScope) *RolePermissionSet {
+	set := &RolePermissionSet{
+		Name:           role.Name,
+		Code:           role.Code,
+		Status:         role.Status,
+		Remark:         role.Remark,
+		DataScope:      role.DataScope,
+		Menus:          make([]string, 0, len(role.Menus)),
+		DataScopeRules: make([]string, 0, len(rules)),
+	}
+	for _, menu := range role.Menus {
+		if key, ok := keys[menu.ID]; ok {
+			set.Menus = append(set.Menus, key)
+		}
+	}
+	if role.DataScope == model.RoleDataScopeCustom {
+		for _, rule := range rules {
+			set.DataScopeRules = append(set.DataScopeRules, fmt.Sprintf("%s:%s:%s", rule.ScopeType, strconv.FormatInt(rule.ResourceID, 10), rule.Pattern))
+		}
+	}
+	set.Menus = sortedUnique(set.Menus)
+	set.DataScopeRules = sortedUnique(set.DataScopeRules)
+	return set
+}
+
+// DiffRolePermissionSets 比较两个角色的权限集合
+func DiffRolePermissionSets(source, target *RolePermissionSet) *RoleDiff {
+	diff := &RoleDiff{
+		Source:          source.Code,
+		Target:          target.Code,
+		SourceDataScope: normalizeDataScope(source.DataScope),
+		TargetDataScope: normalizeDataScope(target.DataScope),
+	}
+	diff.MenusAdded, diff.MenusRemoved = diffStrings(source.Menus, target.Menus)
+	diff.RulesAdded, diff.RulesRemoved = diffStrings(source.DataScopeRules, target.DataScopeRules)
+	diff.DataScopeChanged = diff.SourceDataScope != diff.TargetDataScope
+	diff.Identical = !diff.DataScopeChanged && len(diff.MenusAdded) == 0 && len(diff.MenusRemoved) == 0 &&
+		len(diff.RulesAdded) == 0 && len(diff.RulesRemoved) == 0
+	return diff
+}
+
+// normalizeDataScope 未设置数据范围视为全部数据
+func normalizeDataScope(scope string) string {
+	if scope == "" {
+		return model.RoleDataScopeAll
+	}
+	return scope
+}
+
+// diffStrings 返回 target 中新增和缺少的元素，结果已排序
+func diffStrings(source, target []string) (added, removed []string) {
+	sourceSet := make(map[string]bool, len(source))
+	for _, v := range source {
+		sourceSet[v] = true
+	}
+	targetSet := make(map[string]bool, len(target))
+	for _, v := range target {
+		targetSet[v] = true
+		if !sourceSet[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range source {
+		if !targetSet[v] {
+			removed = append(removed, v)
+		}
+	}
+	return sortedUnique(added), sortedUnique(removed)
+}
+
+// sortedUnique 排序并去重，nil 返回空切片
+func sortedUnique(values []string) []string {
+	result := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
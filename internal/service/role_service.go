@@ -3,6 +3,7 @@ package service
 import (
 	"eden-ops/internal/model"
 	"eden-ops/internal/repository"
+	"fmt"
 )
 
 // RoleService 角色服务接口
@@ -14,17 +15,24 @@ type RoleService interface {
 	List(page, pageSize int) ([]*model.Role, int64, error)
 	AssignMenus(roleID uint, menuIDs []uint) error
 	AssignUserRoles(userID uint, roleIDs []uint) error
+	ListDataScopes(roleID uint) ([]*model.RoleDataScope, error)
+	AssignDataScopes(roleID uint, dataScope string, rules []*model.RoleDataScope) error
+	Clone(sourceID uint, role *model.Role) error
+	GetPermissionSet(id uint) (*RolePermissionSet, error)
+	ExportPermissionSets() ([]*RolePermissionSet, error)
 }
 
 // RoleServiceImpl 角色服务实现
 type RoleServiceImpl struct {
 	roleRepo repository.RoleRepository
+	menuRepo repository.MenuRepository
 }
 
 // NewRoleService 创建角色服务实例
-func NewRoleService(roleRepo repository.RoleRepository) RoleService {
+func NewRoleService(roleRepo repository.RoleRepository, menuRepo repository.MenuRepository) RoleService {
 	return &RoleServiceImpl{
 		roleRepo: roleRepo,
+		menuRepo: menuRepo,
 	}
 }
 
@@ -62,3 +70,47 @@ func (s *RoleServiceImpl) AssignMenus(roleID uint, menuIDs []uint) error {
 func (s *RoleServiceImpl) AssignUserRoles(userID uint, roleIDs []uint) error {
 	return s.roleRepo.AssignUserRoles(userID, roleIDs)
 }
+
+// ListDataScopes 获取角色数据范围规则
+func (s *RoleServiceImpl) ListDataScopes(roleID uint) ([]*model.RoleDataScope, error) {
+	return s.roleRepo.ListDataScopes(roleID)
+}
+
+// AssignDataScopes 设置角色数据范围，全部数据范围时忽略规则
+func (s *RoleServiceImpl) AssignDataScopes(roleID uint, dataScope string, rules []*model.RoleDataScope) error {
+	switch dataScope {
+	case model.RoleDataScopeAll:
+		rules = nil
+	case model.RoleDataScopeCustom:
+		for _, rule := range rules {
+			if err := validateDataScopeRule(rule); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("不支持的数据范围: %s", dataScope)
+	}
+	return s.roleRepo.AssignDataScopes(roleID, dataScope, rules)
+}
+
+// validateDataScopeRule 校验数据范围规则
+func validateDataScopeRule(rule *model.RoleDataScope) error {
+	switch rule.ScopeType {
+	case model.DataScopeTypeNamespace:
+		if rule.Pattern == "" {
+			return fmt.Errorf("命名空间规则必须指定模式")
+		}
+		if !model.ValidNamespacePattern(rule.Pattern) {
+			return fmt.Errorf("无效的命名空间模式: %s，只支持 * 和 ? 通配符", rule.Pattern)
+		}
+		return nil
+	case model.DataScopeTypeCluster, model.DataScopeTypeServer, model.DataScopeTypeDatabase, model.DataScopeTypeCloudAccount:
+		if rule.ResourceID <= 0 {
+			return fmt.Errorf("%s 规则必须指定资源ID", rule.ScopeType)
+		}
+		rule.Pattern = ""
+		return nil
+	default:
+		return fmt.Errorf("不支持的数据范围类型: %s", rule.ScopeType)
+	}
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/export"
+	"eden-ops/internal/pkg/terminal"
 	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
+	"fmt"
 )
 
 // ServerConfigService 服务器配置服务接口
@@ -11,7 +15,8 @@ type ServerConfigService interface {
 	Update(config *model.ServerConfig) error
 	Delete(id uint) error
 	Get(id uint) (*model.ServerConfig, error)
-	List(page, pageSize int, name string) (int64, []model.ServerConfig, error)
+	List(page, pageSize int, name string, scope *model.DataScope) (int64, []model.ServerConfig, error)
+	Export(name string, scope *model.DataScope, fn func([]model.ServerConfig) error) error
 	TestConnection(config *model.ServerConfig) error
 }
 
@@ -26,12 +31,20 @@ func NewServerConfigService(repo *repository.ServerConfigRepository) ServerConfi
 }
 
 // List 获取服务器配置列表
-func (s *serverConfigService) List(page, pageSize int, name string) (int64, []model.ServerConfig, error) {
-	return s.repo.List(page, pageSize, name)
+func (s *serverConfigService) List(page, pageSize int, name string, scope *model.DataScope) (int64, []model.ServerConfig, error) {
+	return s.repo.List(page, pageSize, name, scope)
+}
+
+// Export 分批遍历待导出的服务器配置，最多导出 export.MaxRows 条
+func (s *serverConfigService) Export(name string, scope *model.DataScope, fn func([]model.ServerConfig) error) error {
+	return s.repo.Each(name, scope, export.MaxRows, export.BatchSize, fn)
 }
 
 // Create 创建服务器配置
 func (s *serverConfigService) Create(config *model.ServerConfig) error {
+	if err := normalizeHostKeyFingerprint(config); err != nil {
+		return err
+	}
 	return s.repo.Create(config)
 }
 
@@ -40,8 +53,20 @@ func (s *serverConfigService) Get(id uint) (*model.ServerConfig, error) {
 	return s.repo.Get(id)
 }
 
-// Update 更新服务器配置
+// Update 更新服务器配置。未填写主机公钥指纹且主机和端口未变时保留已登记的指纹，主机或端口变化时重新登记
 func (s *serverConfigService) Update(config *model.ServerConfig) error {
+	if err := normalizeHostKeyFingerprint(config); err != nil {
+		return err
+	}
+	if config.HostKeyFingerprint == "" {
+		existing, err := s.repo.Get(config.ID)
+		if err != nil {
+			return err
+		}
+		if existing.Host == config.Host && existing.Port == config.Port {
+			config.HostKeyFingerprint = existing.HostKeyFingerprint
+		}
+	}
 	return s.repo.Update(config)
 }
 
@@ -55,3 +80,28 @@ func (s *serverConfigService) TestConnection(config *model.ServerConfig) error {
 	// TODO: 实现服务器连接测试逻辑
 	return nil
 }
+
+// normalizeHostKeyFingerprint 校验并规范化手工填写的主机公钥指纹
+func normalizeHostKeyFingerprint(config *model.ServerConfig) error {
+	if config.HostKeyFingerprint == "" {
+		return nil
+	}
+	fingerprint, err := terminal.NormalizeHostKeyFingerprint(config.HostKeyFingerprint)
+	if err != nil {
+		return err
+	}
+	config.HostKeyFingerprint = fingerprint
+	return nil
+}
+
+// trustHostKeyOnFirstUse 服务器未登记主机公钥指纹时，登记首次连接看到的指纹
+func trustHostKeyOnFirstUse(repo *repository.ServerConfigRepository, server *model.ServerConfig) terminal.HostKeyTrustFunc {
+	return func(fingerprint string) error {
+		if err := repo.TrustHostKey(server.ID, fingerprint); err != nil {
+			return fmt.Errorf("登记服务器 %s 的主机公钥指纹失败: %v", server.Name, err)
+		}
+		logger.Info("首次连接服务器 %s，登记主机公钥指纹 %s", server.Name, fingerprint)
+		server.HostKeyFingerprint = fingerprint
+		return nil
+	}
+}
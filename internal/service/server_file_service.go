@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/remotefs"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/config"
+	"eden-ops/pkg/logger"
+)
+
+const defaultSFTPMaxUploadSize = 100 // MB
+
+// FileOperator 文件操作人信息，用于审计
+type FileOperator struct {
+	UserID   uint
+	Username string
+	ClientIP string
+}
+
+// ServerFileService 服务器文件传输服务接口
+type ServerFileService interface {
+	MaxUploadSize() int64
+	List(op *FileOperator, serverID uint, dir string) ([]remotefs.FileInfo, error)
+	Stat(op *FileOperator, serverID uint, file string) (*remotefs.FileInfo, error)
+	Download(op *FileOperator, serverID uint, file string, prepare func(info *remotefs.FileInfo) io.Writer) error
+	Upload(op *FileOperator, serverID uint, dir, filename string, overwrite bool, content io.Reader) (*remotefs.FileInfo, error)
+	Mkdir(op *FileOperator, serverID uint, dir string) error
+	Delete(op *FileOperator, serverID uint, file string, recursive bool) error
+	ListAudits(page, pageSize int, serverID, userID uint, action string) (int64, []model.ServerFileAudit, error)
+}
+
+// serverFileService 服务器文件传输服务实现
+type serverFileService struct {
+	serverRepo      *repository.ServerConfigRepository
+	auditRepo       repository.ServerFileAuditRepository
+	terminalService ServerTerminalService
+	maxUploadSize   int64
+}
+
+// NewServerFileService 创建服务器文件传输服务，访问授权与Web终端共用
+func NewServerFileService(
+	serverRepo *repository.ServerConfigRepository,
+	auditRepo repository.ServerFileAuditRepository,
+	terminalService ServerTerminalService,
+	cfg config.SFTPConfig,
+) ServerFileService {
+	maxUploadSize := cfg.MaxUploadSize
+	if maxUploadSize <= 0 {
+		maxUploadSize = defaultSFTPMaxUploadSize
+	}
+	return &serverFileService{
+		serverRepo:      serverRepo,
+		auditRepo:       auditRepo,
+		terminalService: terminalService,
+		maxUploadSize:   int64(maxUploadSize) << 20,
+	}
+}
+
+// MaxUploadSize 单文件上传大小上限(字节)
+func (s *serverFileService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// withClient 校验权限后连接服务器执行文件操作，并记录审计日志
+func (s *serverFileService) withClient(op *FileOperator, serverID uint, action, target string, fn func(client *remotefs.Client) (int64, error)) error {
+	audit := &model.ServerFileAudit{
+		ServerID: serverID,
+		UserID:   op.UserID,
+		Username: op.Username,
+		ClientIP: op.ClientIP,
+		Action:   action,
+		Path:     truncateString(target, 1024),
+	}
+	defer func() {
+		if err := s.auditRepo.Create(audit); err != nil {
+			logger.Error("写入文件操作审计失败: %v", err)
+		}
+	}()
+
+	fail := func(err error) error {
+		audit.Success = false
+		audit.ErrorMsg = truncateString(err.Error(), 500)
+		return err
+	}
+
+	if err := s.terminalService.CheckPermission(op.UserID, serverID); err != nil {
+		return fail(err)
+	}
+
+	server, err := s.serverRepo.Get(serverID)
+	if err != nil {
+		return fail(fmt.Errorf("服务器配置不存在"))
+	}
+	audit.ServerName = server.Name
+	if server.Status == model.ServerStatusDisabled {
+		return fail(fmt.Errorf("服务器 %s 已禁用", server.Name))
+	}
+
+	client, err := remotefs.Open(server, defaultTerminalDialTimeout, trustHostKeyOnFirstUse(s.serverRepo, server))
+	if err != nil {
+		return fail(err)
+	}
+	defer client.Close()
+
+	size, err := fn(client)
+	audit.Size = size
+	if err != nil {
+		return fail(err)
+	}
+	audit.Success = true
+	return nil
+}
+
+// List 列出远程目录
+func (s *serverFileService) List(op *FileOperator, serverID uint, dir string) ([]remotefs.FileInfo, error) {
+	var files []remotefs.FileInfo
+	err := s.withClient(op, serverID, model.FileActionList, dir, func(client *remotefs.Client) (int64, error) {
+		var err error
+		files, err = client.List(dir)
+		return 0, err
+	})
+	return files, err
+}
+
+// Stat 获取远程文件信息
+func (s *serverFileService) Stat(op *FileOperator, serverID uint, file string) (*remotefs.FileInfo, error) {
+	var info *remotefs.FileInfo
+	err := s.withClient(op, serverID, model.FileActionStat, file, func(client *remotefs.Client) (int64, error) {
+		var err error
+		info, err = client.Stat(file)
+		return 0, err
+	})
+	return info, err
+}
+
+// Download 流式下载远程文件，prepare 在开始传输前调用以设置响应头并返回输出流
+func (s *serverFileService) Download(op *FileOperator, serverID uint, file string, prepare func(info *remotefs.FileInfo) io.Writer) error {
+	return s.withClient(op, serverID, model.FileActionDownload, file, func(client *remotefs.Client) (int64, error) {
+		f, info, err := client.OpenFile(file)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+
+		n, err := io.Copy(prepare(info), f)
+		if err != nil {
+			return n, fmt.Errorf("下载中断: %v", err)
+		}
+		return n, nil
+	})
+}
+
+// Upload 上传文件到远程目录
+func (s *serverFileService) Upload(op *FileOperator, serverID uint, dir, filename string, overwrite bool, content io.Reader) (*remotefs.FileInfo, error) {
+	name := path.Base(path.Clean("/" + filename))
+	if name == "/" || name == "." || name == ".." {
+		return nil, fmt.Errorf("无效的文件名: %s", filename)
+	}
+	target := path.Join(dir, name)
+
+	var info *remotefs.FileInfo
+	err := s.withClient(op, serverID, model.FileActionUpload, target, func(client *remotefs.Client) (int64, error) {
+		upload, err := client.CreateUpload(target, overwrite)
+		if err != nil {
+			return 0, err
+		}
+
+		// 多读1字节用于判断是否超出大小限制
+		n, err := io.Copy(upload, io.LimitReader(content, s.maxUploadSize+1))
+		if err == nil && n > s.maxUploadSize {
+			err = fmt.Errorf("文件大小超过限制 %d MB", s.maxUploadSize>>20)
+		}
+		if err == nil {
+			err = upload.Commit()
+		}
+		if err != nil {
+			// 上传失败时只清理临时文件，已有的目标文件保持不变
+			if rmErr := upload.Abort(); rmErr != nil {
+				logger.Warn("清理未完成的上传文件失败: %s, %v", upload.Target(), rmErr)
+			}
+			return n, err
+		}
+
+		info, err = client.Stat(upload.Target())
+		return n, err
+	})
+	return info, err
+}
+
+// Mkdir 创建远程目录
+func (s *serverFileService) Mkdir(op *FileOperator, serverID uint, dir string) error {
+	return s.withClient(op, serverID, model.FileActionMkdir, dir, func(client *remotefs.Client) (int64, error) {
+		_, err := client.Mkdir(dir)
+		return 0, err
+	})
+}
+
+// Delete 删除远程文件或目录
+func (s *serverFileService) Delete(op *FileOperator, serverID uint, file string, recursive bool) error {
+	return s.withClient(op, serverID, model.FileActionDelete, file, func(client *remotefs.Client) (int64, error) {
+		_, err := client.Remove(file, recursive)
+		return 0, err
+	})
+}
+
+// ListAudits 查询文件操作审计记录
+func (s *serverFileService) ListAudits(page, pageSize int, serverID, userID uint, action string) (int64, []model.ServerFileAudit, error) {
+	return s.auditRepo.List(page, pageSize, serverID, userID, action)
+}
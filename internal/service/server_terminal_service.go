@@ -0,0 +1,401 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/terminal"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/config"
+	"eden-ops/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 终端相关错误
+var (
+	ErrTerminalForbidden = errors.New("无权访问该服务器终端")
+	ErrSessionNotFound   = errors.New("终端会话不存在")
+	ErrRecordingNotFound = errors.New("会话录像不存在")
+)
+
+const (
+	defaultTerminalRecordDir   = "data/recordings"
+	defaultTerminalIdleTimeout = 30 * time.Minute
+	defaultTerminalDialTimeout = 10 * time.Second
+	defaultTerminalCols        = 120
+	defaultTerminalRows        = 30
+)
+
+// TerminalOpenRequest 打开终端请求
+type TerminalOpenRequest struct {
+	ServerID uint
+	UserID   uint
+	Username string
+	ClientIP string
+	Cols     int
+	Rows     int
+}
+
+// TerminalSession 运行中的终端会话
+type TerminalSession struct {
+	Record   *model.ServerSession
+	Shell    *terminal.Shell
+	Recorder *terminal.Recorder
+}
+
+// Resize 调整终端大小并记录到录像
+func (t *TerminalSession) Resize(cols, rows int) error {
+	if cols <= 0 || rows <= 0 {
+		return fmt.Errorf("无效的终端大小: %dx%d", cols, rows)
+	}
+	if err := t.Shell.Resize(cols, rows); err != nil {
+		return err
+	}
+	return t.Recorder.WriteResize(cols, rows)
+}
+
+// ServerPermissionInfo 服务器终端授权信息
+type ServerPermissionInfo struct {
+	ServerID uint   `json:"server_id"`
+	UserIDs  []uint `json:"user_ids"`
+	RoleIDs  []uint `json:"role_ids"`
+}
+
+// ServerTerminalService 服务器Web终端服务接口
+type ServerTerminalService interface {
+	IsAdmin(userID uint) bool
+	CheckPermission(userID, serverID uint) error
+	Open(req *TerminalOpenRequest) (*TerminalSession, error)
+	Close(session *TerminalSession, reason string, cause error)
+	IdleTimeout() time.Duration
+	CloseStaleSessions()
+	ListSessions(viewerID uint, page, pageSize int, serverID, userID uint, status string) (int64, []model.ServerSession, error)
+	GetSession(viewerID, id uint) (*model.ServerSession, error)
+	GetRecordingFile(viewerID, id uint) (string, error)
+	GetPermissions(serverID uint) (*ServerPermissionInfo, error)
+	UpdatePermissions(serverID uint, userIDs, roleIDs []uint) error
+}
+
+// serverTerminalService 服务器Web终端服务实现
+type serverTerminalService struct {
+	serverRepo  *repository.ServerConfigRepository
+	sessionRepo repository.ServerSessionRepository
+	userRepo    repository.UserRepository
+	recordDir   string
+	idleTimeout time.Duration
+	dialTimeout time.Duration
+}
+
+// NewServerTerminalService 创建服务器Web终端服务
+func NewServerTerminalService(
+	serverRepo *repository.ServerConfigRepository,
+	sessionRepo repository.ServerSessionRepository,
+	userRepo repository.UserRepository,
+	cfg config.TerminalConfig,
+) ServerTerminalService {
+	s := &serverTerminalService{
+		serverRepo:  serverRepo,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		recordDir:   cfg.RecordDir,
+		idleTimeout: defaultTerminalIdleTimeout,
+		dialTimeout: defaultTerminalDialTimeout,
+	}
+	if s.recordDir == "" {
+		s.recordDir = defaultTerminalRecordDir
+	}
+	if cfg.IdleTimeout != "" {
+		if d, err := time.ParseDuration(cfg.IdleTimeout); err == nil && d > 0 {
+			s.idleTimeout = d
+		} else {
+			logger.Warn("解析终端空闲超时失败: %s，使用默认值%v", cfg.IdleTimeout, defaultTerminalIdleTimeout)
+		}
+	}
+	if cfg.DialTimeout != "" {
+		if d, err := time.ParseDuration(cfg.DialTimeout); err == nil && d > 0 {
+			s.dialTimeout = d
+		} else {
+			logger.Warn("解析SSH连接超时失败: %s，使用默认值%v", cfg.DialTimeout, defaultTerminalDialTimeout)
+		}
+	}
+	return s
+}
+
+// IdleTimeout 终端空闲超时时间
+func (s *serverTerminalService) IdleTimeout() time.Duration {
+	return s.idleTimeout
+}
+
+// isAdmin 判断用户是否拥有管理员角色
+func (s *serverTerminalService) isAdmin(roles []*model.Role) bool {
+	for _, role := range roles {
+		if role.Code == model.RoleCodeAdmin && role.Status == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin 判断用户是否为管理员
+func (s *serverTerminalService) IsAdmin(userID uint) bool {
+	user, err := s.userRepo.Get(userID)
+	if err != nil || user.Status != 1 {
+		return false
+	}
+	return s.isAdmin(user.Roles)
+}
+
+// CheckPermission 检查用户是否有权访问服务器终端
+func (s *serverTerminalService) CheckPermission(userID, serverID uint) error {
+	user, err := s.userRepo.Get(userID)
+	if err != nil || user.Status != 1 {
+		return ErrTerminalForbidden
+	}
+	if s.isAdmin(user.Roles) {
+		return nil
+	}
+
+	var roleIDs []uint
+	for _, role := range user.Roles {
+		if role.Status == 1 {
+			roleIDs = append(roleIDs, role.ID)
+		}
+	}
+	allowed, err := s.sessionRepo.HasPermission(serverID, userID, roleIDs)
+	if err != nil {
+		return fmt.Errorf("查询终端授权失败: %v", err)
+	}
+	if !allowed {
+		return ErrTerminalForbidden
+	}
+	return nil
+}
+
+// Open 建立SSH连接并启动终端会话
+func (s *serverTerminalService) Open(req *TerminalOpenRequest) (*TerminalSession, error) {
+	if err := s.CheckPermission(req.UserID, req.ServerID); err != nil {
+		return nil, err
+	}
+
+	server, err := s.serverRepo.Get(req.ServerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("服务器配置不存在")
+		}
+		return nil, err
+	}
+	if server.Status == model.ServerStatusDisabled {
+		return nil, fmt.Errorf("服务器 %s 已禁用", server.Name)
+	}
+
+	cols, rows := req.Cols, req.Rows
+	if cols <= 0 {
+		cols = defaultTerminalCols
+	}
+	if rows <= 0 {
+		rows = defaultTerminalRows
+	}
+
+	record := &model.ServerSession{
+		ServerID:   server.ID,
+		ServerName: server.Name,
+		Host:       server.Host,
+		UserID:     req.UserID,
+		Username:   req.Username,
+		ClientIP:   req.ClientIP,
+		Cols:       cols,
+		Rows:       rows,
+		Status:     model.ServerSessionStatusActive,
+		StartedAt:  time.Now(),
+	}
+	if err := s.sessionRepo.Create(record); err != nil {
+		return nil, fmt.Errorf("创建会话记录失败: %v", err)
+	}
+
+	client, err := terminal.Dial(server, s.dialTimeout, trustHostKeyOnFirstUse(s.serverRepo, server))
+	if err != nil {
+		s.markFailed(record, err)
+		return nil, err
+	}
+
+	shell, err := terminal.NewShell(client, cols, rows)
+	if err != nil {
+		client.Close()
+		s.markFailed(record, err)
+		return nil, err
+	}
+
+	record.RecordFile = filepath.Join(s.recordDir, record.StartedAt.Format("20060102"), fmt.Sprintf("%d.cast", record.ID))
+	title := fmt.Sprintf("%s@%s (%s)", req.Username, server.Name, server.Host)
+	recorder, err := terminal.NewRecorder(record.RecordFile, cols, rows, title)
+	if err != nil {
+		shell.Close()
+		s.markFailed(record, err)
+		return nil, err
+	}
+	if err := s.sessionRepo.Update(record); err != nil {
+		logger.Error("更新会话录像路径失败: %v", err)
+	}
+
+	logger.Info("用户 %s 打开服务器终端: %s(%s), 会话ID: %d", req.Username, server.Name, server.Host, record.ID)
+
+	return &TerminalSession{
+		Record:   record,
+		Shell:    shell,
+		Recorder: recorder,
+	}, nil
+}
+
+// markFailed 标记会话连接失败
+func (s *serverTerminalService) markFailed(record *model.ServerSession, cause error) {
+	now := time.Now()
+	record.Status = model.ServerSessionStatusFailed
+	record.CloseReason = model.SessionCloseReasonError
+	record.ErrorMsg = truncateString(cause.Error(), 500)
+	record.EndedAt = &now
+	if err := s.sessionRepo.Update(record); err != nil {
+		logger.Error("更新会话状态失败: %v", err)
+	}
+}
+
+// Close 关闭终端会话并更新会话记录
+func (s *serverTerminalService) Close(session *TerminalSession, reason string, cause error) {
+	if session == nil {
+		return
+	}
+	session.Shell.Close()
+	if err := session.Recorder.Close(); err != nil {
+		logger.Error("关闭会话录像失败: %v", err)
+	}
+
+	now := time.Now()
+	record := session.Record
+	record.Status = model.ServerSessionStatusClosed
+	record.CloseReason = reason
+	if cause != nil {
+		record.ErrorMsg = truncateString(cause.Error(), 500)
+	}
+	record.EndedAt = &now
+	record.Duration = int(now.Sub(record.StartedAt).Seconds())
+	if info, err := os.Stat(record.RecordFile); err == nil {
+		record.RecordSize = info.Size()
+	}
+	if err := s.sessionRepo.Update(record); err != nil {
+		logger.Error("更新会话记录失败: %v", err)
+	}
+
+	logger.Info("服务器终端会话已关闭: 会话ID %d, 用户 %s, 原因 %s, 时长 %d 秒", record.ID, record.Username, reason, record.Duration)
+}
+
+// CloseStaleSessions 关闭服务重启前遗留的进行中会话
+func (s *serverTerminalService) CloseStaleSessions() {
+	count, err := s.sessionRepo.CloseActive(model.SessionCloseReasonShutdown)
+	if err != nil {
+		logger.Error("关闭遗留终端会话失败: %v", err)
+		return
+	}
+	if count > 0 {
+		logger.Info("已关闭 %d 个遗留终端会话", count)
+	}
+}
+
+// ListSessions 查询终端会话，非管理员只能查看自己的会话
+func (s *serverTerminalService) ListSessions(viewerID uint, page, pageSize int, serverID, userID uint, status string) (int64, []model.ServerSession, error) {
+	viewer, err := s.userRepo.Get(viewerID)
+	if err != nil {
+		return 0, nil, ErrTerminalForbidden
+	}
+	if !s.isAdmin(viewer.Roles) {
+		userID = viewerID
+	}
+	return s.sessionRepo.List(page, pageSize, serverID, userID, status)
+}
+
+// GetSession 获取终端会话，非管理员访问他人会话时视为不存在
+func (s *serverTerminalService) GetSession(viewerID, id uint) (*model.ServerSession, error) {
+	session, err := s.sessionRepo.Get(id)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.UserID != viewerID {
+		viewer, err := s.userRepo.Get(viewerID)
+		if err != nil || !s.isAdmin(viewer.Roles) {
+			return nil, ErrSessionNotFound
+		}
+	}
+	return session, nil
+}
+
+// GetRecordingFile 获取会话录像文件路径
+func (s *serverTerminalService) GetRecordingFile(viewerID, id uint) (string, error) {
+	session, err := s.GetSession(viewerID, id)
+	if err != nil {
+		return "", err
+	}
+	if session.RecordFile == "" {
+		return "", ErrRecordingNotFound
+	}
+	if _, err := os.Stat(session.RecordFile); err != nil {
+		return "", ErrRecordingNotFound
+	}
+	return session.RecordFile, nil
+}
+
+// GetPermissions 获取服务器终端授权
+func (s *serverTerminalService) GetPermissions(serverID uint) (*ServerPermissionInfo, error) {
+	permissions, err := s.sessionRepo.ListPermissions(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ServerPermissionInfo{
+		ServerID: serverID,
+		UserIDs:  []uint{},
+		RoleIDs:  []uint{},
+	}
+	for _, p := range permissions {
+		if p.UserID > 0 {
+			info.UserIDs = append(info.UserIDs, p.UserID)
+		}
+		if p.RoleID > 0 {
+			info.RoleIDs = append(info.RoleIDs, p.RoleID)
+		}
+	}
+	return info, nil
+}
+
+// UpdatePermissions 更新服务器终端授权
+func (s *serverTerminalService) UpdatePermissions(serverID uint, userIDs, roleIDs []uint) error {
+	if _, err := s.serverRepo.Get(serverID); err != nil {
+		return fmt.Errorf("服务器配置不存在")
+	}
+	return s.sessionRepo.ReplacePermissions(serverID, uniqueUints(userIDs), uniqueUints(roleIDs))
+}
+
+// uniqueUints 去重并剔除0值
+func uniqueUints(values []uint) []uint {
+	seen := make(map[uint]bool, len(values))
+	result := make([]uint, 0, len(values))
+	for _, v := range values {
+		if v == 0 || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+// truncateString 按字符截断字符串
+func truncateString(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"eden-ops/internal/model"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/auth"
+	"eden-ops/pkg/logger"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// tokenStatusCacheTTL 访问令牌吊销状态缓存有效期，本实例内的吊销会立即生效
+const tokenStatusCacheTTL = 30 * time.Second
+
+// defaultSessionExpire 默认登录会话有效期
+const defaultSessionExpire = 24 * time.Hour
+
+// LoginChallengeTTL 登录挑战有效期，超时需重新输入密码
+const LoginChallengeTTL = 5 * time.Minute
+
+// 登录挑战用途
+const (
+	LoginChallengeTOTP           = "totp"            // 两步验证
+	LoginChallengePasswordChange = "password_change" // 首次登录修改密码
+)
+
+// ErrInvalidLoginChallenge 登录挑战无效
+var ErrInvalidLoginChallenge = errors.New("登录已过期，请重新登录")
+
+// ErrInvalidRefreshToken 刷新令牌无效
+var ErrInvalidRefreshToken = errors.New("刷新令牌无效或已过期，请重新登录")
+
+// TokenPair 登录令牌
+type TokenPair struct {
+	AccessToken      string `json:"token"`
+	RefreshToken     string `json:"refreshToken"`
+	ExpiresIn        int64  `json:"expiresIn"`        // 访问令牌有效期(秒)
+	RefreshExpiresIn int64  `json:"refreshExpiresIn"` // 刷新令牌剩余有效期(秒)
+}
+
+// TokenService 令牌服务接口，管理登录会话、刷新令牌轮换和访问令牌吊销
+type TokenService interface {
+	Issue(user *model.User, clientIP, userAgent string) (*TokenPair, error)
+	Refresh(refreshToken, clientIP, userAgent string) (*TokenPair, error)
+	Logout(claims *auth.CustomClaims) error
+	RevokeUserSessions(userID uint, reason string) error
+	IsTokenRevoked(claims *auth.CustomClaims) (bool, error)
+	CleanupExpired() (int64, error)
+	IssueChallenge(userID uint, purpose string) (string, error)
+	VerifyChallenge(challengeToken, purpose string) (uint, error)
+}
+
+// tokenStatus 缓存的访问令牌状态
+type tokenStatus struct {
+	userID    uint
+	sessionID uint
+	revoked   bool
+	expireAt  time.Time
+}
+
+type tokenService struct {
+	sessionRepo   repository.UserSessionRepository
+	userRepo      repository.UserRepository
+	jwtAuth       *auth.JWTAuth
+	sessionExpire time.Duration
+
+	mu     sync.RWMutex
+	tokens map[string]*tokenStatus // jti -> 状态
+}
+
+// NewTokenService 创建令牌服务，sessionExpire 为登录会话（刷新令牌）有效期
+func NewTokenService(sessionRepo repository.UserSessionRepository, userRepo repository.UserRepository, jwtAuth *auth.JWTAuth, sessionExpire time.Duration) TokenService {
+	if sessionExpire <= 0 {
+		sessionExpire = defaultSessionExpire
+	}
+	return &tokenService{
+		sessionRepo:   sessionRepo,
+		userRepo:      userRepo,
+		jwtAuth:       jwtAuth,
+		sessionExpire: sessionExpire,
+		tokens:        make(map[string]*tokenStatus),
+	}
+}
+
+// Issue 创建登录会话并签发令牌
+func (s *tokenService) Issue(user *model.User, clientIP, userAgent string) (*TokenPair, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("生成刷新令牌失败: %v", err)
+	}
+
+	session := &model.UserSession{
+		UserID:    user.ID,
+		TokenHash: hashRefreshToken(refreshToken),
+		ClientIP:  clientIP,
+		UserAgent: truncateString(userAgent, 255),
+		ExpiresAt: time.Now().Add(s.sessionExpire),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, fmt.Errorf("创建登录会话失败: %v", err)
+	}
+
+	return s.tokenPair(user, session, refreshToken)
+}
+
+// Refresh 使用刷新令牌换取新的令牌，刷新令牌每次使用后轮换；已轮换的令牌再次出现时吊销整个会话
+func (s *tokenService) Refresh(refreshToken, clientIP, userAgent string) (*TokenPair, error) {
+	hash := hashRefreshToken(refreshToken)
+	session, err := s.sessionRepo.GetByTokenHash(hash)
+	if err != nil {
+		if reused, err := s.sessionRepo.GetByPreviousTokenHash(hash); err == nil && reused.RevokedAt == nil {
+			logger.Warn("检测到已轮换的刷新令牌被重复使用，吊销会话: session=%d, user=%d, ip=%s", reused.ID, reused.UserID, clientIP)
+			s.revokeSession(reused.ID, reused.UserID, model.SessionRevokeTokenReuse)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if !session.Active(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.Get(session.UserID)
+	if err != nil {
+		s.revokeSession(session.ID, session.UserID, model.SessionRevokeUserDeleted)
+		return nil, ErrInvalidRefreshToken
+	}
+	if user.Status != 1 {
+		s.revokeSession(session.ID, session.UserID, model.SessionRevokeUserDisabled)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("生成刷新令牌失败: %v", err)
+	}
+	rotated, err := s.sessionRepo.Rotate(session.ID, hash, hashRefreshToken(newToken))
+	if err != nil {
+		return nil, fmt.Errorf("轮换刷新令牌失败: %v", err)
+	}
+	if !rotated {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	logger.Debug("刷新令牌: session=%d, user=%d, ip=%s, ua=%s", session.ID, session.UserID, clientIP, userAgent)
+	return s.tokenPair(user, session, newToken)
+}
+
+// Logout 登出当前会话，吊销访问令牌及其所属会话
+func (s *tokenService) Logout(claims *auth.CustomClaims) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.sessionRepo.AddRevokedToken(&model.RevokedToken{
+			JTI:       claims.ID,
+			UserID:    claims.UserID,
+			ExpiresAt: claims.ExpiresAt.Time,
+			Reason:    model.SessionRevokeLogout,
+		}); err != nil {
+			return fmt.Errorf("吊销访问令牌失败: %v", err)
+		}
+		s.mu.Lock()
+		s.tokens[claims.ID] = &tokenStatus{userID: claims.UserID, sessionID: claims.SessionID, revoked: true, expireAt: claims.ExpiresAt.Time}
+		s.mu.Unlock()
+	}
+
+	if claims.SessionID != 0 {
+		if err := s.sessionRepo.Revoke(claims.SessionID, model.SessionRevokeLogout); err != nil {
+			return fmt.Errorf("吊销登录会话失败: %v", err)
+		}
+		s.markRevoked(func(st *tokenStatus) bool { return st.sessionID == claims.SessionID })
+	}
+	return nil
+}
+
+// RevokeUserSessions 吊销用户的全部会话，该用户已签发的访问令牌随之失效
+func (s *tokenService) RevokeUserSessions(userID uint, reason string) error {
+	count, err := s.sessionRepo.RevokeByUser(userID, reason)
+	if err != nil {
+		return fmt.Errorf("吊销用户会话失败: %v", err)
+	}
+	s.markRevoked(func(st *tokenStatus) bool { return st.userID == userID })
+	if count > 0 {
+		logger.Info("已吊销用户会话: user=%d, count=%d, reason=%s", userID, count, reason)
+	}
+	return nil
+}
+
+// IsTokenRevoked 访问令牌是否已失效：jti 已吊销，或所属会话已吊销、过期
+func (s *tokenService) IsTokenRevoked(claims *auth.CustomClaims) (bool, error) {
+	// 不含会话信息的令牌由旧版本签发，无法吊销，要求重新登录
+	if claims.ID == "" || claims.SessionID == 0 {
+		return true, nil
+	}
+
+	now := time.Now()
+	s.mu.RLock()
+	st, ok := s.tokens[claims.ID]
+	s.mu.RUnlock()
+	if ok && (st.revoked || now.Before(st.expireAt)) {
+		return st.revoked, nil
+	}
+
+	revoked, err := s.sessionRepo.IsTokenRevoked(claims.ID)
+	if err != nil {
+		return false, err
+	}
+	if !revoked {
+		session, err := s.sessionRepo.Get(claims.SessionID)
+		revoked = err != nil || session.UserID != claims.UserID || !session.Active(now)
+	}
+
+	s.mu.Lock()
+	s.pruneLocked(now)
+	s.tokens[claims.ID] = &tokenStatus{
+		userID:    claims.UserID,
+		sessionID: claims.SessionID,
+		revoked:   revoked,
+		expireAt:  now.Add(tokenStatusCacheTTL),
+	}
+	s.mu.Unlock()
+	return revoked, nil
+}
+
+// CleanupExpired 清理已过期的会话和吊销记录
+func (s *tokenService) CleanupExpired() (int64, error) {
+	s.mu.Lock()
+	s.pruneLocked(time.Now())
+	s.mu.Unlock()
+	return s.sessionRepo.DeleteExpired(time.Now())
+}
+
+// IssueChallenge 签发登录挑战令牌
+func (s *tokenService) IssueChallenge(userID uint, purpose string) (string, error) {
+	token, err := s.jwtAuth.GenerateChallengeToken(userID, purpose, LoginChallengeTTL)
+	if err != nil {
+		return "", fmt.Errorf("生成登录挑战失败: %v", err)
+	}
+	return token, nil
+}
+
+// VerifyChallenge 校验登录挑战令牌，返回用户ID
+func (s *tokenService) VerifyChallenge(challengeToken, purpose string) (uint, error) {
+	userID, err := s.jwtAuth.ParseChallengeToken(challengeToken, purpose)
+	if err != nil {
+		logger.Debug("登录挑战校验失败: purpose=%s, %v", purpose, err)
+		return 0, ErrInvalidLoginChallenge
+	}
+	return userID, nil
+}
+
+// tokenPair 为会话签发访问令牌
+func (s *tokenService) tokenPair(user *model.User, session *model.UserSession, refreshToken string) (*TokenPair, error) {
+	accessToken, _, err := s.jwtAuth.GenerateToken(user.ID, user.Username, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("生成token失败: %v", err)
+	}
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(s.jwtAuth.Expire().Seconds()),
+		RefreshExpiresIn: int64(time.Until(session.ExpiresAt).Seconds()),
+	}, nil
+}
+
+// revokeSession 吊销会话，失败时仅记录日志
+func (s *tokenService) revokeSession(sessionID, userID uint, reason string) {
+	if err := s.sessionRepo.Revoke(sessionID, reason); err != nil {
+		logger.Error("吊销登录会话失败: session=%d, user=%d, %v", sessionID, userID, err)
+		return
+	}
+	s.markRevoked(func(st *tokenStatus) bool { return st.sessionID == sessionID })
+}
+
+// markRevoked 将缓存中匹配的令牌标记为已吊销
+func (s *tokenService) markRevoked(match func(st *tokenStatus) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.tokens {
+		if match(st) {
+			st.revoked = true
+		}
+	}
+}
+
+// pruneLocked 清理已过期的缓存项；已吊销的项保留到访问令牌过期，避免重复查询
+func (s *tokenService) pruneLocked(now time.Time) {
+	maxAge := s.jwtAuth.Expire()
+	for jti, st := range s.tokens {
+		if (!st.revoked && now.After(st.expireAt)) || now.Sub(st.expireAt) > maxAge {
+			delete(s.tokens, jti)
+		}
+	}
+}
+
+// newRefreshToken 生成随机刷新令牌
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashRefreshToken 计算刷新令牌摘要，数据库中只保存摘要
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
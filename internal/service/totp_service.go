@@ -0,0 +1,270 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"eden-ops/internal/model"
+	"eden-ops/internal/pkg/totp"
+	"eden-ops/internal/repository"
+	"eden-ops/pkg/logger"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// 两步验证错误
+var (
+	ErrInvalidTOTPCode  = errors.New("验证码错误")
+	ErrTOTPNotEnabled   = errors.New("未启用两步验证")
+	ErrTOTPEnabled      = errors.New("已启用两步验证，请先关闭后再重新绑定")
+	ErrTOTPSetupMissing = errors.New("请先获取绑定密钥")
+)
+
+// TOTPSetup 两步验证绑定信息，secret 可手动输入，uri 用于生成二维码
+type TOTPSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TOTPStatus 两步验证状态
+type TOTPStatus struct {
+	Enabled       bool  `json:"enabled"`
+	RecoveryCodes int64 `json:"recoveryCodes"` // 剩余可用恢复码数量
+}
+
+// TOTPService 两步验证服务接口
+type TOTPService interface {
+	Status(userID uint) (*TOTPStatus, error)
+	Setup(userID uint) (*TOTPSetup, error)
+	Enable(userID uint, code string) ([]string, error)
+	Disable(userID uint, code string) error
+	Reset(userID uint) error
+	RegenerateRecoveryCodes(userID uint, code string) ([]string, error)
+	Verify(user *model.User, code string) error
+}
+
+type totpService struct {
+	issuer       string
+	userRepo     repository.UserRepository
+	recoveryRepo repository.UserRecoveryCodeRepository
+}
+
+// NewTOTPService 创建两步验证服务，issuer 为身份验证器中显示的发行方名称
+func NewTOTPService(issuer string, userRepo repository.UserRepository, recoveryRepo repository.UserRecoveryCodeRepository) TOTPService {
+	if issuer == "" {
+		issuer = "eden-ops"
+	}
+	return &totpService{
+		issuer:       issuer,
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+	}
+}
+
+// Status 获取两步验证状态
+func (s *totpService) Status(userID uint) (*TOTPStatus, error) {
+	user, err := s.userRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &TOTPStatus{Enabled: user.TOTPEnabled}
+	if user.TOTPEnabled {
+		if status.RecoveryCodes, err = s.recoveryRepo.CountUnused(userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// Setup 生成待确认的密钥，使用验证码确认后才会启用
+func (s *totpService) Setup(userID uint) (*TOTPSetup, error) {
+	user, err := s.userRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateFields(userID, map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &TOTPSetup{
+		Secret: secret,
+		URI:    totp.KeyURI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable 使用验证码确认密钥并启用两步验证，返回恢复码（仅此一次明文返回）
+func (s *totpService) Enable(userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPSetupMissing
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return nil, ErrInvalidTOTPCode
+	}
+	codes, err := s.replaceRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateFields(userID, map[string]interface{}{
+		"totp_enabled":   true,
+		"totp_last_step": step,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info("用户启用两步验证: username=%s, userID=%d", user.Username, userID)
+	return codes, nil
+}
+
+// Disable 使用验证码或恢复码关闭两步验证
+func (s *totpService) Disable(userID uint, code string) error {
+	user, err := s.userRepo.Get(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	if err := s.Verify(user, code); err != nil {
+		return err
+	}
+
+	if err := s.clear(userID); err != nil {
+		return err
+	}
+	logger.Info("用户关闭两步验证: username=%s, userID=%d", user.Username, userID)
+	return nil
+}
+
+// Reset 管理员重置用户的两步验证，用于用户丢失身份验证器且恢复码用尽的情况
+func (s *totpService) Reset(userID uint) error {
+	if _, err := s.userRepo.Get(userID); err != nil {
+		return err
+	}
+	if err := s.clear(userID); err != nil {
+		return err
+	}
+	logger.Info("重置用户两步验证: userID=%d", userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes 使用验证码重新生成恢复码，原有恢复码全部作废
+func (s *totpService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTOTPNotEnabled
+	}
+	if err := s.Verify(user, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(userID)
+}
+
+// Verify 校验验证码或恢复码，验证码和恢复码均只能使用一次
+func (s *totpService) Verify(user *model.User, code string) error {
+	if !user.TOTPEnabled || user.TOTPSecret == "" {
+		return ErrTOTPNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != totp.Digits {
+		used, err := s.recoveryRepo.Use(user.ID, hashRecoveryCode(code))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTOTPCode
+		}
+		logger.Warn("用户使用恢复码完成两步验证: username=%s, userID=%d", user.Username, user.ID)
+		return nil
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return ErrInvalidTOTPCode
+	}
+	// 条件更新保证同一验证码并发提交时只有一次成功
+	updated, err := s.userRepo.UpdateTOTPStep(user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrInvalidTOTPCode
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+// clear 清除密钥和恢复码
+func (s *totpService) clear(userID uint) error {
+	if err := s.userRepo.UpdateFields(userID, map[string]interface{}{
+		"totp_enabled":   false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+	}); err != nil {
+		return err
+	}
+	return s.recoveryRepo.DeleteByUser(userID)
+}
+
+// replaceRecoveryCodes 生成新的恢复码，数据库中只保存摘要
+func (s *totpService) replaceRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	if err := s.recoveryRepo.Replace(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// newRecoveryCode 生成形如 abcde-fghij 的恢复码
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode 计算恢复码摘要，忽略大小写、空格和连字符
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
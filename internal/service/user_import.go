@@ -0,0 +1,471 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"eden-ops/internal/model"
+	"eden-ops/pkg/logger"
+)
+
+// 批量操作限制
+const (
+	UserImportMaxRows = 1000
+	UserBatchMaxSize  = 1000
+)
+
+// 导入结果状态
+const (
+	UserImportCreated = "created"
+	UserImportUpdated = "updated"
+	UserImportSkipped = "skipped"
+	UserImportFailed  = "failed"
+)
+
+// UserImportHeaders 导入模板表头，与导出的列保持一致
+var UserImportHeaders = []string{"用户名", "昵称", "邮箱", "手机号", "角色", "状态", "初始密码"}
+
+// userImportColumns 导入文件表头别名，忽略大小写和首尾空格
+var userImportColumns = map[string]string{
+	"username": "username", "用户名": "username", "账号": "username",
+	"nickname": "nickname", "昵称": "nickname", "姓名": "nickname",
+	"email": "email", "邮箱": "email",
+	"phone": "phone", "手机号": "phone", "手机": "phone", "电话": "phone",
+	"roles": "roles", "角色": "roles", "角色编码": "roles",
+	"status": "status", "状态": "status",
+	"password": "password", "密码": "password", "初始密码": "password",
+}
+
+var (
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@-]*$`)
+	phonePattern    = regexp.MustCompile(`^[0-9+\-() ]+$`)
+	roleCodeSplit   = regexp.MustCompile(`[,;|，；、\s]+`)
+)
+
+// UserImportOptions 用户导入选项
+type UserImportOptions struct {
+	DryRun         bool // 只校验不写入
+	UpdateExisting bool // 更新已存在用户的昵称、邮箱、手机号、状态和角色，不修改密码；为 false 时跳过
+}
+
+// UserImportRowResult 单行导入结果
+type UserImportRowResult struct {
+	Row      int      `json:"row"` // 文件中的行号，表头为第 1 行
+	Username string   `json:"username"`
+	Status   string   `json:"status"`
+	Errors   []string `json:"errors,omitempty"`
+	Password string   `json:"password,omitempty"` // 未填写密码时系统生成的初始密码
+}
+
+// UserImportResult 用户导入结果，DryRun 时各计数表示预计结果
+type UserImportResult struct {
+	DryRun  bool                   `json:"dryRun"`
+	Total   int                    `json:"total"`
+	Created int                    `json:"created"`
+	Updated int                    `json:"updated"`
+	Skipped int                    `json:"skipped"`
+	Failed  int                    `json:"failed"`
+	Rows    []*UserImportRowResult `json:"rows"`
+}
+
+// userImportRow 解析后的导入行
+type userImportRow struct {
+	result   *UserImportRowResult
+	user     *model.User
+	existing *model.User
+	roleIDs  []uint
+	hasRoles bool
+	status   *int
+}
+
+// ImportUsers 从表格导入用户，第一行为表头；逐行校验并报告错误，出错的行不影响其他行
+func (s *userService) ImportUsers(rows [][]string, opts UserImportOptions) (*UserImportResult, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("文件内容为空")
+	}
+	columns := make(map[string]int)
+	for i, header := range rows[0] {
+		if key, ok := userImportColumns[strings.ToLower(strings.TrimSpace(header))]; ok {
+			if _, exists := columns[key]; !exists {
+				columns[key] = i
+			}
+		}
+	}
+	if _, ok := columns["username"]; !ok {
+		return nil, errors.New("缺少用户名列")
+	}
+
+	var parsed []*userImportRow
+	for i, values := range rows[1:] {
+		if isBlankRow(values) {
+			continue
+		}
+		if len(parsed) >= UserImportMaxRows {
+			return nil, fmt.Errorf("单次最多导入%d个用户", UserImportMaxRows)
+		}
+		cell := func(key string) string {
+			if index, ok := columns[key]; ok && index < len(values) {
+				return strings.TrimSpace(values[index])
+			}
+			return ""
+		}
+		parsed = append(parsed, &userImportRow{
+			result: &UserImportRowResult{Row: i + 2, Username: cell("username")},
+			user: &model.User{
+				Username: cell("username"),
+				Nickname: cell("nickname"),
+				Email:    cell("email"),
+				Phone:    cell("phone"),
+				Password: cell("password"),
+			},
+		})
+		s.validateImportRow(parsed[len(parsed)-1], cell("roles"), cell("status"))
+	}
+
+	if err := s.resolveImportRows(parsed); err != nil {
+		return nil, err
+	}
+
+	result := &UserImportResult{DryRun: opts.DryRun, Total: len(parsed), Rows: make([]*UserImportRowResult, 0, len(parsed))}
+	for _, row := range parsed {
+		s.applyImportRow(row, opts)
+		switch row.result.Status {
+		case UserImportCreated:
+			result.Created++
+		case UserImportUpdated:
+			result.Updated++
+		case UserImportSkipped:
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+		result.Rows = append(result.Rows, row.result)
+	}
+	if !opts.DryRun {
+		logger.Info("导入用户: 共%d行, 新建%d, 更新%d, 跳过%d, 失败%d", result.Total, result.Created, result.Updated, result.Skipped, result.Failed)
+	}
+	return result, nil
+}
+
+// validateImportRow 校验单行字段格式
+func (s *userService) validateImportRow(row *userImportRow, roles, status string) {
+	user := row.user
+	addError := func(format string, args ...interface{}) {
+		row.result.Errors = append(row.result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case user.Username == "":
+		addError("用户名不能为空")
+	case utf8.RuneCountInString(user.Username) > 32:
+		addError("用户名不能超过32个字符")
+	case !usernamePattern.MatchString(user.Username):
+		addError("用户名只能包含字母、数字和 . _ @ -")
+	}
+	if utf8.RuneCountInString(user.Nickname) > 32 {
+		addError("昵称不能超过32个字符")
+	}
+	if user.Email != "" {
+		if len(user.Email) > 128 {
+			addError("邮箱不能超过128个字符")
+		} else if addr, err := mail.ParseAddress(user.Email); err != nil || addr.Address != user.Email {
+			addError("邮箱格式不正确")
+		}
+	}
+	if user.Phone != "" && (len(user.Phone) > 32 || !phonePattern.MatchString(user.Phone)) {
+		addError("手机号格式不正确")
+	}
+
+	if status != "" {
+		switch strings.ToLower(status) {
+		case "1", "启用", "正常", "enabled", "true":
+			value := 1
+			row.status = &value
+		case "0", "禁用", "停用", "disabled", "false":
+			value := 0
+			row.status = &value
+		default:
+			addError("无效的状态: %s", status)
+		}
+	}
+
+	if roles != "" {
+		row.hasRoles = true
+		for _, code := range roleCodeSplit.Split(roles, -1) {
+			if code != "" {
+				user.Roles = append(user.Roles, &model.Role{Code: code})
+			}
+		}
+	}
+}
+
+// resolveImportRows 解析角色编码、识别已存在及文件内重复的用户名
+func (s *userService) resolveImportRows(rows []*userImportRow) error {
+	codes := make(map[string]struct{})
+	for _, row := range rows {
+		for _, role := range row.user.Roles {
+			codes[role.Code] = struct{}{}
+		}
+	}
+	codeList := make([]string, 0, len(codes))
+	for code := range codes {
+		codeList = append(codeList, code)
+	}
+	roles, err := s.roleRepo.FindByCodes(codeList)
+	if err != nil {
+		return err
+	}
+	roleIDs := make(map[string]uint, len(roles))
+	for _, role := range roles {
+		roleIDs[role.Code] = role.ID
+	}
+
+	seen := make(map[string]int)
+	for _, row := range rows {
+		for _, role := range row.user.Roles {
+			id, ok := roleIDs[role.Code]
+			if !ok {
+				row.result.Errors = append(row.result.Errors, fmt.Sprintf("角色不存在或已禁用: %s", role.Code))
+				continue
+			}
+			row.roleIDs = append(row.roleIDs, id)
+		}
+		row.user.Roles = nil
+
+		name := strings.ToLower(row.user.Username)
+		if name == "" {
+			continue
+		}
+		if first, ok := seen[name]; ok {
+			row.result.Errors = append(row.result.Errors, fmt.Sprintf("用户名与第%d行重复", first))
+			continue
+		}
+		seen[name] = row.result.Row
+
+		if existing, err := s.userRepo.GetByUsername(row.user.Username); err == nil {
+			row.existing = existing
+		}
+	}
+	return nil
+}
+
+// applyImportRow 创建或更新单个用户，DryRun 时只确定结果状态
+func (s *userService) applyImportRow(row *userImportRow, opts UserImportOptions) {
+	result := row.result
+	if row.existing == nil && row.user.Password != "" {
+		if err := s.policy.validate(row.user.Username, row.user.Password); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	if len(result.Errors) > 0 {
+		result.Status = UserImportFailed
+		return
+	}
+
+	if row.existing != nil {
+		if !opts.UpdateExisting {
+			result.Status = UserImportSkipped
+			result.Errors = append(result.Errors, "用户已存在")
+			return
+		}
+		result.Status = UserImportUpdated
+		if opts.DryRun {
+			return
+		}
+		if err := s.updateImportedUser(row); err != nil {
+			result.Status = UserImportFailed
+			result.Errors = append(result.Errors, err.Error())
+		}
+		return
+	}
+
+	result.Status = UserImportCreated
+	if opts.DryRun {
+		return
+	}
+
+	user := row.user
+	user.Source = model.UserSourceLocal
+	user.Status = 1
+	if row.status != nil {
+		user.Status = *row.status
+	}
+	password := user.Password
+	if password == "" {
+		generated, err := generateInitialPassword()
+		if err != nil {
+			result.Status = UserImportFailed
+			result.Errors = append(result.Errors, err.Error())
+			return
+		}
+		password = generated
+		result.Password = generated
+	}
+	hashed, err := s.hashPassword(password)
+	if err != nil {
+		result.Status = UserImportFailed
+		result.Errors = append(result.Errors, err.Error())
+		return
+	}
+	user.Password = hashed
+	user.PasswordChangeRequired = true
+
+	if err := s.userRepo.CreateWithRoles(user, row.roleIDs); err != nil {
+		result.Status = UserImportFailed
+		result.Password = ""
+		result.Errors = append(result.Errors, err.Error())
+	}
+}
+
+// updateImportedUser 更新已存在的用户，空单元格保留原值
+func (s *userService) updateImportedUser(row *userImportRow) error {
+	existing := row.existing
+	fields := make(map[string]interface{})
+	if row.user.Nickname != "" {
+		fields["nickname"] = row.user.Nickname
+	}
+	if row.user.Email != "" {
+		fields["email"] = row.user.Email
+	}
+	if row.user.Phone != "" {
+		fields["phone"] = row.user.Phone
+	}
+	if row.status != nil {
+		fields["status"] = *row.status
+	}
+	if len(fields) > 0 {
+		if err := s.userRepo.UpdateFields(existing.ID, fields); err != nil {
+			return err
+		}
+	}
+	if row.hasRoles {
+		if err := s.userRepo.AssignRoles(existing.ID, row.roleIDs); err != nil {
+			return err
+		}
+	}
+	if row.status != nil && *row.status != 1 && existing.Status == 1 {
+		return s.tokenService.RevokeUserSessions(existing.ID, model.SessionRevokeUserDisabled)
+	}
+	return nil
+}
+
+// BatchSetStatus 批量启用或禁用用户，禁用时吊销其全部登录会话
+func (s *userService) BatchSetStatus(ids []uint, status int) (int64, error) {
+	if len(ids) == 0 {
+		return 0, errors.New("请选择用户")
+	}
+	if len(ids) > UserBatchMaxSize {
+		return 0, fmt.Errorf("单次最多操作%d个用户", UserBatchMaxSize)
+	}
+	if status != 0 && status != 1 {
+		return 0, fmt.Errorf("无效的状态: %d", status)
+	}
+
+	affected, err := s.userRepo.UpdateStatus(ids, status)
+	if err != nil {
+		return 0, err
+	}
+	if status == 0 {
+		for _, id := range ids {
+			if err := s.tokenService.RevokeUserSessions(id, model.SessionRevokeUserDisabled); err != nil {
+				return affected, err
+			}
+		}
+	}
+	return affected, nil
+}
+
+// BatchAssignRoles 批量分配角色，mode 为 replace（替换）、add（追加）或 remove（移除）
+func (s *userService) BatchAssignRoles(userIDs, roleIDs []uint, mode string) error {
+	if len(userIDs) == 0 {
+		return errors.New("请选择用户")
+	}
+	if len(userIDs) > UserBatchMaxSize {
+		return fmt.Errorf("单次最多操作%d个用户", UserBatchMaxSize)
+	}
+	switch mode {
+	case model.RoleAssignReplace, model.RoleAssignAdd, model.RoleAssignRemove:
+	default:
+		return fmt.Errorf("不支持的分配方式: %s", mode)
+	}
+	if mode != model.RoleAssignReplace && len(roleIDs) == 0 {
+		return errors.New("请选择角色")
+	}
+
+	roleIDs = uniqueUints(roleIDs)
+	roles, err := s.roleRepo.FindByIDs(roleIDs)
+	if err != nil {
+		return err
+	}
+	if len(roles) != len(roleIDs) {
+		return errors.New("部分角色不存在")
+	}
+	return s.userRepo.BatchAssignRoles(uniqueUints(userIDs), roleIDs, mode)
+}
+
+// ListAll 获取全部用户及其角色，用于导出
+func (s *userService) ListAll() ([]*model.User, error) {
+	return s.userRepo.ListAll()
+}
+
+// isBlankRow 是否为空行
+func isBlankRow(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// generateInitialPassword 生成包含大小写字母、数字和特殊字符的随机初始密码
+func generateInitialPassword() (string, error) {
+	const length = 16
+	sets := []string{
+		"ABCDEFGHJKLMNPQRSTUVWXYZ",
+		"abcdefghijkmnpqrstuvwxyz",
+		"23456789",
+		"!@#$%^&*-_=+",
+	}
+	all := strings.Join(sets, "")
+
+	pick := func(chars string) (byte, error) {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			return 0, err
+		}
+		return chars[n.Int64()], nil
+	}
+
+	password := make([]byte, 0, length)
+	for _, set := range sets {
+		c, err := pick(set)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < length {
+		c, err := pick(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	// 打乱顺序，避免固定位置的字符类别
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
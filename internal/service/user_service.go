@@ -1,19 +1,30 @@
 package service
 
 import (
+	"crypto/sha256"
 	"eden-ops/internal/model"
 	"eden-ops/internal/repository"
-	"eden-ops/pkg/auth"
+	"eden-ops/pkg/config"
 	"eden-ops/pkg/logger"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrInvalidCredentials 用户名或密码错误
+var ErrInvalidCredentials = errors.New("用户名或密码错误")
+
 // UserService 用户服务接口
 type UserService interface {
-	Login(username, password string) (string, error)
+	Login(username, password, clientIP string) (*model.User, error)
+	VerifyTOTP(userID uint, code, clientIP string) (*model.User, error)
+	ChangePassword(userID uint, oldPassword, newPassword string) error
+	ChangeRequiredPassword(userID uint, newPassword string) (*model.User, error)
+	Unlock(userID uint) error
 	Create(user *model.User) error
 	Update(user *model.User) error
 	Delete(id uint) error
@@ -22,61 +33,233 @@ type UserService interface {
 	GetUserInfo(id uint) (*model.User, error)
 	GetUserRoles(userID uint) ([]*model.Role, error)
 	AssignRoles(userID uint, roleIDs []uint) error
+	ImportUsers(rows [][]string, opts UserImportOptions) (*UserImportResult, error)
+	BatchSetStatus(ids []uint, status int) (int64, error)
+	BatchAssignRoles(userIDs, roleIDs []uint, mode string) error
+	ListAll() ([]*model.User, error)
 }
 
 // userService 用户服务实现
 type userService struct {
-	userRepo repository.UserRepository
-	jwtAuth  *auth.JWTAuth
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+	tokenService TokenService
+	ldapService  LDAPService
+	totpService  TOTPService
+	limiter      *loginLimiter
+	policy       *passwordPolicy
 }
 
 // NewUserService 创建用户服务
-func NewUserService(userRepo repository.UserRepository, jwtAuth *auth.JWTAuth) UserService {
+func NewUserService(userRepo repository.UserRepository, roleRepo repository.RoleRepository, tokenService TokenService, ldapService LDAPService, totpService TOTPService, cfg config.SecurityConfig) UserService {
 	return &userService{
-		userRepo: userRepo,
-		jwtAuth:  jwtAuth,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		tokenService: tokenService,
+		ldapService:  ldapService,
+		totpService:  totpService,
+		limiter:      newLoginLimiter(cfg.Lockout),
+		policy:       newPasswordPolicy(cfg.PasswordPolicy),
 	}
 }
 
-// Login 用户登录
-func (s *userService) Login(username, password string) (string, error) {
-	user, err := s.userRepo.GetByUsername(username)
+// Login 校验用户名和密码，返回登录用户；同一用户名或IP失败次数过多时临时锁定
+func (s *userService) Login(username, password, clientIP string) (*model.User, error) {
+	now := time.Now()
+	if wait := s.limiter.check(username, clientIP, now); wait > 0 {
+		logger.Warn("登录已锁定: username=%s, ip=%s, remaining=%s", username, clientIP, wait.Round(time.Second))
+		return nil, errors.New(lockoutMessage(wait))
+	}
+
+	user, err := s.authenticate(username, password)
 	if err != nil {
-		logger.Info("登录验证失败: 用户不存在, username=%s", username)
-		return "", errors.New("用户名或密码错误")
+		if errors.Is(err, ErrInvalidCredentials) {
+			if locked := s.limiter.fail(username, clientIP, now); locked > 0 {
+				logger.Warn("登录失败次数过多，临时锁定: username=%s, ip=%s, duration=%s", username, clientIP, locked)
+				return nil, errors.New(lockoutMessage(locked))
+			}
+		}
+		return nil, err
+	}
+
+	// 启用两步验证的用户在验证码校验通过后才清除失败记录
+	if !user.TOTPEnabled {
+		s.limiter.succeed(username)
+	}
+	return user, nil
+}
+
+// authenticate 本地用户始终使用本地密码，其余用户在启用LDAP时通过LDAP认证
+func (s *userService) authenticate(username, password string) (*model.User, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err == nil && strings.HasPrefix(user.Source, model.UserSourceOIDCPrefix) {
+		logger.Info("登录验证失败: 单点登录用户不支持密码登录, username=%s", username)
+		return nil, errors.New("该用户请使用单点登录")
+	}
+	if err == nil && user.IsServiceAccount() {
+		logger.Info("登录验证失败: 服务账号不支持登录, username=%s", username)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil || !user.IsLocal() {
+		if s.ldapService.Enabled() {
+			return s.ldapService.Login(username, password)
+		}
+		logger.Info("登录验证失败: 用户不存在或LDAP未启用, username=%s", username)
+		return nil, ErrInvalidCredentials
 	}
 
 	// 验证密码
-	if err := s.verifyPassword(user.Password, password); err != nil {
+	legacy, err := s.verifyPassword(user.Password, password)
+	if err != nil {
 		logger.Info("登录验证失败: 密码错误, username=%s", username)
-		return "", errors.New("用户名或密码错误")
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.Status != 1 {
+		logger.Info("登录验证失败: 用户已禁用, username=%s", username)
+		return nil, errors.New("用户已被禁用")
 	}
 
-	token, err := s.jwtAuth.GenerateToken(user.ID, user.Username)
+	if legacy {
+		s.upgradePassword(user, password)
+	}
+	return user, nil
+}
+
+// VerifyTOTP 校验登录时的两步验证码或恢复码，失败次数计入登录锁定
+func (s *userService) VerifyTOTP(userID uint, code, clientIP string) (*model.User, error) {
+	user, err := s.userRepo.Get(userID)
 	if err != nil {
-		logger.Error("登录失败: 生成token错误, username=%s, error=%v", username, err)
-		return "", fmt.Errorf("生成token失败: %v", err)
+		return nil, ErrInvalidLoginChallenge
+	}
+	if user.Status != 1 {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	now := time.Now()
+	if wait := s.limiter.check(user.Username, clientIP, now); wait > 0 {
+		logger.Warn("两步验证已锁定: username=%s, ip=%s, remaining=%s", user.Username, clientIP, wait.Round(time.Second))
+		return nil, errors.New(lockoutMessage(wait))
+	}
+
+	if err := s.totpService.Verify(user, code); err != nil {
+		if errors.Is(err, ErrInvalidTOTPCode) {
+			logger.Info("两步验证失败: username=%s, ip=%s", user.Username, clientIP)
+			if locked := s.limiter.fail(user.Username, clientIP, now); locked > 0 {
+				logger.Warn("两步验证失败次数过多，临时锁定: username=%s, ip=%s, duration=%s", user.Username, clientIP, locked)
+				return nil, errors.New(lockoutMessage(locked))
+			}
+		}
+		return nil, err
+	}
+
+	s.limiter.succeed(user.Username)
+	return user, nil
+}
+
+// ChangePassword 用户修改自己的密码，修改后吊销全部登录会话
+func (s *userService) ChangePassword(userID uint, oldPassword, newPassword string) error {
+	user, err := s.userRepo.Get(userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsLocal() {
+		return errors.New("外部用户请在身份源修改密码")
+	}
+	if _, err := s.verifyPassword(user.Password, oldPassword); err != nil {
+		return errors.New("原密码错误")
+	}
+
+	if err := s.setPassword(user, newPassword); err != nil {
+		return err
+	}
+	logger.Info("用户修改密码: username=%s, userID=%d", user.Username, userID)
+	return s.tokenService.RevokeUserSessions(userID, model.SessionRevokePasswordChanged)
+}
+
+// ChangeRequiredPassword 首次登录或密码被重置后修改密码，完成后才允许登录
+func (s *userService) ChangeRequiredPassword(userID uint, newPassword string) (*model.User, error) {
+	user, err := s.userRepo.Get(userID)
+	if err != nil || !user.PasswordChangeRequired || !user.IsLocal() {
+		return nil, ErrInvalidLoginChallenge
+	}
+	if user.Status != 1 {
+		return nil, errors.New("用户已被禁用")
 	}
 
-	return token, nil
+	if err := s.setPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+	logger.Info("用户修改初始密码: username=%s, userID=%d", user.Username, userID)
+	return user, nil
+}
+
+// Unlock 解除用户名的登录锁定
+func (s *userService) Unlock(userID uint) error {
+	user, err := s.userRepo.Get(userID)
+	if err != nil {
+		return err
+	}
+	s.limiter.succeed(user.Username)
+	logger.Info("解除用户登录锁定: username=%s, userID=%d", user.Username, userID)
+	return nil
 }
 
-// verifyPassword 验证密码（支持前端 SHA256 加密）
-func (s *userService) verifyPassword(hashedPassword, inputPassword string) error {
-	// 直接验证 bcrypt 哈希（兼容旧的直接密码存储）
+// verifyPassword 验证密码；legacy 表示命中旧版前端 SHA256 摘要方案，需要迁移为原始密码的 bcrypt 哈希
+func (s *userService) verifyPassword(hashedPassword, inputPassword string) (legacy bool, err error) {
 	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(inputPassword)); err == nil {
-		return nil
+		return false, nil
 	}
 
-	// 如果输入密码长度为64，可能是前端 SHA256 加密的结果
-	if len(inputPassword) == 64 {
-		// 这种情况下，数据库中应该存储的是 SHA256 密码的 bcrypt 哈希
-		// 但当前数据库存储的是原始密码的 bcrypt 哈希，所以这里会失败
-		// 我们需要更新数据库或者提供迁移方案
-		logger.Warn("检测到SHA256密码但数据库存储原始密码哈希，需要更新数据库")
+	// 旧版登录页提交的是密码的 SHA256 摘要，库中保存的是摘要的 bcrypt 哈希
+	sum := sha256.Sum256([]byte(inputPassword))
+	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(hex.EncodeToString(sum[:]))); err == nil {
+		return true, nil
 	}
 
-	return fmt.Errorf("密码验证失败")
+	return false, fmt.Errorf("密码验证失败")
+}
+
+// upgradePassword 将旧方案保存的密码重新哈希为原始密码的 bcrypt 哈希，失败时下次登录重试
+func (s *userService) upgradePassword(user *model.User, password string) {
+	hashedPassword, err := s.hashPassword(password)
+	if err != nil {
+		logger.Error("迁移用户密码哈希失败: username=%s, %v", user.Username, err)
+		return
+	}
+	if err := s.userRepo.UpdateFields(user.ID, map[string]interface{}{"password": hashedPassword}); err != nil {
+		logger.Error("迁移用户密码哈希失败: username=%s, %v", user.Username, err)
+		return
+	}
+	user.Password = hashedPassword
+	logger.Info("已迁移用户密码哈希: username=%s", user.Username)
+}
+
+// setPassword 校验密码策略后保存新密码，并清除强制修改密码标记
+func (s *userService) setPassword(user *model.User, newPassword string) error {
+	if err := s.policy.validate(user.Username, newPassword); err != nil {
+		return err
+	}
+	if _, err := s.verifyPassword(user.Password, newPassword); err == nil {
+		return errors.New("新密码不能与原密码相同")
+	}
+
+	hashedPassword, err := s.hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := s.userRepo.UpdateFields(user.ID, map[string]interface{}{
+		"password":                 hashedPassword,
+		"password_change_required": false,
+		"password_changed_at":      now,
+	}); err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	user.PasswordChangeRequired = false
+	user.PasswordChangedAt = &now
+	return nil
 }
 
 // hashPassword 加密密码
@@ -91,31 +274,74 @@ func (s *userService) hashPassword(password string) (string, error) {
 	return string(hashedPassword), nil
 }
 
-// Create 创建用户
+// Create 创建用户，初始密码须符合密码策略，用户首次登录时须修改密码
 func (s *userService) Create(user *model.User) error {
+	if err := s.policy.validate(user.Username, user.Password); err != nil {
+		return err
+	}
 	hashedPassword, err := s.hashPassword(user.Password)
 	if err != nil {
 		return err
 	}
 	user.Password = hashedPassword
+	user.PasswordChangeRequired = true
 
 	return s.userRepo.Create(user)
 }
 
-// Update 更新用户
+// Update 更新用户，禁用用户或修改密码时吊销其全部登录会话；管理员重置密码后用户下次登录须修改密码
 func (s *userService) Update(user *model.User) error {
-	hashedPassword, err := s.hashPassword(user.Password)
+	existing, err := s.userRepo.Get(user.ID)
 	if err != nil {
 		return err
 	}
-	user.Password = hashedPassword
+	user.CreatedAt = existing.CreatedAt
+	user.Source = existing.Source
+	user.TOTPEnabled = existing.TOTPEnabled
+	user.TOTPSecret = existing.TOTPSecret
+	user.TOTPLastStep = existing.TOTPLastStep
+	user.PasswordChangeRequired = existing.PasswordChangeRequired
+	user.PasswordChangedAt = existing.PasswordChangedAt
+
+	passwordChanged := false
+	if user.Password == "" {
+		user.Password = existing.Password
+	} else if _, err := s.verifyPassword(existing.Password, user.Password); err == nil {
+		user.Password = existing.Password
+	} else {
+		if err := s.policy.validate(existing.Username, user.Password); err != nil {
+			return err
+		}
+		hashedPassword, err := s.hashPassword(user.Password)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		user.Password = hashedPassword
+		user.PasswordChangeRequired = true
+		user.PasswordChangedAt = &now
+		passwordChanged = true
+	}
 
-	return s.userRepo.Update(user)
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	switch {
+	case user.Status != 1:
+		return s.tokenService.RevokeUserSessions(user.ID, model.SessionRevokeUserDisabled)
+	case passwordChanged:
+		return s.tokenService.RevokeUserSessions(user.ID, model.SessionRevokePasswordChanged)
+	}
+	return nil
 }
 
-// Delete 删除用户
+// Delete 删除用户并吊销其全部登录会话
 func (s *userService) Delete(id uint) error {
-	return s.userRepo.Delete(id)
+	if err := s.userRepo.Delete(id); err != nil {
+		return err
+	}
+	return s.tokenService.RevokeUserSessions(id, model.SessionRevokeUserDeleted)
 }
 
 // Get 获取用户
@@ -0,0 +1,80 @@
+package task
+
+import (
+	"context"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/logger"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// defaultCloudSyncInterval 默认云资源同步间隔
+const defaultCloudSyncInterval = time.Hour
+
+// CloudSyncTask 云资源同步任务
+type CloudSyncTask struct {
+	service  service.CloudResourceService
+	interval time.Duration
+	cron     *cron.Cron
+	running  int32
+}
+
+// NewCloudSyncTask 创建云资源同步任务
+func NewCloudSyncTask(service service.CloudResourceService, interval string) *CloudSyncTask {
+	d, err := time.ParseDuration(interval)
+	if err != nil || d < time.Minute {
+		if interval != "" {
+			logger.Warn("云资源同步间隔 %s 无效，使用默认值 %v", interval, defaultCloudSyncInterval)
+		}
+		d = defaultCloudSyncInterval
+	}
+	return &CloudSyncTask{
+		service:  service,
+		interval: d,
+		cron:     cron.New(),
+	}
+}
+
+// Start 启动同步任务
+func (t *CloudSyncTask) Start(ctx context.Context) error {
+	logger.Info("启动云资源同步任务，间隔 %v", t.interval)
+
+	_, err := t.cron.AddFunc(fmt.Sprintf("@every %s", t.interval), t.syncAll)
+	if err != nil {
+		return err
+	}
+	t.cron.Start()
+
+	// 启动后立即同步一次
+	go t.syncAll()
+
+	// 监听上下文取消
+	go func() {
+		<-ctx.Done()
+		t.Stop()
+		logger.Info("停止云资源同步任务")
+	}()
+
+	return nil
+}
+
+// Stop 停止任务
+func (t *CloudSyncTask) Stop() {
+	if t.cron != nil {
+		t.cron.Stop()
+	}
+}
+
+// syncAll 同步所有云账号，上一轮未结束时跳过
+func (t *CloudSyncTask) syncAll() {
+	if !atomic.CompareAndSwapInt32(&t.running, 0, 1) {
+		logger.Warn("上一轮云资源同步尚未结束，跳过本次同步")
+		return
+	}
+	defer atomic.StoreInt32(&t.running, 0)
+
+	t.service.SyncAll()
+}
@@ -0,0 +1,71 @@
+package task
+
+import (
+	"context"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/logger"
+	"sync/atomic"
+
+	"github.com/robfig/cron/v3"
+)
+
+// defaultK8sCapacitySchedule 默认每周一 08:00 生成容量汇总
+const defaultK8sCapacitySchedule = "0 8 * * 1"
+
+// K8sCapacitySummaryTask 每周容量汇总任务
+type K8sCapacitySummaryTask struct {
+	service      service.K8sCapacityService
+	schedule     string
+	includeUsage bool
+	cron         *cron.Cron
+	running      int32
+}
+
+// NewK8sCapacitySummaryTask 创建每周容量汇总任务
+func NewK8sCapacitySummaryTask(service service.K8sCapacityService, schedule string, includeUsage bool) *K8sCapacitySummaryTask {
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		if schedule != "" {
+			logger.Warn("容量汇总时间 %s 无效，使用默认值 %s", schedule, defaultK8sCapacitySchedule)
+		}
+		schedule = defaultK8sCapacitySchedule
+	}
+	return &K8sCapacitySummaryTask{
+		service:      service,
+		schedule:     schedule,
+		includeUsage: includeUsage,
+		cron:         cron.New(),
+	}
+}
+
+// Start 启动汇总任务
+func (t *K8sCapacitySummaryTask) Start(ctx context.Context) error {
+	logger.Info("启动每周容量汇总任务，执行时间 %s", t.schedule)
+
+	_, err := t.cron.AddFunc(t.schedule, t.generate)
+	if err != nil {
+		return err
+	}
+	t.cron.Start()
+
+	// 监听上下文取消
+	go func() {
+		<-ctx.Done()
+		t.cron.Stop()
+		logger.Info("停止每周容量汇总任务")
+	}()
+
+	return nil
+}
+
+// generate 生成本周的容量汇总，上一轮未结束时跳过
+func (t *K8sCapacitySummaryTask) generate() {
+	if !atomic.CompareAndSwapInt32(&t.running, 0, 1) {
+		logger.Warn("上一轮容量汇总尚未结束，跳过本次执行")
+		return
+	}
+	defer atomic.StoreInt32(&t.running, 0)
+
+	if err := t.service.GenerateWeeklySummaries(t.includeUsage); err != nil {
+		logger.Error("生成每周容量汇总失败: %v", err)
+	}
+}
@@ -71,7 +71,7 @@ func (t *K8sSyncTask) RefreshJobs() {
 // refreshSyncJobs 刷新同步任务
 func (t *K8sSyncTask) refreshSyncJobs() {
 	// 获取所有启用的Kubernetes配置
-	configs, _, err := t.service.List(1, 1000, "", nil, nil, "")
+	configs, _, err := t.service.List(1, 1000, "", nil, nil, "", nil)
 	if err != nil {
 		logger.Error("获取Kubernetes配置列表失败: %v", err)
 		return
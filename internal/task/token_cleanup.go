@@ -0,0 +1,51 @@
+package task
+
+import (
+	"context"
+	"eden-ops/internal/service"
+	"eden-ops/pkg/logger"
+
+	"github.com/robfig/cron/v3"
+)
+
+// TokenCleanupTask 过期登录会话和令牌吊销记录清理任务
+type TokenCleanupTask struct {
+	service service.TokenService
+	cron    *cron.Cron
+}
+
+// NewTokenCleanupTask 创建令牌清理任务
+func NewTokenCleanupTask(service service.TokenService) *TokenCleanupTask {
+	return &TokenCleanupTask{
+		service: service,
+		cron:    cron.New(),
+	}
+}
+
+// Start 启动清理任务，每小时执行一次
+func (t *TokenCleanupTask) Start(ctx context.Context) error {
+	_, err := t.cron.AddFunc("@hourly", t.cleanup)
+	if err != nil {
+		return err
+	}
+	t.cron.Start()
+
+	go func() {
+		<-ctx.Done()
+		t.cron.Stop()
+	}()
+
+	return nil
+}
+
+// cleanup 清理过期数据
+func (t *TokenCleanupTask) cleanup() {
+	deleted, err := t.service.CleanupExpired()
+	if err != nil {
+		logger.Error("清理过期登录会话失败: %v", err)
+		return
+	}
+	if deleted > 0 {
+		logger.Info("清理过期登录会话及吊销记录 %d 条", deleted)
+	}
+}
@@ -0,0 +1,10 @@
+package auth
+
+// APITokenClaims API令牌认证后的身份信息
+type APITokenClaims struct {
+	TokenID  uint
+	UserID   uint
+	Username string
+	// Scopes 令牌可使用的权限标识，实际权限为其与用户权限的交集
+	Scopes []string
+}
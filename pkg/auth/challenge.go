@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ChallengeClaims 登录挑战令牌声明，密码校验通过后用于完成两步验证、修改初始密码等后续步骤
+type ChallengeClaims struct {
+	UserID  uint   `json:"user_id"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// GenerateChallengeToken 生成登录挑战令牌；使用独立派生的密钥签名，不能当作访问令牌使用
+func (j *JWTAuth) GenerateChallengeToken(userID uint, purpose string, ttl time.Duration) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := &ChallengeClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "eden-ops",
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.challengeKey())
+}
+
+// ParseChallengeToken 解析登录挑战令牌，校验用途后返回用户ID
+func (j *JWTAuth) ParseChallengeToken(tokenString, purpose string) (uint, error) {
+	claims := &ChallengeClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("不支持的签名算法: %v", token.Header["alg"])
+		}
+		return j.challengeKey(), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if claims.Purpose != purpose || claims.UserID == 0 {
+		return 0, ErrTokenInvalid
+	}
+	return claims.UserID, nil
+}
+
+// challengeKey 挑战令牌签名密钥
+func (j *JWTAuth) challengeKey() []byte {
+	key := sha256.Sum256([]byte("eden-ops/login-challenge:" + j.secret))
+	return key[:]
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"log"
 	"time"
@@ -14,44 +16,64 @@ var (
 	ErrTokenNotValidYet = errors.New("令牌尚未生效")
 	ErrTokenMalformed   = errors.New("令牌格式错误")
 	ErrTokenInvalid     = errors.New("令牌无效")
+	ErrTokenRevoked     = errors.New("令牌已失效")
 )
 
 // CustomClaims 自定义声明
 type CustomClaims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
+	// SessionID 登录会话ID，会话失效后令牌随之失效
+	SessionID uint `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// JWTAuth JWT认证
+// JWTAuth JWT认证，签发短期访问令牌，续期由服务端会话的刷新令牌完成
 type JWTAuth struct {
 	secret string
 	expire time.Duration
 }
 
-// NewJWTAuth 创建JWT认证
-func NewJWTAuth(secret string, expire int) *JWTAuth {
+// NewJWTAuth 创建JWT认证，expire 为访问令牌有效期
+func NewJWTAuth(secret string, expire time.Duration) *JWTAuth {
 	return &JWTAuth{
 		secret: secret,
-		expire: time.Duration(expire) * time.Hour, // 配置中的过期时间单位是小时
+		expire: expire,
 	}
 }
 
-// GenerateToken 生成令牌
-func (j *JWTAuth) GenerateToken(userID uint, username string) (string, error) {
-	claims := CustomClaims{
-		UserID:   userID,
-		Username: username,
+// Expire 访问令牌有效期
+func (j *JWTAuth) Expire() time.Duration {
+	return j.expire
+}
+
+// GenerateToken 为登录会话生成访问令牌，每个令牌带有唯一的 jti
+func (j *JWTAuth) GenerateToken(userID uint, username string, sessionID uint) (string, *CustomClaims, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := &CustomClaims{
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.expire)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.expire)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "eden-ops",
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secret))
+	signed, err := token.SignedString([]byte(j.secret))
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
 // ParseToken 解析令牌
@@ -74,14 +96,11 @@ func (j *JWTAuth) ParseToken(tokenString string) (*CustomClaims, error) {
 	return nil, errors.New("invalid token")
 }
 
-// RefreshToken 刷新令牌
-func (j *JWTAuth) RefreshToken(tokenString string) (string, error) {
-	claims, err := j.ParseToken(tokenString)
-	if err != nil {
+// newTokenID 生成令牌ID
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
-
-	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(j.expire))
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secret))
+	return hex.EncodeToString(b), nil
 }
@@ -7,19 +7,27 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
 // Config 系统配置结构
 type Config struct {
-	Server     ServerConfig     `mapstructure:"server"`
-	Database   DatabaseConfig   `mapstructure:"database"`
-	JWT        JWTConfig        `mapstructure:"jwt"`
-	Log        LogConfig        `mapstructure:"log"`
-	Tencent    TencentConfig    `mapstructure:"tencent"`
-	IPLocator  IPLocatorConfig  `mapstructure:"iplocator"`
-	K8sHistory K8sHistoryConfig `mapstructure:"k8s_history"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	JWT         JWTConfig         `mapstructure:"jwt"`
+	Log         LogConfig         `mapstructure:"log"`
+	Tencent     TencentConfig     `mapstructure:"tencent"`
+	IPLocator   IPLocatorConfig   `mapstructure:"iplocator"`
+	K8sHistory  K8sHistoryConfig  `mapstructure:"k8s_history"`
+	K8sCapacity K8sCapacityConfig `mapstructure:"k8s_capacity"`
+	Terminal    TerminalConfig    `mapstructure:"terminal"`
+	SFTP        SFTPConfig        `mapstructure:"sftp"`
+	CloudSync   CloudSyncConfig   `mapstructure:"cloud_sync"`
+	LDAP        LDAPConfig        `mapstructure:"ldap"`
+	OIDC        OIDCConfig        `mapstructure:"oidc"`
+	Security    SecurityConfig    `mapstructure:"security"`
 }
 
 // ServerConfig 服务器配置
@@ -45,9 +53,10 @@ type DatabaseConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret string `mapstructure:"secret"`
-	Expire int    `mapstructure:"expire"`
-	Issuer string `mapstructure:"issuer"`
+	Secret       string `mapstructure:"secret"`
+	Expire       int    `mapstructure:"expire"`        // 登录会话（刷新令牌）有效期(小时)
+	AccessExpire int    `mapstructure:"access_expire"` // 访问令牌有效期(分钟)
+	Issuer       string `mapstructure:"issuer"`
 }
 
 // LogConfig 日志配置
@@ -77,11 +86,129 @@ type IPLocatorConfig struct {
 
 // K8sHistoryConfig K8s历史数据配置
 type K8sHistoryConfig struct {
-	Enabled         bool   `mapstructure:"enabled"`          // 是否启用历史表归档
-	CleanupEnabled  bool   `mapstructure:"cleanup_enabled"`  // 是否启用自动清理
-	CleanupDays     int    `mapstructure:"cleanup_days"`     // 保留天数
-	CleanupInterval string `mapstructure:"cleanup_interval"` // 清理检查间隔
-	BatchSize       int    `mapstructure:"batch_size"`       // 批量操作大小
+	Enabled         bool                    `mapstructure:"enabled"`          // 是否启用历史表归档
+	CleanupEnabled  bool                    `mapstructure:"cleanup_enabled"`  // 是否启用自动清理
+	CleanupDays     int                     `mapstructure:"cleanup_days"`     // 保留天数
+	CleanupInterval string                  `mapstructure:"cleanup_interval"` // 清理检查间隔
+	BatchSize       int                     `mapstructure:"batch_size"`       // 批量操作大小，清理时每批删除的行数
+	BatchSleep      string                  `mapstructure:"batch_sleep"`      // 清理时批次之间的间隔，如 200ms
+	Archive         K8sHistoryArchiveConfig `mapstructure:"archive"`          // 清理前归档到文件存储
+}
+
+// K8sHistoryArchiveConfig K8s历史数据归档配置，启用后清理前先将数据按集群和日期导出为 gzip 压缩的 JSONL 文件
+type K8sHistoryArchiveConfig struct {
+	Enabled  bool         `mapstructure:"enabled"`   // 是否启用归档，归档失败时不删除对应的数据
+	Sink     string       `mapstructure:"sink"`      // 存储类型：local、s3
+	Prefix   string       `mapstructure:"prefix"`    // 文件路径前缀，默认 k8s-history
+	LocalDir string       `mapstructure:"local_dir"` // local 存储的根目录
+	S3       S3SinkConfig `mapstructure:"s3"`        // s3 存储配置，兼容 S3 协议的对象存储均可使用
+}
+
+// S3SinkConfig S3 兼容对象存储配置
+type S3SinkConfig struct {
+	Endpoint  string `mapstructure:"endpoint"`   // 服务地址，如 https://s3.us-east-1.amazonaws.com 或 http://127.0.0.1:9000
+	Region    string `mapstructure:"region"`     // 地域，默认 us-east-1
+	Bucket    string `mapstructure:"bucket"`     // 存储桶
+	AccessKey string `mapstructure:"access_key"` // 访问密钥ID
+	SecretKey string `mapstructure:"secret_key"` // 访问密钥
+	PathStyle bool   `mapstructure:"path_style"` // 是否使用路径风格访问，MinIO 等自建服务通常需要开启
+	Timeout   string `mapstructure:"timeout"`    // 请求超时，默认 5m
+}
+
+// TerminalConfig Web终端配置
+type TerminalConfig struct {
+	RecordDir   string `mapstructure:"record_dir"`   // 会话录像存放目录
+	IdleTimeout string `mapstructure:"idle_timeout"` // 空闲超时时间，如 30m
+	DialTimeout string `mapstructure:"dial_timeout"` // SSH连接超时时间，如 10s
+}
+
+// SFTPConfig 服务器文件传输配置
+type SFTPConfig struct {
+	MaxUploadSize int `mapstructure:"max_upload_size"` // 单文件上传大小上限(MB)
+}
+
+// K8sCapacityConfig K8s容量规划配置
+type K8sCapacityConfig struct {
+	WeeklySummary bool   `mapstructure:"weekly_summary"` // 是否每周为启用的集群生成容量汇总
+	Schedule      string `mapstructure:"schedule"`       // 生成时间，cron 表达式（分 时 日 月 周），默认每周一 08:00
+	IncludeUsage  bool   `mapstructure:"include_usage"`  // 生成汇总时是否从 metrics-server 获取用量并给出调整建议
+}
+
+// CloudSyncConfig 云资源同步配置
+type CloudSyncConfig struct {
+	Interval string   `mapstructure:"interval"` // 同步间隔，如 1h
+	Regions  []string `mapstructure:"regions"`  // 同步的地域，为空时同步云账号所有可用地域
+}
+
+// LDAPConfig LDAP/AD 登录配置
+type LDAPConfig struct {
+	Enabled            bool                `mapstructure:"enabled"`              // 是否启用LDAP登录
+	URL                string              `mapstructure:"url"`                  // 服务地址，如 ldap://dc.example.com:389 或 ldaps://dc.example.com:636
+	StartTLS           bool                `mapstructure:"start_tls"`            // ldap:// 连接是否升级为 StartTLS
+	InsecureSkipVerify bool                `mapstructure:"insecure_skip_verify"` // 是否跳过证书校验
+	Timeout            string              `mapstructure:"timeout"`              // 连接及请求超时，如 10s
+	BindDN             string              `mapstructure:"bind_dn"`              // 查询用户使用的账号，为空时匿名查询
+	BindPassword       string              `mapstructure:"bind_password"`        // 查询账号密码
+	BaseDN             string              `mapstructure:"base_dn"`              // 用户搜索根节点
+	UserFilter         string              `mapstructure:"user_filter"`          // 用户过滤条件，%s 替换为用户名，如 (sAMAccountName=%s)
+	UsernameAttribute  string              `mapstructure:"username_attribute"`   // 用户名属性
+	NicknameAttribute  string              `mapstructure:"nickname_attribute"`   // 昵称属性
+	EmailAttribute     string              `mapstructure:"email_attribute"`      // 邮箱属性
+	PhoneAttribute     string              `mapstructure:"phone_attribute"`      // 手机号属性
+	GroupAttribute     string              `mapstructure:"group_attribute"`      // 用户条目上的所属组属性，如 memberOf
+	GroupBaseDN        string              `mapstructure:"group_base_dn"`        // 组搜索根节点，为空时不搜索组
+	GroupFilter        string              `mapstructure:"group_filter"`         // 组过滤条件，%s 替换为用户DN，如 (member=%s)
+	GroupRoles         map[string][]string `mapstructure:"group_roles"`          // 组（DN 或 CN）到角色编码的映射
+	DefaultRoles       []string            `mapstructure:"default_roles"`        // 所有LDAP用户默认拥有的角色编码
+}
+
+// OIDCConfig OIDC单点登录配置
+type OIDCConfig struct {
+	FrontendURL string               `mapstructure:"frontend_url"` // 登录完成后跳转的前端页面，令牌通过URL片段传递
+	Providers   []OIDCProviderConfig `mapstructure:"providers"`
+}
+
+// OIDCProviderConfig OIDC身份提供方配置
+type OIDCProviderConfig struct {
+	Name          string              `mapstructure:"name"`           // 提供方标识，用于登录及回调地址
+	DisplayName   string              `mapstructure:"display_name"`   // 登录页显示名称
+	Issuer        string              `mapstructure:"issuer"`         // Issuer地址，用于服务发现
+	ClientID      string              `mapstructure:"client_id"`      // 客户端ID
+	ClientSecret  string              `mapstructure:"client_secret"`  // 客户端密钥，公共客户端可为空
+	RedirectURL   string              `mapstructure:"redirect_url"`   // 回调地址，如 https://ops.example.com/api/v1/oidc/{name}/callback
+	Scopes        []string            `mapstructure:"scopes"`         // 额外申请的scope，openid 自动添加
+	UsernameClaim string              `mapstructure:"username_claim"` // 用户名声明，默认 preferred_username
+	NicknameClaim string              `mapstructure:"nickname_claim"` // 昵称声明，默认 name
+	EmailClaim    string              `mapstructure:"email_claim"`    // 邮箱声明，默认 email
+	RolesClaim    string              `mapstructure:"roles_claim"`    // 角色映射使用的声明，支持 a.b 路径，如 groups、realm_access.roles
+	ClaimRoles    map[string][]string `mapstructure:"claim_roles"`    // 声明值到角色编码的映射
+	DefaultRoles  []string            `mapstructure:"default_roles"`  // 该提供方用户默认拥有的角色编码
+}
+
+// SecurityConfig 登录安全配置
+type SecurityConfig struct {
+	Lockout         LoginLockoutConfig   `mapstructure:"lockout"`
+	PasswordPolicy  PasswordPolicyConfig `mapstructure:"password_policy"`
+	TOTPIssuer      string               `mapstructure:"totp_issuer"`        // 身份验证器中显示的发行方名称，默认 eden-ops
+	APITokenMaxDays int                  `mapstructure:"api_token_max_days"` // API令牌最长有效天数，默认365
+}
+
+// LoginLockoutConfig 登录失败锁定配置，锁定期间拒绝登录，同一账号再次被锁定时锁定时长翻倍
+type LoginLockoutConfig struct {
+	MaxFailures     int    `mapstructure:"max_failures"`      // 同一用户名在统计窗口内允许的失败次数，默认5
+	IPMaxFailures   int    `mapstructure:"ip_max_failures"`   // 同一IP在统计窗口内允许的失败次数，默认20
+	Window          string `mapstructure:"window"`            // 失败次数统计窗口，如 15m
+	LockDuration    string `mapstructure:"lock_duration"`     // 首次锁定时长，如 5m
+	MaxLockDuration string `mapstructure:"max_lock_duration"` // 最长锁定时长，如 1h
+}
+
+// PasswordPolicyConfig 本地用户密码策略
+type PasswordPolicyConfig struct {
+	MinLength      int  `mapstructure:"min_length"`      // 最小长度，默认8
+	RequireUpper   bool `mapstructure:"require_upper"`   // 是否要求大写字母
+	RequireLower   bool `mapstructure:"require_lower"`   // 是否要求小写字母
+	RequireDigit   bool `mapstructure:"require_digit"`   // 是否要求数字
+	RequireSpecial bool `mapstructure:"require_special"` // 是否要求特殊字符
 }
 
 // LoadFromEnv 从环境变量加载配置
@@ -130,9 +257,22 @@ func LoadConfigFromYAML(filename string) (*Config, error) {
 		return nil, err
 	}
 
-	// 解析配置
+	// 解析配置，字段按 mapstructure 标签匹配，与 LoadConfig 保持一致
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		Result:           &config,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(raw); err != nil {
 		return nil, err
 	}
 
@@ -168,6 +308,8 @@ func LoadFromEnv() (*Config, error) {
 	cfg.JWT.Secret = os.Getenv("JWT_SECRET")
 	expire, _ := strconv.Atoi(os.Getenv("JWT_EXPIRE"))
 	cfg.JWT.Expire = expire
+	accessExpire, _ := strconv.Atoi(os.Getenv("JWT_ACCESS_EXPIRE"))
+	cfg.JWT.AccessExpire = accessExpire
 	cfg.JWT.Issuer = "eden-ops"
 
 	// 日志配置
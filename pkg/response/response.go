@@ -13,12 +13,14 @@ type Response struct {
 	Data    interface{} `json:"data"`
 }
 
-// PageData represents paginated data
+// PageData 列表接口统一的分页数据，nextCursor 为下一页的游标，仅支持游标分页的列表返回
 type PageData struct {
-	List     interface{} `json:"list"`
-	Total    int64       `json:"total"`
-	Page     int         `json:"page"`
-	PageSize int         `json:"pageSize"`
+	List       interface{} `json:"list"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"pageSize"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	HasMore    bool        `json:"hasMore"`
 }
 
 // Success 成功响应
@@ -57,18 +59,9 @@ func FailedWithCode(c *gin.Context, code int, message string) {
 	})
 }
 
-// PageSuccess 分页成功响应
-func PageSuccess(c *gin.Context, items interface{}, total int64) {
-	c.JSON(http.StatusOK, Response{
-		Code:    0,
-		Message: "success",
-		Data: PageData{
-			List:     items,
-			Total:    total,
-			Page:     1,
-			PageSize: 10,
-		},
-	})
+// ListSuccess 列表成功响应
+func ListSuccess(c *gin.Context, data PageData) {
+	Success(c, data)
 }
 
 // Unauthorized 未授权响应
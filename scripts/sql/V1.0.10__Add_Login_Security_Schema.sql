@@ -0,0 +1,29 @@
+-- 首次登录修改密码及两步验证字段
+ALTER TABLE `sys_user`
+  ADD COLUMN `password_change_required` tinyint(1) NOT NULL DEFAULT 0 COMMENT '下次登录是否须修改密码：管理员创建或重置密码后为1' AFTER `source`,
+  ADD COLUMN `password_changed_at` datetime DEFAULT NULL COMMENT '最近修改密码时间' AFTER `password_change_required`,
+  ADD COLUMN `totp_enabled` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否启用两步验证' AFTER `password_changed_at`,
+  ADD COLUMN `totp_secret` varchar(64) DEFAULT NULL COMMENT 'TOTP密钥，启用前保存待确认的密钥' AFTER `totp_enabled`,
+  ADD COLUMN `totp_last_step` bigint NOT NULL DEFAULT 0 COMMENT '最近一次使用的验证码时间步，防止重放' AFTER `totp_secret`;
+
+-- 两步验证恢复码，仅保存 SHA-256 摘要
+CREATE TABLE IF NOT EXISTS `sys_user_recovery_code` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint NOT NULL COMMENT '用户ID',
+  `code_hash` varchar(64) NOT NULL COMMENT '恢复码摘要',
+  `used_at` datetime DEFAULT NULL COMMENT '使用时间，未使用为空',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='两步验证恢复码表';
+
+-- 旧版登录页提交密码的 SHA256 摘要，库中保存的是摘要的 bcrypt 哈希。
+-- 现已改为提交原始密码，旧哈希在用户下次登录成功时自动重新哈希为原始密码的 bcrypt 哈希。
+-- 仍在使用初始化默认密码的 admin 账号须在下次登录时修改密码
+UPDATE `sys_user` SET `password_change_required` = 1
+WHERE `username` = 'admin' AND `password` = '$2a$10$RBVyo4ojLZEG.j.uJnpcEuhY4DRC0TChUAdo1XBnlKB/mKnZDEbUS';
+
+-- 接口权限映射：重置两步验证、解除登录锁定
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('DELETE', '/api/v1/users/:id/totp', 'system:user:update', '重置用户两步验证'),
+('POST', '/api/v1/users/:id/unlock', 'system:user:update', '解除用户登录锁定');
@@ -0,0 +1,41 @@
+-- 用户API令牌，仅保存 SHA-256 摘要
+CREATE TABLE IF NOT EXISTS `sys_api_token` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `user_id` bigint NOT NULL COMMENT '所属用户ID',
+  `name` varchar(64) NOT NULL COMMENT '令牌名称',
+  `prefix` varchar(16) NOT NULL COMMENT '令牌开头字符，用于识别令牌',
+  `token_hash` varchar(64) NOT NULL COMMENT '令牌摘要',
+  `scopes` varchar(1024) DEFAULT NULL COMMENT '令牌权限标识，逗号分隔',
+  `expires_at` datetime DEFAULT NULL COMMENT '过期时间',
+  `last_used_at` datetime DEFAULT NULL COMMENT '最近使用时间',
+  `last_used_ip` varchar(64) DEFAULT NULL COMMENT '最近使用IP',
+  `revoked_at` datetime DEFAULT NULL COMMENT '吊销时间，未吊销为空',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_token_hash` (`token_hash`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户API令牌表';
+
+SET @admin_role_id = NULL;
+SELECT @admin_role_id := id FROM `sys_role` WHERE `code` = 'admin';
+
+SET @user_menu_id = NULL;
+SELECT @user_menu_id := id FROM `sys_menu` WHERE `perms` = 'system:user:list' AND `type` = 1 AND deleted_at IS NULL;
+
+-- 服务账号及用户API令牌管理按钮权限
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @user_menu_id, 'API令牌管理', 'system:user:api-token', 2, NULL, 5, 1
+WHERE @user_menu_id IS NOT NULL;
+
+INSERT IGNORE INTO `sys_role_menu` (`role_id`, `menu_id`)
+SELECT @admin_role_id, id FROM `sys_menu`
+WHERE @admin_role_id IS NOT NULL AND deleted_at IS NULL AND `perms` = 'system:user:api-token';
+
+-- 接口权限映射：个人令牌接口仅要求登录
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('POST', '/api/v1/service-accounts', 'system:user:create', '创建服务账号'),
+('GET', '/api/v1/users/:id/api-tokens', 'system:user:api-token', '用户API令牌列表'),
+('POST', '/api/v1/users/:id/api-tokens', 'system:user:api-token', '为服务账号创建API令牌'),
+('DELETE', '/api/v1/users/:id/api-tokens/:tokenId', 'system:user:api-token', '吊销用户API令牌');
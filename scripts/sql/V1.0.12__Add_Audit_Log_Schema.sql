@@ -0,0 +1,68 @@
+-- 操作审计日志，只允许追加
+CREATE TABLE IF NOT EXISTS `sys_audit_log` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT '操作时间',
+  `user_id` bigint NOT NULL DEFAULT 0 COMMENT '操作用户ID',
+  `username` varchar(32) DEFAULT NULL COMMENT '操作用户名',
+  `client_ip` varchar(64) DEFAULT NULL COMMENT '来源IP',
+  `user_agent` varchar(255) DEFAULT NULL COMMENT '客户端标识',
+  `api_token_id` bigint NOT NULL DEFAULT 0 COMMENT '使用API令牌访问时的令牌ID',
+  `method` varchar(10) DEFAULT NULL COMMENT '请求方法',
+  `route` varchar(255) DEFAULT NULL COMMENT '路由模板',
+  `path` varchar(512) DEFAULT NULL COMMENT '请求路径',
+  `action` varchar(64) DEFAULT NULL COMMENT '操作',
+  `resource_type` varchar(64) DEFAULT NULL COMMENT '资源类型',
+  `resource_id` varchar(64) DEFAULT NULL COMMENT '资源ID',
+  `resource_name` varchar(255) DEFAULT NULL COMMENT '资源名称',
+  `changes` mediumtext COMMENT '字段变更，敏感字段已脱敏',
+  `request` text COMMENT '脱敏后的请求参数',
+  `result` varchar(16) DEFAULT NULL COMMENT '结果：success、failed',
+  `status_code` int NOT NULL DEFAULT 0 COMMENT 'HTTP状态码',
+  `code` int NOT NULL DEFAULT 0 COMMENT '业务响应码',
+  `message` varchar(512) DEFAULT NULL COMMENT '失败信息',
+  `duration` bigint NOT NULL DEFAULT 0 COMMENT '耗时，毫秒',
+  PRIMARY KEY (`id`),
+  KEY `idx_sys_audit_log_created_at` (`created_at`),
+  KEY `idx_sys_audit_log_user_id` (`user_id`),
+  KEY `idx_sys_audit_log_username` (`username`),
+  KEY `idx_sys_audit_log_action` (`action`),
+  KEY `idx_sys_audit_log_result` (`result`),
+  KEY `idx_audit_resource` (`resource_type`, `resource_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='操作审计日志表';
+
+-- 禁止修改和删除审计日志
+DROP TRIGGER IF EXISTS `trg_sys_audit_log_no_update`;
+CREATE TRIGGER `trg_sys_audit_log_no_update` BEFORE UPDATE ON `sys_audit_log`
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = '审计日志不允许修改';
+
+DROP TRIGGER IF EXISTS `trg_sys_audit_log_no_delete`;
+CREATE TRIGGER `trg_sys_audit_log_no_delete` BEFORE DELETE ON `sys_audit_log`
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = '审计日志不允许删除';
+
+SET @admin_role_id = NULL;
+SELECT @admin_role_id := id FROM `sys_role` WHERE `code` = 'admin';
+
+SET @system_id = NULL;
+SELECT @system_id := id FROM `sys_menu` WHERE `path` = '/system' AND `type` = 0 AND deleted_at IS NULL;
+
+-- 审计日志菜单
+INSERT INTO `sys_menu` (`parent_id`, `name`, `path`, `component`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @system_id, '审计日志', 'audit', 'system/audit/index', 'system:audit:list', 1, 'documentation', 4, 1
+WHERE @system_id IS NOT NULL;
+
+SET @audit_menu_id = NULL;
+SELECT @audit_menu_id := id FROM `sys_menu` WHERE `perms` = 'system:audit:list' AND `type` = 1 AND deleted_at IS NULL;
+
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @audit_menu_id, '导出审计日志', 'system:audit:export', 2, NULL, 1, 1
+WHERE @audit_menu_id IS NOT NULL;
+
+INSERT IGNORE INTO `sys_role_menu` (`role_id`, `menu_id`)
+SELECT @admin_role_id, id FROM `sys_menu`
+WHERE @admin_role_id IS NOT NULL AND deleted_at IS NULL AND `perms` IN ('system:audit:list', 'system:audit:export');
+
+-- 接口权限映射
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/audit-logs', 'system:audit:list', '审计日志列表'),
+('GET', '/api/v1/audit-logs/:id', 'system:audit:list', '审计日志详情'),
+('GET', '/api/v1/audit-logs/export', 'system:audit:export', '导出审计日志');
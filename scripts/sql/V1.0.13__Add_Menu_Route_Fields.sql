@@ -0,0 +1,5 @@
+-- 菜单路由属性：隐藏菜单仍可通过路由访问，缓存表示前端保留页面状态
+ALTER TABLE `sys_menu`
+  ADD COLUMN `hidden` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否在侧边栏隐藏' AFTER `status`,
+  ADD COLUMN `cache` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否缓存页面' AFTER `hidden`;
+
@@ -0,0 +1,47 @@
+SET @admin_role_id = NULL;
+SELECT @admin_role_id := id FROM `sys_role` WHERE `code` = 'admin';
+
+SET @user_menu_id = NULL;
+SELECT @user_menu_id := id FROM `sys_menu` WHERE `perms` = 'system:user:list' AND `type` = 1 AND deleted_at IS NULL;
+
+SET @role_menu_id = NULL;
+SELECT @role_menu_id := id FROM `sys_menu` WHERE `perms` = 'system:role:list' AND `type` = 1 AND deleted_at IS NULL;
+
+SET @menu_menu_id = NULL;
+SELECT @menu_menu_id := id FROM `sys_menu` WHERE `perms` = 'system:menu:list' AND `type` = 1 AND deleted_at IS NULL;
+
+-- 用户导入导出、角色及菜单导出按钮权限
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @user_menu_id, t.name, t.perms, 2, NULL, t.sort_order, 1
+FROM (
+  SELECT '用户导入' AS name, 'system:user:import' AS perms, 6 AS sort_order
+  UNION ALL SELECT '用户导出', 'system:user:export', 7
+) t
+WHERE @user_menu_id IS NOT NULL;
+
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @role_menu_id, '角色导出', 'system:role:export', 2, NULL, 5, 1
+WHERE @role_menu_id IS NOT NULL;
+
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @menu_menu_id, '菜单导出', 'system:menu:export', 2, NULL, 4, 1
+WHERE @menu_menu_id IS NOT NULL;
+
+INSERT IGNORE INTO `sys_role_menu` (`role_id`, `menu_id`)
+SELECT @admin_role_id, id FROM `sys_menu`
+WHERE @admin_role_id IS NOT NULL AND deleted_at IS NULL AND `perms` IN (
+  'system:user:import', 'system:user:export', 'system:role:export', 'system:menu:export'
+);
+
+-- 接口权限映射：批量启用禁用、批量分配角色、复制和比较角色沿用已有权限
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/users/export', 'system:user:export', '导出用户'),
+('GET', '/api/v1/users/import/template', 'system:user:import', '下载用户导入模板'),
+('POST', '/api/v1/users/import', 'system:user:import', '导入用户'),
+('POST', '/api/v1/users/batch/status', 'system:user:update', '批量启用禁用用户'),
+('POST', '/api/v1/users/batch/roles', 'system:user:assign-role', '批量分配用户角色'),
+('GET', '/api/v1/roles/export', 'system:role:export', '导出角色权限'),
+('POST', '/api/v1/roles/:id/clone', 'system:role:create', '复制角色'),
+('GET', '/api/v1/roles/:id/diff', 'system:role:list', '比较角色权限'),
+('POST', '/api/v1/roles/:id/diff', 'system:role:list', '与导入的角色权限比较'),
+('GET', '/api/v1/menus/export', 'system:menu:export', '导出菜单');
@@ -0,0 +1,23 @@
+-- K8s历史数据清理记录，每次定时或手动清理一条
+CREATE TABLE IF NOT EXISTS `infra_k8s_history_cleanup_run` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `trigger` varchar(20) NOT NULL COMMENT '触发方式：scheduled、manual',
+  `operator` varchar(64) DEFAULT NULL COMMENT '手动清理的操作人',
+  `before_date` datetime NOT NULL COMMENT '清理此时间之前归档的数据',
+  `status` varchar(20) NOT NULL COMMENT '状态：running、success、failed',
+  `pod_deleted` bigint NOT NULL DEFAULT 0 COMMENT '删除的Pod历史行数',
+  `node_deleted` bigint NOT NULL DEFAULT 0 COMMENT '删除的Node历史行数',
+  `workload_deleted` bigint NOT NULL DEFAULT 0 COMMENT '删除的Workload历史行数',
+  `total_deleted` bigint NOT NULL DEFAULT 0 COMMENT '删除的总行数',
+  `error` text COMMENT '失败原因',
+  `started_at` datetime NOT NULL COMMENT '开始时间',
+  `finished_at` datetime DEFAULT NULL COMMENT '完成时间',
+  `duration` bigint NOT NULL DEFAULT 0 COMMENT '耗时，毫秒',
+  PRIMARY KEY (`id`),
+  KEY `idx_infra_k8s_history_cleanup_run_status` (`status`),
+  KEY `idx_infra_k8s_history_cleanup_run_started_at` (`started_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='K8s历史数据清理记录';
+
+-- 全部集群的历史统计
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/k8s-history/statistics', 'infrastructure:kubernetes:history-cleanup', '全部集群历史统计');
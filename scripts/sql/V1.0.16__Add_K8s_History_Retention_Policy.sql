@@ -0,0 +1,29 @@
+-- K8s历史数据保留策略，config_id 为 0 表示所有集群的默认策略，kind 为 all 表示全部类型
+CREATE TABLE IF NOT EXISTS `infra_k8s_history_retention_policy` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `config_id` bigint NOT NULL DEFAULT 0 COMMENT 'K8s配置ID，0 表示默认策略',
+  `kind` varchar(20) NOT NULL COMMENT '历史数据类型：pod、node、workload、all',
+  `retention_days` int NOT NULL COMMENT '保留天数，0 表示永久保留',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_config_kind` (`config_id`, `kind`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='K8s历史数据保留策略';
+
+-- 清理明细
+ALTER TABLE `infra_k8s_history_cleanup_run`
+  ADD COLUMN `details` mediumtext COMMENT '按集群和类型的清理明细' AFTER `total_deleted`;
+
+-- 按集群分批清理时按 config_id、archived_at 顺序删除
+ALTER TABLE `infra_k8s_pod_history` ADD KEY `idx_config_archived_at` (`config_id`, `archived_at`);
+ALTER TABLE `infra_k8s_node_history` ADD KEY `idx_config_archived_at` (`config_id`, `archived_at`);
+ALTER TABLE `infra_k8s_workload_history` ADD KEY `idx_config_archived_at` (`config_id`, `archived_at`);
+
+-- 接口权限映射
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/k8s-history/:configId/retention', 'infrastructure:kubernetes:list', '历史数据保留天数'),
+('GET', '/api/v1/k8s-history/retention-policies', 'infrastructure:kubernetes:history-cleanup', '保留策略列表'),
+('POST', '/api/v1/k8s-history/retention-policies', 'infrastructure:kubernetes:history-cleanup', '创建保留策略'),
+('PUT', '/api/v1/k8s-history/retention-policies/:id', 'infrastructure:kubernetes:history-cleanup', '更新保留策略'),
+('DELETE', '/api/v1/k8s-history/retention-policies/:id', 'infrastructure:kubernetes:history-cleanup', '删除保留策略');
@@ -0,0 +1,20 @@
+-- 从归档文件恢复的历史数据记录恢复时间，保留期从恢复时间起计算，且不再重复归档
+ALTER TABLE `infra_k8s_pod_history`
+  ADD COLUMN `restored_at` datetime DEFAULT NULL COMMENT '从归档文件恢复的时间' AFTER `archive_reason`,
+  ADD KEY `idx_restored_at` (`restored_at`);
+ALTER TABLE `infra_k8s_node_history`
+  ADD COLUMN `restored_at` datetime DEFAULT NULL COMMENT '从归档文件恢复的时间' AFTER `archive_reason`,
+  ADD KEY `idx_restored_at` (`restored_at`);
+ALTER TABLE `infra_k8s_workload_history`
+  ADD COLUMN `restored_at` datetime DEFAULT NULL COMMENT '从归档文件恢复的时间' AFTER `archive_reason`,
+  ADD KEY `idx_restored_at` (`restored_at`);
+
+-- 清理前归档的行数和文件数
+ALTER TABLE `infra_k8s_history_cleanup_run`
+  ADD COLUMN `archived_rows` bigint NOT NULL DEFAULT 0 COMMENT '删除前归档的行数' AFTER `total_deleted`,
+  ADD COLUMN `archived_files` int NOT NULL DEFAULT 0 COMMENT '写入的归档文件数' AFTER `archived_rows`;
+
+-- 接口权限映射
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/k8s-history/archives', 'infrastructure:kubernetes:history-cleanup', '历史数据归档文件列表'),
+('POST', '/api/v1/k8s-history/restore', 'infrastructure:kubernetes:history-cleanup', '从归档恢复历史数据');
@@ -0,0 +1,23 @@
+-- K8s资源变更事件，集群同步时与上次同步的状态比较得出
+CREATE TABLE IF NOT EXISTS `infra_k8s_change_event` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `config_id` bigint NOT NULL COMMENT 'K8s配置ID',
+  `kind` varchar(20) NOT NULL COMMENT '资源类型：pod、node、workload',
+  `workload_kind` varchar(20) DEFAULT NULL COMMENT '工作负载类型，如 Deployment',
+  `namespace` varchar(100) DEFAULT NULL COMMENT '命名空间，节点为空',
+  `name` varchar(255) NOT NULL COMMENT '资源名称',
+  `action` varchar(20) NOT NULL COMMENT '变更动作：created、updated、deleted',
+  `changes` mediumtext COMMENT '字段变更，JSON格式',
+  `fields` varchar(1024) DEFAULT NULL COMMENT '变更的字段名，逗号分隔',
+  `source` varchar(20) NOT NULL COMMENT '变更来源',
+  `observed_at` datetime NOT NULL COMMENT '发现变更的同步时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_config_observed` (`config_id`, `observed_at`),
+  KEY `idx_object` (`config_id`, `kind`, `namespace`, `name`),
+  KEY `idx_observed_at` (`observed_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='K8s资源变更事件';
+
+-- 接口权限映射
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/k8s-changes', 'infrastructure:kubernetes:list', '最近的K8s资源变更'),
+('GET', '/api/v1/k8s-changes/timeline', 'infrastructure:kubernetes:list', 'K8s资源变更时间线');
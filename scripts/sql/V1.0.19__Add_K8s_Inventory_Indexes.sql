@@ -0,0 +1,15 @@
+-- 时间点清单：实时表按集群和创建时间查询
+ALTER TABLE `infra_k8s_pod` ADD KEY `idx_config_created_at` (`config_id`, `created_at`);
+ALTER TABLE `infra_k8s_node` ADD KEY `idx_config_created_at` (`config_id`, `created_at`);
+ALTER TABLE `infra_k8s_workload` ADD KEY `idx_config_created_at` (`config_id`, `created_at`);
+
+-- 时间点清单：历史表按集群和归档时间查询已有 idx_config_archived_at，复盘时常按节点或工作负载过滤Pod
+ALTER TABLE `infra_k8s_pod` ADD KEY `idx_config_node_name` (`config_id`, `node_name`);
+ALTER TABLE `infra_k8s_pod_history` ADD KEY `idx_config_node_archived_at` (`config_id`, `node_name`(100), `archived_at`);
+ALTER TABLE `infra_k8s_pod_history` ADD KEY `idx_config_workload_archived_at` (`config_id`, `workload_name`(100), `archived_at`);
+
+-- 接口权限映射
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/k8s-inventory/:configId/pods', 'infrastructure:kubernetes:list', '某一时间点的Pod清单'),
+('GET', '/api/v1/k8s-inventory/:configId/nodes', 'infrastructure:kubernetes:list', '某一时间点的节点清单'),
+('GET', '/api/v1/k8s-inventory/:configId/workloads', 'infrastructure:kubernetes:list', '某一时间点的工作负载清单');
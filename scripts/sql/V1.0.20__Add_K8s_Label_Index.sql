@@ -0,0 +1,20 @@
+-- Pod标签
+ALTER TABLE `infra_k8s_pod`
+  ADD COLUMN `labels` text COMMENT '标签(JSON格式)' AFTER `memory_limit`;
+
+-- K8s资源标签索引，同步时由各资源的标签 JSON 展开，用于标签选择器过滤和全局搜索
+CREATE TABLE IF NOT EXISTS `infra_k8s_label` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `config_id` bigint NOT NULL COMMENT 'K8s配置ID',
+  `kind` varchar(20) NOT NULL COMMENT '资源类型：pod、node、workload',
+  `object_id` bigint NOT NULL COMMENT '实时表中的资源ID',
+  `label_key` varchar(317) NOT NULL COMMENT '标签名',
+  `label_value` varchar(63) NOT NULL COMMENT '标签值',
+  PRIMARY KEY (`id`),
+  KEY `idx_config_kind_key_value` (`config_id`, `kind`, `label_key`, `label_value`),
+  KEY `idx_kind_object` (`kind`, `object_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='K8s资源标签索引';
+
+-- 接口权限映射
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/k8s-search', 'infrastructure:kubernetes:list', 'K8s资源全局搜索');
@@ -0,0 +1,14 @@
+-- 列表导出接口权限映射，与对应列表接口的权限相同
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/database-configs/export', 'infrastructure:database:list', '导出数据库'),
+('GET', '/api/v1/infrastructure/database/export', 'infrastructure:database:list', '导出数据库'),
+('GET', '/api/v1/server-configs/export', 'infrastructure:server:list', '导出服务器'),
+('GET', '/api/v1/infrastructure/server/export', 'infrastructure:server:list', '导出服务器'),
+('GET', '/api/v1/k8s-configs/export', 'infrastructure:kubernetes:list', '导出Kubernetes集群'),
+('GET', '/api/v1/infrastructure/kubernetes/export', 'infrastructure:kubernetes:list', '导出Kubernetes集群'),
+('GET', '/api/v1/k8s-workloads/export', 'infrastructure:kubernetes:list', '导出工作负载'),
+('GET', '/api/v1/k8s-pods/export', 'infrastructure:kubernetes:list', '导出Pod'),
+('GET', '/api/v1/k8s-nodes/export', 'infrastructure:kubernetes:list', '导出节点'),
+('GET', '/api/v1/k8s-history/:configId/pods/export', 'infrastructure:kubernetes:list', '导出Pod历史'),
+('GET', '/api/v1/k8s-history/:configId/nodes/export', 'infrastructure:kubernetes:list', '导出节点历史'),
+('GET', '/api/v1/k8s-history/:configId/workloads/export', 'infrastructure:kubernetes:list', '导出工作负载历史');
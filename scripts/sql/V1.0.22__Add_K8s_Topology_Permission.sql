@@ -0,0 +1,3 @@
+-- Kubernetes拓扑接口权限映射
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/k8s-topology/:configId', 'infrastructure:kubernetes:list', 'K8s工作负载拓扑');
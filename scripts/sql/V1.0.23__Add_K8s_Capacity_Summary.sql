@@ -0,0 +1,33 @@
+-- K8s每周容量汇总，每个集群每周一条，重复生成时覆盖
+CREATE TABLE IF NOT EXISTS `infra_k8s_capacity_summary` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `config_id` bigint NOT NULL COMMENT 'K8s配置ID',
+  `config_name` varchar(100) DEFAULT NULL COMMENT '集群名称',
+  `week_start` date NOT NULL COMMENT '所在周的周一',
+  `node_count` bigint NOT NULL DEFAULT 0 COMMENT '节点数',
+  `pod_count` bigint NOT NULL DEFAULT 0 COMMENT 'Pod数',
+  `workload_count` bigint NOT NULL DEFAULT 0 COMMENT '工作负载数',
+  `cpu_allocatable` double NOT NULL DEFAULT 0 COMMENT '可分配CPU，核',
+  `cpu_requested` double NOT NULL DEFAULT 0 COMMENT 'CPU请求，核',
+  `cpu_request_share` double NOT NULL DEFAULT 0 COMMENT 'CPU请求占可分配的百分比',
+  `memory_allocatable` bigint NOT NULL DEFAULT 0 COMMENT '可分配内存，字节',
+  `memory_requested` bigint NOT NULL DEFAULT 0 COMMENT '内存请求，字节',
+  `memory_request_share` double NOT NULL DEFAULT 0 COMMENT '内存请求占可分配的百分比',
+  `missing_request_workloads` bigint NOT NULL DEFAULT 0 COMMENT '缺少请求的工作负载数',
+  `missing_limit_workloads` bigint NOT NULL DEFAULT 0 COMMENT '缺少限制的工作负载数',
+  `best_effort_pods` bigint NOT NULL DEFAULT 0 COMMENT 'BestEffort Pod数',
+  `recommendation_count` bigint NOT NULL DEFAULT 0 COMMENT '调整建议数',
+  `usage_available` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否有用量数据',
+  `namespaces` mediumtext COMMENT '各命名空间的容量汇总，JSON格式',
+  `workloads` mediumtext COMMENT '需要关注的工作负载，JSON格式',
+  `warnings` text COMMENT '生成时的警告，JSON格式',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '生成时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_config_week` (`config_id`, `week_start`),
+  KEY `idx_infra_k8s_capacity_summary_week_start` (`week_start`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='K8s每周容量汇总';
+
+-- 接口权限映射
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/k8s-capacity/:configId', 'infrastructure:kubernetes:list', 'K8s容量报告'),
+('GET', '/api/v1/k8s-capacity/:configId/summaries', 'infrastructure:kubernetes:list', 'K8s每周容量汇总');
@@ -0,0 +1,2 @@
+-- Pod的QoS等级，取自Pod状态，由 Kubernetes 按全部容器的请求和限制计算
+ALTER TABLE `infra_k8s_pod` ADD COLUMN `qos_class` varchar(20) DEFAULT NULL COMMENT 'QoS等级' AFTER `memory_limit`;
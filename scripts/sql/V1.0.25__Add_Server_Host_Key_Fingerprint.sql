@@ -0,0 +1,2 @@
+-- 服务器主机公钥指纹，终端和文件传输连接时校验，为空时首次连接登记
+ALTER TABLE `infra_server_config` ADD COLUMN `host_key_fingerprint` varchar(100) DEFAULT NULL COMMENT '主机公钥SHA256指纹，为空时首次连接登记' AFTER `sftp_allowed_paths`;
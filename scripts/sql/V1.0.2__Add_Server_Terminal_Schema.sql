@@ -0,0 +1,61 @@
+-- 创建服务器终端会话表
+CREATE TABLE IF NOT EXISTS `infra_server_session` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '会话ID',
+  `server_id` bigint NOT NULL COMMENT '服务器ID',
+  `server_name` varchar(100) DEFAULT NULL COMMENT '服务器名称',
+  `host` varchar(100) DEFAULT NULL COMMENT '主机地址',
+  `user_id` bigint NOT NULL COMMENT '用户ID',
+  `username` varchar(50) DEFAULT NULL COMMENT '用户名',
+  `client_ip` varchar(64) DEFAULT NULL COMMENT '客户端IP',
+  `cols` int DEFAULT NULL COMMENT '终端列数',
+  `rows` int DEFAULT NULL COMMENT '终端行数',
+  `record_file` varchar(255) DEFAULT NULL COMMENT '录像文件路径',
+  `record_size` bigint NOT NULL DEFAULT '0' COMMENT '录像文件大小(字节)',
+  `status` varchar(20) NOT NULL DEFAULT 'active' COMMENT '状态：active=进行中，closed=已关闭，failed=连接失败',
+  `close_reason` varchar(50) DEFAULT NULL COMMENT '关闭原因',
+  `error_msg` varchar(500) DEFAULT NULL COMMENT '错误信息',
+  `started_at` datetime NOT NULL COMMENT '开始时间',
+  `ended_at` datetime DEFAULT NULL COMMENT '结束时间',
+  `duration` int NOT NULL DEFAULT '0' COMMENT '持续时长(秒)',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_server_id` (`server_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_status` (`status`),
+  KEY `idx_started_at` (`started_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='服务器终端会话表';
+
+-- 创建服务器终端授权表
+CREATE TABLE IF NOT EXISTS `infra_server_permission` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `server_id` bigint NOT NULL COMMENT '服务器ID',
+  `user_id` bigint NOT NULL DEFAULT '0' COMMENT '用户ID',
+  `role_id` bigint NOT NULL DEFAULT '0' COMMENT '角色ID',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_server_user_role` (`server_id`,`user_id`,`role_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_role_id` (`role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='服务器终端授权表';
+
+-- 获取管理员角色ID和服务器菜单ID
+SET @admin_role_id = NULL;
+SELECT @admin_role_id := id FROM `sys_role` WHERE `code` = 'admin';
+
+SET @server_menu_id = NULL;
+SELECT @server_menu_id := id FROM `sys_menu` WHERE `perms` = 'infrastructure:server:list' AND `type` = 1 AND deleted_at IS NULL;
+
+-- 服务器终端按钮权限
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @server_menu_id, '服务器终端', 'infrastructure:server:terminal', 2, NULL, 5, 1
+WHERE @server_menu_id IS NOT NULL;
+
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @server_menu_id, '终端会话回放', 'infrastructure:server:session', 2, NULL, 6, 1
+WHERE @server_menu_id IS NOT NULL;
+
+-- 授权给管理员角色
+INSERT IGNORE INTO `sys_role_menu` (`role_id`, `menu_id`)
+SELECT @admin_role_id, id FROM `sys_menu`
+WHERE @admin_role_id IS NOT NULL AND `perms` IN ('infrastructure:server:terminal', 'infrastructure:server:session');
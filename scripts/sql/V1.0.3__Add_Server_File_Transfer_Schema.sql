@@ -0,0 +1,40 @@
+-- 服务器配置增加SFTP允许路径
+ALTER TABLE `infra_server_config`
+  ADD COLUMN `sftp_allowed_paths` text DEFAULT NULL COMMENT 'SFTP允许访问的路径(每行一个)' AFTER `description`;
+
+-- 创建服务器文件操作审计表
+CREATE TABLE IF NOT EXISTS `infra_server_file_audit` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `server_id` bigint NOT NULL COMMENT '服务器ID',
+  `server_name` varchar(100) DEFAULT NULL COMMENT '服务器名称',
+  `user_id` bigint NOT NULL COMMENT '用户ID',
+  `username` varchar(50) DEFAULT NULL COMMENT '用户名',
+  `client_ip` varchar(64) DEFAULT NULL COMMENT '客户端IP',
+  `action` varchar(20) NOT NULL COMMENT '操作类型：list/stat/download/upload/mkdir/delete',
+  `path` varchar(1024) NOT NULL COMMENT '操作路径',
+  `size` bigint NOT NULL DEFAULT '0' COMMENT '传输字节数',
+  `success` tinyint(1) NOT NULL COMMENT '是否成功',
+  `error_msg` varchar(500) DEFAULT NULL COMMENT '错误信息',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_server_id` (`server_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_action` (`action`),
+  KEY `idx_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='服务器文件操作审计表';
+
+-- 获取管理员角色ID和服务器菜单ID
+SET @admin_role_id = NULL;
+SELECT @admin_role_id := id FROM `sys_role` WHERE `code` = 'admin';
+
+SET @server_menu_id = NULL;
+SELECT @server_menu_id := id FROM `sys_menu` WHERE `perms` = 'infrastructure:server:list' AND `type` = 1 AND deleted_at IS NULL;
+
+-- 服务器文件管理按钮权限
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @server_menu_id, '服务器文件管理', 'infrastructure:server:file', 2, NULL, 7, 1
+WHERE @server_menu_id IS NOT NULL;
+
+INSERT IGNORE INTO `sys_role_menu` (`role_id`, `menu_id`)
+SELECT @admin_role_id, id FROM `sys_menu`
+WHERE @admin_role_id IS NOT NULL AND `perms` = 'infrastructure:server:file';
@@ -0,0 +1,88 @@
+-- 创建云资源清单表
+CREATE TABLE IF NOT EXISTS `infra_cloud_resource` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `account_id` bigint NOT NULL COMMENT '云账号ID',
+  `provider` varchar(50) NOT NULL COMMENT '云厂商代码',
+  `region` varchar(50) NOT NULL COMMENT '地域',
+  `zone` varchar(50) DEFAULT NULL COMMENT '可用区',
+  `resource_type` varchar(20) NOT NULL COMMENT '资源类型：CLB/CVM/CFS/MariaDB/Redis/ES/CKafka',
+  `instance_id` varchar(100) NOT NULL COMMENT '实例ID',
+  `name` varchar(255) DEFAULT NULL COMMENT '实例名称',
+  `status` varchar(50) DEFAULT NULL COMMENT '状态',
+  `private_ips` varchar(1024) DEFAULT NULL COMMENT '内网IP(逗号分隔)',
+  `public_ips` varchar(1024) DEFAULT NULL COMMENT '公网IP(逗号分隔)',
+  `tags` text DEFAULT NULL COMMENT '标签(JSON格式)',
+  `spec` text DEFAULT NULL COMMENT '规格(JSON格式)',
+  `create_time` datetime DEFAULT NULL COMMENT '云上创建时间',
+  `synced_at` datetime NOT NULL COMMENT '最近同步时间',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_cloud_resource` (`account_id`, `region`, `resource_type`, `instance_id`),
+  KEY `idx_provider` (`provider`),
+  KEY `idx_resource_type` (`resource_type`),
+  KEY `idx_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='云资源清单表';
+
+-- 创建云资源历史表
+CREATE TABLE IF NOT EXISTS `infra_cloud_resource_history` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `original_id` bigint NOT NULL COMMENT '原始资源ID',
+  `account_id` bigint NOT NULL COMMENT '云账号ID',
+  `provider` varchar(50) NOT NULL COMMENT '云厂商代码',
+  `region` varchar(50) NOT NULL COMMENT '地域',
+  `zone` varchar(50) DEFAULT NULL COMMENT '可用区',
+  `resource_type` varchar(20) NOT NULL COMMENT '资源类型',
+  `instance_id` varchar(100) NOT NULL COMMENT '实例ID',
+  `name` varchar(255) DEFAULT NULL COMMENT '实例名称',
+  `status` varchar(50) DEFAULT NULL COMMENT '最后状态',
+  `private_ips` varchar(1024) DEFAULT NULL COMMENT '内网IP(逗号分隔)',
+  `public_ips` varchar(1024) DEFAULT NULL COMMENT '公网IP(逗号分隔)',
+  `tags` text DEFAULT NULL COMMENT '标签(JSON格式)',
+  `spec` text DEFAULT NULL COMMENT '规格(JSON格式)',
+  `create_time` datetime DEFAULT NULL COMMENT '云上创建时间',
+  `synced_at` datetime NOT NULL COMMENT '最后同步时间',
+  `created_at` datetime NOT NULL COMMENT '原始创建时间',
+  `updated_at` datetime NOT NULL COMMENT '原始更新时间',
+  `archived_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '归档时间',
+  `archive_reason` varchar(100) DEFAULT 'sync_cleanup' COMMENT '归档原因',
+  PRIMARY KEY (`id`),
+  KEY `idx_original_id` (`original_id`),
+  KEY `idx_account_id` (`account_id`),
+  KEY `idx_resource_type` (`resource_type`),
+  KEY `idx_instance_id` (`instance_id`),
+  KEY `idx_archived_at` (`archived_at`),
+  KEY `idx_archive_reason` (`archive_reason`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='云资源历史表';
+
+-- 获取管理员角色ID和基础设施菜单ID
+SET @admin_role_id = NULL;
+SELECT @admin_role_id := id FROM `sys_role` WHERE `code` = 'admin';
+
+SET @infra_id = NULL;
+SELECT @infra_id := id FROM `sys_menu` WHERE `path` = '/infrastructure' AND `type` = 0 AND deleted_at IS NULL;
+
+-- 云资源菜单
+INSERT INTO `sys_menu` (`parent_id`, `name`, `path`, `component`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @infra_id, '云资源', 'cloud-resource', 'infrastructure/cloud-resource/index', 'infrastructure:cloud-resource:list', 1, 'cloud', 6, 1
+WHERE @infra_id IS NOT NULL;
+
+SET @cloud_resource_menu_id = NULL;
+SELECT @cloud_resource_menu_id := id FROM `sys_menu` WHERE `perms` = 'infrastructure:cloud-resource:list' AND `type` = 1 AND deleted_at IS NULL;
+
+SET @cloud_account_menu_id = NULL;
+SELECT @cloud_account_menu_id := id FROM `sys_menu` WHERE `perms` = 'infrastructure:cloud-account:list' AND `type` = 1 AND deleted_at IS NULL;
+
+-- 按钮权限
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @cloud_resource_menu_id, '云资源历史', 'infrastructure:cloud-resource:history', 2, NULL, 1, 1
+WHERE @cloud_resource_menu_id IS NOT NULL;
+
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @cloud_account_menu_id, '云资源同步', 'infrastructure:cloud-account:sync', 2, NULL, 5, 1
+WHERE @cloud_account_menu_id IS NOT NULL;
+
+-- 授权给管理员角色
+INSERT IGNORE INTO `sys_role_menu` (`role_id`, `menu_id`)
+SELECT @admin_role_id, id FROM `sys_menu`
+WHERE @admin_role_id IS NOT NULL AND `perms` IN ('infrastructure:cloud-resource:list', 'infrastructure:cloud-resource:history', 'infrastructure:cloud-account:sync');
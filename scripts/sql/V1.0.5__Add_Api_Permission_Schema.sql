@@ -0,0 +1,201 @@
+-- 创建接口权限映射表
+CREATE TABLE IF NOT EXISTS `sys_api_permission` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `method` varchar(10) NOT NULL COMMENT 'HTTP方法',
+  `path` varchar(255) NOT NULL COMMENT '路由模式，如 /api/v1/users/:id',
+  `perms` varchar(100) NOT NULL COMMENT '权限标识，同一路由配置多个时满足任意一个即可',
+  `description` varchar(255) DEFAULT NULL COMMENT '描述',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_api_permission` (`method`, `path`, `perms`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='接口权限映射表';
+
+-- 获取管理员角色ID
+SET @admin_role_id = NULL;
+SELECT @admin_role_id := id FROM `sys_role` WHERE `code` = 'admin';
+
+SET @user_menu_id = NULL;
+SELECT @user_menu_id := id FROM `sys_menu` WHERE `perms` = 'system:user:list' AND `type` = 1 AND deleted_at IS NULL;
+
+SET @role_menu_id = NULL;
+SELECT @role_menu_id := id FROM `sys_menu` WHERE `perms` = 'system:role:list' AND `type` = 1 AND deleted_at IS NULL;
+
+SET @menu_menu_id = NULL;
+SELECT @menu_menu_id := id FROM `sys_menu` WHERE `perms` = 'system:menu:list' AND `type` = 1 AND deleted_at IS NULL;
+
+SET @k8s_menu_id = NULL;
+SELECT @k8s_menu_id := id FROM `sys_menu` WHERE `perms` = 'infrastructure:kubernetes:list' AND `type` = 1 AND deleted_at IS NULL;
+
+SET @cloud_resource_menu_id = NULL;
+SELECT @cloud_resource_menu_id := id FROM `sys_menu` WHERE `perms` = 'infrastructure:cloud-resource:list' AND `type` = 1 AND deleted_at IS NULL;
+
+-- 系统管理按钮权限
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @user_menu_id, t.name, t.perms, 2, NULL, t.sort_order, 1
+FROM (
+  SELECT '用户创建' AS name, 'system:user:create' AS perms, 1 AS sort_order
+  UNION ALL SELECT '用户更新', 'system:user:update', 2
+  UNION ALL SELECT '用户删除', 'system:user:delete', 3
+  UNION ALL SELECT '分配角色', 'system:user:assign-role', 4
+) t
+WHERE @user_menu_id IS NOT NULL;
+
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @role_menu_id, t.name, t.perms, 2, NULL, t.sort_order, 1
+FROM (
+  SELECT '角色创建' AS name, 'system:role:create' AS perms, 1 AS sort_order
+  UNION ALL SELECT '角色更新', 'system:role:update', 2
+  UNION ALL SELECT '角色删除', 'system:role:delete', 3
+  UNION ALL SELECT '分配菜单', 'system:role:assign-menu', 4
+) t
+WHERE @role_menu_id IS NOT NULL;
+
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @menu_menu_id, t.name, t.perms, 2, NULL, t.sort_order, 1
+FROM (
+  SELECT '菜单创建' AS name, 'system:menu:create' AS perms, 1 AS sort_order
+  UNION ALL SELECT '菜单更新', 'system:menu:update', 2
+  UNION ALL SELECT '菜单删除', 'system:menu:delete', 3
+) t
+WHERE @menu_menu_id IS NOT NULL;
+
+-- 基础设施按钮权限
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @k8s_menu_id, 'K8s历史清理', 'infrastructure:kubernetes:history-cleanup', 2, NULL, 5, 1
+WHERE @k8s_menu_id IS NOT NULL;
+
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @cloud_resource_menu_id, 'IP归属查询', 'infrastructure:cloud-resource:locate', 2, NULL, 2, 1
+WHERE @cloud_resource_menu_id IS NOT NULL;
+
+-- 授权给管理员角色
+INSERT IGNORE INTO `sys_role_menu` (`role_id`, `menu_id`)
+SELECT @admin_role_id, id FROM `sys_menu`
+WHERE @admin_role_id IS NOT NULL AND deleted_at IS NULL AND `perms` IN (
+  'system:user:create', 'system:user:update', 'system:user:delete', 'system:user:assign-role',
+  'system:role:create', 'system:role:update', 'system:role:delete', 'system:role:assign-menu',
+  'system:menu:create', 'system:menu:update', 'system:menu:delete',
+  'infrastructure:kubernetes:history-cleanup', 'infrastructure:cloud-resource:locate'
+);
+
+-- 接口权限映射，未配置映射的接口仅要求登录
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/users', 'system:user:list', '用户列表'),
+('GET', '/api/v1/users/:id', 'system:user:list', '用户详情'),
+('POST', '/api/v1/users', 'system:user:create', '创建用户'),
+('PUT', '/api/v1/users/:id', 'system:user:update', '更新用户'),
+('DELETE', '/api/v1/users/:id', 'system:user:delete', '删除用户'),
+('GET', '/api/v1/users/:id/roles', 'system:user:list', '用户角色'),
+('PUT', '/api/v1/users/:id/roles', 'system:user:assign-role', '分配用户角色'),
+('GET', '/api/v1/roles', 'system:role:list', '角色列表'),
+('GET', '/api/v1/roles/:id', 'system:role:list', '角色详情'),
+('POST', '/api/v1/roles', 'system:role:create', '创建角色'),
+('PUT', '/api/v1/roles/:id', 'system:role:update', '更新角色'),
+('DELETE', '/api/v1/roles/:id', 'system:role:delete', '删除角色'),
+('PUT', '/api/v1/roles/:id/menus', 'system:role:assign-menu', '分配角色菜单'),
+('GET', '/api/v1/menus', 'system:menu:list', '菜单列表'),
+('GET', '/api/v1/menus', 'system:role:assign-menu', '菜单列表'),
+('GET', '/api/v1/menus/:id', 'system:menu:list', '菜单详情'),
+('POST', '/api/v1/menus', 'system:menu:create', '创建菜单'),
+('PUT', '/api/v1/menus/:id', 'system:menu:update', '更新菜单'),
+('DELETE', '/api/v1/menus/:id', 'system:menu:delete', '删除菜单'),
+('GET', '/api/v1/api-permissions', 'system:menu:list', '接口权限列表'),
+('GET', '/api/v1/cloud-accounts', 'infrastructure:cloud-account:list', '云账号列表'),
+('GET', '/api/v1/cloud-accounts/:id', 'infrastructure:cloud-account:list', '云账号详情'),
+('POST', '/api/v1/cloud-accounts', 'infrastructure:cloud-account:create', '创建云账号'),
+('PUT', '/api/v1/cloud-accounts/:id', 'infrastructure:cloud-account:update', '更新云账号'),
+('DELETE', '/api/v1/cloud-accounts/:id', 'infrastructure:cloud-account:delete', '删除云账号'),
+('POST', '/api/v1/cloud-accounts/test', 'infrastructure:cloud-account:create', '测试云账号连接'),
+('POST', '/api/v1/cloud-accounts/test', 'infrastructure:cloud-account:update', '测试云账号连接'),
+('GET', '/api/v1/cloud-accounts/:id/regions', 'infrastructure:cloud-account:list', '云账号地域'),
+('POST', '/api/v1/cloud-accounts/:id/sync', 'infrastructure:cloud-account:sync', '同步云资源'),
+('GET', '/api/v1/cloud-resources', 'infrastructure:cloud-resource:list', '云资源列表'),
+('GET', '/api/v1/cloud-resources/search-ip', 'infrastructure:cloud-resource:list', '按IP查询云资源'),
+('GET', '/api/v1/cloud-resources/history', 'infrastructure:cloud-resource:history', '云资源历史'),
+('GET', '/api/v1/cloud-resources/:id', 'infrastructure:cloud-resource:list', '云资源详情'),
+('GET', '/api/v1/infrastructure/cloud-accounts', 'infrastructure:cloud-account:list', '云账号列表'),
+('GET', '/api/v1/infrastructure/cloud-accounts/:id', 'infrastructure:cloud-account:list', '云账号详情'),
+('POST', '/api/v1/infrastructure/cloud-accounts', 'infrastructure:cloud-account:create', '创建云账号'),
+('PUT', '/api/v1/infrastructure/cloud-accounts/:id', 'infrastructure:cloud-account:update', '更新云账号'),
+('DELETE', '/api/v1/infrastructure/cloud-accounts/:id', 'infrastructure:cloud-account:delete', '删除云账号'),
+('POST', '/api/v1/infrastructure/cloud-accounts/test', 'infrastructure:cloud-account:create', '测试云账号连接'),
+('POST', '/api/v1/infrastructure/cloud-accounts/test', 'infrastructure:cloud-account:update', '测试云账号连接'),
+('GET', '/api/v1/infrastructure/cloud-accounts/:id/regions', 'infrastructure:cloud-account:list', '云账号地域'),
+('POST', '/api/v1/infrastructure/cloud-accounts/:id/sync', 'infrastructure:cloud-account:sync', '同步云资源'),
+('GET', '/api/v1/infrastructure/cloud-resources', 'infrastructure:cloud-resource:list', '云资源列表'),
+('GET', '/api/v1/infrastructure/cloud-resources/search-ip', 'infrastructure:cloud-resource:list', '按IP查询云资源'),
+('GET', '/api/v1/infrastructure/cloud-resources/history', 'infrastructure:cloud-resource:history', '云资源历史'),
+('GET', '/api/v1/infrastructure/cloud-resources/:id', 'infrastructure:cloud-resource:list', '云资源详情'),
+('GET', '/api/v1/ip/locate', 'infrastructure:cloud-resource:locate', '查询IP归属'),
+('GET', '/api/v1/database-configs', 'infrastructure:database:list', '数据库列表'),
+('GET', '/api/v1/database-configs/:id', 'infrastructure:database:list', '数据库详情'),
+('POST', '/api/v1/database-configs', 'infrastructure:database:create', '创建数据库'),
+('PUT', '/api/v1/database-configs/:id', 'infrastructure:database:update', '更新数据库'),
+('DELETE', '/api/v1/database-configs/:id', 'infrastructure:database:delete', '删除数据库'),
+('GET', '/api/v1/infrastructure/database', 'infrastructure:database:list', '数据库列表'),
+('GET', '/api/v1/infrastructure/database/:id', 'infrastructure:database:list', '数据库详情'),
+('POST', '/api/v1/infrastructure/database', 'infrastructure:database:create', '创建数据库'),
+('PUT', '/api/v1/infrastructure/database/:id', 'infrastructure:database:update', '更新数据库'),
+('DELETE', '/api/v1/infrastructure/database/:id', 'infrastructure:database:delete', '删除数据库'),
+('POST', '/api/v1/database-configs/test', 'infrastructure:database:create', '测试数据库连接'),
+('POST', '/api/v1/database-configs/test', 'infrastructure:database:update', '测试数据库连接'),
+('GET', '/api/v1/server-configs', 'infrastructure:server:list', '服务器列表'),
+('GET', '/api/v1/server-configs/:id', 'infrastructure:server:list', '服务器详情'),
+('POST', '/api/v1/server-configs', 'infrastructure:server:create', '创建服务器'),
+('PUT', '/api/v1/server-configs/:id', 'infrastructure:server:update', '更新服务器'),
+('DELETE', '/api/v1/server-configs/:id', 'infrastructure:server:delete', '删除服务器'),
+('GET', '/api/v1/server-configs/:id/terminal', 'infrastructure:server:terminal', '服务器终端'),
+('GET', '/api/v1/infrastructure/server', 'infrastructure:server:list', '服务器列表'),
+('GET', '/api/v1/infrastructure/server/:id', 'infrastructure:server:list', '服务器详情'),
+('POST', '/api/v1/infrastructure/server', 'infrastructure:server:create', '创建服务器'),
+('PUT', '/api/v1/infrastructure/server/:id', 'infrastructure:server:update', '更新服务器'),
+('DELETE', '/api/v1/infrastructure/server/:id', 'infrastructure:server:delete', '删除服务器'),
+('GET', '/api/v1/infrastructure/server/:id/terminal', 'infrastructure:server:terminal', '服务器终端'),
+('POST', '/api/v1/server-configs/test', 'infrastructure:server:create', '测试服务器连接'),
+('POST', '/api/v1/server-configs/test', 'infrastructure:server:update', '测试服务器连接'),
+('GET', '/api/v1/server-configs/:id/permissions', 'infrastructure:server:update', '服务器终端授权'),
+('PUT', '/api/v1/server-configs/:id/permissions', 'infrastructure:server:update', '更新服务器终端授权'),
+('GET', '/api/v1/server-configs/:id/files', 'infrastructure:server:file', '服务器文件列表'),
+('GET', '/api/v1/server-configs/:id/files/stat', 'infrastructure:server:file', '服务器文件信息'),
+('GET', '/api/v1/server-configs/:id/files/download', 'infrastructure:server:file', '下载服务器文件'),
+('POST', '/api/v1/server-configs/:id/files/upload', 'infrastructure:server:file', '上传服务器文件'),
+('POST', '/api/v1/server-configs/:id/files/mkdir', 'infrastructure:server:file', '创建服务器目录'),
+('DELETE', '/api/v1/server-configs/:id/files', 'infrastructure:server:file', '删除服务器文件'),
+('GET', '/api/v1/server-file-audits', 'infrastructure:server:file', '服务器文件审计'),
+('GET', '/api/v1/server-sessions', 'infrastructure:server:session', '终端会话列表'),
+('GET', '/api/v1/server-sessions/:id', 'infrastructure:server:session', '终端会话详情'),
+('GET', '/api/v1/server-sessions/:id/recording', 'infrastructure:server:session', '终端会话回放'),
+('GET', '/api/v1/k8s-configs', 'infrastructure:kubernetes:list', 'Kubernetes列表'),
+('GET', '/api/v1/k8s-configs/with-workload-count', 'infrastructure:kubernetes:list', 'Kubernetes列表'),
+('GET', '/api/v1/k8s-configs/:id', 'infrastructure:kubernetes:list', 'Kubernetes详情'),
+('POST', '/api/v1/k8s-configs', 'infrastructure:kubernetes:create', '创建Kubernetes'),
+('PUT', '/api/v1/k8s-configs/:id', 'infrastructure:kubernetes:update', '更新Kubernetes'),
+('DELETE', '/api/v1/k8s-configs/:id', 'infrastructure:kubernetes:delete', '删除Kubernetes'),
+('POST', '/api/v1/k8s-configs/test', 'infrastructure:kubernetes:create', '测试Kubernetes连接'),
+('POST', '/api/v1/k8s-configs/test', 'infrastructure:kubernetes:update', '测试Kubernetes连接'),
+('GET', '/api/v1/infrastructure/kubernetes', 'infrastructure:kubernetes:list', 'Kubernetes列表'),
+('GET', '/api/v1/infrastructure/kubernetes/:id', 'infrastructure:kubernetes:list', 'Kubernetes详情'),
+('POST', '/api/v1/infrastructure/kubernetes', 'infrastructure:kubernetes:create', '创建Kubernetes'),
+('PUT', '/api/v1/infrastructure/kubernetes/:id', 'infrastructure:kubernetes:update', '更新Kubernetes'),
+('DELETE', '/api/v1/infrastructure/kubernetes/:id', 'infrastructure:kubernetes:delete', '删除Kubernetes'),
+('POST', '/api/v1/infrastructure/kubernetes/test', 'infrastructure:kubernetes:create', '测试Kubernetes连接'),
+('POST', '/api/v1/infrastructure/kubernetes/test', 'infrastructure:kubernetes:update', '测试Kubernetes连接'),
+('GET', '/api/v1/k8s-workloads', 'infrastructure:kubernetes:list', '工作负载列表'),
+('GET', '/api/v1/k8s-workloads/:id', 'infrastructure:kubernetes:list', '工作负载详情'),
+('GET', '/api/v1/k8s-pods', 'infrastructure:kubernetes:list', 'Pod列表'),
+('GET', '/api/v1/k8s-pods/:id', 'infrastructure:kubernetes:list', 'Pod详情'),
+('GET', '/api/v1/k8s-namespaces', 'infrastructure:kubernetes:list', '命名空间列表'),
+('GET', '/api/v1/k8s-nodes', 'infrastructure:kubernetes:list', '节点列表'),
+('GET', '/api/v1/k8s-nodes/:id', 'infrastructure:kubernetes:list', '节点详情'),
+('GET', '/api/v1/k8s-history/:configId/pods', 'infrastructure:kubernetes:list', 'Pod历史'),
+('GET', '/api/v1/k8s-history/:configId/nodes', 'infrastructure:kubernetes:list', '节点历史'),
+('GET', '/api/v1/k8s-history/:configId/workloads', 'infrastructure:kubernetes:list', '工作负载历史'),
+('GET', '/api/v1/k8s-history/:configId/statistics', 'infrastructure:kubernetes:list', '历史统计'),
+('DELETE', '/api/v1/k8s-nodes/:id', 'infrastructure:kubernetes:delete', '删除节点'),
+('POST', '/api/v1/k8s-history/cleanup', 'infrastructure:kubernetes:history-cleanup', '清理历史数据'),
+('GET', '/api/v1/infrastructure/cloud-providers', 'infrastructure:cloud-provider:list', '云厂商列表'),
+('GET', '/api/v1/infrastructure/cloud-providers/:id', 'infrastructure:cloud-provider:list', '云厂商详情'),
+('POST', '/api/v1/infrastructure/cloud-providers', 'infrastructure:cloud-provider:create', '创建云厂商'),
+('PUT', '/api/v1/infrastructure/cloud-providers/:id', 'infrastructure:cloud-provider:update', '更新云厂商'),
+('DELETE', '/api/v1/infrastructure/cloud-providers/:id', 'infrastructure:cloud-provider:delete', '删除云厂商');
@@ -0,0 +1,36 @@
+-- 角色数据范围：all 全部数据，custom 按规则限定
+ALTER TABLE `sys_role` ADD COLUMN `data_scope` varchar(20) NOT NULL DEFAULT 'all' COMMENT '数据范围：all/custom' AFTER `remark`;
+
+-- 创建角色数据范围规则表
+CREATE TABLE IF NOT EXISTS `sys_role_data_scope` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `role_id` bigint NOT NULL COMMENT '角色ID',
+  `scope_type` varchar(20) NOT NULL COMMENT '规则类型：cluster/namespace/server/database/cloud_account',
+  `resource_id` bigint NOT NULL DEFAULT '0' COMMENT '资源ID，命名空间规则为集群ID（0表示所有已授权集群）',
+  `pattern` varchar(128) DEFAULT NULL COMMENT '命名空间通配符模式，支持 * 和 ?',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_role_id` (`role_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色数据范围规则表';
+
+-- 获取管理员角色ID
+SET @admin_role_id = NULL;
+SELECT @admin_role_id := id FROM `sys_role` WHERE `code` = 'admin';
+
+SET @role_menu_id = NULL;
+SELECT @role_menu_id := id FROM `sys_menu` WHERE `perms` = 'system:role:list' AND `type` = 1 AND deleted_at IS NULL;
+
+-- 按钮权限
+INSERT INTO `sys_menu` (`parent_id`, `name`, `perms`, `type`, `icon`, `sort_order`, `status`)
+SELECT @role_menu_id, '分配数据范围', 'system:role:assign-data-scope', 2, NULL, 5, 1
+WHERE @role_menu_id IS NOT NULL;
+
+-- 授权给管理员角色
+INSERT IGNORE INTO `sys_role_menu` (`role_id`, `menu_id`)
+SELECT @admin_role_id, id FROM `sys_menu`
+WHERE @admin_role_id IS NOT NULL AND deleted_at IS NULL AND `perms` = 'system:role:assign-data-scope';
+
+-- 接口权限映射
+INSERT IGNORE INTO `sys_api_permission` (`method`, `path`, `perms`, `description`) VALUES
+('GET', '/api/v1/roles/:id/data-scopes', 'system:role:list', '角色数据范围'),
+('PUT', '/api/v1/roles/:id/data-scopes', 'system:role:assign-data-scope', '分配角色数据范围');
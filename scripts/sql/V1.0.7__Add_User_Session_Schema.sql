@@ -0,0 +1,34 @@
+-- 创建用户登录会话表，仅保存刷新令牌的 SHA-256 摘要
+CREATE TABLE IF NOT EXISTS `sys_user_session` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '会话ID',
+  `user_id` bigint NOT NULL COMMENT '用户ID',
+  `token_hash` varchar(64) NOT NULL COMMENT '当前刷新令牌摘要',
+  `previous_token_hash` varchar(64) DEFAULT NULL COMMENT '上一个刷新令牌摘要，用于识别令牌重放',
+  `client_ip` varchar(64) DEFAULT NULL COMMENT '客户端IP',
+  `user_agent` varchar(255) DEFAULT NULL COMMENT '客户端UA',
+  `expires_at` datetime NOT NULL COMMENT '会话过期时间',
+  `last_refreshed_at` datetime DEFAULT NULL COMMENT '最近刷新时间',
+  `revoked_at` datetime DEFAULT NULL COMMENT '吊销时间',
+  `revoke_reason` varchar(32) DEFAULT NULL COMMENT '吊销原因：logout/logout_all/user_disabled/user_deleted/password_changed/token_reuse',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_token_hash` (`token_hash`),
+  KEY `idx_previous_token_hash` (`previous_token_hash`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户登录会话表';
+
+-- 创建访问令牌吊销表，按 jti 拒绝已登出的访问令牌
+CREATE TABLE IF NOT EXISTS `sys_revoked_token` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT 'ID',
+  `jti` varchar(64) NOT NULL COMMENT '令牌ID',
+  `user_id` bigint NOT NULL COMMENT '用户ID',
+  `expires_at` datetime NOT NULL COMMENT '令牌过期时间，过期后记录可清理',
+  `reason` varchar(32) DEFAULT NULL COMMENT '吊销原因',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_jti` (`jti`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='访问令牌吊销表';
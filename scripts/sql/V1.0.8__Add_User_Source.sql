@@ -0,0 +1,2 @@
+-- 用户来源：local 本地用户，ldap 由LDAP登录自动创建
+ALTER TABLE `sys_user` ADD COLUMN `source` varchar(20) NOT NULL DEFAULT 'local' COMMENT '用户来源：local/ldap' AFTER `status`;
@@ -0,0 +1,2 @@
+-- 用户来源增加 oidc:<身份提供方名称>，由OIDC单点登录自动创建
+ALTER TABLE `sys_user` MODIFY COLUMN `source` varchar(64) NOT NULL DEFAULT 'local' COMMENT '用户来源：local/ldap/oidc:<provider>';
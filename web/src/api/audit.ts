@@ -0,0 +1,75 @@
+import request from '@/utils/request'
+import type { PageResult } from '@/types/api'
+
+// 字段变更，敏感字段的值已脱敏
+export interface AuditChange {
+  field: string
+  before: unknown
+  after: unknown
+}
+
+// 审计日志
+export interface AuditLog {
+  id: number
+  created_at: string
+  userId: number
+  username: string
+  clientIp: string
+  userAgent: string
+  apiTokenId: number
+  method: string
+  route: string
+  path: string
+  action: string
+  resourceType: string
+  resourceId: string
+  resourceName: string
+  changes: AuditChange[] | null
+  request: string
+  result: 'success' | 'failed'
+  statusCode: number
+  code: number
+  message: string
+  duration: number
+}
+
+// 审计日志查询条件
+export interface AuditLogQuery {
+  username?: string
+  action?: string
+  resourceType?: string
+  resourceId?: string
+  result?: string
+  clientIp?: string
+  keyword?: string
+  startTime?: string
+  endTime?: string
+}
+
+// 分页查询审计日志
+export function getAuditLogs(params: AuditLogQuery & { page: number; pageSize: number }) {
+  return request<PageResult<AuditLog>>({
+    url: '/api/v1/audit-logs',
+    method: 'get',
+    params
+  })
+}
+
+// 获取审计日志详情
+export function getAuditLog(id: number) {
+  return request<AuditLog>({
+    url: `/api/v1/audit-logs/${id}`,
+    method: 'get'
+  })
+}
+
+// 导出审计日志，返回文件内容
+export function exportAuditLogs(params: AuditLogQuery & { format: 'csv' | 'jsonl' }) {
+  return request({
+    url: '/api/v1/audit-logs/export',
+    method: 'get',
+    params,
+    responseType: 'blob',
+    timeout: 0
+  }) as unknown as Promise<Blob>
+}
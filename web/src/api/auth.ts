@@ -7,10 +7,32 @@ interface LoginRequest {
   password: string
 }
 
-// 登录响应数据
-export interface LoginResponse {
+// 登录响应数据，需要两步验证或修改密码时返回登录挑战
+export interface LoginResponse extends Partial<TokenResponse>, Partial<LoginChallenge> {
+  user?: User
+}
+
+// 登录挑战：totp 两步验证，password_change 首次登录修改密码
+export interface LoginChallenge {
+  challenge: 'totp' | 'password_change'
+  challengeToken: string
+  expiresIn: number
+}
+
+// 令牌响应数据
+export interface TokenResponse {
   token: string
-  user: User
+  refreshToken: string
+  expiresIn: number
+  refreshExpiresIn: number
+}
+
+// 登录接口失败时返回业务错误码，统一转换为异常
+function loginResult(res: { code: number; message: string; data: LoginResponse }) {
+  if (res.code !== 0) {
+    throw new Error(res.message || '登录失败')
+  }
+  return res.data
 }
 
 // 登录
@@ -19,6 +41,52 @@ export function login(data: LoginRequest) {
     url: '/api/v1/login',
     method: 'post',
     data
+  }).then(loginResult)
+}
+
+// 登录两步验证，code 为验证码或恢复码
+export function loginTOTP(challengeToken: string, code: string) {
+  return request<LoginResponse>({
+    url: '/api/v1/login/totp',
+    method: 'post',
+    data: { challengeToken, code }
+  }).then(loginResult)
+}
+
+// 首次登录修改密码
+export function loginPassword(challengeToken: string, newPassword: string) {
+  return request<LoginResponse>({
+    url: '/api/v1/login/password',
+    method: 'post',
+    data: { challengeToken, newPassword }
+  }).then(loginResult)
+}
+
+// 单点登录身份提供方
+export interface OIDCProvider {
+  name: string
+  displayName: string
+}
+
+// 获取单点登录身份提供方
+export function getOIDCProviders() {
+  return request<OIDCProvider[]>({
+    url: '/api/v1/oidc/providers',
+    method: 'get'
+  }).then(res => res.data)
+}
+
+// 单点登录地址，浏览器跳转后由后端重定向到身份提供方
+export function oidcLoginURL(provider: string) {
+  return `${import.meta.env.VITE_API_URL || ''}/api/v1/oidc/${encodeURIComponent(provider)}/login`
+}
+
+// 刷新令牌
+export function refreshToken(refreshToken: string) {
+  return request<TokenResponse>({
+    url: '/api/v1/refresh',
+    method: 'post',
+    data: { refreshToken }
   }).then(res => res.data)
 }
 
@@ -30,6 +98,14 @@ export function logout() {
   }).then(res => res.data)
 }
 
+// 登出全部会话
+export function logoutAll() {
+  return request<null>({
+    url: '/api/v1/logout/all',
+    method: 'post'
+  }).then(res => res.data)
+}
+
 // 获取用户信息
 export function getUserInfo() {
   return request<User>({
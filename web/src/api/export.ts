@@ -0,0 +1,56 @@
+import request from '@/utils/request'
+
+// 列表导出参数，其余参数与对应列表接口的筛选条件相同
+export interface ExportParams {
+  format?: 'csv' | 'xlsx'
+  // 逗号分隔的列名，与列表接口返回的字段名相同，为空导出全部列
+  columns?: string
+  // 导出数据库中的原始值，默认导出与列表一致的格式化值
+  raw?: boolean
+  [key: string]: any
+}
+
+// 历史数据导出必须指定时间范围，跨度不超过31天，时间格式为 YYYY-MM-DD HH:mm:ss
+export interface HistoryExportParams extends ExportParams {
+  startTime: string
+  endTime: string
+}
+
+// 下载导出文件，返回文件内容
+function download(url: string, params: ExportParams) {
+  return request({
+    url,
+    method: 'get',
+    params,
+    responseType: 'blob',
+    timeout: 0
+  }) as unknown as Promise<Blob>
+}
+
+export function exportK8sClusters(params: ExportParams) {
+  return download('/api/v1/k8s-configs/export', params)
+}
+
+export function exportK8sWorkloads(params: ExportParams) {
+  return download('/api/v1/k8s-workloads/export', params)
+}
+
+export function exportK8sPods(params: ExportParams) {
+  return download('/api/v1/k8s-pods/export', params)
+}
+
+export function exportK8sNodes(params: ExportParams) {
+  return download('/api/v1/k8s-nodes/export', params)
+}
+
+export function exportServers(params: ExportParams) {
+  return download('/api/v1/server-configs/export', params)
+}
+
+export function exportDatabases(params: ExportParams) {
+  return download('/api/v1/database-configs/export', params)
+}
+
+export function exportK8sHistory(configId: number, type: 'pods' | 'nodes' | 'workloads', params: HistoryExportParams) {
+  return download(`/api/v1/k8s-history/${configId}/${type}/export`, params)
+}
@@ -0,0 +1,140 @@
+import request from '@/utils/request'
+import type { BaseResponse, PageQuery, PageResult } from '@/types/api'
+
+// 资源量，cpu 以核、memory 以字节为单位
+export interface K8sResourceAmount {
+  cpu: number
+  memory: number
+}
+
+export interface K8sQoSCount {
+  guaranteed: number
+  burstable: number
+  bestEffort: number
+}
+
+// 请求占集群可分配资源的百分比，以及限制与请求的比值
+export interface K8sCapacityShare {
+  cpuRequestShare: number
+  memoryRequestShare: number
+  cpuLimitRatio: number
+  memoryLimitRatio: number
+}
+
+// 按用量给出的每副本请求调整建议
+export interface K8sRightsizingRecommendation {
+  resource: 'cpu' | 'memory'
+  action: 'set' | 'increase' | 'decrease'
+  current: number
+  usage: number
+  recommended: number
+  reason: string
+}
+
+export interface K8sWorkloadCapacity {
+  id: number
+  name: string
+  namespace: string
+  kind: string
+  replicas: number
+  podCount: number
+  replicaRequests: K8sResourceAmount
+  replicaLimits: K8sResourceAmount
+  requests: K8sResourceAmount
+  limits: K8sResourceAmount
+  share: K8sCapacityShare
+  missingRequests?: string[]
+  missingLimits?: string[]
+  usage?: K8sResourceAmount
+  recommendations?: K8sRightsizingRecommendation[]
+  bestEffortPods: number
+}
+
+export interface K8sNamespaceCapacity {
+  namespace: string
+  podCount: number
+  workloadCount: number
+  requests: K8sResourceAmount
+  limits: K8sResourceAmount
+  share: K8sCapacityShare
+  qos: K8sQoSCount
+  missingRequestWorkloads: number
+  missingLimitWorkloads: number
+  usage?: K8sResourceAmount
+}
+
+export interface K8sCapacityPod {
+  id: number
+  name: string
+  namespace: string
+  workloadKind?: string
+  workloadName?: string
+  nodeName: string
+}
+
+export interface K8sCapacityReport {
+  configId: number
+  namespace?: string
+  generatedAt: string
+  nodeCount: number
+  allocatable: K8sResourceAmount
+  podCount: number
+  workloadCount: number
+  requests: K8sResourceAmount
+  limits: K8sResourceAmount
+  share: K8sCapacityShare
+  qos: K8sQoSCount
+  missingRequestWorkloads: number
+  missingLimitWorkloads: number
+  recommendationCount: number
+  usageAvailable: boolean
+  namespaces: K8sNamespaceCapacity[]
+  workloads: K8sWorkloadCapacity[]
+  bestEffortPods: K8sCapacityPod[]
+  truncated: boolean
+  warnings?: string[]
+}
+
+// 每周容量汇总
+export interface K8sCapacitySummary {
+  id: number
+  configId: number
+  configName: string
+  weekStart: string
+  nodeCount: number
+  podCount: number
+  workloadCount: number
+  cpuAllocatable: number
+  cpuRequested: number
+  cpuRequestShare: number
+  memoryAllocatable: number
+  memoryRequested: number
+  memoryRequestShare: number
+  missingRequestWorkloads: number
+  missingLimitWorkloads: number
+  bestEffortPods: number
+  recommendationCount: number
+  usageAvailable: boolean
+  namespaces: K8sNamespaceCapacity[]
+  workloads: K8sWorkloadCapacity[]
+  warnings?: string[]
+  createdAt: string
+}
+
+// 获取集群或命名空间的容量报告，includeUsage 时从 metrics-server 获取用量并给出调整建议
+export function getK8sCapacityReport(configId: number, params?: { namespace?: string; includeUsage?: boolean }) {
+  return request<BaseResponse<K8sCapacityReport>>({
+    url: `/api/v1/k8s-capacity/${configId}`,
+    method: 'get',
+    params
+  })
+}
+
+// 获取集群的每周容量汇总
+export function getK8sCapacitySummaries(configId: number, params?: PageQuery) {
+  return request<BaseResponse<PageResult<K8sCapacitySummary>>>({
+    url: `/api/v1/k8s-capacity/${configId}/summaries`,
+    method: 'get',
+    params
+  })
+}
@@ -0,0 +1,54 @@
+import request from '@/utils/request'
+import type { BaseResponse, PageResult } from '@/types/api'
+
+// K8s资源字段变更
+export interface K8sFieldChange {
+  field: string
+  old: any
+  new: any
+}
+
+// K8s资源变更事件
+export interface K8sChangeEvent {
+  id: number
+  configId: number
+  kind: 'pod' | 'node' | 'workload'
+  workloadKind?: string
+  namespace: string
+  name: string
+  action: 'created' | 'updated' | 'deleted'
+  changes: K8sFieldChange[]
+  fields: string
+  source: string
+  observedAt: string
+}
+
+export interface K8sChangeQuery {
+  configId?: number
+  kind?: string
+  workloadKind?: string
+  namespace?: string
+  name?: string
+  action?: string
+  field?: string
+  page?: number
+  pageSize?: number
+}
+
+// 获取最近的资源变更，window 如 30m、1h，默认最近一小时
+export function getRecentK8sChanges(params: K8sChangeQuery & { window?: string }) {
+  return request<BaseResponse<PageResult<K8sChangeEvent>>>({
+    url: '/api/v1/k8s-changes',
+    method: 'get',
+    params
+  })
+}
+
+// 获取单个资源的变更时间线
+export function getK8sChangeTimeline(params: K8sChangeQuery & { startTime?: string; endTime?: string }) {
+  return request<BaseResponse<PageResult<K8sChangeEvent>>>({
+    url: '/api/v1/k8s-changes/timeline',
+    method: 'get',
+    params
+  })
+}
@@ -28,6 +28,25 @@ export function cleanupK8sHistory(beforeDate: string) {
   })
 }
 
+// 列出历史数据归档文件
+export function listK8sHistoryArchives(params: { kind?: string; configId?: number; startDate?: string; endDate?: string }) {
+  return request({
+    url: '/api/k8s-history/archives',
+    method: 'get',
+    params
+  })
+}
+
+// 从归档恢复某个集群在日期范围内的历史数据
+export function restoreK8sHistoryArchive(data: { configId: number; kind?: string; startDate: string; endDate: string }) {
+  return request({
+    url: '/api/k8s-history/restore',
+    method: 'post',
+    data,
+    timeout: 0
+  })
+}
+
 // 历史数据类型定义
 export interface K8sHistoryQuery {
   page: number
@@ -56,6 +75,7 @@ export interface K8sPodHistory {
   deleted_at?: string
   archived_at: string
   archive_reason: string
+  restored_at?: string
 }
 
 export interface K8sNodeHistory {
@@ -92,6 +112,7 @@ export interface K8sNodeHistory {
   deleted_at?: string
   archived_at: string
   archive_reason: string
+  restored_at?: string
 }
 
 export interface K8sWorkloadHistory {
@@ -116,6 +137,7 @@ export interface K8sWorkloadHistory {
   deleted_at?: string
   archived_at: string
   archive_reason: string
+  restored_at?: string
 }
 
 export interface K8sHistoryStatistics {
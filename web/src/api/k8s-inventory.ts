@@ -0,0 +1,120 @@
+import request from '@/utils/request'
+import type { BaseResponse, PageResult } from '@/types/api'
+
+// 数据来源：live 表示对象至今仍存在，history 表示对象已被删除
+export type K8sInventorySource = 'live' | 'history'
+
+// 某一时间点存在的Pod
+export interface K8sInventoryPod {
+  original_id: number
+  config_id: number
+  workload_id?: number
+  name: string
+  namespace: string
+  workload_name?: string
+  workload_kind?: string
+  status: string
+  phase?: string
+  node_name?: string
+  pod_ip?: string
+  host_ip?: string
+  instance_ip?: string
+  cpu_request?: string
+  cpu_limit?: string
+  memory_request?: string
+  memory_limit?: string
+  restart_count: number
+  start_time?: string
+  created_at: string
+  removed_at?: string
+  source: K8sInventorySource
+}
+
+// 某一时间点存在的节点
+export interface K8sInventoryNode {
+  originalId: number
+  configId: number
+  name: string
+  internalIP?: string
+  externalIP?: string
+  hostname?: string
+  osImage?: string
+  kernelVersion?: string
+  containerRuntime?: string
+  kubeletVersion?: string
+  cpuCapacity?: string
+  memoryCapacity?: string
+  podsCapacity?: string
+  cpuAllocatable?: string
+  memoryAllocatable?: string
+  podsAllocatable?: string
+  labels?: string
+  taints?: string
+  status: string
+  ready: boolean
+  schedulable: boolean
+  createdAt: string
+  removedAt?: string
+  source: K8sInventorySource
+}
+
+// 某一时间点存在的工作负载
+export interface K8sInventoryWorkload {
+  original_id: number
+  config_id: number
+  name: string
+  namespace: string
+  kind: string
+  replicas: number
+  ready_replicas: number
+  status?: string
+  labels?: string
+  selector?: string
+  images?: string
+  cpu_request?: string
+  cpu_limit?: string
+  memory_request?: string
+  memory_limit?: string
+  created_at: string
+  removed_at?: string
+  source: K8sInventorySource
+}
+
+// at 为 "2006-01-02 15:04:05" 或 RFC3339 格式的时间点
+export interface K8sInventoryQuery {
+  at: string
+  namespace?: string
+  name?: string
+  nodeName?: string
+  workloadName?: string
+  workloadKind?: string
+  page?: number
+  pageSize?: number
+}
+
+// 获取某一时间点的Pod清单
+export function getK8sPodsAt(configId: number, params: K8sInventoryQuery) {
+  return request<BaseResponse<PageResult<K8sInventoryPod>>>({
+    url: `/api/v1/k8s-inventory/${configId}/pods`,
+    method: 'get',
+    params
+  })
+}
+
+// 获取某一时间点的节点清单
+export function getK8sNodesAt(configId: number, params: K8sInventoryQuery) {
+  return request<BaseResponse<PageResult<K8sInventoryNode>>>({
+    url: `/api/v1/k8s-inventory/${configId}/nodes`,
+    method: 'get',
+    params
+  })
+}
+
+// 获取某一时间点的工作负载清单
+export function getK8sWorkloadsAt(configId: number, params: K8sInventoryQuery) {
+  return request<BaseResponse<PageResult<K8sInventoryWorkload>>>({
+    url: `/api/v1/k8s-inventory/${configId}/workloads`,
+    method: 'get',
+    params
+  })
+}
@@ -0,0 +1,33 @@
+import request from '@/utils/request'
+import type { BaseResponse } from '@/types/api'
+
+// 全局搜索命中的K8s资源
+export interface K8sSearchHit {
+  kind: 'pod' | 'node' | 'workload'
+  id: number
+  configId: number
+  configName: string
+  namespace?: string
+  name: string
+  workloadKind?: string
+  status: string
+  // 命中的字段，如 name、pod_ip、images、labels.app
+  matchField: string
+  matchValue: string
+}
+
+export interface K8sSearchResult {
+  keyword: string
+  pods: K8sSearchHit[]
+  workloads: K8sSearchHit[]
+  nodes: K8sSearchHit[]
+}
+
+// 在全部集群中按名称、镜像、IP和标签搜索，limit 为每类资源返回的条数
+export function searchK8sResources(params: { keyword: string; limit?: number }) {
+  return request<BaseResponse<K8sSearchResult>>({
+    url: '/api/v1/k8s-search',
+    method: 'get',
+    params
+  })
+}
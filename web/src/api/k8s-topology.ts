@@ -0,0 +1,50 @@
+import request from '@/utils/request'
+import type { BaseResponse } from '@/types/api'
+
+export type K8sTopologyHealth = 'healthy' | 'warning' | 'critical' | 'unknown'
+
+// 拓扑图中的顶点，id 如 workload:12、pod:34、node:node-1、service:default/web
+export interface K8sTopologyVertex {
+  id: string
+  kind: 'workload' | 'pod' | 'node' | 'service' | 'ingress'
+  resourceId?: number
+  name: string
+  namespace?: string
+  workloadKind?: string
+  status: string
+  health: K8sTopologyHealth
+  reason?: string
+}
+
+export interface K8sTopologyEdge {
+  source: string
+  target: string
+  type: 'owns' | 'runs_on' | 'selects' | 'routes'
+}
+
+// 拓扑分析发现的问题，如多副本工作负载的全部Pod在同一节点
+export interface K8sTopologyInsight {
+  type: 'single_node_replicas'
+  vertexId: string
+  message: string
+  related?: string[]
+}
+
+export interface K8sTopology {
+  configId: number
+  namespace?: string
+  vertices: K8sTopologyVertex[]
+  edges: K8sTopologyEdge[]
+  insights: K8sTopologyInsight[]
+  truncated: boolean
+  warnings?: string[]
+}
+
+// 获取集群或命名空间的 工作负载 -> Pod -> 节点 拓扑，includeServices 时包含 Service 和 Ingress
+export function getK8sTopology(configId: number, params?: { namespace?: string; includeServices?: boolean }) {
+  return request<BaseResponse<K8sTopology>>({
+    url: `/api/v1/k8s-topology/${configId}`,
+    method: 'get',
+    params
+  })
+}
@@ -7,6 +7,8 @@ export interface WorkloadQueryParams {
   namespace?: string
   workloadType?: string
   configId?: string
+  // 标签选择器，如 app=foo,tier in (web,api),!canary
+  labelSelector?: string
 }
 
 export interface Workload {
@@ -50,4 +50,12 @@ export function deleteMenu(id: number) {
     url: `/api/v1/menus/${id}`,
     method: 'delete'
   })
-}
\ No newline at end of file
+}
+// 导出菜单树，返回文件内容
+export function exportMenus() {
+  return request({
+    url: '/api/v1/menus/export',
+    method: 'get',
+    responseType: 'blob'
+  }) as unknown as Promise<Blob>
+}
@@ -59,4 +59,64 @@ export function assignRoleMenus(roleId: number, menuIds: number[]) {
     method: 'put',
     data: { menuIds }
   })
-}
\ No newline at end of file
+}
+// 角色权限集合，菜单以权限标识或完整路由路径表示，可在不同环境间比较
+export interface RolePermissionSet {
+  name: string
+  code: string
+  status: number
+  remark: string
+  dataScope: string
+  menus: string[]
+  dataScopeRules: string[]
+}
+
+// 角色权限差异，Added 为目标角色有而源角色没有的权限
+export interface RoleDiff {
+  source: string
+  target: string
+  identical: boolean
+  menusAdded: string[]
+  menusRemoved: string[]
+  sourceDataScope: string
+  targetDataScope: string
+  dataScopeChanged: boolean
+  rulesAdded: string[]
+  rulesRemoved: string[]
+}
+
+// 复制角色
+export function cloneRole(id: number, data: Partial<Role>) {
+  return request<Role>({
+    url: `/api/v1/roles/${id}/clone`,
+    method: 'post',
+    data
+  })
+}
+
+// 与本环境的其他角色比较权限
+export function diffRole(id: number, targetId: number) {
+  return request<RoleDiff>({
+    url: `/api/v1/roles/${id}/diff`,
+    method: 'get',
+    params: { targetId }
+  })
+}
+
+// 与导出的角色权限集合比较
+export function diffRoleWithSet(id: number, data: RolePermissionSet) {
+  return request<RoleDiff>({
+    url: `/api/v1/roles/${id}/diff`,
+    method: 'post',
+    data
+  })
+}
+
+// 导出全部角色的权限集合，返回文件内容
+export function exportRoles() {
+  return request({
+    url: '/api/v1/roles/export',
+    method: 'get',
+    responseType: 'blob'
+  }) as unknown as Promise<Blob>
+}
@@ -67,6 +67,78 @@ export function updateUserStatus(id: number, status: string) {
   })
 }
 
+// 导入结果中单行的处理结果
+export interface UserImportRow {
+  row: number
+  username: string
+  status: 'created' | 'updated' | 'skipped' | 'failed'
+  errors?: string[]
+  password?: string
+}
+
+// 用户导入结果
+export interface UserImportResult {
+  dryRun: boolean
+  total: number
+  created: number
+  updated: number
+  skipped: number
+  failed: number
+  rows: UserImportRow[]
+}
+
+// 从 CSV/XLSX 文件导入用户，dryRun 时只校验不写入
+export function importUsers(file: File, options: { dryRun: boolean; updateExisting: boolean }) {
+  const data = new FormData()
+  data.append('file', file)
+  data.append('dryRun', String(options.dryRun))
+  data.append('updateExisting', String(options.updateExisting))
+  return request<UserImportResult>({
+    url: '/api/v1/users/import',
+    method: 'post',
+    data,
+    timeout: 0
+  })
+}
+
+// 下载用户导入模板
+export function downloadUserImportTemplate() {
+  return request({
+    url: '/api/v1/users/import/template',
+    method: 'get',
+    responseType: 'blob'
+  }) as unknown as Promise<Blob>
+}
+
+// 导出用户，返回文件内容
+export function exportUsers(format: 'csv' | 'json') {
+  return request({
+    url: '/api/v1/users/export',
+    method: 'get',
+    params: { format },
+    responseType: 'blob',
+    timeout: 0
+  }) as unknown as Promise<Blob>
+}
+
+// 批量启用或禁用用户
+export function batchUserStatus(ids: number[], status: number) {
+  return request<{ affected: number }>({
+    url: '/api/v1/users/batch/status',
+    method: 'post',
+    data: { ids, status }
+  })
+}
+
+// 批量分配用户角色，mode 为 replace 时覆盖原有角色
+export function batchUserRoles(userIds: number[], roleIds: number[], mode: 'replace' | 'add' | 'remove') {
+  return request<null>({
+    url: '/api/v1/users/batch/roles',
+    method: 'post',
+    data: { userIds, roleIds, mode }
+  })
+}
+
 // 获取用户信息
 export function getUserInfo() {
   return request<User>({
@@ -92,6 +164,203 @@ export function assignUserRoles(userId: number, roleIds: number[]) {
   })
 }
 
+// 重置用户两步验证
+export function resetUserTOTP(userId: number) {
+  return request<null>({
+    url: `/api/v1/users/${userId}/totp`,
+    method: 'delete'
+  })
+}
+
+// 解除用户登录锁定
+export function unlockUser(userId: number) {
+  return request<null>({
+    url: `/api/v1/users/${userId}/unlock`,
+    method: 'post'
+  })
+}
+
+// 修改当前用户密码
+export function changePassword(oldPassword: string, newPassword: string) {
+  return request<null>({
+    url: '/api/v1/users/info/password',
+    method: 'put',
+    data: { oldPassword, newPassword }
+  })
+}
+
+// 两步验证状态
+export interface TOTPStatus {
+  enabled: boolean
+  recoveryCodes: number
+}
+
+// 两步验证绑定信息
+export interface TOTPSetup {
+  secret: string
+  uri: string
+}
+
+// 获取当前用户两步验证状态
+export function getTOTPStatus() {
+  return request<TOTPStatus>({
+    url: '/api/v1/users/info/totp',
+    method: 'get'
+  })
+}
+
+// 生成两步验证绑定密钥
+export function setupTOTP() {
+  return request<TOTPSetup>({
+    url: '/api/v1/users/info/totp/setup',
+    method: 'post'
+  })
+}
+
+// 启用两步验证，返回恢复码
+export function enableTOTP(code: string) {
+  return request<{ recoveryCodes: string[] }>({
+    url: '/api/v1/users/info/totp/enable',
+    method: 'post',
+    data: { code }
+  })
+}
+
+// 关闭两步验证
+export function disableTOTP(code: string) {
+  return request<null>({
+    url: '/api/v1/users/info/totp/disable',
+    method: 'post',
+    data: { code }
+  })
+}
+
+// 重新生成恢复码
+export function regenerateRecoveryCodes(code: string) {
+  return request<{ recoveryCodes: string[] }>({
+    url: '/api/v1/users/info/totp/recovery-codes',
+    method: 'post',
+    data: { code }
+  })
+}
+
+// 获取当前用户权限标识
+export function getUserPermissions() {
+  return request<string[]>({
+    url: '/api/v1/users/info/permissions',
+    method: 'get'
+  })
+}
+
+// 当前用户可见的菜单路由
+export interface MenuRoute {
+  id: number
+  name: string
+  path: string
+  component: string
+  redirect?: string
+  meta: {
+    title: string
+    icon?: string
+    hidden: boolean
+    keepAlive: boolean
+    perms?: string
+  }
+  children?: MenuRoute[]
+}
+
+// 获取当前用户可见的菜单路由树
+export function getUserMenus() {
+  return request<MenuRoute[]>({
+    url: '/api/v1/users/info/menus',
+    method: 'get'
+  })
+}
+
+// API令牌
+export interface APIToken {
+  id: number
+  name: string
+  prefix: string
+  scopes: string[]
+  expiresAt: string | null
+  lastUsedAt: string | null
+  lastUsedIp: string
+  revokedAt: string | null
+  created_at: string
+}
+
+// 新创建的API令牌，token 仅在创建时返回一次
+export interface CreatedAPIToken extends APIToken {
+  token: string
+}
+
+// 创建API令牌参数
+export interface APITokenForm {
+  name: string
+  scopes: string[]
+  expiresInDays: number
+}
+
+// 获取当前用户的API令牌
+export function getAPITokens() {
+  return request<APIToken[]>({
+    url: '/api/v1/api-tokens',
+    method: 'get'
+  })
+}
+
+// 创建当前用户的API令牌
+export function createAPIToken(data: APITokenForm) {
+  return request<CreatedAPIToken>({
+    url: '/api/v1/api-tokens',
+    method: 'post',
+    data
+  })
+}
+
+// 吊销当前用户的API令牌
+export function revokeAPIToken(id: number) {
+  return request<null>({
+    url: `/api/v1/api-tokens/${id}`,
+    method: 'delete'
+  })
+}
+
+// 创建服务账号
+export function createServiceAccount(data: { username: string; nickname?: string; email?: string; roleIds?: number[] }) {
+  return request<User>({
+    url: '/api/v1/service-accounts',
+    method: 'post',
+    data
+  })
+}
+
+// 获取指定用户的API令牌
+export function getUserAPITokens(userId: number) {
+  return request<APIToken[]>({
+    url: `/api/v1/users/${userId}/api-tokens`,
+    method: 'get'
+  })
+}
+
+// 为服务账号创建API令牌
+export function createUserAPIToken(userId: number, data: APITokenForm) {
+  return request<CreatedAPIToken>({
+    url: `/api/v1/users/${userId}/api-tokens`,
+    method: 'post',
+    data
+  })
+}
+
+// 吊销指定用户的API令牌
+export function revokeUserAPIToken(userId: number, tokenId: number) {
+  return request<null>({
+    url: `/api/v1/users/${userId}/api-tokens/${tokenId}`,
+    method: 'delete'
+  })
+}
+
 export function getInfo() {
   return request({
     url: '/api/v1/users/info',
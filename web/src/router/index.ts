@@ -149,6 +149,12 @@ export const constantRoutes: Array<RouteRecordRaw> = [
         name: 'Menu',
         component: () => import('@/views/system/menu/index.vue'),
         meta: { title: '菜单管理', icon: 'Menu' }
+      },
+      {
+        path: 'audit',
+        name: 'Audit',
+        component: () => import('@/views/system/audit/index.vue'),
+        meta: { title: '审计日志', icon: 'Document' }
       }
     ]
   },
@@ -1,7 +1,7 @@
 import { defineStore } from 'pinia'
 import { ref } from 'vue'
-import { login, logout, getUserInfo } from '@/api/auth'
-import { getToken, setToken, removeToken, clearAuth } from '@/utils/auth'
+import { login, loginTOTP, loginPassword, logout, getUserInfo, type LoginResponse } from '@/api/auth'
+import { getToken, setToken, setRefreshToken, removeToken, removeRefreshToken, clearAuth } from '@/utils/auth'
 import type { User } from '@/types/api'
 import router from '@/router'
 
@@ -9,24 +9,55 @@ export const useUserStore = defineStore('user', () => {
   const token = ref<string | null>(getToken())
   const user = ref<User | null>(null)
 
-  // 登录
-  async function loginAction(username: string, password: string) {
-    try {
-      const res = await login({ username, password })
-      // login 函数已经返回了 res.data，所以这里直接解构
-      const { token: newToken, user: userInfo } = res
-      token.value = newToken
-      user.value = userInfo
-      setToken(newToken)
+  // 保存登录结果；需要两步验证或修改密码时原样返回登录挑战
+  function applyLoginResult(res: LoginResponse) {
+    if (res.challenge) {
       return res
+    }
+    const { token: newToken, refreshToken, user: userInfo } = res
+    token.value = newToken as string
+    user.value = userInfo || null
+    setToken(newToken as string)
+    setRefreshToken(refreshToken as string)
+    return res
+  }
+
+  // 登录步骤失败时清除本地状态
+  async function loginStep(step: () => Promise<LoginResponse>) {
+    try {
+      return applyLoginResult(await step())
     } catch (error) {
       token.value = null
       user.value = null
       removeToken()
+      removeRefreshToken()
       throw error
     }
   }
 
+  // 登录
+  async function loginAction(username: string, password: string) {
+    return loginStep(() => login({ username, password }))
+  }
+
+  // 登录两步验证
+  async function loginTOTPAction(challengeToken: string, code: string) {
+    return loginStep(() => loginTOTP(challengeToken, code))
+  }
+
+  // 首次登录修改密码
+  async function loginPasswordAction(challengeToken: string, newPassword: string) {
+    return loginStep(() => loginPassword(challengeToken, newPassword))
+  }
+
+  // 单点登录完成后保存后端签发的令牌
+  function ssoLoginAction(newToken: string, refreshToken: string) {
+    token.value = newToken
+    user.value = null
+    setToken(newToken)
+    setRefreshToken(refreshToken)
+  }
+
   // 登出
   async function logoutAction() {
     try {
@@ -64,6 +95,9 @@ export const useUserStore = defineStore('user', () => {
     user,
     userInfo: user, // 添加别名以兼容现有代码
     loginAction,
+    loginTOTPAction,
+    loginPasswordAction,
+    ssoLoginAction,
     logoutAction,
     logout: logoutAction, // 添加别名以兼容现有代码
     getUserInfoAction
@@ -5,16 +5,23 @@ export interface BaseResponse<T> {
   data: T
 }
 
-// 分页查询参数
+// 分页查询参数，cursor 为上一页返回的 nextCursor，指定后按游标翻页
 export interface PageQuery {
   page: number
   pageSize: number
+  sortBy?: string
+  sortOrder?: 'asc' | 'desc'
+  cursor?: string
 }
 
-// 分页结果
+// 分页结果，nextCursor 仅在支持游标分页的列表且有下一页时返回
 export interface PageResult<T> {
   list: T[]
   total: number
+  page: number
+  pageSize: number
+  nextCursor?: string
+  hasMore: boolean
 }
 
 // 用户接口
@@ -28,6 +35,9 @@ export interface User {
   status: string
   roleIds: number[]
   roles: Role[]
+  source?: string
+  totp_enabled?: boolean
+  password_change_required?: boolean
   created_at: string
   updated_at: string
 }
@@ -57,6 +67,8 @@ export interface Menu {
   type: string
   permission: string
   status: string
+  hidden?: boolean
+  cache?: boolean
   children?: Menu[]
   created_at: string
   updated_at: string
@@ -1,6 +1,8 @@
 // Token key
 export const TOKEN_KEY = 'token'
 
+export const REFRESH_TOKEN_KEY = 'refresh-token'
+
 export const USER_INFO_KEY = 'eden-user'
 
 // 获取 token
@@ -18,6 +20,21 @@ export function removeToken(): void {
   localStorage.removeItem(TOKEN_KEY)
 }
 
+// 获取刷新令牌
+export function getRefreshToken(): string | null {
+  return localStorage.getItem(REFRESH_TOKEN_KEY)
+}
+
+// 设置刷新令牌
+export function setRefreshToken(token: string): void {
+  localStorage.setItem(REFRESH_TOKEN_KEY, token)
+}
+
+// 删除刷新令牌
+export function removeRefreshToken(): void {
+  localStorage.removeItem(REFRESH_TOKEN_KEY)
+}
+
 export function getUserInfo(): any | null {
   const userInfo = localStorage.getItem(USER_INFO_KEY)
   return userInfo ? JSON.parse(userInfo) : null
@@ -33,5 +50,6 @@ export function removeUserInfo(): void {
 
 export function clearAuth(): void {
   removeToken()
+  removeRefreshToken()
   removeUserInfo()
 } 
\ No newline at end of file
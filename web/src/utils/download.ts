@@ -0,0 +1,26 @@
+import { ElMessage } from 'element-plus'
+
+// 保存导出的文件。接口出错时返回的是 { code, message } 格式的 JSON，
+// 导出内容本身为 JSON 数组，据此区分，出错时提示并返回 false
+export async function saveBlob(blob: Blob, filename: string) {
+  if (blob.type.startsWith('application/json')) {
+    const text = await blob.text()
+    let data: unknown
+    try {
+      data = JSON.parse(text)
+    } catch {
+      data = null
+    }
+    if (data && !Array.isArray(data) && typeof (data as { code?: unknown }).code === 'number') {
+      ElMessage.error((data as { message?: string }).message || '导出失败')
+      return false
+    }
+  }
+  const url = URL.createObjectURL(blob)
+  const link = document.createElement('a')
+  link.href = url
+  link.download = filename
+  link.click()
+  URL.revokeObjectURL(url)
+  return true
+}
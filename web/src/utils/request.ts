@@ -1,6 +1,6 @@
 import axios, { AxiosInstance, AxiosRequestConfig, AxiosResponse } from 'axios'
 import { ElMessage } from 'element-plus'
-import { getToken } from '@/utils/auth'
+import { getToken, setToken, getRefreshToken, setRefreshToken, clearAuth } from '@/utils/auth'
 import type { BaseResponse } from '@/types/api'
 
 // 创建 axios 实例
@@ -9,6 +9,28 @@ const service: AxiosInstance = axios.create({
   timeout: 10000
 })
 
+// 正在进行的刷新令牌请求，多个请求同时 401 时共用
+let refreshing: Promise<string> | null = null
+
+// 使用刷新令牌换取新的访问令牌，刷新令牌同时轮换
+function refreshAccessToken(): Promise<string> {
+  if (!refreshing) {
+    const refreshToken = getRefreshToken()
+    refreshing = (refreshToken
+      ? axios.post(`${import.meta.env.VITE_API_URL || ''}/api/v1/refresh`, { refreshToken }).then((res) => {
+          const { token, refreshToken: nextRefreshToken } = res.data.data
+          setToken(token)
+          setRefreshToken(nextRefreshToken)
+          return token as string
+        })
+      : Promise.reject(new Error('未登录'))
+    ).finally(() => {
+      refreshing = null
+    })
+  }
+  return refreshing
+}
+
 // 请求拦截器
 service.interceptors.request.use(
   (config) => {
@@ -43,7 +65,23 @@ service.interceptors.response.use(
       return Promise.reject(new Error(response.statusText || '请求失败'))
     }
   },
-  (error) => {
+  async (error) => {
+    // 访问令牌过期时使用刷新令牌续期并重试一次
+    const config = error.config
+    const url: string = config?.url || ''
+    if (error.response?.status === 401 && config && !config._retry && !url.endsWith('/login') && !url.endsWith('/refresh')) {
+      config._retry = true
+      try {
+        const token = await refreshAccessToken()
+        config.headers['Authorization'] = `Bearer ${token}`
+        return service.request(config)
+      } catch (refreshError) {
+        clearAuth()
+        window.location.href = '/login'
+        return Promise.reject(refreshError)
+      }
+    }
+
     // 调试日志
     console.error('响应错误:', error)
     console.error('错误详情:', error.response)